@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/modules"
+	"heph4estus/internal/runner"
+)
+
+// resolveAssets returns the asset bundle a flag ships to the module, or nil
+// when dir is empty. A saved plan keeps the bundle it was planned with.
+func resolveAssets(flagName, name, dir string, mod *modules.ModuleDefinition, saved *runner.PlanFile) (*jobs.AssetBundle, error) {
+	if saved != nil {
+		return saved.Assets.Bundle(), nil
+	}
+	if dir == "" {
+		return nil, nil
+	}
+	if !mod.AcceptsAssets(name) {
+		return nil, fmt.Errorf("--%s is not supported by tool %q", flagName, mod.Name)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %w", flagName, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %w", flagName, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("--%s: %s is not a directory", flagName, dir)
+	}
+	return &jobs.AssetBundle{Name: name, Dir: abs}, nil
+}
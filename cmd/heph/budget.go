@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"heph4estus/internal/operator"
+)
+
+// budgetFlags holds the per-job budget flags shared by scan and nmap.
+type budgetFlags struct {
+	workerHours *float64
+	wallTime    *time.Duration
+	spend       *float64
+	destroy     *bool
+}
+
+func addBudgetFlags(fs *flag.FlagSet) *budgetFlags {
+	return &budgetFlags{
+		workerHours: fs.Float64("max-worker-hours", 0, "Cancel the job once workers have run this many hours in total (default: from config, 0 = unlimited)"),
+		wallTime:    fs.Duration("max-wall-time", 0, "Cancel the job after this much wall time, e.g. 2h (default: from config, 0 = unlimited)"),
+		spend:       fs.Float64("max-spend", 0, "Cancel the job once estimated compute spend reaches this many USD (default: from config, 0 = unlimited)"),
+		destroy:     fs.Bool("budget-destroy", false, "Destroy infrastructure when a budget limit cancels the job"),
+	}
+}
+
+// resolve merges the flags with the profile budget.
+func (f *budgetFlags) resolve(cfg *operator.OperatorConfig) (operator.Budget, error) {
+	return operator.ResolveBudget(operator.Budget{
+		MaxWorkerHours: *f.workerHours,
+		MaxWallTime:    *f.wallTime,
+		MaxSpendUSD:    *f.spend,
+		Destroy:        *f.destroy,
+	}, cfg)
+}
+
+// recordBudget returns the budget to store on a job record, or nil when
+// the job is unlimited.
+func recordBudget(b operator.Budget) *operator.Budget {
+	if b.IsZero() {
+		return nil
+	}
+	return &b
+}
+
+// destroyOnBudget reports whether a scan error is a budget breach that
+// should tear down infrastructure.
+func destroyOnBudget(b operator.Budget, scanErr error) bool {
+	return b.Destroy && errors.Is(scanErr, operator.ErrBudgetExceeded)
+}
+
+// checkBudgetTracker refuses a budgeted job when jobs cannot be persisted,
+// since a breach is recorded on the job for `heph status` to report.
+func checkBudgetTracker(b operator.Budget, tracker *operator.Tracker) error {
+	if !b.IsZero() && tracker.Store() == nil {
+		return fmt.Errorf("a job budget needs job tracking, but the job store is unavailable")
+	}
+	return nil
+}
@@ -23,6 +23,9 @@ func requireDeploySupport(kind cloud.Kind) error {
 	if kind.IsProviderNative() {
 		return nil
 	}
+	if kind.IsLocal() {
+		return fmt.Errorf("local runs without infrastructure — there is nothing to deploy or destroy")
+	}
 	if kind.IsSelfhostedFamily() {
 		return fmt.Errorf("%s infrastructure deploy/destroy is not supported — use 'hetzner', 'linode', or 'vultr' for provider-native VPS deploy, or 'manual' with your own infrastructure", kind.Canonical())
 	}
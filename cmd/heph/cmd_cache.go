@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/cloud/factory"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/logger"
+	"heph4estus/internal/operator"
+)
+
+func runCache(args []string, log logger.Logger) error {
+	if len(args) == 0 {
+		return fmt.Errorf("cache requires a subcommand: list, prune")
+	}
+	switch args[0] {
+	case "list":
+		return runCacheList(args[1:], os.Stdout, log)
+	case "prune":
+		return runCachePrune(args[1:], os.Stdout, log)
+	default:
+		return fmt.Errorf("cache: unknown subcommand %q", args[0])
+	}
+}
+
+// cacheFlags are the storage selection flags shared by the cache subcommands.
+type cacheFlags struct {
+	cloud  *string
+	bucket *string
+}
+
+func addCacheFlags(fs *flag.FlagSet) *cacheFlags {
+	return &cacheFlags{
+		cloud:  fs.String("cloud", "", "Cloud provider whose bucket holds the cache: "+cloud.SupportedKindsText()+" (default: from config or aws)"),
+		bucket: fs.String("bucket", "", "Bucket holding the cache (default: the most recent job's bucket on that cloud)"),
+	}
+}
+
+// open builds the storage client and resolves the bucket.
+func (f *cacheFlags) open(ctx context.Context, log logger.Logger) (cloud.Provider, string, error) {
+	opCfg, _ := operator.LoadConfig()
+	kind, err := resolveCLICloud(*f.cloud, opCfg)
+	if err != nil {
+		return nil, "", err
+	}
+	bucket := *f.bucket
+	if bucket == "" {
+		bucket = defaultCacheBucket(kind)
+	}
+	if bucket == "" {
+		return nil, "", fmt.Errorf("no bucket known for %s; pass --bucket", kind.Canonical())
+	}
+	provider, err := buildRuntimeProvider(ctx, kind, nil, log)
+	if err != nil {
+		return nil, "", fmt.Errorf("building cloud provider: %w", err)
+	}
+	return provider, bucket, nil
+}
+
+// defaultCacheBucket returns the bucket the CLI would scan into on kind:
+// the configured one for local and manual selfhosted runs, and otherwise
+// the bucket of the most recent job on that cloud.
+func defaultCacheBucket(kind cloud.Kind) string {
+	switch {
+	case kind.IsLocal():
+		return factory.LocalConfigFromEnv().Bucket
+	case kind.IsSelfhostedFamily() && !kind.IsProviderNative():
+		if b := factory.SelfhostedConfigFromEnv().Bucket; b != "" {
+			return b
+		}
+	}
+	store, err := operator.NewJobStore()
+	if err != nil {
+		return ""
+	}
+	ids, err := store.List()
+	if err != nil {
+		return ""
+	}
+	var (
+		bucket string
+		latest time.Time
+	)
+	for _, id := range ids {
+		rec, err := store.Load(id)
+		if err != nil || rec.Bucket == "" || cloud.Kind(rec.Cloud).Canonical() != kind.Canonical() {
+			continue
+		}
+		if rec.CreatedAt.After(latest) {
+			bucket, latest = rec.Bucket, rec.CreatedAt
+		}
+	}
+	return bucket
+}
+
+func runCacheList(args []string, w io.Writer, log logger.Logger) error {
+	fs := flag.NewFlagSet("cache list", flag.ContinueOnError)
+	store := addCacheFlags(fs)
+	format := fs.String("format", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("--format must be text or json")
+	}
+	ctx := mainContext()
+	provider, bucket, err := store.open(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer closeProvider(provider)
+	return listChunkCache(ctx, w, provider.Storage(), bucket, *format, log)
+}
+
+func listChunkCache(ctx context.Context, w io.Writer, storage cloud.Storage, bucket, format string, log logger.Logger) error {
+	manifests, broken, err := jobs.ListChunkManifests(ctx, storage, bucket)
+	if err != nil {
+		return err
+	}
+	for _, key := range broken {
+		log.Error("Warning: skipped unreadable manifest %s", key)
+	}
+	if format == "json" {
+		if manifests == nil {
+			manifests = []*jobs.ChunkManifest{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(manifests)
+	}
+	if len(manifests) == 0 {
+		_, _ = fmt.Fprintf(w, "No cached wordlist splits in %s\n", bucket)
+		return nil
+	}
+	var total int64
+	_, _ = fmt.Fprintf(w, "%-32s  %-24s  %10s  %6s  %12s  %s\n", "DIGEST", "SOURCE", "WORDS", "CHUNKS", "SIZE", "LAST USED")
+	for _, m := range manifests {
+		size := m.ByteSize()
+		total += size
+		_, _ = fmt.Fprintf(w, "%-32s  %-24s  %10d  %6d  %12s  %s\n",
+			m.Digest, truncate(m.SourceName, 24), m.TotalWords, len(m.Chunks), formatByteSize(size), m.LastUsedAt.Local().Format(time.DateTime))
+	}
+	_, _ = fmt.Fprintf(w, "%d cached splits, %s in %s\n", len(manifests), formatByteSize(total), bucket)
+	return nil
+}
+
+func runCachePrune(args []string, w io.Writer, log logger.Logger) error {
+	fs := flag.NewFlagSet("cache prune", flag.ContinueOnError)
+	store := addCacheFlags(fs)
+	olderThan := fs.Duration("older-than", 0, "Remove splits not used for this long (e.g. 720h)")
+	digest := fs.String("digest", "", "Remove only the split with this digest")
+	all := fs.Bool("all", false, "Remove every cached split")
+	dryRun := fs.Bool("dry-run", false, "List what would be removed without deleting")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts := cachePruneOptions{olderThan: *olderThan, digest: *digest, all: *all, dryRun: *dryRun}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	ctx := mainContext()
+	provider, bucket, err := store.open(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer closeProvider(provider)
+	return pruneChunkCache(ctx, w, provider.Storage(), bucket, opts, time.Now())
+}
+
+// cachePruneOptions selects which cached splits prune removes.
+type cachePruneOptions struct {
+	olderThan time.Duration
+	digest    string
+	all       bool
+	dryRun    bool
+}
+
+func (o cachePruneOptions) validate() error {
+	set := 0
+	if o.olderThan > 0 {
+		set++
+	}
+	if o.digest != "" {
+		set++
+	}
+	if o.all {
+		set++
+	}
+	if o.olderThan < 0 {
+		return fmt.Errorf("--older-than must be positive")
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of --older-than, --digest or --all is required; usage: heph cache prune --older-than 720h [--dry-run]")
+	}
+	return nil
+}
+
+func (o cachePruneOptions) selects(m *jobs.ChunkManifest, now time.Time) bool {
+	switch {
+	case o.all:
+		return true
+	case o.digest != "":
+		return m.Digest == o.digest
+	default:
+		return now.Sub(m.LastUsedAt) > o.olderThan
+	}
+}
+
+func pruneChunkCache(ctx context.Context, w io.Writer, storage cloud.Storage, bucket string, opts cachePruneOptions, now time.Time) error {
+	manifests, _, err := jobs.ListChunkManifests(ctx, storage, bucket)
+	if err != nil {
+		return err
+	}
+	var (
+		splits int
+		bytes  int64
+	)
+	for _, m := range manifests {
+		if !opts.selects(m, now) {
+			continue
+		}
+		splits++
+		bytes += m.ByteSize()
+		if opts.dryRun {
+			_, _ = fmt.Fprintf(w, "Would remove %s (%s, %d chunks, last used %s)\n", m.Digest, m.SourceName, len(m.Chunks), m.LastUsedAt.Local().Format(time.DateTime))
+			continue
+		}
+		n, err := jobs.DeleteCachedSplit(ctx, storage, bucket, m.Digest)
+		if err != nil {
+			return fmt.Errorf("removing cached split %s: %w", m.Digest, err)
+		}
+		_, _ = fmt.Fprintf(w, "Removed %s (%s, %d objects)\n", m.Digest, m.SourceName, n)
+	}
+	if opts.digest != "" && splits == 0 {
+		return fmt.Errorf("no cached split with digest %s in %s", opts.digest, bucket)
+	}
+	verb := "Removed"
+	if opts.dryRun {
+		verb = "Would remove"
+	}
+	_, _ = fmt.Fprintf(w, "%s %d cached splits (%s)\n", verb, splits, formatByteSize(bytes))
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"heph4estus/internal/cloud/local"
+	"heph4estus/internal/jobs"
+)
+
+// seedChunkCache uploads one cached split of content and returns its digest.
+func seedChunkCache(t *testing.T, storage *local.Storage, content, sum string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := jobs.PlanCachedWordlistFile("ffuf", "job", "https://x/FUZZ", "", path, t.TempDir(), 1, 1, nil, sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = plan.Cleanup() }()
+	if err := jobs.UploadChunks(context.Background(), storage, "bucket", plan); err != nil {
+		t.Fatal(err)
+	}
+	return plan.Manifest.Digest
+}
+
+func TestCacheListAndPrune(t *testing.T) {
+	ctx := context.Background()
+	storage, err := local.NewStorage(t.TempDir(), testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := listChunkCache(ctx, &out, storage, "bucket", "text", testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No cached wordlist splits") {
+		t.Fatalf("empty list output = %q", out.String())
+	}
+
+	first := seedChunkCache(t, storage, "a\nb\n", "sum-1")
+	second := seedChunkCache(t, storage, "c\n", "sum-2")
+	out.Reset()
+	if err := listChunkCache(ctx, &out, storage, "bucket", "text", testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), first) || !strings.Contains(out.String(), second) || !strings.Contains(out.String(), "2 cached splits") {
+		t.Fatalf("list output = %q", out.String())
+	}
+
+	out.Reset()
+	dry := cachePruneOptions{all: true, dryRun: true}
+	if err := pruneChunkCache(ctx, &out, storage, "bucket", dry, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := storage.List(ctx, "bucket", jobs.ChunkCachePrefix); len(keys) != 4 {
+		t.Fatalf("dry run removed objects; %d left", len(keys))
+	}
+
+	out.Reset()
+	if err := pruneChunkCache(ctx, &out, storage, "bucket", cachePruneOptions{olderThan: time.Hour}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Removed 0 cached splits") {
+		t.Fatalf("recent splits pruned: %q", out.String())
+	}
+	if err := pruneChunkCache(ctx, &out, storage, "bucket", cachePruneOptions{digest: first}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := pruneChunkCache(ctx, &out, storage, "bucket", cachePruneOptions{olderThan: time.Hour}, time.Now().Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := storage.List(ctx, "bucket", jobs.ChunkCachePrefix); len(keys) != 0 {
+		t.Fatalf("objects left after prune: %v", keys)
+	}
+	if err := pruneChunkCache(ctx, &out, storage, "bucket", cachePruneOptions{digest: first}, time.Now()); err == nil {
+		t.Fatal("expected an error pruning a missing digest")
+	}
+}
+
+func TestCachePruneRequiresOneSelector(t *testing.T) {
+	for _, opts := range []cachePruneOptions{{}, {all: true, digest: "x"}, {olderThan: -time.Hour}} {
+		if err := opts.validate(); err == nil {
+			t.Errorf("validate(%+v) = nil, want error", opts)
+		}
+	}
+	if err := runCachePrune([]string{"--dry-run"}, &bytes.Buffer{}, testLogger()); err == nil || !strings.Contains(err.Error(), "exactly one of") {
+		t.Fatalf("runCachePrune without selector = %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"heph4estus/internal/findings"
+	"heph4estus/internal/logger"
+)
+
+// diffFormats lists the output formats of heph diff.
+var diffFormats = []string{"text", "json", "markdown"}
+
+func runDiff(args []string, log logger.Logger) error {
+	return runDiffJobs(args, os.Stdout, log)
+}
+
+// runDiffJobs compares the normalized findings of two jobs of the same tool
+// and renders what was added, removed and changed as text, JSON or
+// markdown.
+func runDiffJobs(args []string, w io.Writer, log logger.Logger) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	base := fs.String("base", "", "Job to compare against (the older job)")
+	head := fs.String("head", "", "Job to compare (the newer job)")
+	baseDir := fs.String("base-dir", "", "Read the base job from an exported job directory instead")
+	headDir := fs.String("head-dir", "", "Read the head job from an exported job directory instead")
+	findingType := fs.String("type", "", "Only compare findings of these comma-separated types (for example subdomain, port, http, vulnerability)")
+	format := fs.String("format", "text", "Output format: "+strings.Join(diffFormats, ", "))
+	refresh := fs.Bool("refresh", false, "Re-read both jobs' results instead of using the results index")
+	cloudFlag := fs.String("cloud", "", "Override the cloud provider the jobs' results are read from (default: job record or aws)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("diff: unexpected argument %q", fs.Arg(0))
+	}
+	if (*base == "") == (*baseDir == "") || (*head == "") == (*headDir == "") {
+		return fmt.Errorf("one of --base or --base-dir and one of --head or --head-dir are required; usage: heph diff --base <job_id> --head <job_id> [--format %s]", strings.Join(diffFormats, "|"))
+	}
+	if !slices.Contains(diffFormats, *format) {
+		return fmt.Errorf("--format must be one of %s", strings.Join(diffFormats, ", "))
+	}
+	types := make(map[findings.Type]bool)
+	for _, t := range splitList(*findingType) {
+		types[findings.Type(strings.ToLower(t))] = true
+	}
+
+	ctx := context.Background()
+	baseSide, err := loadDiffSide(ctx, *base, *baseDir, *cloudFlag, *refresh, log)
+	if err != nil {
+		return fmt.Errorf("base: %w", err)
+	}
+	headSide, err := loadDiffSide(ctx, *head, *headDir, *cloudFlag, *refresh, log)
+	if err != nil {
+		return fmt.Errorf("head: %w", err)
+	}
+	if baseSide.tool != "" && headSide.tool != "" && !strings.EqualFold(baseSide.tool, headSide.tool) {
+		return fmt.Errorf("jobs ran different tools: %s is %s and %s is %s", baseSide.jobID, baseSide.tool, headSide.jobID, headSide.tool)
+	}
+	for _, side := range []diffSide{baseSide, headSide} {
+		if side.failed > 0 {
+			log.Error("Warning: %d tasks of %s failed; findings they would have reported show as removed or missing", side.failed, side.jobID)
+		}
+	}
+
+	d := findings.Compare(ofTypes(baseSide.findings, types), ofTypes(headSide.findings, types))
+	d.Tool = firstNonEmptyString(headSide.tool, baseSide.tool)
+	d.Base, d.Head = baseSide.jobID, headSide.jobID
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(d); err != nil {
+			return fmt.Errorf("encoding diff: %w", err)
+		}
+		return nil
+	case "markdown":
+		return writeDiffMarkdown(w, d)
+	}
+	writeDiffText(w, d)
+	return nil
+}
+
+// diffSide is one job of a diff.
+type diffSide struct {
+	jobID    string
+	tool     string
+	findings []findings.Finding
+	failed   int
+}
+
+// loadDiffSide collects the findings of a job by ID or export directory.
+func loadDiffSide(ctx context.Context, jobID, dir, cloudOverride string, refresh bool, log logger.Logger) (diffSide, error) {
+	tasks, err := jobTasks(ctx, jobID, dir, cloudOverride, refresh, log)
+	if err != nil {
+		return diffSide{}, err
+	}
+	s := diffSide{jobID: jobID}
+	for _, t := range tasks {
+		s.tool = firstNonEmptyString(s.tool, t.Result.ToolName)
+		if s.jobID == "" {
+			s.jobID = t.Result.JobID
+		}
+		if t.Failed() {
+			s.failed++
+		}
+		s.findings = append(s.findings, t.Findings...)
+	}
+	if s.jobID == "" {
+		s.jobID = filepath.Base(filepath.Clean(dir))
+	}
+	return s, nil
+}
+
+// ofTypes keeps the findings of the given types, or all of them.
+func ofTypes(fs []findings.Finding, types map[findings.Type]bool) []findings.Finding {
+	if len(types) == 0 {
+		return fs
+	}
+	var out []findings.Finding
+	for _, f := range fs {
+		if types[f.Type] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// writeDiffText renders a diff with one line per finding, marked + for
+// new, - for removed and ~ for changed, grouped by finding type.
+func writeDiffText(w io.Writer, d findings.Diff) {
+	_, _ = fmt.Fprintf(w, "%s diff: %s -> %s\n%s\n", d.Tool, d.Base, d.Head, d.Summary())
+	if d.Empty() {
+		_, _ = fmt.Fprintln(w, "\nNo differences.")
+		return
+	}
+	for _, t := range d.Types() {
+		added, removed, changed := d.Of(t)
+		_, _ = fmt.Fprintf(w, "\n%s\n", t.Label())
+		for _, f := range added {
+			_, _ = fmt.Fprintln(w, diffLine("+", f.Location(), findings.Brief(f)))
+		}
+		for _, f := range removed {
+			_, _ = fmt.Fprintln(w, diffLine("-", f.Location(), findings.Brief(f)))
+		}
+		for _, c := range changed {
+			_, _ = fmt.Fprintln(w, diffLine("~", c.Head.Location(), strings.Join(nonEmptyStrings(c.Head.Check, c.Describe()), "  ")))
+		}
+	}
+}
+
+func diffLine(mark, location, detail string) string {
+	return strings.TrimRight(fmt.Sprintf("  %s %s  %s", mark, location, detail), " ")
+}
+
+// writeDiffMarkdown renders a diff as a markdown section per finding type.
+func writeDiffMarkdown(w io.Writer, d findings.Diff) error {
+	_, _ = fmt.Fprintf(w, "## %s diff: `%s` → `%s`\n\n%s\n", d.Tool, d.Base, d.Head, d.Summary())
+	if d.Empty() {
+		_, _ = fmt.Fprintln(w, "\nNo differences.")
+		return nil
+	}
+	for _, t := range d.Types() {
+		added, removed, changed := d.Of(t)
+		_, _ = fmt.Fprintf(w, "\n### %s\n\n", t.Label())
+		var rows [][]string
+		for _, f := range added {
+			rows = append(rows, []string{"new", f.Location(), findings.Brief(f)})
+		}
+		for _, f := range removed {
+			rows = append(rows, []string{"removed", f.Location(), findings.Brief(f)})
+		}
+		for _, c := range changed {
+			var parts []string
+			for _, field := range c.Fields {
+				before, after := c.Values(field)
+				parts = append(parts, fmt.Sprintf("**%s**: %s → %s", field, before, after))
+			}
+			rows = append(rows, []string{"changed", c.Head.Location(), strings.Join(nonEmptyStrings(c.Head.Check, strings.Join(parts, "; ")), " ")})
+		}
+		if err := writeTable(w, "markdown", []string{"Change", "Location", "Details"}, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func nonEmptyStrings(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"heph4estus/internal/findings"
+)
+
+// writeHTTPXJob exports an httpx job whose one task found the given JSONL
+// records.
+func writeHTTPXJob(t *testing.T, jobID, records string) string {
+	t.Helper()
+	jobDir := filepath.Join(t.TempDir(), jobID)
+	files := map[string]string{
+		"results/hosts_1.json":    `{"tool_name":"httpx","job_id":"` + jobID + `","target":"hosts","output_key":"scans/httpx/` + jobID + `/artifacts/hosts_1.jsonl"}`,
+		"artifacts/hosts_1.jsonl": records,
+	}
+	for name, content := range files {
+		path := filepath.Join(jobDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return jobDir
+}
+
+func TestDiffJobs(t *testing.T) {
+	base := writeHTTPXJob(t, "httpx-1",
+		`{"url":"https://a.example","port":"443","scheme":"https","title":"Login","status_code":200}`+"\n"+
+			`{"url":"https://old.example","port":"443","scheme":"https","title":"Old","status_code":200}`+"\n"+
+			`{"url":"https://same.example","port":"443","scheme":"https","title":"Same","status_code":200}`+"\n")
+	head := writeHTTPXJob(t, "httpx-2",
+		`{"url":"https://a.example","port":"443","scheme":"https","title":"Sign in","status_code":200}`+"\n"+
+			`{"url":"https://new.example:8443","port":"8443","scheme":"https","title":"Admin","status_code":401}`+"\n"+
+			`{"url":"https://same.example","port":"443","scheme":"https","title":"Same","status_code":200}`+"\n")
+	args := []string{"--base-dir", base, "--head-dir", head}
+
+	var out bytes.Buffer
+	if err := runDiffJobs(args, &out, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"httpx diff: httpx-1 -> httpx-2",
+		"1 new, 1 removed, 1 changed, 1 unchanged",
+		"HTTP services",
+		"+ https://new.example:8443  https  Admin",
+		"- https://old.example  https  Old",
+		"~ https://a.example  title: Login -> Sign in",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("text lacks %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runDiffJobs(append(args, "--format", "json"), &out, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	var d findings.Diff
+	if err := json.Unmarshal(out.Bytes(), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Tool != "httpx" || d.Base != "httpx-1" || len(d.Added) != 1 || len(d.Changed) != 1 || d.Changed[0].Fields[0] != "title" {
+		t.Errorf("json diff = %+v", d)
+	}
+
+	out.Reset()
+	if err := runDiffJobs(append(args, "--format", "markdown"), &out, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## httpx diff: `httpx-1` → `httpx-2`", "### HTTP services", "| changed | https://a.example | **title**: Login → Sign in |"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("markdown lacks %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runDiffJobs([]string{"--base-dir", base, "--head-dir", base, "--type", "port"}, &out, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No differences.") {
+		t.Errorf("self diff = %s", out.String())
+	}
+}
+
+func TestDiffJobsErrors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	httpx := writeHTTPXJob(t, "httpx-1", `{"url":"https://a.example","port":"443"}`+"\n")
+	nuclei := writeExportedJob(t)
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{nil, "--base or --base-dir"},
+		{[]string{"--base", "a", "--base-dir", httpx, "--head", "b"}, "--base or --base-dir"},
+		{[]string{"--base-dir", httpx, "--head-dir", httpx, "--format", "html"}, "--format must be one of"},
+		{[]string{"--base-dir", httpx, "--head-dir", nuclei}, "different tools"},
+		{[]string{"--base", "missing", "--head-dir", httpx}, "base:"},
+	} {
+		if err := runDiffJobs(tt.args, &bytes.Buffer{}, testLogger()); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("diff %v: error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"heph4estus/internal/findings"
+	"heph4estus/internal/index"
+	"heph4estus/internal/logger"
+	"heph4estus/internal/tui/core"
+)
+
+func runIndex(args []string, log logger.Logger) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "list":
+			return runIndexList(args[1:], os.Stdout)
+		case "rm":
+			return runIndexRemove(args[1:], os.Stdout)
+		}
+	}
+	return runIndexJob(args, os.Stdout, log)
+}
+
+// runIndexJob reads a job's results and stores their findings in the local
+// results index, replacing what was indexed for the job before.
+func runIndexJob(args []string, w io.Writer, log logger.Logger) error {
+	fs := flag.NewFlagSet("index", flag.ContinueOnError)
+	jobID := fs.String("job-id", "", "Job to index; read from its --out export when it has one, else from cloud storage")
+	dir := fs.String("dir", "", "Index a locally exported job directory instead (<out>/<tool>/<job_id>)")
+	cloudFlag := fs.String("cloud", "", "Override the cloud provider the job's results are read from (default: job record or aws)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("index: unknown subcommand %q (want list, rm, or flags)", fs.Arg(0))
+	}
+	if *jobID == "" && *dir == "" {
+		return fmt.Errorf("--job-id or --dir is required; usage: heph index --job-id <id>, heph index --dir <out>/<tool>/<job_id>, heph index list, or heph index rm --job-id <id>")
+	}
+
+	ctx := context.Background()
+	id, source := *jobID, *dir
+	if *dir != "" {
+		if id == "" {
+			id = filepath.Base(filepath.Clean(*dir))
+		}
+		*jobID = ""
+	}
+	src, closeSrc, err := openResultsSource(ctx, *jobID, *dir, *cloudFlag, log)
+	if err != nil {
+		return err
+	}
+	defer closeSrc()
+	if source == "" {
+		source = resultsSourceName(src)
+	}
+	job, err := indexSource(ctx, src, index.Job{JobID: id, Source: source}, log)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(w, "Indexed %s job %s: %d findings from %d tasks (%d failed)\n", job.Tool, job.JobID, job.Findings, job.Tasks, job.Failed)
+	if finished, _ := jobFinished(id); !finished {
+		log.Error("Warning: job %s is still running; the index holds its results so far and is refreshed when read after the job finishes", id)
+	}
+	return nil
+}
+
+// indexSource collects the results of src and stores them in the index as
+// job, warning about tasks whose results could not be read.
+func indexSource(ctx context.Context, src findings.Source, job index.Job, log logger.Logger) (index.Job, error) {
+	tasks, err := findings.Collect(ctx, src)
+	if err != nil {
+		return job, err
+	}
+	for _, t := range tasks {
+		if t.Err != "" {
+			log.Error("Warning: %s: %s", t.Key, t.Err)
+		}
+	}
+	return putIndexed(job, tasks)
+}
+
+// putIndexed stores the collected tasks of a job in the default index.
+func putIndexed(job index.Job, tasks []findings.Task) (index.Job, error) {
+	db, err := index.OpenDefault()
+	if err != nil {
+		return job, err
+	}
+	defer func() { _ = db.Close() }()
+	return db.Put(job, tasks)
+}
+
+// indexExport indexes a job just exported to dir. Indexing is best-effort:
+// a failure is reported but does not fail the scan.
+func indexExport(ctx context.Context, jobID, dir string, log logger.Logger) {
+	job, err := core.IndexExport(ctx, jobID, dir)
+	if err != nil {
+		log.Error("Warning: indexing results failed: %v (retry with: heph index --job-id %s)", err, jobID)
+		return
+	}
+	logStatus("Indexed %d findings; search across jobs with: heph results --all-jobs", job.Findings)
+}
+
+// resultsSourceName describes where a results source reads from.
+func resultsSourceName(src findings.Source) string {
+	switch s := src.(type) {
+	case *core.S3ResultsSource:
+		return s.Bucket + "/" + s.Prefix
+	case *core.LocalResultsSource:
+		return filepath.Dir(s.ResultsDir)
+	}
+	return ""
+}
+
+// runIndexList lists the indexed jobs, most recent first.
+func runIndexList(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("index list", flag.ContinueOnError)
+	format := fs.String("format", "table", "Output format: table, csv or markdown")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "table" && *format != "csv" && *format != "markdown" {
+		return fmt.Errorf("--format must be one of table, csv, markdown")
+	}
+	db, err := index.OpenDefault()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	jobs, err := db.Jobs()
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 && *format == "table" {
+		_, _ = fmt.Fprintln(w, "No jobs indexed. Index one with: heph index --job-id <id>")
+		return nil
+	}
+	rows := make([][]string, len(jobs))
+	for i, j := range jobs {
+		rows[i] = []string{j.JobID, j.Tool, timeText(j.Finished), strconv.Itoa(j.Tasks), strconv.Itoa(j.Failed), strconv.Itoa(j.Findings), j.Source}
+	}
+	return writeTable(w, *format, []string{"JOB", "TOOL", "FINISHED", "TASKS", "FAILED", "FINDINGS", "SOURCE"}, rows)
+}
+
+// runIndexRemove drops a job from the index.
+func runIndexRemove(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("index rm", flag.ContinueOnError)
+	jobID := fs.String("job-id", "", "Job to remove from the index")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *jobID == "" {
+		return fmt.Errorf("--job-id is required")
+	}
+	db, err := index.OpenDefault()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	removed, err := db.Remove(*jobID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("job %s is not indexed", *jobID)
+	}
+	_, _ = fmt.Fprintf(w, "Removed job %s from the index\n", *jobID)
+	return nil
+}
+
+func timeText(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"heph4estus/internal/index"
+	"heph4estus/internal/operator"
+)
+
+func TestIndexDirAndSearchAllJobs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	jobDir := writeExportedJob(t)
+
+	var out bytes.Buffer
+	if err := runIndexJob([]string{"--job-id", "job-q", "--dir", jobDir}, &out, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Indexed nuclei job job-q: 2 findings from 2 tasks (1 failed)") {
+		t.Errorf("index output = %s", out.String())
+	}
+
+	out.Reset()
+	if err := runResultsQuery([]string{"--all-jobs", "--port", "8443", "--since", "2000-01-01"}, &out, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "tech-nginx") || !strings.Contains(out.String(), "job-q") || strings.Contains(out.String(), "git-config") {
+		t.Errorf("all-jobs output = %s", out.String())
+	}
+	if !strings.Contains(out.String(), "1 findings from 1 of 1 indexed jobs") {
+		t.Errorf("all-jobs summary = %s", out.String())
+	}
+
+	out.Reset()
+	if err := runResultsQuery([]string{"--all-jobs", "--tool", "nuclei", "--format", "csv"}, &out, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "tool,job_id,task_id,target,time") || !strings.Contains(out.String(), "nuclei,job-q,a.example,a.example") {
+		t.Errorf("all-jobs csv = %s", out.String())
+	}
+
+	out.Reset()
+	if err := runIndexList(nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "job-q") || !strings.Contains(out.String(), jobDir) {
+		t.Errorf("index list = %s", out.String())
+	}
+
+	out.Reset()
+	if err := runIndexRemove([]string{"--job-id", "job-q"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if err := runIndexRemove([]string{"--job-id", "job-q"}, &out); err == nil || !strings.Contains(err.Error(), "not indexed") {
+		t.Errorf("second rm error = %v", err)
+	}
+	if err := runResultsQuery([]string{"--all-jobs"}, &out, testLogger()); err == nil || !strings.Contains(err.Error(), "index is empty") {
+		t.Errorf("empty index error = %v", err)
+	}
+}
+
+func TestResultsQueryByJobIDUsesIndex(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	jobDir := writeExportedJob(t)
+	store, err := operator.NewJobStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(&operator.JobRecord{JobID: "job-q", ToolName: "nuclei", Phase: operator.PhaseComplete, LocalOutputDir: jobDir}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runResultsQuery([]string{"--job-id", "job-q"}, &bytes.Buffer{}, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+
+	// With the export gone the job is still answered from the index.
+	if err := os.RemoveAll(jobDir); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := runResultsQuery([]string{"--job-id", "job-q", "--severity", "medium"}, &out, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "git-config") {
+		t.Errorf("indexed output = %s", out.String())
+	}
+	if err := runResultsQuery([]string{"--job-id", "job-q", "--refresh"}, &out, testLogger()); err == nil {
+		t.Error("--refresh should re-read the missing results")
+	}
+}
+
+func TestResultsQueryRunningJobSkipsIndex(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	jobDir := writeExportedJob(t)
+	store, err := operator.NewJobStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &operator.JobRecord{JobID: "job-q", ToolName: "nuclei", Phase: operator.PhaseScanning, LocalOutputDir: jobDir}
+	if err := store.Create(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := runResultsQuery([]string{"--job-id", "job-q"}, &bytes.Buffer{}, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	db, err := index.OpenDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, indexed, _ := db.Job("job-q")
+	_ = db.Close()
+	if indexed {
+		t.Fatal("a running job's partial results were indexed")
+	}
+
+	// An index taken while the job ran is replaced once it finishes.
+	if err := runIndexJob([]string{"--job-id", "job-q"}, &bytes.Buffer{}, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(jobDir, "results", "c.example_3.json"), []byte(`{"tool_name":"nuclei","job_id":"job-q","target":"c.example"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rec.Phase = operator.PhaseComplete
+	if err := store.Update(rec); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := runResultsQuery([]string{"--job-id", "job-q"}, &out, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "from 3 tasks") {
+		t.Errorf("finished job served a stale index:\n%s", out.String())
+	}
+}
+
+func TestIndexAndAllJobsErrors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--all-jobs", "--job-id", "j"}, "drop --job-id"},
+		{[]string{"--all-jobs", "--view", "tasks"}, "--all-jobs lists findings"},
+		{[]string{"--all-jobs", "--since", "yesterday"}, "--since"},
+		{[]string{"--job-id", "j", "--since", "30d"}, "filter --all-jobs"},
+	} {
+		if err := runResultsQuery(tt.args, &bytes.Buffer{}, testLogger()); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("results %v: error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+	if err := runIndexJob(nil, &bytes.Buffer{}, testLogger()); err == nil || !strings.Contains(err.Error(), "--job-id or --dir") {
+		t.Errorf("index without job: error = %v", err)
+	}
+	if err := runIndexJob([]string{"--job-id", "missing"}, &bytes.Buffer{}, testLogger()); err == nil {
+		t.Error("index of an unknown job: want error")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"heph4estus/internal/fleet"
 	"heph4estus/internal/logger"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/scope"
 )
 
 func runInit(args []string, log logger.Logger) error {
@@ -28,6 +29,14 @@ func runInit(args []string, log logger.Logger) error {
 	dualStackRequired := fs.Bool("dual-stack-required", false, "Default to requiring workers with public IPv4 and IPv6-ready public IPv6")
 	cleanupPolicy := fs.String("cleanup-policy", "", "Default cleanup policy: reuse or destroy-after")
 	outputDir := fs.String("output-dir", "", "Default output directory for results")
+	scopeFile := fs.String("scope-file", "", "Default engagement scope file applied to every job")
+	maxWorkerHours := fs.Float64("max-worker-hours", 0, "Default per-job worker-hour budget (0 = unlimited)")
+	maxWallTime := fs.String("max-wall-time", "", "Default per-job wall time budget, e.g. 4h (empty = unlimited)")
+	maxSpend := fs.Float64("max-spend", 0, "Default per-job estimated spend budget in USD (0 = unlimited)")
+	budgetDestroy := fs.Bool("budget-destroy", false, "Destroy infrastructure when a job exceeds its budget")
+	scanWindow := fs.String("window", "", `Default scan window, e.g. "Mon-Fri 09:00-17:00" (empty = any time)`)
+	windowTZ := fs.String("window-tz", "", "Default time zone for the scan window and blackouts")
+	blackouts := fs.String("blackout", "", "Default blackout dates, e.g. 2026-12-24,2026-12-31..2027-01-01")
 	show := fs.Bool("show", false, "Show current config and exit")
 
 	if err := fs.Parse(args); err != nil {
@@ -48,13 +57,56 @@ func runInit(args []string, log logger.Logger) error {
 	explicit := flagsSet(fs)
 
 	if len(explicit) > 0 {
-		return runInitNonInteractive(existing, explicit, *region, *profile, *workers, *computeMode, *cloudValue, *placementMode, *maxWorkersPerHost, *minUniqueIPs, *ipv6Required, *dualStackRequired, *cleanupPolicy, *outputDir)
+		if err := applyBudgetDefaults(existing, explicit, *maxWorkerHours, *maxWallTime, *maxSpend, *budgetDestroy); err != nil {
+			return err
+		}
+		if err := applyWindowDefaults(existing, explicit, *scanWindow, *windowTZ, *blackouts); err != nil {
+			return err
+		}
+		return runInitNonInteractive(existing, explicit, *region, *profile, *workers, *computeMode, *cloudValue, *placementMode, *maxWorkersPerHost, *minUniqueIPs, *ipv6Required, *dualStackRequired, *cleanupPolicy, *outputDir, *scopeFile)
 	}
 
 	return runInitInteractive(existing)
 }
 
-func runInitNonInteractive(cfg *operator.OperatorConfig, explicit map[string]bool, region, profile string, workers int, computeMode, cloudValue, placementMode string, maxWorkersPerHost, minUniqueIPs int, ipv6Required, dualStackRequired bool, cleanupPolicy, outputDir string) error {
+// applyBudgetDefaults sets the profile budget from explicitly passed flags.
+func applyBudgetDefaults(cfg *operator.OperatorConfig, explicit map[string]bool, maxWorkerHours float64, maxWallTime string, maxSpend float64, destroy bool) error {
+	if explicit["max-worker-hours"] {
+		cfg.MaxWorkerHours = maxWorkerHours
+	}
+	if explicit["max-wall-time"] {
+		cfg.MaxWallTime = strings.TrimSpace(maxWallTime)
+	}
+	if explicit["max-spend"] {
+		cfg.MaxSpendUSD = maxSpend
+	}
+	if explicit["budget-destroy"] {
+		cfg.BudgetDestroy = destroy
+	}
+	if _, err := operator.ResolveBudget(operator.Budget{}, cfg); err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyWindowDefaults sets the profile scan window from explicitly passed flags.
+func applyWindowDefaults(cfg *operator.OperatorConfig, explicit map[string]bool, ranges, tz, blackouts string) error {
+	if explicit["window"] {
+		cfg.ScanWindow = strings.TrimSpace(ranges)
+	}
+	if explicit["window-tz"] {
+		cfg.WindowTZ = strings.TrimSpace(tz)
+	}
+	if explicit["blackout"] {
+		cfg.Blackouts = strings.TrimSpace(blackouts)
+	}
+	if _, err := operator.ResolveWindow("", "", "", cfg); err != nil {
+		return err
+	}
+	return nil
+}
+
+func runInitNonInteractive(cfg *operator.OperatorConfig, explicit map[string]bool, region, profile string, workers int, computeMode, cloudValue, placementMode string, maxWorkersPerHost, minUniqueIPs int, ipv6Required, dualStackRequired bool, cleanupPolicy, outputDir, scopeFile string) error {
 	if explicit["region"] {
 		cfg.Region = region
 	}
@@ -118,6 +170,14 @@ func runInitNonInteractive(cfg *operator.OperatorConfig, explicit map[string]boo
 	if explicit["output-dir"] {
 		cfg.OutputDir = outputDir
 	}
+	if explicit["scope-file"] {
+		if scopeFile != "" {
+			if _, err := scope.Load(scopeFile); err != nil {
+				return fmt.Errorf("--scope-file: %w", err)
+			}
+		}
+		cfg.ScopeFile = scopeFile
+	}
 
 	if err := operator.SaveConfig(cfg); err != nil {
 		return fmt.Errorf("saving config: %w", err)
@@ -206,6 +266,13 @@ func runInitInteractive(cfg *operator.OperatorConfig) error {
 
 	cfg.OutputDir = promptField(reader, "Output directory", cfg.OutputDir, "")
 
+	cfg.ScopeFile = promptField(reader, "Scope file", cfg.ScopeFile, "")
+	if cfg.ScopeFile != "" {
+		if _, err := scope.Load(cfg.ScopeFile); err != nil {
+			return err
+		}
+	}
+
 	if err := operator.SaveConfig(cfg); err != nil {
 		return fmt.Errorf("saving config: %w", err)
 	}
@@ -245,6 +312,15 @@ func printConfig(cfg *operator.OperatorConfig) error {
 	_, _ = fmt.Fprintf(os.Stdout, "dual_stack:     %t\n", cfg.DualStackRequired)
 	_, _ = fmt.Fprintf(os.Stdout, "cleanup_policy: %s\n", valueOrDash(cfg.CleanupPolicy))
 	_, _ = fmt.Fprintf(os.Stdout, "output_dir:     %s\n", valueOrDash(cfg.OutputDir))
+	_, _ = fmt.Fprintf(os.Stdout, "scope_file:     %s\n", valueOrDash(cfg.ScopeFile))
+	budget, _ := operator.ResolveBudget(operator.Budget{}, cfg)
+	_, _ = fmt.Fprintf(os.Stdout, "budget:         %s\n", budget)
+	_, _ = fmt.Fprintf(os.Stdout, "budget_destroy: %t\n", cfg.BudgetDestroy)
+	if window, err := operator.ResolveWindow("", "", "", cfg); err == nil && window != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "scan_window:    %s\n", window)
+	} else {
+		_, _ = fmt.Fprintf(os.Stdout, "scan_window:    -\n")
+	}
 
 	dir, err := operator.ConfigDir()
 	if err == nil {
@@ -64,6 +64,7 @@ func TestInitShowPopulatedConfig(t *testing.T) {
 		DualStackRequired: true,
 		CleanupPolicy:     "destroy-after",
 		OutputDir:         "/tmp/results",
+		ScopeFile:         "/etc/heph/scope.txt",
 	}
 	err := printConfig(cfg)
 	_ = w.Close()
@@ -90,6 +91,7 @@ func TestInitShowPopulatedConfig(t *testing.T) {
 		"dual_stack:     true",
 		"cleanup_policy: destroy-after",
 		"output_dir:     /tmp/results",
+		"scope_file:     /etc/heph/scope.txt",
 	}
 	for _, c := range checks {
 		if !strings.Contains(output, c) {
@@ -120,7 +122,7 @@ func TestInitNonInteractiveWritesConfig(t *testing.T) {
 	_, w, _ := os.Pipe()
 	os.Stdout = w
 
-	err := runInitNonInteractive(cfg, explicit, "eu-west-1", "", 25, "", "selfhosted", "throughput", 3, 12, false, true, "", "")
+	err := runInitNonInteractive(cfg, explicit, "eu-west-1", "", 25, "", "selfhosted", "throughput", 3, 12, false, true, "", "", "")
 	_ = w.Close()
 	os.Stdout = old
 
@@ -205,7 +207,7 @@ func TestInitNonInteractiveValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := runInitNonInteractive(cfg, tt.explicit, "", "", tt.workers, tt.compute, tt.cloud, tt.place, tt.maxHost, tt.minIPs, false, false, tt.cleanup, "")
+			err := runInitNonInteractive(cfg, tt.explicit, "", "", tt.workers, tt.compute, tt.cloud, tt.place, tt.maxHost, tt.minIPs, false, false, tt.cleanup, "", "")
 			if err == nil {
 				t.Fatal("expected error")
 			}
@@ -10,7 +10,10 @@ import (
 
 	"heph4estus/internal/cloud"
 	"heph4estus/internal/fleet"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/modules"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/targets"
 	nmaptool "heph4estus/internal/tools/nmap"
 )
 
@@ -96,7 +99,7 @@ func TestPreflightTargetListFileRejectsEmptyTargets(t *testing.T) {
 		t.Fatalf("write temp file: %v", err)
 	}
 
-	_, err := preflightTargetListFile(path)
+	_, err := preflightTargetListFile(path, "", targets.Options{})
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -105,13 +108,91 @@ func TestPreflightTargetListFileRejectsEmptyTargets(t *testing.T) {
 	}
 }
 
+func TestPreflightTargetsNormalizesForModule(t *testing.T) {
+	mod := &modules.ModuleDefinition{Name: "httpx", TargetKinds: []string{"url", "hostport"}}
+	got, err := preflightTargets([]targets.Entry{
+		{Target: "example.com"},
+		{Target: "EXAMPLE.com."},
+		{Target: "https://example.com/"},
+		{Target: "api.example.com:8443", Options: "-path /health"},
+	}, moduleTargetOptions(mod, -1, false))
+	if err != nil {
+		t.Fatalf("preflightTargets: %v", err)
+	}
+	if len(got) != 2 || got[0].Target != "https://example.com" || got[1].Target != "api.example.com:8443" || got[1].Options != "-path /health" {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+
+	mod = &modules.ModuleDefinition{Name: "dnsx", TargetKinds: []string{"hostname"}}
+	_, err = preflightTargets([]targets.Entry{{Target: "10.0.0.0/24"}}, moduleTargetOptions(mod, -1, false))
+	if err == nil || !strings.Contains(err.Error(), "cidr targets are not accepted") {
+		t.Fatalf("expected kind mismatch error, got %v", err)
+	}
+}
+
+func TestPreflightTargetListFileImportsCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "assets.csv")
+	if err := os.WriteFile(path, []byte("Hostname,Owner\nwww.example.com,web team\n"), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	mod := &modules.ModuleDefinition{Name: "httpx", TargetKinds: []string{"url"}}
+	got, err := preflightTargetListFile(path, "", moduleTargetOptions(mod, -1, false))
+	if err != nil {
+		t.Fatalf("preflightTargetListFile: %v", err)
+	}
+	if len(got) != 1 || got[0].Target != "https://www.example.com" || got[0].Metadata["owner"] != "web team" {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+}
+
+func TestPreflightRuntimeTargetChecksKind(t *testing.T) {
+	mod := &modules.ModuleDefinition{Name: "ffuf", TargetKinds: []string{"url"}}
+	if err := preflightRuntimeTarget(mod, "https://example.com/FUZZ"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := preflightRuntimeTarget(mod, "example.com"); err == nil || !strings.Contains(err.Error(), "accepts url") {
+		t.Fatalf("expected kind error, got %v", err)
+	}
+}
+
+func TestNormalizeNmapTargetsKeepsLineOptions(t *testing.T) {
+	opts := targets.Options{SplitCIDR: 24}
+	got, err := normalizeNmapTargets("10.0.0.0/23 -p 22\n# comment\n10.0.1.0/24 -p 22\nScanMe.example.com\n", opts)
+	if err != nil {
+		t.Fatalf("normalizeNmapTargets: %v", err)
+	}
+	want := "10.0.0.0/24 -p 22\n10.0.1.0/24 -p 22\nscanme.example.com"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if _, err := normalizeNmapTargets("bad!host -sV\n", opts); err == nil {
+		t.Fatal("expected invalid target error")
+	}
+}
+
+func TestNmapTargetOptionsSplitsDiscovery(t *testing.T) {
+	mod := &modules.ModuleDefinition{Name: "nmap", TargetKinds: []string{"ip", "cidr", "hostname"}}
+	if got := nmapTargetOptions(mod, nmaptool.ModeDiscoverThenScan, -1, false).SplitCIDR; got != nmaptool.DiscoverySplitCIDR {
+		t.Errorf("discovery split = /%d, want /%d", got, nmaptool.DiscoverySplitCIDR)
+	}
+	if got := nmapTargetOptions(mod, nmaptool.ModeDiscoverThenScan, 0, false).SplitCIDR; got != 0 {
+		t.Errorf("--split-cidr 0 split = /%d, want none", got)
+	}
+	if got := nmapTargetOptions(mod, nmaptool.ModeDiscoverThenScan, 26, false).SplitCIDR; got != 26 {
+		t.Errorf("--split-cidr 26 split = /%d", got)
+	}
+	if got := nmapTargetOptions(mod, nmaptool.ModeTargetOnly, -1, false).SplitCIDR; got != 0 {
+		t.Errorf("target-only split = /%d, want none", got)
+	}
+}
+
 func TestPreflightWordlistFileRejectsEmptyWordlist(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "words.txt")
 	if err := os.WriteFile(path, []byte("\n\n"), 0o644); err != nil {
 		t.Fatalf("write temp file: %v", err)
 	}
 
-	_, err := preflightWordlistFile("ffuf", path, "https://example.com/FUZZ", "", 0, 5)
+	_, err := preflightWordlistFile("ffuf", path, "https://example.com/FUZZ", "", 0, 5, nil)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -126,7 +207,7 @@ func TestPreflightWordlistFileAutoSizesOmittedChunks(t *testing.T) {
 		t.Fatalf("write temp file: %v", err)
 	}
 
-	meta, err := preflightWordlistFile("ffuf", path, "https://example.com/FUZZ", "", 0, 2)
+	meta, err := preflightWordlistFile("ffuf", path, "https://example.com/FUZZ", "", 0, 2, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -144,7 +225,7 @@ func TestPreflightWordlistFileHonorsExplicitChunks(t *testing.T) {
 		t.Fatalf("write temp file: %v", err)
 	}
 
-	meta, err := preflightWordlistFile("ffuf", path, "https://example.com/FUZZ", "", 3, 1)
+	meta, err := preflightWordlistFile("ffuf", path, "https://example.com/FUZZ", "", 3, 1, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -154,7 +235,7 @@ func TestPreflightWordlistFileHonorsExplicitChunks(t *testing.T) {
 }
 
 func TestPreflightWordlistFileRejectsDirectory(t *testing.T) {
-	_, err := preflightWordlistFile("ffuf", t.TempDir(), "https://example.com/FUZZ", "", 0, 2)
+	_, err := preflightWordlistFile("ffuf", t.TempDir(), "https://example.com/FUZZ", "", 0, 2, nil)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -172,7 +253,7 @@ func TestPreflightWordlistFileRejectsUnsafeExplicitChunkSizing(t *testing.T) {
 		t.Fatalf("truncate temp file: %v", err)
 	}
 
-	_, err := preflightWordlistFile("ffuf", path, "https://example.com/FUZZ", "", 1, 1)
+	_, err := preflightWordlistFile("ffuf", path, "https://example.com/FUZZ", "", 1, 1, nil)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -184,11 +265,9 @@ func TestPreflightWordlistFileRejectsUnsafeExplicitChunkSizing(t *testing.T) {
 func TestRunTargetListScanStartedFalseOnLaunchFailure(t *testing.T) {
 	started, err := runTargetListScan(
 		context.Background(),
-		"httpx",
+		jobs.JobConfig{ToolName: "httpx", Entries: []targets.Entry{{Target: "example.com"}}},
 		"job-1",
 		"targets.txt",
-		"example.com\n",
-		"",
 		1,
 		"fargate",
 		"text",
@@ -201,6 +280,7 @@ func TestRunTargetListScanStartedFalseOnLaunchFailure(t *testing.T) {
 		operator.NoopTracker(),
 		cloud.KindAWS,
 		fleet.PlacementPolicy{},
+		jobLimits{},
 	)
 	if err == nil {
 		t.Fatal("expected error")
@@ -213,11 +293,9 @@ func TestRunTargetListScanStartedFalseOnLaunchFailure(t *testing.T) {
 func TestRunTargetListScanStartedTrueOnOutputFailure(t *testing.T) {
 	started, err := runTargetListScan(
 		context.Background(),
-		"httpx",
+		jobs.JobConfig{ToolName: "httpx", Entries: []targets.Entry{{Target: "example.com"}}},
 		"job-1",
 		"targets.txt",
-		"example.com\n",
-		"",
 		1,
 		"fargate",
 		"text",
@@ -230,6 +308,7 @@ func TestRunTargetListScanStartedTrueOnOutputFailure(t *testing.T) {
 		operator.NoopTracker(),
 		cloud.KindAWS,
 		fleet.PlacementPolicy{},
+		jobLimits{},
 	)
 	if err == nil {
 		t.Fatal("expected error")
@@ -260,6 +339,9 @@ func TestRunNmapScanWithDepsStartedFalseOnLaunchFailure(t *testing.T) {
 		operator.NoopTracker(),
 		"job-1",
 		fleet.PlacementPolicy{},
+		jobs.JobConfig{},
+		cloud.KindAWS,
+		jobLimits{},
 	)
 	if err == nil {
 		t.Fatal("expected error")
@@ -290,6 +372,9 @@ func TestRunNmapScanWithDepsStartedTrueOnOutputFailure(t *testing.T) {
 		operator.NoopTracker(),
 		"job-1",
 		fleet.PlacementPolicy{},
+		jobs.JobConfig{},
+		cloud.KindAWS,
+		jobLimits{},
 	)
 	if err == nil {
 		t.Fatal("expected error")
@@ -330,7 +415,9 @@ func TestRunNmapScanWithDeps_ProviderNativeSkipsRunContainer(t *testing.T) {
 		operator.NoopTracker(),
 		"job-sh",
 		fleet.PlacementPolicy{},
+		jobs.JobConfig{},
 		cloud.KindHetzner,
+		jobLimits{},
 	)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -372,7 +459,9 @@ func TestRunNmapScanWithDeps_SelfhostedNeverCallsSpot(t *testing.T) {
 		operator.NoopTracker(),
 		"job-sh",
 		fleet.PlacementPolicy{},
+		jobs.JobConfig{},
 		cloud.KindManual,
+		jobLimits{},
 	)
 	if err != nil {
 		t.Fatalf("unexpected error (spot should not have been called): %v", err)
@@ -395,11 +484,9 @@ func TestRunTargetListScan_ProviderNativeSkipsRunContainer(t *testing.T) {
 	comp := &mockCompute{}
 	started, err := runTargetListScan(
 		context.Background(),
-		"httpx",
+		jobs.JobConfig{ToolName: "httpx", Entries: []targets.Entry{{Target: "example.com"}}},
 		"job-hetzner",
 		"targets.txt",
-		"example.com\n",
-		"",
 		10,
 		"auto",
 		"text",
@@ -417,6 +504,7 @@ func TestRunTargetListScan_ProviderNativeSkipsRunContainer(t *testing.T) {
 		operator.NoopTracker(),
 		cloud.KindHetzner,
 		fleet.PlacementPolicy{},
+		jobLimits{},
 	)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -487,3 +575,17 @@ func TestPrintRunSummaryDeployedWithOutput(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckBudgetTracker(t *testing.T) {
+	budget := operator.Budget{MaxWorkerHours: 2}
+	if err := checkBudgetTracker(budget, operator.NoopTracker()); err == nil {
+		t.Fatal("expected a budget without a job store to fail")
+	}
+	if err := checkBudgetTracker(operator.Budget{}, operator.NoopTracker()); err != nil {
+		t.Fatalf("unlimited job without a store: %v", err)
+	}
+	tracker := operator.NewTracker(operator.NewJobStoreAt(t.TempDir()))
+	if err := checkBudgetTracker(budget, tracker); err != nil {
+		t.Fatalf("budget with a store: %v", err)
+	}
+}
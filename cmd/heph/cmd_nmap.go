@@ -11,27 +11,26 @@ import (
 	"time"
 
 	"heph4estus/internal/cloud"
-	awscloud "heph4estus/internal/cloud/aws"
 	"heph4estus/internal/cloud/factory"
 	"heph4estus/internal/fleet"
 	"heph4estus/internal/infra"
 	"heph4estus/internal/jobs"
 	"heph4estus/internal/logger"
+	"heph4estus/internal/modules"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/runner"
+	"heph4estus/internal/scope"
+	"heph4estus/internal/targets"
 	"heph4estus/internal/tools/nmap"
 	"heph4estus/internal/worker"
 )
 
-const (
-	spotThreshold  = 50
-	pollInterval   = 2 * time.Second
-	enqueueTimeout = 5 * time.Minute
-	launchTimeout  = 5 * time.Minute
-)
+const pollInterval = 2 * time.Second
 
 func runNmap(args []string, log logger.Logger) error {
 	fs := flag.NewFlagSet("nmap", flag.ContinueOnError)
 	inputFile := fs.String("file", "", "Path to file containing targets (required)")
+	inputFormat := fs.String("input-format", "", "Target file format: "+strings.Join(targets.Formats(), ", ")+" (default: from file extension)")
 	defaultOptions := fs.String("default-options", "-sS", "Default nmap options")
 	workers := fs.Int("workers", 0, "Number of worker tasks to launch (default: from config or 10)")
 	computeMode := fs.String("compute-mode", "", "Compute mode: auto, fargate, or spot (default: from config or auto)")
@@ -40,26 +39,72 @@ func runNmap(args []string, log logger.Logger) error {
 	minUniqueIPs := fs.Int("min-unique-ips", 0, "Minimum unique public IPv4 addresses required before scan start")
 	ipv6Required := fs.Bool("ipv6-required", false, "Require IPv6-validated workers before scan start")
 	dualStackRequired := fs.Bool("dual-stack-required", false, "Require workers with both public IPv4 and IPv6-ready public IPv6")
-	mode := fs.String("mode", "target-only", "Distribution mode: target-only or target-ports")
-	portChunks := fs.Int("port-chunks", 5, "Number of port chunks per target (target-ports mode only)")
+	mode := fs.String("mode", nmap.ModeTargetOnly, "Distribution mode: target-only, target-ports or discover-then-scan")
+	portChunks := fs.Int("port-chunks", 5, "Number of port chunks per target (target-ports and discover-then-scan modes)")
+	discoveryOptions := fs.String("discovery-options", nmap.DefaultDiscoveryOptions, "Host discovery options (discover-then-scan mode only)")
 	dnsServers := fs.String("dns-servers", "", "DNS servers for nmap (comma-separated)")
 	timingTemplate := fs.String("timing-template", "", "Nmap timing template (0-5)")
 	jitterMax := fs.Int("jitter-max", 0, "Maximum jitter seconds before each scan (0 = disabled)")
 	noRDNS := fs.Bool("no-rdns", false, "Disable reverse DNS resolution (-n)")
+	scriptsDir := fs.String("scripts", "", "Directory of custom NSE scripts to upload; every task runs them via --script")
 	format := fs.String("format", "text", "Output format: text or json")
 	cloudFlag := fs.String("cloud", "", "Cloud provider: "+cloud.SupportedKindsText()+" (default: from config or aws)")
 
 	outDir := fs.String("out", "", "Download results/artifacts to this directory after completion")
+	scopeFile := fs.String("scope", "", "Engagement scope file; out-of-scope targets are rejected (default: from config)")
+	splitCIDR := fs.Int("split-cidr", -1, "Split IPv4 CIDRs wider than /N into /N blocks (default: module setting, 24 for discover-then-scan; 0 disables)")
+	expandCIDR := fs.Bool("expand-cidr", false, "Expand CIDRs and ranges into individual addresses")
+	planOnly := fs.Bool("plan", false, "Plan the scan and estimate runtime and cost without touching the cloud; saves the plan")
+	planOut := fs.String("plan-out", "", "Where --plan saves the plan (default: <config-dir>/plans/<job-id>.json)")
+	fromPlan := fs.String("from-plan", "", "Run a plan saved by --plan (file path or job ID)")
+	fromMasscan := fs.String("from-masscan-job", "", "Scan exactly the open ports a masscan job found on each host (default options: "+nmap.DefaultHandoffOptions+")")
 
 	// Lifecycle flags.
 	noDeploy := fs.Bool("no-deploy", false, "Fail instead of deploying or redeploying infrastructure")
 	autoApprove := fs.Bool("auto-approve", false, "Skip deploy confirmation prompts when lifecycle requires deploy")
 	destroyAfter := fs.Bool("destroy-after", false, "Destroy infrastructure after the run completes")
+	budget := addBudgetFlags(fs)
+	window := addWindowFlags(fs)
+	polite := addPolitenessFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
+	// A saved plan fixes the tasks and runtime settings.
+	var saved *runner.PlanFile
+	if *fromPlan != "" {
+		if *planOnly {
+			return fmt.Errorf("--plan and --from-plan cannot be combined")
+		}
+		if *inputFile != "" || *fromMasscan != "" {
+			return fmt.Errorf("--from-plan replaces --file and --from-masscan-job")
+		}
+		var err error
+		saved, err = loadPlanForTool(*fromPlan, true)
+		if err != nil {
+			return err
+		}
+		*workers, *computeMode, *cloudFlag = saved.Workers, saved.ComputeMode, saved.Cloud
+		if saved.Mode != "" {
+			*mode, *portChunks = saved.Mode, saved.PortChunks
+		}
+		*inputFile = *fromPlan
+		*fromMasscan = saved.SourceJobID
+	}
+	if *fromMasscan != "" && saved == nil {
+		if *inputFile != "" {
+			return fmt.Errorf("--from-masscan-job replaces --file")
+		}
+		if *mode == nmap.ModeDiscoverThenScan {
+			return fmt.Errorf("--from-masscan-job already knows the live hosts; use --mode target-only or target-ports")
+		}
+		if !flagsSet(fs)["default-options"] {
+			*defaultOptions = nmap.DefaultHandoffOptions
+		}
+		*inputFile = "masscan job " + *fromMasscan
+	}
+
 	// Resolve defaults from operator config.
 	opCfg, _ := operator.LoadConfig()
 	*workers = operator.ResolveWorkers(*workers, opCfg)
@@ -77,6 +122,21 @@ func runNmap(args []string, log logger.Logger) error {
 	if err != nil {
 		return err
 	}
+	if saved != nil {
+		placementPolicy = saved.Placement
+	}
+	jobBudget, err := budget.resolve(opCfg)
+	if err != nil {
+		return err
+	}
+	jobWindow, err := window.resolve(opCfg, saved)
+	if err != nil {
+		return err
+	}
+	jobPoliteness, err := polite.resolve(saved)
+	if err != nil {
+		return err
+	}
 
 	cloudKind, err := resolveCLICloud(*cloudFlag, opCfg)
 	if err != nil {
@@ -95,50 +155,149 @@ func runNmap(args []string, log logger.Logger) error {
 	if *workers <= 0 {
 		return fmt.Errorf("--workers must be positive")
 	}
-	if *mode != "target-only" && *mode != "target-ports" {
-		return fmt.Errorf("--mode must be target-only or target-ports")
+	if *mode != nmap.ModeTargetOnly && *mode != nmap.ModeTargetPorts && *mode != nmap.ModeDiscoverThenScan {
+		return fmt.Errorf("--mode must be target-only, target-ports or discover-then-scan")
 	}
 	if *portChunks <= 0 {
 		return fmt.Errorf("--port-chunks must be positive")
 	}
+	if *splitCIDR > 32 {
+		return fmt.Errorf("--split-cidr must be between 0 and 32")
+	}
 
-	content, err := os.ReadFile(*inputFile)
+	reg, err := modules.NewDefaultRegistry()
+	if err != nil {
+		return fmt.Errorf("loading module registry: %w", err)
+	}
+	mod, err := reg.Get("nmap")
+	if err != nil {
+		return err
+	}
+	assets, err := resolveAssets("scripts", "scripts", *scriptsDir, mod, saved)
 	if err != nil {
-		return fmt.Errorf("reading target file: %w", err)
+		return err
 	}
 
-	// Parse targets.
-	scanner := nmap.NewScanner(log)
-	tasks := scanner.ParseTargetsWithMode(string(content), *defaultOptions, *mode, *portChunks)
+	var (
+		tasks []nmap.ScanTask
+		sc    *scope.Scope
+	)
+	if saved != nil {
+		tasks = runner.NmapScanTasks(saved.Tasks)
+		// Run against the scope the plan was checked with.
+		if sc, err = saved.ScopeValue(); err != nil {
+			return err
+		}
+	} else {
+		var lines string
+		if *fromMasscan != "" {
+			store, err := operator.NewJobStore()
+			if err != nil {
+				return fmt.Errorf("opening job store: %w", err)
+			}
+			entries, err := masscanJobEntries(mainContext(), store, *fromMasscan, *cloudFlag, opCfg, log)
+			if err != nil {
+				return err
+			}
+			lines = masscanTargetLines(entries, *defaultOptions)
+		} else {
+			entries, err := targets.ImportFile(*inputFile, *inputFormat)
+			if err != nil {
+				return err
+			}
+			lines = nmapTargetLines(entries, *defaultOptions)
+		}
+
+		normalized, err := normalizeNmapTargets(lines, nmapTargetOptions(mod, *mode, *splitCIDR, *expandCIDR))
+		if err != nil {
+			return err
+		}
 
-	// Inject nmap-specific options into each task at enqueue time (producer-side).
-	if *noRDNS {
-		for i := range tasks {
-			tasks[i].Options = "-n " + tasks[i].Options
+		// Parse targets.
+		scanner := nmap.NewScanner(log)
+		if *mode == nmap.ModeDiscoverThenScan {
+			tasks = scanner.ParseDiscoveryTasks(normalized, *defaultOptions, *discoveryOptions)
+		} else {
+			tasks = scanner.ParseTargetsWithMode(normalized, *defaultOptions, *mode, *portChunks)
 		}
-	}
-	if *timingTemplate != "" {
-		for i := range tasks {
-			tasks[i].Options = fmt.Sprintf("-T%s %s", *timingTemplate, tasks[i].Options)
+
+		// Inject nmap-specific options into each task at enqueue time
+		// (producer-side). Discovery tasks carry them to their port scan.
+		inject := func(prefix string) {
+			for i := range tasks {
+				tasks[i].Options = prefix + " " + tasks[i].Options
+				if tasks[i].PortOptions != "" {
+					tasks[i].PortOptions = prefix + " " + tasks[i].PortOptions
+				}
+			}
 		}
-	}
-	if *dnsServers != "" {
-		for i := range tasks {
-			tasks[i].Options = fmt.Sprintf("--dns-servers %s %s", *dnsServers, tasks[i].Options)
+		if *noRDNS {
+			inject("-n")
+		}
+		if *timingTemplate != "" {
+			inject("-T" + *timingTemplate)
+		}
+		if *dnsServers != "" {
+			inject("--dns-servers " + *dnsServers)
 		}
-	}
 
-	if len(tasks) == 0 {
-		return fmt.Errorf("no targets found in %s", *inputFile)
+		if len(tasks) == 0 {
+			return fmt.Errorf("no targets found in %s", *inputFile)
+		}
+
+		// Enforce engagement scope before any lifecycle side effects.
+		sc, err = operator.ResolveScope(*scopeFile, opCfg)
+		if err != nil {
+			return err
+		}
+		if sc != nil {
+			if err := sc.CheckAll(nmapTaskTargets(tasks)); err != nil {
+				return err
+			}
+			logStatus("Scope: all targets in scope (%d include, %d exclude rules)", len(sc.Include()), len(sc.Exclude()))
+		}
 	}
+
 	jobID := jobs.NewID("nmap")
+	if saved != nil {
+		jobID = saved.JobID
+	}
 	for i := range tasks {
 		tasks[i].JobID = jobID
 	}
-	if *mode == "target-ports" {
+	if *planOnly {
+		pf := newPlanFile(jobID, "nmap", cloudKind, *workers, *computeMode, placementPolicy, sc)
+		pf.Window = jobWindow
+		pf.Politeness = jobPoliteness
+		pf.Options = *defaultOptions
+		pf.SourceJobID = *fromMasscan
+		if assets != nil {
+			packed, err := jobs.PlanAssets("nmap", jobID, *assets)
+			if err != nil {
+				return err
+			}
+			pf.Assets = runner.NewPlannedAssets(packed)
+		}
+		if *mode == nmap.ModeDiscoverThenScan {
+			// Only discovery can be planned; the port scan depends on
+			// which hosts are up.
+			pf.Mode, pf.PortChunks = *mode, *portChunks
+			pf.Unit = "discovery blocks"
+		}
+		pf.Tasks = runner.NmapTasks(tasks)
+		if pf.Unit == "" {
+			pf.Unit = "targets"
+		}
+		pf.TaskCount = len(tasks)
+		return finishPlan(os.Stdout, pf, *planOut, *format)
+	}
+	switch *mode {
+	case nmap.ModeTargetPorts:
 		groups := countGroups(tasks)
 		logStatus("Mode: target-ports — %d target groups, %d total tasks (%d chunks/target) [job %s]", groups, len(tasks), *portChunks, jobID)
-	} else {
+	case nmap.ModeDiscoverThenScan:
+		logStatus("Mode: discover-then-scan — %d discovery tasks, then %d port chunks per live host [job %s]", len(tasks), *portChunks, jobID)
+	default:
 		logStatus("Parsed %d targets from %s [job %s]", len(tasks), *inputFile, jobID)
 	}
 
@@ -146,6 +305,10 @@ func runNmap(args []string, log logger.Logger) error {
 
 	// Track the job.
 	tracker := newTracker()
+	if err := checkBudgetTracker(jobBudget, tracker); err != nil {
+		return err
+	}
+	limits := jobLimits{Budget: jobBudget, Window: jobWindow, Politeness: jobPoliteness}
 	cleanupPolicy := "reuse"
 	if *destroyAfter {
 		cleanupPolicy = "destroy-after"
@@ -187,6 +350,14 @@ func runNmap(args []string, log logger.Logger) error {
 			"sqs_queue_url":  shCfg.QueueID,
 			"s3_bucket_name": shCfg.Bucket,
 		}
+	} else if cloudKind.IsLocal() {
+		// Local: no infrastructure — storage, queue, and workers run here.
+		localCfg := factory.LocalConfigFromEnv()
+		bucket = localCfg.Bucket
+		outputs = map[string]string{
+			"sqs_queue_url":  localCfg.QueueID,
+			"s3_bucket_name": localCfg.Bucket,
+		}
 	} else {
 		// AWS: resolve tool config and ensure infrastructure.
 		toolCfg, err = infra.ResolveToolConfig("nmap")
@@ -216,6 +387,10 @@ func runNmap(args []string, log logger.Logger) error {
 		ComputeMode:           *computeMode,
 		Cloud:                 string(cloudKind),
 		CleanupPolicy:         cleanupPolicy,
+		Budget:                recordBudget(jobBudget),
+		Window:                jobWindow,
+		Politeness:            jobPoliteness,
+		Assets:                assets,
 		Bucket:                bucket,
 		Placement:             placementPolicy,
 		ExpectedWorkerVersion: outputs["docker_image"],
@@ -227,9 +402,21 @@ func runNmap(args []string, log logger.Logger) error {
 		NATSClientCertPEM:     outputs["nats_operator_client_cert_pem"],
 		NATSClientKeyPEM:      outputs["nats_operator_client_key_pem"],
 	})
+	if *fromMasscan != "" {
+		if store := tracker.Store(); store != nil {
+			_ = store.Link(*fromMasscan, jobID)
+		}
+	}
+	if !jobBudget.IsZero() {
+		logStatus("Budget: %s", jobBudget)
+	}
 
 	// Run the scan.
-	started, scanErr := runNmapScan(ctx, tasks, *workers, *computeMode, *jitterMax, *format, outputs, log, tracker, jobID, placementPolicy, cloudKind)
+	discoverChunks := 0
+	if *mode == nmap.ModeDiscoverThenScan {
+		discoverChunks = *portChunks
+	}
+	started, scanErr := runNmapScan(ctx, tasks, discoverChunks, *workers, *computeMode, *jitterMax, *format, outputs, log, tracker, jobID, placementPolicy, jobs.JobConfig{Assets: assets, Scope: sc}, cloudKind, limits)
 
 	if scanErr != nil {
 		_ = tracker.Fail(jobID, scanErr)
@@ -246,6 +433,7 @@ func runNmap(args []string, log logger.Logger) error {
 		if provErr != nil {
 			return fmt.Errorf("building cloud provider for export: %w", provErr)
 		}
+		defer closeProvider(exportProvider)
 		storage := exportProvider.Storage()
 
 		result, exportErr := operator.ExportJob(ctx, storage, bucket, "nmap", jobID, *outDir)
@@ -254,6 +442,9 @@ func runNmap(args []string, log logger.Logger) error {
 		}
 		exportDir = result.Dir
 		logStatus("Exported %d results, %d artifacts to %s", result.ResultCount, result.ArtifactCount, result.Dir)
+		if m := result.Merge; m != nil {
+			logStatus("Merged %d reports into %d target reports and a job report in %s", m.Reports, m.Targets, m.Dir)
+		}
 
 		// Record the local output path in the job record.
 		if store := tracker.Store(); store != nil {
@@ -262,14 +453,16 @@ func runNmap(args []string, log logger.Logger) error {
 				_ = store.Update(rec)
 			}
 		}
+		indexExport(ctx, jobID, result.Dir, log)
 	}
 
 	// Destroy only after execution has actually started and export is done.
-	if *destroyAfter && started {
-		if cloudKind.IsSelfhostedFamily() && !cloudKind.IsProviderNative() {
+	// A budget breach destroys too when the budget asks for it.
+	if (*destroyAfter || destroyOnBudget(jobBudget, scanErr)) && started {
+		if (cloudKind.IsSelfhostedFamily() && !cloudKind.IsProviderNative()) || cloudKind.IsLocal() {
 			logStatus("Skipping destroy: %s does not support auto-destroy", cloudKind.Canonical())
 		} else if toolCfg != nil {
-			logStatus("Destroying infrastructure (--destroy-after)...")
+			logStatus("Destroying infrastructure...")
 			if destroyErr := infra.RunDestroy(ctx, toolCfg, os.Stderr, log); destroyErr != nil {
 				if scanErr != nil {
 					return fmt.Errorf("scan failed: %w; additionally, destroy failed: %v", scanErr, destroyErr)
@@ -287,7 +480,10 @@ func runNmap(args []string, log logger.Logger) error {
 	return scanErr
 }
 
-func runNmapScan(ctx context.Context, tasks []nmap.ScanTask, workers int, computeMode string, jitterMax int, format string, outputs map[string]string, log logger.Logger, tracker *operator.Tracker, jobID string, placementPolicy fleet.PlacementPolicy, cloudKind cloud.Kind) (bool, error) {
+// runNmapScan runs tasks on the runtime described by outputs. A positive
+// discoverChunks makes tasks host discovery for a discover-then-scan job.
+// cfg carries the job's scope and assets.
+func runNmapScan(ctx context.Context, tasks []nmap.ScanTask, discoverChunks int, workers int, computeMode string, jitterMax int, format string, outputs map[string]string, log logger.Logger, tracker *operator.Tracker, jobID string, placementPolicy fleet.PlacementPolicy, cfg jobs.JobConfig, cloudKind cloud.Kind, limits jobLimits) (bool, error) {
 	queueURL := outputs["sqs_queue_url"]
 	bucket := outputs["s3_bucket_name"]
 	if queueURL == "" || bucket == "" {
@@ -304,142 +500,50 @@ func runNmapScan(ctx context.Context, tasks []nmap.ScanTask, workers int, comput
 	if provErr != nil {
 		return false, fmt.Errorf("building cloud provider: %w", provErr)
 	}
-	return runNmapScanWithDeps(ctx, tasks, workers, computeMode, jitterMax, format, outputs, provider.Queue(), provider.Storage(), provider.Compute(), tracker, jobID, placementPolicy, cloudKind)
+	defer closeProvider(provider)
+	if discoverChunks > 0 {
+		return runNmapDiscoveryWithDeps(ctx, tasks, discoverChunks, workers, computeMode, jitterMax, format, outputs, provider.Queue(), provider.Storage(), provider.Compute(), tracker, jobID, placementPolicy, cfg, cloudKind, limits)
+	}
+	return runNmapScanWithDeps(ctx, tasks, workers, computeMode, jitterMax, format, outputs, provider.Queue(), provider.Storage(), provider.Compute(), tracker, jobID, placementPolicy, cfg, cloudKind, limits)
 }
 
-func runNmapScanWithDeps(ctx context.Context, tasks []nmap.ScanTask, workers int, computeMode string, jitterMax int, format string, outputs map[string]string, queue cloud.Queue, storage cloud.Storage, compute cloud.Compute, tracker *operator.Tracker, jobID string, placementPolicy fleet.PlacementPolicy, cloudKind ...cloud.Kind) (bool, error) {
+func runNmapScanWithDeps(ctx context.Context, tasks []nmap.ScanTask, workers int, computeMode string, jitterMax int, format string, outputs map[string]string, queue cloud.Queue, storage cloud.Storage, compute cloud.Compute, tracker *operator.Tracker, jobID string, placementPolicy fleet.PlacementPolicy, cfg jobs.JobConfig, kind cloud.Kind, limits jobLimits) (bool, error) {
 	queueURL := outputs["sqs_queue_url"]
 	bucket := outputs["s3_bucket_name"]
 	if queueURL == "" || bucket == "" {
 		return false, fmt.Errorf("terraform outputs missing sqs_queue_url or s3_bucket_name")
 	}
-
-	// Enqueue targets.
-	logStatus("Enqueueing %d targets...", len(tasks))
-	enqueueCtx, enqueueCancel := context.WithTimeout(ctx, enqueueTimeout)
-	defer enqueueCancel()
-
-	const sqsMaxPayload = 256 * 1024 // 256 KB SQS message size limit
-	bodies := make([]string, len(tasks))
-	for i, t := range tasks {
-		gt := worker.Task{
-			ToolName:    "nmap",
-			JobID:       t.JobID,
-			Target:      t.Target,
-			Options:     t.Options,
-			GroupID:     t.GroupID,
-			ChunkIdx:    t.ChunkIdx,
-			TotalChunks: t.TotalChunks,
-		}
-		b, err := json.Marshal(gt)
-		if err != nil {
-			return false, fmt.Errorf("marshaling task %d: %w", i, err)
-		}
-		if len(b) > sqsMaxPayload {
-			return false, fmt.Errorf("task %d exceeds SQS 256KB limit (%d bytes)", i, len(b))
-		}
-		bodies[i] = string(b)
-	}
-	if err := queue.SendBatch(enqueueCtx, queueURL, bodies); err != nil {
-		return false, fmt.Errorf("enqueueing targets: %w", err)
+	r, err := newCLIRunner(queue, storage, compute, tracker, kind, outputs, bucket, queueURL, workers, computeMode, jitterMax, placementPolicy, limits)
+	if err != nil {
+		return false, err
 	}
-	logStatus("Enqueued %d targets", len(tasks))
 
-	_ = tracker.UpdatePhase(jobID, operator.PhaseLaunching)
-
-	// Launch workers.
-	logStatus("Launching %d workers (mode: %s)...", workers, computeMode)
-	launchCtx, launchCancel := context.WithTimeout(ctx, launchTimeout)
-	defer launchCancel()
-
-	if len(cloudKind) > 0 && cloudKind[0].IsProviderNative() {
-		ready, err := waitForProviderNativeFleetFunc(launchCtx, cloudKind[0], outputs, placementPolicy)
-		if err != nil {
-			return false, err
-		}
-		logStatus("Using provider-native %s fleet (%d eligible workers, policy: %s)", cloudKind[0].Canonical(), ready, placementPolicy.Summary())
-	} else {
-		containerName := "nmap-worker"
-		workerEnv := map[string]string{
-			"QUEUE_URL":          queueURL,
-			"S3_BUCKET":          bucket,
-			"JITTER_MAX_SECONDS": strconv.Itoa(jitterMax),
-			"TOOL_NAME":          "nmap",
-		}
-
-		// Selfhosted only supports RunContainer (no spot instances).
-		isSelfhosted := len(cloudKind) > 0 && cloudKind[0].IsSelfhostedFamily()
-		useSpot := !isSelfhosted && resolveComputeMode(computeMode, workers)
-		if useSpot {
-			ecrURL := outputs["ecr_repo_url"]
-			userData := awscloud.GenerateUserData(awscloud.UserDataOpts{
-				ECRRepoURL: ecrURL,
-				ImageTag:   "latest",
-				Region:     regionFromECR(ecrURL),
-				EnvVars:    workerEnv,
-			})
-			ids, err := compute.RunSpotInstances(launchCtx, cloud.SpotOpts{
-				AMI:             outputs["ami_id"],
-				Count:           workers,
-				SecurityGroups:  []string{outputs["security_group_id"]},
-				SubnetIDs:       splitOutputList(outputs["subnet_ids"]),
-				InstanceProfile: outputs["instance_profile_arn"],
-				UserData:        userData,
-				Tags: map[string]string{
-					"Project": "heph4estus",
-					"Tool":    "nmap",
-				},
-			})
-			if err != nil {
-				return false, fmt.Errorf("launching spot instances: %w", err)
-			}
-			logStatus("Launched %d spot instances", len(ids))
-		} else {
-			_, err := compute.RunContainer(launchCtx, cloud.ContainerOpts{
-				Cluster:        outputs["ecs_cluster_name"],
-				TaskDefinition: outputs["task_definition_arn"],
-				ContainerName:  containerName,
-				Subnets:        splitOutputList(outputs["subnet_ids"]),
-				SecurityGroups: []string{outputs["security_group_id"]},
-				Env:            workerEnv,
-				Count:          workers,
-			})
-			if err != nil {
-				return false, fmt.Errorf("launching workers: %w", err)
-			}
-			logStatus("Launched %d workers", workers)
-		}
+	// Port splitting is nmap-specific, so hand the runner pre-built tasks.
+	cfg.ToolName, cfg.Tasks = "nmap", runner.NmapTasks(tasks)
+	plan, err := r.Plan(cfg, jobID)
+	if err != nil {
+		return false, err
+	}
+	if err := r.Start(ctx, plan); err != nil {
+		return false, err
 	}
-
-	_ = tracker.UpdatePhase(jobID, operator.PhaseScanning)
 
 	// Poll for progress.
 	logStatus("Scanning...")
 	startTime := time.Now()
 	totalTargets := len(tasks)
-	scanPrefix := jobs.ResultPrefix("nmap", jobID)
-
-	for {
-		count, err := storage.Count(ctx, bucket, scanPrefix)
-		if err != nil {
-			logStatus("Warning: progress check failed: %v", err)
-		} else {
-			elapsed := time.Since(startTime).Truncate(time.Second)
-			pct := float64(count) / float64(totalTargets) * 100
-			logStatus("Progress: %d/%d (%.1f%%) — elapsed %s", count, totalTargets, pct, elapsed)
-
-			if count >= totalTargets {
-				break
-			}
-		}
-		time.Sleep(pollInterval)
+	err = r.Wait(ctx, "nmap", jobID, totalTargets, func(p runner.Progress) {
+		logStatus("Progress: %d/%d (%.1f%%) — elapsed %s", p.Completed, p.Total, p.Percent(), p.Elapsed.Truncate(time.Second))
+	})
+	if err != nil {
+		return true, err
 	}
 
 	elapsed := time.Since(startTime).Truncate(time.Second)
 	logStatus("Scan complete: %d targets in %s", totalTargets, elapsed)
 
 	// Output results.
-	return true, outputResults(ctx, storage, bucket, scanPrefix, format)
+	return true, outputResults(ctx, storage, bucket, jobs.ResultPrefix("nmap", jobID), format)
 }
 
 func outputResults(ctx context.Context, storage cloud.Storage, bucket, prefix, format string) error {
@@ -485,25 +589,12 @@ func outputResults(ctx context.Context, storage cloud.Storage, bucket, prefix, f
 }
 
 func resolveComputeMode(mode string, workers int) bool {
-	switch mode {
-	case "spot":
-		return true
-	case "fargate":
-		return false
-	default: // "auto"
-		return workers >= spotThreshold
-	}
+	return runner.UseSpot(cloud.KindAWS, mode, workers)
 }
 
 // regionFromECR extracts the AWS region from an ECR repo URL.
 func regionFromECR(url string) string {
-	parts := strings.Split(url, ".")
-	for i, p := range parts {
-		if p == "ecr" && i+1 < len(parts) {
-			return parts[i+1]
-		}
-	}
-	return "us-east-1"
+	return runner.RegionFromECR(url)
 }
 
 func extractTargetFromKey(key string) string {
@@ -521,16 +612,7 @@ func countGroups(tasks []nmap.ScanTask) int {
 }
 
 func splitOutputList(s string) []string {
-	s = strings.Trim(s, "[]")
-	parts := strings.Split(s, " ")
-	var result []string
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p != "" {
-			result = append(result, p)
-		}
-	}
-	return result
+	return runner.SplitOutputList(s)
 }
 
 // logStatus prints a status line to stderr (keeps stdout clean for results).
@@ -538,6 +620,85 @@ func logStatus(format string, args ...interface{}) {
 	_, _ = fmt.Fprintf(os.Stderr, format+"\n", args...)
 }
 
+// nmapTargetLines renders imported entries as "<target> [options]" lines.
+// Entries without options that carry discovered ports (from an nmap XML
+// import) are scanned on just those ports with the default options.
+func nmapTargetLines(entries []targets.Entry, defaultOptions string) string {
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		if e.Options == "" && e.Metadata["ports"] != "" {
+			e.Options = strings.TrimSpace(defaultOptions + " -p " + e.Metadata["ports"])
+		}
+		lines[i] = e.Line()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// nmapTargetOptions is moduleTargetOptions for an nmap job in mode.
+// Discovery splits wide CIDRs into nmap.DiscoverySplitCIDR blocks unless
+// splitCIDR or the module sets a size.
+func nmapTargetOptions(mod *modules.ModuleDefinition, mode string, splitCIDR int, expandCIDR bool) targets.Options {
+	opts := moduleTargetOptions(mod, splitCIDR, expandCIDR)
+	if mode == nmap.ModeDiscoverThenScan && splitCIDR < 0 && opts.SplitCIDR == 0 {
+		opts.SplitCIDR = nmap.DiscoverySplitCIDR
+	}
+	return opts
+}
+
+// normalizeNmapTargets normalizes the target at the start of each line of an
+// nmap target file, keeping any per-line options attached to every target
+// the line expands or splits into. Identical lines are dropped.
+func normalizeNmapTargets(content string, opts targets.Options) (string, error) {
+	var (
+		out      []string
+		problems []string
+		total    targets.Stats
+	)
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		rest := strings.Join(fields[1:], " ")
+		total.Input++
+		normalized, err := targets.NormalizeTarget(fields[0], opts, &total)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		for _, t := range normalized {
+			l := strings.TrimSpace(t + " " + rest)
+			if seen[l] {
+				total.Duplicates++
+				continue
+			}
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("validating targets: %w", targets.JoinProblems(problems))
+	}
+	total.Output = len(out)
+	logTargetStats(total)
+	return strings.Join(out, "\n"), nil
+}
+
+// nmapTaskTargets returns the distinct targets of tasks in order.
+func nmapTaskTargets(tasks []nmap.ScanTask) []string {
+	seen := make(map[string]bool, len(tasks))
+	out := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		if !seen[t.Target] {
+			seen[t.Target] = true
+			out = append(out, t.Target)
+		}
+	}
+	return out
+}
+
 // printRunSummary writes a concise post-run summary to stderr.
 func printRunSummary(jobID, tool string, reused bool, cleanupPolicy, localOutputDir string) {
 	_, _ = fmt.Fprintln(os.Stderr, "")
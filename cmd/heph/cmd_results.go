@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/logger"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/tools/dns"
+	"heph4estus/internal/tools/nmap"
+)
+
+func runResults(args []string, log logger.Logger) error {
+	if len(args) > 0 && args[0] == "merge" {
+		return runResultsMerge(args[1:], os.Stdout, log)
+	}
+	return runResultsQuery(args, os.Stdout, log)
+}
+
+// runResultsMerge merges the chunked nmap XML reports of a job into one
+// report per target and one for the whole job, the JSONL reports of a
+// nuclei job into one findings file without repeats across template shards,
+// or the reports of a job that probed for wildcard DNS into one answers
+// file without wildcard answers.
+func runResultsMerge(args []string, w io.Writer, log logger.Logger) error {
+	fs := flag.NewFlagSet("results merge", flag.ContinueOnError)
+	jobID := fs.String("job-id", "", "Merge the reports of this nmap, nuclei or wildcard-probed DNS job from cloud storage")
+	dir := fs.String("dir", "", "Merge the reports of a locally exported job directory instead (<out>/<tool>/<job_id>)")
+	out := fs.String("out", "", "Directory to write merged reports to (default: <dir>/merged, or ./<job_id>-merged)")
+	format := fs.String("format", "all", "Comma-separated nmap output formats: "+strings.Join(nmap.MergeFormats, ", ")+", or all")
+	cloudFlag := fs.String("cloud", "", "Override the cloud provider the job's reports are read from (default: job record or aws)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*jobID == "") == (*dir == "") {
+		return fmt.Errorf("exactly one of --job-id or --dir is required; usage: heph results merge --job-id <id> [--out DIR] [--format xml,gnmap,json]")
+	}
+	formats, err := parseMergeFormats(*format)
+	if err != nil {
+		return err
+	}
+
+	var res *operator.MergeResult
+	if *dir != "" {
+		if _, statErr := os.Stat(filepath.Join(*dir, "artifacts", dns.ProbeGroup)); statErr == nil {
+			res, err = operator.MergeDNSExport(*dir, *out)
+		} else {
+			res, err = operator.MergeNmapExport(*dir, *out, formats)
+		}
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			if res, err = operator.MergeNucleiExport(*dir, *out); err != nil {
+				return err
+			}
+		}
+		if res == nil {
+			return fmt.Errorf("no nmap XML reports or nuclei JSONL reports found under %s", filepath.Join(*dir, "artifacts"))
+		}
+	} else {
+		store, err := operator.NewJobStore()
+		if err != nil {
+			return fmt.Errorf("opening job store: %w", err)
+		}
+		rec, err := store.Load(*jobID)
+		if err != nil {
+			return fmt.Errorf("%w — use --dir to merge an exported job", err)
+		}
+		if rec.ToolName != "nmap" && rec.ToolName != "nuclei" && !rec.WildcardProbe {
+			return fmt.Errorf("job %s is a %s job; only nmap, nuclei and wildcard-probed DNS reports can be merged", *jobID, rec.ToolName)
+		}
+		ctx := context.Background()
+		provider, err := jobProvider(ctx, rec, *cloudFlag, log)
+		if err != nil {
+			return err
+		}
+		defer closeProvider(provider)
+
+		ext, kind := ".xml", "nmap XML"
+		switch {
+		case rec.ToolName == "nuclei":
+			ext, kind = ".jsonl", "nuclei JSONL"
+		case rec.WildcardProbe:
+			ext, kind = ".jsonl", rec.ToolName+" JSONL"
+		}
+		reports, err := downloadReports(ctx, provider.Storage(), rec.Bucket, jobs.ArtifactPrefix(rec.ToolName, *jobID), ext)
+		if err != nil {
+			return err
+		}
+		if len(reports) == 0 {
+			return fmt.Errorf("job %s has no %s reports yet", *jobID, kind)
+		}
+		outDir := *out
+		if outDir == "" {
+			outDir = *jobID + "-merged"
+		}
+		switch {
+		case rec.ToolName == "nuclei":
+			res, err = operator.MergeNucleiReports(reports, outDir)
+		case rec.WildcardProbe:
+			res, err = operator.MergeDNSReports(reports, outDir)
+		default:
+			res, err = operator.MergeNmapReports(reports, outDir, formats)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, key := range res.Skipped {
+		log.Error("Warning: skipped unreadable report %s", key)
+	}
+	if len(res.Files) == 0 {
+		if len(res.Skipped) == 0 {
+			return fmt.Errorf("none of the %d reports held a finding", res.Reports)
+		}
+		return fmt.Errorf("none of the %d reports could be parsed", len(res.Skipped))
+	}
+	_, _ = fmt.Fprintln(w, mergeSummary(res))
+	if len(res.Wildcards) > 0 {
+		_, _ = fmt.Fprintf(w, "Wildcard DNS: %s\n", res.Wildcards)
+	}
+	return nil
+}
+
+// mergeSummary describes what a merge wrote.
+func mergeSummary(res *operator.MergeResult) string {
+	switch {
+	case res.Findings > 0:
+		return fmt.Sprintf("Merged %d reports into %d findings (%d duplicates dropped) in %s", res.Reports, res.Findings, res.Duplicates, res.Dir)
+	case res.Answers > 0 || res.Wildcard > 0:
+		return fmt.Sprintf("Merged %d reports into %d answers (%d wildcard answers dropped) in %s", res.Reports, res.Answers, res.Wildcard, res.Dir)
+	default:
+		return fmt.Sprintf("Merged %d reports into %d target reports and a job report in %s", res.Reports, res.Targets, res.Dir)
+	}
+}
+
+// parseMergeFormats parses --format: a comma-separated list of merge
+// formats, or "all".
+func parseMergeFormats(spec string) ([]string, error) {
+	if spec == "" || spec == "all" {
+		return nmap.MergeFormats, nil
+	}
+	var formats []string
+	for _, f := range strings.Split(spec, ",") {
+		f = strings.TrimSpace(strings.ToLower(f))
+		valid := false
+		for _, known := range nmap.MergeFormats {
+			if f == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("--format: unknown format %q (want %s, or all)", f, strings.Join(nmap.MergeFormats, ", "))
+		}
+		formats = append(formats, f)
+	}
+	return formats, nil
+}
+
+// downloadReports downloads every report with extension ext under prefix.
+func downloadReports(ctx context.Context, storage cloud.Storage, bucket, prefix, ext string) (map[string][]byte, error) {
+	keys, err := storage.List(ctx, bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", prefix, err)
+	}
+	reports := make(map[string][]byte)
+	for _, key := range keys {
+		if !strings.HasSuffix(strings.ToLower(key), ext) {
+			continue
+		}
+		data, err := storage.Download(ctx, bucket, key)
+		if err != nil {
+			return nil, fmt.Errorf("downloading %s: %w", key, err)
+		}
+		reports[strings.TrimPrefix(key, prefix)] = data
+	}
+	return reports, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"heph4estus/internal/operator"
+)
+
+func TestResultsMergeExportedDir(t *testing.T) {
+	jobDir := t.TempDir()
+	chunkDir := filepath.Join(jobDir, "artifacts", "10.0.0.1_line1")
+	if err := os.MkdirAll(chunkDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i, port := range []string{"22", "80"} {
+		report := `<nmaprun scanner="nmap"><host><status state="up"/><address addr="10.0.0.1" addrtype="ipv4"/><ports><port protocol="tcp" portid="` + port + `"><state state="open"/></port></ports></host></nmaprun>`
+		name := filepath.Join(chunkDir, "10.0.0.1_chunk"+string(rune('0'+i))+"_of_2_1.xml")
+		if err := os.WriteFile(name, []byte(report), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outDir := filepath.Join(t.TempDir(), "merged")
+	var out bytes.Buffer
+	if err := runResultsMerge([]string{"--dir", jobDir, "--out", outDir, "--format", "gnmap"}, &out, testLogger()); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if !strings.Contains(out.String(), "Merged 2 reports into 1 target reports") {
+		t.Errorf("output = %q", out.String())
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "job.gnmap"))
+	if err != nil {
+		t.Fatalf("reading job.gnmap: %v", err)
+	}
+	if !strings.Contains(string(data), "Ports: 22/open/tcp/////, 80/open/tcp/////") {
+		t.Errorf("job.gnmap = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "job.xml")); !os.IsNotExist(err) {
+		t.Errorf("job.xml written despite --format gnmap")
+	}
+}
+
+func TestResultsMergeErrors(t *testing.T) {
+	if err := runResultsMerge(nil, &bytes.Buffer{}, testLogger()); err == nil || !strings.Contains(err.Error(), "--job-id or --dir") {
+		t.Fatalf("expected flag error, got %v", err)
+	}
+	if err := runResultsMerge([]string{"--dir", t.TempDir(), "--format", "html"}, &bytes.Buffer{}, testLogger()); err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Fatalf("expected format error, got %v", err)
+	}
+	if err := runResultsMerge([]string{"--dir", t.TempDir()}, &bytes.Buffer{}, testLogger()); err == nil || !strings.Contains(err.Error(), "no nmap XML reports") {
+		t.Fatalf("expected empty dir error, got %v", err)
+	}
+	if err := run([]string{"results"}, testLogger()); err == nil {
+		t.Fatal("expected missing subcommand error")
+	}
+}
+
+func TestResultsMergeNucleiExportedDir(t *testing.T) {
+	jobDir := t.TempDir()
+	groupDir := filepath.Join(jobDir, "artifacts", "a.example_line1")
+	if err := os.MkdirAll(groupDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	finding := `{"template-id":"exposed-git","matched-at":"https://a.example/.git/config"}` + "\n"
+	for i, extra := range []string{"", `{"template-id":"tech","matcher-name":"nginx","matched-at":"https://a.example"}` + "\n"} {
+		name := filepath.Join(groupDir, "a.example_chunk"+string(rune('0'+i))+"_of_2_1.jsonl")
+		if err := os.WriteFile(name, []byte(finding+extra), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := runResultsMerge([]string{"--dir", jobDir}, &out, testLogger()); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if !strings.Contains(out.String(), "Merged 2 reports into 2 findings (1 duplicates dropped)") {
+		t.Errorf("output = %q", out.String())
+	}
+	data, err := os.ReadFile(filepath.Join(jobDir, "merged", "findings.jsonl"))
+	if err != nil {
+		t.Fatalf("reading findings.jsonl: %v", err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 2 {
+		t.Errorf("findings.jsonl has %d lines: %q", n, data)
+	}
+}
+
+// writeExportedJob writes an exported nuclei job directory with one
+// finding-bearing task and one failed task.
+func writeExportedJob(t *testing.T) string {
+	t.Helper()
+	jobDir := t.TempDir()
+	files := map[string]string{
+		"results/a.example_1.json":    `{"tool_name":"nuclei","job_id":"job-q","target":"a.example","output_key":"scans/nuclei/job-q/artifacts/a.example_1.jsonl"}`,
+		"results/b.example_2.json":    `{"tool_name":"nuclei","job_id":"job-q","target":"b.example","error":"exit status 1"}`,
+		"artifacts/a.example_1.jsonl": `{"template-id":"git-config","host":"https://a.example","matched-at":"https://a.example/.git/config","info":{"name":"Git Config","severity":"medium"}}` + "\n" + `{"template-id":"tech-nginx","host":"https://a.example:8443","matched-at":"https://a.example:8443","info":{"name":"Nginx | detect","severity":"info"}}` + "\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(jobDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return jobDir
+}
+
+func TestResultsQueryExportedDir(t *testing.T) {
+	jobDir := writeExportedJob(t)
+	tests := []struct {
+		args    []string
+		want    []string
+		notWant []string
+	}{
+		{args: nil, want: []string{"SEVERITY", "MEDIUM", "https://a.example/.git/config", "Git Config", "2 findings from 2 tasks (1 failed)"}},
+		{args: []string{"--severity", "medium,high"}, want: []string{"git-config"}, notWant: []string{"tech-nginx"}},
+		{args: []string{"--port", "8443", "--format", "jsonl"}, want: []string{`"check":"tech-nginx"`, `"job_id":"job-q"`}, notWant: []string{"git-config"}},
+		{args: []string{"--format", "csv"}, want: []string{"tool,job_id,task_id,type,severity", "nuclei,job-q,a.example,vulnerability,medium"}},
+		{args: []string{"--format", "markdown"}, want: []string{"| SEVERITY | TYPE |", "| --- |", `Nginx \| detect`}},
+		{args: []string{"--status", "error"}, want: []string{"TARGET", "b.example", "ERROR", "exit status 1"}, notWant: []string{"a.example"}},
+		{args: []string{"--view", "tasks", "--target", "A.EXAMPLE", "--format", "csv"}, want: []string{"a.example,,OK,2,,"}, notWant: []string{"b.example"}},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := runResultsQuery(append([]string{"--dir", jobDir}, tt.args...), &out, testLogger()); err != nil {
+			t.Fatalf("results %v: %v", tt.args, err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("results %v: output lacks %q:\n%s", tt.args, want, out.String())
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(out.String(), notWant) {
+				t.Errorf("results %v: output has %q:\n%s", tt.args, notWant, out.String())
+			}
+		}
+	}
+}
+
+func TestResultsQueryByJobIDReadsExport(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	store, err := operator.NewJobStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(&operator.JobRecord{JobID: "job-q", ToolName: "nuclei", LocalOutputDir: writeExportedJob(t)}); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := runResultsQuery([]string{"--job-id", "job-q", "--severity", "medium"}, &out, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "git-config") {
+		t.Errorf("output = %s", out.String())
+	}
+}
+
+func TestResultsQueryErrors(t *testing.T) {
+	dir := writeExportedJob(t)
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{nil, "--job-id or --dir"},
+		{[]string{"--dir", dir, "--format", "html"}, "--format must be one of"},
+		{[]string{"--dir", dir, "--status", "maybe"}, "--status must be ok or error"},
+		{[]string{"--dir", dir, "--severity", "urgent"}, "unknown severity"},
+		{[]string{"--dir", dir, "--view", "tasks", "--port", "80"}, "filter findings"},
+		{[]string{"--dir", t.TempDir()}, "not an exported job directory"},
+		{[]string{"bogus"}, "unknown subcommand"},
+	} {
+		if err := runResultsQuery(tt.args, &bytes.Buffer{}, testLogger()); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("results %v: error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
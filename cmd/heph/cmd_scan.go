@@ -6,12 +6,13 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"heph4estus/internal/cloud"
-	awscloud "heph4estus/internal/cloud/aws"
 	"heph4estus/internal/cloud/factory"
 	"heph4estus/internal/fleet"
 	"heph4estus/internal/infra"
@@ -19,6 +20,11 @@ import (
 	"heph4estus/internal/logger"
 	"heph4estus/internal/modules"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/politeness"
+	"heph4estus/internal/runner"
+	"heph4estus/internal/schedule"
+	"heph4estus/internal/scope"
+	"heph4estus/internal/targets"
 	wordlisttool "heph4estus/internal/tools/wordlist"
 	"heph4estus/internal/worker"
 )
@@ -27,9 +33,13 @@ func runScan(args []string, log logger.Logger) error {
 	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
 	tool := fs.String("tool", "", "Tool to run (e.g. httpx, nuclei, subfinder, ffuf)")
 	inputFile := fs.String("file", "", "Path to file containing targets (target_list modules)")
-	wordlistFile := fs.String("wordlist", "", "Path to wordlist file (wordlist modules)")
+	inputFormat := fs.String("input-format", "", "Target file format: "+strings.Join(targets.Formats(), ", ")+" (default: from file extension)")
+	wordlists := &wordlistFlag{}
+	fs.Var(wordlists, "wordlist", "Path to wordlist file (wordlist modules); repeat as NAME=path for the module's named wordlists")
+	wordlistFile := &wordlists.chunked
 	runtimeTarget := fs.String("target", "", "Runtime target / URL (wordlist modules, e.g. https://example.com/FUZZ)")
 	chunks := fs.Int("chunks", 0, "Number of wordlist chunks (default: auto-size from file size and workers)")
+	taskDuration := fs.Duration("task-duration", 0, "Size wordlist chunks adaptively so each task runs about this long; a calibration sample runs first")
 	options := fs.String("options", "", "Extra tool-specific options")
 	workers := fs.Int("workers", 0, "Number of worker tasks to launch (default: from config or 10)")
 	computeMode := fs.String("compute-mode", "", "Compute mode: auto, fargate, or spot (default: from config or auto)")
@@ -40,17 +50,65 @@ func runScan(args []string, log logger.Logger) error {
 	dualStackRequired := fs.Bool("dual-stack-required", false, "Require workers with both public IPv4 and IPv6-ready public IPv6")
 	format := fs.String("format", "text", "Output format: text or json")
 	outDir := fs.String("out", "", "Download results/artifacts to this directory after completion")
+	scopeFile := fs.String("scope", "", "Engagement scope file; out-of-scope targets are rejected (default: from config)")
+	splitCIDR := fs.Int("split-cidr", -1, "Split IPv4 CIDRs wider than /N into /N blocks (default: module setting; 0 disables)")
+	expandCIDR := fs.Bool("expand-cidr", false, "Expand CIDRs and ranges into individual addresses")
+	rewrite := addTransformFlags(fs)
+	shards := addTemplateShardFlags(fs)
+	noWildcardProbe := fs.Bool("no-wildcard-probe", false, "Skip the wildcard DNS probes that DNS tools run per zone")
+	templatesDir := fs.String("templates", "", "Directory of custom templates to upload; workers extract it for the module's {{assets}} (template shards are planned over it)")
+	planOnly := fs.Bool("plan", false, "Plan the job and estimate runtime and cost without touching the cloud; saves the plan")
+	planOut := fs.String("plan-out", "", "Where --plan saves the plan (default: <config-dir>/plans/<job-id>.json)")
+	fromPlan := fs.String("from-plan", "", "Run a plan saved by --plan (file path or job ID)")
 
 	// Lifecycle flags.
 	noDeploy := fs.Bool("no-deploy", false, "Fail instead of deploying or redeploying infrastructure")
 	autoApprove := fs.Bool("auto-approve", false, "Skip deploy confirmation prompts when lifecycle requires deploy")
 	destroyAfter := fs.Bool("destroy-after", false, "Destroy infrastructure after the run completes")
+	budget := addBudgetFlags(fs)
+	window := addWindowFlags(fs)
+	polite := addPolitenessFlags(fs)
+	globalRate := fs.Int("global-rate", 0, "Combined requests per second across all workers, divided among live workers (rate-limited tools only)")
 	cloudFlag := fs.String("cloud", "", "Cloud provider: "+cloud.SupportedKindsText()+" (default: from config or aws)")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
+	// A saved plan fixes the tool, inputs and runtime settings.
+	var saved *runner.PlanFile
+	if *fromPlan != "" {
+		if *planOnly {
+			return fmt.Errorf("--plan and --from-plan cannot be combined")
+		}
+		if *inputFile != "" || wordlists.given() || *runtimeTarget != "" || *chunks != 0 || *taskDuration != 0 {
+			return fmt.Errorf("--from-plan replaces --file, --wordlist, --target, --chunks and --task-duration")
+		}
+		var err error
+		saved, err = loadPlanForTool(*fromPlan, false)
+		if err != nil {
+			return err
+		}
+		if *tool != "" && *tool != saved.ToolName {
+			return fmt.Errorf("--tool %q does not match plan tool %q", *tool, saved.ToolName)
+		}
+		*tool, *workers, *computeMode, *cloudFlag = saved.ToolName, saved.Workers, saved.ComputeMode, saved.Cloud
+		if !flagsSet(fs)["global-rate"] {
+			*globalRate = saved.GlobalRate
+		}
+		if wl := saved.Wordlist; wl != nil {
+			*wordlistFile, *chunks = wl.Path, wl.Chunks
+			wordlists.named = wl.WholePaths()
+			if *taskDuration = wl.TaskDuration; *taskDuration > 0 {
+				*chunks = 0
+			}
+			*runtimeTarget, *options = saved.RuntimeTarget, saved.Options
+		} else {
+			// Saved tasks already carry the job-wide options.
+			*options = ""
+		}
+	}
+
 	// Resolve defaults from operator config.
 	opCfg, _ := operator.LoadConfig()
 	*workers = operator.ResolveWorkers(*workers, opCfg)
@@ -68,6 +126,25 @@ func runScan(args []string, log logger.Logger) error {
 	if err != nil {
 		return err
 	}
+	if saved != nil {
+		placementPolicy = saved.Placement
+	}
+	jobBudget, err := budget.resolve(opCfg)
+	if err != nil {
+		return err
+	}
+	jobWindow, err := window.resolve(opCfg, saved)
+	if err != nil {
+		return err
+	}
+	jobPoliteness, err := polite.resolve(saved)
+	if err != nil {
+		return err
+	}
+	transform, err := rewrite.resolve(saved)
+	if err != nil {
+		return err
+	}
 
 	cloudKind, err := resolveCLICloud(*cloudFlag, opCfg)
 	if err != nil {
@@ -86,6 +163,12 @@ func runScan(args []string, log logger.Logger) error {
 	if *workers <= 0 {
 		return fmt.Errorf("--workers must be positive")
 	}
+	if *splitCIDR > 32 {
+		return fmt.Errorf("--split-cidr must be between 0 and 32")
+	}
+	if *globalRate < 0 {
+		return fmt.Errorf("--global-rate must be positive")
+	}
 
 	// Load and validate the module from the registry.
 	reg, err := modules.NewDefaultRegistry()
@@ -96,12 +179,31 @@ func runScan(args []string, log logger.Logger) error {
 	if err != nil {
 		return fmt.Errorf("unknown tool: %q (available: %s)", *tool, strings.Join(reg.Names(), ", "))
 	}
+	if err := validateGlobalRate(mod, *globalRate, *workers); err != nil {
+		return err
+	}
+	assets, err := resolveAssets("templates", "templates", *templatesDir, mod, saved)
+	if err != nil {
+		return err
+	}
+	templateShards, err := shards.resolve(fs, mod, *options, assets, saved)
+	if err != nil {
+		return err
+	}
+	wildcardProbe := mod.ResolvesDNS() && !*noWildcardProbe
+	if saved != nil {
+		wildcardProbe = saved.WildcardProbe
+	}
 
 	// Validate flag combinations based on module input type.
+	var wholeWordlists map[string]string
 	if mod.InputType == modules.InputTypeWordlist {
 		if *inputFile != "" {
 			return fmt.Errorf("--file is not valid for wordlist tool %q — use --wordlist instead", *tool)
 		}
+		if wholeWordlists, err = wordlists.resolve(mod); err != nil {
+			return err
+		}
 		if *wordlistFile == "" {
 			return fmt.Errorf("--wordlist flag is required for tool %q", *tool)
 		}
@@ -111,36 +213,126 @@ func runScan(args []string, log logger.Logger) error {
 		if *chunks < 0 {
 			return fmt.Errorf("--chunks must be positive")
 		}
+		if *taskDuration < 0 {
+			return fmt.Errorf("--task-duration must be positive")
+		}
+		if *taskDuration > 0 && *chunks > 0 {
+			return fmt.Errorf("--task-duration and --chunks cannot be combined")
+		}
+		if opt := mod.PairsWordlists(*options); opt != "" && len(wholeWordlists) > 0 {
+			if *chunks > 1 || *taskDuration > 0 {
+				return fmt.Errorf("%s pairs the wordlists line by line, so %s cannot be split; drop --chunks and --task-duration", opt, mod.ChunkedWordlist())
+			}
+			logStatus("%s pairs the wordlists line by line; shipping %s as one chunk", opt, mod.ChunkedWordlist())
+			*chunks = 1
+		}
 	} else {
 		// target_list module
-		if *wordlistFile != "" {
+		if wordlists.given() {
 			return fmt.Errorf("--wordlist is not valid for target_list tool %q — use --file instead", *tool)
 		}
 		if *chunks != 0 {
 			return fmt.Errorf("--chunks is not valid for target_list tool %q", *tool)
 		}
+		if *taskDuration != 0 {
+			return fmt.Errorf("--task-duration is not valid for target_list tool %q", *tool)
+		}
 		if *runtimeTarget != "" {
 			return fmt.Errorf("--target is not valid for target_list tool %q", *tool)
 		}
-		if *inputFile == "" {
+		if rewrite.set(fs) {
+			return fmt.Errorf("wordlist rewrite flags are not valid for target_list tool %q", *tool)
+		}
+		if *inputFile == "" && saved == nil {
 			return fmt.Errorf("--file flag is required")
 		}
 	}
 
 	// Validate local inputs before any lifecycle side effects.
-	var targetContent string
-	var wordlistMeta *wordlisttool.Metadata
+	var targetEntries []targets.Entry
+	var (
+		wordlistMeta *wordlisttool.Metadata
+		wholeMeta    map[string]*wordlisttool.Metadata
+	)
 	if mod.InputType == modules.InputTypeWordlist {
-		wordlistMeta, err = preflightWordlistFile(*tool, *wordlistFile, *runtimeTarget, *options, *chunks, *workers)
+		wordlistMeta, err = preflightWordlistFile(*tool, *wordlistFile, *runtimeTarget, *options, *chunks, *workers, transform)
 		if err != nil {
 			return err
 		}
+		if wholeMeta, err = preflightWholeWordlists(wholeWordlists); err != nil {
+			return err
+		}
+		if err := preflightRuntimeTarget(mod, *runtimeTarget); err != nil {
+			return err
+		}
+	} else if saved != nil {
+		targetEntries = saved.Entries()
+		*inputFile = *fromPlan
 	} else {
-		targetContent, err = preflightTargetListFile(*inputFile)
+		targetEntries, err = preflightTargetListFile(*inputFile, *inputFormat, moduleTargetOptions(mod, *splitCIDR, *expandCIDR))
 		if err != nil {
 			return err
 		}
 	}
+	var sc *scope.Scope
+	if saved != nil {
+		// Run against the scope the plan was checked with.
+		sc, err = saved.ScopeValue()
+	} else {
+		sc, err = preflightScope(*scopeFile, opCfg, entryTargets(targetEntries), *runtimeTarget)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *planOnly {
+		pf := newPlanFile(jobs.NewID(*tool), *tool, cloudKind, *workers, *computeMode, placementPolicy, sc)
+		pf.Window = jobWindow
+		pf.Politeness = jobPoliteness
+		pf.GlobalRate = *globalRate
+		pf.Options = *options
+		if assets != nil {
+			packed, err := jobs.PlanAssets(*tool, pf.JobID, *assets)
+			if err != nil {
+				return err
+			}
+			pf.Assets = runner.NewPlannedAssets(packed)
+		}
+		if mod.InputType == modules.InputTypeWordlist {
+			pf.Wordlist, err = plannedWordlist(*wordlistFile, wordlistMeta)
+			if err != nil {
+				return err
+			}
+			pf.Wordlist.Transform = transform
+			pf.Wordlist.TaskDuration = *taskDuration
+			if pf.Wordlist.Whole, err = plannedWholeWordlists(wholeWordlists, wholeMeta); err != nil {
+				return err
+			}
+			pf.RuntimeTarget = *runtimeTarget
+			pf.Unit = "chunks"
+			pf.TaskCount = pf.Wordlist.Chunks
+		} else {
+			pf.Tasks = runner.EntryTasks(*tool, pf.JobID, *options, targetEntries)
+			pf.Unit = "targets"
+			pf.TaskCount = len(pf.Tasks)
+			if templateShards != nil {
+				pf.TemplateShards = templateShards
+				pf.Unit = "tasks"
+				pf.TaskCount *= len(templateShards.Shards)
+			}
+			if wildcardProbe {
+				pf.WildcardProbe = true
+				pf.TaskCount += len(runner.WildcardProbes(*tool, pf.JobID, targetEntries, sc))
+			}
+		}
+		return finishPlan(os.Stdout, pf, *planOut, *format)
+	}
+
+	tracker := newTracker()
+	if err := checkBudgetTracker(jobBudget, tracker); err != nil {
+		return err
+	}
+	limits := jobLimits{Budget: jobBudget, Window: jobWindow, GlobalRate: *globalRate, Politeness: jobPoliteness}
 
 	ctx := mainContext()
 
@@ -182,6 +374,11 @@ func runScan(args []string, log logger.Logger) error {
 		if queueURL == "" || bucket == "" {
 			return fmt.Errorf("%s requires SELFHOSTED_QUEUE_ID and SELFHOSTED_BUCKET environment variables", cloudKind.Canonical())
 		}
+	} else if cloudKind.IsLocal() {
+		// Local: no infrastructure — storage, queue, and workers run here.
+		localCfg := factory.LocalConfigFromEnv()
+		queueURL = localCfg.QueueID
+		bucket = localCfg.Bucket
 	} else {
 		// AWS: resolve tool config and ensure infrastructure.
 		toolCfg, err = infra.ResolveToolConfig(*tool)
@@ -213,14 +410,17 @@ func runScan(args []string, log logger.Logger) error {
 	if err != nil {
 		return fmt.Errorf("building cloud provider: %w", err)
 	}
+	defer closeProvider(provider)
 	queue := provider.Queue()
 	storage := provider.Storage()
 	compute := provider.Compute()
 
 	jobID := jobs.NewID(*tool)
+	if saved != nil {
+		jobID = saved.JobID
+	}
 
 	// Track the job.
-	tracker := newTracker()
 	cleanupPolicy := "reuse"
 	if *destroyAfter {
 		cleanupPolicy = "destroy-after"
@@ -233,6 +433,16 @@ func runScan(args []string, log logger.Logger) error {
 		ComputeMode:           *computeMode,
 		Cloud:                 string(cloudKind),
 		CleanupPolicy:         cleanupPolicy,
+		Budget:                recordBudget(jobBudget),
+		Window:                jobWindow,
+		Politeness:            jobPoliteness,
+		GlobalRate:            *globalRate,
+		WordlistTransform:     transform,
+		Wordlists:             wholeWordlists,
+		Calibration:           recordCalibration(*taskDuration),
+		TemplateShards:        templateShards,
+		Assets:                assets,
+		WildcardProbe:         wildcardProbe,
 		Bucket:                bucket,
 		Placement:             placementPolicy,
 		ExpectedWorkerVersion: outputs["docker_image"],
@@ -244,15 +454,31 @@ func runScan(args []string, log logger.Logger) error {
 		NATSClientCertPEM:     outputs["nats_operator_client_cert_pem"],
 		NATSClientKeyPEM:      outputs["nats_operator_client_key_pem"],
 	})
+	if !jobBudget.IsZero() {
+		logStatus("Budget: %s", jobBudget)
+	}
+	if *globalRate > 0 {
+		logStatus("Global rate: %d/s across %d workers", *globalRate, *workers)
+	}
 
 	var (
 		scanErr error
 		started bool
 	)
+	jobCfg := jobs.JobConfig{ToolName: *tool, Options: *options, Assets: assets, Scope: sc}
 	if mod.InputType == modules.InputTypeWordlist {
-		started, scanErr = runWordlistScan(ctx, *tool, jobID, *wordlistFile, wordlistMeta, *runtimeTarget, *options, *chunks, *workers, *computeMode, *format, queue, storage, compute, outputs, bucket, queueURL, tracker, cloudKind, placementPolicy)
+		jobCfg.WordlistPath = *wordlistFile
+		jobCfg.RuntimeTarget = *runtimeTarget
+		jobCfg.ChunkCount = *chunks
+		jobCfg.WordlistTransform = transform
+		jobCfg.TaskDuration = *taskDuration
+		jobCfg.Wordlists = wholeWordlists
+		started, scanErr = runWordlistScan(ctx, jobCfg, jobID, wordlistMeta, *workers, *computeMode, *format, queue, storage, compute, outputs, bucket, queueURL, tracker, cloudKind, placementPolicy, limits)
 	} else {
-		started, scanErr = runTargetListScan(ctx, *tool, jobID, *inputFile, targetContent, *options, *workers, *computeMode, *format, queue, storage, compute, outputs, bucket, queueURL, tracker, cloudKind, placementPolicy)
+		jobCfg.Entries = targetEntries
+		jobCfg.TemplateShards = templateShards
+		jobCfg.WildcardProbe = wildcardProbe
+		started, scanErr = runTargetListScan(ctx, jobCfg, jobID, *inputFile, *workers, *computeMode, *format, queue, storage, compute, outputs, bucket, queueURL, tracker, cloudKind, placementPolicy, limits)
 	}
 
 	if scanErr != nil {
@@ -271,6 +497,9 @@ func runScan(args []string, log logger.Logger) error {
 		}
 		exportDir = result.Dir
 		logStatus("Exported %d results, %d artifacts to %s", result.ResultCount, result.ArtifactCount, result.Dir)
+		if m := result.Merge; m != nil {
+			logStatus("%s", mergeSummary(m))
+		}
 
 		// Record the local output path in the job record.
 		if store := tracker.Store(); store != nil {
@@ -279,14 +508,16 @@ func runScan(args []string, log logger.Logger) error {
 				_ = store.Update(rec)
 			}
 		}
+		indexExport(ctx, jobID, result.Dir, log)
 	}
 
 	// Destroy only after execution has actually started and export is done.
-	if *destroyAfter && started {
-		if cloudKind.IsSelfhostedFamily() && !cloudKind.IsProviderNative() {
+	// A budget breach destroys too when the budget asks for it.
+	if (*destroyAfter || destroyOnBudget(jobBudget, scanErr)) && started {
+		if (cloudKind.IsSelfhostedFamily() && !cloudKind.IsProviderNative()) || cloudKind.IsLocal() {
 			logStatus("Skipping destroy: %s does not support auto-destroy", cloudKind.Canonical())
 		} else if toolCfg != nil {
-			logStatus("Destroying infrastructure (--destroy-after)...")
+			logStatus("Destroying infrastructure...")
 			if destroyErr := infra.RunDestroy(ctx, toolCfg, os.Stderr, log); destroyErr != nil {
 				if scanErr != nil {
 					return fmt.Errorf("scan failed: %w; additionally, destroy failed: %v", scanErr, destroyErr)
@@ -304,77 +535,59 @@ func runScan(args []string, log logger.Logger) error {
 	return scanErr
 }
 
-func runTargetListScan(ctx context.Context, tool, jobID, inputFile, content, options string, workers int, computeMode, format string, queue cloud.Queue, storage cloud.Storage, compute cloud.Compute, outputs map[string]string, bucket, queueURL string, tracker *operator.Tracker, cloudKind cloud.Kind, placementPolicy fleet.PlacementPolicy) (bool, error) {
-	targets := parseTargetLines(content)
-	if len(targets) == 0 {
+func runTargetListScan(ctx context.Context, cfg jobs.JobConfig, jobID, inputFile string, workers int, computeMode, format string, queue cloud.Queue, storage cloud.Storage, compute cloud.Compute, outputs map[string]string, bucket, queueURL string, tracker *operator.Tracker, cloudKind cloud.Kind, placementPolicy fleet.PlacementPolicy, limits jobLimits) (bool, error) {
+	tool, entries := cfg.ToolName, cfg.Entries
+	if len(entries) == 0 {
 		return false, fmt.Errorf("no targets found in %s", inputFile)
 	}
 
-	logStatus("Parsed %d targets from %s [job %s]", len(targets), inputFile, jobID)
+	logStatus("Parsed %d targets from %s [job %s]", len(entries), inputFile, jobID)
 
-	// Build tasks.
-	tasks := make([]worker.Task, len(targets))
-	for i, t := range targets {
-		tasks[i] = worker.Task{
-			ToolName: tool,
-			JobID:    jobID,
-			Target:   t,
-			Options:  options,
-		}
+	r, err := newCLIRunner(queue, storage, compute, tracker, cloudKind, outputs, bucket, queueURL, workers, computeMode, 0, placementPolicy, limits)
+	if err != nil {
+		return false, err
 	}
-
-	// Enqueue targets.
-	logStatus("Enqueueing %d targets...", len(tasks))
-	enqueueCtx, enqueueCancel := context.WithTimeout(ctx, enqueueTimeout)
-	defer enqueueCancel()
-
-	bodies := make([]string, len(tasks))
-	for i, t := range tasks {
-		b, err := json.Marshal(t)
-		if err != nil {
-			return false, fmt.Errorf("marshaling task %d: %w", i, err)
-		}
-		bodies[i] = string(b)
+	plan, err := r.Plan(cfg, jobID)
+	if err != nil {
+		return false, err
 	}
-	if err := queue.SendBatch(enqueueCtx, queueURL, bodies); err != nil {
-		return false, fmt.Errorf("enqueueing targets: %w", err)
+	if s := plan.TemplateShards; s != nil {
+		logStatus("Template shards: %d targets x %d shards = %d tasks (%s)", len(entries), len(s.Shards), len(plan.Tasks), s)
 	}
-	logStatus("Enqueued %d targets", len(tasks))
-
-	// Update job record with total task count.
-	if store := tracker.Store(); store != nil {
-		if rec, loadErr := store.Load(jobID); loadErr == nil {
-			rec.TotalTasks = len(tasks)
-			_ = store.Update(rec)
-		}
+	if plan.WildcardProbes > 0 {
+		logStatus("Wildcard DNS: probing with %d random names", plan.WildcardProbes)
 	}
-	_ = tracker.UpdatePhase(jobID, operator.PhaseLaunching)
-
-	// Launch workers.
-	if err := launchGenericWorkers(ctx, tool, workers, computeMode, compute, outputs, queueURL, bucket, cloudKind, placementPolicy); err != nil {
+	if err := r.Start(ctx, plan); err != nil {
 		return false, err
 	}
 
-	_ = tracker.UpdatePhase(jobID, operator.PhaseScanning)
-
 	// Poll for progress.
-	return true, pollAndOutput(ctx, storage, bucket, tool, jobID, len(tasks), "targets", format)
+	if err := pollAndOutput(ctx, r, storage, bucket, tool, jobID, len(plan.Tasks), plan.Unit(), format); err != nil {
+		return true, err
+	}
+	if plan.WildcardProbes > 0 {
+		wildcards, err := r.RecordWildcards(ctx, tool, jobID)
+		switch {
+		case err != nil:
+			logStatus("Warning: %v", err)
+		case len(wildcards) > 0:
+			logStatus("Wildcard DNS: %s; matching answers are dropped when results are merged", wildcards)
+		default:
+			logStatus("Wildcard DNS: none found")
+		}
+	}
+	return true, nil
 }
 
-func runWordlistScan(ctx context.Context, tool, jobID, wordlistFile string, preflight *wordlisttool.Metadata, runtimeTarget, options string, chunks, workers int, computeMode, format string, queue cloud.Queue, storage cloud.Storage, compute cloud.Compute, outputs map[string]string, bucket, queueURL string, tracker *operator.Tracker, cloudKind cloud.Kind, placementPolicy fleet.PlacementPolicy) (bool, error) {
-	tempDir, err := os.MkdirTemp("", "heph-wordlist-*")
+func runWordlistScan(ctx context.Context, cfg jobs.JobConfig, jobID string, preflight *wordlisttool.Metadata, workers int, computeMode, format string, queue cloud.Queue, storage cloud.Storage, compute cloud.Compute, outputs map[string]string, bucket, queueURL string, tracker *operator.Tracker, cloudKind cloud.Kind, placementPolicy fleet.PlacementPolicy, limits jobLimits) (bool, error) {
+	tool, wordlistFile, runtimeTarget, chunks := cfg.ToolName, cfg.WordlistPath, cfg.RuntimeTarget, cfg.ChunkCount
+	r, err := newCLIRunner(queue, storage, compute, tracker, cloudKind, outputs, bucket, queueURL, workers, computeMode, 0, placementPolicy, limits)
 	if err != nil {
-		return false, fmt.Errorf("creating wordlist temp dir: %w", err)
+		return false, err
 	}
-	defer func() {
-		if err := os.RemoveAll(tempDir); err != nil {
-			logStatus("Warning: failed to remove wordlist temp dir %s: %v", tempDir, err)
-		}
-	}()
-
-	plan, err := jobs.PlanWordlistFile(tool, jobID, runtimeTarget, options, wordlistFile, tempDir, chunks, workers)
+	plan, err := r.Plan(cfg, jobID)
 	if err != nil {
-		return false, fmt.Errorf("planning wordlist job: %w", err)
+		return false, err
 	}
 	defer func() {
 		if err := plan.Cleanup(); err != nil {
@@ -382,104 +595,187 @@ func runWordlistScan(ctx context.Context, tool, jobID, wordlistFile string, pref
 		}
 	}()
 
+	wl := plan.Wordlist
 	requested := "auto"
 	if chunks > 0 {
 		requested = strconv.Itoa(chunks)
 	}
-	sourceBytes := plan.TotalSourceBytes
+	entries := wl.TotalWords
+	if plan.Calibrating() {
+		requested = "adaptive, sample"
+		if preflight != nil {
+			entries = preflight.TotalWords
+		}
+	}
+	sourceBytes := wl.TotalSourceBytes
 	if preflight != nil && preflight.TotalSourceBytes > 0 {
 		sourceBytes = preflight.TotalSourceBytes
 	}
 	logStatus("Parsed %d entries from %s (%s); chunks requested=%s effective=%d target=%s max=%s [job %s]",
-		plan.TotalWords,
+		entries,
 		wordlistFile,
 		formatByteSize(sourceBytes),
 		requested,
-		plan.EffectiveChunks,
-		formatByteSize(plan.TargetChunkSize),
-		formatByteSize(plan.MaxChunkSize),
+		wl.EffectiveChunks,
+		formatByteSize(wl.TargetChunkSize),
+		formatByteSize(wl.MaxChunkSize),
 		jobID,
 	)
 	if runtimeTarget != "" {
 		logStatus("Target: %s", runtimeTarget)
 	}
 
-	// Update job record with wordlist metadata.
-	_ = tracker.UpdatePhase(jobID, operator.PhaseUploading)
-	if store := tracker.Store(); store != nil {
-		if rec, loadErr := store.Load(jobID); loadErr == nil {
-			rec.TotalTasks = plan.EffectiveChunks
-			rec.TotalWords = plan.TotalWords
-			rec.RuntimeTarget = runtimeTarget
-			_ = store.Update(rec)
-		}
+	if err := r.Start(ctx, plan); err != nil {
+		return false, err
 	}
 
-	// Upload chunks.
-	logStatus("Uploading %d chunks to s3://%s/...", plan.EffectiveChunks, bucket)
-	uploadCtx, uploadCancel := context.WithTimeout(ctx, enqueueTimeout)
-	defer uploadCancel()
-	if err := jobs.UploadChunks(uploadCtx, storage, bucket, plan); err != nil {
-		return false, fmt.Errorf("uploading wordlist chunks: %w", err)
-	}
+	// Poll for progress.
+	return true, pollAndOutput(ctx, r, storage, bucket, tool, jobID, len(plan.Tasks), plan.Unit(), format)
+}
 
-	// Enqueue tasks.
-	_ = tracker.UpdatePhase(jobID, operator.PhaseEnqueuing)
-	logStatus("Enqueueing %d chunk tasks...", len(plan.Tasks))
-	enqueueCtx, enqueueCancel := context.WithTimeout(ctx, enqueueTimeout)
-	defer enqueueCancel()
+// preflightTargetListFile imports a target file in the given (or detected)
+// format and normalizes it for the module.
+func preflightTargetListFile(path, format string, opts targets.Options) ([]targets.Entry, error) {
+	entries, err := targets.ImportFile(path, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no targets found in %s", path)
+	}
+	return preflightTargets(entries, opts)
+}
 
-	bodies := make([]string, len(plan.Tasks))
-	for i, t := range plan.Tasks {
-		b, err := json.Marshal(t)
-		if err != nil {
-			return false, fmt.Errorf("marshaling task %d: %w", i, err)
-		}
-		bodies[i] = string(b)
+// moduleTargetOptions returns the module's target normalization options with
+// CLI overrides applied. A negative splitCIDR keeps the module default.
+func moduleTargetOptions(mod *modules.ModuleDefinition, splitCIDR int, expandCIDR bool) targets.Options {
+	opts := mod.TargetOptions()
+	if splitCIDR >= 0 {
+		opts.SplitCIDR = splitCIDR
 	}
-	if err := queue.SendBatch(enqueueCtx, queueURL, bodies); err != nil {
-		return false, fmt.Errorf("enqueueing chunk tasks: %w", err)
+	opts.ExpandCIDR = expandCIDR
+	return opts
+}
+
+// preflightTargets normalizes imported entries for the module. The result is
+// what gets planned and scope-checked.
+func preflightTargets(entries []targets.Entry, opts targets.Options) ([]targets.Entry, error) {
+	res, err := targets.NormalizeEntries(entries, opts)
+	if err != nil {
+		return nil, fmt.Errorf("validating targets: %w", err)
 	}
-	logStatus("Enqueued %d chunk tasks", len(plan.Tasks))
-	if err := plan.Cleanup(); err != nil {
-		logStatus("Warning: failed to clean temporary wordlist chunks: %v", err)
+	if len(res.Entries) == 0 {
+		return nil, fmt.Errorf("no targets found")
 	}
+	logTargetStats(res.Stats)
+	return res.Entries, nil
+}
 
-	_ = tracker.UpdatePhase(jobID, operator.PhaseLaunching)
+// entryTargets returns the target of each entry.
+func entryTargets(entries []targets.Entry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Target
+	}
+	return out
+}
 
-	// Launch workers.
-	if err := launchGenericWorkers(ctx, tool, workers, computeMode, compute, outputs, queueURL, bucket, cloudKind, placementPolicy); err != nil {
-		return false, err
+// logTargetStats reports what normalization changed, if anything.
+func logTargetStats(st targets.Stats) {
+	if st.Input == st.Output && st.Converted == 0 {
+		return
 	}
+	logStatus("Targets: %d in, %d out (%d duplicates, %d converted, %d split, %d expanded)",
+		st.Input, st.Output, st.Duplicates, st.Converted, st.Split, st.Expanded)
+}
 
-	_ = tracker.UpdatePhase(jobID, operator.PhaseScanning)
+// preflightRuntimeTarget rejects a wordlist runtime target whose kind the
+// module does not accept.
+func preflightRuntimeTarget(mod *modules.ModuleDefinition, runtimeTarget string) error {
+	accept := mod.AcceptedKinds()
+	if runtimeTarget == "" || len(accept) == 0 {
+		return nil
+	}
+	t, err := targets.Parse(runtimeTarget)
+	if err != nil {
+		return fmt.Errorf("invalid --target: %w", err)
+	}
+	if !slices.Contains(accept, t.Kind) {
+		return fmt.Errorf("--target %q is a %s; tool %q accepts %s", runtimeTarget, t.Kind, mod.Name, joinKinds(accept))
+	}
+	return nil
+}
 
-	// Poll for progress.
-	return true, pollAndOutput(ctx, storage, bucket, tool, jobID, len(plan.Tasks), "chunks", format)
+func joinKinds(kinds []targets.Kind) string {
+	s := make([]string, len(kinds))
+	for i, k := range kinds {
+		s[i] = string(k)
+	}
+	return strings.Join(s, ", ")
 }
 
-func preflightTargetListFile(path string) (string, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return "", fmt.Errorf("reading target file: %w", err)
+// preflightScope resolves the job's scope and rejects out-of-scope targets
+// before any lifecycle side effects. It returns nil when no scope is set.
+func preflightScope(scopeFile string, opCfg *operator.OperatorConfig, targetList []string, runtimeTarget string) (*scope.Scope, error) {
+	sc, err := operator.ResolveScope(scopeFile, opCfg)
+	if err != nil || sc == nil {
+		return nil, err
+	}
+	if runtimeTarget != "" {
+		targetList = append(targetList, runtimeTarget)
 	}
-	if len(parseTargetLines(string(content))) == 0 {
-		return "", fmt.Errorf("no targets found in %s", path)
+	if err := sc.CheckAll(targetList); err != nil {
+		return nil, err
 	}
-	return string(content), nil
+	logStatus("Scope: all targets in scope (%d include, %d exclude rules)", len(sc.Include()), len(sc.Exclude()))
+	return sc, nil
 }
 
-func preflightWordlistFile(_, path, _, _ string, chunks, workers int) (*wordlisttool.Metadata, error) {
+func preflightWordlistFile(_, path, _, _ string, chunks, workers int, transform *wordlisttool.Transform) (*wordlisttool.Metadata, error) {
 	meta, err := wordlisttool.InspectFile(path, wordlisttool.Policy{
 		RequestedChunks: chunks,
 		WorkerCount:     workers,
+		Transform:       transform,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("validating wordlist file: %w", err)
 	}
+	if !transform.IsZero() {
+		st := meta.Transform
+		logStatus("Wordlist: %d in, %d out (%d filtered, %d duplicates, %d added) [%s]",
+			st.Input, st.Output, st.Filtered, st.Duplicates, st.Added, transform)
+	}
 	return meta, nil
 }
 
+// recordCalibration returns the job record's adaptive sizing request, or
+// nil when chunks are sized by bytes.
+func recordCalibration(taskDuration time.Duration) *operator.Calibration {
+	if taskDuration <= 0 {
+		return nil
+	}
+	return &operator.Calibration{TargetTaskDuration: taskDuration}
+}
+
+// plannedWordlist pins a preflighted wordlist for a saved plan.
+func plannedWordlist(path string, meta *wordlisttool.Metadata) (*runner.PlannedWordlist, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving wordlist path: %w", err)
+	}
+	sum, size, err := runner.HashFile(abs)
+	if err != nil {
+		return nil, err
+	}
+	return &runner.PlannedWordlist{
+		Path:       abs,
+		SHA256:     sum,
+		Size:       size,
+		TotalWords: meta.TotalWords,
+		Chunks:     meta.EffectiveChunks,
+	}, nil
+}
+
 func formatByteSize(n int64) string {
 	const mib = 1024 * 1024
 	if n%mib == 0 && n >= mib {
@@ -488,97 +784,83 @@ func formatByteSize(n int64) string {
 	return fmt.Sprintf("%d bytes", n)
 }
 
-func launchGenericWorkers(ctx context.Context, tool string, workers int, computeMode string, compute cloud.Compute, outputs map[string]string, queueURL, bucket string, cloudKind cloud.Kind, placementPolicy fleet.PlacementPolicy) error {
-	logStatus("Launching %d workers (mode: %s)...", workers, computeMode)
-	launchCtx, launchCancel := context.WithTimeout(ctx, launchTimeout)
-	defer launchCancel()
-
-	if cloudKind.IsProviderNative() {
-		ready, err := waitForProviderNativeFleetFunc(launchCtx, cloudKind, outputs, placementPolicy)
-		if err != nil {
-			return err
-		}
-		logStatus("Using provider-native %s fleet (%d eligible workers, policy: %s)", cloudKind.Canonical(), ready, placementPolicy.Summary())
+// validateGlobalRate checks that a --global-rate can be honoured: the tool
+// must take a rate, and every planned worker needs at least one request per
+// second. Workers without fleet heartbeats run at that planned share; workers
+// on the heartbeat stream also wait rather than exceed the rate when more of
+// them than planned pick up the job.
+func validateGlobalRate(mod *modules.ModuleDefinition, rate, workers int) error {
+	if rate == 0 {
 		return nil
 	}
-
-	containerName := fmt.Sprintf("%s-worker", tool)
-	workerEnv := map[string]string{
-		"QUEUE_URL": queueURL,
-		"S3_BUCKET": bucket,
-		"TOOL_NAME": tool,
-	}
-
-	// Selfhosted only supports RunContainer (no spot instances).
-	useSpot := !cloudKind.IsSelfhostedFamily() && resolveComputeMode(computeMode, workers)
-	if useSpot {
-		ecrURL := outputs["ecr_repo_url"]
-		userData := awscloud.GenerateUserData(awscloud.UserDataOpts{
-			ECRRepoURL: ecrURL,
-			ImageTag:   "latest",
-			Region:     regionFromECR(ecrURL),
-			EnvVars:    workerEnv,
-		})
-		ids, err := compute.RunSpotInstances(launchCtx, cloud.SpotOpts{
-			AMI:             outputs["ami_id"],
-			Count:           workers,
-			SecurityGroups:  []string{outputs["security_group_id"]},
-			SubnetIDs:       splitOutputList(outputs["subnet_ids"]),
-			InstanceProfile: outputs["instance_profile_arn"],
-			UserData:        userData,
-			Tags: map[string]string{
-				"Project": "heph4estus",
-				"Tool":    tool,
-			},
-		})
-		if err != nil {
-			return fmt.Errorf("launching spot instances: %w", err)
-		}
-		logStatus("Launched %d spot instances", len(ids))
-	} else {
-		_, err := compute.RunContainer(launchCtx, cloud.ContainerOpts{
-			Cluster:        outputs["ecs_cluster_name"],
-			TaskDefinition: outputs["task_definition_arn"],
-			ContainerName:  containerName,
-			Subnets:        splitOutputList(outputs["subnet_ids"]),
-			SecurityGroups: []string{outputs["security_group_id"]},
-			Env:            workerEnv,
-			Count:          workers,
-		})
-		if err != nil {
-			return fmt.Errorf("launching workers: %w", err)
-		}
-		logStatus("Launched %d workers", workers)
+	if !mod.RateLimited() {
+		return fmt.Errorf("--global-rate is not supported by tool %q: its module has no {{rate}} placeholder", mod.Name)
+	}
+	if rate < workers {
+		return fmt.Errorf("--global-rate %d is below the worker count %d; each worker needs at least 1 request per second", rate, workers)
 	}
 	return nil
 }
 
-func pollAndOutput(ctx context.Context, storage cloud.Storage, bucket, tool, jobID string, totalTasks int, unitLabel, format string) error {
-	logStatus("Scanning...")
-	startTime := time.Now()
-	scanPrefix := jobs.ResultPrefix(tool, jobID)
+// jobLimits holds the operator limits the CLI resolved for a job. They go
+// to the runner directly; the job record only stores them for display.
+type jobLimits struct {
+	Budget     operator.Budget
+	Window     *schedule.Window
+	GlobalRate int
+	Politeness *politeness.Limits
+}
 
-	for {
-		count, err := storage.Count(ctx, bucket, scanPrefix)
-		if err != nil {
-			logStatus("Warning: progress check failed: %v", err)
-		} else {
-			elapsed := time.Since(startTime).Truncate(time.Second)
-			pct := float64(count) / float64(totalTasks) * 100
-			logStatus("Progress: %d/%d %s (%.1f%%) — elapsed %s", count, totalTasks, unitLabel, pct, elapsed)
+// newCLIRunner builds a job runner over the CLI's cloud clients. Fleet waits
+// and stops go through waitForProviderNativeFleetFunc and
+// stopProviderNativeFleetFunc so tests can stub them.
+func newCLIRunner(queue cloud.Queue, storage cloud.Storage, compute cloud.Compute, tracker *operator.Tracker, cloudKind cloud.Kind, outputs map[string]string, bucket, queueURL string, workers int, computeMode string, jitterMax int, placementPolicy fleet.PlacementPolicy, limits jobLimits) (*runner.Runner, error) {
+	return runner.New(runner.Config{
+		Provider:         runner.Services{Q: queue, S: storage, C: compute},
+		Tracker:          tracker,
+		Cloud:            cloudKind,
+		QueueID:          queueURL,
+		Bucket:           bucket,
+		Outputs:          outputs,
+		Workers:          workers,
+		ComputeMode:      computeMode,
+		JitterMaxSeconds: jitterMax,
+		Placement:        placementPolicy,
+		Budget:           limits.Budget,
+		Window:           limits.Window,
+		GlobalRate:       limits.GlobalRate,
+		Politeness:       limits.Politeness,
+		ChunkCache:       true,
+		WaitForFleet: func(ctx context.Context, kind cloud.Kind, outputs map[string]string, policy fleet.PlacementPolicy) (int, error) {
+			return waitForProviderNativeFleetFunc(ctx, kind, outputs, policy)
+		},
+		StopFleet: func(ctx context.Context, kind cloud.Kind, tool string) error {
+			return stopProviderNativeFleetFunc(ctx, kind, tool)
+		},
+		PollInterval: pollInterval,
+		Logf:         logStatus,
+	})
+}
 
-			if count >= totalTasks {
-				break
-			}
-		}
-		time.Sleep(pollInterval)
+func pollAndOutput(ctx context.Context, r *runner.Runner, storage cloud.Storage, bucket, tool, jobID string, totalTasks int, unitLabel, format string) error {
+	logStatus("Scanning...")
+	startTime := time.Now()
+	err := r.Wait(ctx, tool, jobID, totalTasks, func(p runner.Progress) {
+		logStatus("Progress: %d/%d %s (%.1f%%) — elapsed %s", p.Completed, p.Total, unitLabel, p.Percent(), p.Elapsed.Truncate(time.Second))
+	})
+	if err != nil {
+		return err
 	}
 
 	elapsed := time.Since(startTime).Truncate(time.Second)
 	logStatus("Scan complete: %d %s in %s", totalTasks, unitLabel, elapsed)
 
 	// Output results.
-	return outputGenericResults(ctx, storage, bucket, scanPrefix, format)
+	if err := outputGenericResults(ctx, storage, bucket, jobs.ResultPrefix(tool, jobID), format); err != nil {
+		return err
+	}
+	logStatus("Query the findings later with: heph results --job-id %s", jobID)
+	return nil
 }
 
 func outputGenericResults(ctx context.Context, storage cloud.Storage, bucket, prefix, format string) error {
@@ -646,16 +928,3 @@ func outputGenericResults(ctx context.Context, storage cloud.Storage, bucket, pr
 	}
 	return nil
 }
-
-// parseTargetLines splits content into non-empty, non-comment lines.
-func parseTargetLines(content string) []string {
-	var targets []string
-	for _, line := range strings.Split(content, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		targets = append(targets, line)
-	}
-	return targets
-}
@@ -17,23 +17,39 @@ import (
 	"heph4estus/internal/fleetstate"
 	"heph4estus/internal/logger"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/tools/dns"
 )
 
 // statusSnapshot is the structured output of heph status.
 type statusSnapshot struct {
-	JobID          string         `json:"job_id"`
-	Tool           string         `json:"tool"`
-	Phase          operator.Phase `json:"phase"`
-	Cloud          string         `json:"cloud,omitempty"`
-	Bucket         string         `json:"bucket,omitempty"`
-	Progress       statusProgress `json:"progress"`
-	Elapsed        string         `json:"elapsed"`
-	CleanupPolicy  string         `json:"cleanup_policy,omitempty"`
-	ResultPrefix   string         `json:"result_prefix,omitempty"`
-	ArtifactPrefix string         `json:"artifact_prefix,omitempty"`
-	LocalOutputDir string         `json:"local_output_dir,omitempty"`
-	LastError      string         `json:"last_error,omitempty"`
-	Fleet          *statusFleet   `json:"fleet,omitempty"`
+	JobID          string            `json:"job_id"`
+	Tool           string            `json:"tool"`
+	Phase          operator.Phase    `json:"phase"`
+	Stage          string            `json:"stage,omitempty"`
+	Cloud          string            `json:"cloud,omitempty"`
+	Bucket         string            `json:"bucket,omitempty"`
+	Progress       statusProgress    `json:"progress"`
+	Elapsed        string            `json:"elapsed"`
+	CleanupPolicy  string            `json:"cleanup_policy,omitempty"`
+	ResultPrefix   string            `json:"result_prefix,omitempty"`
+	ArtifactPrefix string            `json:"artifact_prefix,omitempty"`
+	LocalOutputDir string            `json:"local_output_dir,omitempty"`
+	ScopeDigest    string            `json:"scope_digest,omitempty"`
+	LastError      string            `json:"last_error,omitempty"`
+	Window         string            `json:"window,omitempty"`
+	Paused         string            `json:"paused,omitempty"`
+	ResumesAt      *time.Time        `json:"resumes_at,omitempty"`
+	GlobalRate     int               `json:"global_rate,omitempty"`
+	PerTarget      string            `json:"per_target,omitempty"`
+	Rewrite        string            `json:"wordlist_transform,omitempty"`
+	Wordlists      map[string]string `json:"wordlists,omitempty"`
+	Chunking       string            `json:"chunking,omitempty"`
+	Templates      string            `json:"template_shards,omitempty"`
+	Assets         string            `json:"assets,omitempty"`
+	Wildcards      dns.Wildcards     `json:"wildcards,omitempty"`
+	SourceJobID    string            `json:"source_job_id,omitempty"`
+	FollowUpJobIDs []string          `json:"follow_up_job_ids,omitempty"`
+	Fleet          *statusFleet      `json:"fleet,omitempty"`
 }
 
 type statusProgress struct {
@@ -227,10 +243,11 @@ func buildSnapshot(rec *operator.JobRecord, liveCompleted int) statusSnapshot {
 		pct = math.Round(float64(completed)/float64(total)*1000) / 10
 	}
 
-	return statusSnapshot{
+	snap := statusSnapshot{
 		JobID:          rec.JobID,
 		Tool:           rec.ToolName,
 		Phase:          phase,
+		Stage:          rec.Stage,
 		Cloud:          rec.Cloud,
 		Bucket:         rec.Bucket,
 		Progress:       statusProgress{Completed: completed, Total: total, Percent: pct},
@@ -239,8 +256,37 @@ func buildSnapshot(rec *operator.JobRecord, liveCompleted int) statusSnapshot {
 		ResultPrefix:   rec.ResultPrefix,
 		ArtifactPrefix: rec.ArtifactPrefix,
 		LocalOutputDir: rec.LocalOutputDir,
+		ScopeDigest:    rec.ScopeDigest,
 		LastError:      rec.LastError,
+		SourceJobID:    rec.SourceJobID,
+		FollowUpJobIDs: rec.FollowUpJobIDs,
+		GlobalRate:     rec.GlobalRate,
+	}
+	if rec.Politeness != nil {
+		snap.PerTarget = rec.Politeness.String()
+	}
+	if !rec.WordlistTransform.IsZero() {
+		snap.Rewrite = rec.WordlistTransform.String()
+	}
+	snap.Wordlists = rec.Wordlists
+	if rec.Calibration != nil {
+		snap.Chunking = rec.Calibration.String()
+	}
+	snap.Templates = rec.TemplateShards.String()
+	if rec.Assets != nil {
+		snap.Assets = rec.Assets.Name + " from " + rec.Assets.Dir
+	}
+	snap.Wildcards = rec.Wildcards
+	if rec.Window != nil {
+		snap.Window = rec.Window.String()
+		if now := time.Now(); !isTerminalPhase(phase) && !rec.Window.Open(now) {
+			snap.Paused = "outside window"
+			if next := rec.Window.NextOpen(now); !next.IsZero() {
+				snap.ResumesAt = &next
+			}
+		}
 	}
+	return snap
 }
 
 func outputStatusJSON(snap statusSnapshot) error {
@@ -252,12 +298,56 @@ func outputStatusJSON(snap statusSnapshot) error {
 func outputStatusText(snap statusSnapshot) error {
 	_, _ = fmt.Fprintf(os.Stdout, "Job:       %s\n", snap.JobID)
 	_, _ = fmt.Fprintf(os.Stdout, "Tool:      %s\n", snap.Tool)
-	_, _ = fmt.Fprintf(os.Stdout, "Phase:     %s\n", snap.Phase)
+	if snap.Stage != "" && !isTerminalPhase(snap.Phase) {
+		_, _ = fmt.Fprintf(os.Stdout, "Phase:     %s (%s)\n", snap.Phase, snap.Stage)
+	} else {
+		_, _ = fmt.Fprintf(os.Stdout, "Phase:     %s\n", snap.Phase)
+	}
 	if snap.Cloud != "" {
 		_, _ = fmt.Fprintf(os.Stdout, "Cloud:     %s\n", snap.Cloud)
 	}
 	_, _ = fmt.Fprintf(os.Stdout, "Progress:  %d / %d  (%.1f%%)\n", snap.Progress.Completed, snap.Progress.Total, snap.Progress.Percent)
 	_, _ = fmt.Fprintf(os.Stdout, "Elapsed:   %s\n", snap.Elapsed)
+	if snap.Window != "" {
+		_, _ = fmt.Fprintf(os.Stdout, "Window:    %s\n", snap.Window)
+	}
+	if snap.Paused != "" {
+		if snap.ResumesAt != nil {
+			_, _ = fmt.Fprintf(os.Stdout, "Paused:    %s (resumes %s)\n", snap.Paused, snap.ResumesAt.Local().Format(time.RFC3339))
+		} else {
+			_, _ = fmt.Fprintf(os.Stdout, "Paused:    %s\n", snap.Paused)
+		}
+	}
+	if snap.GlobalRate > 0 {
+		_, _ = fmt.Fprintf(os.Stdout, "Rate:      %d/s global, divided across the job's live workers\n", snap.GlobalRate)
+	}
+	if snap.PerTarget != "" {
+		_, _ = fmt.Fprintf(os.Stdout, "Per host:  %s\n", snap.PerTarget)
+	}
+	if snap.Rewrite != "" {
+		_, _ = fmt.Fprintf(os.Stdout, "Rewrite:   %s\n", snap.Rewrite)
+	}
+	if snap.Chunking != "" {
+		_, _ = fmt.Fprintf(os.Stdout, "Chunking:  %s\n", snap.Chunking)
+	}
+	for _, name := range sortedKeys(snap.Wordlists) {
+		_, _ = fmt.Fprintf(os.Stdout, "Wordlist:  %s=%s (whole)\n", name, snap.Wordlists[name])
+	}
+	if snap.Templates != "" {
+		_, _ = fmt.Fprintf(os.Stdout, "Templates: %s\n", snap.Templates)
+	}
+	if snap.Assets != "" {
+		_, _ = fmt.Fprintf(os.Stdout, "Assets:    %s\n", snap.Assets)
+	}
+	if len(snap.Wildcards) > 0 {
+		_, _ = fmt.Fprintf(os.Stdout, "Wildcard:  %s\n", snap.Wildcards)
+	}
+	if snap.SourceJobID != "" {
+		_, _ = fmt.Fprintf(os.Stdout, "Source:    %s\n", snap.SourceJobID)
+	}
+	if len(snap.FollowUpJobIDs) > 0 {
+		_, _ = fmt.Fprintf(os.Stdout, "Follow-up: %s\n", strings.Join(snap.FollowUpJobIDs, ", "))
+	}
 
 	if snap.Fleet != nil {
 		_, _ = fmt.Fprintf(os.Stdout, "\nFleet:\n")
@@ -315,6 +405,9 @@ func outputStatusText(snap statusSnapshot) error {
 	if snap.LocalOutputDir != "" {
 		_, _ = fmt.Fprintf(os.Stdout, "Local:     %s\n", snap.LocalOutputDir)
 	}
+	if snap.ScopeDigest != "" {
+		_, _ = fmt.Fprintf(os.Stdout, "Scope:     sha256:%s\n", snap.ScopeDigest)
+	}
 	if snap.LastError != "" {
 		_, _ = fmt.Fprintf(os.Stdout, "Error:     %s\n", snap.LastError)
 	}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"heph4estus/internal/operator"
+	"heph4estus/internal/schedule"
 )
 
 func TestRunStatusRequiresJobID(t *testing.T) {
@@ -151,6 +152,56 @@ func TestBuildSnapshotInfersScanning(t *testing.T) {
 	}
 }
 
+func TestBuildSnapshotPausedOutsideWindow(t *testing.T) {
+	now := time.Now().UTC()
+	closed, err := schedule.New("", "", now.Format("2006-01-02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &operator.JobRecord{
+		JobID:      "nmap-test",
+		ToolName:   "nmap",
+		Phase:      operator.PhaseScanning,
+		CreatedAt:  now.Add(-2 * time.Minute),
+		TotalTasks: 20,
+		Window:     closed,
+	}
+
+	snap := buildSnapshot(rec, 5)
+
+	if snap.Paused != "outside window" {
+		t.Errorf("Paused = %q, want outside window", snap.Paused)
+	}
+	if snap.ResumesAt == nil || !snap.ResumesAt.After(now) {
+		t.Errorf("ResumesAt = %v, want a time after now", snap.ResumesAt)
+	}
+
+	rec.Phase = operator.PhaseComplete
+	if snap := buildSnapshot(rec, 20); snap.Paused != "" {
+		t.Errorf("terminal job reported paused: %q", snap.Paused)
+	}
+}
+
+func TestBuildSnapshotLinkedJobs(t *testing.T) {
+	rec := &operator.JobRecord{
+		JobID:          "nmap-test",
+		ToolName:       "nmap",
+		Phase:          operator.PhaseComplete,
+		CreatedAt:      time.Now().Add(-time.Minute),
+		SourceJobID:    "masscan-test",
+		FollowUpJobIDs: []string{"nuclei-test"},
+	}
+
+	snap := buildSnapshot(rec, 0)
+
+	if snap.SourceJobID != "masscan-test" {
+		t.Errorf("SourceJobID = %q, want masscan-test", snap.SourceJobID)
+	}
+	if len(snap.FollowUpJobIDs) != 1 || snap.FollowUpJobIDs[0] != "nuclei-test" {
+		t.Errorf("FollowUpJobIDs = %v, want [nuclei-test]", snap.FollowUpJobIDs)
+	}
+}
+
 func TestBuildSnapshotPreservesTerminalPhase(t *testing.T) {
 	now := time.Now().UTC()
 	rec := &operator.JobRecord{
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"heph4estus/internal/logger"
+	"heph4estus/internal/modules"
+	"heph4estus/internal/targets"
+)
+
+func runTargets(args []string, log logger.Logger) error {
+	if len(args) == 0 {
+		return fmt.Errorf("targets requires a subcommand: convert")
+	}
+	switch args[0] {
+	case "convert":
+		return runTargetsConvert(args[1:], os.Stdout)
+	default:
+		return fmt.Errorf("targets: unknown subcommand %q", args[0])
+	}
+}
+
+// runTargetsConvert imports and normalizes a target file exactly as a scan
+// would plan it, and prints the result without touching any infrastructure.
+func runTargetsConvert(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("targets convert", flag.ContinueOnError)
+	inputFile := fs.String("file", "", "Path to file containing targets (required)")
+	inputFormat := fs.String("input-format", "", "Target file format: "+strings.Join(targets.Formats(), ", ")+" (default: from file extension)")
+	tool := fs.String("tool", "", "Normalize for this tool's accepted target kinds and CIDR splitting")
+	splitCIDR := fs.Int("split-cidr", -1, "Split IPv4 CIDRs wider than /N into /N blocks (default: tool setting; 0 disables)")
+	expandCIDR := fs.Bool("expand-cidr", false, "Expand CIDRs and ranges into individual addresses")
+	format := fs.String("format", "text", "Output format: text, json or csv")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *inputFile == "" {
+		return fmt.Errorf("--file flag is required")
+	}
+	if *format != "text" && *format != "json" && *format != "csv" {
+		return fmt.Errorf("--format must be text, json or csv")
+	}
+	if *splitCIDR > 32 {
+		return fmt.Errorf("--split-cidr must be between 0 and 32")
+	}
+
+	mod := &modules.ModuleDefinition{}
+	if *tool != "" {
+		reg, err := modules.NewDefaultRegistry()
+		if err != nil {
+			return fmt.Errorf("loading module registry: %w", err)
+		}
+		mod, err = reg.Get(*tool)
+		if err != nil {
+			return fmt.Errorf("unknown tool: %q (available: %s)", *tool, strings.Join(reg.Names(), ", "))
+		}
+	}
+	entries, err := preflightTargetListFile(*inputFile, *inputFormat, moduleTargetOptions(mod, *splitCIDR, *expandCIDR))
+	if err != nil {
+		return err
+	}
+	return writeEntries(w, entries, *format)
+}
+
+func writeEntries(w io.Writer, entries []targets.Entry, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "csv":
+		keySet := make(map[string]bool)
+		for _, e := range entries {
+			for k := range e.Metadata {
+				keySet[k] = true
+			}
+		}
+		keys := make([]string, 0, len(keySet))
+		for k := range keySet {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		cw := csv.NewWriter(w)
+		_ = cw.Write(append([]string{"target", "options"}, keys...))
+		for _, e := range entries {
+			row := []string{e.Target, e.Options}
+			for _, k := range keys {
+				row = append(row, e.Metadata[k])
+			}
+			_ = cw.Write(row)
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		for _, e := range entries {
+			if _, err := fmt.Fprintln(w, e.Line()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
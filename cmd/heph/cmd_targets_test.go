@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTargetsConvertPreviewsPlannedTargets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	content := `{"targets": [{"hostname": "www.example.com", "owner": "web"}, "WWW.example.com.", "api.example.com:8443"]}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runTargetsConvert([]string{"--file", path, "--tool", "httpx"}, &out); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if got := out.String(); got != "https://www.example.com\napi.example.com:8443\n" {
+		t.Fatalf("text output = %q", got)
+	}
+
+	out.Reset()
+	if err := runTargetsConvert([]string{"--file", path, "--format", "csv"}, &out); err != nil {
+		t.Fatalf("convert csv: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "target,options,owner\nwww.example.com,,web\n") {
+		t.Fatalf("csv output = %q", out.String())
+	}
+}
+
+func TestTargetsConvertErrors(t *testing.T) {
+	if err := runTargetsConvert(nil, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "--file") {
+		t.Fatalf("expected --file error, got %v", err)
+	}
+	if err := run([]string{"targets"}, testLogger()); err == nil {
+		t.Fatal("expected missing subcommand error")
+	}
+	path := filepath.Join(t.TempDir(), "targets.txt")
+	if err := os.WriteFile(path, []byte("10.0.0.0/24\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := runTargetsConvert([]string{"--file", path, "--tool", "dnsx"}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "not accepted") {
+		t.Fatalf("expected kind error, got %v", err)
+	}
+}
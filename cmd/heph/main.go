@@ -21,10 +21,15 @@ const usage = `Usage: heph <command> [options]
 Commands:
   nmap     Run an nmap scan (auto-deploys infrastructure if needed)
   scan     Run a generic tool scan (e.g. httpx, nuclei, ffuf; auto-deploys if needed)
+  targets  Preview target files as they would be planned (convert)
   infra    Manage cloud infrastructure explicitly (deploy/destroy/backup/recover/trust/rotate)
   fleet    Inspect and manage provider-native fleet state
   bench    Run provider-native fleet benchmark probes
   status   Check job status (--job-id required)
+  results  Query, filter and render job results and findings, or merge reports (merge)
+  index    Add jobs to the local results index for offline search (list/rm)
+  diff     Compare the findings of two jobs of the same tool (--base, --head)
+  cache    List or prune cached wordlist chunks
   doctor   Check prerequisites and environment health
   init     Set up or update operator defaults (region, profile, workers, etc.)
 
@@ -44,6 +49,8 @@ func run(args []string, log logger.Logger) error {
 		return runNmap(cmdArgs, log)
 	case "scan":
 		return runScan(cmdArgs, log)
+	case "targets":
+		return runTargets(cmdArgs, log)
 	case "infra":
 		return runInfra(cmdArgs, log)
 	case "fleet":
@@ -52,6 +59,14 @@ func run(args []string, log logger.Logger) error {
 		return runBench(cmdArgs, log)
 	case "status":
 		return runStatus(cmdArgs, log)
+	case "results":
+		return runResults(cmdArgs, log)
+	case "index":
+		return runIndex(cmdArgs, log)
+	case "diff":
+		return runDiff(cmdArgs, log)
+	case "cache":
+		return runCache(cmdArgs, log)
 	case "doctor":
 		return runDoctor(cmdArgs, log)
 	case "init":
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/logger"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/targets"
+	"heph4estus/internal/tools/nmap"
+)
+
+// masscanJobEntries returns the hosts a masscan job found open ports on,
+// one entry per host with its ports as "ports" metadata. The job's local
+// export is read when it has one, and cloud storage otherwise.
+func masscanJobEntries(ctx context.Context, store *operator.JobStore, jobID, cloudOverride string, opCfg *operator.OperatorConfig, log logger.Logger) ([]targets.Entry, error) {
+	rec, err := store.Load(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if rec.ToolName != "masscan" {
+		return nil, fmt.Errorf("job %s is a %s job, not masscan", jobID, rec.ToolName)
+	}
+	if rec.Phase != operator.PhaseComplete {
+		logStatus("Warning: masscan job %s is %s; using the results it has so far", jobID, rec.Phase)
+	}
+
+	var artifacts map[string][]byte
+	if dir := filepath.Join(rec.LocalOutputDir, "artifacts"); rec.LocalOutputDir != "" && dirExists(dir) {
+		artifacts, err = readLocalArtifacts(dir)
+	} else {
+		artifacts, err = downloadMasscanArtifacts(ctx, rec, cloudOverride, opCfg, log)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(artifacts))
+	for key := range artifacts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var records []targets.MasscanRecord
+	for _, key := range keys {
+		parsed, err := targets.ParseMasscan(artifacts[key])
+		if err != nil {
+			logStatus("Warning: skipping %s: %v", key, err)
+			continue
+		}
+		records = append(records, parsed...)
+	}
+	entries := targets.MasscanEntries(records)
+	open := entries[:0]
+	for _, e := range entries {
+		if e.Metadata["ports"] != "" {
+			open = append(open, e)
+		}
+	}
+	return open, nil
+}
+
+// masscanTargetLines renders masscan hosts as nmap target lines that scan
+// exactly the ports masscan found open on each.
+func masscanTargetLines(entries []targets.Entry, options string) string {
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.Target + " " + nmap.PortScanOptions(options, e.Metadata["ports"])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func downloadMasscanArtifacts(ctx context.Context, rec *operator.JobRecord, cloudOverride string, opCfg *operator.OperatorConfig, log logger.Logger) (map[string][]byte, error) {
+	if rec.Bucket == "" {
+		return nil, fmt.Errorf("masscan job %s has no storage bucket recorded and no local export", rec.JobID)
+	}
+	effectiveCloud := cloudOverride
+	if effectiveCloud == "" {
+		effectiveCloud = rec.Cloud
+	}
+	kind, err := resolveCLICloud(effectiveCloud, opCfg)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := buildRuntimeProvider(ctx, kind, nil, log)
+	if err != nil {
+		return nil, fmt.Errorf("building cloud provider: %w", err)
+	}
+	defer closeProvider(provider)
+	return downloadArtifacts(ctx, provider.Storage(), rec.Bucket, masscanArtifactPrefix(rec))
+}
+
+func masscanArtifactPrefix(rec *operator.JobRecord) string {
+	if rec.ArtifactPrefix != "" {
+		return rec.ArtifactPrefix
+	}
+	return jobs.ArtifactPrefix("masscan", rec.JobID)
+}
+
+// downloadArtifacts downloads every object under prefix, keyed by its path
+// below prefix.
+func downloadArtifacts(ctx context.Context, storage cloud.Storage, bucket, prefix string) (map[string][]byte, error) {
+	keys, err := storage.List(ctx, bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", prefix, err)
+	}
+	artifacts := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		data, err := storage.Download(ctx, bucket, key)
+		if err != nil {
+			return nil, fmt.Errorf("downloading %s: %w", key, err)
+		}
+		artifacts[strings.TrimPrefix(key, prefix)] = data
+	}
+	return artifacts, nil
+}
+
+// readLocalArtifacts reads every file under dir, keyed by its path below
+// dir.
+func readLocalArtifacts(dir string) (map[string][]byte, error) {
+	artifacts := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		artifacts[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading artifacts in %s: %w", dir, err)
+	}
+	return artifacts, nil
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
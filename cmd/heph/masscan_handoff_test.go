@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"heph4estus/internal/operator"
+)
+
+func TestMasscanJobEntriesFromLocalExport(t *testing.T) {
+	store := operator.NewJobStoreAt(t.TempDir())
+	exportDir := t.TempDir()
+	artifacts := filepath.Join(exportDir, "artifacts")
+	if err := os.MkdirAll(artifacts, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"10-0-0-0-24_1.json": `[{"ip":"10.0.0.5","ports":[{"port":443,"proto":"tcp","status":"open"}]},{"ip":"10.0.0.5","ports":[{"port":22,"proto":"tcp","status":"open"}]}]`,
+		"10-0-1-0-24_2.json": `[{"ip":"10.0.1.9","ports":[{"port":53,"proto":"udp","status":"open"}]}]`,
+		"broken_3.json":      `not json`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(artifacts, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Create(&operator.JobRecord{JobID: "masscan-1", ToolName: "masscan", Phase: operator.PhaseComplete, LocalOutputDir: exportDir}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := masscanJobEntries(context.Background(), store, "masscan-1", "", nil, testLogger())
+	if err != nil {
+		t.Fatalf("masscanJobEntries: %v", err)
+	}
+	got := masscanTargetLines(entries, "-sV -sC")
+	want := "10.0.0.5 -sV -sC -p 22,443\n10.0.1.9 -sV -sC -sU -p U:53"
+	if got != want {
+		t.Errorf("target lines = %q, want %q", got, want)
+	}
+}
+
+func TestMasscanJobEntriesRejectsOtherTools(t *testing.T) {
+	store := operator.NewJobStoreAt(t.TempDir())
+	if err := store.Create(&operator.JobRecord{JobID: "nmap-1", ToolName: "nmap"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := masscanJobEntries(context.Background(), store, "nmap-1", "", nil, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "not masscan") {
+		t.Fatalf("expected tool error, got %v", err)
+	}
+	if _, err := masscanJobEntries(context.Background(), store, "missing", "", nil, testLogger()); err == nil {
+		t.Fatal("expected error for a missing job")
+	}
+}
+
+func TestNmapFromMasscanJobFlagConflicts(t *testing.T) {
+	err := runNmap([]string{"--from-masscan-job", "masscan-1", "--file", "targets.txt"}, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "replaces --file") {
+		t.Fatalf("expected --file conflict, got %v", err)
+	}
+	err = runNmap([]string{"--from-masscan-job", "masscan-1", "--mode", "discover-then-scan"}, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "already knows the live hosts") {
+		t.Fatalf("expected mode conflict, got %v", err)
+	}
+}
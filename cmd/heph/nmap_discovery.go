@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/fleet"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/runner"
+	"heph4estus/internal/tools/nmap"
+)
+
+// runNmapDiscoveryWithDeps runs a discover-then-scan job through the job
+// runner, reporting each stage's progress and printing the results.
+func runNmapDiscoveryWithDeps(ctx context.Context, discovery []nmap.ScanTask, portChunks int, workers int, computeMode string, jitterMax int, format string, outputs map[string]string, queue cloud.Queue, storage cloud.Storage, compute cloud.Compute, tracker *operator.Tracker, jobID string, placementPolicy fleet.PlacementPolicy, cfg jobs.JobConfig, kind cloud.Kind, limits jobLimits) (bool, error) {
+	queueURL := outputs["sqs_queue_url"]
+	bucket := outputs["s3_bucket_name"]
+	if queueURL == "" || bucket == "" {
+		return false, fmt.Errorf("terraform outputs missing sqs_queue_url or s3_bucket_name")
+	}
+
+	r, err := newCLIRunner(queue, storage, compute, tracker, kind, outputs, bucket, queueURL, workers, computeMode, jitterMax, placementPolicy, limits)
+	if err != nil {
+		return false, err
+	}
+
+	startTime := time.Now()
+	out, err := r.RunDiscovery(ctx, cfg, jobID, discovery, portChunks, func(stage string, p runner.Progress) {
+		label := "Discovery"
+		if stage == runner.StagePortScan {
+			label = "Port scan"
+		}
+		logStatus("%s: %d/%d (%.1f%%) — elapsed %s", label, p.Completed, p.Total, p.Percent(), time.Since(startTime).Truncate(time.Second))
+	})
+	if err != nil {
+		return out.Started, err
+	}
+
+	logStatus("Scan complete: %d live hosts, %d tasks in %s", out.LiveHosts, out.TotalTasks, time.Since(startTime).Truncate(time.Second))
+	return true, outputResults(ctx, storage, bucket, jobs.ResultPrefix("nmap", jobID), format)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/cloud/mock"
+	"heph4estus/internal/fleet"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/scope"
+	nmaptool "heph4estus/internal/tools/nmap"
+	"heph4estus/internal/worker"
+)
+
+const discoveryReport = `<?xml version="1.0"?>
+<nmaprun>
+<host><status state="up"/><address addr="10.0.0.1" addrtype="ipv4"/></host>
+<host><status state="down"/><address addr="10.0.0.2" addrtype="ipv4"/></host>
+<host><status state="up"/><address addr="10.0.0.3" addrtype="ipv4"/></host>
+</nmaprun>`
+
+func TestRunNmapDiscoveryWithDeps_ScansOnlyLiveHosts(t *testing.T) {
+	const jobID = "nmap-discovery"
+	discovery := nmaptool.NewScanner(nil).ParseDiscoveryTasks("10.0.0.0/30 -sS -p 1-100", "-sS", nmaptool.DefaultDiscoveryOptions)
+	for i := range discovery {
+		discovery[i].JobID = jobID
+	}
+
+	resultKey := jobs.ResultKey("nmap", jobID, "10.0.0.0/30", nmaptool.DiscoveryGroup, 0, 1, 1, "json")
+	reportKey := jobs.ArtifactKey("nmap", jobID, "10.0.0.0/30", nmaptool.DiscoveryGroup, 0, 1, 1, "xml")
+	result, _ := json.Marshal(worker.Result{ToolName: "nmap", Target: "10.0.0.0/30", OutputKey: reportKey})
+	objects := map[string][]byte{resultKey: result, reportKey: []byte(discoveryReport)}
+
+	storage := &mock.Storage{
+		DownloadFunc: func(_ context.Context, _, key string) ([]byte, error) { return objects[key], nil },
+		ListFunc: func(_ context.Context, _, prefix string) ([]string, error) {
+			var keys []string
+			for k := range objects {
+				if strings.HasPrefix(k, prefix) {
+					keys = append(keys, k)
+				}
+			}
+			return keys, nil
+		},
+		// Every stage finishes on its first progress check.
+		CountFunc: func(context.Context, string, string) (int, error) { return 100, nil },
+	}
+	var enqueued [][]worker.Task
+	queue := &mock.Queue{SendBatchFunc: func(_ context.Context, _ string, bodies []string) error {
+		batch := make([]worker.Task, len(bodies))
+		for i, b := range bodies {
+			if err := json.Unmarshal([]byte(b), &batch[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		enqueued = append(enqueued, batch)
+		return nil
+	}}
+	compute := &mockCompute{}
+	sc, err := scope.New([]string{"10.0.0.0/24"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started, err := runNmapDiscoveryWithDeps(context.Background(), discovery, 2, 1, "fargate", 0, "text",
+		testOutputs(), queue, storage, compute, operator.NoopTracker(), jobID, fleet.PlacementPolicy{}, jobs.JobConfig{Scope: sc}, cloud.KindAWS, jobLimits{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !started {
+		t.Fatal("expected started=true")
+	}
+	if len(enqueued) != 2 {
+		t.Fatalf("expected discovery and port scan batches, got %d", len(enqueued))
+	}
+	if got := enqueued[0][0].Options; got != "-sn" {
+		t.Errorf("discovery options = %q, want -sn", got)
+	}
+	// 10.0.0.2 is down, so only the two live hosts are port scanned.
+	ports := enqueued[1]
+	if len(ports) != 4 {
+		t.Fatalf("expected 2 port chunks for each of 2 live hosts, got %d", len(ports))
+	}
+	for _, task := range ports {
+		if task.Target != "10.0.0.1" && task.Target != "10.0.0.3" {
+			t.Errorf("port scan target = %q, want a live host", task.Target)
+		}
+		if !strings.HasPrefix(task.Options, "-Pn -sS -p ") {
+			t.Errorf("port scan options = %q", task.Options)
+		}
+	}
+	if compute.runContainerN != 2 {
+		t.Errorf("expected workers launched for each stage, got %d launches", compute.runContainerN)
+	}
+}
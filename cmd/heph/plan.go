@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"heph4estus/internal/bench"
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/estimate"
+	"heph4estus/internal/fleet"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/runner"
+	"heph4estus/internal/scope"
+	"heph4estus/internal/tools/dns"
+)
+
+// newPlanFile records the execution settings of a dry-run plan.
+func newPlanFile(jobID, tool string, cloudKind cloud.Kind, workers int, computeMode string, placement fleet.PlacementPolicy, sc *scope.Scope) *runner.PlanFile {
+	pf := &runner.PlanFile{
+		Version:     runner.PlanFileVersion,
+		CreatedAt:   time.Now().UTC(),
+		JobID:       jobID,
+		ToolName:    tool,
+		Cloud:       string(cloudKind),
+		Workers:     workers,
+		ComputeMode: computeMode,
+		Placement:   placement,
+	}
+	if sc != nil {
+		pf.Scope = sc.Digest()
+	}
+	return pf
+}
+
+// plannedComputeMode resolves the compute mode a launch would use: "spot"
+// or "fargate" on AWS, empty on clouds without a choice.
+func plannedComputeMode(kind cloud.Kind, mode string, workers int) (string, bool) {
+	if kind.Canonical() != cloud.KindAWS {
+		return "", false
+	}
+	if resolveComputeMode(mode, workers) {
+		return "spot", true
+	}
+	return "fargate", false
+}
+
+// finishPlan estimates, saves and prints a dry-run plan. out overrides the
+// default location under the operator config directory.
+func finishPlan(w io.Writer, pf *runner.PlanFile, out, format string) error {
+	kind := cloud.Kind(pf.Cloud)
+	mode, spot := plannedComputeMode(kind, pf.ComputeMode, pf.Workers)
+	pf.Spot = spot
+	pf.Estimate = estimate.Compute(estimate.Input{
+		Tasks:       pf.TaskCount,
+		Workers:     pf.Workers,
+		Cloud:       kind,
+		ComputeMode: mode,
+		Samples:     loadEstimateSamples(pf.ToolName),
+	})
+
+	if out == "" {
+		dir, err := runner.PlansDir()
+		if err != nil {
+			return err
+		}
+		out = filepath.Join(dir, pf.JobID+".json")
+	}
+	if err := pf.Save(out); err != nil {
+		return err
+	}
+	return printPlan(w, pf, out, format)
+}
+
+// loadEstimateSamples reads per-task history for tool from the local job
+// and benchmark stores. Missing history only makes the estimate rougher.
+func loadEstimateSamples(tool string) []estimate.Sample {
+	jobStore, _ := operator.NewJobStore()
+	benchStore, _ := bench.NewStore()
+	samples, err := estimate.LoadSamples(jobStore, benchStore, tool)
+	if err != nil {
+		logStatus("Warning: reading job history: %v", err)
+	}
+	return samples
+}
+
+func printPlan(w io.Writer, pf *runner.PlanFile, path, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Path string `json:"path"`
+			*runner.PlanFile
+		}{path, pf})
+	}
+
+	fmt.Fprintf(w, "Plan %s (%s)\n", pf.JobID, pf.ToolName)
+	fmt.Fprintf(w, "  Tasks:     %d %s\n", pf.TaskCount, pf.Unit)
+	if pf.Mode != "" {
+		fmt.Fprintf(w, "  Mode:      %s; the port scan of live hosts is not estimated\n", pf.Mode)
+	}
+	if pf.SourceJobID != "" {
+		fmt.Fprintf(w, "  Source:    ports found by job %s\n", pf.SourceJobID)
+	}
+	if a := pf.Assets; a != nil {
+		fmt.Fprintf(w, "  Assets:    %s from %s (%d files, %s)\n", a.Name, a.Dir, a.Files, formatByteSize(int64(a.Size)))
+	}
+	if pf.TemplateShards != nil {
+		fmt.Fprintf(w, "  Templates: %s\n", pf.TemplateShards)
+	}
+	if pf.WildcardProbe {
+		fmt.Fprintf(w, "  Wildcard:  %d random-name DNS probes per zone, included in the task count\n", dns.ProbesPerZone)
+	}
+	if wl := pf.Wordlist; wl != nil {
+		fmt.Fprintf(w, "  Wordlist:  %s (%d entries, %s) in %d chunks\n", wl.Path, wl.TotalWords, formatByteSize(wl.Size), wl.Chunks)
+		if !wl.Transform.IsZero() {
+			fmt.Fprintf(w, "  Rewrite:   %s\n", wl.Transform)
+		}
+		if wl.TaskDuration > 0 {
+			fmt.Fprintf(w, "  Chunking:  adaptive, about %s per task after a calibration sample\n", wl.TaskDuration)
+		}
+		for _, name := range sortedKeys(wl.WholePaths()) {
+			whole := wl.Whole[name]
+			fmt.Fprintf(w, "  Wordlist:  %s=%s (%d entries, %s) shipped whole\n", name, whole.Path, whole.TotalWords, formatByteSize(whole.Size))
+		}
+	}
+	if pf.RuntimeTarget != "" {
+		fmt.Fprintf(w, "  Target:    %s\n", pf.RuntimeTarget)
+	}
+	runtimeName := pf.Cloud
+	if mode, _ := plannedComputeMode(cloud.Kind(pf.Cloud), pf.ComputeMode, pf.Workers); mode != "" {
+		runtimeName += "/" + mode
+	}
+	fmt.Fprintf(w, "  Runtime:   %s, %d workers\n", runtimeName, pf.Workers)
+	if pf.Scope != nil {
+		fmt.Fprintf(w, "  Scope:     %s\n", pf.Scope.SHA256)
+	}
+	if pf.Window != nil {
+		fmt.Fprintf(w, "  Window:    %s\n", pf.Window)
+	}
+	if pf.Politeness != nil {
+		fmt.Fprintf(w, "  Per host:  %s\n", pf.Politeness)
+	}
+	if pf.GlobalRate > 0 {
+		fmt.Fprintf(w, "  Rate:      %d/s global, %d/s per worker\n", pf.GlobalRate, fleet.RateShare(pf.GlobalRate, pf.Workers))
+	}
+	if est := pf.Estimate; est != nil {
+		fmt.Fprintf(w, "  Estimate:  %s at %s/task (%s)\n", est.Runtime.Round(time.Second), est.PerTask.Round(time.Second), est.Basis)
+		fmt.Fprintf(w, "\n  %-10s %-8s %-28s %10s %12s %9s\n", "CLOUD", "MODE", "SIZE", "RUNTIME", "WORKER-HRS", "USD")
+		for _, c := range est.Costs {
+			marker := " "
+			if c.Selected {
+				marker = "*"
+			}
+			fmt.Fprintf(w, "%s %-10s %-8s %-28s %10s %12.2f %9.2f\n", marker, c.Cloud, c.ComputeMode, c.Size, c.Runtime.Round(time.Second), c.WorkerHours, c.USD)
+		}
+	}
+	fmt.Fprintf(w, "\nSaved plan to %s\nRun it with: heph %s --from-plan %s\n", path, planCommand(pf.ToolName), path)
+	return nil
+}
+
+func planCommand(tool string) string {
+	if tool == "nmap" {
+		return "nmap"
+	}
+	return "scan"
+}
+
+// loadPlanForTool loads a saved plan for the scan or nmap command.
+func loadPlanForTool(ref string, nmapCmd bool) (*runner.PlanFile, error) {
+	pf, err := runner.LoadPlanFile(ref)
+	if err != nil {
+		return nil, err
+	}
+	if nmapCmd != (pf.ToolName == "nmap") {
+		return nil, fmt.Errorf("plan %s is for %q; run it with heph %s --from-plan", pf.JobID, pf.ToolName, planCommand(pf.ToolName))
+	}
+	if err := pf.VerifyWordlist(); err != nil {
+		return nil, err
+	}
+	if err := pf.VerifyAssets(); err != nil {
+		return nil, err
+	}
+	if store := newTracker().Store(); store != nil {
+		if _, err := store.Load(pf.JobID); err == nil {
+			return nil, fmt.Errorf("plan %s has already been run; create a new plan with --plan", pf.JobID)
+		}
+	}
+	logStatus("Loaded plan %s: %d %s on %s with %d workers", pf.JobID, pf.TaskCount, pf.Unit, pf.Cloud, pf.Workers)
+	return pf, nil
+}
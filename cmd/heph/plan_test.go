@@ -0,0 +1,395 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"heph4estus/internal/runner"
+	"heph4estus/internal/tools/dns"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunScanPlanSavesPlanWithoutCloud(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	input := writeTestFile(t, "targets.txt", "example.com\nexample.com\nhttps://api.example.com -title\n")
+	out := filepath.Join(t.TempDir(), "plan.json")
+
+	// AWS would need infrastructure; --plan must return before reaching it.
+	err := runScan([]string{"--tool", "httpx", "--file", input, "--options", "-silent", "--workers", "50", "--cloud", "aws", "--plan", "--plan-out", out}, testLogger())
+	if err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	pf, err := runner.LoadPlanFile(out)
+	if err != nil {
+		t.Fatalf("LoadPlanFile: %v", err)
+	}
+	if pf.ToolName != "httpx" || pf.TaskCount != 2 || len(pf.Tasks) != 2 || !pf.Spot {
+		t.Fatalf("plan = %+v", pf)
+	}
+	if pf.Tasks[1].Options != "-silent -title" || pf.Tasks[1].JobID != pf.JobID {
+		t.Errorf("task = %+v", pf.Tasks[1])
+	}
+	if pf.Estimate == nil || pf.Estimate.Workers != 2 || len(pf.Estimate.Costs) == 0 {
+		t.Errorf("estimate = %+v", pf.Estimate)
+	}
+}
+
+func TestRunScanPlanWordlistPinsFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	wordlist := writeTestFile(t, "words.txt", "admin\nlogin\nbackup\n")
+	err := runScan([]string{"--tool", "ffuf", "--wordlist", wordlist, "--target", "https://example.com/FUZZ", "--chunks", "2", "--cloud", "local", "--plan"}, testLogger())
+	if err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	dir, _ := runner.PlansDir()
+	matches, _ := filepath.Glob(filepath.Join(dir, "ffuf-*.json"))
+	if len(matches) != 1 {
+		t.Fatalf("saved plans = %v", matches)
+	}
+	pf, err := runner.LoadPlanFile(strings.TrimSuffix(filepath.Base(matches[0]), ".json"))
+	if err != nil {
+		t.Fatalf("LoadPlanFile by job ID: %v", err)
+	}
+	if pf.Wordlist == nil || pf.Wordlist.Chunks != 2 || pf.Wordlist.TotalWords != 3 || pf.TaskCount != 2 || pf.Unit != "chunks" {
+		t.Fatalf("plan = %+v wordlist = %+v", pf, pf.Wordlist)
+	}
+
+	if err := os.WriteFile(wordlist, []byte("changed\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err = runScan([]string{"--from-plan", matches[0]}, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "changed since it was planned") {
+		t.Errorf("expected changed wordlist error, got %v", err)
+	}
+}
+
+func TestRunScanPlanCountsWildcardProbes(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	input := writeTestFile(t, "hosts.txt", "www.example.com\napi.example.com\nwww.example.org\n")
+	out := filepath.Join(t.TempDir(), "plan.json")
+	if err := runScan([]string{"--tool", "dnsx", "--file", input, "--cloud", "local", "--plan", "--plan-out", out}, testLogger()); err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	pf, err := runner.LoadPlanFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pf.WildcardProbe || pf.TaskCount != 3+2*dns.ProbesPerZone || len(pf.Tasks) != 3 {
+		t.Fatalf("wildcard probe = %v, task count = %d, tasks = %d", pf.WildcardProbe, pf.TaskCount, len(pf.Tasks))
+	}
+
+	out = filepath.Join(t.TempDir(), "plan.json")
+	if err := runScan([]string{"--tool", "dnsx", "--file", input, "--no-wildcard-probe", "--cloud", "local", "--plan", "--plan-out", out}, testLogger()); err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	if pf, err = runner.LoadPlanFile(out); err != nil || pf.WildcardProbe || pf.TaskCount != 3 {
+		t.Errorf("plan = %+v, %v", pf, err)
+	}
+}
+
+func TestRunScanFromPlanRejectsInputFlags(t *testing.T) {
+	err := runScan([]string{"--from-plan", "plan.json", "--file", "targets.txt"}, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "--from-plan replaces") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRunNmapPlanPinsScripts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	scripts := t.TempDir()
+	if err := os.WriteFile(filepath.Join(scripts, "probe.nse"), []byte("-- probe\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input := writeTestFile(t, "targets.txt", "10.0.0.1\n")
+	out := filepath.Join(t.TempDir(), "nmap-plan.json")
+	if err := runNmap([]string{"--file", input, "--scripts", scripts, "--cloud", "local", "--plan", "--plan-out", out}, testLogger()); err != nil {
+		t.Fatalf("runNmap --plan: %v", err)
+	}
+	pf, err := loadPlanForTool(out, true)
+	if err != nil {
+		t.Fatalf("loadPlanForTool: %v", err)
+	}
+	if a := pf.Assets; a == nil || a.Name != "scripts" || a.Dir != scripts || a.Files != 1 {
+		t.Fatalf("plan assets = %+v", pf.Assets)
+	}
+
+	err = runNmap([]string{"--file", input, "--scripts", input, "--cloud", "local", "--plan"}, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("--scripts with a file = %v", err)
+	}
+}
+
+func TestRunNmapPlanKeepsPortChunks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	input := writeTestFile(t, "targets.txt", "10.0.0.1\n10.0.0.2 -sV -p 22,80\n")
+	out := filepath.Join(t.TempDir(), "nmap-plan.json")
+	err := runNmap([]string{"--file", input, "--mode", "target-ports", "--port-chunks", "2", "--cloud", "local", "--plan", "--plan-out", out, "--format", "json"}, testLogger())
+	if err != nil {
+		t.Fatalf("runNmap --plan: %v", err)
+	}
+	pf, err := runner.LoadPlanFile(out)
+	if err != nil {
+		t.Fatalf("LoadPlanFile: %v", err)
+	}
+	if pf.ToolName != "nmap" || pf.TaskCount != len(pf.Tasks) || pf.TaskCount < 3 {
+		t.Fatalf("plan = %+v", pf)
+	}
+	tasks := runner.NmapScanTasks(pf.Tasks)
+	back := runner.NmapTasks(tasks)
+	for i := range back {
+		if back[i].GroupID != pf.Tasks[i].GroupID || back[i].ChunkIdx != pf.Tasks[i].ChunkIdx || back[i].Options != pf.Tasks[i].Options {
+			t.Errorf("task %d = %+v, want %+v", i, back[i], pf.Tasks[i])
+		}
+	}
+
+	if _, err := loadPlanForTool(out, false); err == nil || !strings.Contains(err.Error(), "heph nmap --from-plan") {
+		t.Errorf("expected wrong-command error, got %v", err)
+	}
+}
+
+func TestRunScanGlobalRateValidation(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	input := writeTestFile(t, "targets.txt", "example.com\n")
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"not rate limited", []string{"--tool", "subfinder", "--global-rate", "100"}, "no {{rate}} placeholder"},
+		{"below workers", []string{"--tool", "httpx", "--global-rate", "5", "--workers", "10"}, "below the worker count"},
+		{"negative", []string{"--tool", "httpx", "--global-rate", "-1"}, "--global-rate must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--file", input, "--cloud", "local", "--plan"}, tt.args...)
+			err := runScan(args, testLogger())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("runScan error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunScanPlanRecordsGlobalRate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	input := writeTestFile(t, "targets.txt", "example.com\n")
+	out := filepath.Join(t.TempDir(), "plan.json")
+	err := runScan([]string{"--tool", "nuclei", "--file", input, "--workers", "4", "--global-rate", "600", "--cloud", "local", "--plan", "--plan-out", out}, testLogger())
+	if err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	pf, err := runner.LoadPlanFile(out)
+	if err != nil {
+		t.Fatalf("LoadPlanFile: %v", err)
+	}
+	if pf.GlobalRate != 600 {
+		t.Errorf("plan global rate = %d, want 600", pf.GlobalRate)
+	}
+}
+
+func TestRunScanPlanRecordsPerTargetLimits(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	wordlist := writeTestFile(t, "words.txt", "admin\nlogin\n")
+	out := filepath.Join(t.TempDir(), "plan.json")
+	err := runScan([]string{"--tool", "ffuf", "--wordlist", wordlist, "--target", "https://example.com/FUZZ", "--per-target-concurrency", "2", "--per-target-rate", "30", "--cloud", "local", "--plan", "--plan-out", out}, testLogger())
+	if err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	pf, err := runner.LoadPlanFile(out)
+	if err != nil {
+		t.Fatalf("LoadPlanFile: %v", err)
+	}
+	if pf.Politeness == nil || pf.Politeness.Concurrency != 2 || pf.Politeness.Rate != 30 {
+		t.Errorf("plan politeness = %+v", pf.Politeness)
+	}
+
+	err = runScan([]string{"--tool", "ffuf", "--wordlist", wordlist, "--target", "https://example.com/FUZZ", "--per-target-concurrency", "-1", "--cloud", "local", "--plan"}, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "per-target concurrency") {
+		t.Fatalf("negative concurrency error = %v", err)
+	}
+}
+
+func TestRunScanPlanRecordsWordlistTransform(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	wordlist := writeTestFile(t, "words.txt", "Admin\nadmin\nlogin\n")
+	out := filepath.Join(t.TempDir(), "plan.json")
+	err := runScan([]string{"--tool", "ffuf", "--wordlist", wordlist, "--target", "https://example.com/FUZZ", "--dedupe", "--lowercase", "--extensions", "php,bak", "--cloud", "local", "--plan", "--plan-out", out}, testLogger())
+	if err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	pf, err := runner.LoadPlanFile(out)
+	if err != nil {
+		t.Fatalf("LoadPlanFile: %v", err)
+	}
+	tr := pf.Wordlist.Transform
+	if tr == nil || !tr.Dedupe || !tr.Lowercase || strings.Join(tr.Extensions, ",") != "php,bak" {
+		t.Fatalf("plan transform = %+v", tr)
+	}
+	if pf.Wordlist.TotalWords != 6 {
+		t.Errorf("TotalWords = %d, want 6 after dedupe and extensions", pf.Wordlist.TotalWords)
+	}
+
+	err = runScan([]string{"--tool", "ffuf", "--wordlist", wordlist, "--target", "https://example.com/FUZZ", "--include-regex", "(", "--cloud", "local", "--plan"}, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "invalid include regex") {
+		t.Fatalf("bad regex error = %v", err)
+	}
+	targetsFile := writeTestFile(t, "targets.txt", "example.com\n")
+	err = runScan([]string{"--tool", "httpx", "--file", targetsFile, "--dedupe", "--cloud", "local", "--plan"}, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "not valid for target_list tool") {
+		t.Fatalf("target_list rewrite error = %v", err)
+	}
+}
+
+func TestRunScanPlanRecordsNamedWordlists(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	paths := writeTestFile(t, "paths.txt", "admin\nlogin\n")
+	users := writeTestFile(t, "users.txt", "root\nguest\nops\n")
+	out := filepath.Join(t.TempDir(), "plan.json")
+	err := runScan([]string{"--tool", "ffuf", "--wordlist", "FUZZ=" + paths, "--wordlist", "W2=" + users, "--target", "https://example.com/FUZZ?u=W2", "--cloud", "local", "--plan", "--plan-out", out}, testLogger())
+	if err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	pf, err := runner.LoadPlanFile(out)
+	if err != nil {
+		t.Fatalf("LoadPlanFile: %v", err)
+	}
+	if pf.Wordlist.TotalWords != 2 || !strings.HasSuffix(pf.Wordlist.Path, "paths.txt") {
+		t.Fatalf("chunked wordlist = %+v", pf.Wordlist)
+	}
+	w2 := pf.Wordlist.Whole["W2"]
+	if w2 == nil || w2.TotalWords != 3 || !strings.HasSuffix(w2.Path, "users.txt") {
+		t.Fatalf("whole wordlists = %+v", pf.Wordlist.Whole)
+	}
+	if err := pf.VerifyWordlist(); err != nil {
+		t.Fatalf("VerifyWordlist: %v", err)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--tool", "ffuf", "--wordlist", paths, "--wordlist", "W9=" + users}, `has no wordlist "W9"`},
+		{[]string{"--tool", "ffuf", "--wordlist", paths, "--wordlist", "FUZZ=" + users}, "both set the chunked wordlist"},
+		{[]string{"--tool", "gobuster", "--wordlist", paths, "--wordlist", "W2=" + users}, "takes a single wordlist"},
+	} {
+		err := runScan(append(tc.args, "--target", "https://example.com/", "--cloud", "local", "--plan"), testLogger())
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("runScan(%v) = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
+func TestRunScanPlanPitchforkShipsOneChunk(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	paths := writeTestFile(t, "paths.txt", "admin\nlogin\nbackup\nstatic\n")
+	users := writeTestFile(t, "users.txt", "root\nguest\nops\nweb\n")
+	args := []string{"--tool", "ffuf", "--wordlist", "FUZZ=" + paths, "--wordlist", "W2=" + users, "--target", "https://example.com/FUZZ?u=W2", "--workers", "4", "--cloud", "local", "--plan"}
+
+	out := filepath.Join(t.TempDir(), "plan.json")
+	if err := runScan(append(args, "--options", "-mode pitchfork", "--plan-out", out), testLogger()); err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	pf, err := runner.LoadPlanFile(out)
+	if err != nil {
+		t.Fatalf("LoadPlanFile: %v", err)
+	}
+	if pf.Wordlist.Chunks != 1 {
+		t.Fatalf("pitchfork chunks = %d, want 1", pf.Wordlist.Chunks)
+	}
+
+	err = runScan(append(args, "--options", "-mode=pitchfork", "--chunks", "2"), testLogger())
+	if err == nil || !strings.Contains(err.Error(), "cannot be split") {
+		t.Fatalf("pitchfork --chunks 2 error = %v", err)
+	}
+}
+
+func TestRunScanPlanRecordsTaskDuration(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	wordlist := writeTestFile(t, "words.txt", "admin\nlogin\n")
+	out := filepath.Join(t.TempDir(), "plan.json")
+	err := runScan([]string{"--tool", "ffuf", "--wordlist", wordlist, "--target", "https://example.com/FUZZ", "--task-duration", "5m", "--cloud", "local", "--plan", "--plan-out", out}, testLogger())
+	if err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	pf, err := runner.LoadPlanFile(out)
+	if err != nil {
+		t.Fatalf("LoadPlanFile: %v", err)
+	}
+	if pf.Wordlist.TaskDuration != 5*time.Minute {
+		t.Fatalf("TaskDuration = %s, want 5m", pf.Wordlist.TaskDuration)
+	}
+
+	err = runScan([]string{"--tool", "ffuf", "--wordlist", wordlist, "--target", "https://example.com/FUZZ", "--task-duration", "5m", "--chunks", "4", "--cloud", "local", "--plan"}, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Fatalf("--task-duration with --chunks = %v", err)
+	}
+}
+
+func TestRunScanPlanRecordsTemplateShards(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	templates := t.TempDir()
+	for _, rel := range []string{"http/a.yaml", "http/b.yaml", "dns/c.yaml"} {
+		path := filepath.Join(templates, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("id: "+filepath.Base(rel)+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	input := writeTestFile(t, "targets.txt", "a.example\nb.example\n")
+	out := filepath.Join(t.TempDir(), "plan.json")
+	err := runScan([]string{"--tool", "nuclei", "--file", input, "--template-shards", "2", "--templates", templates, "--cloud", "local", "--plan", "--plan-out", out}, testLogger())
+	if err != nil {
+		t.Fatalf("runScan --plan: %v", err)
+	}
+	pf, err := runner.LoadPlanFile(out)
+	if err != nil {
+		t.Fatalf("LoadPlanFile: %v", err)
+	}
+	if pf.TemplateShards == nil || len(pf.TemplateShards.Shards) != 2 || pf.TaskCount != 4 || len(pf.Tasks) != 2 {
+		t.Fatalf("plan shards = %+v, tasks = %d of %d", pf.TemplateShards, len(pf.Tasks), pf.TaskCount)
+	}
+	// The uploaded templates replace the installed set, so shards select
+	// templates by ID within the bundle.
+	if !strings.HasPrefix(pf.TemplateShards.Shards[0].Filter, "-id ") {
+		t.Errorf("shard filter = %q", pf.TemplateShards.Shards[0].Filter)
+	}
+	if a := pf.Assets; a == nil || a.Name != "templates" || a.Files != 3 || a.SHA256 == "" {
+		t.Fatalf("plan assets = %+v", a)
+	}
+	if err := pf.VerifyAssets(); err != nil {
+		t.Fatalf("VerifyAssets: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templates, "dns", "d.yaml"), []byte("id: d\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := pf.VerifyAssets(); err == nil {
+		t.Error("VerifyAssets accepted a changed bundle")
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--tool", "httpx", "--file", input, "--template-shards", "2", "--templates", templates}, "not supported by tool"},
+		{[]string{"--tool", "nuclei", "--file", input, "--template-shards", "2", "--templates", templates, "--options", "-tags cve"}, "cannot be combined with template shards"},
+		{[]string{"--tool", "nuclei", "--file", input, "--shard-by", "tag"}, "requires --template-shards"},
+	} {
+		err := runScan(append(tc.args, "--cloud", "local", "--plan"), testLogger())
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("runScan(%v) = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+
+	"heph4estus/internal/politeness"
+	"heph4estus/internal/runner"
+)
+
+// politenessFlags holds the per-target limit flags shared by scan and nmap.
+type politenessFlags struct {
+	concurrency *int
+	rate        *float64
+}
+
+func addPolitenessFlags(fs *flag.FlagSet) *politenessFlags {
+	return &politenessFlags{
+		concurrency: fs.Int("per-target-concurrency", 0, "Maximum tasks running against one target host at once, across all workers (default: unlimited)"),
+		rate:        fs.Float64("per-target-rate", 0, "Maximum task starts against one target host per minute, across all workers (default: unlimited)"),
+	}
+}
+
+// resolve returns the job's per-target limits, or nil when none are set. A
+// saved plan keeps the limits it was planned with.
+func (f *politenessFlags) resolve(saved *runner.PlanFile) (*politeness.Limits, error) {
+	if saved != nil && saved.Politeness != nil {
+		return saved.Politeness, nil
+	}
+	lim := politeness.Limits{Concurrency: *f.concurrency, Rate: *f.rate}
+	if err := lim.Validate(); err != nil {
+		return nil, err
+	}
+	if lim.IsZero() {
+		return nil, nil
+	}
+	return &lim, nil
+}
@@ -3,15 +3,21 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 
 	"heph4estus/internal/cloud"
 	"heph4estus/internal/cloud/factory"
 	"heph4estus/internal/fleet"
+	"heph4estus/internal/infra"
 	"heph4estus/internal/logger"
 )
 
-var waitForProviderNativeFleetFunc = waitForProviderNativeFleet
+var (
+	waitForProviderNativeFleetFunc = waitForProviderNativeFleet
+	stopProviderNativeFleetFunc    = stopProviderNativeFleet
+)
 
 func buildRuntimeProvider(ctx context.Context, kind cloud.Kind, outputs map[string]string, log logger.Logger) (cloud.Provider, error) {
 	if kind.IsProviderNative() && outputs != nil {
@@ -24,6 +30,14 @@ func buildRuntimeProvider(ctx context.Context, kind cloud.Kind, outputs map[stri
 	return factory.BuildForKind(ctx, kind, log)
 }
 
+// closeProvider releases providers that own local resources, such as the
+// embedded NATS server behind --cloud local.
+func closeProvider(p cloud.Provider) {
+	if c, ok := p.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
 func waitForProviderNativeFleet(ctx context.Context, kind cloud.Kind, outputs map[string]string, policy fleet.PlacementPolicy) (int, error) {
 	natsURL := outputs["nats_url"]
 	if natsURL == "" {
@@ -65,6 +79,17 @@ func waitForProviderNativeFleet(ctx context.Context, kind cloud.Kind, outputs ma
 	return summary.EligibleCount, nil
 }
 
+// stopProviderNativeFleet scales the tool's provider-native fleet to zero
+// workers, keeping the controller so the next job can scale it back up.
+func stopProviderNativeFleet(ctx context.Context, kind cloud.Kind, tool string) error {
+	toolCfg, err := infra.ResolveToolConfig(tool, kind)
+	if err != nil {
+		return err
+	}
+	logStatus("Scaling the %s fleet to 0 workers...", kind.Canonical())
+	return infra.RunScaleWorkers(ctx, toolCfg, 0, os.Stderr, logger.NewSimpleLogger())
+}
+
 func fleetWorkerCount(outputs map[string]string) int {
 	if outputs == nil {
 		return 0
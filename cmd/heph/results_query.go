@@ -0,0 +1,576 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/findings"
+	"heph4estus/internal/index"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/logger"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/tui/core"
+)
+
+// resultsFormats lists the output formats of heph results.
+var resultsFormats = []string{"table", "jsonl", "csv", "markdown"}
+
+// resultsFilter selects the tasks and findings heph results lists.
+type resultsFilter struct {
+	status     string // "", "ok" or "error"
+	target     string // lower-cased substring
+	severities map[findings.Severity]bool
+	types      map[findings.Type]bool
+	port       int
+}
+
+// task reports whether t passes the task filters.
+func (f resultsFilter) task(t findings.Task) bool {
+	switch f.status {
+	case "ok":
+		if t.Failed() {
+			return false
+		}
+	case "error":
+		if !t.Failed() {
+			return false
+		}
+	}
+	return true
+}
+
+// finding reports whether a finding of t passes the finding filters. The
+// target filter matches the task's target or the finding's asset or URL.
+func (f resultsFilter) finding(t findings.Task, fd findings.Finding) bool {
+	if f.target != "" && !containsFold(t.Result.Target, f.target) && !containsFold(fd.Asset, f.target) && !containsFold(fd.URL, f.target) {
+		return false
+	}
+	if len(f.severities) > 0 && !f.severities[fd.Severity] {
+		return false
+	}
+	if len(f.types) > 0 && !f.types[fd.Type] {
+		return false
+	}
+	return f.port == 0 || fd.Port == f.port
+}
+
+func containsFold(s, sub string) bool {
+	return strings.Contains(strings.ToLower(s), sub)
+}
+
+// runResultsQuery lists the tasks of a job or the normalized findings of
+// their reports, filtered and rendered as a table, JSONL, CSV or markdown.
+// A job is read from the local results index when it has been indexed;
+// --all-jobs searches the findings of every indexed job.
+func runResultsQuery(args []string, w io.Writer, log logger.Logger) error {
+	fs := flag.NewFlagSet("results", flag.ContinueOnError)
+	jobID := fs.String("job-id", "", "Job to show; read from its --out export when it has one, else from cloud storage")
+	dir := fs.String("dir", "", "Read a locally exported job directory instead (<out>/<tool>/<job_id>)")
+	view := fs.String("view", "", "What to list: findings or tasks (default: findings, or tasks with --status)")
+	status := fs.String("status", "", "Only tasks with this status: ok or error")
+	target := fs.String("target", "", "Only tasks or findings whose target, asset or URL contains this text")
+	severity := fs.String("severity", "", "Only findings of these comma-separated severities: critical, high, medium, low, info, unknown")
+	port := fs.Int("port", 0, "Only findings on this port")
+	findingType := fs.String("type", "", "Only findings of these comma-separated types (for example port, http, vulnerability)")
+	format := fs.String("format", "table", "Output format: "+strings.Join(resultsFormats, ", "))
+	cloudFlag := fs.String("cloud", "", "Override the cloud provider the job's results are read from (default: job record or aws)")
+	refresh := fs.Bool("refresh", false, "Re-read the job's results instead of using the results index, and reindex them")
+	allJobs := fs.Bool("all-jobs", false, "Search the findings of every job in the local results index")
+	tool := fs.String("tool", "", "With --all-jobs, only findings of this tool")
+	since := fs.String("since", "", "With --all-jobs, only findings reported since a date (2006-01-02) or within an age (24h, 30d)")
+	until := fs.String("until", "", "With --all-jobs, only findings reported until a date or age")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("results: unknown subcommand %q (want merge, or flags)", fs.Arg(0))
+	}
+	if *allJobs {
+		if *jobID != "" || *dir != "" {
+			return fmt.Errorf("--all-jobs searches every indexed job; drop --job-id and --dir")
+		}
+		if *status != "" || *view == "tasks" {
+			return fmt.Errorf("--all-jobs lists findings; --status and --view tasks need --job-id or --dir")
+		}
+	} else {
+		if *tool != "" || *since != "" || *until != "" {
+			return fmt.Errorf("--tool, --since and --until filter --all-jobs searches")
+		}
+		if (*jobID == "") == (*dir == "") {
+			return fmt.Errorf("exactly one of --job-id or --dir is required, or --all-jobs to search every indexed job; usage: heph results --job-id <id> [--severity high,critical] [--format %s], heph results --all-jobs --port 8443 --since 30d, or heph results merge", strings.Join(resultsFormats, "|"))
+		}
+	}
+	if !slices.Contains(resultsFormats, *format) {
+		return fmt.Errorf("--format must be one of %s", strings.Join(resultsFormats, ", "))
+	}
+	filter, err := parseResultsFilter(*status, *target, *severity, *findingType, *port)
+	if err != nil {
+		return err
+	}
+	if *view == "" {
+		*view = "findings"
+		if *status != "" {
+			*view = "tasks"
+		}
+	}
+	switch *view {
+	case "findings":
+	case "tasks":
+		if len(filter.severities) > 0 || len(filter.types) > 0 || filter.port != 0 {
+			return fmt.Errorf("--severity, --type and --port filter findings; drop them or use --view findings")
+		}
+	default:
+		return fmt.Errorf("--view must be findings or tasks")
+	}
+
+	if *allJobs {
+		q, err := indexQuery(filter, *tool, *since, *until, time.Now())
+		if err != nil {
+			return err
+		}
+		return searchIndex(w, *format, q)
+	}
+
+	tasks, err := jobTasks(context.Background(), *jobID, *dir, *cloudFlag, *refresh, log)
+	if err != nil {
+		return err
+	}
+
+	if *view == "tasks" {
+		var rows []findings.Task
+		for _, t := range tasks {
+			if filter.task(t) && (filter.target == "" || containsFold(t.Result.Target, filter.target)) {
+				rows = append(rows, t)
+			}
+		}
+		return writeTaskRows(w, *format, rows)
+	}
+	var rows []findings.Finding
+	for _, t := range tasks {
+		if !filter.task(t) {
+			continue
+		}
+		for _, fd := range t.Findings {
+			if filter.finding(t, fd) {
+				rows = append(rows, fd)
+			}
+		}
+	}
+	findings.Sort(rows)
+	if err := writeFindingRows(w, *format, rows); err != nil {
+		return err
+	}
+	if *format == "table" {
+		failed := 0
+		for _, t := range tasks {
+			if t.Failed() {
+				failed++
+			}
+		}
+		_, _ = fmt.Fprintf(w, "\n%d findings from %d tasks (%d failed)\n", len(rows), len(tasks), failed)
+	}
+	return nil
+}
+
+// jobTasks returns the collected tasks of a job: from the results index
+// when the job is indexed and refresh is unset, else read from its results,
+// which are then indexed. A job that is still running is read fresh and not
+// indexed, and an index taken before the job finished is replaced. A
+// directory given with --dir is never indexed.
+func jobTasks(ctx context.Context, jobID, dir, cloudOverride string, refresh bool, log logger.Logger) ([]findings.Task, error) {
+	finished, finishedAt := true, time.Time{}
+	if jobID != "" {
+		finished, finishedAt = jobFinished(jobID)
+	}
+	if jobID != "" && !refresh && finished {
+		if db, err := index.OpenDefault(); err == nil {
+			job, indexed, _ := db.Job(jobID)
+			indexed = indexed && !job.IndexedAt.Before(finishedAt)
+			var tasks []findings.Task
+			if indexed {
+				tasks, err = db.Tasks(jobID)
+			}
+			_ = db.Close()
+			if indexed && err == nil {
+				return tasks, nil
+			}
+		}
+	}
+
+	src, closeSrc, err := openResultsSource(ctx, jobID, dir, cloudOverride, log)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSrc()
+	tasks, err := findings.Collect(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		if t.Err != "" {
+			log.Error("Warning: %s: %s", t.Key, t.Err)
+		}
+	}
+	if jobID != "" && finished {
+		if _, err := putIndexed(index.Job{JobID: jobID, Source: resultsSourceName(src)}, tasks); err != nil {
+			log.Error("Warning: indexing results failed: %v", err)
+		}
+	}
+	return tasks, nil
+}
+
+// jobFinished reports whether a job's results can no longer change, and
+// when its record last changed; an index taken before then may be partial.
+// A job without a record, such as one exported on another machine, counts
+// as finished.
+func jobFinished(jobID string) (bool, time.Time) {
+	store, err := operator.NewJobStore()
+	if err != nil {
+		return true, time.Time{}
+	}
+	rec, err := store.Load(jobID)
+	if err != nil {
+		return true, time.Time{}
+	}
+	return isTerminalPhase(rec.Phase), rec.UpdatedAt
+}
+
+// indexQuery turns the filter flags into a search of the results index.
+func indexQuery(f resultsFilter, tool, since, until string, now time.Time) (index.Query, error) {
+	q := index.Query{Tool: tool, Target: f.target, Port: f.port}
+	for sev := range f.severities {
+		q.Severities = append(q.Severities, sev)
+	}
+	for t := range f.types {
+		q.Types = append(q.Types, t)
+	}
+	var err error
+	if since != "" {
+		if q.Since, err = index.ParseTime(since, now); err != nil {
+			return q, fmt.Errorf("--since: %w", err)
+		}
+	}
+	if until != "" {
+		if q.Until, err = index.ParseTime(until, now); err != nil {
+			return q, fmt.Errorf("--until: %w", err)
+		}
+	}
+	return q, nil
+}
+
+// searchIndex renders the indexed findings of every job matching q.
+func searchIndex(w io.Writer, format string, q index.Query) error {
+	db, err := index.OpenDefault()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	jobs, err := db.Jobs()
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		return fmt.Errorf("the results index is empty; index jobs with heph index --job-id <id>, or export them with --out")
+	}
+	records, err := db.Search(q)
+	if err != nil {
+		return err
+	}
+	if err := writeRecordRows(w, format, records); err != nil {
+		return err
+	}
+	if format == "table" {
+		matched := make(map[string]bool)
+		for _, r := range records {
+			matched[r.JobID] = true
+		}
+		_, _ = fmt.Fprintf(w, "\n%d findings from %d of %d indexed jobs\n", len(records), len(matched), len(jobs))
+	}
+	return nil
+}
+
+// writeRecordRows renders indexed findings, with the job and time of each,
+// in format.
+func writeRecordRows(w io.Writer, format string, rows []index.Record) error {
+	if format == "jsonl" {
+		enc := json.NewEncoder(w)
+		for _, r := range rows {
+			if err := enc.Encode(r); err != nil {
+				return fmt.Errorf("encoding finding: %w", err)
+			}
+		}
+		return nil
+	}
+	if format == "csv" {
+		header := []string{"tool", "job_id", "task_id", "target", "time", "type", "severity", "asset", "port", "protocol", "service", "url", "check", "title", "evidence"}
+		table := make([][]string, len(rows))
+		for i, r := range rows {
+			table[i] = []string{r.Tool, r.JobID, r.TaskID, r.Target, r.Time.UTC().Format(time.RFC3339), string(r.Type), string(r.Severity), r.Asset, portText(r.Port), r.Protocol, r.Service, r.URL, r.Check, r.Title, r.Evidence}
+		}
+		return writeTable(w, format, header, table)
+	}
+	header := []string{"SEVERITY", "TYPE", "LOCATION", "CHECK", "TITLE", "JOB", "SEEN"}
+	table := make([][]string, len(rows))
+	for i, r := range rows {
+		table[i] = []string{strings.ToUpper(string(r.Severity)), string(r.Type), r.Location(), r.Check, r.Title, r.JobID, timeText(r.Time)}
+	}
+	return writeTable(w, format, header, table)
+}
+
+// parseResultsFilter validates the filter flags.
+func parseResultsFilter(status, target, severity, types string, port int) (resultsFilter, error) {
+	f := resultsFilter{status: strings.ToLower(status), target: strings.ToLower(target), port: port}
+	if f.status != "" && f.status != "ok" && f.status != "error" {
+		return f, fmt.Errorf("--status must be ok or error")
+	}
+	if port < 0 || port > 65535 {
+		return f, fmt.Errorf("--port must be between 1 and 65535")
+	}
+	for _, s := range splitList(severity) {
+		sev := findings.ParseSeverity(s)
+		if sev == findings.SeverityUnknown && !strings.EqualFold(s, string(findings.SeverityUnknown)) {
+			return f, fmt.Errorf("--severity: unknown severity %q", s)
+		}
+		if f.severities == nil {
+			f.severities = make(map[findings.Severity]bool)
+		}
+		f.severities[sev] = true
+	}
+	for _, t := range splitList(types) {
+		if f.types == nil {
+			f.types = make(map[findings.Type]bool)
+		}
+		f.types[findings.Type(strings.ToLower(t))] = true
+	}
+	return f, nil
+}
+
+// openResultsSource opens the results of an exported job directory, or of
+// a job by ID: its --out export when that still exists, else its bucket.
+func openResultsSource(ctx context.Context, jobID, dir, cloudOverride string, log logger.Logger) (findings.Source, func(), error) {
+	noop := func() {}
+	if dir == "" {
+		store, err := operator.NewJobStore()
+		if err != nil {
+			return nil, noop, fmt.Errorf("opening job store: %w", err)
+		}
+		rec, err := store.Load(jobID)
+		if err != nil {
+			return nil, noop, fmt.Errorf("%w — use --dir to read an exported job", err)
+		}
+		if rec.LocalOutputDir != "" {
+			if _, err := os.Stat(filepath.Join(rec.LocalOutputDir, "results")); err == nil {
+				dir = rec.LocalOutputDir
+			}
+		}
+		if dir == "" {
+			provider, err := jobProvider(ctx, rec, cloudOverride, log)
+			if err != nil {
+				return nil, noop, err
+			}
+			return &core.S3ResultsSource{
+				Storage: provider.Storage(),
+				Bucket:  rec.Bucket,
+				Prefix:  jobs.ResultPrefix(rec.ToolName, rec.JobID),
+			}, func() { closeProvider(provider) }, nil
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "results")); err != nil {
+		return nil, noop, fmt.Errorf("%s is not an exported job directory: %w", dir, err)
+	}
+	return &core.LocalResultsSource{
+		ResultsDir:   filepath.Join(dir, "results"),
+		ArtifactsDir: filepath.Join(dir, "artifacts"),
+	}, noop, nil
+}
+
+// jobProvider builds the provider holding a job's bucket: the --cloud
+// override, else the cloud on the job record.
+func jobProvider(ctx context.Context, rec *operator.JobRecord, cloudOverride string, log logger.Logger) (cloud.Provider, error) {
+	if rec.Bucket == "" {
+		return nil, fmt.Errorf("job %s has no storage bucket recorded", rec.JobID)
+	}
+	opCfg, _ := operator.LoadConfig()
+	effectiveCloud := cloudOverride
+	if effectiveCloud == "" {
+		effectiveCloud = rec.Cloud
+	}
+	cloudKind, err := resolveCLICloud(effectiveCloud, opCfg)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := buildRuntimeProvider(ctx, cloudKind, nil, log)
+	if err != nil {
+		return nil, fmt.Errorf("building cloud provider: %w", err)
+	}
+	return provider, nil
+}
+
+// writeFindingRows renders findings in format.
+func writeFindingRows(w io.Writer, format string, rows []findings.Finding) error {
+	if format == "jsonl" {
+		enc := json.NewEncoder(w)
+		for _, fd := range rows {
+			if err := enc.Encode(fd); err != nil {
+				return fmt.Errorf("encoding finding: %w", err)
+			}
+		}
+		return nil
+	}
+	if format == "csv" {
+		header := []string{"tool", "job_id", "task_id", "type", "severity", "asset", "port", "protocol", "service", "url", "check", "title", "evidence"}
+		table := make([][]string, len(rows))
+		for i, fd := range rows {
+			table[i] = []string{fd.Tool, fd.JobID, fd.TaskID, string(fd.Type), string(fd.Severity), fd.Asset, portText(fd.Port), fd.Protocol, fd.Service, fd.URL, fd.Check, fd.Title, fd.Evidence}
+		}
+		return writeTable(w, format, header, table)
+	}
+	header := []string{"SEVERITY", "TYPE", "LOCATION", "CHECK", "TITLE", "EVIDENCE"}
+	table := make([][]string, len(rows))
+	for i, fd := range rows {
+		table[i] = []string{strings.ToUpper(string(fd.Severity)), string(fd.Type), fd.Location(), fd.Check, fd.Title, fd.Evidence}
+	}
+	return writeTable(w, format, header, table)
+}
+
+// writeTaskRows renders one row per task in format.
+func writeTaskRows(w io.Writer, format string, rows []findings.Task) error {
+	if format == "jsonl" {
+		enc := json.NewEncoder(w)
+		for _, t := range rows {
+			if err := enc.Encode(t.Result); err != nil {
+				return fmt.Errorf("encoding result: %w", err)
+			}
+		}
+		return nil
+	}
+	header := []string{"TARGET", "CHUNK", "STATUS", "FINDINGS", "DURATION", "ERROR"}
+	if format == "csv" {
+		header = []string{"target", "chunk", "status", "findings", "duration_ms", "error"}
+	}
+	table := make([][]string, len(rows))
+	for i, t := range rows {
+		r := t.Result
+		target := r.Target
+		if target == "" {
+			target = jobs.TargetFromKey(t.Key)
+		}
+		chunk := ""
+		if r.TotalChunks > 0 {
+			chunk = fmt.Sprintf("%d/%d", r.ChunkIdx+1, r.TotalChunks)
+		}
+		status := "OK"
+		if t.Failed() {
+			status = "ERROR"
+		}
+		duration := ""
+		if r.DurationMs > 0 {
+			duration = (time.Duration(r.DurationMs) * time.Millisecond).String()
+			if format == "csv" {
+				duration = strconv.FormatInt(r.DurationMs, 10)
+			}
+		}
+		table[i] = []string{target, chunk, status, strconv.Itoa(len(t.Findings)), duration, firstNonEmptyString(r.Error, t.Err)}
+	}
+	return writeTable(w, format, header, table)
+}
+
+func portText(port int) string {
+	if port == 0 {
+		return ""
+	}
+	return strconv.Itoa(port)
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// maxTableCell caps a table column's width; longer cells are truncated.
+const maxTableCell = 60
+
+// writeTable renders rows as an aligned text table, CSV or a markdown
+// table.
+func writeTable(w io.Writer, format string, header []string, rows [][]string) error {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		_ = cw.Write(header)
+		for _, row := range rows {
+			_ = cw.Write(row)
+		}
+		cw.Flush()
+		return cw.Error()
+	case "markdown":
+		cell := func(s string) string {
+			s = strings.ReplaceAll(s, "|", `\|`)
+			return strings.Join(strings.Fields(s), " ")
+		}
+		line := func(cells []string) string {
+			out := make([]string, len(cells))
+			for i, c := range cells {
+				out[i] = cell(c)
+			}
+			return "| " + strings.Join(out, " | ") + " |\n"
+		}
+		sep := make([]string, len(header))
+		for i := range sep {
+			sep[i] = "---"
+		}
+		_, _ = io.WriteString(w, line(header))
+		_, _ = io.WriteString(w, line(sep))
+		for _, row := range rows {
+			if _, err := io.WriteString(w, line(row)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	flat := func(s string) string {
+		return truncate(strings.Join(strings.Fields(s), " "), maxTableCell)
+	}
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = len(h)
+	}
+	for _, row := range rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], len(flat(c)))
+		}
+	}
+	line := func(cells []string) string {
+		var b strings.Builder
+		for i, c := range cells {
+			if i == len(cells)-1 {
+				b.WriteString(flat(c))
+				break
+			}
+			fmt.Fprintf(&b, "%-*s  ", widths[i], flat(c))
+		}
+		return strings.TrimRight(b.String(), " ") + "\n"
+	}
+	_, _ = io.WriteString(w, line(header))
+	for _, row := range rows {
+		if _, err := io.WriteString(w, line(row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
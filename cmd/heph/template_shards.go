@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/modules"
+	"heph4estus/internal/runner"
+	"heph4estus/internal/tools/nuclei"
+)
+
+// templateShardFlags holds the nuclei template sharding flags of heph scan.
+type templateShardFlags struct {
+	shards *int
+	by     *string
+}
+
+func addTemplateShardFlags(fs *flag.FlagSet) *templateShardFlags {
+	return &templateShardFlags{
+		shards: fs.Int("template-shards", 0, "Split the nuclei template set into N shards; each target runs once per shard (default: off)"),
+		by:     fs.String("shard-by", nuclei.ShardByDir, "How templates are sharded: "+strings.Join(nuclei.ShardModes, ", ")),
+	}
+}
+
+// resolve returns the job's template shards, or nil when the template set
+// is not split. Shards are planned over the uploaded templates bundle, or
+// the local copy of the installed set when none is uploaded. A saved plan
+// keeps the shards it was planned with.
+func (f *templateShardFlags) resolve(fs *flag.FlagSet, mod *modules.ModuleDefinition, options string, bundle *jobs.AssetBundle, saved *runner.PlanFile) (*nuclei.ShardPlan, error) {
+	if saved != nil {
+		return saved.TemplateShards, nil
+	}
+	if *f.shards == 0 {
+		if flagsSet(fs)["shard-by"] {
+			return nil, fmt.Errorf("--shard-by requires --template-shards")
+		}
+		return nil, nil
+	}
+	if *f.shards < 0 {
+		return nil, fmt.Errorf("--template-shards must be positive")
+	}
+	if mod.Name != "nuclei" {
+		return nil, fmt.Errorf("--template-shards is not supported by tool %q", mod.Name)
+	}
+	if err := nuclei.CheckOptions(options); err != nil {
+		return nil, fmt.Errorf("--options: %w", err)
+	}
+	dir := nuclei.DefaultTemplatesDir()
+	if bundle != nil {
+		dir = bundle.Dir
+	}
+	templates, err := nuclei.LoadTemplates(dir)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := nuclei.PlanShards(templates, *f.shards, *f.by, bundle != nil)
+	if err != nil {
+		return nil, fmt.Errorf("--shard-by: %w", err)
+	}
+	logStatus("Templates: %s from %s", plan, dir)
+	return plan, nil
+}
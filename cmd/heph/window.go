@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+
+	"heph4estus/internal/operator"
+	"heph4estus/internal/runner"
+	"heph4estus/internal/schedule"
+)
+
+// windowFlags holds the scan window flags shared by scan and nmap.
+type windowFlags struct {
+	ranges    *string
+	tz        *string
+	blackouts *string
+}
+
+func addWindowFlags(fs *flag.FlagSet) *windowFlags {
+	return &windowFlags{
+		ranges:    fs.String("window", "", `Scan window: weekly ranges such as "Mon-Fri 09:00-17:00", separated by ';' (default: from config, or any time)`),
+		tz:        fs.String("window-tz", "", "Time zone for --window and --blackout, e.g. Europe/London (default: UTC)"),
+		blackouts: fs.String("blackout", "", "Dates the job must not run, e.g. 2026-12-24,2026-12-31..2027-01-01"),
+	}
+}
+
+// resolve merges the flags with the profile window. A saved plan keeps the
+// window it was planned with.
+func (f *windowFlags) resolve(cfg *operator.OperatorConfig, saved *runner.PlanFile) (*schedule.Window, error) {
+	if saved != nil && saved.Window != nil {
+		return saved.Window, nil
+	}
+	return operator.ResolveWindow(*f.ranges, *f.tz, *f.blackouts, cfg)
+}
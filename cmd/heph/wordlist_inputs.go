@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"heph4estus/internal/modules"
+	"heph4estus/internal/runner"
+	wordlisttool "heph4estus/internal/tools/wordlist"
+)
+
+// namedWordlist matches the NAME= prefix of a --wordlist value.
+var namedWordlist = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_]*)=(.+)$`)
+
+// wordlistFlag collects repeated --wordlist values. A bare path is the
+// chunked wordlist; NAME=path binds a path to one of the module's named
+// wordlists.
+type wordlistFlag struct {
+	chunked string
+	named   map[string]string
+}
+
+func (f *wordlistFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	parts := []string{}
+	if f.chunked != "" {
+		parts = append(parts, f.chunked)
+	}
+	for _, name := range f.names() {
+		parts = append(parts, name+"="+f.named[name])
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *wordlistFlag) Set(v string) error {
+	if m := namedWordlist.FindStringSubmatch(v); m != nil {
+		if f.named == nil {
+			f.named = make(map[string]string)
+		}
+		if _, dup := f.named[m[1]]; dup {
+			return fmt.Errorf("wordlist %s given twice", m[1])
+		}
+		f.named[m[1]] = m[2]
+		return nil
+	}
+	if f.chunked != "" {
+		return fmt.Errorf("only one unnamed wordlist is allowed; name the others with NAME=path")
+	}
+	f.chunked = v
+	return nil
+}
+
+// given reports whether any --wordlist was passed.
+func (f *wordlistFlag) given() bool {
+	return f.chunked != "" || len(f.named) > 0
+}
+
+func (f *wordlistFlag) names() []string {
+	return sortedKeys(f.named)
+}
+
+// resolve matches the named wordlists against the module's declared inputs.
+// The chunked input's path moves to f.chunked; the others are returned by
+// name.
+func (f *wordlistFlag) resolve(mod *modules.ModuleDefinition) (map[string]string, error) {
+	chunkedName := mod.ChunkedWordlist()
+	var whole map[string]string
+	for _, name := range f.names() {
+		path := f.named[name]
+		if name == chunkedName {
+			if f.chunked != "" {
+				return nil, fmt.Errorf("--wordlist %s=%s and --wordlist %s both set the chunked wordlist", name, path, f.chunked)
+			}
+			f.chunked = path
+			continue
+		}
+		if !slices.ContainsFunc(mod.WholeWordlists(), func(w modules.WordlistInput) bool { return w.Name == name }) {
+			if len(mod.Wordlists) == 0 {
+				return nil, fmt.Errorf("tool %q takes a single wordlist; drop the %s= prefix", mod.Name, name)
+			}
+			return nil, fmt.Errorf("tool %q has no wordlist %q (declared: %s)", mod.Name, name, declaredWordlists(mod))
+		}
+		if whole == nil {
+			whole = make(map[string]string)
+		}
+		whole[name] = path
+	}
+	for _, w := range mod.WholeWordlists() {
+		if _, ok := whole[w.Name]; !ok && !w.Optional {
+			return nil, fmt.Errorf("--wordlist %s=PATH is required for tool %q", w.Name, mod.Name)
+		}
+	}
+	return whole, nil
+}
+
+func declaredWordlists(mod *modules.ModuleDefinition) string {
+	names := make([]string, len(mod.Wordlists))
+	for i, w := range mod.Wordlists {
+		names[i] = w.Name
+		if w.Chunked {
+			names[i] += " (chunked)"
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// preflightWholeWordlists checks that every wordlist shipped whole can be
+// read and fits in one chunk.
+func preflightWholeWordlists(whole map[string]string) (map[string]*wordlisttool.Metadata, error) {
+	if len(whole) == 0 {
+		return nil, nil
+	}
+	metas := make(map[string]*wordlisttool.Metadata, len(whole))
+	for _, name := range sortedKeys(whole) {
+		path := whole[name]
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("validating wordlist %s: %w", name, err)
+		}
+		if info.Size() > wordlisttool.MaxSafeChunkSize {
+			return nil, fmt.Errorf("wordlist %s is %s; wordlists shipped whole must stay under %s", name, formatByteSize(info.Size()), formatByteSize(wordlisttool.MaxSafeChunkSize))
+		}
+		meta, err := wordlisttool.InspectFile(path, wordlisttool.Policy{RequestedChunks: 1})
+		if err != nil {
+			return nil, fmt.Errorf("validating wordlist %s: %w", name, err)
+		}
+		logStatus("Wordlist %s: %d entries from %s, shipped whole to every task", name, meta.TotalWords, path)
+		metas[name] = meta
+	}
+	return metas, nil
+}
+
+// plannedWholeWordlists pins the wordlists shipped whole for a saved plan.
+func plannedWholeWordlists(whole map[string]string, metas map[string]*wordlisttool.Metadata) (map[string]*runner.PlannedWordlist, error) {
+	if len(whole) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]*runner.PlannedWordlist, len(whole))
+	for name, path := range whole {
+		pw, err := plannedWordlist(path, metas[name])
+		if err != nil {
+			return nil, err
+		}
+		out[name] = pw
+	}
+	return out, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
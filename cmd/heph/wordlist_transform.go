@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"heph4estus/internal/runner"
+	wordlisttool "heph4estus/internal/tools/wordlist"
+)
+
+// transformFlags holds the wordlist rewrite flags of heph scan.
+type transformFlags struct {
+	dedupe     *bool
+	lowercase  *bool
+	include    *string
+	exclude    *string
+	minLength  *int
+	maxLength  *int
+	prefixes   *string
+	suffixes   *string
+	extensions *string
+}
+
+func addTransformFlags(fs *flag.FlagSet) *transformFlags {
+	return &transformFlags{
+		dedupe:     fs.Bool("dedupe", false, "Drop duplicate wordlist entries while splitting (bounded memory)"),
+		lowercase:  fs.Bool("lowercase", false, "Fold wordlist entries to lower case"),
+		include:    fs.String("include-regex", "", "Keep only wordlist entries matching this regex"),
+		exclude:    fs.String("exclude-regex", "", "Drop wordlist entries matching this regex"),
+		minLength:  fs.Int("min-length", 0, "Drop wordlist entries shorter than N characters"),
+		maxLength:  fs.Int("max-length", 0, "Drop wordlist entries longer than N characters"),
+		prefixes:   fs.String("prefixes", "", "Comma-separated prefixes; each adds a prefixed variant of every entry"),
+		suffixes:   fs.String("suffixes", "", "Comma-separated suffixes; each adds a suffixed variant of every entry"),
+		extensions: fs.String("extensions", "", "Comma-separated extensions added to every entry before upload (e.g. php,bak)"),
+	}
+}
+
+// set reports whether any transform flag was given.
+func (f *transformFlags) set(fs *flag.FlagSet) bool {
+	set := flagsSet(fs)
+	for _, name := range []string{"dedupe", "lowercase", "include-regex", "exclude-regex", "min-length", "max-length", "prefixes", "suffixes", "extensions"} {
+		if set[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the job's wordlist transform, or nil when none is set. A
+// saved plan keeps the transform its chunks were planned with.
+func (f *transformFlags) resolve(saved *runner.PlanFile) (*wordlisttool.Transform, error) {
+	if saved != nil {
+		if saved.Wordlist != nil {
+			return saved.Wordlist.Transform, nil
+		}
+		return nil, nil
+	}
+	t := wordlisttool.Transform{
+		Dedupe:     *f.dedupe,
+		Lowercase:  *f.lowercase,
+		Include:    *f.include,
+		Exclude:    *f.exclude,
+		MinLength:  *f.minLength,
+		MaxLength:  *f.maxLength,
+		Prefixes:   splitList(*f.prefixes),
+		Suffixes:   splitList(*f.suffixes),
+		Extensions: splitList(*f.extensions),
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if t.IsZero() {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+// splitList splits a comma-separated flag value, dropping blanks.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -17,7 +17,9 @@ func main() {
 
 	app := tui.NewApp()
 	p := tea.NewProgram(app)
-	if _, err := p.Run(); err != nil {
+	_, err := p.Run()
+	_ = app.Close()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/jobs"
+)
+
+// cancelRecheck is how long a job found running is trusted before its
+// cancel marker is checked again.
+const cancelRecheck = 30 * time.Second
+
+// cancellations caches which jobs were cancelled, so a queue full of one
+// job's tasks costs one storage check per cancelRecheck rather than one per
+// task. Tests reset it.
+var cancellations = newJobCancellations()
+
+// jobCancellations remembers cancel markers found in storage. A cancelled
+// job stays cancelled, so only running jobs are checked again.
+type jobCancellations struct {
+	cancelled map[string]bool
+	checked   map[string]time.Time
+}
+
+func newJobCancellations() *jobCancellations {
+	return &jobCancellations{cancelled: map[string]bool{}, checked: map[string]time.Time{}}
+}
+
+// isCancelled reports whether the runner marked the job cancelled, such as
+// after a budget breach. A failed check counts as running, so a storage
+// outage does not drop tasks.
+func (c *jobCancellations) isCancelled(ctx context.Context, storage cloud.Storage, bucket, tool, jobID string, now time.Time) bool {
+	if c.cancelled[jobID] {
+		return true
+	}
+	if at, ok := c.checked[jobID]; ok && now.Sub(at) < cancelRecheck {
+		return false
+	}
+	n, err := storage.Count(ctx, bucket, jobs.CancelKey(tool, jobID))
+	if err != nil {
+		return false
+	}
+	c.checked[jobID] = now
+	if n > 0 {
+		c.cancelled[jobID] = true
+	}
+	return n > 0
+}
@@ -10,23 +10,27 @@ import (
 	appconfig "heph4estus/internal/config"
 	"heph4estus/internal/fleet"
 	"heph4estus/internal/logger"
+	"heph4estus/internal/modules"
 	"heph4estus/internal/tlsutil"
+	"heph4estus/internal/worker"
 
 	"github.com/nats-io/nats.go"
 )
 
 // startHeartbeat launches a background goroutine that publishes fleet
-// heartbeat messages over NATS. It returns a cancel function to stop
-// the heartbeat.
-func startHeartbeat(ctx context.Context, cfg *appconfig.WorkerConfig, log logger.Logger) (cancel func()) {
+// heartbeat messages over NATS. It also follows the fleet's heartbeats so
+// the returned balancer can divide global rates across the live workers.
+// It returns a cancel function to stop the heartbeat; the balancer is nil
+// when the worker is not on a heartbeat stream.
+func startHeartbeat(ctx context.Context, cfg *appconfig.WorkerConfig, log logger.Logger) (cancel func(), balancer *fleet.RateBalancer) {
 	if !cfg.FleetHeartbeat || cfg.NATSURL == "" {
-		return func() {}
+		return func() {}, nil
 	}
 
 	opts, err := heartbeatNATSOptions(cfg)
 	if err != nil {
 		log.Error("Fleet heartbeat: invalid NATS TLS trust: %v", err)
-		return func() {}
+		return func() {}, nil
 	}
 	opts = append(opts,
 		nats.Name("heph-worker-heartbeat-"+cfg.WorkerID),
@@ -35,7 +39,22 @@ func startHeartbeat(ctx context.Context, cfg *appconfig.WorkerConfig, log logger
 	conn, err := nats.Connect(cfg.NATSURL, opts...)
 	if err != nil {
 		log.Error("Fleet heartbeat: failed to connect to NATS: %v", err)
-		return func() {}
+		return func() {}, nil
+	}
+
+	balancer = fleet.NewRateBalancer(cfg.WorkerID, 0)
+	if _, err := conn.Subscribe(fleet.HeartbeatSubject, func(msg *nats.Msg) {
+		var hb fleet.HeartbeatMessage
+		if err := json.Unmarshal(msg.Data, &hb); err != nil {
+			return
+		}
+		if hb.Cloud != cfg.Cloud || hb.GenerationID != cfg.GenerationID {
+			return
+		}
+		balancer.Observe(hb)
+	}); err != nil {
+		log.Error("Fleet heartbeat: failed to follow the fleet, global rates use the planned worker count: %v", err)
+		balancer = nil
 	}
 
 	// Probe IPv6 connectivity once at startup.
@@ -50,19 +69,80 @@ func startHeartbeat(ctx context.Context, cfg *appconfig.WorkerConfig, log logger
 		defer conn.Close()
 
 		// Publish initial heartbeat immediately.
-		publishHeartbeat(conn, cfg, publicIPv4, publicIPv6, ipv6Ready, log)
+		publishHeartbeat(conn, cfg, publicIPv4, publicIPv6, ipv6Ready, balancer, log)
 
+		// Claims and releases are announced at once so other workers never
+		// count on rate this worker has just taken.
+		var changed <-chan struct{}
+		if balancer != nil {
+			changed = balancer.Changed()
+		}
 		for {
 			select {
 			case <-hbCtx.Done():
 				return
 			case <-ticker.C:
-				publishHeartbeat(conn, cfg, publicIPv4, publicIPv6, ipv6Ready, log)
+			case <-changed:
 			}
+			publishHeartbeat(conn, cfg, publicIPv4, publicIPv6, ipv6Ready, balancer, log)
 		}
 	}()
 
-	return hbCancel
+	return hbCancel, balancer
+}
+
+// rateClaimRetry is how long a worker waits before claiming again when the
+// job's other workers hold its whole global rate.
+const rateClaimRetry = 5 * time.Second
+
+// rateExecutor claims the task's share of its job's global rate across the
+// job's live workers before running it, and releases it afterwards, so
+// shares rebalance as workers join or leave without the job's total ever
+// exceeding the global rate.
+type rateExecutor struct {
+	next     taskExecutor
+	balancer *fleet.RateBalancer
+	log      logger.Logger
+	retry    time.Duration
+}
+
+func (e *rateExecutor) Execute(ctx context.Context, mod *modules.ModuleDefinition, task worker.Task) (worker.Result, []byte, error) {
+	if task.GlobalRate <= 0 {
+		return e.next.Execute(ctx, mod, task)
+	}
+	share, err := e.claim(ctx, task)
+	if err != nil {
+		return worker.Result{}, nil, err
+	}
+	defer e.balancer.Release()
+	if share != task.Rate {
+		e.log.Info("Rate: %d of the global %d across %d live workers", share, task.GlobalRate, e.balancer.Workers(task.JobID))
+		task.Rate = share
+	}
+	return e.next.Execute(ctx, mod, task)
+}
+
+// claim waits until the balancer grants a share of the task's global rate.
+func (e *rateExecutor) claim(ctx context.Context, task worker.Task) (int, error) {
+	retry := e.retry
+	if retry <= 0 {
+		retry = rateClaimRetry
+	}
+	waiting := false
+	for {
+		if share := e.balancer.Claim(task.JobID, task.GlobalRate, task.Rate); share > 0 {
+			return share, nil
+		}
+		if !waiting {
+			e.log.Info("Rate: the job's other workers hold all of the global %d, waiting for a share", task.GlobalRate)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(retry):
+		}
+	}
 }
 
 func heartbeatNATSOptions(cfg *appconfig.WorkerConfig) ([]nats.Option, error) {
@@ -84,7 +164,8 @@ func heartbeatNATSOptions(cfg *appconfig.WorkerConfig) ([]nats.Option, error) {
 	return []nats.Option{nats.Secure(tlsConfig)}, nil
 }
 
-func publishHeartbeat(conn *nats.Conn, cfg *appconfig.WorkerConfig, ipv4, ipv6 string, ipv6Ready bool, log logger.Logger) {
+func publishHeartbeat(conn *nats.Conn, cfg *appconfig.WorkerConfig, ipv4, ipv6 string, ipv6Ready bool, balancer *fleet.RateBalancer, log logger.Logger) {
+	jobID, rate := balancer.Held()
 	msg := fleet.HeartbeatMessage{
 		WorkerID:     cfg.WorkerID,
 		Host:         cfg.WorkerHost,
@@ -96,6 +177,8 @@ func publishHeartbeat(conn *nats.Conn, cfg *appconfig.WorkerConfig, ipv4, ipv6 s
 		Cloud:        cfg.Cloud,
 		GenerationID: cfg.GenerationID,
 		Timestamp:    time.Now().Unix(),
+		JobID:        jobID,
+		Rate:         rate,
 	}
 
 	data, err := json.Marshal(msg)
@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"time"
+	_ "time/tzdata" // scan windows name time zones; images may lack zoneinfo
 
 	"heph4estus/internal/cloud"
 	"heph4estus/internal/cloud/factory"
@@ -12,6 +15,8 @@ import (
 	"heph4estus/internal/jobs"
 	"heph4estus/internal/logger"
 	"heph4estus/internal/modules"
+	"heph4estus/internal/schedule"
+	"heph4estus/internal/scope"
 	"heph4estus/internal/worker"
 )
 
@@ -51,20 +56,64 @@ func main() {
 		log.Fatal("Failed to build cloud provider: %v", err)
 	}
 
-	executor := worker.NewExecutor(log, provider.Storage(), cfg.Bucket)
+	var executor taskExecutor = worker.NewExecutor(log, provider.Storage(), cfg.Bucket)
+	leases = newTargetLeases(cfg, provider)
 
 	ctx := context.Background()
 
 	// Start fleet heartbeat if configured (selfhosted/Hetzner workers).
-	stopHeartbeat := startHeartbeat(ctx, cfg, log)
+	stopHeartbeat, balancer := startHeartbeat(ctx, cfg, log)
 	defer stopHeartbeat()
+	if balancer != nil && mod.RateLimited() {
+		executor = &rateExecutor{next: executor, balancer: balancer, log: log}
+	}
+
+	// The launch-time window pauses the whole worker. Windows carried by
+	// tasks only defer those tasks, so tasks of other jobs on a shared
+	// queue keep running.
+	if cfg.Window != nil {
+		log.Info("Scan window: %s", cfg.Window)
+	}
+
+	// Tasks requeued for busy targets or closed windows are hidden until
+	// then, so an empty queue before it does not mean the job is done.
+	var busyUntil, windowUntil time.Time
 
 	for {
+		if wait := pauseFor(cfg.Window, time.Now()); wait > 0 {
+			log.Info("Outside scan window (%s), paused for %s", cfg.Window, wait)
+			time.Sleep(wait)
+			continue
+		}
 		processed, err := processMessage(ctx, log, cfg, mod, provider.Queue(), provider.Storage(), executor)
+		var closed *windowClosedError
+		if errors.As(err, &closed) {
+			if until := closed.reopens(time.Now()); until.After(windowUntil) {
+				windowUntil = until
+			}
+			continue
+		}
+		var busy *targetBusyError
+		if errors.As(err, &busy) {
+			if until := time.Now().Add(busy.wait); until.After(busyUntil) {
+				busyUntil = until
+			}
+			continue
+		}
 		if err != nil {
 			log.Error("Error processing message: %v", err)
 		}
 		if !processed {
+			if wait := time.Until(busyUntil); wait > 0 {
+				log.Info("Queue empty while tasks wait on busy targets, checking again in %s", wait.Round(time.Second))
+				time.Sleep(wait)
+				continue
+			}
+			if wait := min(time.Until(windowUntil), maxPause); wait > 0 {
+				log.Info("Queue empty while tasks wait for their scan window, checking again in %s", wait.Round(time.Second))
+				time.Sleep(wait)
+				continue
+			}
 			log.Info("Queue empty, exiting")
 			break
 		}
@@ -101,6 +150,41 @@ func processMessage(
 		return true, fmt.Errorf("unmarshaling task: %w", err)
 	}
 
+	// Drop tasks of a cancelled job; other jobs may share the queue.
+	if task.JobID != "" && cancellations.isCancelled(ctx, storage, cfg.Bucket, mod.Name, task.JobID, time.Now()) {
+		log.Info("Dropping %s task for %s: job %s was cancelled", mod.Name, task.Target, task.JobID)
+		if err := queue.Delete(ctx, cfg.QueueID, msg.ReceiptHandle); err != nil {
+			log.Error("Error deleting message for target %s: %v", task.Target, err)
+		}
+		return true, nil
+	}
+
+	// Hand the task back untouched while its window is closed.
+	if task.Window != nil && !task.Window.Open(time.Now()) {
+		return true, deferTask(ctx, log, cfg, queue, msg, task.Window)
+	}
+
+	// Re-check the target against the job's scope before touching it.
+	if task.Scope != nil {
+		if err := checkTaskScope(ctx, task); err != nil {
+			log.Error("Refusing %s task for %s: %v", mod.Name, task.Target, err)
+			return true, rejectTask(ctx, log, cfg, mod, queue, storage, msg, task, err)
+		}
+	}
+
+	// Take a per-target lease so the fleet stays polite to one host.
+	if task.Politeness != nil && leases != nil {
+		lease, wait, err := leases.acquire(ctx, task.Target, *task.Politeness, mod.TimeoutDuration()+leaseGrace)
+		switch {
+		case err != nil:
+			log.Error("Politeness limits for %s unavailable, running without them: %v", task.Target, err)
+		case lease == nil:
+			return true, throttleTask(ctx, log, cfg, queue, msg, task.Target, wait)
+		default:
+			defer leases.release(log, lease)
+		}
+	}
+
 	// Apply pre-scan jitter to spread worker timing.
 	if cfg.JitterMaxSeconds > 0 {
 		d := worker.ApplyJitter(cfg.JitterMaxSeconds)
@@ -173,3 +257,101 @@ func processMessage(
 	log.Info("Message processing complete for target: %s", task.Target)
 	return true, nil
 }
+
+// maxPause bounds one sleep while the scan window is closed so the worker
+// logs that it is still paused.
+const maxPause = 15 * time.Minute
+
+// windowClosedError reports a task received outside its scan window.
+type windowClosedError struct {
+	window *schedule.Window
+}
+
+func (e *windowClosedError) Error() string {
+	return fmt.Sprintf("outside scan window %s", e.window)
+}
+
+// reopens returns when the task's window next opens, or maxPause from now
+// when it never does.
+func (e *windowClosedError) reopens(now time.Time) time.Time {
+	if next := e.window.NextOpen(now); !next.IsZero() {
+		return next
+	}
+	return now.Add(maxPause)
+}
+
+// pauseFor returns how long to wait before pulling tasks, or zero when the
+// window is open.
+func pauseFor(w *schedule.Window, now time.Time) time.Duration {
+	if w.Open(now) {
+		return 0
+	}
+	next := w.NextOpen(now)
+	if next.IsZero() || next.Sub(now) > maxPause {
+		return maxPause
+	}
+	return next.Sub(now)
+}
+
+// deferTask puts a message back on the queue, hidden until the window
+// opens where the queue supports it, without using up its deliveries, and
+// tells the caller when the window reopens.
+func deferTask(ctx context.Context, log logger.Logger, cfg *appconfig.WorkerConfig, queue cloud.Queue, msg *cloud.Message, w *schedule.Window) error {
+	now := time.Now()
+	closed := &windowClosedError{window: w}
+	delay := closed.reopens(now).Sub(now)
+	log.Info("Task received outside scan window (%s), returning it to the queue", w)
+	if err := cloud.RequeueMessage(ctx, queue, cfg.QueueID, msg, delay); err != nil && !errors.Is(err, cloud.ErrNotImplemented) {
+		log.Error("Error requeueing message: %v", err)
+	}
+	return closed
+}
+
+// scopeResolver resolves host-name targets for scope checks. Tests replace it.
+var scopeResolver scope.Resolver = net.DefaultResolver
+
+// scopeLookupTimeout bounds the DNS lookup made for each scope check.
+const scopeLookupTimeout = 10 * time.Second
+
+// checkTaskScope verifies the scope digest embedded in task and checks the
+// target, including its resolved addresses, against it.
+func checkTaskScope(ctx context.Context, task worker.Task) error {
+	sc, err := task.Scope.Scope()
+	if err != nil {
+		return err
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, scopeLookupTimeout)
+	defer cancel()
+	return sc.CheckResolved(lookupCtx, task.Target, scopeResolver)
+}
+
+// rejectTask records a permanent failure for a task that must not run and
+// deletes its message so it is not retried.
+func rejectTask(ctx context.Context, log logger.Logger, cfg *appconfig.WorkerConfig, mod *modules.ModuleDefinition, queue cloud.Queue, storage cloud.Storage, msg *cloud.Message, task worker.Task, reason error) error {
+	result := worker.Result{
+		ToolName:    mod.Name,
+		JobID:       task.JobID,
+		Target:      task.Target,
+		Error:       reason.Error(),
+		Timestamp:   time.Now(),
+		GroupID:     task.GroupID,
+		ChunkIdx:    task.ChunkIdx,
+		TotalChunks: task.TotalChunks,
+		Metadata:    task.Metadata,
+	}
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshaling result for %s: %w", task.Target, err)
+	}
+
+	uploadCtx, uploadCancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer uploadCancel()
+	key := jobs.ResultKey(mod.Name, task.JobID, task.Target, task.GroupID, task.ChunkIdx, task.TotalChunks, result.Timestamp.Unix(), "json")
+	if err := storage.Upload(uploadCtx, cfg.Bucket, key, resultJSON); err != nil {
+		return fmt.Errorf("uploading rejection for %s: %w", task.Target, err)
+	}
+	if err := queue.Delete(ctx, cfg.QueueID, msg.ReceiptHandle); err != nil {
+		log.Error("Error deleting message for target %s: %v", task.Target, err)
+	}
+	return nil
+}
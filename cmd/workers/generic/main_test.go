@@ -4,19 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net"
 	"strings"
 	"testing"
 	"time"
 
 	"heph4estus/internal/cloud"
 	appconfig "heph4estus/internal/config"
+	"heph4estus/internal/fleet"
+	"heph4estus/internal/jobs"
 	"heph4estus/internal/modules"
+	"heph4estus/internal/politeness"
+	"heph4estus/internal/schedule"
+	"heph4estus/internal/scope"
 	"heph4estus/internal/worker"
 )
 
 type mockQueue struct {
-	msg     *cloud.Message
-	deleted bool
+	msg      *cloud.Message
+	deleted  bool
+	released time.Duration
+	requeued time.Duration
 }
 
 func (q *mockQueue) Send(ctx context.Context, queueID, body string) error { return nil }
@@ -30,12 +38,25 @@ func (q *mockQueue) Delete(ctx context.Context, queueID, receiptHandle string) e
 	q.deleted = true
 	return nil
 }
+func (q *mockQueue) Release(ctx context.Context, queueID, receiptHandle string, delay time.Duration) error {
+	q.released = delay
+	return nil
+}
+
+// requeueQueue also implements cloud.MessageRequeuer.
+type requeueQueue struct{ mockQueue }
+
+func (q *requeueQueue) Requeue(ctx context.Context, queueID, receiptHandle, body string, delay time.Duration) error {
+	q.requeued = delay
+	return nil
+}
 
 type mockStorage struct {
 	uploadErr error
 	uploaded  bool
 	keys      []string
 	payloads  map[string][]byte
+	counts    map[string]int
 }
 
 func (s *mockStorage) Upload(ctx context.Context, bucket, key string, data []byte) error {
@@ -57,7 +78,7 @@ func (s *mockStorage) List(ctx context.Context, bucket, prefix string) ([]string
 	return nil, nil
 }
 func (s *mockStorage) Count(ctx context.Context, bucket, prefix string) (int, error) {
-	return 0, nil
+	return s.counts[prefix], nil
 }
 
 type mockLogger struct{}
@@ -302,3 +323,297 @@ func TestProcessMessage_ExecutionError(t *testing.T) {
 		t.Fatal("message should not be deleted on execution error — SQS retries")
 	}
 }
+
+type stubResolver map[string][]string
+
+func (r stubResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	out := make([]net.IPAddr, len(ips))
+	for i, ip := range ips {
+		out[i] = net.IPAddr{IP: net.ParseIP(ip)}
+	}
+	return out, nil
+}
+
+func scopedTaskMessage(t *testing.T, target string, digest *scope.Digest) *cloud.Message {
+	t.Helper()
+	body, err := json.Marshal(worker.Task{ToolName: "nmap", JobID: "job-123", Target: target, Options: "-sn", Scope: digest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &cloud.Message{ID: "msg-1", Body: string(body), ReceiptHandle: "receipt-1", ReceiveCount: 1}
+}
+
+func testScopeDigest(t *testing.T) *scope.Digest {
+	t.Helper()
+	sc, err := scope.New([]string{"10.0.0.0/24", "*.example.com"}, []string{"10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sc.Digest()
+}
+
+func TestProcessMessage_ScopeViolationRejectedWithoutExecuting(t *testing.T) {
+	orig := scopeResolver
+	scopeResolver = stubResolver{"db.example.com": {"10.0.0.1"}}
+	defer func() { scopeResolver = orig }()
+
+	tampered := testScopeDigest(t)
+	tampered.Include = append(tampered.Include, "0.0.0.0/0")
+
+	tests := []struct {
+		name   string
+		msg    *cloud.Message
+		reason string
+	}{
+		{"outside include", scopedTaskMessage(t, "192.0.2.1", testScopeDigest(t)), "out of scope"},
+		{"resolves to excluded address", scopedTaskMessage(t, "db.example.com", testScopeDigest(t)), "resolves to 10.0.0.1"},
+		{"tampered digest", scopedTaskMessage(t, "192.0.2.1", tampered), "digest mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &mockQueue{msg: tt.msg}
+			s := &mockStorage{}
+			e := &mockExecutor{execErr: errors.New("executor must not run")}
+
+			processed, err := processMessage(context.Background(), &mockLogger{}, testConfig(), testModule(), q, s, e)
+			if !processed || err != nil {
+				t.Fatalf("processMessage = %v, %v", processed, err)
+			}
+			if !q.deleted {
+				t.Fatal("rejected task should be deleted so it is not retried")
+			}
+			if len(s.keys) != 1 || !strings.HasPrefix(s.keys[0], "scans/nmap/job-123/results/") {
+				t.Fatalf("expected one rejection result, got %v", s.keys)
+			}
+			var stored worker.Result
+			if err := json.Unmarshal(s.payloads[s.keys[0]], &stored); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(stored.Error, tt.reason) {
+				t.Errorf("Error = %q, want it to mention %q", stored.Error, tt.reason)
+			}
+		})
+	}
+}
+
+func TestProcessMessage_InScopeTaskExecutes(t *testing.T) {
+	orig := scopeResolver
+	scopeResolver = stubResolver{"www.example.com": {"93.184.216.34"}}
+	defer func() { scopeResolver = orig }()
+
+	q := &mockQueue{msg: scopedTaskMessage(t, "www.example.com", testScopeDigest(t))}
+	s := &mockStorage{}
+	e := &mockExecutor{result: worker.Result{Output: "ok"}}
+
+	processed, err := processMessage(context.Background(), &mockLogger{}, testConfig(), testModule(), q, s, e)
+	if !processed || err != nil {
+		t.Fatalf("processMessage = %v, %v", processed, err)
+	}
+	var stored worker.Result
+	if err := json.Unmarshal(s.payloads[s.keys[0]], &stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.Error != "" || stored.Output != "ok" {
+		t.Errorf("unexpected result %+v", stored)
+	}
+}
+
+type countingExecutor struct {
+	mockExecutor
+	calls int
+}
+
+func (e *countingExecutor) Execute(ctx context.Context, mod *modules.ModuleDefinition, task worker.Task) (worker.Result, []byte, error) {
+	e.calls++
+	return e.mockExecutor.Execute(ctx, mod, task)
+}
+
+func TestProcessMessage_ClosedWindowRequeuesTask(t *testing.T) {
+	now := time.Now().UTC()
+	// A one-hour window that opened two hours ago is closed now.
+	start := now.Add(-2 * time.Hour)
+	w := &schedule.Window{Ranges: []schedule.Range{{
+		Start: start.Hour()*60 + start.Minute(),
+		End:   (start.Hour()+1)%24*60 + start.Minute(),
+	}}}
+	task := worker.Task{ToolName: "nmap", JobID: "job-123", Target: "127.0.0.1", Window: w}
+	body, _ := json.Marshal(task)
+	q := &requeueQueue{mockQueue{msg: &cloud.Message{ID: "msg-1", Body: string(body), ReceiptHandle: "receipt-1"}}}
+	e := &countingExecutor{}
+
+	processed, err := processMessage(context.Background(), &mockLogger{}, testConfig(), testModule(), q, &mockStorage{}, e)
+	if !processed {
+		t.Fatal("expected the message to count as handled")
+	}
+	var closed *windowClosedError
+	if !errors.As(err, &closed) || closed.window == nil {
+		t.Fatalf("expected windowClosedError, got %v", err)
+	}
+	if e.calls != 0 || q.deleted {
+		t.Fatalf("task must not run or be deleted: calls=%d deleted=%v", e.calls, q.deleted)
+	}
+	// Requeued rather than released, so waiting does not use up deliveries.
+	if q.released != 0 || q.requeued < 21*time.Hour || q.requeued > 23*time.Hour {
+		t.Fatalf("released for %s, requeued for %s, want requeued until the window reopens", q.released, q.requeued)
+	}
+	if d := time.Until(closed.reopens(time.Now())); d < 21*time.Hour || d > 23*time.Hour {
+		t.Fatalf("reopens in %s, want when the window reopens", d)
+	}
+	if d := pauseFor(closed.window, time.Now()); d != maxPause {
+		t.Fatalf("pauseFor = %s, want %s", d, maxPause)
+	}
+	if d := pauseFor(nil, time.Now()); d != 0 {
+		t.Fatalf("pauseFor(nil) = %s, want 0", d)
+	}
+}
+
+func TestProcessMessage_CancelledJobDropsTask(t *testing.T) {
+	cancellations = newJobCancellations()
+	t.Cleanup(func() { cancellations = newJobCancellations() })
+
+	task := worker.Task{ToolName: "nmap", JobID: "job-cancelled", Target: "127.0.0.1"}
+	body, _ := json.Marshal(task)
+	q := &mockQueue{msg: &cloud.Message{ID: "msg-1", Body: string(body), ReceiptHandle: "receipt-1"}}
+	s := &mockStorage{counts: map[string]int{jobs.CancelKey("nmap", "job-cancelled"): 1}}
+	e := &countingExecutor{}
+
+	processed, err := processMessage(context.Background(), &mockLogger{}, testConfig(), testModule(), q, s, e)
+	if !processed || err != nil {
+		t.Fatalf("processMessage = %v, %v", processed, err)
+	}
+	if e.calls != 0 || !q.deleted || s.uploaded {
+		t.Fatalf("calls=%d deleted=%v uploaded=%v, want the task dropped without a result", e.calls, q.deleted, s.uploaded)
+	}
+}
+
+func TestJobCancellations_RechecksRunningJobs(t *testing.T) {
+	c := newJobCancellations()
+	s := &mockStorage{counts: map[string]int{}}
+	now := time.Now()
+	if c.isCancelled(context.Background(), s, "b", "nmap", "job-1", now) {
+		t.Fatal("job without a marker reported cancelled")
+	}
+	s.counts[jobs.CancelKey("nmap", "job-1")] = 1
+	if c.isCancelled(context.Background(), s, "b", "nmap", "job-1", now.Add(time.Second)) {
+		t.Fatal("running job should be trusted until the recheck interval")
+	}
+	if !c.isCancelled(context.Background(), s, "b", "nmap", "job-1", now.Add(cancelRecheck)) {
+		t.Fatal("marker not seen after the recheck interval")
+	}
+	delete(s.counts, jobs.CancelKey("nmap", "job-1"))
+	if !c.isCancelled(context.Background(), s, "b", "nmap", "job-1", now.Add(2*cancelRecheck)) {
+		t.Fatal("a cancelled job must stay cancelled")
+	}
+}
+
+type recordingExecutor struct {
+	mockExecutor
+	task worker.Task
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, mod *modules.ModuleDefinition, task worker.Task) (worker.Result, []byte, error) {
+	e.task = task
+	return e.mockExecutor.Execute(ctx, mod, task)
+}
+
+func TestRateExecutor_KeepsPlannedShareUntilFleetKnown(t *testing.T) {
+	next := &recordingExecutor{}
+	balancer := fleet.NewRateBalancer("w1", 0)
+	exec := &rateExecutor{next: next, balancer: balancer, log: &mockLogger{}}
+	task := worker.Task{ToolName: "nuclei", JobID: "job-1", Target: "example.com", GlobalRate: 1000, Rate: 250}
+	if _, _, err := exec.Execute(context.Background(), &modules.ModuleDefinition{}, task); err != nil {
+		t.Fatal(err)
+	}
+	if next.task.Rate != 250 {
+		t.Errorf("rate = %d, want the planned 250 while the balancer warms up", next.task.Rate)
+	}
+	if job, rate := balancer.Held(); job != "" || rate != 0 {
+		t.Errorf("share still held after the task: %q %d", job, rate)
+	}
+}
+
+func TestRateExecutor_WaitsWhileTheJobsRateIsHeld(t *testing.T) {
+	next := &recordingExecutor{}
+	balancer := fleet.NewRateBalancer("w1", 0)
+	balancer.Observe(fleet.HeartbeatMessage{WorkerID: "w2", JobID: "job-1", Rate: 1000})
+	exec := &rateExecutor{next: next, balancer: balancer, log: &mockLogger{}, retry: time.Millisecond}
+	task := worker.Task{ToolName: "nuclei", JobID: "job-1", Target: "example.com", GlobalRate: 1000, Rate: 250}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := exec.Execute(ctx, &modules.ModuleDefinition{}, task); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Execute = %v, want to wait until the context ends", err)
+	}
+	if next.task.Target != "" {
+		t.Error("task ran without a share of the global rate")
+	}
+}
+
+// memKV is an in-memory cloud.KV.
+type memKV struct {
+	data map[string][]byte
+	revs map[string]uint64
+}
+
+func (m *memKV) Get(_ context.Context, key string) ([]byte, uint64, error) {
+	return m.data[key], m.revs[key], nil
+}
+
+func (m *memKV) Put(_ context.Context, key string, value []byte, revision uint64) error {
+	if m.revs[key] != revision {
+		return cloud.ErrKVConflict
+	}
+	m.data[key] = value
+	m.revs[key]++
+	return nil
+}
+
+func TestProcessMessage_BusyTargetRequeuesTask(t *testing.T) {
+	kv := &memKV{data: map[string][]byte{}, revs: map[string]uint64{}}
+	leases = &targetLeases{open: func(context.Context) (cloud.KV, error) { return kv, nil }, owner: "w1"}
+	t.Cleanup(func() { leases = nil })
+
+	lim := &politeness.Limits{Concurrency: 1}
+	task := worker.Task{ToolName: "nmap", JobID: "job-123", Target: "10.0.0.1", Politeness: lim}
+	body, _ := json.Marshal(task)
+	newQueue := func() *mockQueue {
+		return &mockQueue{msg: &cloud.Message{ID: "msg-1", Body: string(body), ReceiptHandle: "receipt-1"}}
+	}
+
+	// Another worker holds the only slot on the target.
+	other := politeness.NewLimiter(kv, "w2")
+	held, _, err := other.Acquire(context.Background(), "10.0.0.1", *lim, time.Hour)
+	if err != nil || held == nil {
+		t.Fatalf("Acquire = %v, %v", held, err)
+	}
+
+	q := newQueue()
+	e := &countingExecutor{}
+	processed, err := processMessage(context.Background(), &mockLogger{}, testConfig(), testModule(), q, &mockStorage{}, e)
+	var busy *targetBusyError
+	if !processed || !errors.As(err, &busy) {
+		t.Fatalf("processMessage = %v, %v; want targetBusyError", processed, err)
+	}
+	if e.calls != 0 || q.deleted || q.released != busy.wait {
+		t.Fatalf("calls=%d deleted=%v released=%s, want the task handed back for %s", e.calls, q.deleted, q.released, busy.wait)
+	}
+
+	// Once the slot is free the task runs and gives its lease back.
+	if err := other.Release(context.Background(), held); err != nil {
+		t.Fatal(err)
+	}
+	q = newQueue()
+	if _, err := processMessage(context.Background(), &mockLogger{}, testConfig(), testModule(), q, &mockStorage{}, e); err != nil {
+		t.Fatalf("processMessage: %v", err)
+	}
+	if e.calls != 1 || !q.deleted {
+		t.Fatalf("calls=%d deleted=%v, want the task run", e.calls, q.deleted)
+	}
+	if again, _, _ := other.Acquire(context.Background(), "10.0.0.1", *lim, time.Hour); again == nil {
+		t.Fatal("the worker should release its lease after the task")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"heph4estus/internal/cloud"
+	appconfig "heph4estus/internal/config"
+	"heph4estus/internal/logger"
+	"heph4estus/internal/politeness"
+)
+
+// leaseGrace is added to a module's timeout when holding a lease, so a
+// lease outlives the task it covers even when the tool overruns slightly.
+const leaseGrace = time.Minute
+
+// leases hands out per-target politeness leases. Nil runs every task
+// without them; main sets it and tests replace it.
+var leases *targetLeases
+
+// targetLeases opens the shared lease store on first use, so jobs without
+// politeness limits never touch it.
+type targetLeases struct {
+	open  func(ctx context.Context) (cloud.KV, error)
+	owner string
+
+	once    sync.Once
+	limiter *politeness.Limiter
+	err     error
+}
+
+// newTargetLeases returns leases kept in the provider's KV store named by
+// LEASE_STORE, or politeness.DefaultStore.
+func newTargetLeases(cfg *appconfig.WorkerConfig, provider cloud.Provider) *targetLeases {
+	name := cfg.LeaseStore
+	if name == "" {
+		name = politeness.DefaultStore
+	}
+	return &targetLeases{
+		open: func(ctx context.Context) (cloud.KV, error) {
+			return cloud.OpenKV(ctx, provider, name)
+		},
+		owner: cfg.WorkerID,
+	}
+}
+
+// acquire takes a lease on target. It returns a nil lease and the wait
+// before the next attempt when the target is saturated.
+func (t *targetLeases) acquire(ctx context.Context, target string, lim politeness.Limits, hold time.Duration) (*politeness.Lease, time.Duration, error) {
+	t.once.Do(func() {
+		kv, err := t.open(ctx)
+		if err != nil {
+			t.err = fmt.Errorf("opening lease store: %w", err)
+			return
+		}
+		t.limiter = politeness.NewLimiter(kv, t.owner)
+	})
+	if t.err != nil {
+		return nil, 0, t.err
+	}
+	return t.limiter.Acquire(ctx, target, lim, hold)
+}
+
+// release gives a lease back, logging rather than failing the task; an
+// unreleased lease frees itself when it expires.
+func (t *targetLeases) release(log logger.Logger, lease *politeness.Lease) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := t.limiter.Release(ctx, lease); err != nil {
+		log.Error("Error releasing politeness lease for %s: %v", lease.Target, err)
+	}
+}
+
+// targetBusyError reports a task handed back because its target is at its
+// politeness limit.
+type targetBusyError struct {
+	target string
+	wait   time.Duration
+}
+
+func (e *targetBusyError) Error() string {
+	return fmt.Sprintf("target %s is at its politeness limit, retrying in %s", e.target, e.wait)
+}
+
+// throttleTask puts a message back on the queue, delayed by wait where the
+// queue supports it, and tells the caller the target is busy.
+func throttleTask(ctx context.Context, log logger.Logger, cfg *appconfig.WorkerConfig, queue cloud.Queue, msg *cloud.Message, target string, wait time.Duration) error {
+	log.Info("Target %s is at its politeness limit, requeueing the task for %s", target, wait)
+	if err := cloud.RequeueMessage(ctx, queue, cfg.QueueID, msg, wait); err != nil && !errors.Is(err, cloud.ErrNotImplemented) {
+		log.Error("Error requeueing message: %v", err)
+	}
+	return &targetBusyError{target: target, wait: wait}
+}
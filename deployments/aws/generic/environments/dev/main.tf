@@ -40,8 +40,9 @@ module "security" {
 
   name_prefix   = var.name_prefix
   environment   = local.environment
-  sqs_queue_arn = module.messaging.queue_arn
-  s3_bucket_arn = module.storage.bucket_arn
+  sqs_queue_arn   = module.messaging.queue_arn
+  s3_bucket_arn   = module.storage.bucket_arn
+  lease_table_arn = module.messaging.lease_table_arn
 }
 
 # Create compute resources
@@ -69,6 +70,7 @@ module "spot" {
 
   name_prefix   = var.name_prefix
   environment   = local.environment
-  sqs_queue_arn = module.messaging.queue_arn
-  s3_bucket_arn = module.storage.bucket_arn
+  sqs_queue_arn   = module.messaging.queue_arn
+  s3_bucket_arn   = module.storage.bucket_arn
+  lease_table_arn = module.messaging.lease_table_arn
 }
@@ -13,6 +13,11 @@ output "ecr_repo_url" {
   value       = module.compute.ecr_repository_url
 }
 
+output "lease_store_name" {
+  description = "Name of the DynamoDB table for per-target politeness leases"
+  value       = module.messaging.lease_table_name
+}
+
 output "s3_bucket_name" {
   description = "Name of the S3 bucket for results"
   value       = module.storage.bucket_id
@@ -27,3 +27,26 @@ resource "aws_sqs_queue" "dlq" {
     Terraform   = "true"
   }
 }
+
+# Per-target politeness leases shared by all workers
+resource "aws_dynamodb_table" "leases" {
+  name         = "${var.name_prefix}-leases"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "key"
+
+  attribute {
+    name = "key"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "expires_at"
+    enabled        = true
+  }
+
+  tags = {
+    Name        = "${var.name_prefix}-leases"
+    Environment = var.environment
+    Terraform   = "true"
+  }
+}
@@ -17,3 +17,13 @@ output "dlq_arn" {
   description = "ARN of the dead-letter queue"
   value       = aws_sqs_queue.dlq.arn
 }
+
+output "lease_table_name" {
+  description = "Name of the DynamoDB table for per-target politeness leases"
+  value       = aws_dynamodb_table.leases.name
+}
+
+output "lease_table_arn" {
+  description = "ARN of the DynamoDB table for per-target politeness leases"
+  value       = aws_dynamodb_table.leases.arn
+}
@@ -58,10 +58,21 @@ resource "aws_iam_policy" "spot_worker" {
         Action = [
           "sqs:ReceiveMessage",
           "sqs:DeleteMessage",
+          "sqs:SendMessage",
+          "sqs:ChangeMessageVisibility",
           "sqs:GetQueueAttributes"
         ]
         Resource = var.sqs_queue_arn
       },
+      {
+        Sid    = "LeaseTableAccess"
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetItem",
+          "dynamodb:PutItem"
+        ]
+        Resource = var.lease_table_arn
+      },
       {
         Sid    = "S3Access"
         Effect = "Allow"
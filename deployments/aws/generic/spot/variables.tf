@@ -17,3 +17,8 @@ variable "s3_bucket_arn" {
   description = "ARN of the S3 bucket for result uploads"
   type        = string
 }
+
+variable "lease_table_arn" {
+  description = "ARN of the DynamoDB table for per-target politeness leases"
+  type        = string
+}
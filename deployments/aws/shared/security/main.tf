@@ -181,7 +181,7 @@ resource "aws_iam_role" "ecs_task" {
   }
 }
 
-# Custom policy for ECS task to access SQS and S3
+# Custom policy for ECS task to access SQS, S3 and the lease table
 resource "aws_iam_policy" "ecs_task_custom" {
   name        = "${var.name_prefix}-ecs-task-custom-policy"
   description = "Custom policy for ECS task role"
@@ -194,6 +194,8 @@ resource "aws_iam_policy" "ecs_task_custom" {
         Action = [
           "sqs:ReceiveMessage",
           "sqs:DeleteMessage",
+          "sqs:SendMessage",
+          "sqs:ChangeMessageVisibility",
           "sqs:GetQueueAttributes"
         ]
         Resource = var.sqs_queue_arn
@@ -205,6 +207,14 @@ resource "aws_iam_policy" "ecs_task_custom" {
           "s3:GetObject"
         ]
         Resource = "${var.s3_bucket_arn}/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetItem",
+          "dynamodb:PutItem"
+        ]
+        Resource = var.lease_table_arn
       }
     ]
   })
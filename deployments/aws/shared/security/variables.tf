@@ -18,4 +18,9 @@ variable "sqs_queue_arn" {
 variable "s3_bucket_arn" {
   description = "ARN of the S3 bucket for results"
   type        = string
-}
\ No newline at end of file
+}
+
+variable "lease_table_arn" {
+  description = "ARN of the DynamoDB table for per-target politeness leases"
+  type        = string
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"heph4estus/internal/cloud"
 
@@ -54,6 +55,7 @@ type mockSQSAPI struct {
 	sendMessageBatchFunc func(context.Context, *sqs.SendMessageBatchInput, ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
 	receiveMessageFunc   func(context.Context, *sqs.ReceiveMessageInput, ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	deleteMessageFunc    func(context.Context, *sqs.DeleteMessageInput, ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	changeVisibilityFunc func(context.Context, *sqs.ChangeMessageVisibilityInput, ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
 }
 
 func (m *mockSQSAPI) SendMessage(ctx context.Context, in *sqs.SendMessageInput, opts ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
@@ -69,16 +71,25 @@ func (m *mockSQSAPI) DeleteMessage(ctx context.Context, in *sqs.DeleteMessageInp
 	return m.deleteMessageFunc(ctx, in, opts...)
 }
 
+func (m *mockSQSAPI) ChangeMessageVisibility(ctx context.Context, in *sqs.ChangeMessageVisibilityInput, opts ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	return m.changeVisibilityFunc(ctx, in, opts...)
+}
+
 var _ SQSAPI = (*mockSQSAPI)(nil)
 
 type mockECSAPI struct {
-	runTaskFunc func(context.Context, *ecs.RunTaskInput, ...func(*ecs.Options)) (*ecs.RunTaskOutput, error)
+	runTaskFunc  func(context.Context, *ecs.RunTaskInput, ...func(*ecs.Options)) (*ecs.RunTaskOutput, error)
+	stopTaskFunc func(context.Context, *ecs.StopTaskInput, ...func(*ecs.Options)) (*ecs.StopTaskOutput, error)
 }
 
 func (m *mockECSAPI) RunTask(ctx context.Context, in *ecs.RunTaskInput, opts ...func(*ecs.Options)) (*ecs.RunTaskOutput, error) {
 	return m.runTaskFunc(ctx, in, opts...)
 }
 
+func (m *mockECSAPI) StopTask(ctx context.Context, in *ecs.StopTaskInput, opts ...func(*ecs.Options)) (*ecs.StopTaskOutput, error) {
+	return m.stopTaskFunc(ctx, in, opts...)
+}
+
 var _ ECSAPI = (*mockECSAPI)(nil)
 
 type mockSFNAPI struct {
@@ -302,6 +313,25 @@ func TestSQSReceive_NoMessages(t *testing.T) {
 	}
 }
 
+func TestSQSRelease_CapsVisibilityTimeout(t *testing.T) {
+	var got *sqs.ChangeMessageVisibilityInput
+	client := &SQSClient{
+		logger: nopLogger{},
+		client: &mockSQSAPI{
+			changeVisibilityFunc: func(_ context.Context, in *sqs.ChangeMessageVisibilityInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+				got = in
+				return &sqs.ChangeMessageVisibilityOutput{}, nil
+			},
+		},
+	}
+	if err := client.Release(context.Background(), "q", "rh-1", 48*time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || *got.ReceiptHandle != "rh-1" || got.VisibilityTimeout != 43200 {
+		t.Fatalf("unexpected input: %+v", got)
+	}
+}
+
 func TestSQSReceive_Error(t *testing.T) {
 	want := errors.New("receive failed")
 	client := &SQSClient{
@@ -908,3 +938,63 @@ func TestCompositeCompute_DelegatesToEC2(t *testing.T) {
 		t.Fatal("expected EC2 to be called for RunSpotInstances")
 	}
 }
+
+func TestCompositeCompute_StopWorkers(t *testing.T) {
+	var stopped []string
+	var clusters []string
+	ecsClient := &ECSClient{
+		logger: nopLogger{},
+		client: &mockECSAPI{
+			stopTaskFunc: func(_ context.Context, in *ecs.StopTaskInput, _ ...func(*ecs.Options)) (*ecs.StopTaskOutput, error) {
+				stopped = append(stopped, aws.ToString(in.Task))
+				clusters = append(clusters, aws.ToString(in.Cluster))
+				return &ecs.StopTaskOutput{}, nil
+			},
+		},
+	}
+	mock := newMockEC2()
+	var terminated []string
+	mock.terminateInstancesFunc = func(_ context.Context, in *ec2.TerminateInstancesInput, _ ...func(*ec2.Options)) (*ec2.TerminateInstancesOutput, error) {
+		terminated = append(terminated, in.InstanceIds...)
+		return &ec2.TerminateInstancesOutput{}, nil
+	}
+	composite := &CompositeCompute{ecs: ecsClient, ec2: &EC2Client{client: mock, logger: nopLogger{}}}
+
+	arn := "arn:aws:ecs:us-east-1:123456789012:task/heph-httpx/0123abcd"
+	if err := cloud.StopWorkers(context.Background(), composite, []string{arn, "i-1", "i-2"}); err != nil {
+		t.Fatalf("StopWorkers: %v", err)
+	}
+	if len(stopped) != 1 || stopped[0] != arn || clusters[0] != "heph-httpx" {
+		t.Errorf("stopped = %v clusters = %v", stopped, clusters)
+	}
+	if strings.Join(terminated, ",") != "i-1,i-2" {
+		t.Errorf("terminated = %v", terminated)
+	}
+}
+
+func TestSQSRequeue_SendsDelayedCopyAndDeletes(t *testing.T) {
+	var sent *sqs.SendMessageInput
+	var deleted string
+	client := &SQSClient{
+		logger: nopLogger{},
+		client: &mockSQSAPI{
+			sendMessageFunc: func(_ context.Context, in *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+				sent = in
+				return &sqs.SendMessageOutput{}, nil
+			},
+			deleteMessageFunc: func(_ context.Context, in *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+				deleted = *in.ReceiptHandle
+				return &sqs.DeleteMessageOutput{}, nil
+			},
+		},
+	}
+	if err := client.Requeue(context.Background(), "q", "rh-1", "body", time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent == nil || *sent.MessageBody != "body" || sent.DelaySeconds != 900 {
+		t.Fatalf("unexpected send: %+v", sent)
+	}
+	if deleted != "rh-1" {
+		t.Fatalf("deleted %q, want rh-1", deleted)
+	}
+}
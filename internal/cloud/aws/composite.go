@@ -2,12 +2,17 @@ package aws
 
 import (
 	"context"
+	"errors"
+	"strings"
 
 	"heph4estus/internal/cloud"
 )
 
 // Compile-time interface check.
-var _ cloud.Compute = (*CompositeCompute)(nil)
+var (
+	_ cloud.Compute       = (*CompositeCompute)(nil)
+	_ cloud.WorkerStopper = (*CompositeCompute)(nil)
+)
 
 // CompositeCompute delegates container operations to ECS (Fargate) and spot
 // operations to EC2. This allows the TUI to auto-select compute mode.
@@ -27,3 +32,17 @@ func (c *CompositeCompute) RunSpotInstances(ctx context.Context, opts cloud.Spot
 func (c *CompositeCompute) GetSpotStatus(ctx context.Context, instanceIDs []string) ([]cloud.SpotStatus, error) {
 	return c.ec2.GetSpotStatus(ctx, instanceIDs)
 }
+
+// StopWorkers stops Fargate tasks (ARNs) through ECS and terminates spot
+// instances (instance IDs) through EC2.
+func (c *CompositeCompute) StopWorkers(ctx context.Context, ids []string) error {
+	var tasks, instances []string
+	for _, id := range ids {
+		if strings.HasPrefix(id, "arn:") {
+			tasks = append(tasks, id)
+		} else {
+			instances = append(instances, id)
+		}
+	}
+	return errors.Join(c.ecs.StopWorkers(ctx, tasks), c.ec2.StopWorkers(ctx, instances))
+}
@@ -0,0 +1,117 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"heph4estus/internal/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoItemTTL is how long an untouched item lives; the table expires
+// items through its expires_at TTL attribute.
+const dynamoItemTTL = 24 * time.Hour
+
+// Compile-time interface checks.
+var (
+	_ cloud.KV              = (*DynamoKV)(nil)
+	_ cloud.KVOpener        = (*AWSProvider)(nil)
+	_ cloud.MessageRequeuer = (*SQSClient)(nil)
+)
+
+// DynamoKV is a cloud.KV backed by a DynamoDB table whose partition key is
+// the string attribute "key".
+type DynamoKV struct {
+	client *dynamodb.Client
+	table  string
+	now    func() time.Time
+}
+
+// NewDynamoKV returns a KV over table. A non-empty endpoint overrides the
+// SDK's resolved endpoint, e.g. for DynamoDB Local.
+func NewDynamoKV(cfg aws.Config, table, endpoint string) *DynamoKV {
+	return &DynamoKV{
+		client: dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+			if endpoint != "" {
+				o.BaseEndpoint = aws.String(endpoint)
+			}
+		}),
+		table: table,
+		now:   time.Now,
+	}
+}
+
+// OpenKV returns the DynamoDB table name as a KV. DYNAMODB_ENDPOINT
+// overrides the endpoint.
+func (p *AWSProvider) OpenKV(_ context.Context, name string) (cloud.KV, error) {
+	if name == "" {
+		return nil, fmt.Errorf("aws: kv table name is required")
+	}
+	return NewDynamoKV(p.cfg, name, os.Getenv("DYNAMODB_ENDPOINT")), nil
+}
+
+// Get reads key with a strongly consistent read.
+func (d *DynamoKV) Get(ctx context.Context, key string) ([]byte, uint64, error) {
+	out, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(d.table),
+		Key:            map[string]types.AttributeValue{"key": &types.AttributeValueMemberS{Value: key}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("aws: dynamodb get %q: %w", key, err)
+	}
+	if out.Item == nil {
+		return nil, 0, nil
+	}
+	n, _ := out.Item["revision"].(*types.AttributeValueMemberN)
+	if n == nil {
+		return nil, 0, fmt.Errorf("aws: dynamodb item %q has no revision", key)
+	}
+	rev, err := strconv.ParseUint(n.Value, 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("aws: dynamodb item %q has invalid revision: %w", key, err)
+	}
+	var value []byte
+	if b, ok := out.Item["value"].(*types.AttributeValueMemberB); ok {
+		value = b.Value
+	}
+	return value, rev, nil
+}
+
+// Put writes key on the condition that it is still at revision.
+func (d *DynamoKV) Put(ctx context.Context, key string, value []byte, revision uint64) error {
+	in := &dynamodb.PutItemInput{
+		TableName: aws.String(d.table),
+		Item: map[string]types.AttributeValue{
+			"key":        &types.AttributeValueMemberS{Value: key},
+			"value":      &types.AttributeValueMemberB{Value: value},
+			"revision":   &types.AttributeValueMemberN{Value: strconv.FormatUint(revision+1, 10)},
+			"expires_at": &types.AttributeValueMemberN{Value: strconv.FormatInt(d.now().Add(dynamoItemTTL).Unix(), 10)},
+		},
+	}
+	if revision == 0 {
+		in.ConditionExpression = aws.String("attribute_not_exists(#k)")
+		in.ExpressionAttributeNames = map[string]string{"#k": "key"}
+	} else {
+		in.ConditionExpression = aws.String("#r = :r")
+		in.ExpressionAttributeNames = map[string]string{"#r": "revision"}
+		in.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":r": &types.AttributeValueMemberN{Value: strconv.FormatUint(revision, 10)},
+		}
+	}
+	if _, err := d.client.PutItem(ctx, in); err != nil {
+		var conflict *types.ConditionalCheckFailedException
+		if errors.As(err, &conflict) {
+			return cloud.ErrKVConflict
+		}
+		return fmt.Errorf("aws: dynamodb put %q: %w", key, err)
+	}
+	return nil
+}
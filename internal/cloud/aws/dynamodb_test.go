@@ -0,0 +1,118 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"heph4estus/internal/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// dynamoItem is an item in the DynamoDB JSON wire format.
+type dynamoItem map[string]struct {
+	S string `json:"S,omitempty"`
+	N string `json:"N,omitempty"`
+	B []byte `json:"B,omitempty"`
+}
+
+// fakeDynamo is a local stand-in for the two DynamoDB operations DynamoKV
+// uses, including the conditional checks.
+type fakeDynamo struct {
+	mu    sync.Mutex
+	items map[string]dynamoItem
+	auth  []string
+}
+
+func (f *fakeDynamo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	var req struct {
+		TableName                 string            `json:"TableName"`
+		Key                       dynamoItem        `json:"Key"`
+		Item                      dynamoItem        `json:"Item"`
+		ConditionExpression       string            `json:"ConditionExpression"`
+		ExpressionAttributeValues dynamoItem        `json:"ExpressionAttributeValues"`
+		ExpressionAttributeNames  map[string]string `json:"ExpressionAttributeNames"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.") {
+	case "GetItem":
+		item, ok := f.items[req.Key["key"].S]
+		if !ok {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"Item": item})
+	case "PutItem":
+		key := req.Item["key"].S
+		cur, exists := f.items[key]
+		ok := true
+		switch req.ConditionExpression {
+		case "attribute_not_exists(#k)":
+			ok = !exists
+		case "#r = :r":
+			ok = exists && cur["revision"].N == req.ExpressionAttributeValues[":r"].N
+		}
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`))
+			return
+		}
+		f.items[key] = req.Item
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		http.Error(w, "unknown operation", http.StatusBadRequest)
+	}
+}
+
+func TestDynamoKV_CompareAndSwap(t *testing.T) {
+	fake := &fakeDynamo{items: map[string]dynamoItem{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	cfg := aws.Config{
+		Region:      "eu-west-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+	}
+	kv := NewDynamoKV(cfg, "heph-leases", srv.URL)
+	ctx := context.Background()
+
+	if v, rev, err := kv.Get(ctx, "target.a"); err != nil || v != nil || rev != 0 {
+		t.Fatalf("Get missing = %q, %d, %v", v, rev, err)
+	}
+	if err := kv.Put(ctx, "target.a", []byte("one"), 0); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := kv.Put(ctx, "target.a", []byte("again"), 0); !errors.Is(err, cloud.ErrKVConflict) {
+		t.Fatalf("second create = %v, want ErrKVConflict", err)
+	}
+	v, rev, err := kv.Get(ctx, "target.a")
+	if err != nil || string(v) != "one" || rev != 1 {
+		t.Fatalf("Get = %q, %d, %v", v, rev, err)
+	}
+	if err := kv.Put(ctx, "target.a", []byte("two"), rev); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := kv.Put(ctx, "target.a", []byte("stale"), rev); !errors.Is(err, cloud.ErrKVConflict) {
+		t.Fatalf("stale update = %v, want ErrKVConflict", err)
+	}
+	if fake.items["target.a"]["expires_at"].N == "" {
+		t.Error("items should carry an expires_at TTL attribute")
+	}
+	if !strings.Contains(fake.auth[0], "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(fake.auth[0], "/eu-west-1/dynamodb/aws4_request") {
+		t.Errorf("request not signed for dynamodb: %q", fake.auth[0])
+	}
+}
@@ -169,6 +169,18 @@ func (c *EC2Client) GetSpotStatus(ctx context.Context, instanceIDs []string) ([]
 	return statuses, nil
 }
 
+// StopWorkers terminates the given spot instances.
+func (c *EC2Client) StopWorkers(ctx context.Context, instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return nil
+	}
+	if _, err := c.client.TerminateInstances(ctx, &ec2.TerminateInstancesInput{InstanceIds: instanceIDs}); err != nil {
+		return fmt.Errorf("TerminateInstances: %w", err)
+	}
+	c.logger.Info("Terminated %d spot instances", len(instanceIDs))
+	return nil
+}
+
 // RunContainer is not implemented for EC2 (use ECS for Fargate).
 func (c *EC2Client) RunContainer(_ context.Context, _ cloud.ContainerOpts) (string, error) {
 	return "", cloud.ErrNotImplemented
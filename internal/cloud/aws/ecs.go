@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -16,6 +17,7 @@ import (
 // ECSAPI is the subset of the ECS SDK we use.
 type ECSAPI interface {
 	RunTask(ctx context.Context, params *ecs.RunTaskInput, optFns ...func(*ecs.Options)) (*ecs.RunTaskOutput, error)
+	StopTask(ctx context.Context, params *ecs.StopTaskInput, optFns ...func(*ecs.Options)) (*ecs.StopTaskOutput, error)
 }
 
 // ECSClient wraps the ECS SDK client.
@@ -101,6 +103,34 @@ func (c *ECSClient) RunContainer(ctx context.Context, opts cloud.ContainerOpts)
 	return strings.Join(allARNs, ","), nil
 }
 
+// StopWorkers stops the given Fargate task ARNs. The cluster is taken from
+// each ARN (arn:aws:ecs:<region>:<account>:task/<cluster>/<id>).
+func (c *ECSClient) StopWorkers(ctx context.Context, taskARNs []string) error {
+	var errs []error
+	for _, arn := range taskARNs {
+		input := &ecs.StopTaskInput{Task: aws.String(arn), Reason: aws.String("heph: job stopped")}
+		if cluster := clusterFromTaskARN(arn); cluster != "" {
+			input.Cluster = aws.String(cluster)
+		}
+		if _, err := c.client.StopTask(ctx, input); err != nil {
+			errs = append(errs, fmt.Errorf("StopTask %s: %w", arn, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func clusterFromTaskARN(arn string) string {
+	_, resource, ok := strings.Cut(arn, ":task/")
+	if !ok {
+		return ""
+	}
+	cluster, _, ok := strings.Cut(resource, "/")
+	if !ok {
+		return ""
+	}
+	return cluster
+}
+
 // RunSpotInstances is not implemented for ECS.
 func (c *ECSClient) RunSpotInstances(_ context.Context, _ cloud.SpotOpts) ([]string, error) {
 	return nil, cloud.ErrNotImplemented
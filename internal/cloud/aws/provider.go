@@ -12,6 +12,7 @@ var _ cloud.Provider = (*AWSProvider)(nil)
 
 // AWSProvider implements cloud.Provider for AWS.
 type AWSProvider struct {
+	cfg aws.Config
 	s3  *S3Client
 	sqs *SQSClient
 	sfn *SFNClient
@@ -22,6 +23,7 @@ type AWSProvider struct {
 // NewProvider creates an AWSProvider from a shared AWS config.
 func NewProvider(cfg aws.Config, log logger.Logger) *AWSProvider {
 	return &AWSProvider{
+		cfg: cfg,
 		s3:  NewS3Client(cfg, log),
 		sqs: NewSQSClient(cfg, log),
 		sfn: NewSFNClient(cfg, log),
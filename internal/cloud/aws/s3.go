@@ -3,6 +3,7 @@ package aws
 import (
 	"bytes"
 	"context"
+	"heph4estus/internal/cloud"
 	"heph4estus/internal/logger"
 	"io"
 
@@ -17,6 +18,12 @@ type S3API interface {
 	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
 }
 
+// s3Deleter is the delete call of the S3 SDK. It is kept out of S3API so
+// fakes that never delete need not implement it.
+type s3Deleter interface {
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
 // S3Client is a wrapper around the S3 client
 type S3Client struct {
 	client S3API
@@ -102,6 +109,20 @@ func (c *S3Client) Count(ctx context.Context, bucket, prefix string) (int, error
 	return count, nil
 }
 
+// Delete removes an object. S3 treats deleting a missing key as success.
+func (c *S3Client) Delete(ctx context.Context, bucket, key string) error {
+	d, ok := c.client.(s3Deleter)
+	if !ok {
+		return cloud.ErrNotImplemented
+	}
+	c.logger.Info("Deleting object from S3: %s/%s", bucket, key)
+	_, err := d.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
 // PutObject uploads an object to S3 (backward-compat alias for Upload).
 func (c *S3Client) PutObject(ctx context.Context, bucket, key string, data []byte) error {
 	return c.Upload(ctx, bucket, key, data)
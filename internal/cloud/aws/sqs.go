@@ -6,6 +6,7 @@ import (
 	"heph4estus/internal/cloud"
 	"heph4estus/internal/logger"
 	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
@@ -18,6 +19,7 @@ type SQSAPI interface {
 	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
 	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
 }
 
 // SQSClient is a wrapper around the SQS client
@@ -113,3 +115,42 @@ func (c *SQSClient) Delete(ctx context.Context, queueID, receiptHandle string) e
 	})
 	return err
 }
+
+// maxVisibilityTimeout is the longest SQS will hide a received message.
+const maxVisibilityTimeout = 12 * time.Hour
+
+// Release hides a received message for delay, capped at the SQS maximum of
+// 12 hours, instead of processing it.
+func (c *SQSClient) Release(ctx context.Context, queueID, receiptHandle string, delay time.Duration) error {
+	c.logger.Info("Releasing message back to SQS queue: %s", queueID)
+	if delay > maxVisibilityTimeout {
+		delay = maxVisibilityTimeout
+	}
+	_, err := c.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          &queueID,
+		ReceiptHandle:     &receiptHandle,
+		VisibilityTimeout: int32(delay / time.Second),
+	})
+	return err
+}
+
+// maxSendDelay is the longest SQS will delay a newly sent message.
+const maxSendDelay = 15 * time.Minute
+
+// Requeue sends a fresh copy of a received message, delayed by up to the
+// SQS maximum of 15 minutes, and deletes the original. The copy starts
+// with a clean receive count, so requeues never push a task to the DLQ.
+func (c *SQSClient) Requeue(ctx context.Context, queueID, receiptHandle, body string, delay time.Duration) error {
+	c.logger.Info("Requeueing message on SQS queue: %s", queueID)
+	if delay > maxSendDelay {
+		delay = maxSendDelay
+	}
+	if _, err := c.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:     &queueID,
+		MessageBody:  &body,
+		DelaySeconds: int32(delay / time.Second),
+	}); err != nil {
+		return fmt.Errorf("requeueing message: %w", err)
+	}
+	return c.Delete(ctx, queueID, receiptHandle)
+}
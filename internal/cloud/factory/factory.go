@@ -12,6 +12,7 @@ import (
 
 	"heph4estus/internal/cloud"
 	awscloud "heph4estus/internal/cloud/aws"
+	"heph4estus/internal/cloud/local"
 	"heph4estus/internal/cloud/selfhosted"
 	"heph4estus/internal/logger"
 
@@ -73,12 +74,27 @@ type SelfhostedConfig struct {
 	DockerImage string   // Docker image reference for the worker container
 }
 
-// Config is the input to Build. Exactly one of AWS or Selfhosted should be
-// populated, matching Kind.
+// LocalConfig describes the fully local runtime: filesystem storage, an
+// embedded (or existing) NATS server, and locally spawned workers.
+type LocalConfig struct {
+	Dir          string // runtime root; empty uses local.DefaultDir
+	NATSURL      string // empty starts an embedded JetStream server
+	StreamName   string
+	WorkerBinary string // generic-worker path override
+	DockerImage  string // launch workers as local containers instead
+
+	// Scan runtime settings.
+	QueueID string
+	Bucket  string
+}
+
+// Config is the input to Build. Exactly one of AWS, Selfhosted, or Local
+// should be populated, matching Kind.
 type Config struct {
 	Kind       cloud.Kind
 	AWS        *AWSConfig
 	Selfhosted *SelfhostedConfig
+	Local      *LocalConfig
 	Logger     logger.Logger
 }
 
@@ -174,11 +190,44 @@ func Build(cfg Config) (cloud.Provider, error) {
 			}
 		}
 		return selfhosted.NewProvider(pcfg, cfg.Logger)
+	case cloud.KindLocal:
+		if cfg.Local == nil {
+			return nil, fmt.Errorf("factory: local config is required for cloud %q", cloud.KindLocal)
+		}
+		dir := cfg.Local.Dir
+		if dir == "" {
+			var err error
+			if dir, err = local.DefaultDir(); err != nil {
+				return nil, err
+			}
+		}
+		return local.NewProvider(local.Config{
+			Dir:          dir,
+			NATSURL:      cfg.Local.NATSURL,
+			StreamName:   cfg.Local.StreamName,
+			WorkerBinary: cfg.Local.WorkerBinary,
+			DockerImage:  cfg.Local.DockerImage,
+		}, cfg.Logger)
 	default:
 		return nil, fmt.Errorf("factory: unsupported cloud %q", cfg.Kind)
 	}
 }
 
+// LocalConfigFromEnv reads local runtime configuration from environment
+// variables. Workers spawned by the local provider inherit HEPH_LOCAL_DIR and
+// NATS_URL, so they attach to the operator's storage root and queue.
+func LocalConfigFromEnv() *LocalConfig {
+	return &LocalConfig{
+		Dir:          os.Getenv("HEPH_LOCAL_DIR"),
+		NATSURL:      os.Getenv("NATS_URL"),
+		StreamName:   os.Getenv("NATS_STREAM"),
+		WorkerBinary: os.Getenv("HEPH_LOCAL_WORKER_BIN"),
+		DockerImage:  os.Getenv("HEPH_LOCAL_DOCKER_IMAGE"),
+		QueueID:      envOr("HEPH_LOCAL_QUEUE_ID", "heph-local"),
+		Bucket:       envOr("HEPH_LOCAL_BUCKET", "heph-local"),
+	}
+}
+
 // SelfhostedConfigFromEnv reads selfhosted provider configuration from
 // environment variables. CLI, TUI, and worker paths use this so endpoint
 // configuration is centralised in one place.
@@ -219,10 +268,22 @@ func SelfhostedConfigFromEnv() *SelfhostedConfig {
 	return cfg
 }
 
+// DirectRuntimeConfigFromEnv returns the runtime settings for clouds that run
+// without a deploy step — manual selfhosted and local. Local only carries
+// the queue ID and bucket; its endpoints are owned by the local provider.
+func DirectRuntimeConfigFromEnv(kind cloud.Kind) *SelfhostedConfig {
+	if kind.IsLocal() {
+		lc := LocalConfigFromEnv()
+		return &SelfhostedConfig{QueueID: lc.QueueID, Bucket: lc.Bucket}
+	}
+	return SelfhostedConfigFromEnv()
+}
+
 // BuildForKind constructs a provider for the given kind, loading
 // configuration from the standard environment. For AWS this uses the
 // default SDK credential chain; for selfhosted it reads NATS_URL,
-// S3_ENDPOINT, and related env vars via SelfhostedConfigFromEnv.
+// S3_ENDPOINT, and related env vars via SelfhostedConfigFromEnv; for local
+// it reads HEPH_LOCAL_DIR and friends via LocalConfigFromEnv.
 func BuildForKind(ctx context.Context, kind cloud.Kind, log logger.Logger) (cloud.Provider, error) {
 	switch kind.RuntimeFamily() {
 	case cloud.KindAWS, "":
@@ -241,6 +302,12 @@ func BuildForKind(ctx context.Context, kind cloud.Kind, log logger.Logger) (clou
 			Selfhosted: SelfhostedConfigFromEnv(),
 			Logger:     log,
 		})
+	case cloud.KindLocal:
+		return Build(Config{
+			Kind:   cloud.KindLocal,
+			Local:  LocalConfigFromEnv(),
+			Logger: log,
+		})
 	default:
 		return nil, fmt.Errorf("factory: unsupported cloud %q", kind)
 	}
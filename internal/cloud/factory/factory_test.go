@@ -439,3 +439,58 @@ func TestBuildLinodeMissingConfig(t *testing.T) {
 		t.Fatal("expected config-required error for linode")
 	}
 }
+
+func TestBuildLocalStartsEmbeddedQueue(t *testing.T) {
+	p, err := Build(Config{
+		Kind:   cloud.KindLocal,
+		Local:  &LocalConfig{Dir: t.TempDir()},
+		Logger: logger.NewSimpleLogger(),
+	})
+	if err != nil {
+		t.Fatalf("Build local: %v", err)
+	}
+	closer, ok := p.(interface{ Close() error })
+	if !ok {
+		t.Fatal("expected local provider to be closable")
+	}
+	defer func() { _ = closer.Close() }()
+
+	ctx := context.Background()
+	if err := p.Storage().Upload(ctx, "heph-local", "k", []byte("v")); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if err := p.Queue().Send(ctx, "heph-local", "hello"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+}
+
+func TestBuildLocalMissingConfig(t *testing.T) {
+	_, err := Build(Config{Kind: cloud.KindLocal, Logger: logger.NewSimpleLogger()})
+	if err == nil {
+		t.Fatal("expected error when local config is missing")
+	}
+}
+
+func TestLocalConfigFromEnv(t *testing.T) {
+	t.Setenv("HEPH_LOCAL_DIR", "/tmp/heph-local")
+	t.Setenv("NATS_URL", "")
+	t.Setenv("HEPH_LOCAL_WORKER_BIN", "/opt/bin/generic-worker")
+	t.Setenv("HEPH_LOCAL_QUEUE_ID", "")
+	t.Setenv("HEPH_LOCAL_BUCKET", "scans")
+
+	cfg := LocalConfigFromEnv()
+	if cfg.Dir != "/tmp/heph-local" || cfg.WorkerBinary != "/opt/bin/generic-worker" {
+		t.Errorf("dir/bin = %q/%q", cfg.Dir, cfg.WorkerBinary)
+	}
+	if cfg.QueueID != "heph-local" {
+		t.Errorf("QueueID = %q, want default heph-local", cfg.QueueID)
+	}
+	if cfg.Bucket != "scans" {
+		t.Errorf("Bucket = %q", cfg.Bucket)
+	}
+
+	direct := DirectRuntimeConfigFromEnv(cloud.KindLocal)
+	if direct.QueueID != "heph-local" || direct.Bucket != "scans" {
+		t.Errorf("direct runtime = %q/%q", direct.QueueID, direct.Bucket)
+	}
+}
@@ -23,6 +23,10 @@ const (
 	KindLinode   Kind = "linode"
 	KindScaleway Kind = "scaleway"
 	KindVultr    Kind = "vultr"
+	// KindLocal runs storage, queue, and workers on the operator's machine.
+	// It needs no infrastructure and is meant for module development and
+	// offline integration tests.
+	KindLocal Kind = "local"
 )
 
 // DefaultKind is the cloud used when nothing else is specified.
@@ -30,7 +34,7 @@ const DefaultKind = KindAWS
 
 // SupportedKinds returns the canonical list of cloud kinds.
 func SupportedKinds() []Kind {
-	return []Kind{KindAWS, KindManual, KindHetzner, KindLinode, KindScaleway, KindVultr}
+	return []Kind{KindAWS, KindManual, KindHetzner, KindLinode, KindScaleway, KindVultr, KindLocal}
 }
 
 // String returns the canonical string form of the kind.
@@ -51,6 +55,8 @@ func (k Kind) Canonical() Kind {
 		return KindScaleway
 	case "vultr":
 		return KindVultr
+	case "local":
+		return KindLocal
 	default:
 		return k
 	}
@@ -83,6 +89,8 @@ func (k Kind) RuntimeFamily() Kind {
 		return KindVultr
 	case KindManual, KindScaleway:
 		return KindManual
+	case KindLocal:
+		return KindLocal
 	default:
 		return KindAWS
 	}
@@ -99,6 +107,11 @@ func (k Kind) IsProviderNative() bool {
 	}
 }
 
+// IsLocal reports whether the kind runs entirely on the operator's machine.
+func (k Kind) IsLocal() bool {
+	return k.Canonical() == KindLocal
+}
+
 // SupportedKindsText returns the canonical user-facing list for help text.
 func SupportedKindsText() string {
 	return joinKinds(SupportedKinds())
@@ -128,10 +141,10 @@ func ParseKind(s string) (Kind, error) {
 //
 // Policy:
 //   - AWS: auto, fargate, spot (and empty, which resolves to auto).
-//   - Selfhosted: auto only (fargate and spot are AWS-specific concepts).
+//   - Selfhosted and local: auto only (fargate and spot are AWS-specific concepts).
 func ValidateComputeMode(kind Kind, mode string) error {
 	switch {
-	case kind.IsSelfhostedFamily():
+	case kind.IsSelfhostedFamily(), kind.IsLocal():
 		if mode != "" && mode != "auto" {
 			return fmt.Errorf("provider %q only supports compute-mode \"auto\", got %q (fargate and spot are AWS-specific)", kind.Canonical(), mode)
 		}
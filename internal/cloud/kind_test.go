@@ -19,6 +19,7 @@ func TestParseKind(t *testing.T) {
 		{"linode", "linode", KindLinode, false},
 		{"scaleway", "scaleway", KindScaleway, false},
 		{"vultr", "vultr", KindVultr, false},
+		{"local", "local", KindLocal, false},
 		{"unknown", "gcp", "", true},
 	}
 	for _, tt := range tests {
@@ -50,6 +51,8 @@ func TestValidateComputeMode(t *testing.T) {
 		{"manual empty", KindManual, "", false},
 		{"manual fargate rejected", KindManual, "fargate", true},
 		{"hetzner spot rejected", KindHetzner, "spot", true},
+		{"local auto", KindLocal, "auto", false},
+		{"local spot rejected", KindLocal, "spot", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -63,7 +66,7 @@ func TestValidateComputeMode(t *testing.T) {
 
 func TestSupportedKindsReturnsCanonicalUserFacingKinds(t *testing.T) {
 	got := SupportedKinds()
-	want := []Kind{KindAWS, KindManual, KindHetzner, KindLinode, KindScaleway, KindVultr}
+	want := []Kind{KindAWS, KindManual, KindHetzner, KindLinode, KindScaleway, KindVultr, KindLocal}
 	if len(got) != len(want) {
 		t.Fatalf("SupportedKinds() len = %d, want %d", len(got), len(want))
 	}
@@ -85,6 +88,7 @@ func TestIsProviderNative(t *testing.T) {
 		{KindLinode, true},
 		{KindScaleway, false},
 		{KindVultr, true},
+		{KindLocal, false},
 	}
 	for _, tt := range tests {
 		if got := tt.kind.IsProviderNative(); got != tt.want {
@@ -107,6 +111,7 @@ func TestSelfhostedFamilyHelpers(t *testing.T) {
 		{KindHetzner, true, KindHetzner, KindHetzner},
 		{KindLinode, true, KindLinode, KindLinode},
 		{KindVultr, true, KindVultr, KindVultr},
+		{KindLocal, false, KindLocal, KindLocal},
 	}
 	for _, tt := range tests {
 		if got := tt.kind.IsSelfhostedFamily(); got != tt.wantFamily {
@@ -0,0 +1,52 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrKVConflict is returned by KV.Put when the key changed since it was read.
+var ErrKVConflict = errors.New("cloud: kv revision conflict")
+
+// KV is a small shared key-value store with compare-and-swap writes. Workers
+// use it to coordinate with each other, such as for per-target leases.
+type KV interface {
+	// Get returns the value of key and its revision. A missing key returns
+	// a nil value and revision 0.
+	Get(ctx context.Context, key string) ([]byte, uint64, error)
+	// Put stores value if key is still at revision, where 0 means the key
+	// must not exist yet. It returns ErrKVConflict otherwise.
+	Put(ctx context.Context, key string, value []byte, revision uint64) error
+}
+
+// KVOpener is implemented by Providers that offer a shared KV store.
+type KVOpener interface {
+	OpenKV(ctx context.Context, name string) (KV, error)
+}
+
+// OpenKV opens the named KV store of p when p supports one and returns
+// ErrNotImplemented otherwise.
+func OpenKV(ctx context.Context, p Provider, name string) (KV, error) {
+	o, ok := p.(KVOpener)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return o.OpenKV(ctx, name)
+}
+
+// MessageRequeuer is implemented by Queue backends that can put a received
+// message back for a later attempt without using up its deliveries.
+type MessageRequeuer interface {
+	Requeue(ctx context.Context, queueID, receiptHandle, body string, delay time.Duration) error
+}
+
+// RequeueMessage puts msg back on q for another attempt after delay. Queues
+// that cannot requeue fall back to ReleaseMessage, which counts as a
+// delivery.
+func RequeueMessage(ctx context.Context, q Queue, queueID string, msg *Message, delay time.Duration) error {
+	if r, ok := q.(MessageRequeuer); ok {
+		return r.Requeue(ctx, queueID, msg.ReceiptHandle, msg.Body, delay)
+	}
+	return ReleaseMessage(ctx, q, queueID, msg.ReceiptHandle, delay)
+}
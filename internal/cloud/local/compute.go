@@ -0,0 +1,256 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/logger"
+)
+
+// WorkerBinaryName is the generic worker binary produced by `make build`.
+const WorkerBinaryName = "generic-worker"
+
+var errSpotUnsupported = errors.New("local: spot instances are not supported — local workers run as processes on this machine")
+
+// Compute is a cloud.Compute implementation that runs generic workers on the
+// local machine, either as child processes or as `docker run` containers
+// when an image is configured.
+type Compute struct {
+	workerBin string
+	image     string
+	env       map[string]string
+	logDir    string
+	logger    logger.Logger
+
+	mu    sync.Mutex
+	procs []*exec.Cmd
+	wg    sync.WaitGroup
+}
+
+func newCompute(workerBin, image string, env map[string]string, logDir string, log logger.Logger) *Compute {
+	return &Compute{
+		workerBin: workerBin,
+		image:     image,
+		env:       env,
+		logDir:    logDir,
+		logger:    log,
+	}
+}
+
+// RunContainer starts opts.Count workers and returns their comma-separated
+// identifiers. Workers outlive ctx: they exit on their own once the queue
+// drains, so the launch timeout must not kill them.
+func (c *Compute) RunContainer(ctx context.Context, opts cloud.ContainerOpts) (string, error) {
+	count := opts.Count
+	if count <= 0 {
+		count = 1
+	}
+	base := opts.ContainerName
+	if base == "" {
+		base = "worker"
+	}
+	env := c.buildEnv(opts.Env)
+
+	image := c.image
+	if opts.Image != "" {
+		image = opts.Image
+	}
+
+	var launched []string
+	for i := 0; i < count; i++ {
+		name := workerName(base, i, count)
+		var (
+			id  string
+			err error
+		)
+		if image != "" {
+			id, err = c.runDocker(ctx, name, image, env)
+		} else {
+			id, err = c.startProcess(name, env)
+		}
+		if err != nil {
+			return strings.Join(launched, ","), err
+		}
+		launched = append(launched, id)
+	}
+	return strings.Join(launched, ","), nil
+}
+
+func (c *Compute) RunSpotInstances(_ context.Context, _ cloud.SpotOpts) ([]string, error) {
+	return nil, errSpotUnsupported
+}
+
+func (c *Compute) GetSpotStatus(_ context.Context, _ []string) ([]cloud.SpotStatus, error) {
+	return nil, errSpotUnsupported
+}
+
+// Wait blocks until every worker process started by this Compute exits.
+// Containers are detached and are not tracked.
+func (c *Compute) Wait() {
+	c.wg.Wait()
+}
+
+// Stop signals every still-running worker process to exit.
+func (c *Compute) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cmd := range c.procs {
+		if cmd.Process != nil && cmd.ProcessState == nil {
+			_ = cmd.Process.Kill()
+		}
+	}
+}
+
+// StopWorkers kills the listed worker processes ("name:pid") and removes
+// the listed containers.
+func (c *Compute) StopWorkers(ctx context.Context, ids []string) error {
+	pids := make(map[int]bool)
+	var containers []string
+	for _, id := range ids {
+		if _, pid, ok := strings.Cut(id, ":"); ok {
+			if n, err := strconv.Atoi(pid); err == nil {
+				pids[n] = true
+				continue
+			}
+		}
+		containers = append(containers, id)
+	}
+
+	c.mu.Lock()
+	for _, cmd := range c.procs {
+		if cmd.Process != nil && cmd.ProcessState == nil && pids[cmd.Process.Pid] {
+			_ = cmd.Process.Kill()
+		}
+	}
+	c.mu.Unlock()
+
+	if len(containers) == 0 {
+		return nil
+	}
+	out, err := exec.CommandContext(ctx, "docker", append([]string{"rm", "-f"}, containers...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("local: docker rm: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (c *Compute) startProcess(name string, env map[string]string) (string, error) {
+	bin, err := ResolveWorkerBinary(c.workerBin)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command(bin)
+	cmd.Env = append(os.Environ(), envList(env)...)
+	if c.logDir != "" {
+		if err := os.MkdirAll(c.logDir, 0o755); err != nil {
+			return "", fmt.Errorf("local: creating worker log directory: %w", err)
+		}
+		logFile, err := os.Create(filepath.Join(c.logDir, name+".log"))
+		if err != nil {
+			return "", fmt.Errorf("local: creating worker log: %w", err)
+		}
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		defer func() { _ = logFile.Close() }()
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("local: starting %s: %w", name, err)
+	}
+	c.logger.Info("Started local worker %s (pid %d)", name, cmd.Process.Pid)
+
+	c.mu.Lock()
+	c.procs = append(c.procs, cmd)
+	c.mu.Unlock()
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if err := cmd.Wait(); err != nil {
+			c.logger.Error("Local worker %s exited: %v", name, err)
+		}
+	}()
+	return name + ":" + strconv.Itoa(cmd.Process.Pid), nil
+}
+
+func (c *Compute) runDocker(ctx context.Context, name, image string, env map[string]string) (string, error) {
+	args := []string{"run", "-d", "--rm", "--name", name, "--network", "host"}
+	// Mount the storage root at the same path so HEPH_LOCAL_DIR resolves
+	// identically inside the container.
+	if dir := env["HEPH_LOCAL_DIR"]; dir != "" {
+		args = append(args, "-v", dir+":"+dir)
+	}
+	for _, kv := range envList(env) {
+		args = append(args, "-e", kv)
+	}
+	args = append(args, image)
+
+	c.logger.Info("Starting local container %s from %s", name, image)
+	out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("local: docker run %s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return name, nil
+}
+
+// buildEnv merges provider-level transport env with per-launch env. Launch
+// env wins on conflicts.
+func (c *Compute) buildEnv(optsEnv map[string]string) map[string]string {
+	env := make(map[string]string, len(c.env)+len(optsEnv))
+	for k, v := range c.env {
+		env[k] = v
+	}
+	for k, v := range optsEnv {
+		env[k] = v
+	}
+	return env
+}
+
+// ResolveWorkerBinary finds the generic worker executable: an explicit path
+// wins, then a generic-worker next to the running binary, then $PATH.
+func ResolveWorkerBinary(explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	if self, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(self), WorkerBinaryName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	if p, err := exec.LookPath(WorkerBinaryName); err == nil {
+		return p, nil
+	}
+	return "", fmt.Errorf("local: %s not found — run `make build` or set HEPH_LOCAL_WORKER_BIN", WorkerBinaryName)
+}
+
+func envList(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = k + "=" + env[k]
+	}
+	return out
+}
+
+var nameRe = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+func workerName(base string, index, total int) string {
+	base = nameRe.ReplaceAllString(base, "-")
+	if total == 1 {
+		return base
+	}
+	return fmt.Sprintf("%s-%d", base, index)
+}
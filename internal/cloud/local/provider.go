@@ -0,0 +1,142 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/cloud/selfhosted"
+	"heph4estus/internal/logger"
+)
+
+// workerDrainTimeout bounds how long Close waits for local workers to notice
+// the empty queue and exit before killing them.
+const workerDrainTimeout = 10 * time.Second
+
+// Compile-time interface check.
+var _ cloud.Provider = (*Provider)(nil)
+
+// Config is the input to NewProvider.
+type Config struct {
+	// Dir is the local runtime root. Objects live under Dir/storage and
+	// worker logs under Dir/logs.
+	Dir string
+	// NATSURL connects to an existing NATS server. When empty, NewProvider
+	// starts an embedded JetStream server owned by the provider.
+	NATSURL    string
+	StreamName string
+	// WorkerBinary overrides generic-worker discovery.
+	WorkerBinary string
+	// DockerImage, when set, launches workers with `docker run` instead of
+	// as child processes.
+	DockerImage string
+}
+
+// DefaultDir returns the default local runtime root
+// (<config-dir>/heph4estus/local).
+func DefaultDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("local: resolving config dir: %w", err)
+	}
+	return filepath.Join(base, "heph4estus", "local"), nil
+}
+
+// Provider is the local cloud.Provider.
+type Provider struct {
+	storage *Storage
+	queue   *selfhosted.Queue
+	compute *Compute
+	server  *Server
+}
+
+// NewProvider builds a local Provider. Close releases the queue connection
+// and, when one was started, the embedded NATS server.
+func NewProvider(cfg Config, log logger.Logger) (*Provider, error) {
+	if log == nil {
+		return nil, fmt.Errorf("local: logger is required")
+	}
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("local: runtime directory is required")
+	}
+	dir, err := filepath.Abs(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("local: resolving runtime directory: %w", err)
+	}
+	storage, err := NewStorage(filepath.Join(dir, "storage"), log)
+	if err != nil {
+		return nil, err
+	}
+
+	var server *Server
+	natsURL := cfg.NATSURL
+	if natsURL == "" {
+		server, err = StartServer()
+		if err != nil {
+			return nil, err
+		}
+		natsURL = server.ClientURL()
+		log.Info("Started embedded NATS at %s", natsURL)
+	}
+	queue, err := selfhosted.NewQueue(selfhosted.QueueConfig{URL: natsURL, StreamName: cfg.StreamName}, log)
+	if err != nil {
+		if server != nil {
+			_ = server.Shutdown()
+		}
+		return nil, fmt.Errorf("local: queue init: %w", err)
+	}
+
+	env := map[string]string{
+		"CLOUD":          string(cloud.KindLocal),
+		"HEPH_LOCAL_DIR": dir,
+		"NATS_URL":       natsURL,
+	}
+	if cfg.StreamName != "" {
+		env["NATS_STREAM"] = cfg.StreamName
+	}
+	compute := newCompute(cfg.WorkerBinary, cfg.DockerImage, env, filepath.Join(dir, "logs"), log)
+
+	return &Provider{storage: storage, queue: queue, compute: compute, server: server}, nil
+}
+
+// Storage returns the filesystem-backed object store.
+func (p *Provider) Storage() cloud.Storage { return p.storage }
+
+// Queue returns the JetStream queue.
+func (p *Provider) Queue() cloud.Queue { return p.queue }
+
+// Compute returns the local worker launcher.
+func (p *Provider) Compute() cloud.Compute { return p.compute }
+
+// OpenKV opens a key-value bucket on the provider's NATS server.
+func (p *Provider) OpenKV(ctx context.Context, name string) (cloud.KV, error) {
+	return p.queue.OpenKV(ctx, name)
+}
+
+// LocalCompute exposes the concrete compute so callers can wait for or stop
+// spawned workers.
+func (p *Provider) LocalCompute() *Compute { return p.compute }
+
+// Close waits briefly for spawned workers to exit, then disconnects from
+// NATS and shuts down the embedded server, if any.
+func (p *Provider) Close() error {
+	done := make(chan struct{})
+	go func() {
+		p.compute.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(workerDrainTimeout):
+		p.compute.Stop()
+		<-done
+	}
+	p.queue.Close()
+	if p.server != nil {
+		return p.server.Shutdown()
+	}
+	return nil
+}
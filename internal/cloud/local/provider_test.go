@@ -0,0 +1,175 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/logger"
+)
+
+// TestMain lets the test binary double as a generic worker: Compute spawns
+// os.Args[0] with HEPH_LOCAL_TEST_WORKER=1, which drains the queue and
+// writes one result object per message, like cmd/workers/generic.
+func TestMain(m *testing.M) {
+	if os.Getenv("HEPH_LOCAL_TEST_WORKER") == "1" {
+		os.Exit(runTestWorker())
+	}
+	os.Exit(m.Run())
+}
+
+func runTestWorker() int {
+	p, err := NewProvider(Config{Dir: os.Getenv("HEPH_LOCAL_DIR"), NATSURL: os.Getenv("NATS_URL")}, logger.NewSimpleLogger())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer func() { _ = p.Close() }()
+
+	ctx := context.Background()
+	queueID, bucket := os.Getenv("QUEUE_URL"), os.Getenv("S3_BUCKET")
+	for {
+		msg, err := p.Queue().Receive(ctx, queueID)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if msg == nil {
+			return 0
+		}
+		var task struct {
+			Target string `json:"target"`
+		}
+		_ = json.Unmarshal([]byte(msg.Body), &task)
+		key := "results/" + task.Target + ".json"
+		if err := p.Storage().Upload(ctx, bucket, key, []byte(msg.Body)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		_ = p.Queue().Delete(ctx, queueID, msg.ReceiptHandle)
+	}
+}
+
+func TestNewProvider_Validates(t *testing.T) {
+	if _, err := NewProvider(Config{Dir: t.TempDir()}, nil); err == nil {
+		t.Error("expected error without logger")
+	}
+	if _, err := NewProvider(Config{}, logger.NewSimpleLogger()); err == nil {
+		t.Error("expected error without directory")
+	}
+}
+
+func TestProvider_QueueRoundTrip(t *testing.T) {
+	p, err := NewProvider(Config{Dir: t.TempDir()}, logger.NewSimpleLogger())
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	ctx := context.Background()
+	if err := p.Queue().SendBatch(ctx, "q", []string{"one", "two"}); err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	for _, want := range []string{"one", "two"} {
+		msg, err := p.Queue().Receive(ctx, "q")
+		if err != nil || msg == nil {
+			t.Fatalf("Receive: %v, %v", msg, err)
+		}
+		if msg.Body != want {
+			t.Errorf("Body = %q, want %q", msg.Body, want)
+		}
+		if err := p.Queue().Delete(ctx, "q", msg.ReceiptHandle); err != nil {
+			t.Errorf("Delete: %v", err)
+		}
+	}
+}
+
+func TestProvider_SpotUnsupported(t *testing.T) {
+	c := newCompute("", "", nil, "", logger.NewSimpleLogger())
+	if _, err := c.RunSpotInstances(context.Background(), cloud.SpotOpts{}); err == nil {
+		t.Error("expected spot error")
+	}
+}
+
+func TestProvider_EndToEndWithLocalWorkers(t *testing.T) {
+	dir := t.TempDir()
+	p, err := NewProvider(Config{Dir: dir, WorkerBinary: os.Args[0]}, logger.NewSimpleLogger())
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	ctx := context.Background()
+	targets := []string{"a", "b", "c", "d"}
+	bodies := make([]string, len(targets))
+	for i, target := range targets {
+		bodies[i] = fmt.Sprintf(`{"tool_name":"test","target":%q}`, target)
+	}
+	if err := p.Queue().SendBatch(ctx, "jobs", bodies); err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+
+	ids, err := p.Compute().RunContainer(ctx, cloud.ContainerOpts{
+		ContainerName: "test-worker",
+		Count:         2,
+		Env: map[string]string{
+			"HEPH_LOCAL_TEST_WORKER": "1",
+			"QUEUE_URL":              "jobs",
+			"S3_BUCKET":              "bucket",
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunContainer: %v", err)
+	}
+	if ids == "" {
+		t.Error("expected worker identifiers")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.LocalCompute().Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(60 * time.Second):
+		p.LocalCompute().Stop()
+		t.Fatal("local workers did not exit")
+	}
+
+	n, err := p.Storage().Count(ctx, "bucket", "results/")
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if n != len(targets) {
+		logs, _ := os.ReadDir(dir + "/logs")
+		for _, l := range logs {
+			data, _ := os.ReadFile(dir + "/logs/" + l.Name())
+			t.Logf("%s:\n%s", l.Name(), data)
+		}
+		t.Fatalf("results = %d, want %d", n, len(targets))
+	}
+}
+
+func TestResolveWorkerBinary(t *testing.T) {
+	if got, err := ResolveWorkerBinary("/opt/heph/worker"); err != nil || got != "/opt/heph/worker" {
+		t.Errorf("explicit path = %q, %v", got, err)
+	}
+	t.Setenv("PATH", t.TempDir())
+	if _, err := ResolveWorkerBinary(""); err == nil {
+		t.Error("expected not-found error with empty PATH")
+	}
+}
+
+func TestWorkerName(t *testing.T) {
+	if got := workerName("httpx-worker", 0, 1); got != "httpx-worker" {
+		t.Errorf("single = %q", got)
+	}
+	if got := workerName("my tool/worker", 2, 3); got != "my-tool-worker-2" {
+		t.Errorf("sanitized = %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+package local
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	natsserver "github.com/nats-io/nats-server/v2/server"
+)
+
+const serverStartupTimeout = 10 * time.Second
+
+// Server is an embedded NATS JetStream server bound to the loopback
+// interface. Local workers are separate processes, so they reach it over
+// TCP through ClientURL rather than in-process.
+type Server struct {
+	srv      *natsserver.Server
+	storeDir string
+}
+
+// StartServer launches an embedded JetStream server on a random loopback
+// port with a throwaway store directory. Call Shutdown when done.
+func StartServer() (*Server, error) {
+	storeDir, err := os.MkdirTemp("", "heph-local-nats-*")
+	if err != nil {
+		return nil, fmt.Errorf("local: creating NATS store: %w", err)
+	}
+	srv, err := natsserver.NewServer(&natsserver.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  storeDir,
+		NoSigs:    true,
+		NoLog:     true,
+	})
+	if err != nil {
+		_ = os.RemoveAll(storeDir)
+		return nil, fmt.Errorf("local: embedded NATS: %w", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(serverStartupTimeout) {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+		_ = os.RemoveAll(storeDir)
+		return nil, fmt.Errorf("local: embedded NATS not ready after %s", serverStartupTimeout)
+	}
+	return &Server{srv: srv, storeDir: storeDir}, nil
+}
+
+// ClientURL returns the nats:// URL workers should connect to.
+func (s *Server) ClientURL() string { return s.srv.ClientURL() }
+
+// Shutdown stops the server and removes its store directory.
+func (s *Server) Shutdown() error {
+	s.srv.Shutdown()
+	s.srv.WaitForShutdown()
+	return os.RemoveAll(s.storeDir)
+}
@@ -0,0 +1,167 @@
+// Package local contains a cloud.Provider that runs entirely on the
+// operator's machine: a filesystem-backed object store, an embedded NATS
+// JetStream queue, and compute that spawns generic workers as local
+// processes (or local Docker containers).
+//
+// It exists for module development and as an offline integration target;
+// nothing here talks to a remote service.
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"heph4estus/internal/logger"
+)
+
+// Storage is a cloud.Storage implementation that maps buckets to
+// directories under a root and object keys to relative file paths.
+type Storage struct {
+	root   string
+	logger logger.Logger
+}
+
+// NewStorage returns a Storage rooted at dir, creating it if needed.
+func NewStorage(dir string, log logger.Logger) (*Storage, error) {
+	if log == nil {
+		return nil, fmt.Errorf("local: logger is required")
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("local: storage directory is required")
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("local: resolving storage directory: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, fmt.Errorf("local: creating storage directory: %w", err)
+	}
+	return &Storage{root: abs, logger: log}, nil
+}
+
+// Root returns the absolute storage root.
+func (s *Storage) Root() string { return s.root }
+
+// objectPath resolves bucket/key to a file path, rejecting keys that would
+// escape the bucket directory.
+func (s *Storage) objectPath(bucket, key string) (string, error) {
+	if bucket == "" || strings.ContainsAny(bucket, `/\`) || bucket == "." || bucket == ".." {
+		return "", fmt.Errorf("local: invalid bucket %q", bucket)
+	}
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || strings.HasSuffix(key, "/") {
+		return "", fmt.Errorf("local: invalid key %q", key)
+	}
+	return filepath.Join(s.root, bucket, filepath.FromSlash(clean[1:])), nil
+}
+
+func (s *Storage) Upload(_ context.Context, bucket, key string, data []byte) error {
+	p, err := s.objectPath(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("local: creating object directory: %w", err)
+	}
+	// Write to a temp file and rename so concurrent readers never observe a
+	// partially written object.
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("local: creating temp object: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("local: writing %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("local: writing %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("local: storing %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *Storage) Download(_ context.Context, bucket, key string) ([]byte, error) {
+	p, err := s.objectPath(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("local: reading %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// List returns every key in bucket that starts with prefix, sorted. A
+// missing bucket lists as empty, matching S3 semantics for an empty prefix.
+func (s *Storage) List(_ context.Context, bucket, prefix string) ([]string, error) {
+	if bucket == "" || strings.ContainsAny(bucket, `/\`) {
+		return nil, fmt.Errorf("local: invalid bucket %q", bucket)
+	}
+	bucketDir := filepath.Join(s.root, bucket)
+
+	// Only walk the deepest directory the prefix fully names.
+	start := bucketDir
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		start = filepath.Join(bucketDir, filepath.FromSlash(path.Clean("/" + prefix[:i])[1:]))
+	}
+
+	var keys []string
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(bucketDir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("local: listing %s/%s: %w", bucket, prefix, err)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes an object. A missing object is not an error, matching S3.
+func (s *Storage) Delete(_ context.Context, bucket, key string) error {
+	p, err := s.objectPath(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("local: deleting %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *Storage) Count(ctx context.Context, bucket, prefix string) (int, error) {
+	keys, err := s.List(ctx, bucket, prefix)
+	if err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
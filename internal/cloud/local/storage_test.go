@@ -0,0 +1,125 @@
+package local
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"heph4estus/internal/logger"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+	s, err := NewStorage(t.TempDir(), logger.NewSimpleLogger())
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	return s
+}
+
+func TestStorage_RoundTrip(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	if err := s.Upload(ctx, "bucket", "scans/httpx/job/results/a.json", []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	got, err := s.Download(ctx, "bucket", "scans/httpx/job/results/a.json")
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if string(got) != `{"a":1}` {
+		t.Errorf("Download = %q", got)
+	}
+
+	// Overwrite replaces the object.
+	if err := s.Upload(ctx, "bucket", "scans/httpx/job/results/a.json", []byte("v2")); err != nil {
+		t.Fatalf("Upload overwrite: %v", err)
+	}
+	got, _ = s.Download(ctx, "bucket", "scans/httpx/job/results/a.json")
+	if string(got) != "v2" {
+		t.Errorf("after overwrite = %q", got)
+	}
+}
+
+func TestStorage_ListAndCount(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	for _, key := range []string{
+		"scans/httpx/job-1/results/b.json",
+		"scans/httpx/job-1/results/a.json",
+		"scans/httpx/job-1/artifacts/a.txt",
+		"scans/httpx/job-2/results/c.json",
+	} {
+		if err := s.Upload(ctx, "bucket", key, []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := s.List(ctx, "bucket", "scans/httpx/job-1/results/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	want := []string{"scans/httpx/job-1/results/a.json", "scans/httpx/job-1/results/b.json"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("List = %v, want %v", keys, want)
+	}
+
+	// Partial final segment still matches by string prefix.
+	keys, _ = s.List(ctx, "bucket", "scans/httpx/job-")
+	if len(keys) != 4 {
+		t.Errorf("partial prefix listed %d keys, want 4", len(keys))
+	}
+
+	n, err := s.Count(ctx, "bucket", "scans/httpx/job-2/")
+	if err != nil || n != 1 {
+		t.Errorf("Count = %d, %v; want 1", n, err)
+	}
+}
+
+func TestStorage_MissingBucketListsEmpty(t *testing.T) {
+	s := newTestStorage(t)
+	keys, err := s.List(context.Background(), "nope", "scans/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("List = %v, want empty", keys)
+	}
+}
+
+func TestStorage_RejectsEscapingKeys(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	if err := s.Upload(ctx, "bucket", "../../etc/passwd", []byte("x")); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	// The key is confined to the bucket rather than escaping the root.
+	if _, err := s.Download(ctx, "bucket", "etc/passwd"); err != nil {
+		t.Errorf("expected escaped key to land inside bucket: %v", err)
+	}
+	if err := s.Upload(ctx, "../other", "k", []byte("x")); err == nil {
+		t.Error("expected invalid bucket error")
+	}
+	if err := s.Upload(ctx, "bucket", "", []byte("x")); err == nil {
+		t.Error("expected empty key error")
+	}
+}
+
+func TestStorage_Delete(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	if err := s.Upload(ctx, "bucket", "cache/wordlists/d/chunk_0.txt", []byte("a\n")); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if err := s.Delete(ctx, "bucket", "cache/wordlists/d/chunk_0.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if keys, _ := s.List(ctx, "bucket", "cache/"); len(keys) != 0 {
+		t.Errorf("List after delete = %v", keys)
+	}
+	if err := s.Delete(ctx, "bucket", "cache/wordlists/d/chunk_0.txt"); err != nil {
+		t.Errorf("deleting a missing object: %v", err)
+	}
+}
@@ -78,6 +78,7 @@ type Compute struct {
 	RunContainerFunc    func(ctx context.Context, opts cloud.ContainerOpts) (string, error)
 	RunSpotInstancesFunc func(ctx context.Context, opts cloud.SpotOpts) ([]string, error)
 	GetSpotStatusFunc   func(ctx context.Context, instanceIDs []string) ([]cloud.SpotStatus, error)
+	StopWorkersFunc     func(ctx context.Context, ids []string) error
 }
 
 func (c *Compute) RunContainer(ctx context.Context, opts cloud.ContainerOpts) (string, error) {
@@ -92,6 +93,14 @@ func (c *Compute) GetSpotStatus(ctx context.Context, instanceIDs []string) ([]cl
 	return c.GetSpotStatusFunc(ctx, instanceIDs)
 }
 
+// StopWorkers returns cloud.ErrNotImplemented unless StopWorkersFunc is set.
+func (c *Compute) StopWorkers(ctx context.Context, ids []string) error {
+	if c.StopWorkersFunc == nil {
+		return cloud.ErrNotImplemented
+	}
+	return c.StopWorkersFunc(ctx, ids)
+}
+
 // ProgressCounter is a test double for cloud.ProgressCounter.
 type ProgressCounter struct {
 	IncrementFunc func(ctx context.Context, counterID string) error
@@ -3,6 +3,7 @@ package cloud
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 // ErrNotImplemented is returned by stub implementations.
@@ -31,6 +32,23 @@ type Queue interface {
 	Delete(ctx context.Context, queueID, receiptHandle string) error
 }
 
+// MessageReleaser is implemented by Queue backends that can hand a received
+// message back without processing it, keeping it hidden for delay.
+type MessageReleaser interface {
+	Release(ctx context.Context, queueID, receiptHandle string, delay time.Duration) error
+}
+
+// ReleaseMessage returns a received message to q when q supports it and
+// returns ErrNotImplemented otherwise; the message then reappears once its
+// visibility timeout expires.
+func ReleaseMessage(ctx context.Context, q Queue, queueID, receiptHandle string, delay time.Duration) error {
+	r, ok := q.(MessageReleaser)
+	if !ok {
+		return ErrNotImplemented
+	}
+	return r.Release(ctx, queueID, receiptHandle, delay)
+}
+
 // Message represents a single message received from a queue.
 type Message struct {
 	ID            string
@@ -46,6 +64,23 @@ type Compute interface {
 	GetSpotStatus(ctx context.Context, instanceIDs []string) ([]SpotStatus, error)
 }
 
+// WorkerStopper is implemented by Compute backends that can stop workers
+// they launched, identified by the IDs RunContainer and RunSpotInstances
+// returned.
+type WorkerStopper interface {
+	StopWorkers(ctx context.Context, ids []string) error
+}
+
+// StopWorkers stops the given workers when c supports it and returns
+// ErrNotImplemented otherwise.
+func StopWorkers(ctx context.Context, c Compute, ids []string) error {
+	s, ok := c.(WorkerStopper)
+	if !ok {
+		return ErrNotImplemented
+	}
+	return s.StopWorkers(ctx, ids)
+}
+
 // ContainerOpts configures a container task (ECS Fargate, Cloud Run, etc.).
 type ContainerOpts struct {
 	Image          string
@@ -113,6 +113,24 @@ func (c *DockerCompute) RunContainer(ctx context.Context, opts cloud.ContainerOp
 	return strings.Join(launched, ","), nil
 }
 
+// StopWorkers removes the listed containers ("host:name") over SSH.
+func (c *DockerCompute) StopWorkers(ctx context.Context, ids []string) error {
+	var errs []error
+	for _, id := range ids {
+		i := strings.LastIndex(id, ":")
+		if i <= 0 {
+			errs = append(errs, fmt.Errorf("invalid worker id %q", id))
+			continue
+		}
+		host, name := id[:i], id[i+1:]
+		c.logger.Info("Stopping container %s on %s", name, host)
+		if err := c.runner.Run(ctx, host, "docker rm -f "+name); err != nil {
+			errs = append(errs, fmt.Errorf("docker rm on %s: %w", host, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (c *DockerCompute) RunSpotInstances(_ context.Context, _ cloud.SpotOpts) ([]string, error) {
 	return nil, errSpotUnsupported
 }
@@ -268,6 +268,23 @@ func TestContainerName(t *testing.T) {
 
 // --- Spot methods ---
 
+func TestDockerCompute_StopWorkers(t *testing.T) {
+	rec := &recordingRunner{}
+	dc := testCompute([]string{"10.0.0.1", "10.0.0.2"}, "img", nil, rec)
+
+	ids, err := dc.RunContainer(context.Background(), cloud.ContainerOpts{ContainerName: "w", Count: 2})
+	if err != nil {
+		t.Fatalf("RunContainer: %v", err)
+	}
+	rec.calls = nil
+	if err := cloud.StopWorkers(context.Background(), dc, strings.Split(ids, ",")); err != nil {
+		t.Fatalf("StopWorkers: %v", err)
+	}
+	if len(rec.calls) != 2 || rec.calls[1].Host != "10.0.0.2" || rec.calls[1].Cmd != "docker rm -f w-1" {
+		t.Errorf("calls = %+v", rec.calls)
+	}
+}
+
 func TestDockerCompute_SpotUnsupported(t *testing.T) {
 	dc := testCompute([]string{"h1"}, "img", map[string]string{}, &recordingRunner{})
 	ctx := context.Background()
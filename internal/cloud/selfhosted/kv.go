@@ -0,0 +1,108 @@
+package selfhosted
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"heph4estus/internal/cloud"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// kvTTL bounds how long an untouched key lives in a KV bucket.
+const kvTTL = 24 * time.Hour
+
+// KV is a cloud.KV backed by a JetStream key-value bucket.
+type KV struct {
+	kv jetstream.KeyValue
+}
+
+// Compile-time interface checks.
+var (
+	_ cloud.KV              = (*KV)(nil)
+	_ cloud.KVOpener        = (*Provider)(nil)
+	_ cloud.MessageRequeuer = (*Queue)(nil)
+)
+
+// OpenKV opens the JetStream key-value bucket name, creating it when needed.
+func (q *Queue) OpenKV(ctx context.Context, name string) (cloud.KV, error) {
+	kv, err := q.js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:  name,
+		History: 1,
+		TTL:     kvTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("selfhosted: open kv %q: %w", name, err)
+	}
+	return &KV{kv: kv}, nil
+}
+
+// OpenKV opens a key-value bucket on the provider's NATS server.
+func (p *Provider) OpenKV(ctx context.Context, name string) (cloud.KV, error) {
+	q, ok := p.queue.(*Queue)
+	if !ok {
+		return nil, errQueueNotConfigured
+	}
+	return q.OpenKV(ctx, name)
+}
+
+// Get returns the value and revision of key.
+func (k *KV) Get(ctx context.Context, key string) ([]byte, uint64, error) {
+	entry, err := k.kv.Get(ctx, key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("selfhosted: kv get %q: %w", key, err)
+	}
+	return entry.Value(), entry.Revision(), nil
+}
+
+// Put creates key when revision is 0 and updates it otherwise.
+func (k *KV) Put(ctx context.Context, key string, value []byte, revision uint64) error {
+	var err error
+	if revision == 0 {
+		_, err = k.kv.Create(ctx, key, value)
+	} else {
+		_, err = k.kv.Update(ctx, key, value, revision)
+	}
+	if errors.Is(err, jetstream.ErrKeyExists) {
+		return cloud.ErrKVConflict
+	}
+	if err != nil {
+		return fmt.Errorf("selfhosted: kv put %q: %w", key, err)
+	}
+	return nil
+}
+
+// Requeue hands a received message back for another attempt after delay.
+// JetStream redelivers it after a delayed nak, which counts as a delivery;
+// once only one delivery is left, the message is republished instead so it
+// is never dropped, at the cost of the copy being visible straight away.
+func (q *Queue) Requeue(ctx context.Context, queueID, receiptHandle, body string, delay time.Duration) error {
+	q.mu.Lock()
+	msg, ok := q.inflight[receiptHandle]
+	if ok {
+		delete(q.inflight, receiptHandle)
+	}
+	q.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("selfhosted: unknown receipt handle %q", receiptHandle)
+	}
+	meta, err := msg.Metadata()
+	if err != nil {
+		return fmt.Errorf("selfhosted: message metadata: %w", err)
+	}
+	if int(meta.NumDelivered) < q.cfg.maxDeliver()-1 {
+		q.logger.Info("Requeueing message via receipt handle: %s", receiptHandle)
+		return msg.NakWithDelay(delay)
+	}
+	q.logger.Info("Republishing message via receipt handle: %s", receiptHandle)
+	if err := q.Send(ctx, queueID, body); err != nil {
+		_ = msg.NakWithDelay(delay)
+		return err
+	}
+	return msg.Ack()
+}
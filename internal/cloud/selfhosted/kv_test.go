@@ -0,0 +1,60 @@
+package selfhosted
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"heph4estus/internal/cloud"
+)
+
+func TestKV_CompareAndSwap(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+	kv, err := q.OpenKV(ctx, "test-leases")
+	if err != nil {
+		t.Fatalf("OpenKV: %v", err)
+	}
+
+	if v, rev, err := kv.Get(ctx, "target.a"); err != nil || v != nil || rev != 0 {
+		t.Fatalf("Get missing = %q, %d, %v", v, rev, err)
+	}
+	if err := kv.Put(ctx, "target.a", []byte("one"), 0); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := kv.Put(ctx, "target.a", []byte("again"), 0); !errors.Is(err, cloud.ErrKVConflict) {
+		t.Fatalf("second create = %v, want ErrKVConflict", err)
+	}
+	v, rev, err := kv.Get(ctx, "target.a")
+	if err != nil || string(v) != "one" || rev == 0 {
+		t.Fatalf("Get = %q, %d, %v", v, rev, err)
+	}
+	if err := kv.Put(ctx, "target.a", []byte("two"), rev); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := kv.Put(ctx, "target.a", []byte("stale"), rev); !errors.Is(err, cloud.ErrKVConflict) {
+		t.Fatalf("stale update = %v, want ErrKVConflict", err)
+	}
+}
+
+func TestRequeue_RepublishesBeforeLastDelivery(t *testing.T) {
+	q := newTestQueue(t) // MaxDeliver 3
+	ctx := context.Background()
+	if err := q.Send(ctx, "q-requeue", "task"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		msg, err := q.Receive(ctx, "q-requeue")
+		if err != nil || msg == nil {
+			t.Fatalf("receive %d = %v, %v; the task must not be dropped", i, msg, err)
+		}
+		if msg.ReceiveCount > 2 {
+			t.Fatalf("receive %d: ReceiveCount = %d, want a republished copy before the last delivery", i, msg.ReceiveCount)
+		}
+		if err := q.Requeue(ctx, "q-requeue", msg.ReceiptHandle, msg.Body, time.Millisecond); err != nil {
+			t.Fatalf("requeue %d: %v", i, err)
+		}
+	}
+}
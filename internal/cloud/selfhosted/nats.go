@@ -242,6 +242,21 @@ func (q *Queue) Delete(ctx context.Context, queueID, receiptHandle string) error
 	return msg.Ack()
 }
 
+// Release naks a received message so JetStream redelivers it after delay.
+func (q *Queue) Release(ctx context.Context, queueID, receiptHandle string, delay time.Duration) error {
+	q.logger.Info("Releasing message via receipt handle: %s", receiptHandle)
+	q.mu.Lock()
+	msg, ok := q.inflight[receiptHandle]
+	if ok {
+		delete(q.inflight, receiptHandle)
+	}
+	q.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("selfhosted: unknown receipt handle %q", receiptHandle)
+	}
+	return msg.NakWithDelay(delay)
+}
+
 // Close drains the NATS connection. Callers should call this on shutdown.
 func (q *Queue) Close() {
 	q.nc.Close()
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"strings"
 
+	"heph4estus/internal/cloud"
 	"heph4estus/internal/logger"
 	"heph4estus/internal/tlsutil"
 
@@ -29,6 +30,12 @@ type S3API interface {
 	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
 }
 
+// s3Deleter is the delete call of the S3 SDK. It is kept out of S3API so
+// fakes that never delete need not implement it.
+type s3Deleter interface {
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
 // StorageConfig describes an S3-compatible endpoint such as MinIO. Callers
 // pass this explicitly; nothing is read from the ambient AWS environment so
 // operator misconfiguration surfaces as a clear error rather than an
@@ -162,6 +169,20 @@ func (s *Storage) List(ctx context.Context, bucket, prefix string) ([]string, er
 	return keys, nil
 }
 
+// Delete removes an object from an S3-compatible bucket.
+func (s *Storage) Delete(ctx context.Context, bucket, key string) error {
+	d, ok := s.client.(s3Deleter)
+	if !ok {
+		return cloud.ErrNotImplemented
+	}
+	s.logger.Info("Deleting object from S3-compatible: %s/%s", bucket, key)
+	_, err := d.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
 // Count returns the number of objects matching a prefix without materializing keys.
 func (s *Storage) Count(ctx context.Context, bucket, prefix string) (int, error) {
 	s.logger.Info("Counting objects in S3-compatible: %s/%s", bucket, prefix)
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"heph4estus/internal/cloud"
 	"heph4estus/internal/logger"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -39,6 +40,11 @@ func (f *fakeS3) PutObject(_ context.Context, in *s3.PutObjectInput, optFns ...f
 	return &s3.PutObjectOutput{}, nil
 }
 
+func (f *fakeS3) DeleteObject(_ context.Context, in *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	delete(f.objects, aws.ToString(in.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
 func (f *fakeS3) GetObject(_ context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
 	data, ok := f.objects[aws.ToString(in.Key)]
 	if !ok {
@@ -104,6 +110,22 @@ func TestStorageUploadDownloadRoundTrip(t *testing.T) {
 	}
 }
 
+func TestStorageDelete(t *testing.T) {
+	fake := newFakeS3()
+	s := NewStorageWithClient(fake, logger.NewSimpleLogger())
+	ctx := context.Background()
+
+	if err := s.Upload(ctx, "bucket", "cache/a.txt", []byte("a")); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if err := cloud.DeleteObject(ctx, s, "bucket", "cache/a.txt"); err != nil {
+		t.Fatalf("DeleteObject: %v", err)
+	}
+	if _, ok := fake.objects["cache/a.txt"]; ok {
+		t.Fatal("object still present after delete")
+	}
+}
+
 func TestStorageListSinglePage(t *testing.T) {
 	fake := newFakeS3()
 	fake.objects["scans/a.json"] = []byte("1")
@@ -0,0 +1,18 @@
+package cloud
+
+import "context"
+
+// ObjectDeleter is implemented by Storage backends that can remove objects.
+type ObjectDeleter interface {
+	Delete(ctx context.Context, bucket, key string) error
+}
+
+// DeleteObject removes key from bucket when s supports it and returns
+// ErrNotImplemented otherwise. Deleting a missing object is not an error.
+func DeleteObject(ctx context.Context, s Storage, bucket, key string) error {
+	d, ok := s.(ObjectDeleter)
+	if !ok {
+		return ErrNotImplemented
+	}
+	return d.Delete(ctx, bucket, key)
+}
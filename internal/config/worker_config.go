@@ -1,9 +1,13 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"strconv"
+
+	"heph4estus/internal/schedule"
 )
 
 // WorkerConfig represents the configuration for the generic worker.
@@ -13,6 +17,11 @@ type WorkerConfig struct {
 	Bucket           string // S3_BUCKET — storage bucket name
 	ToolName         string
 	JitterMaxSeconds int // JITTER_MAX_SECONDS; 0 = disabled
+	// Window is the scan window from SCAN_WINDOW (JSON); nil = always open.
+	Window *schedule.Window
+	// LeaseStore is LEASE_STORE, the shared KV store (NATS KV bucket or
+	// DynamoDB table) holding per-target politeness leases.
+	LeaseStore string
 
 	// Fleet heartbeat settings (selfhosted/Hetzner workers).
 	FleetHeartbeat       bool   // FLEET_HEARTBEAT; enables heartbeat publishing
@@ -59,6 +68,17 @@ func NewWorkerConfig() (*WorkerConfig, error) {
 		}
 	}
 
+	var window *schedule.Window
+	if v := os.Getenv("SCAN_WINDOW"); v != "" {
+		window = &schedule.Window{}
+		if err := json.Unmarshal([]byte(v), window); err != nil {
+			return nil, fmt.Errorf("invalid SCAN_WINDOW: %w", err)
+		}
+		if err := window.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid SCAN_WINDOW: %w", err)
+		}
+	}
+
 	fleetHeartbeat := os.Getenv("FLEET_HEARTBEAT") == "true"
 	workerID := os.Getenv("WORKER_ID")
 	if workerID == "" {
@@ -72,6 +92,8 @@ func NewWorkerConfig() (*WorkerConfig, error) {
 		Bucket:               bucket,
 		ToolName:             toolName,
 		JitterMaxSeconds:     jitterMax,
+		Window:               window,
+		LeaseStore:           os.Getenv("LEASE_STORE"),
 		FleetHeartbeat:       fleetHeartbeat,
 		WorkerID:             workerID,
 		WorkerHost:           os.Getenv("WORKER_HOST"),
@@ -81,6 +81,26 @@ func TestNewWorkerConfig_JitterInvalidIgnored(t *testing.T) {
 	}
 }
 
+func TestNewWorkerConfig_ScanWindow(t *testing.T) {
+	t.Setenv("QUEUE_URL", "q")
+	t.Setenv("S3_BUCKET", "b")
+	t.Setenv("TOOL_NAME", "httpx")
+	t.Setenv("SCAN_WINDOW", `{"tz":"UTC","ranges":[{"days":[1,2,3,4,5],"start":540,"end":1020}]}`)
+
+	cfg, err := NewWorkerConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Window == nil || cfg.Window.String() != "Mon-Fri 09:00-17:00 (UTC)" {
+		t.Errorf("Window = %v", cfg.Window)
+	}
+
+	t.Setenv("SCAN_WINDOW", `{"tz":"Nowhere/Special"}`)
+	if _, err := NewWorkerConfig(); err == nil {
+		t.Error("expected error for invalid SCAN_WINDOW")
+	}
+}
+
 func TestNewWorkerConfig_SelfhostedScanRuntime(t *testing.T) {
 	// Prove a selfhosted worker reads env-driven queue/bucket exactly like AWS.
 	t.Setenv("QUEUE_URL", "nats-subject")
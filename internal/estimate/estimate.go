@@ -0,0 +1,233 @@
+// Package estimate predicts how long a job will run and what it will cost
+// on each supported cloud. Per-task durations come from completed job
+// records and fleet benchmark reports; prices are list prices for the
+// default worker sizes and are meant for budgeting, not billing.
+package estimate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"heph4estus/internal/bench"
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/operator"
+)
+
+// DefaultPerTask is assumed when there is no history for a tool.
+const DefaultPerTask = time.Minute
+
+// Rate is the hourly price and typical startup time of one way to run
+// workers.
+type Rate struct {
+	Cloud       cloud.Kind
+	ComputeMode string // "fargate" or "spot" on AWS, empty elsewhere
+	// WorkerHourly is the USD price of one worker for one hour.
+	WorkerHourly float64
+	// ControllerHourly is the USD price of the controller, if any.
+	ControllerHourly float64
+	// Startup is the typical time from launch until workers take tasks.
+	Startup time.Duration
+	Size    string
+}
+
+// DefaultRates lists the default worker sizes of each cloud.
+var DefaultRates = []Rate{
+	{Cloud: cloud.KindAWS, ComputeMode: "fargate", WorkerHourly: 0.0123, Startup: 90 * time.Second, Size: "Fargate 0.25 vCPU / 0.5 GB"},
+	{Cloud: cloud.KindAWS, ComputeMode: "spot", WorkerHourly: 0.0040, Startup: 3 * time.Minute, Size: "EC2 Spot t3.micro"},
+	{Cloud: cloud.KindHetzner, WorkerHourly: 0.0072, ControllerHourly: 0.0072, Startup: 4 * time.Minute, Size: "CX22"},
+	{Cloud: cloud.KindLinode, WorkerHourly: 0.0075, ControllerHourly: 0.0075, Startup: 4 * time.Minute, Size: "Nanode 1 GB"},
+	{Cloud: cloud.KindVultr, WorkerHourly: 0.0070, ControllerHourly: 0.0070, Startup: 4 * time.Minute, Size: "vc2-1c-1gb"},
+	{Cloud: cloud.KindManual, Size: "operator-managed hosts"},
+	{Cloud: cloud.KindLocal, Size: "local processes"},
+}
+
+func (r Rate) matches(kind cloud.Kind, computeMode string) bool {
+	return r.Cloud == kind.Canonical() && (r.ComputeMode == "" || r.ComputeMode == computeMode)
+}
+
+// RateFor returns the default rate for kind and the resolved compute mode.
+func RateFor(kind cloud.Kind, computeMode string) (Rate, bool) {
+	for _, r := range DefaultRates {
+		if r.matches(kind, computeMode) {
+			return r, true
+		}
+	}
+	return Rate{}, false
+}
+
+// Spend returns the USD cost of running workers at r for d.
+func (r Rate) Spend(workers int, d time.Duration) float64 {
+	return d.Hours() * (float64(workers)*r.WorkerHourly + r.ControllerHourly)
+}
+
+// Sample is one observed per-task duration: the worker time a single task
+// took on average in a past run.
+type Sample struct {
+	PerTask time.Duration
+	Cloud   string
+	Source  string // "job" or "bench"
+}
+
+// FromJobs derives samples from completed job records of tool.
+func FromJobs(records []*operator.JobRecord, tool string) []Sample {
+	var out []Sample
+	for _, rec := range records {
+		if rec == nil || rec.ToolName != tool || rec.Phase != operator.PhaseComplete {
+			continue
+		}
+		if rec.TotalTasks <= 0 || rec.StartedAt.IsZero() || !rec.UpdatedAt.After(rec.StartedAt) {
+			continue
+		}
+		workers := min(max(rec.WorkerCount, 1), rec.TotalTasks)
+		wall := rec.UpdatedAt.Sub(rec.StartedAt)
+		out = append(out, Sample{
+			PerTask: wall * time.Duration(workers) / time.Duration(rec.TotalTasks),
+			Cloud:   rec.Cloud,
+			Source:  "job",
+		})
+	}
+	return out
+}
+
+// FromBench derives samples from fleet benchmark reports that measured
+// task throughput.
+func FromBench(reports []bench.FleetReport) []Sample {
+	var out []Sample
+	for _, r := range reports {
+		if r.TasksPerMinute <= 0 || r.DesiredWorkers <= 0 {
+			continue
+		}
+		perTask := time.Duration(float64(time.Minute) * float64(r.DesiredWorkers) / r.TasksPerMinute)
+		out = append(out, Sample{PerTask: perTask, Cloud: r.Cloud, Source: "bench"})
+	}
+	return out
+}
+
+// Input describes the job being estimated.
+type Input struct {
+	Tasks       int
+	Workers     int
+	Cloud       cloud.Kind
+	ComputeMode string // resolved: "fargate" or "spot" on AWS
+	Samples     []Sample
+}
+
+// Cost is the estimate for running the job on one Rate.
+type Cost struct {
+	Cloud       string        `json:"cloud"`
+	ComputeMode string        `json:"compute_mode,omitempty"`
+	Size        string        `json:"size"`
+	Runtime     time.Duration `json:"runtime"`
+	WorkerHours float64       `json:"worker_hours"`
+	USD         float64       `json:"usd"`
+	Selected    bool          `json:"selected,omitempty"`
+}
+
+// Estimate is the predicted runtime and cost of a job.
+type Estimate struct {
+	PerTask time.Duration `json:"per_task"`
+	// Basis explains where PerTask came from.
+	Basis       string        `json:"basis"`
+	Workers     int           `json:"workers"`
+	Waves       int           `json:"waves"`
+	Runtime     time.Duration `json:"runtime"`
+	WorkerHours float64       `json:"worker_hours"`
+	USD         float64       `json:"usd"`
+	Costs       []Cost        `json:"costs"`
+}
+
+// Compute estimates in for the selected cloud and every default rate.
+// Samples from the selected cloud are preferred over samples from others.
+func Compute(in Input) *Estimate {
+	perTask, basis := perTask(in.Samples, in.Cloud)
+	workers := max(1, min(in.Workers, in.Tasks))
+	waves := 0
+	if in.Tasks > 0 {
+		waves = (in.Tasks + workers - 1) / workers
+	}
+	work := time.Duration(waves) * perTask
+
+	est := &Estimate{PerTask: perTask, Basis: basis, Workers: workers, Waves: waves}
+	for _, rate := range DefaultRates {
+		runtime := rate.Startup + work
+		hours := runtime.Hours()
+		c := Cost{
+			Cloud:       string(rate.Cloud),
+			ComputeMode: rate.ComputeMode,
+			Size:        rate.Size,
+			Runtime:     runtime,
+			WorkerHours: hours * float64(workers),
+			USD:         rate.Spend(workers, runtime),
+			Selected:    rate.matches(in.Cloud, in.ComputeMode),
+		}
+		if c.Selected {
+			est.Runtime, est.WorkerHours, est.USD = c.Runtime, c.WorkerHours, c.USD
+		}
+		est.Costs = append(est.Costs, c)
+	}
+	if est.Runtime == 0 {
+		est.Runtime = work
+		est.WorkerHours = work.Hours() * float64(workers)
+	}
+	return est
+}
+
+// perTask returns the median sample, preferring samples from kind.
+func perTask(samples []Sample, kind cloud.Kind) (time.Duration, string) {
+	var same []Sample
+	for _, s := range samples {
+		if cloud.Kind(s.Cloud).Canonical() == kind.Canonical() {
+			same = append(same, s)
+		}
+	}
+	if len(same) > 0 {
+		samples = same
+	}
+	if len(samples) == 0 {
+		return DefaultPerTask, fmt.Sprintf("no history; assumed %s per task", DefaultPerTask)
+	}
+	d := make([]time.Duration, len(samples))
+	jobs, benches := 0, 0
+	for i, s := range samples {
+		d[i] = s.PerTask
+		if s.Source == "bench" {
+			benches++
+		} else {
+			jobs++
+		}
+	}
+	sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+	median := d[len(d)/2]
+	if len(d)%2 == 0 {
+		median = (d[len(d)/2-1] + d[len(d)/2]) / 2
+	}
+	return median, fmt.Sprintf("median of %d past job(s) and %d benchmark(s)", jobs, benches)
+}
+
+// LoadSamples reads the per-task history of tool from the job store and the
+// benchmark store. Either store may be nil.
+func LoadSamples(jobs *operator.JobStore, benches *bench.Store, tool string) ([]Sample, error) {
+	var samples []Sample
+	if jobs != nil {
+		ids, err := jobs.List()
+		if err != nil {
+			return nil, err
+		}
+		records := make([]*operator.JobRecord, 0, len(ids))
+		for _, id := range ids {
+			if rec, err := jobs.Load(id); err == nil {
+				records = append(records, rec)
+			}
+		}
+		samples = append(samples, FromJobs(records, tool)...)
+	}
+	if benches != nil {
+		reports, err := benches.List(tool, "", 0)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, FromBench(reports)...)
+	}
+	return samples, nil
+}
@@ -0,0 +1,106 @@
+package estimate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"heph4estus/internal/bench"
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/operator"
+)
+
+func TestFromJobs(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []*operator.JobRecord{
+		// 100 tasks on 10 workers in 10 minutes: 1 minute of worker time per task.
+		{ToolName: "httpx", Phase: operator.PhaseComplete, TotalTasks: 100, WorkerCount: 10, StartedAt: start, UpdatedAt: start.Add(10 * time.Minute), Cloud: "aws"},
+		// Fewer tasks than workers: only 2 workers were busy.
+		{ToolName: "httpx", Phase: operator.PhaseComplete, TotalTasks: 2, WorkerCount: 10, StartedAt: start, UpdatedAt: start.Add(time.Minute)},
+		{ToolName: "httpx", Phase: operator.PhaseFailed, TotalTasks: 100, WorkerCount: 10, StartedAt: start, UpdatedAt: start.Add(time.Hour)},
+		{ToolName: "nuclei", Phase: operator.PhaseComplete, TotalTasks: 1, WorkerCount: 1, StartedAt: start, UpdatedAt: start.Add(time.Hour)},
+		{ToolName: "httpx", Phase: operator.PhaseComplete, TotalTasks: 5},
+	}
+	samples := FromJobs(records, "httpx")
+	if len(samples) != 2 {
+		t.Fatalf("samples = %+v, want 2", samples)
+	}
+	if samples[0].PerTask != time.Minute || samples[1].PerTask != time.Minute {
+		t.Errorf("per-task = %s, %s, want 1m each", samples[0].PerTask, samples[1].PerTask)
+	}
+}
+
+func TestFromBench(t *testing.T) {
+	samples := FromBench([]bench.FleetReport{
+		{Cloud: "hetzner", DesiredWorkers: 20, TasksPerMinute: 40},
+		{Cloud: "hetzner", DesiredWorkers: 20},
+	})
+	if len(samples) != 1 || samples[0].PerTask != 30*time.Second {
+		t.Fatalf("samples = %+v", samples)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	est := Compute(Input{
+		Tasks:       1000,
+		Workers:     100,
+		Cloud:       cloud.KindAWS,
+		ComputeMode: "spot",
+		Samples: []Sample{
+			{PerTask: 20 * time.Second, Cloud: "aws", Source: "job"},
+			{PerTask: 40 * time.Second, Cloud: "aws", Source: "job"},
+			{PerTask: 10 * time.Minute, Cloud: "hetzner", Source: "bench"},
+		},
+	})
+	if est.PerTask != 30*time.Second || !strings.Contains(est.Basis, "2 past job(s)") {
+		t.Errorf("per-task = %s (%s), want 30s from aws jobs", est.PerTask, est.Basis)
+	}
+	if est.Waves != 10 {
+		t.Errorf("waves = %d, want 10", est.Waves)
+	}
+	// 3m spot startup + 10 waves x 30s.
+	if est.Runtime != 8*time.Minute {
+		t.Errorf("runtime = %s, want 8m", est.Runtime)
+	}
+	var selected int
+	for _, c := range est.Costs {
+		if c.Selected {
+			selected++
+			if c.Cloud != "aws" || c.ComputeMode != "spot" || c.USD != est.USD {
+				t.Errorf("unexpected selected cost: %+v", c)
+			}
+		}
+		if c.Cloud == "local" && c.USD != 0 {
+			t.Errorf("local should be free: %+v", c)
+		}
+	}
+	if selected != 1 {
+		t.Errorf("selected = %d, want 1", selected)
+	}
+}
+
+func TestCompute_NoHistory(t *testing.T) {
+	est := Compute(Input{Tasks: 3, Workers: 10, Cloud: cloud.KindLocal})
+	if est.PerTask != DefaultPerTask || !strings.Contains(est.Basis, "no history") {
+		t.Errorf("estimate = %+v", est)
+	}
+	if est.Workers != 3 || est.Runtime != DefaultPerTask {
+		t.Errorf("workers = %d runtime = %s", est.Workers, est.Runtime)
+	}
+}
+
+func TestLoadSamples(t *testing.T) {
+	store := operator.NewJobStoreAt(t.TempDir())
+	start := time.Now().Add(-time.Hour).UTC()
+	rec := &operator.JobRecord{JobID: "httpx-1", ToolName: "httpx", Phase: operator.PhaseComplete, TotalTasks: 10, WorkerCount: 1, StartedAt: start}
+	if err := store.Create(rec); err != nil {
+		t.Fatal(err)
+	}
+	samples, err := LoadSamples(store, bench.NewStoreAt(t.TempDir()), "httpx")
+	if err != nil {
+		t.Fatalf("LoadSamples: %v", err)
+	}
+	if len(samples) != 1 || samples[0].PerTask < 5*time.Minute {
+		t.Errorf("samples = %+v", samples)
+	}
+}
@@ -0,0 +1,95 @@
+package findings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"heph4estus/internal/worker"
+)
+
+// Source lists a job's results and reads them and the artifacts they
+// reference. The TUI's S3 and local results sources implement it.
+type Source interface {
+	ListKeys(ctx context.Context) ([]string, error)
+	Download(ctx context.Context, key string) ([]byte, error)
+	DownloadArtifact(ctx context.Context, outputKey string) ([]byte, error)
+}
+
+// Task is one task's result and the findings of its report.
+type Task struct {
+	Key      string
+	Result   worker.Result
+	Findings []Finding
+	// Err describes a result or report that could not be read.
+	Err string
+}
+
+// Failed reports whether the task itself failed.
+func (t Task) Failed() bool {
+	return t.Result.Error != ""
+}
+
+// collectWorkers bounds the downloads Collect runs at once.
+const collectWorkers = 16
+
+// Collect reads every result of src and parses the report of each task
+// whose tool has a parser. Tasks are returned in key order. A result or
+// report that cannot be read is recorded on its task rather than failing
+// the whole collection.
+func Collect(ctx context.Context, src Source) ([]Task, error) {
+	keys, err := src.ListKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing results: %w", err)
+	}
+	var resultKeys []string
+	for _, k := range keys {
+		if strings.HasSuffix(k, ".json") {
+			resultKeys = append(resultKeys, k)
+		}
+	}
+	sort.Strings(resultKeys)
+
+	tasks := make([]Task, len(resultKeys))
+	sem := make(chan struct{}, collectWorkers)
+	var wg sync.WaitGroup
+	for i, key := range resultKeys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			tasks[i] = collectTask(ctx, src, key)
+		}()
+	}
+	wg.Wait()
+	return tasks, ctx.Err()
+}
+
+func collectTask(ctx context.Context, src Source, key string) Task {
+	t := Task{Key: key}
+	data, err := src.Download(ctx, key)
+	if err != nil {
+		t.Err = err.Error()
+		return t
+	}
+	if err := json.Unmarshal(data, &t.Result); err != nil {
+		t.Err = fmt.Sprintf("parsing result: %v", err)
+		return t
+	}
+	if t.Result.OutputKey == "" || !Has(t.Result.ToolName) {
+		return t
+	}
+	report, err := src.DownloadArtifact(ctx, t.Result.OutputKey)
+	if err != nil {
+		t.Err = err.Error()
+		return t
+	}
+	if t.Findings, err = Parse(OriginOf(t.Result), report); err != nil {
+		t.Err = err.Error()
+	}
+	return t
+}
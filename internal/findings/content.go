@@ -0,0 +1,93 @@
+package findings
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ffufReport holds the fields of ffuf's JSON output (-of json).
+type ffufReport struct {
+	Results []struct {
+		Input            map[string]any `json:"input"`
+		Status           int            `json:"status"`
+		Length           int            `json:"length"`
+		URL              string         `json:"url"`
+		RedirectLocation string         `json:"redirectlocation"`
+	} `json:"results"`
+}
+
+// parseFFUF reports each ffuf hit as a path.
+func parseFFUF(data []byte, _ string) ([]Finding, error) {
+	var rep ffufReport
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, hit := range rep.Results {
+		if hit.URL == "" {
+			continue
+		}
+		out = append(out, pathFinding(hit.URL, hit.Status, hit.Length, hit.RedirectLocation))
+	}
+	return out, nil
+}
+
+// gobusterLine matches a line of gobuster dir output, for example
+// "/admin (Status: 301) [Size: 178] [--> http://example.com/admin/]".
+var gobusterLine = regexp.MustCompile(`^(\S+)\s+\(Status:\s*(\d+)\)(?:\s+\[Size:\s*(\d+)\])?(?:\s+\[-->\s*([^\]]+)\])?`)
+
+// parseGobuster reports each path in gobuster's text output, resolved
+// against the task's target URL.
+func parseGobuster(data []byte, target string) ([]Finding, error) {
+	var out []Finding
+	err := eachLine(data, func(line []byte) {
+		m := gobusterLine.FindStringSubmatch(string(line))
+		if m == nil {
+			return
+		}
+		status, _ := strconv.Atoi(m[2])
+		size, _ := strconv.Atoi(m[3])
+		out = append(out, pathFinding(resolveURL(target, m[1]), status, size, strings.TrimSpace(m[4])))
+	})
+	return out, err
+}
+
+// feroxRecord holds the fields of a feroxbuster --json line.
+type feroxRecord struct {
+	Type          string `json:"type"`
+	URL           string `json:"url"`
+	Status        int    `json:"status"`
+	ContentLength int    `json:"content_length"`
+	Wildcard      bool   `json:"wildcard"`
+	Headers       struct {
+		Location string `json:"location"`
+	} `json:"headers"`
+}
+
+// parseFeroxbuster reports each response feroxbuster kept. Statistics and
+// configuration lines and wildcard responses are skipped.
+func parseFeroxbuster(data []byte, _ string) ([]Finding, error) {
+	records, err := jsonLines[feroxRecord](data)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, rec := range records {
+		if rec.Type != "response" || rec.Wildcard || rec.URL == "" {
+			continue
+		}
+		out = append(out, pathFinding(rec.URL, rec.Status, rec.ContentLength, rec.Headers.Location))
+	}
+	return out, nil
+}
+
+func pathFinding(u string, status, length int, redirect string) Finding {
+	asset, port := hostPort(u)
+	f := Finding{Type: TypePath, Asset: asset, Port: port, URL: u, Evidence: httpEvidence(status, length)}
+	if redirect != "" {
+		f.Evidence = strings.TrimPrefix(f.Evidence+", -> "+redirect, ", ")
+	}
+	return f
+}
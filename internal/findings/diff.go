@@ -0,0 +1,189 @@
+package findings
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Change is a finding reported by both jobs of a diff whose attributes
+// differ.
+type Change struct {
+	Base Finding `json:"base"`
+	Head Finding `json:"head"`
+	// Fields names the attributes that differ: severity, service, title or
+	// evidence.
+	Fields []string `json:"fields"`
+}
+
+// Describe summarizes what changed, such as "title: Login -> Sign in".
+func (c Change) Describe() string {
+	parts := make([]string, len(c.Fields))
+	for i, field := range c.Fields {
+		before, after := c.Values(field)
+		parts[i] = fmt.Sprintf("%s: %s -> %s", field, before, after)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Values returns the base and head values of a changed attribute, with
+// "(none)" for an empty one.
+func (c Change) Values(field string) (before, after string) {
+	return orNone(attribute(c.Base, field)), orNone(attribute(c.Head, field))
+}
+
+// Diff compares the findings of two jobs of the same tool.
+type Diff struct {
+	Tool      string    `json:"tool"`
+	Base      string    `json:"base_job"`
+	Head      string    `json:"head_job"`
+	Added     []Finding `json:"added"`
+	Removed   []Finding `json:"removed"`
+	Changed   []Change  `json:"changed"`
+	Unchanged int       `json:"unchanged"`
+}
+
+// Empty reports whether the jobs reported the same findings.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Summary counts the differences, such as "2 new, 1 removed, 0 changed,
+// 5 unchanged".
+func (d Diff) Summary() string {
+	return fmt.Sprintf("%d new, %d removed, %d changed, %d unchanged", len(d.Added), len(d.Removed), len(d.Changed), d.Unchanged)
+}
+
+// Of returns the added, removed and changed findings of type t.
+func (d Diff) Of(t Type) (added, removed []Finding, changed []Change) {
+	for _, f := range d.Added {
+		if f.Type == t {
+			added = append(added, f)
+		}
+	}
+	for _, f := range d.Removed {
+		if f.Type == t {
+			removed = append(removed, f)
+		}
+	}
+	for _, c := range d.Changed {
+		if c.Head.Type == t {
+			changed = append(changed, c)
+		}
+	}
+	return added, removed, changed
+}
+
+// Brief describes a finding in one line of a diff: its check, service and
+// title, and its severity when it is a security issue.
+func Brief(f Finding) string {
+	var parts []string
+	if f.Severity != SeverityInfo && f.Severity != "" {
+		parts = append(parts, strings.ToUpper(string(f.Severity)))
+	}
+	return strings.Join(append(parts, nonEmpty(f.Check, f.Service, f.Title)...), "  ")
+}
+
+// Types returns the finding types the diff touches, in the order of the
+// type constants.
+func (d Diff) Types() []Type {
+	seen := make(map[Type]bool)
+	for _, f := range d.Added {
+		seen[f.Type] = true
+	}
+	for _, f := range d.Removed {
+		seen[f.Type] = true
+	}
+	for _, c := range d.Changed {
+		seen[c.Head.Type] = true
+	}
+	var out []Type
+	for _, t := range typeOrder {
+		if seen[t] {
+			out = append(out, t)
+			delete(seen, t)
+		}
+	}
+	var rest []Type
+	for t := range seen {
+		rest = append(rest, t)
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i] < rest[j] })
+	return append(out, rest...)
+}
+
+var typeOrder = []Type{
+	TypeSubdomain, TypeDNSRecord, TypeOpenPort, TypeHTTPService, TypeURL,
+	TypePath, TypeVulnerability, TypeScriptOutput, TypeScreenshot,
+}
+
+// diffFields are the attributes a finding can change between jobs without
+// becoming a different finding; see Finding.Key.
+var diffFields = []string{"severity", "service", "title", "evidence"}
+
+func attribute(f Finding, field string) string {
+	switch field {
+	case "severity":
+		return string(f.Severity)
+	case "service":
+		return f.Service
+	case "title":
+		return f.Title
+	case "evidence":
+		return f.Evidence
+	}
+	return ""
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// Compare diffs the findings of a base and a head job. Findings are matched
+// by Key; a finding reported more than once by a job counts once, with the
+// attributes of its first report.
+func Compare(base, head []Finding) Diff {
+	d := Diff{Added: []Finding{}, Removed: []Finding{}, Changed: []Change{}}
+	baseByKey := firstByKey(base)
+	headByKey := firstByKey(head)
+	for key, h := range headByKey {
+		b, ok := baseByKey[key]
+		if !ok {
+			d.Added = append(d.Added, h)
+			continue
+		}
+		var fields []string
+		for _, field := range diffFields {
+			if attribute(b, field) != attribute(h, field) {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) > 0 {
+			d.Changed = append(d.Changed, Change{Base: b, Head: h, Fields: fields})
+		} else {
+			d.Unchanged++
+		}
+	}
+	for key, b := range baseByKey {
+		if _, ok := headByKey[key]; !ok {
+			d.Removed = append(d.Removed, b)
+		}
+	}
+	Sort(d.Added)
+	Sort(d.Removed)
+	sort.SliceStable(d.Changed, func(i, j int) bool { return Less(d.Changed[i].Head, d.Changed[j].Head) })
+	return d
+}
+
+func firstByKey(fs []Finding) map[string]Finding {
+	out := make(map[string]Finding, len(fs))
+	for _, f := range fs {
+		if _, ok := out[f.Key()]; !ok {
+			out[f.Key()] = f
+		}
+	}
+	return out
+}
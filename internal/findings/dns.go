@@ -0,0 +1,50 @@
+package findings
+
+import (
+	"encoding/json"
+	"strings"
+
+	"heph4estus/internal/tools/dns"
+)
+
+// parseSubfinder reports each host name in subfinder's output, either
+// plain lines or JSON lines (-oJ).
+func parseSubfinder(data []byte, _ string) ([]Finding, error) {
+	var out []Finding
+	seen := make(map[string]bool)
+	err := eachLine(data, func(line []byte) {
+		host := string(line)
+		var check string
+		if line[0] == '{' {
+			var rec struct {
+				Host   string `json:"host"`
+				Source string `json:"source"`
+			}
+			if json.Unmarshal(line, &rec) != nil {
+				return
+			}
+			host, check = rec.Host, rec.Source
+		}
+		host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+		if host == "" || strings.ContainsAny(host, " /") || seen[host] {
+			return
+		}
+		seen[host] = true
+		out = append(out, Finding{Type: TypeSubdomain, Asset: host, Check: check})
+	})
+	return out, err
+}
+
+// parseDNS reports each name a dnsx or massdns report resolved, with the
+// addresses and CNAME targets it resolved to. Names that did not resolve
+// and wildcard probes are skipped.
+func parseDNS(data []byte, _ string) ([]Finding, error) {
+	var out []Finding
+	for _, a := range dns.ParseAnswers(data) {
+		if len(a.Values) == 0 || dns.IsProbe(a.Host) {
+			continue
+		}
+		out = append(out, Finding{Type: TypeDNSRecord, Asset: a.Host, Evidence: strings.Join(a.Values, ", ")})
+	}
+	return out, nil
+}
@@ -0,0 +1,370 @@
+// Package findings normalizes the reports of every module into one schema,
+// so reports, diffs and notifications need not know each tool's format.
+package findings
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"heph4estus/internal/worker"
+)
+
+// Type is the kind of thing a finding reports.
+type Type string
+
+const (
+	TypeSubdomain     Type = "subdomain"     // a discovered host name
+	TypeDNSRecord     Type = "dns"           // a name and what it resolved to
+	TypeOpenPort      Type = "port"          // an open port, with its service if known
+	TypeHTTPService   Type = "http"          // a live web server
+	TypeURL           Type = "url"           // a crawled URL
+	TypePath          Type = "path"          // a path found by content discovery
+	TypeVulnerability Type = "vulnerability" // a security issue
+	TypeScriptOutput  Type = "script"        // NSE script output
+	TypeScreenshot    Type = "screenshot"    // a captured page
+)
+
+// Label names a type for headings, such as "Open ports".
+func (t Type) Label() string {
+	switch t {
+	case TypeSubdomain:
+		return "Subdomains"
+	case TypeDNSRecord:
+		return "DNS records"
+	case TypeOpenPort:
+		return "Open ports"
+	case TypeHTTPService:
+		return "HTTP services"
+	case TypeURL:
+		return "URLs"
+	case TypePath:
+		return "Paths"
+	case TypeVulnerability:
+		return "Vulnerabilities"
+	case TypeScriptOutput:
+		return "Script output"
+	case TypeScreenshot:
+		return "Screenshots"
+	}
+	return string(t)
+}
+
+// Severity ranks a finding. Findings that are not security issues are info.
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityHigh     Severity = "high"
+	SeverityMedium   Severity = "medium"
+	SeverityLow      Severity = "low"
+	SeverityInfo     Severity = "info"
+	SeverityUnknown  Severity = "unknown"
+)
+
+// ParseSeverity normalizes a tool's severity label.
+func ParseSeverity(s string) Severity {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "critical":
+		return SeverityCritical
+	case "high":
+		return SeverityHigh
+	case "medium", "moderate":
+		return SeverityMedium
+	case "low":
+		return SeverityLow
+	case "info", "informational", "information", "none":
+		return SeverityInfo
+	default:
+		return SeverityUnknown
+	}
+}
+
+// Rank orders severities from critical (0) to unknown (5).
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityCritical:
+		return 0
+	case SeverityHigh:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 3
+	case SeverityInfo:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// Finding is one normalized result of a module.
+type Finding struct {
+	Tool   string `json:"tool"`
+	JobID  string `json:"job_id,omitempty"`
+	TaskID string `json:"task_id,omitempty"`
+
+	Type     Type     `json:"type"`
+	Severity Severity `json:"severity"`
+	// Asset is the host name or address the finding is about.
+	Asset    string `json:"asset"`
+	Port     int    `json:"port,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+	Service  string `json:"service,omitempty"`
+	URL      string `json:"url,omitempty"`
+	// Check names what produced the finding: a nuclei template, an NSE
+	// script, a DNS record type.
+	Check    string `json:"check,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// Key identifies a finding across tasks and jobs: the same check reporting
+// the same location is one finding whatever its title or evidence says.
+func (f Finding) Key() string {
+	return strings.Join([]string{
+		f.Tool, string(f.Type), strings.ToLower(f.Asset),
+		strconv.Itoa(f.Port), f.Protocol, f.URL, f.Check,
+	}, "\x00")
+}
+
+// Location is a finding's URL, else its asset and port.
+func (f Finding) Location() string {
+	if f.URL != "" {
+		return f.URL
+	}
+	if f.Port > 0 {
+		loc := fmt.Sprintf("%s:%d", f.Asset, f.Port)
+		if f.Protocol != "" && f.Protocol != "tcp" {
+			loc += "/" + f.Protocol
+		}
+		return loc
+	}
+	return f.Asset
+}
+
+// Parser reads one task's report. target is the task's target, which
+// reports of some tools need to resolve relative paths.
+type Parser func(data []byte, target string) ([]Finding, error)
+
+// ErrNoParser is returned for tools without a registered parser.
+var ErrNoParser = errors.New("no findings parser")
+
+var parsers = map[string]Parser{}
+
+// Register adds the parser for a tool, replacing any earlier one.
+func Register(tool string, p Parser) {
+	parsers[strings.ToLower(tool)] = p
+}
+
+// Has reports whether tool has a parser.
+func Has(tool string) bool {
+	_, ok := parsers[strings.ToLower(strings.TrimSpace(tool))]
+	return ok
+}
+
+// Tools returns the tools with a parser, sorted.
+func Tools() []string {
+	tools := make([]string, 0, len(parsers))
+	for tool := range parsers {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+func init() {
+	for tool, p := range map[string]Parser{
+		"nmap":        parseNmap,
+		"masscan":     parseMasscan,
+		"nuclei":      parseNuclei,
+		"dalfox":      parseDalfox,
+		"httpx":       parseHTTPX,
+		"katana":      parseKatana,
+		"gospider":    parseGospider,
+		"gowitness":   parseGowitness,
+		"ffuf":        parseFFUF,
+		"gobuster":    parseGobuster,
+		"feroxbuster": parseFeroxbuster,
+		"subfinder":   parseSubfinder,
+		"dnsx":        parseDNS,
+		"massdns":     parseDNS,
+	} {
+		Register(tool, p)
+	}
+}
+
+// Origin identifies the task a report came from.
+type Origin struct {
+	Tool   string
+	JobID  string
+	TaskID string
+	Target string
+}
+
+// OriginOf returns the origin of a task's result.
+func OriginOf(r worker.Result) Origin {
+	return Origin{Tool: r.ToolName, JobID: r.JobID, TaskID: TaskID(r), Target: r.Target}
+}
+
+// TaskID names the task a result came from: its target, prefixed with its
+// group and suffixed with its chunk when it has them.
+func TaskID(r worker.Result) string {
+	id := r.Target
+	if r.GroupID != "" && r.GroupID != r.Target {
+		id = r.GroupID + "/" + id
+	}
+	if r.TotalChunks > 0 {
+		id += fmt.Sprintf("#%d/%d", r.ChunkIdx+1, r.TotalChunks)
+	}
+	return id
+}
+
+// Parse reads a report of o's tool and stamps each finding with o.
+func Parse(o Origin, data []byte) ([]Finding, error) {
+	p, ok := parsers[strings.ToLower(strings.TrimSpace(o.Tool))]
+	if !ok {
+		return nil, fmt.Errorf("%w for %s", ErrNoParser, o.Tool)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	out, err := p(data, o.Target)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s report: %w", o.Tool, err)
+	}
+	for i := range out {
+		out[i].Tool = strings.ToLower(o.Tool)
+		out[i].JobID = o.JobID
+		out[i].TaskID = o.TaskID
+		if out[i].Severity == "" {
+			out[i].Severity = SeverityInfo
+		}
+	}
+	return out, nil
+}
+
+// Sort orders findings by severity, then asset, port, URL and check.
+func Sort(fs []Finding) {
+	sort.SliceStable(fs, func(i, j int) bool { return Less(fs[i], fs[j]) })
+}
+
+// Less reports whether a sorts before b in the order of Sort.
+func Less(a, b Finding) bool {
+	if a.Severity.Rank() != b.Severity.Rank() {
+		return a.Severity.Rank() < b.Severity.Rank()
+	}
+	if a.Asset != b.Asset {
+		return a.Asset < b.Asset
+	}
+	if a.Port != b.Port {
+		return a.Port < b.Port
+	}
+	if a.URL != b.URL {
+		return a.URL < b.URL
+	}
+	return a.Check < b.Check
+}
+
+const maxLineBytes = 16 * 1024 * 1024
+
+// eachLine calls fn with every non-empty, trimmed line of data.
+func eachLine(data []byte, fn func(line []byte)) error {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for sc.Scan() {
+		if line := bytes.TrimSpace(sc.Bytes()); len(line) > 0 {
+			fn(line)
+		}
+	}
+	return sc.Err()
+}
+
+// jsonLines decodes a JSONL report. Lines that are not JSON are skipped,
+// unless no line is, which means the report is in some other format.
+func jsonLines[T any](data []byte) ([]T, error) {
+	var (
+		records []T
+		invalid int
+		lastErr error
+	)
+	err := eachLine(data, func(line []byte) {
+		var rec T
+		if err := json.Unmarshal(line, &rec); err != nil {
+			invalid++
+			lastErr = err
+			return
+		}
+		records = append(records, rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 && invalid > 0 {
+		return nil, fmt.Errorf("no JSON records: %w", lastErr)
+	}
+	return records, nil
+}
+
+// hostPort returns the host and port of a URL, defaulting the port from
+// the scheme. A bare host is returned as is.
+func hostPort(raw string) (string, int) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		host := strings.TrimSpace(raw)
+		if h, p, err := net.SplitHostPort(host); err == nil {
+			port, _ := strconv.Atoi(p)
+			return strings.ToLower(h), port
+		}
+		return strings.ToLower(host), 0
+	}
+	port, _ := strconv.Atoi(u.Port())
+	if port == 0 {
+		switch u.Scheme {
+		case "http":
+			port = 80
+		case "https":
+			port = 443
+		}
+	}
+	return strings.ToLower(u.Hostname()), port
+}
+
+// resolveURL joins a path onto the base URL of a task's target. A FUZZ
+// keyword in the base is dropped along with anything after it.
+func resolveURL(base, path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	if i := strings.Index(base, "FUZZ"); i >= 0 {
+		base = base[:i]
+	}
+	b, err := url.Parse(base)
+	if err != nil || b.Host == "" {
+		return path
+	}
+	ref, err := url.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return path
+	}
+	if !strings.HasSuffix(b.Path, "/") {
+		b.Path += "/"
+	}
+	return b.ResolveReference(ref).String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
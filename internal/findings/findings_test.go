@@ -0,0 +1,309 @@
+package findings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"heph4estus/internal/modules"
+	"heph4estus/internal/worker"
+)
+
+func TestEveryModuleHasParser(t *testing.T) {
+	reg, err := modules.NewDefaultRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range reg.Names() {
+		if !Has(name) {
+			t.Errorf("module %s has no findings parser", name)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tool   string
+		target string
+		report string
+		want   []Finding
+	}{
+		{
+			tool: "nmap",
+			report: `<nmaprun><host><address addr="10.0.0.1" addrtype="ipv4"/><ports>` +
+				`<port protocol="tcp" portid="22"><state state="open"/><service name="ssh" product="OpenSSH" version="9.6"/><script id="ssh-hostkey" output=" 256 aa:bb "/></port>` +
+				`<port protocol="tcp" portid="23"><state state="closed"/></port></ports></host></nmaprun>`,
+			want: []Finding{
+				{Type: TypeOpenPort, Asset: "10.0.0.1", Port: 22, Protocol: "tcp", Service: "ssh", Title: "OpenSSH 9.6"},
+				{Type: TypeScriptOutput, Asset: "10.0.0.1", Port: 22, Protocol: "tcp", Service: "ssh", Check: "ssh-hostkey", Evidence: "256 aa:bb"},
+			},
+		},
+		{
+			tool:   "masscan",
+			report: `[{"ip":"192.0.2.10","ports":[{"port":443,"proto":"tcp","status":"open","reason":"syn-ack"}]},]`,
+			want:   []Finding{{Type: TypeOpenPort, Asset: "192.0.2.10", Port: 443, Protocol: "tcp", Evidence: "reason syn-ack"}},
+		},
+		{
+			tool: "nuclei",
+			report: `{"template-id":"git-config","matcher-name":"exposed","type":"http","host":"https://example.com","matched-at":"https://example.com/.git/config","port":"443","info":{"name":"Git Config","severity":"Medium"}}` + "\n" +
+				`{"template-id":"ssh-auth","type":"network","host":"example.com:22","matched-at":"example.com:22","extracted-results":["password"],"info":{"name":"SSH Auth","severity":"info"}}` + "\n",
+			want: []Finding{
+				{Type: TypeVulnerability, Severity: SeverityMedium, Asset: "example.com", Port: 443, URL: "https://example.com/.git/config", Check: "git-config:exposed", Title: "Git Config"},
+				{Type: TypeVulnerability, Severity: SeverityInfo, Asset: "example.com", Port: 22, Protocol: "network", Check: "ssh-auth", Title: "SSH Auth", Evidence: "password"},
+			},
+		},
+		{
+			tool: "dalfox",
+			report: "[\n" +
+				`{"type":"V","data":"https://example.com/?q=x","param":"q","evidence":"alert(1)","cwe":"CWE-79","severity":"High"},` + "\n" +
+				`{"type":"R","data":"https://example.com/s?p=y","param":"p","payload":"<x>"},` + "\n{}\n",
+			want: []Finding{
+				{Type: TypeVulnerability, Severity: SeverityHigh, Asset: "example.com", Port: 443, URL: "https://example.com/?q=x", Check: "CWE-79:q", Title: "Verified XSS", Evidence: "alert(1)"},
+				{Type: TypeVulnerability, Severity: SeverityMedium, Asset: "example.com", Port: 443, URL: "https://example.com/s?p=y", Check: "xss:p", Title: "Reflected payload", Evidence: "<x>"},
+			},
+		},
+		{
+			tool:   "httpx",
+			report: `{"url":"https://example.com:8443","input":"example.com","port":"8443","scheme":"https","status_code":200,"title":"Admin","webserver":"nginx","tech":["Go"],"content_length":512}` + "\n",
+			want:   []Finding{{Type: TypeHTTPService, Asset: "example.com", Port: 8443, Protocol: "tcp", Service: "https", URL: "https://example.com:8443", Title: "Admin", Evidence: "status 200, 512 bytes, nginx, Go"}},
+		},
+		{
+			tool:   "katana",
+			report: `{"request":{"method":"GET","endpoint":"https://example.com/login","tag":"a","source":"https://example.com/"},"response":{"status_code":200}}` + "\n",
+			want:   []Finding{{Type: TypeURL, Asset: "example.com", Port: 443, URL: "https://example.com/login", Check: "a", Evidence: "status 200, from https://example.com/"}},
+		},
+		{
+			tool: "gospider",
+			report: "[url] - [code-200] - https://example.com/about\n" +
+				"[subdomains] - https://api.example.com\n" +
+				"[linkfinder] - [from: https://example.com/js/app.js] - /api/v1\n" +
+				"[javascript] - not-a-url\n",
+			want: []Finding{
+				{Type: TypeURL, Asset: "example.com", Port: 443, URL: "https://example.com/about", Check: "url", Evidence: "status 200"},
+				{Type: TypeSubdomain, Asset: "api.example.com", Check: "subdomains"},
+				{Type: TypeURL, Asset: "example.com", Port: 443, URL: "https://example.com/api/v1", Check: "linkfinder", Evidence: "from https://example.com/js/app.js"},
+			},
+		},
+		{
+			tool:   "gowitness",
+			report: `{"url":"http://example.com","final_url":"https://example.com/","response_code":200,"title":"Home","file_name":"example.png"}` + "\n" + `{"url":"http://down.example.com","failed":true}` + "\n",
+			want:   []Finding{{Type: TypeScreenshot, Asset: "example.com", Port: 80, URL: "http://example.com", Title: "Home", Evidence: "status 200, example.png, -> https://example.com/"}},
+		},
+		{
+			tool:   "ffuf",
+			report: `{"results":[{"input":{"FUZZ":"admin"},"status":301,"length":10,"url":"https://example.com/admin","redirectlocation":"/admin/"}]}`,
+			want:   []Finding{{Type: TypePath, Asset: "example.com", Port: 443, URL: "https://example.com/admin", Evidence: "status 301, 10 bytes, -> /admin/"}},
+		},
+		{
+			tool:   "gobuster",
+			target: "https://example.com/app",
+			report: "/admin                (Status: 200) [Size: 178]\n/old (Status: 301) [Size: 0] [--> https://example.com/app/new]\nProgress: 100\n",
+			want: []Finding{
+				{Type: TypePath, Asset: "example.com", Port: 443, URL: "https://example.com/app/admin", Evidence: "status 200, 178 bytes"},
+				{Type: TypePath, Asset: "example.com", Port: 443, URL: "https://example.com/app/old", Evidence: "status 301, -> https://example.com/app/new"},
+			},
+		},
+		{
+			tool: "feroxbuster",
+			report: `{"type":"configuration","target_url":"https://example.com"}` + "\n" +
+				`{"type":"response","url":"https://example.com/backup","status":200,"content_length":42}` + "\n" +
+				`{"type":"response","url":"https://example.com/x","status":200,"wildcard":true}` + "\n",
+			want: []Finding{{Type: TypePath, Asset: "example.com", Port: 443, URL: "https://example.com/backup", Evidence: "status 200, 42 bytes"}},
+		},
+		{
+			tool:   "subfinder",
+			report: "www.example.com\nWWW.example.com.\n{\"host\":\"api.example.com\",\"source\":\"crtsh\"}\n",
+			want: []Finding{
+				{Type: TypeSubdomain, Asset: "www.example.com"},
+				{Type: TypeSubdomain, Asset: "api.example.com", Check: "crtsh"},
+			},
+		},
+		{
+			tool:   "dnsx",
+			report: `{"host":"www.example.com","a":["1.2.3.4"],"cname":["edge.example.net."]}` + "\n" + `{"host":"heph-wc-0011223344ff.example.com","a":["1.2.3.4"]}` + "\n",
+			want:   []Finding{{Type: TypeDNSRecord, Asset: "www.example.com", Evidence: "1.2.3.4, edge.example.net"}},
+		},
+		{
+			tool:   "massdns",
+			report: `{"name":"mail.example.com.","type":"A","status":"NOERROR","data":{"answers":[{"type":"A","data":"5.6.7.8"}]}}` + "\n" + `{"name":"nx.example.com.","status":"NXDOMAIN","data":{}}` + "\n",
+			want:   []Finding{{Type: TypeDNSRecord, Asset: "mail.example.com", Evidence: "5.6.7.8"}},
+		},
+	}
+	covered := make(map[string]bool)
+	for _, tt := range tests {
+		covered[tt.tool] = true
+		t.Run(tt.tool, func(t *testing.T) {
+			got, err := Parse(Origin{Tool: tt.tool, JobID: "job-1", TaskID: "task-1", Target: tt.target}, []byte(tt.report))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d findings, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				want.Tool, want.JobID, want.TaskID = tt.tool, "job-1", "task-1"
+				if want.Severity == "" {
+					want.Severity = SeverityInfo
+				}
+				if got[i] != want {
+					t.Errorf("finding %d:\n got  %+v\n want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+	for _, tool := range Tools() {
+		if !covered[tool] {
+			t.Errorf("no parse test for %s", tool)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(Origin{Tool: "unknown"}, []byte("x")); !errors.Is(err, ErrNoParser) {
+		t.Errorf("unknown tool error = %v", err)
+	}
+	if _, err := Parse(Origin{Tool: "httpx"}, []byte("not json\n")); err == nil || !strings.Contains(err.Error(), "parsing httpx report") {
+		t.Errorf("invalid report error = %v", err)
+	}
+	if got, err := Parse(Origin{Tool: "httpx"}, []byte("not json\n{\"url\":\"http://a\"}\n")); err != nil || len(got) != 1 {
+		t.Errorf("report with a noise line = %v, %v", got, err)
+	}
+	if got, err := Parse(Origin{Tool: "nmap"}, []byte("  \n")); err != nil || got != nil {
+		t.Errorf("empty report = %v, %v", got, err)
+	}
+}
+
+func TestTaskIDAndKey(t *testing.T) {
+	r := worker.Result{ToolName: "ffuf", JobID: "j", Target: "https://example.com/FUZZ", GroupID: "g", ChunkIdx: 1, TotalChunks: 4}
+	if got := TaskID(r); got != "g/https://example.com/FUZZ#2/4" {
+		t.Errorf("TaskID = %q", got)
+	}
+	if o := OriginOf(r); o.Tool != "ffuf" || o.JobID != "j" || o.Target != r.Target {
+		t.Errorf("origin = %+v", o)
+	}
+	a := Finding{Tool: "httpx", Type: TypeHTTPService, Asset: "Example.com", Port: 443, Title: "Old"}
+	b := Finding{Tool: "httpx", Type: TypeHTTPService, Asset: "example.com", Port: 443, Title: "New", JobID: "other"}
+	if a.Key() != b.Key() {
+		t.Error("findings differing only in title and job should share a key")
+	}
+	if b.Port = 8443; a.Key() == b.Key() {
+		t.Error("findings on different ports should not share a key")
+	}
+}
+
+func TestSortAndSeverity(t *testing.T) {
+	fs := []Finding{
+		{Asset: "b", Severity: SeverityInfo},
+		{Asset: "a", Severity: ParseSeverity("CRITICAL")},
+		{Asset: "a", Severity: ParseSeverity("bogus")},
+		{Asset: "a", Severity: SeverityInfo},
+	}
+	Sort(fs)
+	var got []string
+	for _, f := range fs {
+		got = append(got, f.Asset+":"+string(f.Severity))
+	}
+	if want := "a:critical a:info b:info a:unknown"; strings.Join(got, " ") != want {
+		t.Errorf("sorted = %v, want %s", got, want)
+	}
+}
+
+// mapSource serves results and artifacts from memory.
+type mapSource map[string][]byte
+
+func (s mapSource) ListKeys(context.Context) ([]string, error) {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (s mapSource) Download(_ context.Context, key string) ([]byte, error) {
+	if data, ok := s[key]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("not found: %s", key)
+}
+
+func (s mapSource) DownloadArtifact(ctx context.Context, key string) ([]byte, error) {
+	return s.Download(ctx, key)
+}
+
+func TestCollect(t *testing.T) {
+	result := func(r worker.Result) []byte {
+		data, _ := json.Marshal(r)
+		return data
+	}
+	src := mapSource{
+		"b_2.json":    result(worker.Result{ToolName: "httpx", JobID: "j", Target: "b", OutputKey: "art/b.jsonl"}),
+		"a_1.json":    result(worker.Result{ToolName: "httpx", JobID: "j", Target: "a", Error: "timeout"}),
+		"c_3.json":    result(worker.Result{ToolName: "httpx", JobID: "j", Target: "c", OutputKey: "art/missing.jsonl"}),
+		"art/b.jsonl": []byte(`{"url":"https://b","status_code":200}` + "\n"),
+		"notes.txt":   []byte("ignored"),
+	}
+	tasks, err := Collect(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 || tasks[0].Key != "a_1.json" || tasks[2].Key != "c_3.json" {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+	if !tasks[0].Failed() || tasks[0].Findings != nil {
+		t.Errorf("failed task = %+v", tasks[0])
+	}
+	if got := tasks[1].Findings; len(got) != 1 || got[0].Asset != "b" || got[0].JobID != "j" || got[0].TaskID != "b" {
+		t.Errorf("findings = %+v", got)
+	}
+	if tasks[2].Err == "" || tasks[2].Failed() {
+		t.Errorf("task with a missing report = %+v", tasks[2])
+	}
+}
+
+func TestCompare(t *testing.T) {
+	port := func(asset string, p int, service string) Finding {
+		return Finding{Tool: "nmap", Type: TypeOpenPort, Severity: SeverityInfo, Asset: asset, Port: p, Protocol: "tcp", Service: service}
+	}
+	web := func(url, title string) Finding {
+		return Finding{Tool: "nmap", Type: TypeHTTPService, Severity: SeverityInfo, Asset: "a", Port: 443, URL: url, Title: title}
+	}
+	base := []Finding{port("a", 22, "ssh"), port("a", 80, "http"), port("b", 25, "smtp"), web("https://a/", "Login"), port("a", 22, "ssh")}
+	head := []Finding{port("a", 22, "ssh"), port("a", 80, "http-proxy"), port("c", 8443, "https"), web("https://a/", "Sign in")}
+	for i := range head {
+		head[i].JobID = "head"
+	}
+
+	d := Compare(base, head)
+	if d.Summary() != "1 new, 1 removed, 2 changed, 1 unchanged" {
+		t.Fatalf("summary = %s", d.Summary())
+	}
+	if d.Added[0].Asset != "c" || d.Removed[0].Asset != "b" {
+		t.Errorf("added %+v removed %+v", d.Added, d.Removed)
+	}
+	if got := d.Changed[0].Describe(); got != "service: http -> http-proxy" {
+		t.Errorf("change = %s", got)
+	}
+	if got := d.Changed[1].Describe(); got != "title: Login -> Sign in" {
+		t.Errorf("change = %s", got)
+	}
+	if types := d.Types(); len(types) != 2 || types[0] != TypeOpenPort || types[1] != TypeHTTPService {
+		t.Errorf("types = %v", types)
+	}
+	if added, removed, changed := d.Of(TypeHTTPService); len(added)+len(removed) != 0 || len(changed) != 1 {
+		t.Errorf("http = %v %v %v", added, removed, changed)
+	}
+	if !Compare(base, base).Empty() {
+		t.Error("a job should not differ from itself")
+	}
+
+	vuln := Finding{Type: TypeVulnerability, Severity: SeverityHigh, Asset: "a", Check: "cve-1", Title: "Bad"}
+	if got := Brief(vuln); got != "HIGH  cve-1  Bad" {
+		t.Errorf("Brief = %q", got)
+	}
+	if got := (Finding{Asset: "a", Port: 53, Protocol: "udp"}).Location(); got != "a:53/udp" {
+		t.Errorf("Location = %q", got)
+	}
+}
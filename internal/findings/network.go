@@ -0,0 +1,110 @@
+package findings
+
+import (
+	"fmt"
+	"strings"
+
+	"heph4estus/internal/targets"
+	"heph4estus/internal/tools/nmap"
+)
+
+// parseNmap reports every open port of an nmap XML report, with its
+// service, and the output of every script run against it or its host.
+func parseNmap(data []byte, target string) ([]Finding, error) {
+	run, err := nmap.ParseRun(data)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, h := range run.Hosts {
+		asset := nmapAsset(h, target)
+		for _, p := range h.Ports {
+			if !strings.EqualFold(p.State.State, "open") {
+				continue
+			}
+			f := Finding{
+				Type:     TypeOpenPort,
+				Asset:    asset,
+				Port:     p.PortID,
+				Protocol: p.Protocol,
+			}
+			if s := p.Service; s != nil {
+				f.Service = s.Name
+				f.Title = strings.Join(nonEmpty(s.Product, s.Version, s.ExtraInfo), " ")
+			}
+			out = append(out, f)
+			for _, sc := range p.Scripts {
+				out = append(out, Finding{
+					Type:     TypeScriptOutput,
+					Asset:    asset,
+					Port:     p.PortID,
+					Protocol: p.Protocol,
+					Service:  f.Service,
+					Check:    sc.ID,
+					Evidence: strings.TrimSpace(sc.Output),
+				})
+			}
+		}
+		for _, sc := range h.HostScripts {
+			out = append(out, Finding{
+				Type:     TypeScriptOutput,
+				Asset:    asset,
+				Check:    sc.ID,
+				Evidence: strings.TrimSpace(sc.Output),
+			})
+		}
+	}
+	return out, nil
+}
+
+// nmapAsset prefers a host's IPv4 address, then any address, then the
+// task's target.
+func nmapAsset(h nmap.Host, target string) string {
+	for _, a := range h.Addresses {
+		if a.Addr != "" && (a.AddrType == "" || a.AddrType == "ipv4") {
+			return a.Addr
+		}
+	}
+	for _, a := range h.Addresses {
+		if a.Addr != "" && a.AddrType != "mac" {
+			return a.Addr
+		}
+	}
+	return target
+}
+
+// parseMasscan reports the open ports of masscan JSON output.
+func parseMasscan(data []byte, _ string) ([]Finding, error) {
+	records, err := targets.ParseMasscan(data)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, rec := range records {
+		for _, p := range rec.Ports {
+			if p.Status != "" && p.Status != "open" {
+				continue
+			}
+			proto := p.Proto
+			if proto == "" {
+				proto = "tcp"
+			}
+			f := Finding{Type: TypeOpenPort, Asset: rec.IP, Port: p.Port, Protocol: proto}
+			if p.Reason != "" {
+				f.Evidence = fmt.Sprintf("reason %s", p.Reason)
+			}
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+func nonEmpty(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+package findings
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// nucleiRecord holds the fields of a nuclei JSONL result.
+type nucleiRecord struct {
+	TemplateID       string   `json:"template-id"`
+	MatcherName      string   `json:"matcher-name"`
+	ExtractorName    string   `json:"extractor-name"`
+	Type             string   `json:"type"`
+	Host             string   `json:"host"`
+	Port             string   `json:"port"`
+	IP               string   `json:"ip"`
+	MatchedAt        string   `json:"matched-at"`
+	ExtractedResults []string `json:"extracted-results"`
+	Info             struct {
+		Name     string `json:"name"`
+		Severity string `json:"severity"`
+	} `json:"info"`
+}
+
+// parseNuclei reports each nuclei result as a vulnerability, or as info
+// for templates that only detect something.
+func parseNuclei(data []byte, _ string) ([]Finding, error) {
+	records, err := jsonLines[nucleiRecord](data)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, rec := range records {
+		if rec.TemplateID == "" {
+			continue
+		}
+		where := firstNonEmpty(rec.MatchedAt, rec.Host)
+		asset, port := hostPort(where)
+		if p, err := strconv.Atoi(rec.Port); err == nil && p > 0 {
+			port = p
+		}
+		f := Finding{
+			Type:     TypeVulnerability,
+			Severity: ParseSeverity(rec.Info.Severity),
+			Asset:    asset,
+			Port:     port,
+			Check:    rec.TemplateID,
+			Title:    rec.Info.Name,
+			Evidence: strings.Join(rec.ExtractedResults, ", "),
+		}
+		if name := firstNonEmpty(rec.MatcherName, rec.ExtractorName); name != "" {
+			f.Check += ":" + name
+		}
+		if strings.Contains(where, "://") {
+			f.URL = where
+		}
+		if rec.Type != "" && rec.Type != "http" {
+			f.Protocol = rec.Type
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// dalfoxRecord holds the fields of one dalfox JSON result.
+type dalfoxRecord struct {
+	Type       string `json:"type"`
+	InjectType string `json:"inject_type"`
+	Method     string `json:"method"`
+	Data       string `json:"data"`
+	Param      string `json:"param"`
+	Payload    string `json:"payload"`
+	Evidence   string `json:"evidence"`
+	CWE        string `json:"cwe"`
+	Severity   string `json:"severity"`
+	MessageStr string `json:"message_str"`
+}
+
+// parseDalfox reports dalfox's XSS results. Dalfox writes a JSON array,
+// which older releases leave unterminated or pad with empty objects, so
+// its elements are also read one per line.
+func parseDalfox(data []byte, _ string) ([]Finding, error) {
+	var records []dalfoxRecord
+	if err := json.Unmarshal(bytes.TrimSpace(data), &records); err != nil {
+		records = nil
+		err := eachLine(data, func(line []byte) {
+			line = bytes.TrimSuffix(bytes.TrimPrefix(line, []byte("[")), []byte("]"))
+			line = bytes.TrimSuffix(bytes.TrimSpace(line), []byte(","))
+			var rec dalfoxRecord
+			if json.Unmarshal(line, &rec) == nil {
+				records = append(records, rec)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var out []Finding
+	for _, rec := range records {
+		if rec.Data == "" {
+			continue
+		}
+		asset, port := hostPort(rec.Data)
+		f := Finding{
+			Type:     TypeVulnerability,
+			Severity: ParseSeverity(rec.Severity),
+			Asset:    asset,
+			Port:     port,
+			URL:      rec.Data,
+			Check:    firstNonEmpty(rec.CWE, "xss"),
+			Title:    dalfoxTitle(rec.Type),
+			Evidence: firstNonEmpty(rec.Evidence, rec.MessageStr, rec.Payload),
+		}
+		if rec.Param != "" {
+			f.Check += ":" + rec.Param
+		}
+		if f.Severity == SeverityUnknown {
+			f.Severity = dalfoxSeverity(rec.Type)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// dalfoxTitle names a dalfox result type: V(erified), R(eflected) or
+// G(rep).
+func dalfoxTitle(t string) string {
+	switch t {
+	case "V":
+		return "Verified XSS"
+	case "R":
+		return "Reflected payload"
+	case "G":
+		return "Grep pattern match"
+	default:
+		return "XSS"
+	}
+}
+
+func dalfoxSeverity(t string) Severity {
+	switch t {
+	case "V":
+		return SeverityHigh
+	case "R":
+		return SeverityMedium
+	default:
+		return SeverityInfo
+	}
+}
@@ -0,0 +1,192 @@
+package findings
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// httpxRecord holds the fields of an httpx JSONL result.
+type httpxRecord struct {
+	URL           string   `json:"url"`
+	Input         string   `json:"input"`
+	Port          string   `json:"port"`
+	Scheme        string   `json:"scheme"`
+	StatusCode    int      `json:"status_code"`
+	Title         string   `json:"title"`
+	Webserver     string   `json:"webserver"`
+	Tech          []string `json:"tech"`
+	ContentLength int      `json:"content_length"`
+	Failed        bool     `json:"failed"`
+}
+
+// parseHTTPX reports each live web server httpx probed.
+func parseHTTPX(data []byte, _ string) ([]Finding, error) {
+	records, err := jsonLines[httpxRecord](data)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, rec := range records {
+		where := firstNonEmpty(rec.URL, rec.Input)
+		if rec.Failed || where == "" {
+			continue
+		}
+		asset, port := hostPort(where)
+		if p, err := strconv.Atoi(rec.Port); err == nil && p > 0 {
+			port = p
+		}
+		out = append(out, Finding{
+			Type:     TypeHTTPService,
+			Asset:    asset,
+			Port:     port,
+			Protocol: "tcp",
+			Service:  rec.Scheme,
+			URL:      rec.URL,
+			Title:    rec.Title,
+			Evidence: httpEvidence(rec.StatusCode, rec.ContentLength, rec.Webserver, strings.Join(rec.Tech, ",")),
+		})
+	}
+	return out, nil
+}
+
+// katanaRecord holds the fields of a katana JSONL result. Older releases
+// put the endpoint at the top level.
+type katanaRecord struct {
+	Endpoint string `json:"endpoint"`
+	Request  struct {
+		Method   string `json:"method"`
+		Endpoint string `json:"endpoint"`
+		Tag      string `json:"tag"`
+		Source   string `json:"source"`
+	} `json:"request"`
+	Response struct {
+		StatusCode int `json:"status_code"`
+	} `json:"response"`
+}
+
+// parseKatana reports each URL katana crawled.
+func parseKatana(data []byte, _ string) ([]Finding, error) {
+	records, err := jsonLines[katanaRecord](data)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, rec := range records {
+		where := firstNonEmpty(rec.Request.Endpoint, rec.Endpoint)
+		if where == "" {
+			continue
+		}
+		asset, port := hostPort(where)
+		f := Finding{Type: TypeURL, Asset: asset, Port: port, URL: where, Check: rec.Request.Tag}
+		var ev []string
+		if rec.Response.StatusCode > 0 {
+			ev = append(ev, fmt.Sprintf("status %d", rec.Response.StatusCode))
+		}
+		if rec.Request.Source != "" {
+			ev = append(ev, "from "+rec.Request.Source)
+		}
+		f.Evidence = strings.Join(ev, ", ")
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+var gospiderStatus = regexp.MustCompile(`^\[code-(\d+)\]$`)
+
+// parseGospider reads gospider's text output, lines such as
+// "[url] - [code-200] - https://example.com/" or
+// "[linkfinder] - [from: https://example.com/app.js] - /api". Subdomains
+// are reported as such, everything else as URLs.
+func parseGospider(data []byte, _ string) ([]Finding, error) {
+	var out []Finding
+	err := eachLine(data, func(line []byte) {
+		parts := strings.Split(string(line), " - ")
+		tag := "url"
+		if strings.HasPrefix(parts[0], "[") && len(parts) > 1 {
+			tag = strings.Trim(parts[0], "[]")
+			parts = parts[1:]
+		}
+		where := strings.TrimSpace(parts[len(parts)-1])
+		var ev []string
+		for _, p := range parts[:len(parts)-1] {
+			p = strings.TrimSpace(p)
+			if m := gospiderStatus.FindStringSubmatch(p); m != nil {
+				ev = append(ev, "status "+m[1])
+			} else if from, ok := strings.CutPrefix(p, "[from: "); ok {
+				from = strings.TrimSuffix(from, "]")
+				ev = append(ev, "from "+from)
+				if b, err := url.Parse(from); err == nil {
+					if ref, err := url.Parse(where); err == nil {
+						where = b.ResolveReference(ref).String()
+					}
+				}
+			}
+		}
+		if !strings.Contains(where, "://") {
+			return
+		}
+		asset, port := hostPort(where)
+		f := Finding{Type: TypeURL, Asset: asset, Port: port, URL: where, Check: tag, Evidence: strings.Join(ev, ", ")}
+		if tag == "subdomains" {
+			f = Finding{Type: TypeSubdomain, Asset: asset, Check: tag}
+		}
+		out = append(out, f)
+	})
+	return out, err
+}
+
+// gowitnessRecord holds the fields of a gowitness JSONL result.
+type gowitnessRecord struct {
+	URL          string `json:"url"`
+	FinalURL     string `json:"final_url"`
+	ResponseCode int    `json:"response_code"`
+	Title        string `json:"title"`
+	FileName     string `json:"file_name"`
+	Failed       bool   `json:"failed"`
+}
+
+// parseGowitness reports each page gowitness captured.
+func parseGowitness(data []byte, _ string) ([]Finding, error) {
+	records, err := jsonLines[gowitnessRecord](data)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, rec := range records {
+		if rec.Failed || rec.URL == "" {
+			continue
+		}
+		asset, port := hostPort(rec.URL)
+		ev := nonEmpty(rec.FileName)
+		if rec.ResponseCode > 0 {
+			ev = append([]string{fmt.Sprintf("status %d", rec.ResponseCode)}, ev...)
+		}
+		if rec.FinalURL != "" && rec.FinalURL != rec.URL {
+			ev = append(ev, "-> "+rec.FinalURL)
+		}
+		out = append(out, Finding{
+			Type:     TypeScreenshot,
+			Asset:    asset,
+			Port:     port,
+			URL:      rec.URL,
+			Title:    rec.Title,
+			Evidence: strings.Join(ev, ", "),
+		})
+	}
+	return out, nil
+}
+
+// httpEvidence summarizes an HTTP response.
+func httpEvidence(status, length int, extra ...string) string {
+	var ev []string
+	if status > 0 {
+		ev = append(ev, fmt.Sprintf("status %d", status))
+	}
+	if length > 0 {
+		ev = append(ev, fmt.Sprintf("%d bytes", length))
+	}
+	return strings.Join(append(ev, nonEmpty(extra...)...), ", ")
+}
@@ -36,6 +36,10 @@ type HeartbeatMessage struct {
 	Cloud        string `json:"cloud"`
 	GenerationID string `json:"generation_id"`
 	Timestamp    int64  `json:"timestamp"`
+	// JobID and Rate, when set, are the job whose rate-limited task the
+	// worker is running and the share of its global rate it holds.
+	JobID string `json:"job_id,omitempty"`
+	Rate  int    `json:"rate,omitempty"`
 }
 
 // WorkerInfo holds metadata about a single worker VM.
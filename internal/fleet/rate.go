@@ -0,0 +1,177 @@
+package fleet
+
+import (
+	"sync"
+	"time"
+)
+
+// RateShare divides a job's global rate across workers. Each worker gets
+// at least 1, so a global rate below the worker count is exceeded; callers
+// should reject that combination up front.
+func RateShare(global, workers int) int {
+	if global <= 0 {
+		return 0
+	}
+	if workers <= 1 {
+		return global
+	}
+	return max(global/workers, 1)
+}
+
+// RateBalancer follows the heartbeat stream so a worker can claim its share
+// of a job's global rate. Heartbeats carry the job and rate each worker is
+// running at, so shares are divided across that job's workers only and a
+// claim never takes rate another worker still holds.
+type RateBalancer struct {
+	self          string
+	healthTimeout time.Duration
+	warmUntil     time.Time
+	now           func() time.Time
+	changed       chan struct{}
+
+	mu       sync.Mutex
+	seen     map[string]peer
+	heldJob  string
+	heldRate int
+}
+
+// peer is the last heartbeat seen from another worker.
+type peer struct {
+	at    time.Time
+	jobID string
+	rate  int
+}
+
+// NewRateBalancer returns a balancer for the worker self that considers
+// other workers live until healthTimeout has passed since their last
+// heartbeat. A zero healthTimeout means DefaultHealthTimeout.
+func NewRateBalancer(self string, healthTimeout time.Duration) *RateBalancer {
+	return newRateBalancer(self, healthTimeout, time.Now)
+}
+
+func newRateBalancer(self string, healthTimeout time.Duration, now func() time.Time) *RateBalancer {
+	if healthTimeout <= 0 {
+		healthTimeout = DefaultHealthTimeout
+	}
+	return &RateBalancer{
+		self:          self,
+		healthTimeout: healthTimeout,
+		// Every live worker heartbeats once per interval, so the count is
+		// only complete after one interval of listening.
+		warmUntil: now().Add(DefaultHeartbeatInterval),
+		now:       now,
+		changed:   make(chan struct{}, 1),
+		seen:      make(map[string]peer),
+	}
+}
+
+// Observe records another worker's heartbeat. The worker's own heartbeats
+// are ignored.
+func (b *RateBalancer) Observe(hb HeartbeatMessage) {
+	if b == nil || hb.WorkerID == "" || hb.WorkerID == b.self {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seen[hb.WorkerID] = peer{at: b.now(), jobID: hb.JobID, rate: hb.Rate}
+}
+
+// Workers returns the number of live workers that run or may pick up tasks
+// of jobID, including this one, or 0 while the balancer has not yet
+// listened for a full heartbeat interval.
+func (b *RateBalancer) Workers(jobID string) int {
+	if b == nil || b.now().Before(b.warmUntil) {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	others, _ := b.peersLocked(jobID)
+	return others + 1
+}
+
+// peersLocked counts the other live workers that run or may pick up tasks
+// of jobID, that is those on jobID or idle, and the rate those on jobID
+// hold. Workers busy with other jobs do not count. Workers whose
+// heartbeats have timed out are forgotten.
+func (b *RateBalancer) peersLocked(jobID string) (workers, held int) {
+	now := b.now()
+	for id, p := range b.seen {
+		if now.Sub(p.at) > b.healthTimeout {
+			delete(b.seen, id)
+			continue
+		}
+		switch p.jobID {
+		case jobID:
+			held += p.rate
+			fallthrough
+		case "":
+			workers++
+		}
+	}
+	return workers, held
+}
+
+// Claim returns the rate this worker may run a task of jobID at and holds
+// it until Release. The share is RateShare of global across the live
+// workers that run or may pick up the job's tasks, or at most fallback
+// while the balancer is warming up. It is capped by what the job's other
+// workers do not already hold, so the job's total never exceeds global.
+// It returns 0, holding nothing, only when the others hold all of global;
+// the caller should wait for a running task to release its share and
+// claim again.
+func (b *RateBalancer) Claim(jobID string, global, fallback int) int {
+	if b == nil || global <= 0 {
+		return fallback
+	}
+	warm := !b.now().Before(b.warmUntil)
+	b.mu.Lock()
+	others, held := b.peersLocked(jobID)
+	fair := RateShare(global, others+1)
+	if !warm && fallback > 0 {
+		fair = min(fair, fallback)
+	}
+	share := min(fair, global-held)
+	if share <= 0 {
+		b.mu.Unlock()
+		return 0
+	}
+	b.heldJob, b.heldRate = jobID, share
+	b.mu.Unlock()
+	b.notify()
+	return share
+}
+
+// Release gives up the rate held since the last Claim.
+func (b *RateBalancer) Release() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.heldJob, b.heldRate = "", 0
+	b.mu.Unlock()
+	b.notify()
+}
+
+// Held returns the job and rate this worker holds, for its heartbeats.
+func (b *RateBalancer) Held() (jobID string, rate int) {
+	if b == nil {
+		return "", 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.heldJob, b.heldRate
+}
+
+// Changed is signalled whenever a claim or release changes what this
+// worker holds, so it can announce the change without waiting for its next
+// heartbeat.
+func (b *RateBalancer) Changed() <-chan struct{} {
+	return b.changed
+}
+
+func (b *RateBalancer) notify() {
+	select {
+	case b.changed <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,143 @@
+package fleet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateShare(t *testing.T) {
+	tests := []struct {
+		global, workers, want int
+	}{
+		{0, 4, 0},
+		{1000, 0, 1000},
+		{1000, 1, 1000},
+		{1000, 4, 250},
+		{1000, 3, 333},
+		{3, 5, 1},
+	}
+	for _, tt := range tests {
+		if got := RateShare(tt.global, tt.workers); got != tt.want {
+			t.Errorf("RateShare(%d, %d) = %d, want %d", tt.global, tt.workers, got, tt.want)
+		}
+	}
+}
+
+func TestRateBalancer_RebalancesAsWorkersJoinAndLeave(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	clock := func() time.Time { return now }
+	b := newRateBalancer("self", 90*time.Second, clock)
+
+	b.Observe(HeartbeatMessage{WorkerID: "w2", JobID: "job"})
+	if got := b.Claim("job", 1000, 100); got != 100 {
+		t.Fatalf("Claim during warm-up = %d, want fallback 100", got)
+	}
+	if job, rate := b.Held(); job != "job" || rate != 100 {
+		t.Fatalf("Held = %q %d, want job 100", job, rate)
+	}
+	b.Release()
+
+	now = now.Add(DefaultHeartbeatInterval)
+	if got := b.Claim("job", 1000, 100); got != 500 {
+		t.Fatalf("Claim with 2 workers = %d, want 500", got)
+	}
+	b.Release()
+
+	// w2 still runs at 500 when w3 and w4 join, so only what is free is
+	// granted until it releases its share.
+	b.Observe(HeartbeatMessage{WorkerID: "w2", JobID: "job", Rate: 500})
+	b.Observe(HeartbeatMessage{WorkerID: "w3", JobID: "job", Rate: 250})
+	if got := b.Claim("job", 1000, 100); got != 250 {
+		t.Fatalf("Claim with 3 workers = %d, want 250", got)
+	}
+	b.Release()
+	b.Observe(HeartbeatMessage{WorkerID: "w4", JobID: "job", Rate: 250})
+	if got := b.Claim("job", 1000, 100); got != 0 {
+		t.Fatalf("Claim with nothing free = %d, want 0", got)
+	}
+	if job, rate := b.Held(); job != "" || rate != 0 {
+		t.Fatalf("Held after a failed claim = %q %d", job, rate)
+	}
+	b.Observe(HeartbeatMessage{WorkerID: "w2", JobID: "job", Rate: 250})
+	if got := b.Claim("job", 1000, 100); got != 250 {
+		t.Fatalf("Claim with 4 workers = %d, want 250", got)
+	}
+	b.Release()
+
+	// w2 and w3 stop heartbeating and age out.
+	now = now.Add(60 * time.Second)
+	b.Observe(HeartbeatMessage{WorkerID: "w4", JobID: "job"})
+	now = now.Add(40 * time.Second)
+	if got := b.Workers("job"); got != 2 {
+		t.Fatalf("Workers after two left = %d, want 2", got)
+	}
+	if got := b.Claim("job", 1000, 100); got != 500 {
+		t.Fatalf("Claim after two left = %d, want 500", got)
+	}
+}
+
+func TestRateBalancer_ConcurrentClaimersShareEvenly(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	clock := func() time.Time { return now }
+	ids := []string{"w1", "w2", "w3", "w4"}
+	balancers := make([]*RateBalancer, len(ids))
+	for i, id := range ids {
+		balancers[i] = newRateBalancer(id, 0, clock)
+	}
+	now = now.Add(DefaultHeartbeatInterval)
+	// Every worker hears the others' idle heartbeats, its own echo and a
+	// worker busy with another job.
+	for _, b := range balancers {
+		for _, id := range ids {
+			b.Observe(HeartbeatMessage{WorkerID: id})
+		}
+		b.Observe(HeartbeatMessage{WorkerID: "w5", JobID: "other", Rate: 900})
+	}
+
+	// All four claim before any of them hears the others' claims.
+	total := 0
+	for i, b := range balancers {
+		got := b.Claim("job", 1000, 100)
+		if got != 250 {
+			t.Errorf("%s Claim = %d, want 250", ids[i], got)
+		}
+		total += got
+	}
+	if total > 1000 {
+		t.Errorf("claims total %d, over the global 1000", total)
+	}
+	if got := balancers[0].Workers("job"); got != 4 {
+		t.Errorf("Workers = %d, want 4", got)
+	}
+}
+
+func TestRateBalancer_NeverExceedsGlobal(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := newRateBalancer("self", 0, func() time.Time { return now })
+	now = now.Add(DefaultHeartbeatInterval)
+
+	// Five workers share a global rate of 3: the first ones get 1 each and
+	// the rest wait instead of rounding up.
+	for _, id := range []string{"w2", "w3", "w4", "w5"} {
+		b.Observe(HeartbeatMessage{WorkerID: id, JobID: "job"})
+	}
+	b.Observe(HeartbeatMessage{WorkerID: "w2", JobID: "job", Rate: 1})
+	if got := b.Claim("job", 3, 1); got != 1 {
+		t.Fatalf("Claim with 2 free = %d, want 1", got)
+	}
+	b.Release()
+	b.Observe(HeartbeatMessage{WorkerID: "w3", JobID: "job", Rate: 1})
+	b.Observe(HeartbeatMessage{WorkerID: "w4", JobID: "job", Rate: 1})
+	if got := b.Claim("job", 3, 1); got != 0 {
+		t.Fatalf("Claim with none free = %d, want 0", got)
+	}
+}
+
+func TestRateBalancer_NilFallsBack(t *testing.T) {
+	var b *RateBalancer
+	b.Observe(HeartbeatMessage{WorkerID: "w1"})
+	if got := b.Claim("job", 1000, 125); got != 125 {
+		t.Fatalf("nil Claim = %d, want 125", got)
+	}
+	b.Release()
+}
@@ -0,0 +1,399 @@
+// Package index keeps a local database of the normalized results of past
+// jobs, so results can be searched across jobs without reading them back
+// from cloud storage. It is a single bbolt file and needs no CGO.
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"heph4estus/internal/findings"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/worker"
+)
+
+var (
+	jobsBucket  = []byte("jobs")  // job ID -> Job
+	tasksBucket = []byte("tasks") // job ID -> bucket of task key -> storedTask
+)
+
+// ErrLocked is returned when another heph process holds the index open.
+var ErrLocked = errors.New("results index is in use by another heph process")
+
+// lockTimeout bounds how long Open waits for another process to release
+// the index.
+const lockTimeout = 2 * time.Second
+
+// Job summarizes an indexed job.
+type Job struct {
+	JobID string `json:"job_id"`
+	Tool  string `json:"tool"`
+	// Source is where the results were read from: an export directory or
+	// a bucket prefix.
+	Source    string    `json:"source,omitempty"`
+	Started   time.Time `json:"started,omitempty"`  // earliest result
+	Finished  time.Time `json:"finished,omitempty"` // latest result
+	IndexedAt time.Time `json:"indexed_at"`
+	Tasks     int       `json:"tasks"`
+	Failed    int       `json:"failed"`
+	Findings  int       `json:"findings"`
+}
+
+// Record is a finding together with the task target and time it was
+// reported at.
+type Record struct {
+	findings.Finding
+	Target string    `json:"target,omitempty"`
+	Time   time.Time `json:"time,omitempty"`
+}
+
+// storedTask is the persisted form of a findings.Task.
+type storedTask struct {
+	Result   worker.Result      `json:"result"`
+	Findings []findings.Finding `json:"findings,omitempty"`
+	Err      string             `json:"err,omitempty"`
+}
+
+// DB is an open results index.
+type DB struct {
+	db *bolt.DB
+}
+
+// DefaultPath returns the index path (<config-dir>/heph4estus/index.db).
+func DefaultPath() (string, error) {
+	dir, err := operator.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "index.db"), nil
+}
+
+// OpenDefault opens the index at DefaultPath.
+func OpenDefault() (*DB, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path)
+}
+
+// Open opens or creates the index at path.
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating index dir: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: lockTimeout})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening results index %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{jobsBucket, tasksBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("initializing results index: %w", err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Close releases the index.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Put replaces everything indexed for job.JobID with tasks and returns the
+// job as stored. The tool is taken from the results when job.Tool is empty.
+func (d *DB) Put(job Job, tasks []findings.Task) (Job, error) {
+	if job.JobID == "" {
+		return job, fmt.Errorf("job ID is required")
+	}
+	job.Tasks, job.Failed, job.Findings = len(tasks), 0, 0
+	job.Started, job.Finished = time.Time{}, time.Time{}
+	job.IndexedAt = time.Now().UTC()
+	for _, t := range tasks {
+		if job.Tool == "" {
+			job.Tool = t.Result.ToolName
+		}
+		if t.Failed() {
+			job.Failed++
+		}
+		job.Findings += len(t.Findings)
+		if ts := t.Result.Timestamp; !ts.IsZero() {
+			if job.Started.IsZero() || ts.Before(job.Started) {
+				job.Started = ts
+			}
+			if ts.After(job.Finished) {
+				job.Finished = ts
+			}
+		}
+	}
+
+	meta, err := json.Marshal(job)
+	if err != nil {
+		return job, fmt.Errorf("encoding job: %w", err)
+	}
+	err = d.db.Update(func(tx *bolt.Tx) error {
+		all := tx.Bucket(tasksBucket)
+		if all.Bucket([]byte(job.JobID)) != nil {
+			if err := all.DeleteBucket([]byte(job.JobID)); err != nil {
+				return err
+			}
+		}
+		b, err := all.CreateBucket([]byte(job.JobID))
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			data, err := json.Marshal(storedTask{Result: t.Result, Findings: t.Findings, Err: t.Err})
+			if err != nil {
+				return fmt.Errorf("encoding task %s: %w", t.Key, err)
+			}
+			if err := b.Put([]byte(t.Key), data); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(jobsBucket).Put([]byte(job.JobID), meta)
+	})
+	if err != nil {
+		return job, fmt.Errorf("indexing job %s: %w", job.JobID, err)
+	}
+	return job, nil
+}
+
+// Remove drops a job from the index. It reports whether the job was
+// indexed.
+func (d *DB) Remove(jobID string) (bool, error) {
+	found := false
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		jb := tx.Bucket(jobsBucket)
+		if jb.Get([]byte(jobID)) == nil {
+			return nil
+		}
+		found = true
+		if tx.Bucket(tasksBucket).Bucket([]byte(jobID)) != nil {
+			if err := tx.Bucket(tasksBucket).DeleteBucket([]byte(jobID)); err != nil {
+				return err
+			}
+		}
+		return jb.Delete([]byte(jobID))
+	})
+	if err != nil {
+		return false, fmt.Errorf("removing job %s from index: %w", jobID, err)
+	}
+	return found, nil
+}
+
+// Job returns an indexed job and whether it is indexed.
+func (d *DB) Job(jobID string) (Job, bool, error) {
+	var job Job
+	found := false
+	err := d.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(jobsBucket).Get([]byte(jobID))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &job)
+	})
+	if err != nil {
+		return job, false, fmt.Errorf("reading indexed job %s: %w", jobID, err)
+	}
+	return job, found, nil
+}
+
+// Jobs returns every indexed job, most recent first.
+func (d *DB) Jobs() ([]Job, error) {
+	var out []Job
+	err := d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).ForEach(func(k, v []byte) error {
+			var job Job
+			if err := json.Unmarshal(v, &job); err != nil {
+				return fmt.Errorf("job %s: %w", k, err)
+			}
+			out = append(out, job)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing indexed jobs: %w", err)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := jobTime(out[i]), jobTime(out[j])
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return out[i].JobID < out[j].JobID
+	})
+	return out, nil
+}
+
+// jobTime is when a job last reported a result, else when it was indexed.
+func jobTime(j Job) time.Time {
+	if !j.Finished.IsZero() {
+		return j.Finished
+	}
+	return j.IndexedAt
+}
+
+// Tasks returns the indexed tasks of a job in key order.
+func (d *DB) Tasks(jobID string) ([]findings.Task, error) {
+	var out []findings.Task
+	err := d.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(tasksBucket).Bucket([]byte(jobID))
+		if b == nil {
+			return fmt.Errorf("job %s is not indexed", jobID)
+		}
+		return b.ForEach(func(k, v []byte) error {
+			t, err := decodeTask(k, v)
+			if err != nil {
+				return err
+			}
+			out = append(out, t)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading indexed tasks: %w", err)
+	}
+	return out, nil
+}
+
+func decodeTask(k, v []byte) (findings.Task, error) {
+	var st storedTask
+	if err := json.Unmarshal(v, &st); err != nil {
+		return findings.Task{}, fmt.Errorf("task %s: %w", k, err)
+	}
+	return findings.Task{Key: string(k), Result: st.Result, Findings: st.Findings, Err: st.Err}, nil
+}
+
+// Search returns the findings of every indexed job that match q, sorted
+// like findings.Sort and then newest first.
+func (d *DB) Search(q Query) ([]Record, error) {
+	jobs, err := d.Jobs()
+	if err != nil {
+		return nil, err
+	}
+	var out []Record
+	err = d.db.View(func(tx *bolt.Tx) error {
+		for _, job := range jobs {
+			if !q.job(job) {
+				continue
+			}
+			b := tx.Bucket(tasksBucket).Bucket([]byte(job.JobID))
+			if b == nil {
+				continue
+			}
+			err := b.ForEach(func(k, v []byte) error {
+				t, err := decodeTask(k, v)
+				if err != nil {
+					return err
+				}
+				at := t.Result.Timestamp
+				if at.IsZero() {
+					at = jobTime(job)
+				}
+				if !q.inWindow(at) {
+					return nil
+				}
+				for _, f := range t.Findings {
+					if q.finding(t.Result.Target, f) {
+						out = append(out, Record{Finding: f, Target: t.Result.Target, Time: at})
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("job %s: %w", job.JobID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching results index: %w", err)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if findings.Less(a.Finding, b.Finding) {
+			return true
+		}
+		if findings.Less(b.Finding, a.Finding) {
+			return false
+		}
+		return a.Time.After(b.Time)
+	})
+	return out, nil
+}
+
+// Query selects indexed findings. Zero fields match everything.
+type Query struct {
+	Tool  string
+	JobID string
+	// Target matches, case-insensitively, part of the task target or the
+	// finding's asset or URL.
+	Target     string
+	Port       int
+	Severities []findings.Severity
+	Types      []findings.Type
+	// Since and Until bound the time the finding's result was reported.
+	Since time.Time
+	Until time.Time
+}
+
+// job reports whether any finding of job could match q.
+func (q Query) job(j Job) bool {
+	if q.Tool != "" && !strings.EqualFold(j.Tool, q.Tool) {
+		return false
+	}
+	if q.JobID != "" && j.JobID != q.JobID {
+		return false
+	}
+	// A job whose results all fall outside the window is skipped whole.
+	if !j.Started.IsZero() {
+		if !q.Since.IsZero() && j.Finished.Before(q.Since) {
+			return false
+		}
+		if !q.Until.IsZero() && j.Started.After(q.Until) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q Query) inWindow(t time.Time) bool {
+	return (q.Since.IsZero() || !t.Before(q.Since)) && (q.Until.IsZero() || !t.After(q.Until))
+}
+
+func (q Query) finding(target string, f findings.Finding) bool {
+	if q.Target != "" {
+		sub := strings.ToLower(q.Target)
+		if !strings.Contains(strings.ToLower(target), sub) &&
+			!strings.Contains(strings.ToLower(f.Asset), sub) &&
+			!strings.Contains(strings.ToLower(f.URL), sub) {
+			return false
+		}
+	}
+	if q.Port != 0 && f.Port != q.Port {
+		return false
+	}
+	if len(q.Severities) > 0 && !slices.Contains(q.Severities, f.Severity) {
+		return false
+	}
+	return len(q.Types) == 0 || slices.Contains(q.Types, f.Type)
+}
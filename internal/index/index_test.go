@@ -0,0 +1,189 @@
+package index
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"heph4estus/internal/findings"
+	"heph4estus/internal/worker"
+)
+
+var day = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func task(tool, jobID, target string, at time.Time, fs ...findings.Finding) findings.Task {
+	for i := range fs {
+		fs[i].Tool, fs[i].JobID = tool, jobID
+		if fs[i].Severity == "" {
+			fs[i].Severity = findings.SeverityInfo
+		}
+	}
+	return findings.Task{
+		Key:      target + ".json",
+		Result:   worker.Result{ToolName: tool, JobID: jobID, Target: target, Timestamp: at},
+		Findings: fs,
+	}
+}
+
+func openTemp(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestPutSearchAcrossJobs(t *testing.T) {
+	db := openTemp(t)
+	old := []findings.Task{
+		task("nmap", "nmap-old", "10.0.0.1", day.AddDate(0, -2, 0),
+			findings.Finding{Type: findings.TypeOpenPort, Asset: "10.0.0.1", Port: 8443, Protocol: "tcp"}),
+	}
+	recent := []findings.Task{
+		task("nmap", "nmap-new", "10.0.0.2", day,
+			findings.Finding{Type: findings.TypeOpenPort, Asset: "10.0.0.2", Port: 8443, Protocol: "tcp"},
+			findings.Finding{Type: findings.TypeOpenPort, Asset: "10.0.0.2", Port: 22, Protocol: "tcp"}),
+		{Key: "10.0.0.3.json", Result: worker.Result{ToolName: "nmap", Target: "10.0.0.3", Error: "timeout", Timestamp: day}},
+	}
+	web := []findings.Task{
+		task("nuclei", "nuclei-1", "https://app.example.com", day,
+			findings.Finding{Type: findings.TypeVulnerability, Severity: findings.SeverityHigh, Asset: "app.example.com", Port: 8443, Check: "cve-1"}),
+	}
+	for _, j := range []struct {
+		id    string
+		tasks []findings.Task
+	}{{"nmap-old", old}, {"nmap-new", recent}, {"nuclei-1", web}} {
+		if _, err := db.Put(Job{JobID: j.id}, j.tasks); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job, ok, err := db.Job("nmap-new")
+	if err != nil || !ok {
+		t.Fatalf("Job = %v, %v", ok, err)
+	}
+	if job.Tool != "nmap" || job.Tasks != 2 || job.Failed != 1 || job.Findings != 2 || !job.Finished.Equal(day) {
+		t.Errorf("job = %+v", job)
+	}
+
+	got, err := db.Search(Query{Port: 8443, Since: day.AddDate(0, 0, -30)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Severity != findings.SeverityHigh || got[1].Asset != "10.0.0.2" || got[1].Target != "10.0.0.2" {
+		t.Fatalf("port 8443 this month = %+v", got)
+	}
+
+	got, err = db.Search(Query{Tool: "nmap", Port: 8443})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("nmap port 8443 = %+v", got)
+	}
+
+	got, err = db.Search(Query{Target: "APP.example", Severities: []findings.Severity{findings.SeverityHigh}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Check != "cve-1" {
+		t.Fatalf("target search = %+v", got)
+	}
+
+	jobs, err := db.Jobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 3 || jobs[2].JobID != "nmap-old" {
+		t.Errorf("jobs = %+v", jobs)
+	}
+}
+
+func TestPutReplacesAndRemove(t *testing.T) {
+	db := openTemp(t)
+	first := []findings.Task{
+		task("httpx", "j1", "a.example", day, findings.Finding{Type: findings.TypeHTTPService, Asset: "a.example", Port: 443}),
+		task("httpx", "j1", "b.example", day, findings.Finding{Type: findings.TypeHTTPService, Asset: "b.example", Port: 443}),
+	}
+	if _, err := db.Put(Job{JobID: "j1", Source: "/out/httpx/j1"}, first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(Job{JobID: "j1"}, first[:1]); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := db.Tasks("j1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].Key != "a.example.json" || len(tasks[0].Findings) != 1 {
+		t.Fatalf("tasks after reindex = %+v", tasks)
+	}
+
+	removed, err := db.Remove("j1")
+	if err != nil || !removed {
+		t.Fatalf("Remove = %v, %v", removed, err)
+	}
+	if removed, _ := db.Remove("j1"); removed {
+		t.Error("second Remove reported the job")
+	}
+	if _, ok, _ := db.Job("j1"); ok {
+		t.Error("job still indexed")
+	}
+	if _, err := db.Tasks("j1"); err == nil {
+		t.Error("Tasks of a removed job: want error")
+	}
+	if _, err := db.Put(Job{}, nil); err == nil {
+		t.Error("Put without job ID: want error")
+	}
+}
+
+func TestOpenLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	if _, err := Open(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second Open = %v, want ErrLocked", err)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	q, err := ParseQuery("port:8443 since:30d sev:high,critical type:vulnerability tool:nuclei job:j1 example.com https://x.example/a", day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Port != 8443 || q.Tool != "nuclei" || q.JobID != "j1" || len(q.Severities) != 2 || len(q.Types) != 1 {
+		t.Errorf("query = %+v", q)
+	}
+	if !q.Since.Equal(day.AddDate(0, 0, -30)) {
+		t.Errorf("since = %v", q.Since)
+	}
+	if q.Target != "example.com https://x.example/a" {
+		t.Errorf("target = %q", q.Target)
+	}
+
+	for _, bad := range []string{"port:http", "sev:urgent", "since:yesterday"} {
+		if _, err := ParseQuery(bad, day); err == nil {
+			t.Errorf("ParseQuery(%q): want error", bad)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	for in, want := range map[string]time.Time{
+		"2026-09-01":           time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		"2026-09-01T08:00:00Z": time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC),
+		"36h":                  day.Add(-36 * time.Hour),
+		"2w":                   day.AddDate(0, 0, -14),
+	} {
+		got, err := ParseTime(in, day)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package index
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"heph4estus/internal/findings"
+)
+
+// ParseQuery reads a search such as "port:8443 since:30d example.com".
+// Terms are tool:, job:, port:, severity: (or sev:), type:, since: and
+// until:; severity and type take comma-separated lists. Any other word is
+// matched against targets, assets and URLs.
+func ParseQuery(s string, now time.Time) (Query, error) {
+	var q Query
+	var words []string
+	for _, term := range strings.Fields(s) {
+		name, value, ok := strings.Cut(term, ":")
+		if !ok || value == "" || strings.Contains(value, "//") {
+			words = append(words, term)
+			continue
+		}
+		switch strings.ToLower(name) {
+		case "tool":
+			q.Tool = value
+		case "job":
+			q.JobID = value
+		case "port":
+			p, err := strconv.Atoi(value)
+			if err != nil || p < 1 || p > 65535 {
+				return q, fmt.Errorf("port: %q is not a port", value)
+			}
+			q.Port = p
+		case "severity", "sev":
+			for _, v := range strings.Split(value, ",") {
+				sev := findings.ParseSeverity(v)
+				if sev == findings.SeverityUnknown && !strings.EqualFold(v, string(findings.SeverityUnknown)) {
+					return q, fmt.Errorf("severity: unknown severity %q", v)
+				}
+				q.Severities = append(q.Severities, sev)
+			}
+		case "type":
+			for _, v := range strings.Split(value, ",") {
+				q.Types = append(q.Types, findings.Type(strings.ToLower(v)))
+			}
+		case "since":
+			t, err := ParseTime(value, now)
+			if err != nil {
+				return q, fmt.Errorf("since: %w", err)
+			}
+			q.Since = t
+		case "until":
+			t, err := ParseTime(value, now)
+			if err != nil {
+				return q, fmt.Errorf("until: %w", err)
+			}
+			q.Until = t
+		default:
+			words = append(words, term)
+		}
+	}
+	q.Target = strings.Join(words, " ")
+	return q, nil
+}
+
+// ParseTime reads an absolute date (2026-10-01 or RFC 3339) or an age
+// before now such as 36h, 30d or 2w.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return t, nil
+	}
+	if n := len(s); n > 1 {
+		unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[n-1]]
+		if count, err := strconv.Atoi(s[:n-1]); err == nil && unit > 0 && count >= 0 {
+			return now.Add(-time.Duration(count) * unit), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (2006-01-02) or an age (24h, 30d, 2w)", s)
+}
@@ -21,6 +21,9 @@ const (
 	StatusMismatch
 	// StatusError means Terraform probing failed due to a real error.
 	StatusError
+	// StatusStopped means a provider-native fleet was scaled to zero
+	// workers, such as by a budget breach.
+	StatusStopped
 )
 
 func (s InfraStatus) String() string {
@@ -35,6 +38,8 @@ func (s InfraStatus) String() string {
 		return "mismatch"
 	case StatusError:
 		return "error"
+	case StatusStopped:
+		return "stopped"
 	default:
 		return "unknown"
 	}
@@ -109,6 +114,14 @@ func Probe(ctx context.Context, tf *TerraformClient, kind cloud.Kind, terraformD
 		}
 	}
 
+	if kind.IsProviderNative() && outputs["worker_count"] == "0" {
+		return ProbeResult{
+			Status:       StatusStopped,
+			Outputs:      outputs,
+			DeployedTool: deployedTool,
+		}
+	}
+
 	return ProbeResult{
 		Status:       StatusReady,
 		Outputs:      outputs,
@@ -158,6 +171,7 @@ const (
 	ReasonToolMismatch                    // different tool deployed
 	ReasonProbeError                      // terraform failed
 	ReasonBlockedByPolicy                 // --no-deploy prevents action
+	ReasonFleetStopped                    // fleet scaled to zero workers
 )
 
 func (r Reason) String() string {
@@ -174,6 +188,8 @@ func (r Reason) String() string {
 		return "failed to probe infrastructure state"
 	case ReasonBlockedByPolicy:
 		return "deploy blocked by --no-deploy flag"
+	case ReasonFleetStopped:
+		return "fleet has no workers"
 	default:
 		return "unknown reason"
 	}
@@ -246,6 +262,22 @@ func Decide(probe ProbeResult, policy LifecyclePolicy) LifecycleResult {
 			Message:  fmt.Sprintf("redeploying infrastructure (currently deployed for %q)", probe.DeployedTool),
 		}
 
+	case StatusStopped:
+		if policy.NoDeploy {
+			return LifecycleResult{
+				Decision: DecisionBlock,
+				Reason:   ReasonBlockedByPolicy,
+				Probe:    probe,
+				Message:  "the fleet was scaled to zero workers and --no-deploy is set",
+			}
+		}
+		return LifecycleResult{
+			Decision: DecisionDeploy,
+			Reason:   ReasonFleetStopped,
+			Probe:    probe,
+			Message:  "scaling the fleet back up (it was scaled to zero workers)",
+		}
+
 	case StatusError:
 		return LifecycleResult{
 			Decision: DecisionBlock,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"heph4estus/internal/cloud"
@@ -521,6 +522,27 @@ func TestProbe_CloudMatch_HetznerToHetzner(t *testing.T) {
 	}
 }
 
+func TestProbe_HetznerScaledToZeroRedeploys(t *testing.T) {
+	// A fleet scaled to zero workers after a budget breach must be scaled
+	// back up rather than reused.
+	outputs := strings.Replace(hetznerOutputs("nmap"), `"worker_count":{"value":"3"}`, `"worker_count":{"value":"0"}`, 1)
+	tc := &TerraformClient{
+		runCmd: newMockExecutor(outputs, "", 0, nil),
+		logger: nopLogger{},
+	}
+
+	result := Probe(context.Background(), tc, cloud.KindHetzner, "/work", "nmap")
+	if result.Status != StatusStopped {
+		t.Fatalf("expected StatusStopped, got %s", result.Status)
+	}
+	if d := Decide(result, LifecyclePolicy{}); d.Decision != DecisionDeploy || d.Reason != ReasonFleetStopped {
+		t.Fatalf("Decide = %s (%s), want deploy", d.Decision, d.Reason)
+	}
+	if d := Decide(result, LifecyclePolicy{NoDeploy: true}); d.Decision != DecisionBlock {
+		t.Fatalf("Decide with --no-deploy = %s, want block", d.Decision)
+	}
+}
+
 func TestProbe_CloudMismatch_IgnoredForAWS(t *testing.T) {
 	// AWS probe should not check cloud output — it's only relevant for provider-native.
 	outputJSON := `{
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
 
 	"heph4estus/internal/cloud"
 	"heph4estus/internal/logger"
@@ -159,6 +160,23 @@ func RunDestroy(ctx context.Context, cfg *ToolConfig, stream io.Writer, log logg
 	return nil
 }
 
+// RunScaleWorkers re-applies a provider-native deployment with workers
+// worker VMs, keeping the controller and storage. Zero stops the fleet.
+func RunScaleWorkers(ctx context.Context, cfg *ToolConfig, workers int, stream io.Writer, log logger.Logger) error {
+	vars := make(map[string]string, len(cfg.TerraformVars)+1)
+	for k, v := range cfg.TerraformVars {
+		vars[k] = v
+	}
+	vars["worker_count"] = strconv.Itoa(workers)
+	if err := ValidateProviderNativeTerraformVars(cfg.Cloud, vars); err != nil {
+		return err
+	}
+	if err := writeLine(stream, fmt.Sprintf("==> Scaling workers to %d", workers)); err != nil {
+		return err
+	}
+	return NewTerraformClient(log).Apply(ctx, cfg.TerraformDir, vars, stream)
+}
+
 // EnsureResult holds the outputs and lifecycle metadata from EnsureInfra.
 type EnsureResult struct {
 	Outputs map[string]string
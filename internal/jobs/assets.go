@@ -0,0 +1,141 @@
+package jobs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxAssetBundleSize caps the packed size of an asset bundle.
+const MaxAssetBundleSize = 64 << 20
+
+// AssetBundle is a local directory shipped to every task of a job, such as
+// private nuclei templates or NSE scripts. Name is one of the bundle names
+// the module accepts.
+type AssetBundle struct {
+	Name string `json:"name"`
+	Dir  string `json:"dir"`
+}
+
+// AssetPlan is an asset bundle packed for upload.
+type AssetPlan struct {
+	Bundle AssetBundle
+	Key    string
+	Data   []byte
+	Files  int
+	SHA256 string
+}
+
+// AssetKey returns the storage key of a job's packed asset bundle.
+func AssetKey(toolName, jobID, name string) string {
+	return path.Join(InputPrefix(toolName, jobID), "assets_"+sanitizeSegment(name, "assets")+".tar.gz")
+}
+
+// PlanAssets packs the bundle's directory for the job.
+func PlanAssets(toolName, jobID string, bundle AssetBundle) (*AssetPlan, error) {
+	data, files, err := PackAssets(bundle.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("packing %s assets: %w", bundle.Name, err)
+	}
+	sum := sha256.Sum256(data)
+	return &AssetPlan{
+		Bundle: bundle,
+		Key:    AssetKey(toolName, jobID, bundle.Name),
+		Data:   data,
+		Files:  files,
+		SHA256: hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// PackAssets packs the regular files under dir into a gzipped tar and
+// returns it with the file count. Hidden entries are skipped, and paths,
+// modes and timestamps are normalized so the same tree always packs to the
+// same bytes.
+func PackAssets(dir string) ([]byte, int, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !info.IsDir() {
+		return nil, 0, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	files := 0
+	// WalkDir visits entries in lexical order, which keeps the output stable.
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{Name: filepath.ToSlash(rel), ModTime: time.Unix(0, 0), Format: tar.FormatPAX}
+		switch {
+		case d.IsDir():
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+			hdr.Mode = 0o755
+			return tw.WriteHeader(hdr)
+		case d.Type().IsRegular():
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			hdr.Typeflag = tar.TypeReg
+			hdr.Mode = 0o644
+			hdr.Size = int64(len(data))
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := tw.Write(data); err != nil {
+				return err
+			}
+			files++
+			if buf.Len() > MaxAssetBundleSize {
+				return fmt.Errorf("bundle exceeds %d bytes", MaxAssetBundleSize)
+			}
+			return nil
+		default:
+			// Symlinks and special files are not shipped.
+			return nil
+		}
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if files == 0 {
+		return nil, 0, fmt.Errorf("no files found in %s", dir)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, 0, err
+	}
+	if buf.Len() > MaxAssetBundleSize {
+		return nil, 0, fmt.Errorf("bundle exceeds %d bytes", MaxAssetBundleSize)
+	}
+	return buf.Bytes(), files, nil
+}
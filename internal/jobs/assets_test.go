@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"heph4estus/internal/worker"
+)
+
+func TestPackAssets(t *testing.T) {
+	dir := t.TempDir()
+	for rel, body := range map[string]string{
+		"http/a.yaml":  "id: a\n",
+		"dns/b.yaml":   "id: b\n",
+		".git/config":  "ignored",
+		".hidden.yaml": "ignored",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, files, err := PackAssets(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files != 2 {
+		t.Fatalf("files = %d, want 2", files)
+	}
+	// Touching a file must not change the packed bytes.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "http", "a.yaml"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := PackAssets(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, again) {
+		t.Error("packing is not deterministic")
+	}
+
+	out := t.TempDir()
+	if err := worker.ExtractAssets(first, out); err != nil {
+		t.Fatalf("ExtractAssets: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(out, "dns", "b.yaml")); err != nil || string(data) != "id: b\n" {
+		t.Errorf("extracted dns/b.yaml = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(out, ".git")); !os.IsNotExist(err) {
+		t.Error("hidden directory was packed")
+	}
+
+	if _, _, err := PackAssets(t.TempDir()); err == nil {
+		t.Error("expected an error for an empty directory")
+	}
+}
+
+func TestAssetKey(t *testing.T) {
+	if got, want := AssetKey("nuclei", "job-1", "templates"), "scans/nuclei/job-1/inputs/assets_templates.tar.gz"; got != want {
+		t.Fatalf("AssetKey() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,252 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"heph4estus/internal/cloud"
+	wordlisttool "heph4estus/internal/tools/wordlist"
+	"heph4estus/internal/worker"
+)
+
+// ChunkCachePrefix is the shared storage prefix for cached wordlist chunks.
+// Each cached split lives under <prefix><digest>/ with its manifest.
+const ChunkCachePrefix = "cache/wordlists/"
+
+// chunkCacheVersion is bumped whenever the split layout changes, so old
+// cache entries are never reused for a different layout.
+const chunkCacheVersion = "v1"
+
+// ChunkManifest describes one cached wordlist split. It is uploaded after
+// every chunk, so its presence means the whole split is usable.
+type ChunkManifest struct {
+	Digest           string                      `json:"digest"`
+	SourceSHA256     string                      `json:"source_sha256"`
+	SourceName       string                      `json:"source_name"`
+	TotalWords       int                         `json:"total_words"`
+	TotalSourceBytes int64                       `json:"total_source_bytes"`
+	TargetChunkSize  int64                       `json:"target_chunk_size"`
+	MaxChunkSize     int64                       `json:"max_chunk_size"`
+	Transform        *wordlisttool.Transform     `json:"transform,omitempty"`
+	TransformStats   wordlisttool.TransformStats `json:"transform_stats"`
+	Chunks           []CachedChunk               `json:"chunks"`
+	CreatedAt        time.Time                   `json:"created_at"`
+	LastUsedAt       time.Time                   `json:"last_used_at"`
+}
+
+// CachedChunk is one chunk of a cached split.
+type CachedChunk struct {
+	Key       string `json:"key"`
+	ByteSize  int64  `json:"byte_size"`
+	WordCount int    `json:"word_count"`
+}
+
+// ByteSize returns the total size of the cached chunks.
+func (m *ChunkManifest) ByteSize() int64 {
+	var n int64
+	for _, c := range m.Chunks {
+		n += c.ByteSize
+	}
+	return n
+}
+
+// ChunkCacheDigest identifies a split by everything that shapes it: the
+// source file hash, the requested chunk count (or the worker count when
+// chunks are auto-sized) and the transform.
+func ChunkCacheDigest(sourceSHA256 string, chunkCount, workerCount int, transform *wordlisttool.Transform) string {
+	if chunkCount > 0 {
+		workerCount = 0
+	}
+	rewrite := "none"
+	if !transform.IsZero() {
+		b, _ := json.Marshal(transform)
+		rewrite = string(b)
+	}
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\n%s\nchunks=%d\nworkers=%d\ntarget=%d\nmax=%d\ntransform=%s\n",
+		chunkCacheVersion, sourceSHA256, chunkCount, workerCount,
+		wordlisttool.DefaultTargetChunkSize, wordlisttool.MaxSafeChunkSize, rewrite)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// ChunkCacheKey returns the storage key of one cached chunk.
+func ChunkCacheKey(digest string, chunkIdx int) string {
+	return path.Join(ChunkCachePrefix, digest, fmt.Sprintf("chunk_%d.txt", chunkIdx))
+}
+
+// ChunkManifestKey returns the storage key of a cached split's manifest.
+func ChunkManifestKey(digest string) string {
+	return path.Join(ChunkCachePrefix, digest, "manifest.json")
+}
+
+// LoadChunkManifest returns the manifest for digest, or nil when the split
+// is not cached.
+func LoadChunkManifest(ctx context.Context, storage cloud.Storage, bucket, digest string) (*ChunkManifest, error) {
+	key := ChunkManifestKey(digest)
+	keys, err := storage.List(ctx, bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("checking chunk cache: %w", err)
+	}
+	if !slices.Contains(keys, key) {
+		return nil, nil
+	}
+	return downloadChunkManifest(ctx, storage, bucket, key)
+}
+
+func downloadChunkManifest(ctx context.Context, storage cloud.Storage, bucket, key string) (*ChunkManifest, error) {
+	data, err := storage.Download(ctx, bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("reading chunk manifest %s: %w", key, err)
+	}
+	var m ChunkManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing chunk manifest %s: %w", key, err)
+	}
+	if len(m.Chunks) == 0 {
+		return nil, fmt.Errorf("chunk manifest %s lists no chunks", key)
+	}
+	return &m, nil
+}
+
+// ListChunkManifests returns every cached split in bucket, oldest use first.
+// Unreadable manifests are skipped and their keys returned separately.
+func ListChunkManifests(ctx context.Context, storage cloud.Storage, bucket string) ([]*ChunkManifest, []string, error) {
+	keys, err := storage.List(ctx, bucket, ChunkCachePrefix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing chunk cache: %w", err)
+	}
+	var (
+		manifests []*ChunkManifest
+		broken    []string
+	)
+	for _, key := range keys {
+		if path.Base(key) != "manifest.json" {
+			continue
+		}
+		m, err := downloadChunkManifest(ctx, storage, bucket, key)
+		if err != nil {
+			broken = append(broken, key)
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	slices.SortFunc(manifests, func(a, b *ChunkManifest) int {
+		return a.LastUsedAt.Compare(b.LastUsedAt)
+	})
+	return manifests, broken, nil
+}
+
+// DeleteCachedSplit removes a cached split: the manifest first, so no job
+// reuses a half-deleted split, then every object under its prefix.
+func DeleteCachedSplit(ctx context.Context, storage cloud.Storage, bucket, digest string) (int, error) {
+	if digest == "" || strings.Contains(digest, "/") {
+		return 0, fmt.Errorf("invalid chunk cache digest %q", digest)
+	}
+	manifest := ChunkManifestKey(digest)
+	if err := cloud.DeleteObject(ctx, storage, bucket, manifest); err != nil {
+		return 0, fmt.Errorf("deleting %s: %w", manifest, err)
+	}
+	keys, err := storage.List(ctx, bucket, path.Join(ChunkCachePrefix, digest)+"/")
+	if err != nil {
+		return 1, fmt.Errorf("listing cached chunks: %w", err)
+	}
+	deleted := 1
+	for _, key := range keys {
+		if key == manifest {
+			continue
+		}
+		if err := cloud.DeleteObject(ctx, storage, bucket, key); err != nil {
+			return deleted, fmt.Errorf("deleting %s: %w", key, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// PlanCachedWordlistFile splits a wordlist into chunks keyed under the chunk
+// cache instead of the job's input prefix. Uploading the plan also uploads
+// the manifest, which lets later jobs reuse the chunks.
+func PlanCachedWordlistFile(toolName, jobID, runtimeTarget, options, wordlistPath, tempDir string, chunkCount, workerCount int, transform *wordlisttool.Transform, sourceSHA256 string) (*WordlistPlan, error) {
+	digest := ChunkCacheDigest(sourceSHA256, chunkCount, workerCount, transform)
+	policy := wordlisttool.Policy{RequestedChunks: chunkCount, WorkerCount: workerCount, Transform: transform}
+	plan, err := planWordlistFile(toolName, jobID, runtimeTarget, options, wordlistPath, tempDir, policy, func(i int) string {
+		return ChunkCacheKey(digest, i)
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	m := &ChunkManifest{
+		Digest:           digest,
+		SourceSHA256:     sourceSHA256,
+		SourceName:       path.Base(strings.ReplaceAll(wordlistPath, "\\", "/")),
+		TotalWords:       plan.TotalWords,
+		TotalSourceBytes: plan.TotalSourceBytes,
+		TargetChunkSize:  plan.TargetChunkSize,
+		MaxChunkSize:     plan.MaxChunkSize,
+		Transform:        transform,
+		TransformStats:   plan.TransformStats,
+		Chunks:           make([]CachedChunk, len(plan.ChunkFiles)),
+		CreatedAt:        now,
+		LastUsedAt:       now,
+	}
+	for i, chunk := range plan.ChunkFiles {
+		m.Chunks[i] = CachedChunk{Key: chunk.Key, ByteSize: chunk.ByteSize, WordCount: chunk.WordCount}
+	}
+	plan.Manifest = m
+	return plan, nil
+}
+
+// PlanFromManifest prepares tasks over an already cached split. The plan
+// has no chunk files; uploading it only refreshes the manifest's last use.
+func PlanFromManifest(toolName, jobID, runtimeTarget, options string, m *ChunkManifest) *WordlistPlan {
+	groupID := SafeTargetStem(runtimeTarget)
+	total := len(m.Chunks)
+	plan := &WordlistPlan{
+		Tasks:            make([]worker.Task, total),
+		ChunkKeys:        make([]string, total),
+		TotalWords:       m.TotalWords,
+		TotalSourceBytes: m.TotalSourceBytes,
+		EffectiveChunks:  total,
+		TargetChunkSize:  m.TargetChunkSize,
+		MaxChunkSize:     m.MaxChunkSize,
+		Transform:        m.Transform,
+		TransformStats:   m.TransformStats,
+		Manifest:         m,
+		Cached:           true,
+	}
+	for i, chunk := range m.Chunks {
+		plan.ChunkKeys[i] = chunk.Key
+		plan.Tasks[i] = worker.Task{
+			ToolName:    toolName,
+			JobID:       jobID,
+			Target:      runtimeTarget,
+			InputKey:    chunk.Key,
+			Options:     options,
+			GroupID:     groupID,
+			ChunkIdx:    i,
+			TotalChunks: total,
+		}
+	}
+	return plan
+}
+
+// uploadManifest writes the plan's manifest, stamping its last use.
+func uploadManifest(ctx context.Context, storage cloud.Storage, bucket string, m *ChunkManifest) error {
+	m.LastUsedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding chunk manifest: %w", err)
+	}
+	if err := storage.Upload(ctx, bucket, ChunkManifestKey(m.Digest), data); err != nil {
+		return fmt.Errorf("uploading chunk manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"heph4estus/internal/cloud/local"
+	"heph4estus/internal/logger"
+	wordlisttool "heph4estus/internal/tools/wordlist"
+)
+
+func TestChunkCacheDigest(t *testing.T) {
+	base := ChunkCacheDigest("abc", 4, 10, nil)
+	if len(base) != 32 {
+		t.Fatalf("digest length = %d", len(base))
+	}
+	if ChunkCacheDigest("abc", 4, 3, nil) != base {
+		t.Error("worker count changed the digest of an explicit chunk count")
+	}
+	if ChunkCacheDigest("abc", 0, 10, nil) == ChunkCacheDigest("abc", 0, 3, nil) {
+		t.Error("worker count must matter when chunks are auto-sized")
+	}
+	if ChunkCacheDigest("abc", 4, 10, &wordlisttool.Transform{}) != base {
+		t.Error("an empty transform changed the digest")
+	}
+	if ChunkCacheDigest("abc", 4, 10, &wordlisttool.Transform{Dedupe: true}) == base {
+		t.Error("transform did not change the digest")
+	}
+	if ChunkCacheDigest("abd", 4, 10, nil) == base {
+		t.Error("source hash did not change the digest")
+	}
+}
+
+func TestChunkCacheRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	storage, err := local.NewStorage(t.TempDir(), logger.NewSimpleLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("a\nb\nc\nd\ne\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := PlanCachedWordlistFile("ffuf", "job-1", "https://x/FUZZ", "", path, t.TempDir(), 2, 1, nil, "sum")
+	if err != nil {
+		t.Fatalf("PlanCachedWordlistFile: %v", err)
+	}
+	defer cleanupWordlistPlan(t, plan)
+	digest := plan.Manifest.Digest
+	for _, task := range plan.Tasks {
+		if !strings.HasPrefix(task.InputKey, ChunkCachePrefix+digest+"/") {
+			t.Fatalf("task input key %q is not under the cache prefix", task.InputKey)
+		}
+	}
+	if m, err := LoadChunkManifest(ctx, storage, "bucket", digest); err != nil || m != nil {
+		t.Fatalf("manifest before upload = %v, %v", m, err)
+	}
+	if err := UploadChunks(ctx, storage, "bucket", plan); err != nil {
+		t.Fatalf("UploadChunks: %v", err)
+	}
+
+	m, err := LoadChunkManifest(ctx, storage, "bucket", digest)
+	if err != nil || m == nil {
+		t.Fatalf("LoadChunkManifest = %v, %v", m, err)
+	}
+	if m.TotalWords != 5 || len(m.Chunks) != 2 || m.SourceName != "words.txt" {
+		t.Fatalf("manifest = %+v", m)
+	}
+
+	reused := PlanFromManifest("ffuf", "job-2", "https://y/FUZZ", "-ac", m)
+	if !reused.Cached || len(reused.Tasks) != 2 || reused.TotalWords != 5 {
+		t.Fatalf("reused plan = %+v", reused)
+	}
+	if got := reused.Tasks[1]; got.JobID != "job-2" || got.InputKey != plan.Tasks[1].InputKey || got.TotalChunks != 2 || got.Options != "-ac" {
+		t.Errorf("reused task = %+v", got)
+	}
+
+	manifests, broken, err := ListChunkManifests(ctx, storage, "bucket")
+	if err != nil || len(manifests) != 1 || len(broken) != 0 {
+		t.Fatalf("ListChunkManifests = %d, %v, %v", len(manifests), broken, err)
+	}
+	n, err := DeleteCachedSplit(ctx, storage, "bucket", digest)
+	if err != nil || n != 3 {
+		t.Fatalf("DeleteCachedSplit = %d, %v", n, err)
+	}
+	if keys, _ := storage.List(ctx, "bucket", ChunkCachePrefix); len(keys) != 0 {
+		t.Errorf("objects left after delete: %v", keys)
+	}
+}
@@ -81,6 +81,12 @@ func sanitizeSegment(segment, fallback string) string {
 	return segment
 }
 
+// CancelKey returns the S3 key of the marker that tells workers to drop the
+// job's remaining tasks.
+func CancelKey(toolName, jobID string) string {
+	return path.Join("scans", sanitizeSegment(toolName, "tool"), normalizeJobID(jobID), "cancelled")
+}
+
 // InputPrefix returns the S3 key prefix for uploaded wordlist chunks.
 func InputPrefix(toolName, jobID string) string {
 	return path.Join("scans", sanitizeSegment(toolName, "tool"), normalizeJobID(jobID), "inputs") + "/"
@@ -3,6 +3,12 @@ package jobs
 import (
 	"context"
 	"time"
+
+	"heph4estus/internal/scope"
+	"heph4estus/internal/targets"
+	"heph4estus/internal/tools/nuclei"
+	wordlisttool "heph4estus/internal/tools/wordlist"
+	"heph4estus/internal/worker"
 )
 
 // State represents the lifecycle state of a job.
@@ -20,8 +26,54 @@ const (
 type JobConfig struct {
 	ToolName string
 	Targets  []byte
+	// Entries, when set, are planned instead of Targets. Each entry's
+	// options are appended to Options and its metadata rides on the task.
+	Entries  []targets.Entry
 	Options  string
 	Metadata map[string]string // tool-specific params
+
+	// Tasks, when set, are enqueued as-is instead of being planned from
+	// Targets. Tools with their own planning (e.g. nmap port splitting)
+	// hand over pre-built tasks this way.
+	Tasks []worker.Task
+
+	// TemplateShards, when set, splits a nuclei template set: every target
+	// task runs once per shard with the shard's template filter appended.
+	TemplateShards *nuclei.ShardPlan
+
+	// WildcardProbe adds probe tasks for each zone of the targets, which
+	// resolve random names to detect wildcard DNS. The answers they find
+	// are filtered from merged results.
+	WildcardProbe bool
+
+	// Assets, when set, is a local directory packed, uploaded once and
+	// extracted by every task for the module's {{assets}} placeholder.
+	Assets *AssetBundle
+
+	// Wordlist modules: the local wordlist to chunk, the single runtime
+	// target, and the requested chunk count (zero auto-sizes).
+	WordlistPath  string
+	RuntimeTarget string
+	ChunkCount    int
+	// WordlistTransform, when set, dedupes, filters and expands wordlist
+	// entries while they are split into chunks.
+	WordlistTransform *wordlisttool.Transform
+	// TaskDuration, when set, sizes chunks adaptively: a calibration slice
+	// runs first and the rest is split so each task runs about this long.
+	// It replaces ChunkCount.
+	TaskDuration time.Duration
+	// Wordlists maps the module's other named wordlists to local files.
+	// Each is uploaded once and shipped whole to every task.
+	Wordlists map[string]string
+
+	// Scope, when set, rejects out-of-scope targets at planning time and is
+	// embedded in every task for worker-side re-checks.
+	Scope *scope.Scope
+
+	// TargetOptions, when set, normalizes Targets or Entries before
+	// planning: parsed, deduplicated, split or expanded, and converted to
+	// accepted kinds.
+	TargetOptions *targets.Options
 }
 
 // JobStatus reports the current state of a submitted job.
@@ -36,8 +88,7 @@ type JobStatus struct {
 }
 
 // Runner is the interface that both the TUI and CLI use to submit and
-// monitor jobs. Concrete implementations (e.g. AWS Step Functions) will
-// be added in Phase 3.
+// monitor jobs. The cloud-agnostic implementation lives in internal/runner.
 type Runner interface {
 	Submit(ctx context.Context, cfg JobConfig) (string, error)
 	Status(ctx context.Context, jobID string) (*JobStatus, error)
@@ -0,0 +1,17 @@
+package jobs
+
+import "strings"
+
+// ParseTargetLines splits target file content into non-empty, non-comment
+// lines with surrounding whitespace trimmed.
+func ParseTargetLines(content string) []string {
+	var targets []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		targets = append(targets, line)
+	}
+	return targets
+}
@@ -0,0 +1,13 @@
+package jobs
+
+import "testing"
+
+func TestParseTargetLines(t *testing.T) {
+	got := ParseTargetLines("  a.example.com \n# comment\n\r\nb.example.com\r\n\n")
+	if len(got) != 2 || got[0] != "a.example.com" || got[1] != "b.example.com" {
+		t.Errorf("ParseTargetLines = %q", got)
+	}
+	if got := ParseTargetLines(""); len(got) != 0 {
+		t.Errorf("empty content = %q", got)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
+	"slices"
 	"strings"
 
 	"heph4estus/internal/cloud"
@@ -59,6 +61,19 @@ type WordlistPlan struct {
 	RequestedChunks  int
 	TargetChunkSize  int64
 	MaxChunkSize     int64
+	// Transform is the rewrite applied during the split, and TransformStats
+	// what it removed and added.
+	Transform      *wordlisttool.Transform
+	TransformStats wordlisttool.TransformStats
+
+	// Manifest, when set, is uploaded after the chunks so later jobs can
+	// reuse them. Cached plans point at chunks that are already uploaded.
+	Manifest *ChunkManifest
+	Cached   bool
+
+	// Whole holds the job's other named wordlists. Each is a one-chunk plan
+	// that every task receives whole through Task.Wordlists.
+	Whole map[string]*WordlistPlan
 
 	cleanup func() error
 }
@@ -75,12 +90,80 @@ type WordlistChunk struct {
 
 // Cleanup removes temporary chunk files for file-based plans.
 func (p *WordlistPlan) Cleanup() error {
-	if p == nil || p.cleanup == nil {
+	if p == nil {
 		return nil
 	}
+	var firstErr error
+	for _, whole := range p.Whole {
+		if err := whole.Cleanup(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if p.cleanup == nil {
+		return firstErr
+	}
 	cleanup := p.cleanup
 	p.cleanup = nil
-	return cleanup()
+	if err := cleanup(); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// AddWhole attaches a whole wordlist to the plan and points every task at
+// its single chunk.
+func (p *WordlistPlan) AddWhole(name string, whole *WordlistPlan) {
+	if p.Whole == nil {
+		p.Whole = make(map[string]*WordlistPlan)
+	}
+	p.Whole[name] = whole
+	for i := range p.Tasks {
+		if p.Tasks[i].Wordlists == nil {
+			p.Tasks[i].Wordlists = make(map[string]string)
+		}
+		p.Tasks[i].Wordlists[name] = whole.ChunkKeys[0]
+	}
+}
+
+// WholeNames returns the names of the plan's whole wordlists in order.
+func (p *WordlistPlan) WholeNames() []string {
+	names := make([]string, 0, len(p.Whole))
+	for name := range p.Whole {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// WholeWordlistKey returns the storage key of a named wordlist shipped whole.
+func WholeWordlistKey(toolName, jobID, name string) string {
+	return path.Join(InputPrefix(toolName, jobID), "wordlist_"+sanitizeSegment(name, "wordlist")+".txt")
+}
+
+// PlanWholeWordlistFile prepares a wordlist that every task receives whole.
+// With a source hash it is keyed under the chunk cache, so jobs that use the
+// same file share one upload.
+func PlanWholeWordlistFile(toolName, jobID, name, wordlistPath, tempDir, sourceSHA256 string) (*WordlistPlan, error) {
+	info, err := os.Stat(wordlistPath)
+	if err != nil {
+		return nil, fmt.Errorf("wordlist %s: %w", name, err)
+	}
+	if info.Size() > wordlisttool.MaxSafeChunkSize {
+		return nil, fmt.Errorf("wordlist %s is %d bytes; wordlists shipped whole must stay under %d bytes", name, info.Size(), wordlisttool.MaxSafeChunkSize)
+	}
+	var plan *WordlistPlan
+	if sourceSHA256 != "" {
+		plan, err = PlanCachedWordlistFile(toolName, jobID, "", "", wordlistPath, tempDir, 1, 1, nil, sourceSHA256)
+	} else {
+		plan, err = planWordlistFile(toolName, jobID, "", "", wordlistPath, tempDir, wordlisttool.Policy{RequestedChunks: 1}, func(int) string {
+			return WholeWordlistKey(toolName, jobID, name)
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("wordlist %s: %w", name, err)
+	}
+	plan.Tasks = nil
+	return plan, nil
 }
 
 // PlanWordlistJob splits a wordlist into chunks and prepares tasks.
@@ -121,16 +204,41 @@ func PlanWordlistJob(toolName, jobID, runtimeTarget, options string, wordlistCon
 }
 
 // PlanWordlistFile splits a wordlist file into temporary chunk files and prepares tasks.
-func PlanWordlistFile(toolName, jobID, runtimeTarget, options, wordlistPath, tempDir string, chunkCount, workerCount int) (*WordlistPlan, error) {
-	result, err := wordlisttool.SplitFile(wordlistPath, tempDir, wordlisttool.Policy{
-		RequestedChunks: chunkCount,
-		WorkerCount:     workerCount,
-	}, func(i int) string {
+// A non-nil transform rewrites entries during the split.
+func PlanWordlistFile(toolName, jobID, runtimeTarget, options, wordlistPath, tempDir string, chunkCount, workerCount int, transform *wordlisttool.Transform) (*WordlistPlan, error) {
+	policy := wordlisttool.Policy{RequestedChunks: chunkCount, WorkerCount: workerCount, Transform: transform}
+	return planWordlistFile(toolName, jobID, runtimeTarget, options, wordlistPath, tempDir, policy, func(i int) string {
 		return InputKey(toolName, jobID, i)
 	})
+}
+
+// PlanWordlistSlice splits the entries [skip, skip+limit) of a wordlist file,
+// or every entry after skip when limit is zero, into chunkCount chunks
+// numbered from firstChunk. Adaptive chunk sizing plans its calibration
+// slice and then the remainder this way.
+func PlanWordlistSlice(toolName, jobID, runtimeTarget, options, wordlistPath, tempDir string, skip, limit, chunkCount, firstChunk int, transform *wordlisttool.Transform) (*WordlistPlan, error) {
+	policy := wordlisttool.Policy{RequestedChunks: chunkCount, Transform: transform, Skip: skip, Limit: limit}
+	plan, err := planWordlistFile(toolName, jobID, runtimeTarget, options, wordlistPath, tempDir, policy, func(i int) string {
+		return InputKey(toolName, jobID, firstChunk+i)
+	})
 	if err != nil {
 		return nil, err
 	}
+	for i := range plan.Tasks {
+		plan.Tasks[i].ChunkIdx += firstChunk
+		plan.Tasks[i].TotalChunks += firstChunk
+		plan.ChunkFiles[i].Index += firstChunk
+		plan.ChunkFiles[i].TotalChunks += firstChunk
+	}
+	return plan, nil
+}
+
+func planWordlistFile(toolName, jobID, runtimeTarget, options, wordlistPath, tempDir string, policy wordlisttool.Policy, keyForChunk func(int) string) (*WordlistPlan, error) {
+	result, err := wordlisttool.SplitFile(wordlistPath, tempDir, policy, keyForChunk)
+	if err != nil {
+		return nil, err
+	}
+	transform := policy.Transform
 
 	groupID := SafeTargetStem(runtimeTarget)
 	plan := &WordlistPlan{
@@ -143,6 +251,8 @@ func PlanWordlistFile(toolName, jobID, runtimeTarget, options, wordlistPath, tem
 		RequestedChunks:  result.RequestedChunks,
 		TargetChunkSize:  result.TargetChunkSize,
 		MaxChunkSize:     result.MaxChunkSize,
+		Transform:        transform,
+		TransformStats:   result.Transform,
 		cleanup:          result.Cleanup,
 	}
 
@@ -171,8 +281,26 @@ func PlanWordlistFile(toolName, jobID, runtimeTarget, options, wordlistPath, tem
 	return plan, nil
 }
 
-// UploadChunks uploads all chunk files to storage.
+// UploadChunks uploads all chunk files to storage, then the cache manifest
+// when the plan has one, then the plan's whole wordlists.
 func UploadChunks(ctx context.Context, storage cloud.Storage, bucket string, plan *WordlistPlan) error {
+	if err := uploadChunks(ctx, storage, bucket, plan); err != nil {
+		return err
+	}
+	if plan.Manifest != nil {
+		if err := uploadManifest(ctx, storage, bucket, plan.Manifest); err != nil {
+			return err
+		}
+	}
+	for _, name := range plan.WholeNames() {
+		if err := UploadChunks(ctx, storage, bucket, plan.Whole[name]); err != nil {
+			return fmt.Errorf("wordlist %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func uploadChunks(ctx context.Context, storage cloud.Storage, bucket string, plan *WordlistPlan) error {
 	if len(plan.ChunkFiles) > 0 {
 		for _, chunk := range plan.ChunkFiles {
 			if chunk.ByteSize > plan.MaxChunkSize && plan.MaxChunkSize > 0 {
@@ -166,7 +166,7 @@ func TestPlanWordlistFileCreatesCompatibleTasks(t *testing.T) {
 		t.Fatalf("write wordlist: %v", err)
 	}
 
-	plan, err := PlanWordlistFile("ffuf", "job-123", "https://example.com/FUZZ", "-ac", path, t.TempDir(), 2, 1)
+	plan, err := PlanWordlistFile("ffuf", "job-123", "https://example.com/FUZZ", "-ac", path, t.TempDir(), 2, 1, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -215,7 +215,7 @@ func TestPlanWordlistFileInputKeysRemainStable(t *testing.T) {
 		t.Fatalf("write wordlist: %v", err)
 	}
 
-	plan, err := PlanWordlistFile("gobuster", "job-abc", "example.com", "", path, t.TempDir(), 3, 1)
+	plan, err := PlanWordlistFile("gobuster", "job-abc", "example.com", "", path, t.TempDir(), 3, 1, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -260,7 +260,7 @@ func TestUploadChunksReadsFileChunksOneAtATime(t *testing.T) {
 	if err := os.WriteFile(path, []byte("a\nb\nc\nd\n"), 0o644); err != nil {
 		t.Fatalf("write wordlist: %v", err)
 	}
-	plan, err := PlanWordlistFile("ffuf", "job-123", "https://example.com/FUZZ", "", path, t.TempDir(), 2, 1)
+	plan, err := PlanWordlistFile("ffuf", "job-123", "https://example.com/FUZZ", "", path, t.TempDir(), 2, 1, nil)
 	if err != nil {
 		t.Fatalf("plan wordlist file: %v", err)
 	}
@@ -288,7 +288,7 @@ func TestUploadChunksFailureIncludesChunkIndexAndKey(t *testing.T) {
 	if err := os.WriteFile(path, []byte("a\nb\n"), 0o644); err != nil {
 		t.Fatalf("write wordlist: %v", err)
 	}
-	plan, err := PlanWordlistFile("ffuf", "job-123", "https://example.com/FUZZ", "", path, t.TempDir(), 2, 1)
+	plan, err := PlanWordlistFile("ffuf", "job-123", "https://example.com/FUZZ", "", path, t.TempDir(), 2, 1, nil)
 	if err != nil {
 		t.Fatalf("plan wordlist file: %v", err)
 	}
@@ -372,3 +372,32 @@ func TestSafeTargetStem(t *testing.T) {
 		t.Fatal("SafeTargetStem should be deterministic for the same input")
 	}
 }
+
+func TestPlanWholeWordlistFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.txt")
+	if err := os.WriteFile(path, []byte("root\nadmin\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	whole, err := PlanWholeWordlistFile("ffuf", "job-123", "W2", path, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("PlanWholeWordlistFile: %v", err)
+	}
+	defer cleanupWordlistPlan(t, whole)
+	if want := "scans/ffuf/job-123/inputs/wordlist_w2.txt"; len(whole.ChunkKeys) != 1 || whole.ChunkKeys[0] != want {
+		t.Fatalf("keys = %v, want [%s]", whole.ChunkKeys, want)
+	}
+
+	plan, err := PlanWordlistJob("ffuf", "job-123", "https://x/FUZZ", "", "a\nb\n", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.AddWhole("W2", whole)
+	for _, task := range plan.Tasks {
+		if task.Wordlists["W2"] != whole.ChunkKeys[0] {
+			t.Fatalf("task wordlists = %v", task.Wordlists)
+		}
+	}
+	if names := plan.WholeNames(); len(names) != 1 || names[0] != "W2" {
+		t.Fatalf("WholeNames() = %v", names)
+	}
+}
@@ -3,8 +3,12 @@ package modules
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
+
+	"heph4estus/internal/targets"
 )
 
 var (
@@ -35,8 +39,47 @@ type ModuleDefinition struct {
 	Timeout       string            `yaml:"timeout"`
 	Tags          []string          `yaml:"tags"`
 	Env           map[string]string `yaml:"env,omitempty"`
+	// TargetKinds lists the target kinds the tool accepts (ip, cidr, range,
+	// hostname, hostport, url). For target_list modules it applies to the
+	// input list; for wordlist modules to the runtime target. Empty accepts
+	// anything.
+	TargetKinds []string `yaml:"target_kinds,omitempty"`
+	// SplitCIDR is the default /N block size wide IPv4 CIDRs are split into.
+	// Zero, the usual setting, leaves CIDRs whole unless --split-cidr asks.
+	SplitCIDR int `yaml:"split_cidr,omitempty"`
+	// DefaultRate is what the {{rate}} placeholder renders to when the job
+	// sets no global rate. Modules that use {{rate}} must set it.
+	DefaultRate int `yaml:"default_rate,omitempty"`
+	// Wordlists declares named wordlist inputs for wordlist modules. One is
+	// chunked across tasks; the others are shipped whole to every task.
+	// Modules without declarations take a single chunked wordlist.
+	Wordlists []WordlistInput `yaml:"wordlists,omitempty"`
+	// PairedWordlists lists options, such as ffuf's "-mode pitchfork",
+	// under which the tool pairs its wordlists line by line. Splitting the
+	// chunked wordlist would misalign it with the whole ones, so with any
+	// of them it ships as one chunk.
+	PairedWordlists []string `yaml:"paired_wordlists,omitempty"`
+	// Assets names the asset bundles a job may ship to the module, such as
+	// custom templates or scripts. A shipped bundle is extracted into the
+	// task's temp dir and its path renders through {{assets}}.
+	Assets []string `yaml:"assets,omitempty"`
+}
+
+// WordlistInput is one named wordlist a module takes. Its file path
+// renders through the {{wordlist:NAME}} placeholder; the chunked input is
+// also available as {{input}} and {{wordlist}}.
+type WordlistInput struct {
+	Name string `yaml:"name"`
+	// Chunked marks the input that is split across tasks.
+	Chunked bool `yaml:"chunked,omitempty"`
+	// Optional inputs may be omitted. Exec arguments that reference an
+	// omitted input are dropped, so bind the flag and value in one
+	// argument, e.g. "-w={{wordlist:W2}}:W2".
+	Optional bool `yaml:"optional,omitempty"`
 }
 
+var wordlistNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
 func (m *ModuleDefinition) Validate() error {
 	if m.Name == "" {
 		return fmt.Errorf("%w: name is required", ErrInvalidModule)
@@ -76,9 +119,152 @@ func (m *ModuleDefinition) Validate() error {
 	if _, err := time.ParseDuration(m.Timeout); err != nil {
 		return fmt.Errorf("%w: invalid timeout %q: %v", ErrInvalidModule, m.Timeout, err)
 	}
+	for _, k := range m.TargetKinds {
+		if _, err := targets.ParseKind(k); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidModule, err)
+		}
+	}
+	if m.SplitCIDR < 0 || m.SplitCIDR > 32 {
+		return fmt.Errorf("%w: split_cidr must be between 0 and 32", ErrInvalidModule)
+	}
+	if m.DefaultRate < 0 {
+		return fmt.Errorf("%w: default_rate must not be negative", ErrInvalidModule)
+	}
+	if m.RateLimited() && m.DefaultRate == 0 {
+		return fmt.Errorf("%w: default_rate is required when the command uses {{rate}}", ErrInvalidModule)
+	}
+	if err := m.validateWordlists(); err != nil {
+		return err
+	}
+	return m.validateAssets()
+}
+
+func (m *ModuleDefinition) validateAssets() error {
+	uses := containsPlaceholder(m.Exec, m.Shell, "assets")
+	if len(m.Assets) == 0 {
+		if uses {
+			return fmt.Errorf("%w: the command uses {{assets}} but the module declares no assets", ErrInvalidModule)
+		}
+		return nil
+	}
+	seen := make(map[string]bool, len(m.Assets))
+	for _, name := range m.Assets {
+		if !wordlistNamePattern.MatchString(name) {
+			return fmt.Errorf("%w: invalid asset bundle name %q", ErrInvalidModule, name)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: duplicate asset bundle %q", ErrInvalidModule, name)
+		}
+		seen[name] = true
+	}
+	if !uses {
+		return fmt.Errorf("%w: assets are never used; add {{assets}} to the command", ErrInvalidModule)
+	}
 	return nil
 }
 
+func (m *ModuleDefinition) validateWordlists() error {
+	if len(m.Wordlists) == 0 {
+		if len(m.PairedWordlists) > 0 {
+			return fmt.Errorf("%w: paired_wordlists needs declared wordlists", ErrInvalidModule)
+		}
+		return nil
+	}
+	if m.InputType != InputTypeWordlist {
+		return fmt.Errorf("%w: wordlists are only valid for %s modules", ErrInvalidModule, InputTypeWordlist)
+	}
+	seen := make(map[string]bool, len(m.Wordlists))
+	chunked := 0
+	for _, w := range m.Wordlists {
+		if !wordlistNamePattern.MatchString(w.Name) {
+			return fmt.Errorf("%w: invalid wordlist name %q", ErrInvalidModule, w.Name)
+		}
+		if seen[w.Name] {
+			return fmt.Errorf("%w: duplicate wordlist %q", ErrInvalidModule, w.Name)
+		}
+		seen[w.Name] = true
+		if w.Chunked {
+			chunked++
+			if w.Optional {
+				return fmt.Errorf("%w: chunked wordlist %q cannot be optional", ErrInvalidModule, w.Name)
+			}
+			continue
+		}
+		if !containsPlaceholder(m.Exec, m.Shell, "wordlist:"+w.Name) {
+			return fmt.Errorf("%w: wordlist %q is never used; add {{wordlist:%s}} to the command", ErrInvalidModule, w.Name, w.Name)
+		}
+	}
+	if chunked != 1 {
+		return fmt.Errorf("%w: exactly one wordlist must be chunked, found %d", ErrInvalidModule, chunked)
+	}
+	for _, opt := range m.PairedWordlists {
+		if n := len(strings.Fields(opt)); n < 1 || n > 2 {
+			return fmt.Errorf("%w: paired_wordlists option %q must be a flag and an optional value", ErrInvalidModule, opt)
+		}
+	}
+	return nil
+}
+
+// PairsWordlists returns the PairedWordlists option that options set, as
+// "flag value", "flag=value" or a bare flag, or "" when none is set.
+func (m *ModuleDefinition) PairsWordlists(options string) string {
+	fields := strings.Fields(options)
+	for _, opt := range m.PairedWordlists {
+		flag, value, _ := strings.Cut(strings.Join(strings.Fields(opt), " "), " ")
+		for i, f := range fields {
+			switch {
+			case value == "" && f == flag,
+				value != "" && f == flag+"="+value,
+				value != "" && f == flag && i+1 < len(fields) && fields[i+1] == value:
+				return opt
+			}
+		}
+	}
+	return ""
+}
+
+// ChunkedWordlist returns the name of the chunked wordlist input, or ""
+// when the module declares no named wordlists.
+func (m *ModuleDefinition) ChunkedWordlist() string {
+	for _, w := range m.Wordlists {
+		if w.Chunked {
+			return w.Name
+		}
+	}
+	return ""
+}
+
+// WholeWordlists returns the declared inputs that are shipped whole.
+func (m *ModuleDefinition) WholeWordlists() []WordlistInput {
+	var out []WordlistInput
+	for _, w := range m.Wordlists {
+		if !w.Chunked {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// AcceptsAssets reports whether the module takes the named asset bundle.
+func (m *ModuleDefinition) AcceptsAssets(name string) bool {
+	return slices.Contains(m.Assets, name)
+}
+
+// AcceptedKinds returns the parsed target_kinds. Validate must have passed.
+func (m *ModuleDefinition) AcceptedKinds() []targets.Kind {
+	kinds := make([]targets.Kind, 0, len(m.TargetKinds))
+	for _, k := range m.TargetKinds {
+		kind, _ := targets.ParseKind(k)
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// TargetOptions returns the module's default target normalization options.
+func (m *ModuleDefinition) TargetOptions() targets.Options {
+	return targets.Options{Accept: m.AcceptedKinds(), SplitCIDR: m.SplitCIDR}
+}
+
 func (m *ModuleDefinition) TimeoutDuration() time.Duration {
 	d, _ := time.ParseDuration(m.Timeout)
 	return d
@@ -94,6 +280,18 @@ func (m *ModuleDefinition) NeedsWordlist() bool {
 	return containsPlaceholder(m.Exec, m.Shell, "wordlist") || containsPlaceholder(m.Exec, m.Shell, "input")
 }
 
+// ResolvesDNS returns true for target-list modules tagged dns, whose
+// results wildcard DNS records can flood.
+func (m *ModuleDefinition) ResolvesDNS() bool {
+	return m.InputType == InputTypeTargetList && slices.Contains(m.Tags, "dns")
+}
+
+// RateLimited returns true when the module command uses the {{rate}}
+// placeholder, so a job-level global rate can be divided across workers.
+func (m *ModuleDefinition) RateLimited() bool {
+	return containsPlaceholder(m.Exec, m.Shell, "rate")
+}
+
 func containsPlaceholder(exec []string, shell, placeholder string) bool {
 	needle := "{{" + placeholder + "}}"
 	for _, arg := range exec {
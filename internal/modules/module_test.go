@@ -206,3 +206,129 @@ func TestTimeoutDuration(t *testing.T) {
 		t.Fatalf("expected 1h30m, got %v", got)
 	}
 }
+
+func TestValidate_TargetKinds(t *testing.T) {
+	m := validModule()
+	m.TargetKinds = []string{"url", "hostport"}
+	m.SplitCIDR = 24
+	if err := m.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	opts := m.TargetOptions()
+	if len(opts.Accept) != 2 || opts.Accept[0] != "url" || opts.SplitCIDR != 24 {
+		t.Errorf("TargetOptions = %+v", opts)
+	}
+
+	m.TargetKinds = []string{"email"}
+	if err := m.Validate(); !errors.Is(err, ErrInvalidModule) {
+		t.Fatalf("expected ErrInvalidModule for unknown kind, got %v", err)
+	}
+	m.TargetKinds = nil
+	m.SplitCIDR = 33
+	if err := m.Validate(); !errors.Is(err, ErrInvalidModule) {
+		t.Fatalf("expected ErrInvalidModule for split_cidr, got %v", err)
+	}
+}
+
+func TestValidate_Rate(t *testing.T) {
+	m := validModule()
+	m.Exec = append(m.Exec, "--rate", "{{rate}}")
+	if err := m.Validate(); !errors.Is(err, ErrInvalidModule) {
+		t.Fatalf("{{rate}} without default_rate: got %v, want ErrInvalidModule", err)
+	}
+	m.DefaultRate = 100
+	if err := m.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !m.RateLimited() {
+		t.Error("RateLimited() = false, want true")
+	}
+
+	m = validModule()
+	m.DefaultRate = -1
+	if err := m.Validate(); !errors.Is(err, ErrInvalidModule) {
+		t.Fatalf("negative default_rate: got %v, want ErrInvalidModule", err)
+	}
+	m = validModule()
+	if m.RateLimited() {
+		t.Error("RateLimited() = true without {{rate}}")
+	}
+}
+
+func TestValidate_Wordlists(t *testing.T) {
+	wordlistModule := func() ModuleDefinition {
+		m := validModule()
+		m.InputType = InputTypeWordlist
+		m.Exec = []string{"ffuf", "-w", "{{input}}:FUZZ", "-w={{wordlist:W2}}:W2", "-o", "{{output}}"}
+		m.Wordlists = []WordlistInput{{Name: "FUZZ", Chunked: true}, {Name: "W2", Optional: true}}
+		return m
+	}
+	m := wordlistModule()
+	if err := m.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if m.ChunkedWordlist() != "FUZZ" || len(m.WholeWordlists()) != 1 {
+		t.Fatalf("ChunkedWordlist() = %q, WholeWordlists() = %v", m.ChunkedWordlist(), m.WholeWordlists())
+	}
+
+	for name, mutate := range map[string]func(*ModuleDefinition){
+		"no chunked":     func(m *ModuleDefinition) { m.Wordlists[0].Chunked = false },
+		"two chunked":    func(m *ModuleDefinition) { m.Wordlists[1].Chunked = true },
+		"duplicate":      func(m *ModuleDefinition) { m.Wordlists[1].Name = "FUZZ" },
+		"bad name":       func(m *ModuleDefinition) { m.Wordlists[1].Name = "../W2" },
+		"unused":         func(m *ModuleDefinition) { m.Exec = []string{"ffuf", "-w", "{{input}}", "-o", "{{output}}"} },
+		"optional chunk": func(m *ModuleDefinition) { m.Wordlists[0].Optional = true },
+		"not a wordlist": func(m *ModuleDefinition) { m.InputType = InputTypeTargetList },
+		"bad paired":     func(m *ModuleDefinition) { m.PairedWordlists = []string{"-a b c"} },
+	} {
+		m := wordlistModule()
+		mutate(&m)
+		if err := m.Validate(); !errors.Is(err, ErrInvalidModule) {
+			t.Errorf("%s: got %v, want ErrInvalidModule", name, err)
+		}
+	}
+}
+
+func TestPairsWordlists(t *testing.T) {
+	m := ModuleDefinition{PairedWordlists: []string{"-mode pitchfork"}}
+	for options, want := range map[string]string{
+		"-mode pitchfork -ac":   "-mode pitchfork",
+		"-ac -mode=pitchfork":   "-mode pitchfork",
+		"-mode clusterbomb":     "",
+		"-mode":                 "",
+		"-H pitchfork -mode sn": "",
+	} {
+		if got := m.PairsWordlists(options); got != want {
+			t.Errorf("PairsWordlists(%q) = %q, want %q", options, got, want)
+		}
+	}
+}
+
+func TestValidate_Assets(t *testing.T) {
+	assetModule := func() ModuleDefinition {
+		m := validModule()
+		m.Exec = append(m.Exec, "-t={{assets}}")
+		m.Assets = []string{"templates"}
+		return m
+	}
+	m := assetModule()
+	if err := m.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !m.AcceptsAssets("templates") || m.AcceptsAssets("scripts") {
+		t.Fatalf("AcceptsAssets wrong for %v", m.Assets)
+	}
+
+	for name, mutate := range map[string]func(*ModuleDefinition){
+		"undeclared": func(m *ModuleDefinition) { m.Assets = nil },
+		"unused":     func(m *ModuleDefinition) { m.Exec = m.Exec[:len(m.Exec)-1] },
+		"duplicate":  func(m *ModuleDefinition) { m.Assets = append(m.Assets, "templates") },
+		"bad name":   func(m *ModuleDefinition) { m.Assets = []string{"../x"} },
+	} {
+		m := assetModule()
+		mutate(&m)
+		if err := m.Validate(); !errors.Is(err, ErrInvalidModule) {
+			t.Errorf("%s: got %v, want ErrInvalidModule", name, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package operator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrBudgetExceeded is returned (wrapped) when a running job crosses one of
+// its budget limits.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Budget caps what a single job may consume. Zero fields are unlimited.
+type Budget struct {
+	MaxWorkerHours float64       `json:"max_worker_hours,omitempty"`
+	MaxWallTime    time.Duration `json:"max_wall_time,omitempty"`
+	MaxSpendUSD    float64       `json:"max_spend_usd,omitempty"`
+	// Destroy tears down the job's infrastructure when a limit is hit.
+	Destroy bool `json:"destroy,omitempty"`
+}
+
+// IsZero reports whether the budget sets no limits.
+func (b Budget) IsZero() bool {
+	return b.MaxWorkerHours <= 0 && b.MaxWallTime <= 0 && b.MaxSpendUSD <= 0
+}
+
+// String summarises the limits, e.g. "2h0m0s wall, 10.0 worker-hours, $5.00".
+func (b Budget) String() string {
+	var parts []string
+	if b.MaxWallTime > 0 {
+		parts = append(parts, b.MaxWallTime.String()+" wall")
+	}
+	if b.MaxWorkerHours > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f worker-hours", b.MaxWorkerHours))
+	}
+	if b.MaxSpendUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f", b.MaxSpendUSD))
+	}
+	if len(parts) == 0 {
+		return "unlimited"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Usage is what a job has consumed so far.
+type Usage struct {
+	Elapsed     time.Duration
+	WorkerHours float64
+	SpendUSD    float64 // estimated from list prices
+}
+
+// Check returns an error wrapping ErrBudgetExceeded when u crosses a limit.
+func (b Budget) Check(u Usage) error {
+	switch {
+	case b.MaxWallTime > 0 && u.Elapsed >= b.MaxWallTime:
+		return fmt.Errorf("%w: wall time %s reached limit %s", ErrBudgetExceeded, u.Elapsed.Truncate(time.Second), b.MaxWallTime)
+	case b.MaxWorkerHours > 0 && u.WorkerHours >= b.MaxWorkerHours:
+		return fmt.Errorf("%w: %.2f worker-hours reached limit %.2f", ErrBudgetExceeded, u.WorkerHours, b.MaxWorkerHours)
+	case b.MaxSpendUSD > 0 && u.SpendUSD >= b.MaxSpendUSD:
+		return fmt.Errorf("%w: estimated spend $%.2f reached limit $%.2f", ErrBudgetExceeded, u.SpendUSD, b.MaxSpendUSD)
+	}
+	return nil
+}
+
+// ResolveBudget merges explicit per-job limits with the saved profile
+// budget. Explicit non-zero values win field by field.
+func ResolveBudget(explicit Budget, cfg *OperatorConfig) (Budget, error) {
+	if explicit.MaxWorkerHours < 0 || explicit.MaxWallTime < 0 || explicit.MaxSpendUSD < 0 {
+		return Budget{}, fmt.Errorf("budget limits must not be negative")
+	}
+	b := Budget{}
+	if cfg != nil {
+		b.MaxWorkerHours = cfg.MaxWorkerHours
+		b.MaxSpendUSD = cfg.MaxSpendUSD
+		b.Destroy = cfg.BudgetDestroy
+		if cfg.MaxWallTime != "" {
+			d, err := time.ParseDuration(cfg.MaxWallTime)
+			if err != nil {
+				return Budget{}, fmt.Errorf("invalid max_wall_time in config: %w", err)
+			}
+			b.MaxWallTime = d
+		}
+	}
+	if explicit.MaxWorkerHours > 0 {
+		b.MaxWorkerHours = explicit.MaxWorkerHours
+	}
+	if explicit.MaxWallTime > 0 {
+		b.MaxWallTime = explicit.MaxWallTime
+	}
+	if explicit.MaxSpendUSD > 0 {
+		b.MaxSpendUSD = explicit.MaxSpendUSD
+	}
+	if explicit.Destroy {
+		b.Destroy = true
+	}
+	if b.MaxWorkerHours < 0 || b.MaxWallTime < 0 || b.MaxSpendUSD < 0 {
+		return Budget{}, fmt.Errorf("budget limits must not be negative")
+	}
+	return b, nil
+}
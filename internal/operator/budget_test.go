@@ -0,0 +1,51 @@
+package operator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBudgetCheck(t *testing.T) {
+	b := Budget{MaxWallTime: time.Hour, MaxWorkerHours: 10, MaxSpendUSD: 5}
+	if err := b.Check(Usage{Elapsed: 30 * time.Minute, WorkerHours: 5, SpendUSD: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := map[string]Usage{
+		"wall time":    {Elapsed: time.Hour},
+		"worker-hours": {WorkerHours: 10},
+		"spend $5.50":  {SpendUSD: 5.5},
+	}
+	for want, u := range tests {
+		err := b.Check(u)
+		if !errors.Is(err, ErrBudgetExceeded) || !strings.Contains(err.Error(), want) {
+			t.Errorf("Check(%+v) = %v, want %q", u, err, want)
+		}
+	}
+	if err := (Budget{}).Check(Usage{Elapsed: 1000 * time.Hour}); err != nil {
+		t.Errorf("zero budget should be unlimited, got %v", err)
+	}
+}
+
+func TestResolveBudget(t *testing.T) {
+	cfg := &OperatorConfig{MaxWallTime: "2h", MaxSpendUSD: 20, BudgetDestroy: true}
+	b, err := ResolveBudget(Budget{MaxSpendUSD: 5, MaxWorkerHours: 3}, cfg)
+	if err != nil {
+		t.Fatalf("ResolveBudget: %v", err)
+	}
+	want := Budget{MaxWallTime: 2 * time.Hour, MaxSpendUSD: 5, MaxWorkerHours: 3, Destroy: true}
+	if b != want {
+		t.Errorf("budget = %+v, want %+v", b, want)
+	}
+	if b.String() != "2h0m0s wall, 3.0 worker-hours, $5.00" {
+		t.Errorf("String() = %q", b.String())
+	}
+
+	if _, err := ResolveBudget(Budget{}, &OperatorConfig{MaxWallTime: "soon"}); err == nil {
+		t.Error("expected error for invalid max_wall_time")
+	}
+	if _, err := ResolveBudget(Budget{MaxSpendUSD: -1}, nil); err == nil {
+		t.Error("expected error for negative limit")
+	}
+}
@@ -0,0 +1,40 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+)
+
+// Calibration records how adaptive chunk sizing split a wordlist job. A
+// sample slice runs first; its measured per-entry time sizes the chunks of
+// the remainder so each task runs for about TargetTaskDuration.
+type Calibration struct {
+	TargetTaskDuration time.Duration `json:"target_task_duration"`
+	SampleChunks       int           `json:"sample_chunks,omitempty"`
+	SampleEntries      int           `json:"sample_entries,omitempty"`
+	// PerEntry is the measured time per wordlist entry, and Source where it
+	// came from: "results" (worker-reported durations) or "wall-clock".
+	PerEntry        time.Duration `json:"per_entry,omitempty"`
+	Source          string        `json:"source,omitempty"`
+	EntriesPerChunk int           `json:"entries_per_chunk,omitempty"`
+	Chunks          int           `json:"chunks,omitempty"` // remainder chunks
+	CalibratedAt    *time.Time    `json:"calibrated_at,omitempty"`
+}
+
+// Done reports whether the sample has been measured.
+func (c *Calibration) Done() bool {
+	return c != nil && c.CalibratedAt != nil
+}
+
+// String summarises the calibration, e.g. "5m0s per task: 12ms/entry from
+// results, 25000 entries in each of 40 chunks".
+func (c *Calibration) String() string {
+	if !c.Done() {
+		if c.SampleChunks == 0 {
+			return fmt.Sprintf("%s per task requested", c.TargetTaskDuration)
+		}
+		return fmt.Sprintf("%s per task, calibrating on %d entries in %d chunks", c.TargetTaskDuration, c.SampleEntries, c.SampleChunks)
+	}
+	return fmt.Sprintf("%s per task: %s/entry from %s, %d entries in each of %d chunks",
+		c.TargetTaskDuration, c.PerEntry, c.Source, c.EntriesPerChunk, c.Chunks)
+}
@@ -0,0 +1,22 @@
+package operator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalibrationString(t *testing.T) {
+	c := &Calibration{TargetTaskDuration: 5 * time.Minute}
+	if got := c.String(); got != "5m0s per task requested" {
+		t.Errorf("unplanned String() = %q", got)
+	}
+	c.SampleChunks, c.SampleEntries = 4, 800
+	if got := c.String(); got != "5m0s per task, calibrating on 800 entries in 4 chunks" {
+		t.Errorf("pending String() = %q", got)
+	}
+	now := time.Now()
+	c.PerEntry, c.Source, c.EntriesPerChunk, c.Chunks, c.CalibratedAt = 12*time.Millisecond, "results", 25000, 40, &now
+	if got := c.String(); got != "5m0s per task: 12ms/entry from results, 25000 entries in each of 40 chunks" {
+		t.Errorf("done String() = %q", got)
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"heph4estus/internal/cloud"
 	"heph4estus/internal/fleet"
+	"heph4estus/internal/schedule"
+	"heph4estus/internal/scope"
 )
 
 const appName = "heph4estus"
@@ -26,9 +28,22 @@ type OperatorConfig struct {
 	DualStackRequired bool   `json:"dual_stack_required,omitempty"`
 	CleanupPolicy     string `json:"cleanup_policy,omitempty"` // "reuse" or "destroy-after"
 	OutputDir         string `json:"output_dir,omitempty"`
+	// ScopeFile is the default engagement scope file applied to every job
+	// that does not pass --scope.
+	ScopeFile string `json:"scope_file,omitempty"`
 	// Cloud is the persisted default cloud kind ("aws", "manual", "hetzner",
 	// etc.). Empty means "use the built-in default" (AWS).
 	Cloud string `json:"cloud,omitempty"`
+	// Per-profile job budgets; see Budget. MaxWallTime is a Go duration
+	// string such as "2h30m".
+	MaxWorkerHours float64 `json:"max_worker_hours,omitempty"`
+	MaxWallTime    string  `json:"max_wall_time,omitempty"`
+	MaxSpendUSD    float64 `json:"max_spend_usd,omitempty"`
+	BudgetDestroy  bool    `json:"budget_destroy,omitempty"`
+	// Per-profile scan window; see schedule.New for the syntax.
+	ScanWindow string `json:"scan_window,omitempty"`
+	WindowTZ   string `json:"window_tz,omitempty"`
+	Blackouts  string `json:"blackouts,omitempty"`
 }
 
 // ConfigDir returns the operator config directory path.
@@ -217,6 +232,40 @@ func ResolveOutputDir(explicit string, cfg *OperatorConfig) string {
 	return ""
 }
 
+// ResolveScope loads the effective scope given an explicit scope file path
+// ("" means unset) and saved operator config. It returns nil when neither
+// names a scope file.
+func ResolveScope(explicit string, cfg *OperatorConfig) (*scope.Scope, error) {
+	path := explicit
+	if path == "" && cfg != nil {
+		path = cfg.ScopeFile
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return scope.Load(path)
+}
+
+// ResolveWindow builds the effective scan window. Explicit range, time zone
+// or blackout values replace the saved profile window as a whole; nil means
+// the job may run at any time.
+func ResolveWindow(ranges, tz, blackouts string, cfg *OperatorConfig) (*schedule.Window, error) {
+	if ranges == "" && tz == "" && blackouts == "" && cfg != nil {
+		ranges, tz, blackouts = cfg.ScanWindow, cfg.WindowTZ, cfg.Blackouts
+	}
+	return schedule.New(ranges, tz, blackouts)
+}
+
+// LoadProfileScope loads the scope file named by the saved operator config,
+// or returns nil when none is configured.
+func LoadProfileScope() (*scope.Scope, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ResolveScope("", cfg)
+}
+
 // ResolveRegion returns the effective region given an explicit flag value
 // ("" means unset) and saved operator config. Falls back to us-east-1.
 func ResolveRegion(explicit string, cfg *OperatorConfig) string {
@@ -295,6 +295,66 @@ func TestResolveOutputDir(t *testing.T) {
 	}
 }
 
+func TestResolveScope(t *testing.T) {
+	dir := t.TempDir()
+	saved := filepath.Join(dir, "saved.txt")
+	explicit := filepath.Join(dir, "explicit.txt")
+	if err := os.WriteFile(saved, []byte("10.0.0.0/8\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(explicit, []byte("example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &OperatorConfig{ScopeFile: saved}
+
+	s, err := ResolveScope(explicit, cfg)
+	if err != nil || s == nil {
+		t.Fatalf("explicit: %v, %v", s, err)
+	}
+	if err := s.Check("example.com"); err != nil {
+		t.Errorf("explicit scope should win: %v", err)
+	}
+
+	s, err = ResolveScope("", cfg)
+	if err != nil || s == nil {
+		t.Fatalf("saved: %v, %v", s, err)
+	}
+	if err := s.Check("10.1.2.3"); err != nil {
+		t.Errorf("saved scope should apply: %v", err)
+	}
+
+	if s, err := ResolveScope("", &OperatorConfig{}); s != nil || err != nil {
+		t.Errorf("unset = %v, %v; want nil, nil", s, err)
+	}
+	if _, err := ResolveScope(filepath.Join(dir, "missing.txt"), nil); err == nil {
+		t.Error("expected error for missing scope file")
+	}
+}
+
+func TestResolveWindow(t *testing.T) {
+	cfg := &OperatorConfig{ScanWindow: "Mon-Fri 09:00-17:00", WindowTZ: "Europe/London", Blackouts: "2026-12-24"}
+
+	w, err := ResolveWindow("", "", "", cfg)
+	if err != nil || w == nil {
+		t.Fatalf("saved: %v, %v", w, err)
+	}
+	if got := w.String(); got != "Mon-Fri 09:00-17:00 (Europe/London), blackout 2026-12-24" {
+		t.Errorf("saved window = %q", got)
+	}
+
+	w, err = ResolveWindow("22:00-06:00", "", "", cfg)
+	if err != nil || w == nil || w.String() != "22:00-06:00" {
+		t.Errorf("explicit window should replace the saved one: %v, %v", w, err)
+	}
+
+	if w, err := ResolveWindow("", "", "", nil); w != nil || err != nil {
+		t.Errorf("unset = %v, %v; want nil, nil", w, err)
+	}
+	if _, err := ResolveWindow("", "", "", &OperatorConfig{ScanWindow: "whenever"}); err == nil {
+		t.Error("expected error for invalid saved window")
+	}
+}
+
 func TestResolveCloud(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -9,6 +9,8 @@ import (
 
 	"heph4estus/internal/cloud"
 	"heph4estus/internal/jobs"
+	"heph4estus/internal/tools/dns"
+	"heph4estus/internal/tools/nmap"
 )
 
 // ExportResult summarises what was written to the local output directory.
@@ -16,6 +18,7 @@ type ExportResult struct {
 	Dir            string // root output dir: <out>/<tool>/<job_id>
 	ResultCount    int
 	ArtifactCount  int
+	Merge          *MergeResult // merged nmap reports, nuclei findings or DNS answers; nil for other tools
 }
 
 // ExportJob downloads results and artifacts from S3 to a predictable local
@@ -24,6 +27,14 @@ type ExportResult struct {
 //	<outDir>/<tool>/<jobID>/results/...
 //	<outDir>/<tool>/<jobID>/artifacts/...
 //
+// Nmap jobs also get their chunk reports merged into <jobDir>/merged, one
+// report per target and one for the whole job, in every merge format.
+// Nuclei jobs get their reports merged into <jobDir>/merged/findings.jsonl,
+// with findings that several template shards reported kept once. Jobs that
+// probed for wildcard DNS get their answers merged into
+// <jobDir>/merged/resolved.jsonl, without the answers that match a
+// wildcard.
+//
 // It returns the counts of files written so callers can report progress.
 // Any download failure is returned immediately — partial exports are not
 // silently swallowed.
@@ -43,11 +54,39 @@ func ExportJob(ctx context.Context, storage cloud.Storage, bucket, tool, jobID,
 		return nil, fmt.Errorf("exporting artifacts: %w", err)
 	}
 
-	return &ExportResult{
+	res := &ExportResult{
 		Dir:           jobDir,
 		ResultCount:   resultCount,
 		ArtifactCount: artifactCount,
-	}, nil
+	}
+	if tool == "nmap" && artifactCount > 0 {
+		merge, err := MergeNmapExport(jobDir, "", nmap.MergeFormats)
+		if err != nil {
+			return nil, fmt.Errorf("merging nmap reports: %w", err)
+		}
+		if merge != nil && len(merge.Files) > 0 {
+			res.Merge = merge
+		}
+	}
+	if tool == "nuclei" && artifactCount > 0 {
+		merge, err := MergeNucleiExport(jobDir, "")
+		if err != nil {
+			return nil, fmt.Errorf("merging nuclei findings: %w", err)
+		}
+		if merge != nil && len(merge.Files) > 0 {
+			res.Merge = merge
+		}
+	}
+	if _, err := os.Stat(filepath.Join(jobDir, "artifacts", dns.ProbeGroup)); err == nil {
+		merge, err := MergeDNSExport(jobDir, "")
+		if err != nil {
+			return nil, fmt.Errorf("merging DNS answers: %w", err)
+		}
+		if merge != nil && len(merge.Files) > 0 {
+			res.Merge = merge
+		}
+	}
+	return res, nil
 }
 
 // downloadPrefix lists all keys under prefix and writes each object to the
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"heph4estus/internal/tools/dns"
 )
 
 // stubStorage implements cloud.Storage for export tests.
@@ -150,3 +153,63 @@ func TestExportJobNestedArtifacts(t *testing.T) {
 		t.Errorf("expected nested artifact to exist: %v", err)
 	}
 }
+
+func TestExportJobMergesNmapReports(t *testing.T) {
+	report := func(port string, start int) []byte {
+		return []byte(fmt.Sprintf(`<nmaprun scanner="nmap" start="%d"><host><status state="up"/><address addr="10.0.0.1" addrtype="ipv4"/><ports><port protocol="tcp" portid="%s"><state state="open"/></port></ports></host><runstats><finished time="%d"/><hosts up="1" down="0" total="1"/></runstats></nmaprun>`, start, port, start+10))
+	}
+	store := &stubStorage{objects: map[string][]byte{
+		"scans/nmap/job-5/artifacts/10.0.0.1_line1/10.0.0.1_chunk0_of_2_1.xml": report("22", 100),
+		"scans/nmap/job-5/artifacts/10.0.0.1_line1/10.0.0.1_chunk1_of_2_2.xml": report("443", 105),
+	}}
+
+	outDir := t.TempDir()
+	result, err := ExportJob(context.Background(), store, "bucket", "nmap", "job-5", outDir)
+	if err != nil {
+		t.Fatalf("ExportJob: %v", err)
+	}
+	if result.Merge == nil {
+		t.Fatal("expected nmap reports to be merged on export")
+	}
+	if result.Merge.Reports != 2 || result.Merge.Targets != 1 {
+		t.Errorf("merge = %d reports, %d targets, want 2 and 1", result.Merge.Reports, result.Merge.Targets)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "nmap", "job-5", "merged", "job.gnmap"))
+	if err != nil {
+		t.Fatalf("reading merged gnmap: %v", err)
+	}
+	if want := "Ports: 22/open/tcp/////, 443/open/tcp/////"; !strings.Contains(string(data), want) {
+		t.Errorf("merged gnmap missing %q:\n%s", want, data)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "nmap", "job-5", "merged", "targets", "10.0.0.1.xml")); err != nil {
+		t.Errorf("missing per-target report: %v", err)
+	}
+}
+
+func TestExportJobFiltersWildcardDNSAnswers(t *testing.T) {
+	probe := dns.ProbeName("job-6", "example.com", 0)
+	store := &stubStorage{objects: map[string][]byte{
+		"scans/dnsx/job-6/artifacts/wildcard-probe/" + probe + "_chunk0_of_1_1.jsonl": []byte(`{"host":"` + probe + `","a":["1.2.3.4"]}` + "\n"),
+		"scans/dnsx/job-6/artifacts/www.example.com_2.jsonl":                          []byte(`{"host":"www.example.com","a":["9.9.9.9"]}` + "\n"),
+		"scans/dnsx/job-6/artifacts/junk.example.com_3.jsonl":                         []byte(`{"host":"junk.example.com","a":["1.2.3.4"]}` + "\n"),
+	}}
+
+	outDir := t.TempDir()
+	result, err := ExportJob(context.Background(), store, "bucket", "dnsx", "job-6", outDir)
+	if err != nil {
+		t.Fatalf("ExportJob: %v", err)
+	}
+	if result.Merge == nil || result.Merge.Answers != 1 || result.Merge.Wildcard != 2 {
+		t.Fatalf("merge = %+v", result.Merge)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "dnsx", "job-6", "merged", DNSAnswersFile))
+	if err != nil {
+		t.Fatalf("reading merged answers: %v", err)
+	}
+	if !strings.Contains(string(data), "www.example.com") || strings.Contains(string(data), "junk.example.com") {
+		t.Errorf("merged answers = %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "dnsx", "job-6", "merged", WildcardsFile)); err != nil {
+		t.Errorf("missing wildcards file: %v", err)
+	}
+}
@@ -8,6 +8,12 @@ import (
 	"time"
 
 	"heph4estus/internal/fleet"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/politeness"
+	"heph4estus/internal/schedule"
+	"heph4estus/internal/tools/dns"
+	"heph4estus/internal/tools/nuclei"
+	"heph4estus/internal/tools/wordlist"
 )
 
 // Phase represents the current lifecycle phase of a job.
@@ -28,6 +34,7 @@ type JobRecord struct {
 	JobID                 string                `json:"job_id"`
 	ToolName              string                `json:"tool_name"`
 	Phase                 Phase                 `json:"phase"`
+	Stage                 string                `json:"stage,omitempty"` // step of a multi-stage job, e.g. "discovery"
 	CreatedAt             time.Time             `json:"created_at"`
 	StartedAt             time.Time             `json:"started_at,omitempty"`
 	UpdatedAt             time.Time             `json:"updated_at"`
@@ -41,8 +48,22 @@ type JobRecord struct {
 	ResultPrefix          string                `json:"result_prefix,omitempty"`
 	ArtifactPrefix        string                `json:"artifact_prefix,omitempty"`
 	RuntimeTarget         string                `json:"runtime_target,omitempty"`
+	ScopeDigest           string                `json:"scope_digest,omitempty"`
 	LastError             string                `json:"last_error,omitempty"`
+	Budget                *Budget               `json:"budget,omitempty"`
+	Window                *schedule.Window      `json:"window,omitempty"`
+	GlobalRate            int                   `json:"global_rate,omitempty"` // combined requests per second across workers
+	Politeness            *politeness.Limits    `json:"politeness,omitempty"`  // per-target limits across workers
+	WordlistTransform     *wordlist.Transform   `json:"wordlist_transform,omitempty"`
+	Wordlists             map[string]string     `json:"wordlists,omitempty"`   // named wordlists shipped whole, by local path
+	Calibration           *Calibration          `json:"calibration,omitempty"` // adaptive chunk sizing
+	TemplateShards        *nuclei.ShardPlan     `json:"template_shards,omitempty"`
+	Assets                *jobs.AssetBundle     `json:"assets,omitempty"` // asset bundle shipped to every task
+	WildcardProbe         bool                  `json:"wildcard_probe,omitempty"`
+	Wildcards             dns.Wildcards         `json:"wildcards,omitempty"` // wildcard DNS answers by domain
 	LocalOutputDir        string                `json:"local_output_dir,omitempty"`
+	SourceJobID           string                `json:"source_job_id,omitempty"`     // job whose results seeded this one
+	FollowUpJobIDs        []string              `json:"follow_up_job_ids,omitempty"` // jobs seeded from this one's results
 	Placement             fleet.PlacementPolicy `json:"placement,omitempty"`
 	ExpectedWorkerVersion string                `json:"expected_worker_version,omitempty"`
 
@@ -126,6 +147,32 @@ func (s *JobStore) Update(rec *JobRecord) error {
 	return s.write(rec)
 }
 
+// Link records that followUpID was seeded from the results of sourceID, on
+// both job records. The follow-up record must already exist; a missing
+// source record is not an error, since it may come from another machine.
+func (s *JobStore) Link(sourceID, followUpID string) error {
+	follow, err := s.Load(followUpID)
+	if err != nil {
+		return err
+	}
+	follow.SourceJobID = sourceID
+	if err := s.Update(follow); err != nil {
+		return err
+	}
+
+	source, err := s.Load(sourceID)
+	if err != nil {
+		return nil
+	}
+	for _, id := range source.FollowUpJobIDs {
+		if id == followUpID {
+			return nil
+		}
+	}
+	source.FollowUpJobIDs = append(source.FollowUpJobIDs, followUpID)
+	return s.Update(source)
+}
+
 // List returns all stored job IDs (most recent first is not guaranteed;
 // callers should sort by CreatedAt if needed).
 func (s *JobStore) List() ([]string, error) {
@@ -256,3 +256,33 @@ func TestJobRecord_CloudUpdatePersists(t *testing.T) {
 		t.Errorf("cloud = %q, want selfhosted", loaded.Cloud)
 	}
 }
+
+func TestJobStore_Link(t *testing.T) {
+	store := NewJobStoreAt(t.TempDir())
+	for _, id := range []string{"masscan-1", "nmap-1"} {
+		if err := store.Create(&JobRecord{JobID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := store.Link("masscan-1", "nmap-1"); err != nil {
+			t.Fatalf("link: %v", err)
+		}
+	}
+	follow, _ := store.Load("nmap-1")
+	if follow.SourceJobID != "masscan-1" {
+		t.Errorf("source_job_id = %q, want masscan-1", follow.SourceJobID)
+	}
+	source, _ := store.Load("masscan-1")
+	if len(source.FollowUpJobIDs) != 1 || source.FollowUpJobIDs[0] != "nmap-1" {
+		t.Errorf("follow_up_job_ids = %v, want [nmap-1]", source.FollowUpJobIDs)
+	}
+
+	if err := store.Link("elsewhere", "nmap-1"); err != nil {
+		t.Errorf("missing source record should not fail: %v", err)
+	}
+	if err := store.Link("masscan-1", "missing"); err == nil {
+		t.Error("expected error for a missing follow-up record")
+	}
+}
@@ -0,0 +1,212 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"heph4estus/internal/tools/dns"
+	"heph4estus/internal/tools/nmap"
+	"heph4estus/internal/tools/nuclei"
+)
+
+// NucleiFindingsFile is the name of a merged nuclei findings report.
+const NucleiFindingsFile = "findings.jsonl"
+
+// DNSAnswersFile is the name of a merged DNS report with wildcard answers
+// filtered out, and WildcardsFile lists the wildcard answers by domain.
+const (
+	DNSAnswersFile = "resolved.jsonl"
+	WildcardsFile  = "wildcards.json"
+)
+
+// MergeResult summarises a merge of nmap chunk reports, nuclei shard
+// reports or DNS reports.
+type MergeResult struct {
+	Dir     string   // directory the merged reports were written to
+	Reports int      // chunk reports merged
+	Targets int      // per-target reports written, per format (nmap)
+	Files   []string // every file written
+	Skipped []string // chunk reports that did not parse
+
+	Findings   int // distinct findings written (nuclei)
+	Duplicates int // findings dropped as repeats across shards (nuclei)
+
+	Answers   int           // answers written (DNS)
+	Wildcard  int           // answers dropped as wildcard noise (DNS)
+	Wildcards dns.Wildcards // wildcard answers the probes found (DNS)
+}
+
+// MergeNmapReports merges the nmap XML chunk reports in reports, keyed by
+// artifact key, into one report per target and one for the whole job, and
+// writes them under outDir in each of formats. Nothing is written when no
+// report parses.
+func MergeNmapReports(reports map[string][]byte, outDir string, formats []string) (*MergeResult, error) {
+	job, perTarget, skipped := nmap.MergeReports(reports)
+	res := &MergeResult{
+		Dir:     outDir,
+		Reports: len(reports) - len(skipped),
+		Targets: len(perTarget),
+	}
+	for key := range skipped {
+		res.Skipped = append(res.Skipped, key)
+	}
+	sort.Strings(res.Skipped)
+	if job == nil {
+		return res, nil
+	}
+	files, err := nmap.WriteMerged(outDir, job, perTarget, formats)
+	res.Files = files
+	if err != nil {
+		return res, fmt.Errorf("writing merged reports: %w", err)
+	}
+	return res, nil
+}
+
+// MergeNmapExport merges the nmap XML artifacts of an exported job directory
+// (see ExportJob) into outDir, or <jobDir>/merged when outDir is empty. It
+// returns nil when the directory holds no reports.
+func MergeNmapExport(jobDir, outDir string, formats []string) (*MergeResult, error) {
+	reports, err := readFiles(filepath.Join(jobDir, "artifacts"), ".xml")
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, nil
+	}
+	if outDir == "" {
+		outDir = filepath.Join(jobDir, "merged")
+	}
+	return MergeNmapReports(reports, outDir, formats)
+}
+
+// MergeNucleiReports merges the nuclei JSONL reports in reports, keyed by
+// artifact key, into one findings file under outDir, dropping findings that
+// several template shards reported. Nothing is written when no report
+// holds a finding.
+func MergeNucleiReports(reports map[string][]byte, outDir string) (*MergeResult, error) {
+	merged, stats := nuclei.MergeFindings(reports)
+	res := &MergeResult{
+		Dir:        outDir,
+		Reports:    stats.Reports,
+		Findings:   stats.Findings,
+		Duplicates: stats.Duplicates,
+	}
+	if stats.Findings == 0 {
+		return res, nil
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return res, fmt.Errorf("creating %s: %w", outDir, err)
+	}
+	path := filepath.Join(outDir, NucleiFindingsFile)
+	if err := os.WriteFile(path, merged, 0o644); err != nil {
+		return res, fmt.Errorf("writing merged findings: %w", err)
+	}
+	res.Files = []string{path}
+	return res, nil
+}
+
+// MergeNucleiExport merges the nuclei JSONL artifacts of an exported job
+// directory into outDir, or <jobDir>/merged when outDir is empty. It
+// returns nil when the directory holds no reports.
+func MergeNucleiExport(jobDir, outDir string) (*MergeResult, error) {
+	reports, err := readFiles(filepath.Join(jobDir, "artifacts"), ".jsonl")
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, nil
+	}
+	if outDir == "" {
+		outDir = filepath.Join(jobDir, "merged")
+	}
+	return MergeNucleiReports(reports, outDir)
+}
+
+// MergeDNSReports merges the dnsx or massdns JSONL reports in reports,
+// keyed by artifact key, into one answers file under outDir. The wildcard
+// probe reports among them decide which answers are wildcard noise; those
+// are dropped and the wildcards are written alongside.
+func MergeDNSReports(reports map[string][]byte, outDir string) (*MergeResult, error) {
+	w := dns.DetectWildcards(reports)
+	merged, stats := dns.FilterReports(reports, w)
+	res := &MergeResult{
+		Dir:       outDir,
+		Reports:   stats.Reports,
+		Answers:   stats.Answers,
+		Wildcard:  stats.Wildcard,
+		Wildcards: w,
+	}
+	if stats.Answers == 0 && len(w) == 0 {
+		return res, nil
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return res, fmt.Errorf("creating %s: %w", outDir, err)
+	}
+	path := filepath.Join(outDir, DNSAnswersFile)
+	if err := os.WriteFile(path, merged, 0o644); err != nil {
+		return res, fmt.Errorf("writing merged answers: %w", err)
+	}
+	res.Files = []string{path}
+	if len(w) > 0 {
+		data, err := json.MarshalIndent(w, "", "  ")
+		if err != nil {
+			return res, fmt.Errorf("encoding wildcards: %w", err)
+		}
+		path := filepath.Join(outDir, WildcardsFile)
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return res, fmt.Errorf("writing wildcards: %w", err)
+		}
+		res.Files = append(res.Files, path)
+	}
+	return res, nil
+}
+
+// MergeDNSExport merges the JSONL artifacts of an exported DNS job
+// directory into outDir, or <jobDir>/merged when outDir is empty. It
+// returns nil when the directory holds no reports.
+func MergeDNSExport(jobDir, outDir string) (*MergeResult, error) {
+	reports, err := readFiles(filepath.Join(jobDir, "artifacts"), ".jsonl")
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, nil
+	}
+	if outDir == "" {
+		outDir = filepath.Join(jobDir, "merged")
+	}
+	return MergeDNSReports(reports, outDir)
+}
+
+// readFiles reads every file with extension ext under dir, keyed by its
+// path relative to dir.
+func readFiles(dir, ext string) (map[string][]byte, error) {
+	reports := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ext) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		rel, _ := filepath.Rel(dir, path)
+		reports[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading reports in %s: %w", dir, err)
+	}
+	return reports, nil
+}
@@ -0,0 +1,249 @@
+// Package politeness limits how hard the fleet works any one target. All
+// workers share one record per target host in a cloud.KV: a token bucket
+// that paces task starts, and a set of leases that caps the tasks running
+// at once. A worker takes a lease before it runs a task and hands the task
+// back to the queue, delayed, while the target is saturated.
+package politeness
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"heph4estus/internal/cloud"
+)
+
+// DefaultStore is the KV store workers keep target records in when their
+// launch environment names none.
+const DefaultStore = "heph-leases"
+
+// Bounds on how long a saturated task waits before its next attempt.
+const (
+	MinRetryDelay = time.Second
+	MaxRetryDelay = 5 * time.Minute
+	// busyRetryDelay is the wait when every lease is held. Leases are
+	// normally released well before they expire, so retry sooner.
+	busyRetryDelay = 30 * time.Second
+)
+
+// maxAttempts bounds the compare-and-swap retries of one update.
+const maxAttempts = 10
+
+// Limits caps how hard the fleet works one target.
+type Limits struct {
+	// Concurrency caps the tasks running against one target at once.
+	Concurrency int `json:"concurrency,omitempty"`
+	// Rate caps task starts against one target per minute.
+	Rate float64 `json:"rate,omitempty"`
+}
+
+// IsZero reports whether no limit is set.
+func (l Limits) IsZero() bool { return l.Concurrency == 0 && l.Rate == 0 }
+
+// Validate rejects negative limits.
+func (l Limits) Validate() error {
+	if l.Concurrency < 0 {
+		return fmt.Errorf("per-target concurrency must not be negative")
+	}
+	if l.Rate < 0 || math.IsNaN(l.Rate) || math.IsInf(l.Rate, 0) {
+		return fmt.Errorf("per-target rate must be a positive number")
+	}
+	return nil
+}
+
+// String renders the limits for status output, e.g. "2 concurrent, 6/min".
+func (l Limits) String() string {
+	var parts []string
+	if l.Concurrency > 0 {
+		parts = append(parts, fmt.Sprintf("%d concurrent", l.Concurrency))
+	}
+	if l.Rate > 0 {
+		parts = append(parts, fmt.Sprintf("%g/min", l.Rate))
+	}
+	if len(parts) == 0 {
+		return "unlimited"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// burst is how many task starts the bucket holds when full.
+func (l Limits) burst() float64 {
+	return float64(max(l.Concurrency, 1))
+}
+
+// Host returns the host a target runs against: the host of a URL or
+// host:port, or the target itself for a bare host, address or CIDR.
+func Host(target string) string {
+	t := strings.TrimSpace(target)
+	if strings.Contains(t, "://") {
+		if u, err := url.Parse(t); err == nil && u.Hostname() != "" {
+			t = u.Hostname()
+		}
+	} else if h, _, err := net.SplitHostPort(t); err == nil {
+		t = h
+	}
+	t = strings.Trim(t, "[]")
+	return strings.TrimSuffix(strings.ToLower(t), ".")
+}
+
+// Key returns the KV key of a target's record. Hosts are encoded because
+// KV backends restrict key characters.
+func Key(target string) string {
+	return "target." + base64.RawURLEncoding.EncodeToString([]byte(Host(target)))
+}
+
+// record is the shared state of one target.
+type record struct {
+	Tokens  float64              `json:"tokens"`
+	Updated time.Time            `json:"updated"`
+	Leases  map[string]time.Time `json:"leases,omitempty"` // lease ID -> expiry
+}
+
+// Lease is a held slot against one target.
+type Lease struct {
+	Target string
+	key    string
+	id     string
+}
+
+// Limiter takes and releases leases in a shared KV.
+type Limiter struct {
+	kv    cloud.KV
+	owner string
+	now   func() time.Time
+}
+
+// NewLimiter returns a Limiter over kv. Lease IDs are prefixed with owner,
+// usually the worker ID, so a record shows who holds its leases.
+func NewLimiter(kv cloud.KV, owner string) *Limiter {
+	if owner == "" {
+		owner = "worker"
+	}
+	return &Limiter{kv: kv, owner: owner, now: time.Now}
+}
+
+// Acquire takes a lease on target under lim, held for at most hold. When
+// the target is saturated it returns a nil lease and how long to wait
+// before trying again.
+func (l *Limiter) Acquire(ctx context.Context, target string, lim Limits, hold time.Duration) (*Lease, time.Duration, error) {
+	lease := &Lease{Target: target, key: Key(target)}
+	if lim.Concurrency > 0 {
+		lease.id = l.leaseID()
+	}
+	var wait time.Duration
+	err := l.update(ctx, lease.key, func(rec *record, now time.Time) bool {
+		wait = 0
+		if lim.Concurrency > 0 && len(rec.Leases) >= lim.Concurrency {
+			wait = busyRetryDelay
+			for _, exp := range rec.Leases {
+				wait = min(wait, exp.Sub(now))
+			}
+		}
+		if lim.Rate > 0 && rec.Tokens < 1 {
+			need := time.Duration((1 - rec.Tokens) / lim.Rate * float64(time.Minute))
+			wait = max(wait, need)
+		}
+		if wait > 0 {
+			return false
+		}
+		if lim.Rate > 0 {
+			rec.Tokens--
+		}
+		if lease.id != "" {
+			rec.Leases[lease.id] = now.Add(hold)
+		}
+		return true
+	}, lim)
+	if err != nil {
+		return nil, 0, err
+	}
+	if wait > 0 {
+		return nil, min(max(wait, MinRetryDelay), MaxRetryDelay), nil
+	}
+	return lease, 0, nil
+}
+
+// Release gives a lease back. Releasing a lease that took no concurrency
+// slot, or one that has already expired, does nothing.
+func (l *Limiter) Release(ctx context.Context, lease *Lease) error {
+	if lease == nil || lease.id == "" {
+		return nil
+	}
+	return l.update(ctx, lease.key, func(rec *record, _ time.Time) bool {
+		if _, ok := rec.Leases[lease.id]; !ok {
+			return false
+		}
+		delete(rec.Leases, lease.id)
+		return true
+	}, Limits{})
+}
+
+// update applies fn to the record at key, refilled and pruned as of now,
+// and writes it back when fn reports a change, retrying on conflicts.
+func (l *Limiter) update(ctx context.Context, key string, fn func(*record, time.Time) bool, lim Limits) error {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		data, rev, err := l.kv.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("politeness: reading %s: %w", key, err)
+		}
+		now := l.now()
+		rec := record{Tokens: lim.burst(), Updated: now}
+		if data != nil {
+			if err := json.Unmarshal(data, &rec); err != nil {
+				return fmt.Errorf("politeness: decoding %s: %w", key, err)
+			}
+		}
+		rec.refill(lim, now)
+		if !fn(&rec, now) {
+			return nil
+		}
+		if data, err = json.Marshal(rec); err != nil {
+			return fmt.Errorf("politeness: encoding %s: %w", key, err)
+		}
+		err = l.kv.Put(ctx, key, data, rev)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, cloud.ErrKVConflict) {
+			return fmt.Errorf("politeness: writing %s: %w", key, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * 10 * time.Millisecond):
+		}
+	}
+	return fmt.Errorf("politeness: %s: gave up after %d conflicting updates", key, maxAttempts)
+}
+
+// refill adds the tokens earned since the last refill, up to the burst,
+// and drops expired leases. Without a rate the bucket is left as it is.
+func (r *record) refill(lim Limits, now time.Time) {
+	if lim.Rate > 0 && now.After(r.Updated) {
+		r.Tokens = min(r.Tokens+now.Sub(r.Updated).Minutes()*lim.Rate, lim.burst())
+		r.Updated = now
+	}
+	if r.Leases == nil {
+		r.Leases = make(map[string]time.Time)
+	}
+	for id, exp := range r.Leases {
+		if !exp.After(now) {
+			delete(r.Leases, id)
+		}
+	}
+}
+
+func (l *Limiter) leaseID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return l.owner + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,179 @@
+package politeness
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"heph4estus/internal/cloud"
+)
+
+// memKV is an in-memory cloud.KV.
+type memKV struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	revs map[string]uint64
+}
+
+func newMemKV() *memKV {
+	return &memKV{data: map[string][]byte{}, revs: map[string]uint64{}}
+}
+
+func (m *memKV) Get(_ context.Context, key string) ([]byte, uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[key], m.revs[key], nil
+}
+
+func (m *memKV) Put(_ context.Context, key string, value []byte, revision uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.revs[key] != revision {
+		return cloud.ErrKVConflict
+	}
+	m.data[key] = value
+	m.revs[key]++
+	return nil
+}
+
+func newTestLimiter(kv cloud.KV, now *time.Time) *Limiter {
+	l := NewLimiter(kv, "w1")
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestHost(t *testing.T) {
+	tests := map[string]string{
+		"https://Example.com/FUZZ":  "example.com",
+		"http://10.0.0.1:8080/path": "10.0.0.1",
+		"example.com:443":           "example.com",
+		"[2001:db8::1]:80":          "2001:db8::1",
+		"10.0.0.0/24":               "10.0.0.0/24",
+		" Example.COM. ":            "example.com",
+	}
+	for in, want := range tests {
+		if got := Host(in); got != want {
+			t.Errorf("Host(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if Key("https://example.com/a") != Key("example.com:8443") {
+		t.Error("targets on the same host should share a key")
+	}
+}
+
+func TestAcquire_Concurrency(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	kv := newMemKV()
+	l := newTestLimiter(kv, &now)
+	ctx := context.Background()
+	lim := Limits{Concurrency: 2}
+
+	a, _, err := l.Acquire(ctx, "example.com", lim, time.Hour)
+	if err != nil || a == nil {
+		t.Fatalf("first Acquire = %v, %v", a, err)
+	}
+	b, _, _ := l.Acquire(ctx, "https://example.com/x", lim, time.Hour)
+	if b == nil {
+		t.Fatal("second Acquire should succeed")
+	}
+	c, wait, _ := l.Acquire(ctx, "example.com", lim, time.Hour)
+	if c != nil || wait != busyRetryDelay {
+		t.Fatalf("third Acquire = %v, wait %s; want saturated with %s", c, wait, busyRetryDelay)
+	}
+	if other, _, _ := l.Acquire(ctx, "other.example", lim, time.Hour); other == nil {
+		t.Fatal("another target should not be limited")
+	}
+
+	if err := l.Release(ctx, a); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if c, _, _ = l.Acquire(ctx, "example.com", lim, time.Hour); c == nil {
+		t.Fatal("Acquire after Release should succeed")
+	}
+}
+
+func TestAcquire_ExpiredLeasesFreeSlots(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newTestLimiter(newMemKV(), &now)
+	ctx := context.Background()
+	lim := Limits{Concurrency: 1}
+
+	if a, _, _ := l.Acquire(ctx, "example.com", lim, 10*time.Second); a == nil {
+		t.Fatal("first Acquire should succeed")
+	}
+	if _, wait, _ := l.Acquire(ctx, "example.com", lim, 10*time.Second); wait != 10*time.Second {
+		t.Fatalf("wait = %s, want the lease's remaining 10s", wait)
+	}
+	now = now.Add(11 * time.Second)
+	if b, _, _ := l.Acquire(ctx, "example.com", lim, 10*time.Second); b == nil {
+		t.Fatal("Acquire after the lease expired should succeed")
+	}
+}
+
+func TestAcquire_Rate(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newTestLimiter(newMemKV(), &now)
+	ctx := context.Background()
+	lim := Limits{Rate: 6} // one start every 10s
+
+	if a, _, _ := l.Acquire(ctx, "example.com", lim, time.Hour); a == nil {
+		t.Fatal("first Acquire should succeed")
+	}
+	_, wait, _ := l.Acquire(ctx, "example.com", lim, time.Hour)
+	if wait != 10*time.Second {
+		t.Fatalf("wait = %s, want 10s", wait)
+	}
+	now = now.Add(5 * time.Second)
+	if _, wait, _ = l.Acquire(ctx, "example.com", lim, time.Hour); wait != 5*time.Second {
+		t.Fatalf("wait = %s, want 5s", wait)
+	}
+	now = now.Add(5 * time.Second)
+	if b, _, _ := l.Acquire(ctx, "example.com", lim, time.Hour); b == nil {
+		t.Fatal("Acquire after refill should succeed")
+	}
+}
+
+func TestAcquire_SharedAcrossWorkers(t *testing.T) {
+	kv := newMemKV()
+	ctx := context.Background()
+	lim := Limits{Concurrency: 3}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		held int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, _, err := NewLimiter(kv, "w").Acquire(ctx, "example.com", lim, time.Hour)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if lease != nil {
+				mu.Lock()
+				held++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if held != 3 {
+		t.Fatalf("leases held = %d, want 3", held)
+	}
+}
+
+func TestLimitsValidateAndString(t *testing.T) {
+	if err := (Limits{Concurrency: -1}).Validate(); err == nil {
+		t.Error("negative concurrency should be rejected")
+	}
+	if err := (Limits{Rate: -2}).Validate(); err == nil {
+		t.Error("negative rate should be rejected")
+	}
+	if got := (Limits{Concurrency: 2, Rate: 6}).String(); got != "2 concurrent, 6/min" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/tools/wordlist"
+	"heph4estus/internal/worker"
+)
+
+// CalibrationEntries is how many wordlist entries each calibration chunk
+// holds. The sample has one chunk per worker.
+const CalibrationEntries = 200
+
+// calibration is the state of an adaptively sized wordlist job between
+// planning its sample and planning the remainder.
+type calibration struct {
+	cfg          jobs.JobConfig
+	transform    *wordlist.Transform
+	target       time.Duration
+	totalWords   int
+	totalBytes   int64
+	sampleWords  int
+	sampleChunks int
+	tempDir      string
+}
+
+func (c *calibration) record() *operator.Calibration {
+	return &operator.Calibration{
+		TargetTaskDuration: c.target,
+		SampleChunks:       c.sampleChunks,
+		SampleEntries:      c.sampleWords,
+	}
+}
+
+// planCalibration plans the sample slice of an adaptively sized wordlist:
+// one chunk of CalibrationEntries per worker. It returns a nil plan when
+// the wordlist is too small to be worth calibrating.
+func (r *Runner) planCalibration(cfg jobs.JobConfig, jobID, tempDir string, target time.Duration) (*jobs.WordlistPlan, *calibration, error) {
+	if cfg.ChunkCount > 0 {
+		return nil, nil, fmt.Errorf("a task duration replaces the chunk count; set only one")
+	}
+	transform := cfg.WordlistTransform
+	meta, err := wordlist.InspectFile(cfg.WordlistPath, wordlist.Policy{Transform: transform})
+	if err != nil {
+		return nil, nil, err
+	}
+	chunks := r.cfg.Workers
+	sample := chunks * CalibrationEntries
+	if meta.TotalWords < 2*sample {
+		r.logf("Wordlist has %d entries, too few to calibrate on %d; sizing chunks by bytes", meta.TotalWords, sample)
+		return nil, nil, nil
+	}
+	wl, err := jobs.PlanWordlistSlice(cfg.ToolName, jobID, cfg.RuntimeTarget, cfg.Options, cfg.WordlistPath, tempDir, 0, sample, chunks, 0, transform)
+	if err != nil {
+		return nil, nil, err
+	}
+	return wl, &calibration{
+		cfg:          cfg,
+		transform:    transform,
+		target:       target,
+		totalWords:   meta.TotalWords,
+		totalBytes:   meta.EntryBytes(),
+		sampleWords:  wl.TotalWords,
+		sampleChunks: len(wl.Tasks),
+		tempDir:      tempDir,
+	}, nil
+}
+
+// finishCalibration waits for the sample chunks, measures the time per
+// entry, enqueues the rest of the wordlist in chunks sized to the target
+// task duration and launches workers to run them.
+func (r *Runner) finishCalibration(ctx context.Context, plan *Plan) error {
+	c := plan.calibration
+	r.logf("Calibrating: %d sample chunks of %d entries, target %s per task", c.sampleChunks, CalibrationEntries, c.target)
+	started := time.Now()
+	last := -1
+	if err := r.Wait(ctx, plan.ToolName, plan.JobID, len(plan.Tasks), func(p Progress) {
+		if p.Completed != last {
+			last = p.Completed
+			r.logf("Calibration: %d/%d sample chunks done", p.Completed, p.Total)
+		}
+	}); err != nil {
+		return fmt.Errorf("calibrating chunk size: %w", err)
+	}
+	perEntry, source := r.measurePerEntry(ctx, plan, time.Since(started))
+
+	remaining := c.totalWords - c.sampleWords
+	chunks := adaptiveChunkCount(remaining, c.totalBytes*int64(remaining)/int64(c.totalWords), perEntry, c.target, r.cfg.Workers)
+	rest, err := jobs.PlanWordlistSlice(c.cfg.ToolName, plan.JobID, c.cfg.RuntimeTarget, c.cfg.Options, c.cfg.WordlistPath, c.tempDir, c.sampleWords, 0, chunks, len(plan.Tasks), c.transform)
+	if err != nil {
+		return fmt.Errorf("planning remaining chunks: %w", err)
+	}
+	defer func() {
+		if err := rest.Cleanup(); err != nil {
+			r.logf("Warning: failed to clean temporary wordlist chunks: %v", err)
+		}
+	}()
+	inheritTaskSettings(rest.Tasks, plan.Tasks[0])
+
+	now := time.Now().UTC()
+	cal := c.record()
+	cal.PerEntry = perEntry
+	cal.Source = source
+	cal.Chunks = rest.EffectiveChunks
+	cal.EntriesPerChunk = (rest.TotalWords + rest.EffectiveChunks - 1) / rest.EffectiveChunks
+	cal.CalibratedAt = &now
+	r.logf("Calibrated: %s/entry from %s; splitting %d remaining entries into %d chunks of about %d",
+		perEntry, source, rest.TotalWords, cal.Chunks, cal.EntriesPerChunk)
+
+	if err := r.Upload(ctx, rest); err != nil {
+		return fmt.Errorf("uploading wordlist chunks: %w", err)
+	}
+	if err := r.Enqueue(ctx, rest.Tasks); err != nil {
+		return fmt.Errorf("enqueueing chunks: %w", err)
+	}
+	plan.Tasks = append(plan.Tasks, rest.Tasks...)
+	r.logf("Enqueued %d more chunks (%d total)", len(rest.Tasks), len(plan.Tasks))
+	if store := r.cfg.Tracker.Store(); store != nil {
+		if rec, err := store.Load(plan.JobID); err == nil {
+			rec.TotalTasks = len(plan.Tasks)
+			rec.Calibration = cal
+			_ = store.Update(rec)
+		}
+	}
+	// The sample's workers exit once the queue drains, which it did while
+	// the remainder was measured and split; launch a fresh set for it.
+	if _, err := r.Launch(ctx, plan.ToolName); err != nil {
+		return fmt.Errorf("launching workers for remaining chunks: %w", err)
+	}
+	return nil
+}
+
+// adaptiveChunkCount sizes the remaining entries so each chunk runs for
+// about target, keeping every worker busy and every chunk under the safe
+// size limit.
+func adaptiveChunkCount(entries int, entryBytes int64, perEntry, target time.Duration, workers int) int {
+	perChunk := max(int(target/perEntry), 1)
+	chunks := (entries + perChunk - 1) / perChunk
+	chunks = max(chunks, workers)
+	// Leave headroom: chunks are cut by entry, not by byte.
+	minBySize := int((entryBytes*11/10 + wordlist.MaxSafeChunkSize - 1) / wordlist.MaxSafeChunkSize)
+	chunks = max(chunks, minBySize)
+	return max(min(chunks, entries), 1)
+}
+
+// measurePerEntry returns the time per wordlist entry over the sample.
+// Worker-reported durations are preferred; results from older workers fall
+// back to the sample's wall-clock time spread across the workers.
+func (r *Runner) measurePerEntry(ctx context.Context, plan *Plan, elapsed time.Duration) (time.Duration, string) {
+	c := plan.calibration
+	words := make(map[int]int, len(plan.Wordlist.ChunkFiles))
+	for _, chunk := range plan.Wordlist.ChunkFiles {
+		words[chunk.Index] = chunk.WordCount
+	}
+	storage := r.cfg.Provider.Storage()
+	keys, err := storage.List(ctx, r.cfg.Bucket, jobs.ResultPrefix(plan.ToolName, plan.JobID))
+	if err != nil {
+		r.logf("Warning: listing calibration results: %v", err)
+	}
+	var (
+		total   time.Duration
+		entries int
+		seen    = make(map[int]bool)
+	)
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		data, err := storage.Download(ctx, r.cfg.Bucket, key)
+		if err != nil {
+			continue
+		}
+		var res worker.Result
+		if json.Unmarshal(data, &res) != nil || res.DurationMs <= 0 || seen[res.ChunkIdx] {
+			continue
+		}
+		n, ok := words[res.ChunkIdx]
+		if !ok {
+			continue
+		}
+		seen[res.ChunkIdx] = true
+		total += time.Duration(res.DurationMs) * time.Millisecond
+		entries += n
+	}
+	if entries > 0 {
+		return max(total/time.Duration(entries), time.Microsecond), "results"
+	}
+	parallel := min(r.cfg.Workers, c.sampleChunks)
+	return max(elapsed*time.Duration(parallel)/time.Duration(c.sampleWords), time.Microsecond), "wall-clock"
+}
+
+// inheritTaskSettings copies the job-wide settings Plan and Start stamp on
+// tasks onto tasks planned later.
+func inheritTaskSettings(tasks []worker.Task, from worker.Task) {
+	for i := range tasks {
+		tasks[i].Scope = from.Scope
+		tasks[i].Window = from.Window
+		tasks[i].GlobalRate = from.GlobalRate
+		tasks[i].Rate = from.Rate
+		tasks[i].Politeness = from.Politeness
+		tasks[i].Wordlists = from.Wordlists
+		tasks[i].Assets = from.Assets
+	}
+}
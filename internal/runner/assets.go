@@ -0,0 +1,18 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"heph4estus/internal/jobs"
+)
+
+// uploadAssets uploads the packed bundle tasks extract.
+func (r *Runner) uploadAssets(ctx context.Context, assets *jobs.AssetPlan) error {
+	ctx, cancel := context.WithTimeout(ctx, EnqueueTimeout)
+	defer cancel()
+	if err := r.cfg.Provider.Storage().Upload(ctx, r.cfg.Bucket, assets.Key, assets.Data); err != nil {
+		return fmt.Errorf("uploading %s assets to %s: %w", assets.Bundle.Name, assets.Key, err)
+	}
+	return nil
+}
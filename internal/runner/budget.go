@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/estimate"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/operator"
+)
+
+// BudgetUsage estimates what workers have consumed after running for
+// elapsed, priced at the default rate of kind and the resolved compute mode.
+func BudgetUsage(kind cloud.Kind, computeMode string, workers int, elapsed time.Duration) operator.Usage {
+	u := operator.Usage{Elapsed: elapsed, WorkerHours: elapsed.Hours() * float64(workers)}
+	mode := ""
+	if kind.Canonical() == cloud.KindAWS {
+		mode = "fargate"
+		if UseSpot(kind, computeMode, workers) {
+			mode = "spot"
+		}
+	}
+	if rate, ok := estimate.RateFor(kind, mode); ok {
+		u.SpendUSD = rate.Spend(workers, elapsed)
+	}
+	return u
+}
+
+// usage measures consumption from worker launch, or from since when this
+// runner did not launch the workers itself.
+func (r *Runner) usage(since time.Time) operator.Usage {
+	if !r.launchedAt.IsZero() {
+		since = r.launchedAt
+	}
+	workers := r.workersUp
+	if workers <= 0 {
+		workers = r.cfg.Workers
+	}
+	return BudgetUsage(r.cfg.Cloud, r.cfg.ComputeMode, workers, time.Since(since))
+}
+
+// abort cancels the job after a budget breach: it marks the job's queued
+// tasks for workers to drop, stops the workers and records reason on the
+// job. A provider-native fleet is scaled down unless the budget destroys
+// the infrastructure anyway.
+func (r *Runner) abort(ctx context.Context, tool, jobID string, budget operator.Budget, reason error) error {
+	r.logf("%v; stopping workers", reason)
+	if err := r.Cancel(ctx, tool, jobID, reason); err != nil {
+		r.logf("Warning: %v", err)
+	}
+	if r.cfg.Cloud.IsProviderNative() {
+		if !budget.Destroy {
+			if err := r.StopFleet(ctx, tool); err != nil {
+				r.logf("Warning: failed to stop the fleet: %v", err)
+			}
+		}
+	} else if err := r.StopWorkers(ctx); err != nil {
+		r.logf("Warning: failed to stop workers: %v", err)
+	}
+	_ = r.cfg.Tracker.Fail(jobID, reason)
+	return reason
+}
+
+// Cancel marks a job cancelled so workers drop its remaining tasks instead
+// of running them. The tasks stay on the queue, which other jobs may share,
+// until a worker receives and drops them.
+func (r *Runner) Cancel(ctx context.Context, tool, jobID string, reason error) error {
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), EnqueueTimeout)
+	defer cancel()
+	if err := r.cfg.Provider.Storage().Upload(cancelCtx, r.cfg.Bucket, jobs.CancelKey(tool, jobID), []byte(reason.Error())); err != nil {
+		return fmt.Errorf("marking job %s cancelled: %w", jobID, err)
+	}
+	return nil
+}
+
+// StopFleet scales a provider-native fleet down with Config.StopFleet.
+func (r *Runner) StopFleet(ctx context.Context, tool string) error {
+	if r.cfg.StopFleet == nil {
+		return fmt.Errorf("%s workers are a standing fleet and no fleet stopper is configured; destroy the infrastructure to stop spend", r.cfg.Cloud.Canonical())
+	}
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), LaunchTimeout)
+	defer cancel()
+	if err := r.cfg.StopFleet(stopCtx, r.cfg.Cloud, tool); err != nil {
+		return err
+	}
+	r.logf("Stopped the %s fleet", r.cfg.Cloud.Canonical())
+	return nil
+}
+
+// StopWorkers stops the workers this runner launched. Provider-native
+// fleets are standing infrastructure; use StopFleet for them.
+func (r *Runner) StopWorkers(ctx context.Context) error {
+	if r.cfg.Cloud.IsProviderNative() {
+		return fmt.Errorf("%s workers are a standing fleet and cannot be stopped per job", r.cfg.Cloud.Canonical())
+	}
+	if len(r.launchedIDs) == 0 {
+		return nil
+	}
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), LaunchTimeout)
+	defer cancel()
+	if err := cloud.StopWorkers(stopCtx, r.cfg.Provider.Compute(), r.launchedIDs); err != nil {
+		return err
+	}
+	r.logf("Stopped %d workers", len(r.launchedIDs))
+	return nil
+}
+
+// SplitWorkerIDs splits the comma-separated worker IDs RunContainer returns.
+func SplitWorkerIDs(s string) []string {
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/operator"
+)
+
+func TestBudgetUsage(t *testing.T) {
+	u := BudgetUsage(cloud.KindAWS, "spot", 10, 3*time.Hour)
+	if u.WorkerHours != 30 {
+		t.Errorf("worker-hours = %v, want 30", u.WorkerHours)
+	}
+	if got, want := u.SpendUSD, 30*0.0040; got < want-1e-9 || got > want+1e-9 {
+		t.Errorf("spend = %v, want %v", got, want)
+	}
+	if u := BudgetUsage(cloud.KindLocal, "auto", 4, time.Hour); u.SpendUSD != 0 || u.WorkerHours != 4 {
+		t.Errorf("local usage = %+v", u)
+	}
+}
+
+func TestRun_BudgetExceededStopsWorkers(t *testing.T) {
+	f := newFakeCloud()
+	r, store := newTestRunner(t, f, func(c *Config) {
+		c.Budget = operator.Budget{MaxWallTime: time.Nanosecond}
+	})
+
+	out, err := r.Run(context.Background(), jobs.JobConfig{ToolName: "httpx", Targets: []byte("a\nb\n")}, "", nil)
+	if !errors.Is(err, operator.ErrBudgetExceeded) {
+		t.Fatalf("Run error = %v, want budget exceeded", err)
+	}
+	if len(f.stopped) != 1 || f.stopped[0] != "task-1" {
+		t.Errorf("stopped = %v, want [task-1]", f.stopped)
+	}
+	if reason := f.objects[jobs.CancelKey("httpx", out.JobID)]; !strings.Contains(string(reason), "budget exceeded") {
+		t.Errorf("cancel marker = %q, want the budget breach", reason)
+	}
+	rec, _ := store.Load(out.JobID)
+	if rec.Phase != operator.PhaseFailed || !strings.Contains(rec.LastError, "budget exceeded: wall time") {
+		t.Errorf("record phase = %s, last error = %q", rec.Phase, rec.LastError)
+	}
+	if rec.Budget == nil || rec.Budget.MaxWallTime != time.Nanosecond {
+		t.Errorf("record budget = %+v", rec.Budget)
+	}
+}
+
+func TestWait_IgnoresRecordBudget(t *testing.T) {
+	f := newFakeCloud()
+	r, store := newTestRunner(t, f, nil)
+	if err := store.Create(&operator.JobRecord{JobID: "job-9", ToolName: "httpx", Budget: &operator.Budget{MaxWorkerHours: 1e-9}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Launch(context.Background(), "httpx"); err != nil {
+		t.Fatalf("Launch: %v", err)
+	}
+	// The record is for display; only Config.Budget is enforced.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Wait(ctx, "httpx", "job-9", 5, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait error = %v, want the context deadline", err)
+	}
+}
+
+func TestWait_BudgetStopsProviderNativeFleet(t *testing.T) {
+	f := newFakeCloud()
+	var stopped []string
+	r, _ := newTestRunner(t, f, func(c *Config) {
+		c.Cloud = cloud.KindHetzner
+		c.Budget = operator.Budget{MaxWallTime: time.Nanosecond}
+		c.StopFleet = func(_ context.Context, kind cloud.Kind, tool string) error {
+			stopped = append(stopped, string(kind)+"/"+tool)
+			return nil
+		}
+	})
+	err := r.Wait(context.Background(), "httpx", "job-10", 5, nil)
+	if !errors.Is(err, operator.ErrBudgetExceeded) {
+		t.Fatalf("Wait error = %v, want budget exceeded", err)
+	}
+	if len(stopped) != 1 || stopped[0] != "hetzner/httpx" {
+		t.Errorf("stopped fleets = %v, want [hetzner/httpx]", stopped)
+	}
+	if _, ok := f.objects[jobs.CancelKey("httpx", "job-10")]; !ok {
+		t.Error("expected the job to be marked cancelled")
+	}
+
+	// A budget that destroys the infrastructure leaves that to the caller.
+	stopped = nil
+	r.cfg.Budget.Destroy = true
+	if err := r.Wait(context.Background(), "httpx", "job-11", 5, nil); !errors.Is(err, operator.ErrBudgetExceeded) {
+		t.Fatalf("Wait error = %v, want budget exceeded", err)
+	}
+	if len(stopped) != 0 {
+		t.Errorf("stopped fleets = %v, want none when destroying", stopped)
+	}
+}
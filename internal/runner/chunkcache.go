@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"heph4estus/internal/jobs"
+)
+
+// planWordlist splits cfg's wordlist into chunk files under tempDir. With
+// the chunk cache enabled it first looks for the same split already in the
+// bucket and, when found, plans over those chunks without splitting. With a
+// task duration it plans only the calibration sample, returned alongside.
+// The job's other named wordlists are attached whole.
+func (r *Runner) planWordlist(cfg jobs.JobConfig, jobID, tempDir string) (*jobs.WordlistPlan, *calibration, error) {
+	var (
+		wl  *jobs.WordlistPlan
+		cal *calibration
+		err error
+	)
+	if d := cfg.TaskDuration; d > 0 {
+		wl, cal, err = r.planCalibration(cfg, jobID, tempDir, d)
+	}
+	if err == nil && wl == nil {
+		wl, err = r.planChunkedWordlist(cfg, jobID, tempDir)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	named := cfg.Wordlists
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		whole, err := r.planWholeWordlist(cfg, jobID, name, named[name], tempDir)
+		if err != nil {
+			_ = wl.Cleanup()
+			return nil, nil, err
+		}
+		wl.AddWhole(name, whole)
+	}
+	return wl, cal, nil
+}
+
+func (r *Runner) planChunkedWordlist(cfg jobs.JobConfig, jobID, tempDir string) (*jobs.WordlistPlan, error) {
+	transform := cfg.WordlistTransform
+	if !r.cfg.ChunkCache {
+		return jobs.PlanWordlistFile(cfg.ToolName, jobID, cfg.RuntimeTarget, cfg.Options, cfg.WordlistPath, tempDir, cfg.ChunkCount, r.cfg.Workers, transform)
+	}
+
+	sum, _, err := HashFile(cfg.WordlistPath)
+	if err != nil {
+		return nil, err
+	}
+	if m := r.cachedManifest(jobs.ChunkCacheDigest(sum, cfg.ChunkCount, r.cfg.Workers, transform)); m != nil {
+		return jobs.PlanFromManifest(cfg.ToolName, jobID, cfg.RuntimeTarget, cfg.Options, m), nil
+	}
+	return jobs.PlanCachedWordlistFile(cfg.ToolName, jobID, cfg.RuntimeTarget, cfg.Options, cfg.WordlistPath, tempDir, cfg.ChunkCount, r.cfg.Workers, transform, sum)
+}
+
+// planWholeWordlist prepares one named wordlist that every task receives
+// whole. Cached, it is stored as a one-chunk split of the file.
+func (r *Runner) planWholeWordlist(cfg jobs.JobConfig, jobID, name, path, tempDir string) (*jobs.WordlistPlan, error) {
+	if !r.cfg.ChunkCache {
+		return jobs.PlanWholeWordlistFile(cfg.ToolName, jobID, name, path, tempDir, "")
+	}
+	sum, _, err := HashFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("wordlist %s: %w", name, err)
+	}
+	if m := r.cachedManifest(jobs.ChunkCacheDigest(sum, 1, 0, nil)); m != nil && len(m.Chunks) == 1 {
+		whole := jobs.PlanFromManifest(cfg.ToolName, jobID, "", "", m)
+		whole.Tasks = nil
+		return whole, nil
+	}
+	return jobs.PlanWholeWordlistFile(cfg.ToolName, jobID, name, path, tempDir, sum)
+}
+
+// cachedManifest returns the cached split with digest, or nil when it is
+// missing or the cache cannot be read.
+func (r *Runner) cachedManifest(digest string) *jobs.ChunkManifest {
+	ctx, cancel := context.WithTimeout(context.Background(), EnqueueTimeout)
+	defer cancel()
+	m, err := jobs.LoadChunkManifest(ctx, r.cfg.Provider.Storage(), r.cfg.Bucket, digest)
+	if err != nil {
+		r.logf("Warning: %v; splitting the wordlist again", err)
+	}
+	return m
+}
@@ -0,0 +1,219 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/scope"
+	"heph4estus/internal/tools/nmap"
+	"heph4estus/internal/worker"
+)
+
+// Stages of a discover-then-scan job, recorded on its job record.
+const (
+	StageDiscovery = "discovery"
+	StagePortScan  = "port scan"
+)
+
+// DiscoveryOutcome summarises a RunDiscovery job. TotalTasks counts both
+// stages.
+type DiscoveryOutcome struct {
+	Outcome
+	DiscoveryTasks int
+	LiveHosts      int
+	PortTasks      int
+}
+
+// RunDiscovery runs a discover-then-scan nmap job under jobID. cfg
+// carries the job's scope and assets; its tasks are set per stage. The
+// discovery tasks run first; the hosts their XML reports show up, minus
+// any outside the scope, are then port scanned in chunks of portChunks. Progress
+// is reported per stage, counting only that stage's tasks, and the stage
+// is recorded on the job. The job is left for the caller to complete.
+func (r *Runner) RunDiscovery(ctx context.Context, cfg jobs.JobConfig, jobID string, discovery []nmap.ScanTask, portChunks int, onProgress func(stage string, p Progress)) (*DiscoveryOutcome, error) {
+	out := &DiscoveryOutcome{Outcome: Outcome{JobID: jobID, TotalTasks: len(discovery)}, DiscoveryTasks: len(discovery)}
+	cfg.ToolName, cfg.Tasks = "nmap", NmapTasks(discovery)
+	plan, err := r.Plan(cfg, jobID)
+	if err != nil {
+		return out, err
+	}
+	if err := r.Start(ctx, plan); err != nil {
+		return out, err
+	}
+	out.Started = true
+	r.setStage(jobID, StageDiscovery, 0)
+
+	r.logf("Discovering live hosts...")
+	err = r.Wait(ctx, "nmap", jobID, len(discovery), func(p Progress) {
+		if onProgress != nil {
+			onProgress(StageDiscovery, p)
+		}
+	})
+	if err != nil {
+		return out, err
+	}
+
+	hosts, err := r.discoveredHosts(ctx, jobID, discovery)
+	if err != nil {
+		return out, err
+	}
+	hosts = r.inScopeHosts(hosts, cfg.Scope)
+	portTasks := nmap.NewScanner(nil).PortScanTasks(hosts, portChunks)
+	out.LiveHosts = len(hosts)
+	r.logf("Discovery complete: %d live hosts", len(hosts))
+	if len(portTasks) == 0 {
+		r.logf("No live hosts found; skipping the port scan")
+		return out, nil
+	}
+
+	cfg.Tasks = NmapTasks(portTasks)
+	plan, err = r.Plan(cfg, jobID)
+	if err != nil {
+		return out, err
+	}
+	if err := r.Start(ctx, plan); err != nil {
+		return out, err
+	}
+	// Discovery results stay under the job's result prefix, so progress
+	// counts both stages.
+	out.PortTasks = len(portTasks)
+	out.TotalTasks += len(portTasks)
+	r.setStage(jobID, StagePortScan, out.TotalTasks)
+
+	r.logf("Scanning %d live hosts (%d tasks)...", len(hosts), len(portTasks))
+	return out, r.Wait(ctx, "nmap", jobID, out.TotalTasks, func(p Progress) {
+		if onProgress != nil {
+			p.Completed = max(p.Completed-len(discovery), 0)
+			p.Total = len(portTasks)
+			onProgress(StagePortScan, p)
+		}
+	})
+}
+
+// discoveredHosts reads the discovery results of jobID and returns the
+// hosts their XML reports found up, each with the port options of the
+// discovery task that found it.
+func (r *Runner) discoveredHosts(ctx context.Context, jobID string, discovery []nmap.ScanTask) ([]nmap.LiveHost, error) {
+	portOptions := make(map[string]string, len(discovery))
+	for _, t := range discovery {
+		portOptions[t.Target] = t.PortOptions
+	}
+
+	storage := r.cfg.Provider.Storage()
+	prefix := jobs.ResultPrefix("nmap", jobID) + nmap.DiscoveryGroup + "/"
+	keys, err := storage.List(ctx, r.cfg.Bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing discovery results: %w", err)
+	}
+	var hosts []nmap.LiveHost
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		data, err := storage.Download(ctx, r.cfg.Bucket, key)
+		if err != nil {
+			r.logf("Warning: failed to download %s: %v", key, err)
+			continue
+		}
+		var result worker.Result
+		if err := json.Unmarshal(data, &result); err != nil {
+			r.logf("Warning: failed to parse %s: %v", key, err)
+			continue
+		}
+		if result.OutputKey == "" {
+			r.logf("Warning: discovery of %s produced no report: %s", result.Target, result.Error)
+			continue
+		}
+		report, err := storage.Download(ctx, r.cfg.Bucket, result.OutputKey)
+		if err != nil {
+			r.logf("Warning: failed to download %s: %v", result.OutputKey, err)
+			continue
+		}
+		up, err := nmap.UpHosts(report)
+		if err != nil {
+			r.logf("Warning: %s: %v", result.OutputKey, err)
+			continue
+		}
+		for _, addr := range up {
+			hosts = append(hosts, nmap.LiveHost{Address: addr, Options: portOptions[result.Target]})
+		}
+	}
+	return hosts, nil
+}
+
+// inScopeHosts drops discovered hosts outside the engagement scope, such as
+// an excluded address inside an in-scope block.
+func (r *Runner) inScopeHosts(hosts []nmap.LiveHost, sc *scope.Scope) []nmap.LiveHost {
+	if sc == nil {
+		return hosts
+	}
+	kept := hosts[:0]
+	for _, h := range hosts {
+		if err := sc.Check(h.Address); err != nil {
+			r.logf("Skipping %s: %v", h.Address, err)
+			continue
+		}
+		kept = append(kept, h)
+	}
+	return kept
+}
+
+// setStage records the current stage of a multi-stage job and, when total
+// is positive, its new task total.
+func (r *Runner) setStage(jobID, stage string, total int) {
+	store := r.cfg.Tracker.Store()
+	if store == nil {
+		return
+	}
+	rec, err := store.Load(jobID)
+	if err != nil {
+		return
+	}
+	rec.Stage = stage
+	if total > 0 {
+		rec.TotalTasks = total
+	}
+	_ = store.Update(rec)
+}
+
+// NmapTasks converts nmap scan tasks to queue tasks. A discovery task's
+// port options travel in its metadata.
+func NmapTasks(tasks []nmap.ScanTask) []worker.Task {
+	out := make([]worker.Task, len(tasks))
+	for i, t := range tasks {
+		out[i] = worker.Task{
+			ToolName:    "nmap",
+			JobID:       t.JobID,
+			Target:      t.Target,
+			Options:     t.Options,
+			GroupID:     t.GroupID,
+			ChunkIdx:    t.ChunkIdx,
+			TotalChunks: t.TotalChunks,
+		}
+		if t.PortOptions != "" {
+			out[i].Metadata = map[string]string{nmap.PortOptionsKey: t.PortOptions}
+		}
+	}
+	return out
+}
+
+// NmapScanTasks converts queue tasks, such as those of a saved plan, back
+// to nmap scan tasks.
+func NmapScanTasks(tasks []worker.Task) []nmap.ScanTask {
+	out := make([]nmap.ScanTask, len(tasks))
+	for i, t := range tasks {
+		out[i] = nmap.ScanTask{
+			Target:      t.Target,
+			Options:     t.Options,
+			JobID:       t.JobID,
+			GroupID:     t.GroupID,
+			ChunkIdx:    t.ChunkIdx,
+			TotalChunks: t.TotalChunks,
+			PortOptions: t.Metadata[nmap.PortOptionsKey],
+		}
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/scope"
+	"heph4estus/internal/tools/nmap"
+	"heph4estus/internal/worker"
+)
+
+const discoveryReport = `<?xml version="1.0"?>
+<nmaprun>
+<host><status state="up"/><address addr="10.0.0.1" addrtype="ipv4"/></host>
+<host><status state="down"/><address addr="10.0.0.2" addrtype="ipv4"/></host>
+<host><status state="up"/><address addr="10.0.0.3" addrtype="ipv4"/></host>
+<host><status state="up"/><address addr="10.0.1.1" addrtype="ipv4"/></host>
+</nmaprun>`
+
+func TestRunDiscovery_ScansOnlyLiveHostsInScope(t *testing.T) {
+	const jobID = "nmap-discovery"
+	discovery := nmap.NewScanner(nil).ParseDiscoveryTasks("10.0.0.0/30 -sS -p 1-100", "-sS", nmap.DefaultDiscoveryOptions)
+	for i := range discovery {
+		discovery[i].JobID = jobID
+	}
+
+	f := newFakeCloud()
+	resultKey := jobs.ResultKey("nmap", jobID, "10.0.0.0/30", nmap.DiscoveryGroup, 0, 1, 1, "json")
+	reportKey := jobs.ArtifactKey("nmap", jobID, "10.0.0.0/30", nmap.DiscoveryGroup, 0, 1, 1, "xml")
+	result, _ := json.Marshal(worker.Result{ToolName: "nmap", Target: "10.0.0.0/30", OutputKey: reportKey})
+	f.objects[resultKey] = result
+	f.objects[reportKey] = []byte(discoveryReport)
+	// Every stage finishes on its first progress check.
+	f.results = 100
+
+	r, store := newTestRunner(t, f, nil)
+	sc, err := scope.New([]string{"10.0.0.0/24"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stages []string
+	out, err := r.RunDiscovery(context.Background(), jobs.JobConfig{Scope: sc}, jobID, discovery, 2, func(stage string, p Progress) {
+		stages = append(stages, stage)
+	})
+	if err != nil {
+		t.Fatalf("RunDiscovery: %v", err)
+	}
+	if !out.Started || out.LiveHosts != 2 || out.PortTasks != 4 || out.TotalTasks != 5 {
+		t.Errorf("outcome = %+v", out)
+	}
+	if strings.Join(stages, ",") != StageDiscovery+","+StagePortScan {
+		t.Errorf("progress stages = %v", stages)
+	}
+	if len(f.containers) != 2 {
+		t.Errorf("expected workers launched for each stage, got %d launches", len(f.containers))
+	}
+
+	var tasks []worker.Task
+	for _, body := range f.sent {
+		var task worker.Task
+		if err := json.Unmarshal([]byte(body), &task); err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) != 5 || tasks[0].Options != "-sn" {
+		t.Fatalf("enqueued = %+v, want a -sn discovery task and 4 port chunks", tasks)
+	}
+	// 10.0.0.2 is down and the stray 10.0.1.1 is out of scope.
+	for _, task := range tasks[1:] {
+		if (task.Target != "10.0.0.1" && task.Target != "10.0.0.3") || !strings.HasPrefix(task.Options, "-Pn -sS -p ") {
+			t.Errorf("port scan task = %s %q", task.Target, task.Options)
+		}
+	}
+
+	rec, err := store.Load(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Stage != StagePortScan || rec.TotalTasks != 5 {
+		t.Errorf("record stage = %q, total = %d", rec.Stage, rec.TotalTasks)
+	}
+}
+
+func TestNmapTasks_RoundTripKeepsPortOptions(t *testing.T) {
+	in := []nmap.ScanTask{{Target: "10.0.0.0/24", Options: "-sn", GroupID: nmap.DiscoveryGroup, PortOptions: "-sV"}}
+	out := NmapScanTasks(NmapTasks(in))
+	if out[0].PortOptions != "-sV" {
+		t.Errorf("PortOptions = %q after round trip", out[0].PortOptions)
+	}
+}
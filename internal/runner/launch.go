@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"heph4estus/internal/cloud"
+	awscloud "heph4estus/internal/cloud/aws"
+	"heph4estus/internal/schedule"
+)
+
+// SpotThreshold is the worker count at or above which "auto" compute mode
+// selects spot instances on AWS.
+const SpotThreshold = 50
+
+// UseSpot reports whether a launch should use spot instances. The selfhosted
+// family and local runtime only support RunContainer, so they never select spot.
+func UseSpot(kind cloud.Kind, mode string, workers int) bool {
+	if kind.IsSelfhostedFamily() || kind.IsLocal() {
+		return false
+	}
+	switch mode {
+	case "spot":
+		return true
+	case "fargate":
+		return false
+	default: // "auto"
+		return workers >= SpotThreshold
+	}
+}
+
+// LaunchSpec describes one worker launch independent of the compute backend.
+type LaunchSpec struct {
+	ToolName         string
+	QueueID          string
+	Bucket           string
+	Workers          int
+	JitterMaxSeconds int
+	// Window, when set, is passed to workers as SCAN_WINDOW.
+	Window *schedule.Window
+	// LeaseStore, when set, is passed to workers as LEASE_STORE: the shared
+	// store of per-target politeness leases.
+	LeaseStore string
+
+	// ECS/Fargate settings.
+	Cluster         string
+	TaskDefinition  string
+	Subnets         []string
+	SecurityGroupID string
+
+	// Spot settings.
+	ECRRepoURL         string
+	AMIID              string
+	InstanceProfileARN string
+}
+
+// LaunchSpecFromOutputs fills a LaunchSpec from terraform outputs.
+func LaunchSpecFromOutputs(tool string, workers int, outputs map[string]string) LaunchSpec {
+	return LaunchSpec{
+		ToolName:           tool,
+		QueueID:            outputs["sqs_queue_url"],
+		Bucket:             outputs["s3_bucket_name"],
+		Workers:            workers,
+		LeaseStore:         outputs["lease_store_name"],
+		Cluster:            outputs["ecs_cluster_name"],
+		TaskDefinition:     outputs["task_definition_arn"],
+		Subnets:            SplitOutputList(outputs["subnet_ids"]),
+		SecurityGroupID:    outputs["security_group_id"],
+		ECRRepoURL:         outputs["ecr_repo_url"],
+		AMIID:              outputs["ami_id"],
+		InstanceProfileARN: outputs["instance_profile_arn"],
+	}
+}
+
+// WorkerEnv returns the environment every generic worker needs to find its
+// queue, bucket and module.
+func (s LaunchSpec) WorkerEnv() map[string]string {
+	env := map[string]string{
+		"QUEUE_URL":          s.QueueID,
+		"S3_BUCKET":          s.Bucket,
+		"TOOL_NAME":          s.ToolName,
+		"JITTER_MAX_SECONDS": strconv.Itoa(s.JitterMaxSeconds),
+	}
+	if s.Window != nil {
+		if b, err := json.Marshal(s.Window); err == nil {
+			env["SCAN_WINDOW"] = string(b)
+		}
+	}
+	if s.LeaseStore != "" {
+		env["LEASE_STORE"] = s.LeaseStore
+	}
+	return env
+}
+
+// ContainerOpts builds the RunContainer request for the spec.
+func (s LaunchSpec) ContainerOpts() cloud.ContainerOpts {
+	return cloud.ContainerOpts{
+		Cluster:        s.Cluster,
+		TaskDefinition: s.TaskDefinition,
+		ContainerName:  fmt.Sprintf("%s-worker", s.ToolName),
+		Subnets:        s.Subnets,
+		SecurityGroups: []string{s.SecurityGroupID},
+		Env:            s.WorkerEnv(),
+		Count:          s.Workers,
+	}
+}
+
+// SpotOpts builds the RunSpotInstances request for the spec, including the
+// user-data script that pulls the worker image from ECR.
+func (s LaunchSpec) SpotOpts() cloud.SpotOpts {
+	userData := awscloud.GenerateUserData(awscloud.UserDataOpts{
+		ECRRepoURL: s.ECRRepoURL,
+		ImageTag:   "latest",
+		Region:     RegionFromECR(s.ECRRepoURL),
+		EnvVars:    s.WorkerEnv(),
+	})
+	return cloud.SpotOpts{
+		AMI:             s.AMIID,
+		Count:           s.Workers,
+		SecurityGroups:  []string{s.SecurityGroupID},
+		SubnetIDs:       s.Subnets,
+		InstanceProfile: s.InstanceProfileARN,
+		UserData:        userData,
+		Tags: map[string]string{
+			"Project": "heph4estus",
+			"Tool":    s.ToolName,
+		},
+	}
+}
+
+// RegionFromECR extracts the AWS region from an ECR repo URL.
+func RegionFromECR(url string) string {
+	parts := strings.Split(url, ".")
+	for i, p := range parts {
+		if p == "ecr" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return "us-east-1"
+}
+
+// SplitOutputList parses a terraform list output rendered as "[a b c]".
+func SplitOutputList(s string) []string {
+	s = strings.Trim(s, "[]")
+	parts := strings.Split(s, " ")
+	var result []string
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"testing"
+
+	"heph4estus/internal/cloud"
+)
+
+func TestUseSpot(t *testing.T) {
+	tests := []struct {
+		kind    cloud.Kind
+		mode    string
+		workers int
+		want    bool
+	}{
+		{cloud.KindAWS, "auto", SpotThreshold - 1, false},
+		{cloud.KindAWS, "auto", SpotThreshold, true},
+		{cloud.KindAWS, "", SpotThreshold - 1, false},
+		{cloud.KindAWS, "", SpotThreshold, true},
+		{cloud.KindAWS, "spot", 1, true},
+		{cloud.KindAWS, "fargate", 500, false},
+		{cloud.KindManual, "spot", 500, false},
+		{cloud.KindHetzner, "auto", 500, false},
+		{cloud.KindLocal, "auto", 500, false},
+	}
+	for _, tt := range tests {
+		if got := UseSpot(tt.kind, tt.mode, tt.workers); got != tt.want {
+			t.Errorf("UseSpot(%s, %s, %d) = %v, want %v", tt.kind, tt.mode, tt.workers, got, tt.want)
+		}
+	}
+}
+
+func TestLaunchSpecFromOutputs(t *testing.T) {
+	spec := LaunchSpecFromOutputs("nuclei", 3, map[string]string{
+		"sqs_queue_url":     "https://sqs/q",
+		"s3_bucket_name":    "bucket",
+		"subnet_ids":        "[subnet-a subnet-b]",
+		"security_group_id": "sg-1",
+		"ecr_repo_url":      "123.dkr.ecr.eu-west-1.amazonaws.com/heph",
+		"lease_store_name":  "heph-leases",
+	})
+	opts := spec.ContainerOpts()
+	if opts.ContainerName != "nuclei-worker" || opts.Count != 3 || len(opts.Subnets) != 2 {
+		t.Errorf("unexpected container opts: %+v", opts)
+	}
+	if opts.Env["TOOL_NAME"] != "nuclei" || opts.Env["QUEUE_URL"] != "https://sqs/q" || opts.Env["LEASE_STORE"] != "heph-leases" {
+		t.Errorf("unexpected env: %v", opts.Env)
+	}
+	if got := RegionFromECR(spec.ECRRepoURL); got != "eu-west-1" {
+		t.Errorf("region = %q, want eu-west-1", got)
+	}
+}
+
+func TestSplitOutputList(t *testing.T) {
+	got := SplitOutputList("[a  b c]")
+	if len(got) != 3 || got[0] != "a" || got[2] != "c" {
+		t.Errorf("SplitOutputList = %v", got)
+	}
+	if got := SplitOutputList("[]"); len(got) != 0 {
+		t.Errorf("empty list = %v", got)
+	}
+}
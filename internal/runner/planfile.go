@@ -0,0 +1,243 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"heph4estus/internal/estimate"
+	"heph4estus/internal/fleet"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/politeness"
+	"heph4estus/internal/schedule"
+	"heph4estus/internal/scope"
+	"heph4estus/internal/targets"
+	"heph4estus/internal/tools/nuclei"
+	"heph4estus/internal/tools/wordlist"
+	"heph4estus/internal/worker"
+)
+
+// PlanFileVersion is the current saved plan format.
+const PlanFileVersion = 1
+
+// PlanFile is a dry-run plan saved to disk so the exact same job can be
+// executed later. Target-list and nmap plans carry their tasks verbatim;
+// wordlist plans carry the wordlist hash and chunk count, which re-plan to
+// the same chunks because chunk layout depends only on the file, the job ID
+// and the chunk count.
+type PlanFile struct {
+	Version       int                   `json:"version"`
+	CreatedAt     time.Time             `json:"created_at"`
+	JobID         string                `json:"job_id"`
+	ToolName      string                `json:"tool"`
+	Cloud         string                `json:"cloud"`
+	Workers       int                   `json:"workers"`
+	ComputeMode   string                `json:"compute_mode"`
+	Spot          bool                  `json:"spot"`
+	Placement     fleet.PlacementPolicy `json:"placement"`
+	Options       string                `json:"options,omitempty"`
+	Mode          string                `json:"mode,omitempty"`
+	PortChunks    int                   `json:"port_chunks,omitempty"`
+	SourceJobID   string                `json:"source_job_id,omitempty"`
+	RuntimeTarget string                `json:"runtime_target,omitempty"`
+	Unit          string                `json:"unit"`
+	TaskCount     int                   `json:"task_count"`
+	Tasks         []worker.Task         `json:"tasks,omitempty"`
+	Wordlist      *PlannedWordlist      `json:"wordlist,omitempty"`
+	Scope         *scope.Digest         `json:"scope,omitempty"`
+	Window        *schedule.Window      `json:"window,omitempty"`
+	GlobalRate    int                   `json:"global_rate,omitempty"`
+	Politeness    *politeness.Limits    `json:"politeness,omitempty"`
+	Estimate      *estimate.Estimate    `json:"estimate,omitempty"`
+
+	// TemplateShards, when set, is applied to Tasks at run time: each
+	// target task runs once per shard.
+	TemplateShards *nuclei.ShardPlan `json:"template_shards,omitempty"`
+	// Assets pins the asset bundle shipped to every task.
+	Assets *PlannedAssets `json:"assets,omitempty"`
+	// WildcardProbe adds the wildcard DNS probes to Tasks at run time.
+	WildcardProbe bool `json:"wildcard_probe,omitempty"`
+}
+
+// PlannedAssets pins the asset bundle a saved plan ships.
+type PlannedAssets struct {
+	Name   string `json:"name"`
+	Dir    string `json:"dir"`
+	SHA256 string `json:"sha256"`
+	Files  int    `json:"files"`
+	Size   int    `json:"size"`
+}
+
+// NewPlannedAssets pins a packed asset bundle.
+func NewPlannedAssets(a *jobs.AssetPlan) *PlannedAssets {
+	return &PlannedAssets{Name: a.Bundle.Name, Dir: a.Bundle.Dir, SHA256: a.SHA256, Files: a.Files, Size: len(a.Data)}
+}
+
+// Bundle returns the pinned bundle.
+func (a *PlannedAssets) Bundle() *jobs.AssetBundle {
+	if a == nil {
+		return nil
+	}
+	return &jobs.AssetBundle{Name: a.Name, Dir: a.Dir}
+}
+
+// PlannedWordlist pins the wordlist a saved plan was computed from.
+type PlannedWordlist struct {
+	Path       string `json:"path"`
+	SHA256     string `json:"sha256"`
+	Size       int64  `json:"size"`
+	TotalWords int    `json:"total_words"`
+	Chunks     int    `json:"chunks"`
+	// Transform is re-applied on replay so the chunks match the plan.
+	Transform *wordlist.Transform `json:"transform,omitempty"`
+	// TaskDuration, when set, sizes the chunks adaptively at run time;
+	// Chunks is then only the byte-based estimate.
+	TaskDuration time.Duration `json:"task_duration,omitempty"`
+	// Whole pins the module's other named wordlists, shipped whole.
+	Whole map[string]*PlannedWordlist `json:"whole,omitempty"`
+}
+
+// WholePaths returns the paths of the wordlists shipped whole, by name.
+func (wl *PlannedWordlist) WholePaths() map[string]string {
+	if len(wl.Whole) == 0 {
+		return nil
+	}
+	paths := make(map[string]string, len(wl.Whole))
+	for name, w := range wl.Whole {
+		paths[name] = w.Path
+	}
+	return paths
+}
+
+// PlansDir returns the default directory for saved plans.
+func PlansDir() (string, error) {
+	dir, err := operator.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "plans"), nil
+}
+
+// Save writes the plan as indented JSON, creating parent directories.
+func (pf *PlanFile) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating plan directory: %w", err)
+	}
+	data, err := json.MarshalIndent(pf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing plan: %w", err)
+	}
+	return nil
+}
+
+// LoadPlanFile reads a saved plan. ref is a file path or, when no such
+// file exists, a job ID looked up in PlansDir.
+func LoadPlanFile(ref string) (*PlanFile, error) {
+	path := ref
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		dir, dirErr := PlansDir()
+		if dirErr != nil {
+			return nil, dirErr
+		}
+		path = filepath.Join(dir, ref+".json")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading plan: %w", err)
+	}
+	var pf PlanFile
+	if err := json.Unmarshal(data, &pf); err != nil {
+		return nil, fmt.Errorf("decoding plan %s: %w", path, err)
+	}
+	if pf.Version != PlanFileVersion {
+		return nil, fmt.Errorf("plan %s has version %d, want %d", path, pf.Version, PlanFileVersion)
+	}
+	if pf.JobID == "" || pf.ToolName == "" {
+		return nil, fmt.Errorf("plan %s is missing job ID or tool", path)
+	}
+	if pf.Wordlist == nil && len(pf.Tasks) == 0 {
+		return nil, fmt.Errorf("plan %s has no tasks", path)
+	}
+	return &pf, nil
+}
+
+// Entries returns the plan's target-list tasks as entries that re-plan to
+// the same tasks when planned with empty base options.
+func (pf *PlanFile) Entries() []targets.Entry {
+	entries := make([]targets.Entry, len(pf.Tasks))
+	for i, t := range pf.Tasks {
+		entries[i] = targets.Entry{Target: t.Target, Options: t.Options, Metadata: t.Metadata}
+	}
+	return entries
+}
+
+// ScopeValue rebuilds the scope the plan was checked against, or returns
+// nil for unscoped plans.
+func (pf *PlanFile) ScopeValue() (*scope.Scope, error) {
+	if pf.Scope == nil {
+		return nil, nil
+	}
+	sc, err := pf.Scope.Scope()
+	if err != nil {
+		return nil, fmt.Errorf("plan scope: %w", err)
+	}
+	return sc, nil
+}
+
+// VerifyWordlist checks that the planned wordlist is unchanged on disk.
+func (pf *PlanFile) VerifyWordlist() error {
+	if pf.Wordlist == nil {
+		return nil
+	}
+	for _, wl := range append([]*PlannedWordlist{pf.Wordlist}, slices.Collect(maps.Values(pf.Wordlist.Whole))...) {
+		sum, _, err := HashFile(wl.Path)
+		if err != nil {
+			return err
+		}
+		if sum != wl.SHA256 {
+			return fmt.Errorf("wordlist %s changed since it was planned", wl.Path)
+		}
+	}
+	return nil
+}
+
+// VerifyAssets checks that the planned asset bundle packs to the same bytes.
+func (pf *PlanFile) VerifyAssets() error {
+	if pf.Assets == nil {
+		return nil
+	}
+	assets, err := jobs.PlanAssets(pf.ToolName, pf.JobID, *pf.Assets.Bundle())
+	if err != nil {
+		return err
+	}
+	if assets.SHA256 != pf.Assets.SHA256 {
+		return fmt.Errorf("%s assets in %s changed since they were planned", pf.Assets.Name, pf.Assets.Dir)
+	}
+	return nil
+}
+
+// HashFile returns the hex SHA-256 and size of the file at path.
+func HashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("hashing %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/scope"
+	"heph4estus/internal/worker"
+)
+
+func TestPlanFile_RoundTripReplansSameTasks(t *testing.T) {
+	sc, err := scope.New([]string{"10.0.0.0/8"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf := &PlanFile{
+		Version:   PlanFileVersion,
+		CreatedAt: time.Now().UTC(),
+		JobID:     "nmap-123",
+		ToolName:  "nmap",
+		Unit:      "targets",
+		TaskCount: 2,
+		Tasks: []worker.Task{
+			{ToolName: "nmap", JobID: "nmap-123", Target: "10.0.0.1", Options: "-sV -p 22", Metadata: map[string]string{"site": "lab"}},
+			{ToolName: "nmap", JobID: "nmap-123", Target: "10.0.0.2", Options: "-sV"},
+		},
+		Scope: sc.Digest(),
+	}
+	path := filepath.Join(t.TempDir(), "plans", "nmap-123.json")
+	if err := pf.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadPlanFile(path)
+	if err != nil {
+		t.Fatalf("LoadPlanFile: %v", err)
+	}
+	loadedScope, err := loaded.ScopeValue()
+	if err != nil || loadedScope == nil {
+		t.Fatalf("ScopeValue = %v, %v", loadedScope, err)
+	}
+
+	r, _ := newTestRunner(t, newFakeCloud(), nil)
+	plan, err := r.Plan(jobs.JobConfig{ToolName: loaded.ToolName, Entries: loaded.Entries(), Scope: loadedScope}, loaded.JobID)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(plan.Tasks) != 2 {
+		t.Fatalf("tasks = %+v", plan.Tasks)
+	}
+	for i, task := range plan.Tasks {
+		want := pf.Tasks[i]
+		if task.JobID != want.JobID || task.Target != want.Target || task.Options != want.Options || task.Metadata["site"] != want.Metadata["site"] {
+			t.Errorf("task %d = %+v, want %+v", i, task, want)
+		}
+		if task.Scope == nil || task.Scope.SHA256 != pf.Scope.SHA256 {
+			t.Errorf("task %d scope = %+v", i, task.Scope)
+		}
+	}
+}
+
+func TestPlanFile_VerifyWordlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("admin\nlogin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sum, size, err := HashFile(path)
+	if err != nil || size != 12 {
+		t.Fatalf("HashFile = %s, %d, %v", sum, size, err)
+	}
+	pf := &PlanFile{Wordlist: &PlannedWordlist{Path: path, SHA256: sum, Size: size}}
+	if err := pf.VerifyWordlist(); err != nil {
+		t.Fatalf("VerifyWordlist: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("admin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := pf.VerifyWordlist(); err == nil || !strings.Contains(err.Error(), "changed") {
+		t.Errorf("expected changed-wordlist error, got %v", err)
+	}
+}
+
+func TestLoadPlanFile_RejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "job_id": "x", "tool": "httpx"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPlanFile(path); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("expected version error, got %v", err)
+	}
+}
@@ -0,0 +1,791 @@
+// Package runner implements jobs.Runner on top of cloud.Provider and
+// operator.Tracker. It owns the job lifecycle shared by the CLI, the TUI and
+// library callers: plan, upload, enqueue, launch, monitor and export.
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/fleet"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/politeness"
+	"heph4estus/internal/schedule"
+	"heph4estus/internal/scope"
+	"heph4estus/internal/targets"
+	"heph4estus/internal/tools/nuclei"
+	"heph4estus/internal/worker"
+)
+
+const (
+	// DefaultPollInterval is how often Wait counts result objects.
+	DefaultPollInterval = 2 * time.Second
+	// EnqueueTimeout bounds chunk uploads and queue sends.
+	EnqueueTimeout = 5 * time.Minute
+	// LaunchTimeout bounds worker launch and provider-native fleet waits.
+	LaunchTimeout = 5 * time.Minute
+	// MaxTaskPayload is the SQS message size limit every queue backend honours.
+	MaxTaskPayload = 256 * 1024
+)
+
+// Compile-time interface check.
+var _ jobs.Runner = (*Runner)(nil)
+
+// FleetWaiter blocks until a provider-native fleet has admitted enough
+// workers and returns the eligible worker count.
+type FleetWaiter func(ctx context.Context, kind cloud.Kind, outputs map[string]string, policy fleet.PlacementPolicy) (int, error)
+
+// FleetStopper scales a provider-native fleet down, such as after a budget
+// breach, since its workers cannot be stopped per job.
+type FleetStopper func(ctx context.Context, kind cloud.Kind, tool string) error
+
+// Config describes where and how a Runner executes jobs.
+type Config struct {
+	Provider cloud.Provider
+	Tracker  *operator.Tracker // nil disables job persistence
+
+	Cloud   cloud.Kind
+	QueueID string
+	Bucket  string
+	// Outputs are the terraform outputs (or equivalent) for the runtime.
+	// They feed worker launch settings and fleet metadata on job records.
+	Outputs map[string]string
+
+	Workers          int
+	ComputeMode      string
+	JitterMaxSeconds int
+	Placement        fleet.PlacementPolicy
+	CleanupPolicy    string
+	// Budget caps the job; Wait stops workers and fails the job when a
+	// limit is crossed. The job record keeps a copy for status display.
+	Budget operator.Budget
+	// Window restricts when workers pull tasks. Nil runs at any time.
+	Window *schedule.Window
+	// GlobalRate caps the job's combined request rate; each task carries
+	// its share across Workers. Zero leaves the rate uncapped.
+	GlobalRate int
+	// Politeness caps how hard the fleet works each target host. Nil
+	// leaves targets unlimited.
+	Politeness *politeness.Limits
+	// ChunkCache keys wordlist chunks by content under a prefix shared
+	// across jobs and reuses splits that are already uploaded.
+	ChunkCache bool
+
+	// WaitForFleet is required for provider-native clouds, which use a
+	// standing fleet instead of launching workers per job.
+	WaitForFleet FleetWaiter
+	// StopFleet scales a provider-native fleet down when a budget cancels
+	// the job. Nil leaves the fleet running.
+	StopFleet    FleetStopper
+	PollInterval time.Duration
+	// Logf receives human-readable status lines. Nil discards them.
+	Logf func(format string, args ...any)
+}
+
+// Runner executes jobs against one cloud runtime.
+type Runner struct {
+	cfg Config
+
+	// Set by Launch for budget enforcement.
+	launchedIDs []string
+	launchedAt  time.Time
+	workersUp   int
+}
+
+// New validates cfg and returns a Runner.
+func New(cfg Config) (*Runner, error) {
+	if cfg.Provider == nil {
+		return nil, fmt.Errorf("runner: provider is required")
+	}
+	if cfg.QueueID == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("runner: queue and bucket are required")
+	}
+	if cfg.Tracker == nil {
+		cfg.Tracker = operator.NoopTracker()
+	}
+	if cfg.Cloud == "" {
+		cfg.Cloud = cloud.DefaultKind
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	return &Runner{cfg: cfg}, nil
+}
+
+// Services adapts already-built queue, storage and compute clients to
+// cloud.Provider for callers that do not hold a provider value.
+type Services struct {
+	Q cloud.Queue
+	S cloud.Storage
+	C cloud.Compute
+}
+
+func (s Services) Queue() cloud.Queue     { return s.Q }
+func (s Services) Storage() cloud.Storage { return s.S }
+func (s Services) Compute() cloud.Compute { return s.C }
+
+// Plan is a prepared job: the tasks to enqueue and, for wordlist jobs, the
+// chunk files that must be uploaded first.
+type Plan struct {
+	JobID         string
+	ToolName      string
+	RuntimeTarget string
+	Tasks         []worker.Task
+	Wordlist      *jobs.WordlistPlan // nil for target-list jobs
+	// ScopeDigest is the SHA-256 of the scope the tasks were checked
+	// against, or empty for unscoped jobs.
+	ScopeDigest string
+	// TargetStats reports target normalization, or nil when it was off.
+	TargetStats *targets.Stats
+	// TemplateShards is the nuclei template split the tasks run, or nil.
+	TemplateShards *nuclei.ShardPlan
+	// Assets is the packed asset bundle every task extracts, or nil.
+	Assets *jobs.AssetPlan
+	// WildcardProbes counts the wildcard DNS probe tasks that lead Tasks.
+	WildcardProbes int
+
+	tempDir string
+	// calibration is set when the wordlist is sized adaptively; Tasks then
+	// cover only the sample until Start plans the remainder.
+	calibration *calibration
+}
+
+// Calibrating reports whether the plan's chunks are sized adaptively, so
+// Start adds tasks after the calibration sample finishes.
+func (p *Plan) Calibrating() bool {
+	return p.calibration != nil
+}
+
+// Unit returns the progress label for the plan's tasks.
+func (p *Plan) Unit() string {
+	if p.Wordlist != nil {
+		return "chunks"
+	}
+	if p.TemplateShards != nil {
+		return "tasks"
+	}
+	return "targets"
+}
+
+// Cleanup removes temporary chunk files. It is safe to call repeatedly.
+func (p *Plan) Cleanup() error {
+	if p == nil {
+		return nil
+	}
+	var errs []error
+	if p.Wordlist != nil {
+		if err := p.Wordlist.Cleanup(); err != nil {
+			errs = append(errs, fmt.Errorf("cleaning wordlist chunks: %w", err))
+		}
+	}
+	if p.tempDir != "" {
+		if err := os.RemoveAll(p.tempDir); err != nil {
+			errs = append(errs, fmt.Errorf("removing wordlist temp dir: %w", err))
+		}
+		p.tempDir = ""
+	}
+	return errors.Join(errs...)
+}
+
+// Plan turns a job config into tasks. Pre-built tasks are used as-is, a
+// WordlistPath produces streamed chunk files, and otherwise every entry (or
+// target line) becomes one task, normalized first when cfg.TargetOptions is
+// set. A job that splits a nuclei template set gets one task per target and
+// shard instead, and a job that probes for wildcard DNS gets probe tasks for
+// each zone first. When cfg.Scope is set, any out-of-scope target fails the
+// plan and every task carries the scope digest. An asset bundle is packed
+// here and every task carries its storage key.
+func (r *Runner) Plan(cfg jobs.JobConfig, jobID string) (*Plan, error) {
+	if cfg.ToolName == "" {
+		return nil, fmt.Errorf("tool name is required")
+	}
+	if jobID == "" {
+		jobID = jobs.NewID(cfg.ToolName)
+	}
+	plan := &Plan{JobID: jobID, ToolName: cfg.ToolName, RuntimeTarget: cfg.RuntimeTarget}
+
+	switch {
+	case len(cfg.Tasks) > 0:
+		plan.Tasks = make([]worker.Task, len(cfg.Tasks))
+		for i, t := range cfg.Tasks {
+			if t.ToolName == "" {
+				t.ToolName = cfg.ToolName
+			}
+			t.JobID = jobID
+			plan.Tasks[i] = t
+		}
+	case cfg.WordlistPath != "":
+		tempDir, err := os.MkdirTemp("", "heph-wordlist-*")
+		if err != nil {
+			return nil, fmt.Errorf("creating wordlist temp dir: %w", err)
+		}
+		wl, cal, err := r.planWordlist(cfg, jobID, tempDir)
+		if err != nil {
+			_ = os.RemoveAll(tempDir)
+			return nil, fmt.Errorf("planning wordlist job: %w", err)
+		}
+		plan.tempDir = tempDir
+		plan.Wordlist = wl
+		plan.Tasks = wl.Tasks
+		plan.calibration = cal
+	default:
+		entries := cfg.Entries
+		if len(entries) == 0 {
+			for _, line := range jobs.ParseTargetLines(string(cfg.Targets)) {
+				entries = append(entries, targets.Entry{Target: line})
+			}
+		}
+		if cfg.TargetOptions != nil {
+			res, err := targets.NormalizeEntries(entries, *cfg.TargetOptions)
+			if err != nil {
+				return nil, fmt.Errorf("normalizing targets: %w", err)
+			}
+			entries = res.Entries
+			plan.TargetStats = &res.Stats
+		}
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("no targets found")
+		}
+		plan.Tasks = EntryTasks(cfg.ToolName, jobID, cfg.Options, entries)
+		if shards := cfg.TemplateShards; shards != nil {
+			plan.Tasks = shards.Tasks(plan.Tasks)
+			plan.TemplateShards = shards
+		}
+		if cfg.WildcardProbe {
+			probes := WildcardProbes(cfg.ToolName, jobID, entries, cfg.Scope)
+			plan.Tasks = append(probes, plan.Tasks...)
+			plan.WildcardProbes = len(probes)
+		}
+	}
+	if cfg.Scope != nil {
+		if err := applyScope(plan, cfg.Scope); err != nil {
+			_ = plan.Cleanup()
+			return nil, err
+		}
+	}
+	if bundle := cfg.Assets; bundle != nil {
+		assets, err := jobs.PlanAssets(cfg.ToolName, jobID, *bundle)
+		if err != nil {
+			_ = plan.Cleanup()
+			return nil, err
+		}
+		for i := range plan.Tasks {
+			plan.Tasks[i].Assets = assets.Key
+		}
+		plan.Assets = assets
+	}
+	return plan, nil
+}
+
+// EntryTasks builds one task per entry, appending each entry's options to
+// the job-wide options.
+func EntryTasks(tool, jobID, options string, entries []targets.Entry) []worker.Task {
+	tasks := make([]worker.Task, len(entries))
+	for i, e := range entries {
+		tasks[i] = worker.Task{
+			ToolName: tool,
+			JobID:    jobID,
+			Target:   e.Target,
+			Options:  strings.TrimSpace(options + " " + e.Options),
+			Metadata: e.Metadata,
+		}
+	}
+	return tasks
+}
+
+// applyScope checks the plan's tasks against sc and records its digest.
+func applyScope(plan *Plan, sc *scope.Scope) error {
+	digest, err := ApplyScope(plan.Tasks, sc)
+	if err != nil {
+		return err
+	}
+	plan.ScopeDigest = digest.SHA256
+	return nil
+}
+
+// ApplyScope checks every distinct task target against sc and, when all
+// are in scope, embeds the scope digest in each task for worker re-checks.
+func ApplyScope(tasks []worker.Task, sc *scope.Scope) (*scope.Digest, error) {
+	seen := make(map[string]bool)
+	var distinct []string
+	for _, t := range tasks {
+		if t.Target == "" || seen[t.Target] {
+			continue
+		}
+		seen[t.Target] = true
+		distinct = append(distinct, t.Target)
+	}
+	if err := sc.CheckAll(distinct); err != nil {
+		return nil, err
+	}
+	digest := sc.Digest()
+	for i := range tasks {
+		tasks[i].Scope = digest
+	}
+	return digest, nil
+}
+
+// Submit plans and starts a job, returning once workers are launched.
+// Use Wait or Status to follow progress.
+func (r *Runner) Submit(ctx context.Context, cfg jobs.JobConfig) (string, error) {
+	plan, err := r.Plan(cfg, "")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = plan.Cleanup() }()
+	if err := r.Start(ctx, plan); err != nil {
+		return plan.JobID, err
+	}
+	return plan.JobID, nil
+}
+
+// Outcome summarises a Run.
+type Outcome struct {
+	JobID      string
+	TotalTasks int
+	// Started is true once workers were launched; after that point the job
+	// consumed cloud resources even if monitoring or export failed.
+	Started bool
+	Export  *operator.ExportResult
+}
+
+// Run executes the full lifecycle for cfg and blocks until every task has
+// a result. When outDir is non-empty the results are exported there.
+func (r *Runner) Run(ctx context.Context, cfg jobs.JobConfig, outDir string, onProgress func(Progress)) (*Outcome, error) {
+	plan, err := r.Plan(cfg, "")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = plan.Cleanup() }()
+
+	out := &Outcome{JobID: plan.JobID, TotalTasks: len(plan.Tasks)}
+	if err := r.Start(ctx, plan); err != nil {
+		return out, err
+	}
+	out.Started = true
+	if err := r.Wait(ctx, plan.ToolName, plan.JobID, len(plan.Tasks), onProgress); err != nil {
+		_ = r.cfg.Tracker.Fail(plan.JobID, err)
+		return out, err
+	}
+	if plan.WildcardProbes > 0 {
+		if _, err := r.RecordWildcards(ctx, plan.ToolName, plan.JobID); err != nil {
+			r.logf("Warning: %v", err)
+		}
+	}
+	_ = r.cfg.Tracker.Complete(plan.JobID)
+	if outDir != "" {
+		res, err := r.Export(ctx, plan.ToolName, plan.JobID, outDir)
+		if err != nil {
+			return out, fmt.Errorf("export failed: %w", err)
+		}
+		out.Export = res
+	}
+	return out, nil
+}
+
+// Start records the job, uploads wordlist chunks and assets, enqueues tasks and
+// launches workers. An adaptively sized wordlist job then waits for its
+// calibration sample, enqueues the remainder, adding to plan.Tasks, and
+// launches workers for it.
+// Failures are recorded on the job before returning.
+func (r *Runner) Start(ctx context.Context, plan *Plan) error {
+	if len(plan.Tasks) == 0 {
+		return fmt.Errorf("no tasks to enqueue")
+	}
+	r.ensureRecord(plan)
+	if window := r.cfg.Window; window != nil {
+		for i := range plan.Tasks {
+			plan.Tasks[i].Window = window
+		}
+		r.logf("Scan window: %s", window)
+	}
+	if global := r.cfg.GlobalRate; global > 0 {
+		share := fleet.RateShare(global, r.cfg.Workers)
+		for i := range plan.Tasks {
+			plan.Tasks[i].GlobalRate = global
+			plan.Tasks[i].Rate = share
+		}
+		r.logf("Global rate: %d/s, %d/s per worker across %d workers", global, share, r.cfg.Workers)
+	}
+	if lim := r.cfg.Politeness; lim != nil {
+		for i := range plan.Tasks {
+			plan.Tasks[i].Politeness = lim
+		}
+		r.logf("Per-target limits: %s", lim)
+		if r.cfg.Cloud.RuntimeFamily() == cloud.KindAWS && r.cfg.Outputs["lease_store_name"] == "" {
+			r.logf("Warning: infrastructure has no lease table; redeploy for per-target limits to take effect")
+		}
+	}
+
+	fail := func(err error) error {
+		_ = r.cfg.Tracker.Fail(plan.JobID, err)
+		return err
+	}
+
+	if plan.Wordlist != nil {
+		_ = r.cfg.Tracker.UpdatePhase(plan.JobID, operator.PhaseUploading)
+		if plan.Wordlist.Cached {
+			r.logf("Reusing %d cached chunks from s3://%s/%s%s/", plan.Wordlist.EffectiveChunks, r.cfg.Bucket, jobs.ChunkCachePrefix, plan.Wordlist.Manifest.Digest)
+		} else {
+			r.logf("Uploading %d chunks to s3://%s/...", plan.Wordlist.EffectiveChunks, r.cfg.Bucket)
+		}
+		for _, name := range plan.Wordlist.WholeNames() {
+			whole := plan.Wordlist.Whole[name]
+			if whole.Cached {
+				r.logf("Reusing cached wordlist %s (%d words)", name, whole.TotalWords)
+			} else {
+				r.logf("Shipping wordlist %s whole (%d words)", name, whole.TotalWords)
+			}
+		}
+		if err := r.Upload(ctx, plan.Wordlist); err != nil {
+			return fail(fmt.Errorf("uploading wordlist chunks: %w", err))
+		}
+	}
+
+	if plan.Assets != nil {
+		_ = r.cfg.Tracker.UpdatePhase(plan.JobID, operator.PhaseUploading)
+		r.logf("Uploading %s assets (%d files, %d bytes)...", plan.Assets.Bundle.Name, plan.Assets.Files, len(plan.Assets.Data))
+		if err := r.uploadAssets(ctx, plan.Assets); err != nil {
+			return fail(err)
+		}
+	}
+
+	_ = r.cfg.Tracker.UpdatePhase(plan.JobID, operator.PhaseEnqueuing)
+	r.logf("Enqueueing %d %s...", len(plan.Tasks), plan.Unit())
+	if err := r.Enqueue(ctx, plan.Tasks); err != nil {
+		return fail(fmt.Errorf("enqueueing %s: %w", plan.Unit(), err))
+	}
+	r.logf("Enqueued %d %s", len(plan.Tasks), plan.Unit())
+	if plan.Wordlist != nil {
+		if err := plan.Wordlist.Cleanup(); err != nil {
+			r.logf("Warning: failed to clean temporary wordlist chunks: %v", err)
+		}
+	}
+
+	_ = r.cfg.Tracker.UpdatePhase(plan.JobID, operator.PhaseLaunching)
+	if _, err := r.Launch(ctx, plan.ToolName); err != nil {
+		return fail(err)
+	}
+	_ = r.cfg.Tracker.UpdatePhase(plan.JobID, operator.PhaseScanning)
+	if plan.calibration != nil {
+		if err := r.finishCalibration(ctx, plan); err != nil {
+			return fail(err)
+		}
+	}
+	return nil
+}
+
+// NewRecord builds the job record the runner persists for plan.
+func (r *Runner) NewRecord(plan *Plan) *operator.JobRecord {
+	rec := &operator.JobRecord{
+		JobID:                 plan.JobID,
+		ToolName:              plan.ToolName,
+		Phase:                 operator.PhaseEnqueuing,
+		TotalTasks:            len(plan.Tasks),
+		WorkerCount:           r.cfg.Workers,
+		ComputeMode:           r.cfg.ComputeMode,
+		Cloud:                 string(r.cfg.Cloud),
+		CleanupPolicy:         r.cfg.CleanupPolicy,
+		Bucket:                r.cfg.Bucket,
+		RuntimeTarget:         plan.RuntimeTarget,
+		ScopeDigest:           plan.ScopeDigest,
+		Placement:             r.cfg.Placement,
+		ExpectedWorkerVersion: r.cfg.Outputs["docker_image"],
+		NATSUrl:               r.cfg.Outputs["nats_url"],
+		ControllerIP:          r.cfg.Outputs["controller_ip"],
+		GenerationID:          r.cfg.Outputs["generation_id"],
+		ControllerCAPEM:       r.cfg.Outputs["controller_ca_pem"],
+		ControllerHost:        r.cfg.Outputs["controller_host"],
+		NATSClientCertPEM:     r.cfg.Outputs["nats_operator_client_cert_pem"],
+		NATSClientKeyPEM:      r.cfg.Outputs["nats_operator_client_key_pem"],
+	}
+	if plan.Wordlist != nil {
+		rec.Phase = operator.PhaseUploading
+		rec.TotalWords = plan.Wordlist.TotalWords
+		rec.WordlistTransform = plan.Wordlist.Transform
+	}
+	if c := plan.calibration; c != nil {
+		rec.TotalWords = c.totalWords
+		rec.Calibration = c.record()
+	}
+	rec.TemplateShards = plan.TemplateShards
+	rec.WildcardProbe = plan.WildcardProbes > 0
+	if plan.Assets != nil {
+		bundle := plan.Assets.Bundle
+		rec.Assets = &bundle
+	}
+	if !r.cfg.Budget.IsZero() {
+		budget := r.cfg.Budget
+		rec.Budget = &budget
+	}
+	rec.Window = r.cfg.Window
+	rec.GlobalRate = r.cfg.GlobalRate
+	rec.Politeness = r.cfg.Politeness
+	return rec
+}
+
+// ensureRecord creates the job record, or fills in the planned counts when
+// the caller already created one.
+func (r *Runner) ensureRecord(plan *Plan) {
+	store := r.cfg.Tracker.Store()
+	if store == nil {
+		return
+	}
+	rec, err := store.Load(plan.JobID)
+	if err != nil {
+		_ = r.cfg.Tracker.Create(r.NewRecord(plan))
+		return
+	}
+	rec.TotalTasks = len(plan.Tasks)
+	if plan.Wordlist != nil {
+		rec.TotalWords = plan.Wordlist.TotalWords
+	}
+	if c := plan.calibration; c != nil {
+		rec.TotalWords = c.totalWords
+		rec.Calibration = c.record()
+	}
+	if plan.RuntimeTarget != "" {
+		rec.RuntimeTarget = plan.RuntimeTarget
+	}
+	if plan.ScopeDigest != "" {
+		rec.ScopeDigest = plan.ScopeDigest
+	}
+	_ = store.Update(rec)
+}
+
+// Upload writes wordlist chunk files to the job's input prefix.
+func (r *Runner) Upload(ctx context.Context, wl *jobs.WordlistPlan) error {
+	uploadCtx, cancel := context.WithTimeout(ctx, EnqueueTimeout)
+	defer cancel()
+	return jobs.UploadChunks(uploadCtx, r.cfg.Provider.Storage(), r.cfg.Bucket, wl)
+}
+
+// Enqueue sends tasks to the runner's queue.
+func (r *Runner) Enqueue(ctx context.Context, tasks []worker.Task) error {
+	enqueueCtx, cancel := context.WithTimeout(ctx, EnqueueTimeout)
+	defer cancel()
+	return EnqueueTasks(enqueueCtx, r.cfg.Provider.Queue(), r.cfg.QueueID, tasks)
+}
+
+// EnqueueTasks marshals tasks and sends them in one batch, rejecting any
+// task whose body exceeds MaxTaskPayload.
+func EnqueueTasks(ctx context.Context, queue cloud.Queue, queueID string, tasks []worker.Task) error {
+	bodies := make([]string, len(tasks))
+	for i, t := range tasks {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Errorf("marshaling task %d: %w", i, err)
+		}
+		if len(b) > MaxTaskPayload {
+			return fmt.Errorf("task %d exceeds 256KB queue message limit (%d bytes)", i, len(b))
+		}
+		bodies[i] = string(b)
+	}
+	return queue.SendBatch(ctx, queueID, bodies)
+}
+
+// Launch starts workers for tool, or waits for the standing fleet on
+// provider-native clouds. It returns the number of workers available.
+func (r *Runner) Launch(ctx context.Context, tool string) (int, error) {
+	r.logf("Launching %d workers (mode: %s)...", r.cfg.Workers, r.cfg.ComputeMode)
+	launchCtx, cancel := context.WithTimeout(ctx, LaunchTimeout)
+	defer cancel()
+
+	if r.cfg.Cloud.IsProviderNative() {
+		if r.cfg.WaitForFleet == nil {
+			return 0, fmt.Errorf("provider-native cloud %q requires a fleet waiter", r.cfg.Cloud.Canonical())
+		}
+		ready, err := r.cfg.WaitForFleet(launchCtx, r.cfg.Cloud, r.cfg.Outputs, r.cfg.Placement)
+		if err != nil {
+			return 0, err
+		}
+		r.logf("Using provider-native %s fleet (%d eligible workers, policy: %s)", r.cfg.Cloud.Canonical(), ready, r.cfg.Placement.Summary())
+		r.recordLaunch(nil, ready)
+		return ready, nil
+	}
+
+	spec := LaunchSpecFromOutputs(tool, r.cfg.Workers, r.cfg.Outputs)
+	spec.QueueID = r.cfg.QueueID
+	spec.Bucket = r.cfg.Bucket
+	spec.JitterMaxSeconds = r.cfg.JitterMaxSeconds
+	spec.Window = r.cfg.Window
+
+	compute := r.cfg.Provider.Compute()
+	if UseSpot(r.cfg.Cloud, r.cfg.ComputeMode, r.cfg.Workers) {
+		ids, err := compute.RunSpotInstances(launchCtx, spec.SpotOpts())
+		r.recordLaunch(ids, len(ids))
+		if err != nil {
+			return 0, fmt.Errorf("launching spot instances: %w", err)
+		}
+		r.logf("Launched %d spot instances", len(ids))
+		return len(ids), nil
+	}
+	ids, err := compute.RunContainer(launchCtx, spec.ContainerOpts())
+	r.recordLaunch(SplitWorkerIDs(ids), r.cfg.Workers)
+	if err != nil {
+		return 0, fmt.Errorf("launching workers: %w", err)
+	}
+	r.logf("Launched %d workers", r.cfg.Workers)
+	return r.cfg.Workers, nil
+}
+
+func (r *Runner) recordLaunch(ids []string, workers int) {
+	r.launchedIDs = append(r.launchedIDs, ids...)
+	// Later launches, such as a second stage, keep the job's first launch
+	// time so budgets cover the whole job.
+	if r.launchedAt.IsZero() {
+		r.launchedAt = time.Now()
+	}
+	r.workersUp = workers
+}
+
+// WorkersUp returns how many workers the last Launch made available.
+func (r *Runner) WorkersUp() int {
+	return r.workersUp
+}
+
+// Progress is one observation made while waiting for a job.
+type Progress struct {
+	Completed int
+	Total     int
+	Elapsed   time.Duration
+}
+
+// Percent returns completion as a percentage of Total.
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return float64(p.Completed) / float64(p.Total) * 100
+}
+
+// Wait polls the job's result prefix until total results exist or ctx is
+// cancelled. Transient count failures are logged and retried. When the job
+// has a budget and crosses it, Wait cancels the job's queued tasks, stops
+// the workers, fails the job and returns an error wrapping
+// operator.ErrBudgetExceeded.
+func (r *Runner) Wait(ctx context.Context, tool, jobID string, total int, onProgress func(Progress)) error {
+	start := time.Now()
+	prefix := jobs.ResultPrefix(tool, jobID)
+	storage := r.cfg.Provider.Storage()
+	budget := r.cfg.Budget
+	window := r.cfg.Window
+	paused := false
+	for {
+		if closed := !window.Open(time.Now()); closed != paused {
+			paused = closed
+			if paused {
+				r.logf("Paused: outside scan window, resumes %s", window.NextOpen(time.Now()).Format(time.RFC3339))
+			} else {
+				r.logf("Scan window open, resuming")
+			}
+		}
+		count, err := storage.Count(ctx, r.cfg.Bucket, prefix)
+		if err != nil {
+			r.logf("Warning: progress check failed: %v", err)
+		} else {
+			if onProgress != nil {
+				onProgress(Progress{Completed: count, Total: total, Elapsed: time.Since(start)})
+			}
+			if count >= total {
+				return nil
+			}
+		}
+		if !budget.IsZero() {
+			if err := budget.Check(r.usage(start)); err != nil {
+				return r.abort(ctx, tool, jobID, budget, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.cfg.PollInterval):
+		}
+	}
+}
+
+// Export downloads the job's results and artifacts under outDir and records
+// the local path on the job.
+func (r *Runner) Export(ctx context.Context, tool, jobID, outDir string) (*operator.ExportResult, error) {
+	res, err := operator.ExportJob(ctx, r.cfg.Provider.Storage(), r.cfg.Bucket, tool, jobID, outDir)
+	if err != nil {
+		return nil, err
+	}
+	if store := r.cfg.Tracker.Store(); store != nil {
+		if rec, loadErr := store.Load(jobID); loadErr == nil {
+			rec.LocalOutputDir = res.Dir
+			_ = store.Update(rec)
+		}
+	}
+	return res, nil
+}
+
+// Status reports the persisted phase of a job together with live progress
+// counted from storage.
+func (r *Runner) Status(ctx context.Context, jobID string) (*jobs.JobStatus, error) {
+	store := r.cfg.Tracker.Store()
+	if store == nil {
+		return nil, fmt.Errorf("job tracking is unavailable")
+	}
+	rec, err := store.Load(jobID)
+	if err != nil {
+		return nil, err
+	}
+	status := &jobs.JobStatus{
+		JobID:      rec.JobID,
+		State:      stateForPhase(rec.Phase),
+		TotalTasks: rec.TotalTasks,
+		StartedAt:  rec.StartedAt,
+		Error:      rec.LastError,
+	}
+	prefix := rec.ResultPrefix
+	if prefix == "" {
+		prefix = jobs.ResultPrefix(rec.ToolName, rec.JobID)
+	}
+	bucket := rec.Bucket
+	if bucket == "" {
+		bucket = r.cfg.Bucket
+	}
+	count, err := r.cfg.Provider.Storage().Count(ctx, bucket, prefix)
+	if err != nil {
+		return status, fmt.Errorf("counting results: %w", err)
+	}
+	status.CompletedTasks = count
+	if status.State == jobs.StateRunning && rec.TotalTasks > 0 && count >= rec.TotalTasks {
+		status.State = jobs.StateCompleted
+	}
+	return status, nil
+}
+
+func stateForPhase(p operator.Phase) jobs.State {
+	switch p {
+	case operator.PhaseUploading, operator.PhaseEnqueuing:
+		return jobs.StatePending
+	case operator.PhaseLaunching:
+		return jobs.StateDeploying
+	case operator.PhaseScanning:
+		return jobs.StateRunning
+	case operator.PhaseComplete:
+		return jobs.StateCompleted
+	case operator.PhaseFailed:
+		return jobs.StateFailed
+	default:
+		return jobs.StatePending
+	}
+}
+
+func (r *Runner) logf(format string, args ...any) {
+	if r.cfg.Logf != nil {
+		r.cfg.Logf(format, args...)
+	}
+}
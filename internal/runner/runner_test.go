@@ -0,0 +1,871 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/cloud/mock"
+	"heph4estus/internal/fleet"
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/politeness"
+	"heph4estus/internal/schedule"
+	"heph4estus/internal/scope"
+	"heph4estus/internal/targets"
+	"heph4estus/internal/tools/dns"
+	"heph4estus/internal/tools/nuclei"
+	"heph4estus/internal/tools/wordlist"
+	"heph4estus/internal/worker"
+)
+
+// fakeCloud records calls against in-memory storage and queue state.
+type fakeCloud struct {
+	mu         sync.Mutex
+	objects    map[string][]byte
+	sent       []string
+	containers []cloud.ContainerOpts
+	spots      []cloud.SpotOpts
+	stopped    []string
+	launchErr  error
+	// results is returned by Count once workers were launched.
+	results int
+	// drainOnLaunch makes each launch run the tasks queued so far, each
+	// taking 2s, and exit on the empty queue like real workers.
+	drainOnLaunch bool
+	consumed      int
+}
+
+// drain writes a result for every task queued since the last launch.
+func (f *fakeCloud) drain() {
+	for _, body := range f.sent[f.consumed:] {
+		var task worker.Task
+		_ = json.Unmarshal([]byte(body), &task)
+		data, _ := json.Marshal(worker.Result{JobID: task.JobID, ChunkIdx: task.ChunkIdx, DurationMs: 2000})
+		f.objects[fmt.Sprintf("%schunk_%d.json", jobs.ResultPrefix(task.ToolName, task.JobID), task.ChunkIdx)] = data
+		f.results++
+	}
+	f.consumed = len(f.sent)
+}
+
+func newFakeCloud() *fakeCloud {
+	return &fakeCloud{objects: map[string][]byte{}}
+}
+
+func (f *fakeCloud) provider() Services {
+	return Services{
+		S: &mock.Storage{
+			UploadFunc: func(_ context.Context, _, key string, data []byte) error {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.objects[key] = data
+				return nil
+			},
+			DownloadFunc: func(_ context.Context, _, key string) ([]byte, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				data, ok := f.objects[key]
+				if !ok {
+					return nil, fmt.Errorf("not found: %s", key)
+				}
+				return data, nil
+			},
+			ListFunc: func(_ context.Context, _, prefix string) ([]string, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				var keys []string
+				for k := range f.objects {
+					if strings.HasPrefix(k, prefix) {
+						keys = append(keys, k)
+					}
+				}
+				return keys, nil
+			},
+			CountFunc: func(context.Context, string, string) (int, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				return f.results, nil
+			},
+		},
+		Q: &mock.Queue{
+			SendBatchFunc: func(_ context.Context, _ string, bodies []string) error {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.sent = append(f.sent, bodies...)
+				return nil
+			},
+		},
+		C: &mock.Compute{
+			RunContainerFunc: func(_ context.Context, opts cloud.ContainerOpts) (string, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				if f.launchErr != nil {
+					return "", f.launchErr
+				}
+				f.containers = append(f.containers, opts)
+				if f.drainOnLaunch {
+					f.drain()
+				}
+				return "task-1", nil
+			},
+			RunSpotInstancesFunc: func(_ context.Context, opts cloud.SpotOpts) ([]string, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.spots = append(f.spots, opts)
+				return []string{"i-1", "i-2"}, nil
+			},
+			StopWorkersFunc: func(_ context.Context, ids []string) error {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.stopped = append(f.stopped, ids...)
+				return nil
+			},
+		},
+	}
+}
+
+func newTestRunner(t *testing.T, f *fakeCloud, mutate func(*Config)) (*Runner, *operator.JobStore) {
+	t.Helper()
+	store := operator.NewJobStoreAt(t.TempDir())
+	cfg := Config{
+		Provider:     f.provider(),
+		Tracker:      operator.NewTracker(store),
+		Cloud:        cloud.KindAWS,
+		QueueID:      "queue-1",
+		Bucket:       "bucket-1",
+		Outputs:      map[string]string{"ecs_cluster_name": "cluster", "subnet_ids": "[a b]"},
+		Workers:      2,
+		ComputeMode:  "auto",
+		PollInterval: time.Millisecond,
+	}
+	if mutate != nil {
+		mutate(&cfg)
+	}
+	r, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return r, store
+}
+
+func TestNew_Validates(t *testing.T) {
+	if _, err := New(Config{QueueID: "q", Bucket: "b"}); err == nil {
+		t.Error("expected error without provider")
+	}
+	if _, err := New(Config{Provider: newFakeCloud().provider(), Bucket: "b"}); err == nil {
+		t.Error("expected error without queue")
+	}
+}
+
+func TestPlan_TargetList(t *testing.T) {
+	r, _ := newTestRunner(t, newFakeCloud(), nil)
+	plan, err := r.Plan(jobs.JobConfig{
+		ToolName: "httpx",
+		Targets:  []byte("a.example.com\n# comment\n\nb.example.com\n"),
+		Options:  "-silent",
+	}, "job-1")
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(plan.Tasks) != 2 {
+		t.Fatalf("tasks = %d, want 2", len(plan.Tasks))
+	}
+	if plan.Tasks[1].Target != "b.example.com" || plan.Tasks[1].Options != "-silent" || plan.Tasks[1].JobID != "job-1" {
+		t.Errorf("unexpected task: %+v", plan.Tasks[1])
+	}
+	if plan.Unit() != "targets" {
+		t.Errorf("unit = %q, want targets", plan.Unit())
+	}
+}
+
+func TestPlan_NormalizesTargets(t *testing.T) {
+	r, _ := newTestRunner(t, newFakeCloud(), nil)
+	plan, err := r.Plan(jobs.JobConfig{
+		ToolName:      "httpx",
+		Targets:       []byte("example.com\nhttps://example.com/\nEXAMPLE.com\napi.example.com:8443\n"),
+		TargetOptions: &targets.Options{Accept: []targets.Kind{targets.KindURL, targets.KindHostPort}},
+	}, "job-norm")
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	var got []string
+	for _, task := range plan.Tasks {
+		got = append(got, task.Target)
+	}
+	if strings.Join(got, ",") != "https://example.com,api.example.com:8443" {
+		t.Errorf("targets = %v", got)
+	}
+	if plan.TargetStats == nil || plan.TargetStats.Duplicates != 2 || plan.TargetStats.Converted != 2 {
+		t.Errorf("stats = %+v", plan.TargetStats)
+	}
+
+	_, err = r.Plan(jobs.JobConfig{
+		ToolName:      "dnsx",
+		Targets:       []byte("10.0.0.0/24\n"),
+		TargetOptions: &targets.Options{Accept: []targets.Kind{targets.KindHostname}},
+	}, "")
+	if err == nil || !strings.Contains(err.Error(), "cidr targets are not accepted") {
+		t.Errorf("Plan = %v, want kind mismatch error", err)
+	}
+}
+
+func TestPlan_NoTargets(t *testing.T) {
+	r, _ := newTestRunner(t, newFakeCloud(), nil)
+	if _, err := r.Plan(jobs.JobConfig{ToolName: "httpx", Targets: []byte("# only\n")}, ""); err == nil {
+		t.Error("expected error for empty target list")
+	}
+}
+
+func TestPlan_PrebuiltTasks(t *testing.T) {
+	r, _ := newTestRunner(t, newFakeCloud(), nil)
+	plan, err := r.Plan(jobs.JobConfig{
+		ToolName: "nmap",
+		Tasks:    []worker.Task{{Target: "10.0.0.1", GroupID: "g", ChunkIdx: 1, TotalChunks: 2}},
+	}, "job-2")
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	got := plan.Tasks[0]
+	if got.ToolName != "nmap" || got.JobID != "job-2" || got.GroupID != "g" || got.TotalChunks != 2 {
+		t.Errorf("unexpected task: %+v", got)
+	}
+}
+
+func TestPlan_Wordlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("admin\nlogin\napi\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, _ := newTestRunner(t, newFakeCloud(), nil)
+	plan, err := r.Plan(jobs.JobConfig{
+		ToolName:      "ffuf",
+		WordlistPath:  path,
+		RuntimeTarget: "https://example.com/FUZZ",
+		ChunkCount:    2,
+	}, "job-3")
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	defer func() { _ = plan.Cleanup() }()
+	if plan.Wordlist == nil || plan.Wordlist.TotalWords != 3 {
+		t.Fatalf("unexpected wordlist plan: %+v", plan.Wordlist)
+	}
+	if len(plan.Tasks) != 2 || plan.Unit() != "chunks" {
+		t.Errorf("tasks = %d unit = %q, want 2 chunks", len(plan.Tasks), plan.Unit())
+	}
+}
+
+func TestPlan_ScopeRejectsOutOfScopeTargets(t *testing.T) {
+	sc, err := scope.New([]string{"10.0.0.0/24", "*.example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := newTestRunner(t, newFakeCloud(), nil)
+	_, err = r.Plan(jobs.JobConfig{ToolName: "httpx", Targets: []byte("10.0.0.1\nwww.example.org\n"), Scope: sc}, "")
+	if !errors.Is(err, scope.ErrOutOfScope) || !strings.Contains(err.Error(), "www.example.org") {
+		t.Fatalf("Plan = %v, want out-of-scope error naming the target", err)
+	}
+}
+
+func TestPlan_ScopeDigestEmbeddedInTasks(t *testing.T) {
+	sc, err := scope.New([]string{"10.0.0.0/24", "*.example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, store := newTestRunner(t, newFakeCloud(), nil)
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "httpx", Targets: []byte("10.0.0.1\nhttps://www.example.com\n"), Scope: sc}, "job-scope")
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	want := sc.Digest().SHA256
+	for _, task := range plan.Tasks {
+		if task.Scope == nil || task.Scope.SHA256 != want {
+			t.Fatalf("task %q missing scope digest: %+v", task.Target, task.Scope)
+		}
+	}
+	if err := r.Start(context.Background(), plan); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := store.Load("job-scope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.ScopeDigest != want {
+		t.Errorf("record scope digest = %q, want %q", rec.ScopeDigest, want)
+	}
+}
+
+func TestStart_EnqueuesAndLaunches(t *testing.T) {
+	f := newFakeCloud()
+	r, store := newTestRunner(t, f, func(c *Config) { c.JitterMaxSeconds = 7 })
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "httpx", Targets: []byte("a\nb\nc\n")}, "job-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Start(context.Background(), plan); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if len(f.sent) != 3 {
+		t.Fatalf("sent = %d, want 3", len(f.sent))
+	}
+	var task worker.Task
+	if err := json.Unmarshal([]byte(f.sent[0]), &task); err != nil || task.Target != "a" {
+		t.Errorf("unexpected first body %q: %v", f.sent[0], err)
+	}
+	if len(f.containers) != 1 || len(f.spots) != 0 {
+		t.Fatalf("containers=%d spots=%d, want 1/0", len(f.containers), len(f.spots))
+	}
+	opts := f.containers[0]
+	if opts.ContainerName != "httpx-worker" || opts.Count != 2 || opts.Cluster != "cluster" {
+		t.Errorf("unexpected container opts: %+v", opts)
+	}
+	if opts.Env["QUEUE_URL"] != "queue-1" || opts.Env["S3_BUCKET"] != "bucket-1" || opts.Env["JITTER_MAX_SECONDS"] != "7" {
+		t.Errorf("unexpected worker env: %v", opts.Env)
+	}
+
+	rec, err := store.Load("job-4")
+	if err != nil {
+		t.Fatalf("load record: %v", err)
+	}
+	if rec.Phase != operator.PhaseScanning || rec.TotalTasks != 3 {
+		t.Errorf("record phase=%s total=%d, want scanning/3", rec.Phase, rec.TotalTasks)
+	}
+}
+
+func TestStart_StampsRateShare(t *testing.T) {
+	f := newFakeCloud()
+	r, store := newTestRunner(t, f, func(c *Config) { c.Workers = 4; c.GlobalRate = 1000 })
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "nuclei", Targets: []byte("a\nb\n")}, "job-rate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Start(context.Background(), plan); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	var task worker.Task
+	if err := json.Unmarshal([]byte(f.sent[0]), &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.GlobalRate != 1000 || task.Rate != 250 {
+		t.Errorf("task rate = %d of %d, want 250 of 1000", task.Rate, task.GlobalRate)
+	}
+	rec, err := store.Load("job-rate")
+	if err != nil {
+		t.Fatalf("load record: %v", err)
+	}
+	if rec.GlobalRate != 1000 {
+		t.Errorf("record global rate = %d, want 1000", rec.GlobalRate)
+	}
+}
+
+func TestStart_StampsPerTargetLimits(t *testing.T) {
+	f := newFakeCloud()
+	lim := &politeness.Limits{Concurrency: 2, Rate: 6}
+	r, _ := newTestRunner(t, f, func(c *Config) { c.Politeness = lim })
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "ffuf", Targets: []byte("a\n")}, "job-polite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Start(context.Background(), plan); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	var task worker.Task
+	if err := json.Unmarshal([]byte(f.sent[0]), &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Politeness == nil || *task.Politeness != *lim {
+		t.Errorf("task politeness = %+v, want %+v", task.Politeness, lim)
+	}
+}
+
+func TestStart_StampsConfiguredWindow(t *testing.T) {
+	w, err := schedule.New("mon-fri 09:00-17:00", "UTC", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeCloud()
+	r, store := newTestRunner(t, f, func(c *Config) { c.Window = w })
+	// A caller-created record without a window must not clear it.
+	if err := store.Create(&operator.JobRecord{JobID: "job-window", ToolName: "httpx"}); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "httpx", Targets: []byte("a\n")}, "job-window")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Start(context.Background(), plan); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	var task worker.Task
+	if err := json.Unmarshal([]byte(f.sent[0]), &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Window == nil || task.Window.String() != w.String() {
+		t.Errorf("task window = %v, want %v", task.Window, w)
+	}
+	if env := f.containers[0].Env["SCAN_WINDOW"]; env == "" {
+		t.Error("workers launched without SCAN_WINDOW")
+	}
+}
+
+func TestPlan_WordlistTransform(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("a\nA\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeCloud()
+	r, _ := newTestRunner(t, f, nil)
+	tr := &wordlist.Transform{Dedupe: true, Lowercase: true}
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "ffuf", WordlistPath: path, RuntimeTarget: "https://x/FUZZ", WordlistTransform: tr}, "job-rewrite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = plan.Cleanup() }()
+	if plan.Wordlist.TotalWords != 2 || plan.Wordlist.TransformStats.Duplicates != 1 {
+		t.Errorf("words = %d, stats = %+v", plan.Wordlist.TotalWords, plan.Wordlist.TransformStats)
+	}
+}
+
+func TestPlan_TemplateShards(t *testing.T) {
+	f := newFakeCloud()
+	r, _ := newTestRunner(t, f, nil)
+	shards := &nuclei.ShardPlan{By: nuclei.ShardByTag, Templates: 5, Shards: []nuclei.Shard{{Templates: 3, Filter: "-tags cve"}, {Templates: 2, Filter: "-tags panel"}}}
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "nuclei", Targets: []byte("a.example\nb.example\n"), Options: "-severity high", TemplateShards: shards}, "job-shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Tasks) != 4 || plan.Unit() != "tasks" {
+		t.Fatalf("tasks = %d, unit = %s", len(plan.Tasks), plan.Unit())
+	}
+	if got := plan.Tasks[3]; got.Target != "b.example" || got.Options != "-severity high -tags panel" || got.ChunkIdx != 1 || got.TotalChunks != 2 {
+		t.Errorf("task = %+v", got)
+	}
+	if rec := r.NewRecord(plan); rec.TemplateShards.String() != shards.String() || rec.TotalTasks != 4 {
+		t.Errorf("record shards = %v, total = %d", rec.TemplateShards, rec.TotalTasks)
+	}
+}
+
+func TestPlan_WildcardProbes(t *testing.T) {
+	f := newFakeCloud()
+	r, store := newTestRunner(t, f, nil)
+	if err := store.Create(&operator.JobRecord{JobID: "job-wc", ToolName: "dnsx"}); err != nil {
+		t.Fatal(err)
+	}
+	sc, err := scope.New([]string{"*.example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "dnsx", Targets: []byte("www.example.com\napi.example.com\n"), Scope: sc, WildcardProbe: true}, "job-wc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.WildcardProbes != dns.ProbesPerZone || len(plan.Tasks) != 2+dns.ProbesPerZone {
+		t.Fatalf("probes = %d, tasks = %d", plan.WildcardProbes, len(plan.Tasks))
+	}
+	probe := plan.Tasks[0]
+	if probe.GroupID != dns.ProbeGroup || probe.Target != dns.ProbeName("job-wc", "example.com", 0) {
+		t.Errorf("probe task = %+v", probe)
+	}
+	if rec := r.NewRecord(plan); !rec.WildcardProbe || rec.TotalTasks != len(plan.Tasks) {
+		t.Errorf("record wildcard probe = %v, total = %d", rec.WildcardProbe, rec.TotalTasks)
+	}
+
+	f.objects[jobs.ArtifactPrefix("dnsx", "job-wc")+dns.ProbeGroup+"/probe_chunk0_of_1_1.jsonl"] = []byte(`{"host":"` + probe.Target + `","a":["1.2.3.4"]}` + "\n")
+	w, err := r.RecordWildcards(context.Background(), "dnsx", "job-wc")
+	if err != nil {
+		t.Fatalf("RecordWildcards: %v", err)
+	}
+	if w.String() != "example.com (1.2.3.4)" {
+		t.Errorf("wildcards = %q", w)
+	}
+	if rec, err := store.Load("job-wc"); err != nil || rec.Wildcards.String() != w.String() {
+		t.Errorf("recorded wildcards = %v, %v", rec.Wildcards, err)
+	}
+}
+
+func TestPlan_WildcardProbesSkipOutOfScope(t *testing.T) {
+	r, _ := newTestRunner(t, newFakeCloud(), nil)
+	sc, err := scope.New([]string{"www.example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "dnsx", Targets: []byte("www.example.com\n"), Scope: sc, WildcardProbe: true}, "job-wc2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.WildcardProbes != 0 || len(plan.Tasks) != 1 {
+		t.Errorf("probes = %d, tasks = %d", plan.WildcardProbes, len(plan.Tasks))
+	}
+}
+
+func TestStart_UploadsAssets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "probe.nse"), []byte("probe\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeCloud()
+	r, _ := newTestRunner(t, f, nil)
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "nmap", Targets: []byte("10.0.0.1\n10.0.0.2\n"), Assets: &jobs.AssetBundle{Name: "scripts", Dir: dir}}, "job-assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := jobs.AssetKey("nmap", "job-assets", "scripts")
+	if plan.Assets == nil || plan.Assets.Files != 1 {
+		t.Fatalf("plan assets = %+v", plan.Assets)
+	}
+	for _, task := range plan.Tasks {
+		if task.Assets != key {
+			t.Errorf("task assets = %q, want %q", task.Assets, key)
+		}
+	}
+	if err := r.Start(context.Background(), plan); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, ok := f.objects[key]; !ok {
+		t.Errorf("bundle not uploaded to %s", key)
+	}
+}
+
+func TestStart_UploadsWordlistChunks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("a\nb\nc\nd\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeCloud()
+	r, store := newTestRunner(t, f, nil)
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "ffuf", WordlistPath: path, RuntimeTarget: "https://x/FUZZ", ChunkCount: 2}, "job-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = plan.Cleanup() }()
+	if err := r.Start(context.Background(), plan); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if len(f.objects) != 2 {
+		t.Errorf("uploaded %d chunks, want 2", len(f.objects))
+	}
+	rec, _ := store.Load("job-5")
+	if rec.TotalWords != 4 || rec.RuntimeTarget != "https://x/FUZZ" {
+		t.Errorf("record words=%d target=%q", rec.TotalWords, rec.RuntimeTarget)
+	}
+}
+
+func TestStart_ReusesCachedWordlistChunks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("a\nb\nc\nd\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeCloud()
+	r, _ := newTestRunner(t, f, func(c *Config) { c.ChunkCache = true })
+	run := func(jobID string) *Plan {
+		plan, err := r.Plan(jobs.JobConfig{ToolName: "ffuf", WordlistPath: path, RuntimeTarget: "https://x/FUZZ", ChunkCount: 2}, jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = plan.Cleanup() }()
+		if err := r.Start(context.Background(), plan); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		return plan
+	}
+
+	first := run("job-a")
+	if first.Wordlist.Cached || len(f.objects) != 3 {
+		t.Fatalf("first job cached=%v objects=%d, want a fresh split of 2 chunks plus a manifest", first.Wordlist.Cached, len(f.objects))
+	}
+	second := run("job-b")
+	if !second.Wordlist.Cached || second.Wordlist.TotalWords != 4 || len(f.objects) != 3 {
+		t.Fatalf("second job cached=%v words=%d objects=%d", second.Wordlist.Cached, second.Wordlist.TotalWords, len(f.objects))
+	}
+	if second.Tasks[0].InputKey != first.Tasks[0].InputKey || second.Tasks[0].JobID != "job-b" {
+		t.Errorf("second job task = %+v", second.Tasks[0])
+	}
+}
+
+func TestStart_ShipsNamedWordlistsWhole(t *testing.T) {
+	dir := t.TempDir()
+	paths := filepath.Join(dir, "paths.txt")
+	users := filepath.Join(dir, "users.txt")
+	if err := os.WriteFile(paths, []byte("a\nb\nc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(users, []byte("root\nadmin\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeCloud()
+	r, _ := newTestRunner(t, f, func(c *Config) { c.ChunkCache = true })
+	cfg := jobs.JobConfig{ToolName: "ffuf", WordlistPath: paths, RuntimeTarget: "https://x/FUZZ/W2", ChunkCount: 3, Wordlists: map[string]string{"W2": users}}
+	run := func(jobID string) *Plan {
+		plan, err := r.Plan(cfg, jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = plan.Cleanup() }()
+		if err := r.Start(context.Background(), plan); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		return plan
+	}
+
+	first := run("job-a")
+	whole := first.Wordlist.Whole["W2"]
+	if whole == nil || whole.TotalWords != 2 || len(first.Tasks) != 3 {
+		t.Fatalf("plan = %+v", first.Wordlist)
+	}
+	for _, task := range first.Tasks {
+		if task.Wordlists["W2"] != whole.ChunkKeys[0] {
+			t.Fatalf("task wordlists = %v, want W2=%s", task.Wordlists, whole.ChunkKeys[0])
+		}
+	}
+	if data := f.objects[whole.ChunkKeys[0]]; string(data) != "root\nadmin\n" {
+		t.Fatalf("uploaded W2 = %q", data)
+	}
+	objects := len(f.objects)
+	second := run("job-b")
+	if !second.Wordlist.Whole["W2"].Cached || len(f.objects) != objects {
+		t.Fatalf("second job re-uploaded W2: cached=%v objects=%d, want %d", second.Wordlist.Whole["W2"].Cached, len(f.objects), objects)
+	}
+}
+
+func TestStart_AdaptiveChunksFromCalibration(t *testing.T) {
+	var words strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&words, "w%d\n", i)
+	}
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte(words.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeCloud()
+	// Both sample chunks report 200 entries in 2s: 10ms per entry.
+	for i := 0; i < 2; i++ {
+		data, _ := json.Marshal(worker.Result{ChunkIdx: i, DurationMs: 2000})
+		f.objects[fmt.Sprintf("%ssample_%d.json", jobs.ResultPrefix("ffuf", "job-cal"), i)] = data
+	}
+	f.results = 2
+	r, store := newTestRunner(t, f, nil)
+
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "ffuf", WordlistPath: path, RuntimeTarget: "https://x/FUZZ", TaskDuration: 4 * time.Second}, "job-cal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = plan.Cleanup() }()
+	if !plan.Calibrating() || len(plan.Tasks) != 2 || plan.Wordlist.TotalWords != 2*CalibrationEntries {
+		t.Fatalf("sample plan: calibrating=%v tasks=%d words=%d", plan.Calibrating(), len(plan.Tasks), plan.Wordlist.TotalWords)
+	}
+	if err := r.Start(context.Background(), plan); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// 1600 remaining entries at 400 per 4s chunk.
+	if len(plan.Tasks) != 6 || len(f.sent) != 6 {
+		t.Fatalf("tasks=%d sent=%d, want 6", len(plan.Tasks), len(f.sent))
+	}
+	if last := plan.Tasks[5]; last.ChunkIdx != 5 || last.TotalChunks != 6 || last.InputKey != jobs.InputKey("ffuf", "job-cal", 5) {
+		t.Errorf("last task = %+v", last)
+	}
+	rec, _ := store.Load("job-cal")
+	cal := rec.Calibration
+	if rec.TotalTasks != 6 || rec.TotalWords != 2000 || cal == nil || !cal.Done() {
+		t.Fatalf("record tasks=%d words=%d calibration=%+v", rec.TotalTasks, rec.TotalWords, cal)
+	}
+	if cal.PerEntry != 10*time.Millisecond || cal.Source != "results" || cal.Chunks != 4 || cal.EntriesPerChunk != 400 {
+		t.Errorf("calibration = %+v", cal)
+	}
+}
+
+func TestStart_AdaptiveLaunchesWorkersForRemainder(t *testing.T) {
+	var words strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&words, "w%d\n", i)
+	}
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte(words.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeCloud()
+	f.drainOnLaunch = true
+	r, _ := newTestRunner(t, f, nil)
+
+	plan, err := r.Plan(jobs.JobConfig{ToolName: "ffuf", WordlistPath: path, RuntimeTarget: "https://x/FUZZ", TaskDuration: 4 * time.Second}, "job-cal-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = plan.Cleanup() }()
+	if err := r.Start(context.Background(), plan); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if len(f.containers) != 2 {
+		t.Fatalf("launches = %d, want one for the sample and one for the remainder", len(f.containers))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := r.Wait(ctx, plan.ToolName, plan.JobID, len(plan.Tasks), nil); err != nil {
+		t.Fatalf("Wait for %d tasks: %v", len(plan.Tasks), err)
+	}
+}
+
+func TestAdaptiveChunkCount(t *testing.T) {
+	for _, tc := range []struct {
+		entries  int
+		bytes    int64
+		perEntry time.Duration
+		workers  int
+		want     int
+	}{
+		{1000, 10_000, time.Second, 2, 5},                             // 240 entries per 4m chunk
+		{1000, 10_000, time.Millisecond, 2, 2},                        // fast: keep every worker busy
+		{1000, 10_000, time.Hour, 2, 1000},                            // slow: one entry per chunk
+		{1000, 3 * wordlist.MaxSafeChunkSize, time.Millisecond, 1, 4}, // size cap with headroom
+	} {
+		if got := adaptiveChunkCount(tc.entries, tc.bytes, tc.perEntry, 4*time.Minute, tc.workers); got != tc.want {
+			t.Errorf("adaptiveChunkCount(%d, %d, %s, %d) = %d, want %d", tc.entries, tc.bytes, tc.perEntry, tc.workers, got, tc.want)
+		}
+	}
+}
+
+func TestStart_LaunchFailureRecorded(t *testing.T) {
+	f := newFakeCloud()
+	f.launchErr = fmt.Errorf("capacity")
+	r, store := newTestRunner(t, f, nil)
+	plan, _ := r.Plan(jobs.JobConfig{ToolName: "httpx", Targets: []byte("a\n")}, "job-6")
+	if err := r.Start(context.Background(), plan); err == nil {
+		t.Fatal("expected launch error")
+	}
+	rec, _ := store.Load("job-6")
+	if rec.Phase != operator.PhaseFailed || !strings.Contains(rec.LastError, "capacity") {
+		t.Errorf("record phase=%s err=%q", rec.Phase, rec.LastError)
+	}
+}
+
+func TestLaunch_SpotForLargeFleets(t *testing.T) {
+	f := newFakeCloud()
+	r, _ := newTestRunner(t, f, func(c *Config) { c.Workers = SpotThreshold })
+	n, err := r.Launch(context.Background(), "nuclei")
+	if err != nil {
+		t.Fatalf("Launch: %v", err)
+	}
+	if n != 2 || len(f.spots) != 1 || len(f.containers) != 0 {
+		t.Errorf("n=%d spots=%d containers=%d", n, len(f.spots), len(f.containers))
+	}
+	if f.spots[0].Tags["Tool"] != "nuclei" || f.spots[0].UserData == "" {
+		t.Errorf("unexpected spot opts: %+v", f.spots[0])
+	}
+}
+
+func TestLaunch_ProviderNativeWaitsForFleet(t *testing.T) {
+	f := newFakeCloud()
+	var waited bool
+	r, _ := newTestRunner(t, f, func(c *Config) {
+		c.Cloud = cloud.KindHetzner
+		c.WaitForFleet = func(context.Context, cloud.Kind, map[string]string, fleet.PlacementPolicy) (int, error) {
+			waited = true
+			return 4, nil
+		}
+	})
+	n, err := r.Launch(context.Background(), "httpx")
+	if err != nil {
+		t.Fatalf("Launch: %v", err)
+	}
+	if !waited || n != 4 || len(f.containers) != 0 || len(f.spots) != 0 {
+		t.Errorf("waited=%v n=%d containers=%d spots=%d", waited, n, len(f.containers), len(f.spots))
+	}
+}
+
+func TestLaunch_ProviderNativeRequiresWaiter(t *testing.T) {
+	r, _ := newTestRunner(t, newFakeCloud(), func(c *Config) { c.Cloud = cloud.KindHetzner })
+	if _, err := r.Launch(context.Background(), "httpx"); err == nil {
+		t.Error("expected error without fleet waiter")
+	}
+}
+
+func TestWait_HonoursContext(t *testing.T) {
+	r, _ := newTestRunner(t, newFakeCloud(), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Wait(ctx, "httpx", "job-7", 5, nil); err == nil {
+		t.Error("expected context error")
+	}
+}
+
+func TestRun_EndToEnd(t *testing.T) {
+	f := newFakeCloud()
+	r, store := newTestRunner(t, f, nil)
+	f.results = 2
+
+	var last Progress
+	out, err := r.Run(context.Background(), jobs.JobConfig{ToolName: "httpx", Targets: []byte("a\nb\n")}, "", func(p Progress) { last = p })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !out.Started || out.TotalTasks != 2 {
+		t.Errorf("unexpected outcome: %+v", out)
+	}
+	if last.Completed != 2 || last.Percent() != 100 {
+		t.Errorf("last progress = %+v", last)
+	}
+	rec, _ := store.Load(out.JobID)
+	if rec.Phase != operator.PhaseComplete {
+		t.Errorf("phase = %s, want complete", rec.Phase)
+	}
+
+	status, err := r.Status(context.Background(), out.JobID)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.State != jobs.StateCompleted || status.CompletedTasks != 2 {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestSubmit_ReturnsJobID(t *testing.T) {
+	f := newFakeCloud()
+	r, _ := newTestRunner(t, f, nil)
+	id, err := r.Submit(context.Background(), jobs.JobConfig{ToolName: "httpx", Targets: []byte("a\n")})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if !strings.HasPrefix(id, "httpx-") {
+		t.Errorf("job id = %q", id)
+	}
+	status, err := r.Status(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.State != jobs.StateRunning {
+		t.Errorf("state = %s, want running", status.State)
+	}
+}
+
+func TestEnqueueTasks_RejectsOversizedPayload(t *testing.T) {
+	q := &mock.Queue{SendBatchFunc: func(context.Context, string, []string) error { return nil }}
+	big := worker.Task{ToolName: "nmap", Options: strings.Repeat("x", MaxTaskPayload)}
+	if err := EnqueueTasks(context.Background(), q, "q", []worker.Task{big}); err == nil {
+		t.Error("expected payload size error")
+	}
+}
+
+func TestStateForPhase(t *testing.T) {
+	cases := map[operator.Phase]jobs.State{
+		operator.PhaseUploading: jobs.StatePending,
+		operator.PhaseEnqueuing: jobs.StatePending,
+		operator.PhaseLaunching: jobs.StateDeploying,
+		operator.PhaseScanning:  jobs.StateRunning,
+		operator.PhaseComplete:  jobs.StateCompleted,
+		operator.PhaseFailed:    jobs.StateFailed,
+	}
+	for phase, want := range cases {
+		if got := stateForPhase(phase); got != want {
+			t.Errorf("stateForPhase(%s) = %s, want %s", phase, got, want)
+		}
+	}
+}
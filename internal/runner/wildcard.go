@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"heph4estus/internal/jobs"
+	"heph4estus/internal/scope"
+	"heph4estus/internal/targets"
+	"heph4estus/internal/tools/dns"
+	"heph4estus/internal/worker"
+)
+
+// WildcardProbes returns the probe tasks for the entries' zones.
+// Probes whose random name falls outside sc are skipped rather than
+// failing the plan.
+func WildcardProbes(tool, jobID string, entries []targets.Entry, sc *scope.Scope) []worker.Task {
+	hosts := make([]string, len(entries))
+	for i, e := range entries {
+		hosts[i] = e.Target
+	}
+	var probes []worker.Task
+	for _, t := range dns.ProbeTasks(tool, jobID, hosts) {
+		if sc != nil && sc.Check(t.Target) != nil {
+			continue
+		}
+		probes = append(probes, t)
+	}
+	return probes
+}
+
+// RecordWildcards reads the job's probe reports, records the wildcard
+// answers they found on the job and returns them.
+func (r *Runner) RecordWildcards(ctx context.Context, tool, jobID string) (dns.Wildcards, error) {
+	storage := r.cfg.Provider.Storage()
+	prefix := jobs.ArtifactPrefix(tool, jobID) + dns.ProbeGroup + "/"
+	keys, err := storage.List(ctx, r.cfg.Bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing wildcard probes: %w", err)
+	}
+	reports := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		data, err := storage.Download(ctx, r.cfg.Bucket, key)
+		if err != nil {
+			return nil, fmt.Errorf("downloading wildcard probe %s: %w", key, err)
+		}
+		reports[key] = data
+	}
+	w := dns.DetectWildcards(reports)
+	if store := r.cfg.Tracker.Store(); store != nil {
+		if rec, err := store.Load(jobID); err == nil {
+			rec.Wildcards = w
+			_ = store.Update(rec)
+		}
+	}
+	return w, nil
+}
@@ -0,0 +1,350 @@
+// Package schedule restricts when a job may scan. A window is a set of
+// weekly time ranges in a time zone, minus blackout dates. Workers stop
+// pulling tasks while the window is closed and resume when it opens.
+//
+// Range syntax, several ranges separated by ";":
+//
+//	09:00-17:00              every day
+//	Mon-Fri 09:00-17:00      weekdays
+//	Sat,Sun 10:00-14:00      listed days
+//	Fri 22:00-06:00          overnight; the range belongs to its start day
+//
+// Blackouts are dates or inclusive date ranges in the window's time zone,
+// separated by ",": "2026-12-24,2026-12-31..2027-01-01".
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Window is when a job may run. The zero window is always open.
+type Window struct {
+	// TZ is an IANA time zone name; empty means UTC.
+	TZ string `json:"tz,omitempty"`
+	// Ranges are the weekly open periods. Without ranges the window is
+	// open at any time that is not blacked out.
+	Ranges    []Range  `json:"ranges,omitempty"`
+	Blackouts []string `json:"blackouts,omitempty"`
+}
+
+// Range is one weekly open period.
+type Range struct {
+	// Days the range starts on; empty means every day.
+	Days []time.Weekday `json:"days,omitempty"`
+	// Start and End are minutes after midnight. An End at or before Start
+	// wraps past midnight into the next day.
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// New builds a window from range, time zone and blackout specs. It returns
+// nil when all three are empty.
+func New(ranges, tz, blackouts string) (*Window, error) {
+	ranges, tz, blackouts = strings.TrimSpace(ranges), strings.TrimSpace(tz), strings.TrimSpace(blackouts)
+	if ranges == "" && tz == "" && blackouts == "" {
+		return nil, nil
+	}
+	w := &Window{TZ: tz}
+	for _, spec := range strings.Split(ranges, ";") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		r, err := parseRange(spec)
+		if err != nil {
+			return nil, err
+		}
+		w.Ranges = append(w.Ranges, r)
+	}
+	for _, b := range strings.Split(blackouts, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			w.Blackouts = append(w.Blackouts, b)
+		}
+	}
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Validate checks the time zone, ranges and blackout dates.
+func (w *Window) Validate() error {
+	if _, err := w.location(); err != nil {
+		return err
+	}
+	for _, r := range w.Ranges {
+		if r.Start < 0 || r.Start >= 24*60 || r.End < 0 || r.End > 24*60 {
+			return fmt.Errorf("window range %s: time out of range", r)
+		}
+	}
+	for _, b := range w.Blackouts {
+		if _, _, err := parseBlackout(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open reports whether the window is open at t.
+func (w *Window) Open(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	loc, err := w.location()
+	if err != nil {
+		return false
+	}
+	lt := t.In(loc)
+	if w.blackedOut(lt) {
+		return false
+	}
+	if len(w.Ranges) == 0 {
+		return true
+	}
+	minute := lt.Hour()*60 + lt.Minute()
+	today := lt.Weekday()
+	yesterday := (today + 6) % 7
+	for _, r := range w.Ranges {
+		if r.End > r.Start {
+			if r.onDay(today) && minute >= r.Start && minute < r.End {
+				return true
+			}
+			continue
+		}
+		if (r.onDay(today) && minute >= r.Start) || (r.onDay(yesterday) && minute < r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the first time at or after t when the window is open,
+// or the zero time when it stays closed for the next year.
+func (w *Window) NextOpen(t time.Time) time.Time {
+	if w.Open(t) {
+		return t
+	}
+	loc, err := w.location()
+	if err != nil {
+		return time.Time{}
+	}
+	lt := t.In(loc)
+	day := time.Date(lt.Year(), lt.Month(), lt.Day(), 0, 0, 0, 0, loc)
+	// The window can only open at a range start or at midnight, when a
+	// blackout ends.
+	for i := 0; i <= 366; i++ {
+		d := day.AddDate(0, 0, i)
+		candidates := []time.Time{d}
+		for _, r := range w.Ranges {
+			if r.onDay(d.Weekday()) {
+				candidates = append(candidates, d.Add(time.Duration(r.Start)*time.Minute))
+			}
+		}
+		sort.Slice(candidates, func(a, b int) bool { return candidates[a].Before(candidates[b]) })
+		for _, c := range candidates {
+			if c.After(t) && w.Open(c) {
+				return c
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// String summarises the window, e.g.
+// "Mon-Fri 09:00-17:00 (Europe/London), blackout 2026-12-24".
+func (w *Window) String() string {
+	if w == nil {
+		return "always"
+	}
+	var parts []string
+	for _, r := range w.Ranges {
+		parts = append(parts, r.String())
+	}
+	s := "any time"
+	if len(parts) > 0 {
+		s = strings.Join(parts, "; ")
+	}
+	if w.TZ != "" {
+		s += " (" + w.TZ + ")"
+	}
+	if len(w.Blackouts) > 0 {
+		s += ", blackout " + strings.Join(w.Blackouts, ",")
+	}
+	return s
+}
+
+// String renders the range in the syntax New accepts.
+func (r Range) String() string {
+	span := fmt.Sprintf("%02d:%02d-%02d:%02d", r.Start/60, r.Start%60, r.End/60, r.End%60)
+	if len(r.Days) == 0 {
+		return span
+	}
+	return formatDays(r.Days) + " " + span
+}
+
+func (r Range) onDay(d time.Weekday) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, day := range r.Days {
+		if day == d {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Window) location() (*time.Location, error) {
+	if w.TZ == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(w.TZ)
+	if err != nil {
+		return nil, fmt.Errorf("window time zone %q: %w", w.TZ, err)
+	}
+	return loc, nil
+}
+
+func (w *Window) blackedOut(lt time.Time) bool {
+	date := lt.Format(dateLayout)
+	for _, b := range w.Blackouts {
+		from, to, err := parseBlackout(b)
+		if err == nil && date >= from && date <= to {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBlackout returns the inclusive first and last dates of a blackout.
+func parseBlackout(s string) (string, string, error) {
+	from, to, isRange := strings.Cut(s, "..")
+	if !isRange {
+		to = from
+	}
+	for _, d := range []string{from, to} {
+		if _, err := time.Parse(dateLayout, d); err != nil {
+			return "", "", fmt.Errorf("blackout %q: dates must be YYYY-MM-DD", s)
+		}
+	}
+	if to < from {
+		return "", "", fmt.Errorf("blackout %q ends before it starts", s)
+	}
+	return from, to, nil
+}
+
+func parseRange(spec string) (Range, error) {
+	fields := strings.Fields(spec)
+	var r Range
+	switch len(fields) {
+	case 1:
+	case 2:
+		days, err := parseDays(fields[0])
+		if err != nil {
+			return Range{}, fmt.Errorf("window range %q: %w", spec, err)
+		}
+		r.Days = days
+	default:
+		return Range{}, fmt.Errorf("window range %q: want [days] HH:MM-HH:MM", spec)
+	}
+	start, end, ok := strings.Cut(fields[len(fields)-1], "-")
+	if !ok {
+		return Range{}, fmt.Errorf("window range %q: want HH:MM-HH:MM", spec)
+	}
+	var err error
+	if r.Start, err = parseClock(start); err != nil {
+		return Range{}, fmt.Errorf("window range %q: %w", spec, err)
+	}
+	if r.End, err = parseClock(end); err != nil {
+		return Range{}, fmt.Errorf("window range %q: %w", spec, err)
+	}
+	return r, nil
+}
+
+func parseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	hour, herr := strconv.Atoi(h)
+	minute, merr := strconv.Atoi(m)
+	if !ok || herr != nil || merr != nil || hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// parseDay accepts a day name or any prefix of it of at least three
+// letters, e.g. "Mon", "tues" or "Wednesday".
+func parseDay(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	if len(s) >= 3 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.HasPrefix(strings.ToLower(d.String()), s) {
+				return d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q", s)
+}
+
+// parseDays accepts "Mon-Fri", "Sat,Sun", "Fri-Mon" and "daily".
+func parseDays(s string) ([]time.Weekday, error) {
+	if l := strings.ToLower(s); l == "daily" || l == "*" {
+		return nil, nil
+	}
+	seen := make(map[time.Weekday]bool)
+	var days []time.Weekday
+	add := func(d time.Weekday) {
+		if !seen[d] {
+			seen[d] = true
+			days = append(days, d)
+		}
+	}
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := parseDay(from)
+		if err != nil {
+			return nil, err
+		}
+		if !isRange {
+			add(first)
+			continue
+		}
+		last, err := parseDay(to)
+		if err != nil {
+			return nil, err
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			add(d)
+			if d == last {
+				break
+			}
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+	return days, nil
+}
+
+func formatDays(days []time.Weekday) string {
+	names := make([]string, len(days))
+	for i, d := range days {
+		names[i] = d.String()[:3]
+	}
+	// Collapse a run of consecutive days into "Mon-Fri".
+	if len(days) > 2 {
+		consecutive := true
+		for i := 1; i < len(days); i++ {
+			if days[i] != days[i-1]+1 {
+				consecutive = false
+				break
+			}
+		}
+		if consecutive {
+			return names[0] + "-" + names[len(names)-1]
+		}
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,124 @@
+package schedule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func mustNew(t *testing.T, ranges, tz, blackouts string) *Window {
+	t.Helper()
+	w, err := New(ranges, tz, blackouts)
+	if err != nil {
+		t.Fatalf("New(%q, %q, %q): %v", ranges, tz, blackouts, err)
+	}
+	return w
+}
+
+func utc(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNewEmpty(t *testing.T) {
+	w, err := New(" ", "", "")
+	if err != nil || w != nil {
+		t.Fatalf("New(empty) = %v, %v; want nil, nil", w, err)
+	}
+	if !w.Open(time.Now()) {
+		t.Error("nil window should always be open")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	// 2026-10-19 is a Monday.
+	w := mustNew(t, "Mon-Fri 09:00-17:00; Sat 22:00-02:00", "", "2026-10-21")
+	tests := []struct {
+		at   string
+		want bool
+	}{
+		{"2026-10-19 08:59", false},
+		{"2026-10-19 09:00", true},
+		{"2026-10-19 16:59", true},
+		{"2026-10-19 17:00", false},
+		{"2026-10-21 12:00", false}, // blackout
+		{"2026-10-24 23:00", true},  // Saturday overnight
+		{"2026-10-25 01:30", true},  // ... into Sunday
+		{"2026-10-25 02:00", false},
+		{"2026-10-25 12:00", false},
+	}
+	for _, tt := range tests {
+		if got := w.Open(utc(tt.at)); got != tt.want {
+			t.Errorf("Open(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestOpenTimeZone(t *testing.T) {
+	w := mustNew(t, "09:00-17:00", "America/New_York", "")
+	// 13:00 UTC is 09:00 in New York (EDT).
+	if !w.Open(utc("2026-10-19 13:00")) {
+		t.Error("expected open at 09:00 New York time")
+	}
+	if w.Open(utc("2026-10-19 12:59")) {
+		t.Error("expected closed before 09:00 New York time")
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	w := mustNew(t, "Mon-Fri 09:00-17:00", "", "2026-10-20..2026-10-22")
+	tests := []struct{ at, want string }{
+		{"2026-10-19 10:00", "2026-10-19 10:00"}, // already open
+		{"2026-10-19 18:00", "2026-10-23 09:00"}, // skips the blackout
+		{"2026-10-24 12:00", "2026-10-26 09:00"}, // weekend
+	}
+	for _, tt := range tests {
+		if got := w.NextOpen(utc(tt.at)); !got.Equal(utc(tt.want)) {
+			t.Errorf("NextOpen(%s) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+
+	allDay := mustNew(t, "", "", "2026-10-19")
+	if got := allDay.NextOpen(utc("2026-10-19 12:00")); !got.Equal(utc("2026-10-20 00:00")) {
+		t.Errorf("NextOpen after blackout = %s", got)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	bad := []struct{ ranges, tz, blackouts string }{
+		{"Mon-Fri", "", ""},
+		{"Funday 09:00-10:00", "", ""},
+		{"09:00-25:00", "", ""},
+		{"9-17", "", ""},
+		{"", "Mars/Olympus", ""},
+		{"", "", "2026-13-01"},
+		{"", "", "2026-10-20..2026-10-19"},
+	}
+	for _, b := range bad {
+		if _, err := New(b.ranges, b.tz, b.blackouts); err == nil {
+			t.Errorf("New(%q, %q, %q) should fail", b.ranges, b.tz, b.blackouts)
+		}
+	}
+}
+
+func TestStringAndJSON(t *testing.T) {
+	w := mustNew(t, "mon-fri 09:00-17:00; Sat,Sun 10:00-12:00", "Europe/London", "2026-12-24")
+	want := "Mon-Fri 09:00-17:00; Sun,Sat 10:00-12:00 (Europe/London), blackout 2026-12-24"
+	if got := w.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Window
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.String() != want {
+		t.Errorf("round trip = %q", back.String())
+	}
+}
@@ -0,0 +1,45 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+
+	"heph4estus/internal/targets"
+)
+
+// Resolver looks up host addresses. *net.Resolver satisfies it.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// CheckResolved runs Check and, for host-name targets, resolves the name
+// and rejects it when any address matches an exclude rule. Lookup failures
+// are not violations: the tool will fail to reach the host on its own.
+func (s *Scope) CheckResolved(ctx context.Context, target string, r Resolver) error {
+	if err := s.Check(target); err != nil {
+		return err
+	}
+	t, _ := targets.Parse(target)
+	if t.Host == "" || t.IsAddr() || r == nil {
+		return nil
+	}
+	addrs, err := r.LookupIPAddr(ctx, t.Host)
+	if err != nil {
+		return nil
+	}
+	for _, a := range addrs {
+		addr, ok := netip.AddrFromSlice(a.IP)
+		if !ok {
+			continue
+		}
+		addr = addr.Unmap()
+		for _, ex := range s.exclude {
+			if ex.prefix.IsValid() && ex.prefix.Contains(addr) {
+				return &Violation{Target: target, Reason: fmt.Sprintf("resolves to %s, which matches exclude rule %s", addr, ex)}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+// Package scope enforces engagement scope: which IPs, networks and domains a
+// job may touch. A scope is a set of include and exclude rules loaded from a
+// scope file. It is checked when a job is planned and again by every worker
+// before it executes a task.
+//
+// Scope file format, one rule per line:
+//
+//	# comments and blank lines are ignored
+//	10.0.0.0/24        include a network
+//	192.0.2.10         include a single address
+//	example.com        include exactly this host name
+//	*.example.com      include every subdomain (not the apex)
+//	!10.0.0.1          exclude; excludes always win over includes
+//	!dev.example.com
+//
+// With no include rules the scope is a pure denylist: everything not
+// excluded is in scope. With include rules, a target must match one of them.
+// Host names are only matched by domain rules, never by resolving them at
+// planning time, so a host name needs a domain rule when includes are set.
+package scope
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+
+	"heph4estus/internal/targets"
+)
+
+// ErrOutOfScope is wrapped by every scope violation.
+var ErrOutOfScope = errors.New("out of scope")
+
+// rule is one parsed include or exclude line.
+type rule struct {
+	prefix   netip.Prefix // valid for address and network rules
+	domain   string       // lower-case, no trailing dot
+	wildcard bool         // domain rule matches subdomains only
+}
+
+func (r rule) String() string {
+	switch {
+	case r.prefix.IsValid():
+		if r.prefix.IsSingleIP() {
+			return r.prefix.Addr().String()
+		}
+		return r.prefix.String()
+	case r.wildcard:
+		return "*." + r.domain
+	default:
+		return r.domain
+	}
+}
+
+func (r rule) matchesHost(host string) bool {
+	if r.domain == "" {
+		return false
+	}
+	if r.wildcard {
+		return strings.HasSuffix(host, "."+r.domain)
+	}
+	return host == r.domain
+}
+
+// Scope is a parsed set of include and exclude rules.
+type Scope struct {
+	include []rule
+	exclude []rule
+}
+
+// Load reads a scope file from path.
+func Load(path string) (*Scope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading scope file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	s, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing scope file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse reads scope rules in the scope file format.
+func Parse(r io.Reader) (*Scope, error) {
+	var include, exclude []string
+	sc := bufio.NewScanner(r)
+	lineNum := 0
+	for sc.Scan() {
+		lineNum++
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "!") {
+			pattern := strings.TrimSpace(line[1:])
+			if _, err := parseRule(pattern); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			exclude = append(exclude, pattern)
+			continue
+		}
+		if _, err := parseRule(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		include = append(include, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return New(include, exclude)
+}
+
+// New builds a scope from include and exclude patterns.
+func New(include, exclude []string) (*Scope, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, fmt.Errorf("scope has no rules")
+	}
+	s := &Scope{}
+	for _, p := range include {
+		r, err := parseRule(p)
+		if err != nil {
+			return nil, err
+		}
+		s.include = append(s.include, r)
+	}
+	for _, p := range exclude {
+		r, err := parseRule(p)
+		if err != nil {
+			return nil, err
+		}
+		s.exclude = append(s.exclude, r)
+	}
+	return s, nil
+}
+
+// parseRule parses an address, CIDR, domain or *.domain pattern.
+func parseRule(pattern string) (rule, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return rule{}, fmt.Errorf("empty scope rule")
+	}
+	if strings.Contains(pattern, "/") {
+		p, err := netip.ParsePrefix(pattern)
+		if err != nil {
+			return rule{}, fmt.Errorf("invalid network %q: %w", pattern, err)
+		}
+		return rule{prefix: unmapPrefix(p).Masked()}, nil
+	}
+	if addr, err := netip.ParseAddr(pattern); err == nil {
+		addr = addr.Unmap()
+		return rule{prefix: netip.PrefixFrom(addr, addr.BitLen())}, nil
+	}
+	wildcard := false
+	if strings.HasPrefix(pattern, "*.") {
+		wildcard = true
+		pattern = pattern[2:]
+	}
+	domain := targets.NormalizeHostname(pattern)
+	if !targets.ValidHostname(domain) {
+		return rule{}, fmt.Errorf("invalid scope rule %q", pattern)
+	}
+	return rule{domain: domain, wildcard: wildcard}, nil
+}
+
+// Include returns the canonical include patterns.
+func (s *Scope) Include() []string { return ruleStrings(s.include) }
+
+// Exclude returns the canonical exclude patterns.
+func (s *Scope) Exclude() []string { return ruleStrings(s.exclude) }
+
+func ruleStrings(rules []rule) []string {
+	out := make([]string, len(rules))
+	for i, r := range rules {
+		out[i] = r.String()
+	}
+	return out
+}
+
+// Violation describes a target that falls outside the scope.
+type Violation struct {
+	Target string
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s is out of scope: %s", v.Target, v.Reason)
+}
+
+func (v *Violation) Unwrap() error { return ErrOutOfScope }
+
+// Check reports whether target is in scope without resolving DNS. Targets
+// may be addresses, CIDRs, ranges, host names, host:port pairs or URLs. It
+// returns a *Violation when the target is out of scope or cannot be parsed.
+func (s *Scope) Check(target string) error {
+	t, err := targets.Parse(target)
+	if err != nil {
+		return &Violation{Target: target, Reason: err.Error()}
+	}
+	switch {
+	case t.Kind == targets.KindCIDR:
+		return s.checkPrefix(target, t.Prefix)
+	case t.Kind == targets.KindRange:
+		addrs, err := targets.Addrs(t, targets.DefaultMaxExpand)
+		if err != nil {
+			return &Violation{Target: target, Reason: err.Error()}
+		}
+		for _, a := range addrs {
+			if err := s.checkPrefix(target, netip.PrefixFrom(a, a.BitLen())); err != nil {
+				return err
+			}
+		}
+		return nil
+	case t.IsAddr():
+		return s.checkPrefix(target, netip.PrefixFrom(t.Addr, t.Addr.BitLen()))
+	default:
+		return s.checkHost(target, t.Host)
+	}
+}
+
+func (s *Scope) checkPrefix(target string, p netip.Prefix) error {
+	for _, ex := range s.exclude {
+		if ex.prefix.IsValid() && ex.prefix.Overlaps(p) {
+			return &Violation{Target: target, Reason: "matches exclude rule " + ex.String()}
+		}
+	}
+	if len(s.include) == 0 {
+		return nil
+	}
+	for _, in := range s.include {
+		if in.prefix.IsValid() && in.prefix.Bits() <= p.Bits() && in.prefix.Contains(p.Addr()) {
+			return nil
+		}
+	}
+	return &Violation{Target: target, Reason: "not covered by any include rule"}
+}
+
+func (s *Scope) checkHost(target, host string) error {
+	for _, ex := range s.exclude {
+		if ex.matchesHost(host) {
+			return &Violation{Target: target, Reason: "matches exclude rule " + ex.String()}
+		}
+	}
+	if len(s.include) == 0 {
+		return nil
+	}
+	for _, in := range s.include {
+		if in.matchesHost(host) {
+			return nil
+		}
+	}
+	return &Violation{Target: target, Reason: "host name not covered by any domain include rule"}
+}
+
+// CheckAll checks every target and returns an error listing the violations,
+// or nil when all targets are in scope. The error wraps ErrOutOfScope.
+func (s *Scope) CheckAll(targets []string) error {
+	var violations []*Violation
+	for _, t := range targets {
+		if err := s.Check(t); err != nil {
+			var v *Violation
+			if errors.As(err, &v) {
+				violations = append(violations, v)
+			}
+		}
+	}
+	return joinViolations(violations)
+}
+
+// maxListedViolations caps how many violations an aggregate error spells out.
+const maxListedViolations = 10
+
+func joinViolations(violations []*Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d target(s) out of scope", len(violations))
+	for i, v := range violations {
+		if i == maxListedViolations {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(violations)-maxListedViolations)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %s: %s", v.Target, v.Reason)
+	}
+	return fmt.Errorf("%w: %s", ErrOutOfScope, b.String())
+}
+
+// Digest is the compact, self-verifying form of a scope embedded in every
+// task so workers can re-check targets without access to the scope file.
+type Digest struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	SHA256  string   `json:"sha256"`
+}
+
+// Digest returns the canonical digest of s. Rules are sorted so equal scopes
+// produce equal hashes regardless of file order.
+func (s *Scope) Digest() *Digest {
+	include := sortedUnique(s.Include())
+	exclude := sortedUnique(s.Exclude())
+	return &Digest{Include: include, Exclude: exclude, SHA256: digestHash(include, exclude)}
+}
+
+// Scope verifies the digest hash and rebuilds the scope it describes.
+func (d *Digest) Scope() (*Scope, error) {
+	if d == nil {
+		return nil, fmt.Errorf("scope digest is missing")
+	}
+	if got := digestHash(d.Include, d.Exclude); got != d.SHA256 {
+		return nil, fmt.Errorf("scope digest mismatch: have %s, computed %s", d.SHA256, got)
+	}
+	return New(d.Include, d.Exclude)
+}
+
+func digestHash(include, exclude []string) string {
+	h := sha256.New()
+	for _, p := range include {
+		_, _ = io.WriteString(h, "+"+p+"\n")
+	}
+	for _, p := range exclude {
+		_, _ = io.WriteString(h, "-"+p+"\n")
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sortedUnique(in []string) []string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := append([]string(nil), in...)
+	sort.Strings(out)
+	n := 0
+	for i, s := range out {
+		if i > 0 && s == out[n-1] {
+			continue
+		}
+		out[n] = s
+		n++
+	}
+	return out[:n]
+}
+
+func unmapPrefix(p netip.Prefix) netip.Prefix {
+	if p.Addr().Is4In6() && p.Bits() >= 96 {
+		return netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+	}
+	return p
+}
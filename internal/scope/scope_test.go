@@ -0,0 +1,198 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testScopeFile = `
+# engagement scope
+10.0.0.0/24
+192.0.2.10
+example.com
+*.example.com     # all subdomains
+!10.0.0.128/25
+!dev.example.com
+`
+
+func mustParse(t *testing.T, content string) *Scope {
+	t.Helper()
+	s, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return s
+}
+
+func TestCheck(t *testing.T) {
+	s := mustParse(t, testScopeFile)
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"10.0.0.5", true},
+		{"10.0.0.200", false},
+		{"10.0.1.1", false},
+		{"10.0.0.0/25", true},
+		{"10.0.0.0/24", false}, // overlaps the excluded half
+		{"10.0.0.0/23", false}, // wider than the include
+		{"192.0.2.10", true},
+		{"192.0.2.11", false},
+		{"::ffff:10.0.0.5", true},
+		{"example.com", true},
+		{"EXAMPLE.com.", true},
+		{"www.example.com", true},
+		{"a.b.example.com", true},
+		{"dev.example.com", false},
+		{"example.org", false},
+		{"notexample.com", false},
+		{"https://www.example.com:8443/login", true},
+		{"http://10.0.0.200/", false},
+		{"www.example.com:443", true},
+		{"10.0.0.5:22", true},
+		{"www.example.com/admin", false}, // a path needs a URL scheme
+		{"10.0.0.10-20", true},
+		{"10.0.0.120-130", false}, // reaches the excluded half
+		{"10.0.0.1,5,9-12", true},
+		{"10.0.0-1.1", false}, // 10.0.1.1 is outside
+		{"", false},
+		{"not a target", false},
+	}
+	for _, tt := range tests {
+		err := s.Check(tt.target)
+		if got := err == nil; got != tt.want {
+			t.Errorf("Check(%q) = %v, want in-scope %v", tt.target, err, tt.want)
+		}
+		if err != nil && !errors.Is(err, ErrOutOfScope) {
+			t.Errorf("Check(%q) error does not wrap ErrOutOfScope: %v", tt.target, err)
+		}
+	}
+}
+
+func TestCheck_DenylistOnly(t *testing.T) {
+	s := mustParse(t, "!203.0.113.0/24\n!*.gov\n")
+	if err := s.Check("198.51.100.1"); err != nil {
+		t.Errorf("expected denylist-only scope to allow unlisted IP: %v", err)
+	}
+	if err := s.Check("anything.example"); err != nil {
+		t.Errorf("expected denylist-only scope to allow unlisted host: %v", err)
+	}
+	if err := s.Check("203.0.113.7"); err == nil {
+		t.Error("expected excluded IP to be rejected")
+	}
+	if err := s.Check("www.whitehouse.gov"); err == nil {
+		t.Error("expected excluded wildcard domain to be rejected")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, content := range []string{
+		"",
+		"# only comments\n",
+		"10.0.0.0/33\n",
+		"bad_host!\n",
+		"!\n",
+	} {
+		if _, err := Parse(strings.NewReader(content)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", content)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scope.txt")
+	if err := os.WriteFile(path, []byte(testScopeFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(s.Include()) != 4 || len(s.Exclude()) != 2 {
+		t.Errorf("rules = %v / %v", s.Include(), s.Exclude())
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	s := mustParse(t, testScopeFile)
+	if err := s.CheckAll([]string{"10.0.0.1", "www.example.com"}); err != nil {
+		t.Errorf("CheckAll in-scope: %v", err)
+	}
+	err := s.CheckAll([]string{"10.0.0.1", "evil.test", "10.9.9.9"})
+	if !errors.Is(err, ErrOutOfScope) {
+		t.Fatalf("CheckAll = %v, want ErrOutOfScope", err)
+	}
+	if !strings.Contains(err.Error(), "2 target(s)") || !strings.Contains(err.Error(), "evil.test") {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+func TestDigest_RoundTripAndTamper(t *testing.T) {
+	a := mustParse(t, "10.0.0.0/24\nexample.com\n!10.0.0.1\n")
+	b := mustParse(t, "example.com\n!10.0.0.1\n10.0.0.7/24\n")
+	if a.Digest().SHA256 != b.Digest().SHA256 {
+		t.Error("equivalent scopes should share a digest")
+	}
+
+	d := a.Digest()
+	s, err := d.Scope()
+	if err != nil {
+		t.Fatalf("Digest.Scope: %v", err)
+	}
+	if err := s.Check("10.0.0.2"); err != nil {
+		t.Errorf("rebuilt scope rejected in-scope target: %v", err)
+	}
+
+	d.Include = append(d.Include, "0.0.0.0/0")
+	if _, err := d.Scope(); err == nil {
+		t.Error("expected tampered digest to be rejected")
+	}
+	var nilDigest *Digest
+	if _, err := nilDigest.Scope(); err == nil {
+		t.Error("expected nil digest error")
+	}
+}
+
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := f[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	out := make([]net.IPAddr, len(ips))
+	for i, ip := range ips {
+		out[i] = net.IPAddr{IP: net.ParseIP(ip)}
+	}
+	return out, nil
+}
+
+func TestCheckResolved(t *testing.T) {
+	s := mustParse(t, testScopeFile)
+	r := fakeResolver{
+		"www.example.com":   {"93.184.216.34"},
+		"stage.example.com": {"10.0.0.5", "10.0.0.130"},
+	}
+	ctx := context.Background()
+	if err := s.CheckResolved(ctx, "https://www.example.com/", r); err != nil {
+		t.Errorf("www: %v", err)
+	}
+	err := s.CheckResolved(ctx, "stage.example.com", r)
+	if !errors.Is(err, ErrOutOfScope) || !strings.Contains(err.Error(), "10.0.0.130") {
+		t.Errorf("stage = %v, want exclusion via resolved address", err)
+	}
+	if err := s.CheckResolved(ctx, "gone.example.com", r); err != nil {
+		t.Errorf("lookup failure should not be a violation: %v", err)
+	}
+	if err := s.CheckResolved(ctx, "evil.test", r); err == nil {
+		t.Error("expected static check to still apply")
+	}
+}
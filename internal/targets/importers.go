@@ -0,0 +1,311 @@
+package targets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Input formats understood by Import.
+const (
+	FormatText    = "text"     // one "<target> [options]" per line
+	FormatCSV     = "csv"      // header row with a target column
+	FormatJSON    = "json"     // array, {"targets": [...]} or JSON lines
+	FormatNmapXML = "nmap-xml" // hosts that were up in a previous nmap run
+	FormatBurp    = "burp"     // Burp Suite project options scope
+	FormatMasscan = "masscan"  // hosts with open ports from masscan JSON
+)
+
+// Entry is one imported target with its optional per-target options and
+// metadata, both carried into the task planned for it.
+type Entry struct {
+	Target   string            `json:"target"`
+	Options  string            `json:"options,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Line renders the entry in the text format.
+func (e Entry) Line() string {
+	return strings.TrimSpace(e.Target + " " + e.Options)
+}
+
+// TextLines renders entries in the text format, one per line.
+func TextLines(entries []Entry) string {
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e.Line())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Importer reads entries in one input format.
+type Importer func(r io.Reader) ([]Entry, error)
+
+var importers = map[string]Importer{
+	FormatText:    importText,
+	FormatCSV:     importCSV,
+	FormatJSON:    importJSON,
+	FormatNmapXML: importNmapXML,
+	FormatBurp:    importBurp,
+	FormatMasscan: importMasscan,
+}
+
+// Formats returns the supported input format names, sorted.
+func Formats() []string {
+	names := make([]string, 0, len(importers))
+	for name := range importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Import reads entries from r in the named format.
+func Import(r io.Reader, format string) ([]Entry, error) {
+	imp, ok := importers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown input format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+	entries, err := imp(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s targets: %w", format, err)
+	}
+	return entries, nil
+}
+
+// ImportFile reads entries from path. An empty format is detected from the
+// file extension and, for JSON, the document shape.
+func ImportFile(path, format string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading target file: %w", err)
+	}
+	if format == "" {
+		format = DetectFormat(path, data)
+	}
+	return Import(bytes.NewReader(data), format)
+}
+
+// DetectFormat guesses the input format from the file extension, looking at
+// the content only to tell Burp scope and masscan JSON from plain JSON and
+// nmap XML from other files.
+func DetectFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".xml":
+		return FormatNmapXML
+	case ".json", ".jsonl", ".ndjson":
+		if isBurpScope(data) {
+			return FormatBurp
+		}
+		if isMasscan(data) {
+			return FormatMasscan
+		}
+		return FormatJSON
+	}
+	head := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(head, []byte("<?xml")) && bytes.Contains(head, []byte("<nmaprun")):
+		return FormatNmapXML
+	case bytes.HasPrefix(head, []byte("{")) && isBurpScope(data):
+		return FormatBurp
+	}
+	return FormatText
+}
+
+func importText(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		entries = append(entries, Entry{Target: fields[0], Options: strings.Join(fields[1:], " ")})
+	}
+	return entries, sc.Err()
+}
+
+// targetColumns are the CSV headers and JSON keys recognised as the target,
+// in order of preference.
+var targetColumns = []string{"target", "url", "host", "hostname", "domain", "fqdn", "ip", "ip_address", "address", "cidr", "network"}
+
+func targetColumn(names []string) int {
+	for _, want := range targetColumns {
+		for i, name := range names {
+			if normalizeKey(name) == want {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func normalizeKey(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(s)
+}
+
+// importCSV reads a CSV with a header row. The first recognised target
+// column is the target, an "options" column becomes per-target options and
+// every other non-empty column is kept as metadata. Files whose first row
+// has no recognised header use the first column as the target.
+func importCSV(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	col := targetColumn(header)
+	if col < 0 {
+		header, col = nil, 0
+	} else {
+		records = records[1:]
+	}
+	var entries []Entry
+	for _, rec := range records {
+		if col >= len(rec) || strings.TrimSpace(rec[col]) == "" {
+			continue
+		}
+		e := Entry{Target: strings.TrimSpace(rec[col])}
+		for i, v := range rec {
+			v = strings.TrimSpace(v)
+			if i == col || v == "" || i >= len(header) {
+				continue
+			}
+			key := normalizeKey(header[i])
+			if key == "options" {
+				e.Options = v
+				continue
+			}
+			if e.Metadata == nil {
+				e.Metadata = make(map[string]string)
+			}
+			e.Metadata[key] = v
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// importJSON reads a JSON array, an object with a "targets" (or "assets",
+// "hosts") array, or a stream of JSON values. Each value is a target string
+// or an object with a recognised target key; "options" becomes per-target
+// options and other scalar fields become metadata.
+func importJSON(r io.Reader) ([]Entry, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var entries []Entry
+	for {
+		var v any
+		if err := dec.Decode(&v); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if err := appendJSONValue(&entries, v, true); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func appendJSONValue(entries *[]Entry, v any, top bool) error {
+	switch val := v.(type) {
+	case string:
+		if s := strings.TrimSpace(val); s != "" {
+			*entries = append(*entries, Entry{Target: s})
+		}
+		return nil
+	case []any:
+		for _, item := range val {
+			if err := appendJSONValue(entries, item, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		if top {
+			for _, key := range []string{"targets", "assets", "hosts"} {
+				if list, ok := val[key].([]any); ok {
+					return appendJSONValue(entries, list, false)
+				}
+			}
+		}
+		e, err := jsonEntry(val)
+		if err != nil {
+			return err
+		}
+		*entries = append(*entries, e)
+		return nil
+	}
+	return fmt.Errorf("unsupported JSON value %v", v)
+}
+
+func jsonEntry(obj map[string]any) (Entry, error) {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	col := targetColumn(keys)
+	if col < 0 {
+		return Entry{}, fmt.Errorf("object has no target field (one of %s)", strings.Join(targetColumns, ", "))
+	}
+	target, ok := jsonScalar(obj[keys[col]])
+	if !ok || target == "" {
+		return Entry{}, fmt.Errorf("field %q is not a target string", keys[col])
+	}
+	e := Entry{Target: target}
+	for i, k := range keys {
+		if i == col {
+			continue
+		}
+		v, ok := jsonScalar(obj[k])
+		if !ok || v == "" {
+			continue
+		}
+		key := normalizeKey(k)
+		if key == "options" {
+			e.Options = v
+			continue
+		}
+		if e.Metadata == nil {
+			e.Metadata = make(map[string]string)
+		}
+		e.Metadata[key] = v
+	}
+	return e, nil
+}
+
+func jsonScalar(v any) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return strings.TrimSpace(val), true
+	case json.Number:
+		return val.String(), true
+	case bool:
+		return strconv.FormatBool(val), true
+	}
+	return "", false
+}
@@ -0,0 +1,104 @@
+package targets
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type burpConfig struct {
+	Target *struct {
+		Scope *struct {
+			AdvancedMode bool       `json:"advanced_mode"`
+			Include      []burpRule `json:"include"`
+		} `json:"scope"`
+	} `json:"target"`
+}
+
+type burpRule struct {
+	Enabled  bool   `json:"enabled"`
+	Prefix   string `json:"prefix"`
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+}
+
+func isBurpScope(data []byte) bool {
+	if !bytes.Contains(data, []byte(`"scope"`)) {
+		return false
+	}
+	var cfg burpConfig
+	return json.Unmarshal(data, &cfg) == nil && cfg.Target != nil && cfg.Target.Scope != nil
+}
+
+// importBurp reads the enabled include rules of a Burp Suite project options
+// scope. Simple-mode rules are URL prefixes. Advanced-mode host and port
+// patterns are used when they are literal (an anchored, escaped name);
+// wildcard patterns cannot be enumerated and are skipped. Exclude rules are
+// ignored here; express them in a scope file instead.
+func importBurp(r io.Reader) ([]Entry, error) {
+	var cfg burpConfig
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Target == nil || cfg.Target.Scope == nil {
+		return nil, nil
+	}
+	var entries []Entry
+	seen := make(map[string]bool)
+	for _, rule := range cfg.Target.Scope.Include {
+		if !rule.Enabled {
+			continue
+		}
+		target := burpTarget(rule)
+		if target == "" || seen[target] {
+			continue
+		}
+		seen[target] = true
+		entries = append(entries, Entry{Target: target})
+	}
+	return entries, nil
+}
+
+func burpTarget(rule burpRule) string {
+	if rule.Prefix != "" {
+		return strings.TrimSpace(rule.Prefix)
+	}
+	host, ok := literalPattern(rule.Host)
+	if !ok || host == "" {
+		return ""
+	}
+	port := 0
+	if p, ok := literalPattern(rule.Port); ok && p != "" {
+		if n, err := strconv.Atoi(p); err == nil {
+			port = n
+		}
+	}
+	switch proto := strings.ToLower(rule.Protocol); proto {
+	case "http", "https":
+		if port == DefaultPort(proto) {
+			port = 0
+		}
+		return proto + "://" + joinHostPort(host, port)
+	default:
+		return joinHostPort(host, port)
+	}
+}
+
+// regexMeta matches regular expression syntax that is left after removing
+// anchors and escaped dots, meaning the pattern is not a literal.
+var regexMeta = regexp.MustCompile(`[\\*+?()\[\]{}|^$]`)
+
+func literalPattern(p string) (string, bool) {
+	p = strings.TrimSpace(p)
+	p = strings.TrimPrefix(p, "^")
+	p = strings.TrimSuffix(p, "$")
+	p = strings.ReplaceAll(p, `\.`, ".")
+	if regexMeta.MatchString(p) {
+		return "", false
+	}
+	return p, true
+}
@@ -0,0 +1,164 @@
+package targets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MasscanRecord is one record of masscan's JSON output (-oJ): a host and
+// the ports it reported.
+type MasscanRecord struct {
+	IP    string        `json:"ip"`
+	Ports []MasscanPort `json:"ports"`
+}
+
+// MasscanPort is a port masscan reported on a host.
+type MasscanPort struct {
+	Port   int    `json:"port"`
+	Proto  string `json:"proto"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// masscanTrailingComma matches the comma older masscan releases leave after
+// the last record of a JSON array.
+var masscanTrailingComma = regexp.MustCompile(`,\s*\]\s*$`)
+
+// ParseMasscan reads masscan JSON output, either a JSON array (-oJ) or one
+// record per line (-oD).
+func ParseMasscan(data []byte) ([]MasscanRecord, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		trimmed = masscanTrailingComma.ReplaceAll(trimmed, []byte("]"))
+		var records []MasscanRecord
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, err
+		}
+		return records, nil
+	}
+
+	var records []MasscanRecord
+	sc := bufio.NewScanner(bytes.NewReader(trimmed))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := bytes.TrimSuffix(bytes.TrimSpace(sc.Bytes()), []byte(","))
+		if len(line) == 0 {
+			continue
+		}
+		var rec MasscanRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		records = append(records, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func isMasscan(data []byte) bool {
+	head := bytes.TrimSpace(data)
+	if !bytes.Contains(head, []byte(`"ip"`)) || !bytes.Contains(head, []byte(`"ports"`)) {
+		return false
+	}
+	records, err := ParseMasscan(data)
+	return err == nil && len(records) > 0 && records[0].IP != ""
+}
+
+// importMasscan reads the hosts of masscan JSON output.
+func importMasscan(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	records, err := ParseMasscan(data)
+	if err != nil {
+		return nil, err
+	}
+	return MasscanEntries(records), nil
+}
+
+// MasscanEntries returns one entry per host of masscan records. masscan
+// reports one record per open port, so records are grouped by host; each
+// host's open ports are kept as "ports" metadata (UDP ports as "U:53", SCTP
+// as "S:80").
+func MasscanEntries(records []MasscanRecord) []Entry {
+	ports := make(map[string]map[string]bool)
+	var order []string
+	for _, rec := range records {
+		if rec.IP == "" {
+			continue
+		}
+		if ports[rec.IP] == nil {
+			ports[rec.IP] = make(map[string]bool)
+			order = append(order, rec.IP)
+		}
+		for _, p := range rec.Ports {
+			if p.Status != "" && p.Status != "open" {
+				continue
+			}
+			ports[rec.IP][masscanPortSpec(p)] = true
+		}
+	}
+
+	entries := make([]Entry, 0, len(order))
+	for _, ip := range order {
+		e := Entry{Target: ip}
+		if specs := sortedPortSpecs(ports[ip]); len(specs) > 0 {
+			e.Metadata = map[string]string{"ports": strings.Join(specs, ",")}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func masscanPortSpec(p MasscanPort) string {
+	port := strconv.Itoa(p.Port)
+	switch strings.ToLower(p.Proto) {
+	case "udp":
+		return "U:" + port
+	case "sctp":
+		return "S:" + port
+	default:
+		return port
+	}
+}
+
+// sortedPortSpecs orders port specs by protocol (TCP, then UDP, then SCTP)
+// and port number.
+func sortedPortSpecs(set map[string]bool) []string {
+	specs := make([]string, 0, len(set))
+	for s := range set {
+		specs = append(specs, s)
+	}
+	rank := func(s string) (int, int) {
+		proto, port := 0, s
+		switch {
+		case strings.HasPrefix(s, "U:"):
+			proto, port = 1, s[2:]
+		case strings.HasPrefix(s, "S:"):
+			proto, port = 2, s[2:]
+		}
+		n, _ := strconv.Atoi(port)
+		return proto, n
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		pi, ni := rank(specs[i])
+		pj, nj := rank(specs[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return ni < nj
+	})
+	return specs
+}
@@ -0,0 +1,87 @@
+package targets
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+type nmapRun struct {
+	Hosts []nmapHost `xml:"host"`
+}
+
+type nmapHost struct {
+	Status    nmapStatus    `xml:"status"`
+	Addresses []nmapAddress `xml:"address"`
+	Hostnames []nmapName    `xml:"hostnames>hostname"`
+	Ports     []nmapPort    `xml:"ports>port"`
+}
+
+type nmapStatus struct {
+	State string `xml:"state,attr"`
+}
+
+type nmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+}
+
+type nmapName struct {
+	Name string `xml:"name,attr"`
+}
+
+type nmapPort struct {
+	Protocol string     `xml:"protocol,attr"`
+	PortID   string     `xml:"portid,attr"`
+	State    nmapStatus `xml:"state"`
+}
+
+// importNmapXML reads the hosts of an nmap XML report that were up. The
+// target is the host's IP address; its first host name and open ports are
+// kept as "hostname" and "ports" metadata (UDP ports as "U:53").
+func importNmapXML(r io.Reader) ([]Entry, error) {
+	var run nmapRun
+	if err := xml.NewDecoder(r).Decode(&run); err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, h := range run.Hosts {
+		if h.Status.State != "" && h.Status.State != "up" {
+			continue
+		}
+		addr := ""
+		for _, a := range h.Addresses {
+			if a.AddrType == "ipv4" || a.AddrType == "ipv6" {
+				addr = a.Addr
+				break
+			}
+		}
+		if addr == "" {
+			continue
+		}
+		e := Entry{Target: addr}
+		meta := make(map[string]string)
+		if len(h.Hostnames) > 0 && h.Hostnames[0].Name != "" {
+			meta["hostname"] = h.Hostnames[0].Name
+		}
+		var ports []string
+		for _, p := range h.Ports {
+			if p.State.State != "open" {
+				continue
+			}
+			if p.Protocol == "udp" {
+				ports = append(ports, "U:"+p.PortID)
+			} else {
+				ports = append(ports, p.PortID)
+			}
+		}
+		if len(ports) > 0 {
+			meta["ports"] = strings.Join(ports, ",")
+		}
+		if len(meta) > 0 {
+			e.Metadata = meta
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
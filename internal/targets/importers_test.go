@@ -0,0 +1,220 @@
+package targets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImport_Text(t *testing.T) {
+	entries, err := Import(strings.NewReader("# header\nexample.com -sV -p 80\n\n10.0.0.1\n"), FormatText)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Target != "example.com" || entries[0].Options != "-sV -p 80" || entries[1].Options != "" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestImport_CSV(t *testing.T) {
+	in := "Asset Name,IP Address,Owner,Options\nweb,192.0.2.10,alice,-p 443\ndb,,bob,\nmail,192.0.2.25,,\n"
+	entries, err := Import(strings.NewReader(in), FormatCSV)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v, want 2", entries)
+	}
+	e := entries[0]
+	if e.Target != "192.0.2.10" || e.Options != "-p 443" || e.Metadata["owner"] != "alice" || e.Metadata["asset_name"] != "web" {
+		t.Errorf("entry = %+v", e)
+	}
+
+	entries, err = Import(strings.NewReader("example.com,prod\nexample.org,dev\n"), FormatCSV)
+	if err != nil {
+		t.Fatalf("Import headerless: %v", err)
+	}
+	if len(entries) != 2 || entries[1].Target != "example.org" || entries[1].Metadata != nil {
+		t.Errorf("headerless entries = %+v", entries)
+	}
+}
+
+func TestImport_JSON(t *testing.T) {
+	tests := map[string]string{
+		"array":   `["example.com", {"hostname": "api.example.com", "env": "prod", "port": 8443}]`,
+		"wrapped": `{"targets": ["example.com", {"hostname": "api.example.com", "env": "prod", "port": 8443}]}`,
+		"lines":   "\"example.com\"\n{\"hostname\": \"api.example.com\", \"env\": \"prod\", \"port\": 8443}\n",
+	}
+	for name, in := range tests {
+		entries, err := Import(strings.NewReader(in), FormatJSON)
+		if err != nil {
+			t.Fatalf("%s: Import: %v", name, err)
+		}
+		if len(entries) != 2 || entries[0].Target != "example.com" {
+			t.Fatalf("%s: entries = %+v", name, entries)
+		}
+		e := entries[1]
+		if e.Target != "api.example.com" || e.Metadata["env"] != "prod" || e.Metadata["port"] != "8443" {
+			t.Errorf("%s: entry = %+v", name, e)
+		}
+	}
+
+	if _, err := Import(strings.NewReader(`[{"owner": "alice"}]`), FormatJSON); err == nil {
+		t.Error("expected error for object without a target field")
+	}
+}
+
+const testNmapXML = `<?xml version="1.0"?>
+<nmaprun>
+  <host>
+    <status state="up"/>
+    <address addr="192.0.2.10" addrtype="ipv4"/>
+    <address addr="00:11:22:33:44:55" addrtype="mac"/>
+    <hostnames><hostname name="web.example.com" type="PTR"/></hostnames>
+    <ports>
+      <port protocol="tcp" portid="22"><state state="open"/></port>
+      <port protocol="tcp" portid="25"><state state="filtered"/></port>
+      <port protocol="tcp" portid="443"><state state="open"/></port>
+      <port protocol="udp" portid="53"><state state="open"/></port>
+    </ports>
+  </host>
+  <host>
+    <status state="down"/>
+    <address addr="192.0.2.11" addrtype="ipv4"/>
+  </host>
+</nmaprun>`
+
+func TestImport_NmapXML(t *testing.T) {
+	entries, err := Import(strings.NewReader(testNmapXML), FormatNmapXML)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("entries = %+v, want 1 up host", entries)
+	}
+	e := entries[0]
+	if e.Target != "192.0.2.10" || e.Metadata["hostname"] != "web.example.com" || e.Metadata["ports"] != "22,443,U:53" {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+const testBurpScope = `{
+  "target": {
+    "scope": {
+      "advanced_mode": true,
+      "include": [
+        {"enabled": true, "protocol": "https", "host": "^www\\.example\\.com$", "port": "^443$", "file": "^/.*"},
+        {"enabled": true, "protocol": "any", "host": "^api\\.example\\.com$", "port": "^8443$"},
+        {"enabled": true, "protocol": "any", "host": "^.*\\.example\\.com$"},
+        {"enabled": false, "protocol": "any", "host": "^disabled\\.example\\.com$"},
+        {"enabled": true, "prefix": "http://legacy.example.com/app"}
+      ],
+      "exclude": [
+        {"enabled": true, "protocol": "any", "host": "^logout\\.example\\.com$"}
+      ]
+    }
+  }
+}`
+
+func TestImport_Burp(t *testing.T) {
+	entries, err := Import(strings.NewReader(testBurpScope), FormatBurp)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Target)
+	}
+	want := "https://www.example.com,api.example.com:8443,http://legacy.example.com/app"
+	if strings.Join(got, ",") != want {
+		t.Errorf("targets = %v, want %s", got, want)
+	}
+}
+
+// testMasscan is masscan -oJ output, including the trailing comma older
+// releases leave after the last record.
+const testMasscan = `[
+{ "ip": "192.0.2.10", "timestamp": "1700000000", "ports": [ {"port": 443, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 54} ] },
+{ "ip": "192.0.2.10", "timestamp": "1700000001", "ports": [ {"port": 22, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 54} ] },
+{ "ip": "192.0.2.10", "timestamp": "1700000002", "ports": [ {"port": 53, "proto": "udp", "status": "open", "reason": "none", "ttl": 54} ] },
+{ "ip": "192.0.2.20", "timestamp": "1700000003", "ports": [ {"port": 80, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 54} ] },
+]`
+
+func TestImport_Masscan(t *testing.T) {
+	entries, err := Import(strings.NewReader(testMasscan), FormatMasscan)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v, want 2 hosts", entries)
+	}
+	if e := entries[0]; e.Target != "192.0.2.10" || e.Metadata["ports"] != "22,443,U:53" {
+		t.Errorf("entry = %+v", e)
+	}
+	if e := entries[1]; e.Target != "192.0.2.20" || e.Metadata["ports"] != "80" {
+		t.Errorf("entry = %+v", e)
+	}
+
+	lines := `{"ip":"192.0.2.30","ports":[{"port":8080,"proto":"tcp","status":"open"}]}
+{"ip":"192.0.2.30","ports":[{"port":8443,"proto":"tcp","status":"open"}]}
+`
+	entries, err = Import(strings.NewReader(lines), FormatMasscan)
+	if err != nil || len(entries) != 1 || entries[0].Metadata["ports"] != "8080,8443" {
+		t.Errorf("JSON lines = %+v, %v", entries, err)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want string
+	}{
+		{"targets.txt", "example.com\n", FormatText},
+		{"assets.CSV", "host\nexample.com\n", FormatCSV},
+		{"inventory.json", `["example.com"]`, FormatJSON},
+		{"project.json", testBurpScope, FormatBurp},
+		{"scan.xml", testNmapXML, FormatNmapXML},
+		{"scan", testNmapXML, FormatNmapXML},
+		{"scope", testBurpScope, FormatBurp},
+		{"masscan.json", testMasscan, FormatMasscan},
+		{"hosts.json", `[{"ip": "192.0.2.1", "ports": "80"}]`, FormatJSON},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.path, []byte(tt.data)); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestImportFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.xml")
+	if err := os.WriteFile(path, []byte(testNmapXML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ImportFile(path, "")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ImportFile = %+v, %v", entries, err)
+	}
+	if _, err := ImportFile(path, "yaml"); err == nil || !strings.Contains(err.Error(), "unknown input format") {
+		t.Errorf("expected unknown format error, got %v", err)
+	}
+}
+
+func TestNormalizeEntries_KeepsOptionsAndMetadata(t *testing.T) {
+	res, err := NormalizeEntries([]Entry{
+		{Target: "10.0.0.0/23", Options: "-p 22", Metadata: map[string]string{"site": "lab"}},
+		{Target: "10.0.1.0/24", Options: "-p 22"},
+		{Target: "10.0.1.0/24", Options: "-p 80"},
+	}, Options{SplitCIDR: 24})
+	if err != nil {
+		t.Fatalf("NormalizeEntries: %v", err)
+	}
+	if len(res.Entries) != 3 || res.Stats.Duplicates != 1 {
+		t.Fatalf("entries = %+v stats = %+v", res.Entries, res.Stats)
+	}
+	if res.Entries[1].Target != "10.0.1.0/24" || res.Entries[1].Metadata["site"] != "lab" || res.Entries[2].Options != "-p 80" {
+		t.Errorf("entries = %+v", res.Entries)
+	}
+}
@@ -0,0 +1,380 @@
+package targets
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// DefaultMaxExpand caps how many targets a single CIDR or range may expand
+// or split into.
+const DefaultMaxExpand = 65536
+
+// Options controls Normalize.
+type Options struct {
+	// Accept lists the kinds the consuming module takes. Other kinds are
+	// converted when possible and rejected otherwise. Empty accepts all.
+	Accept []Kind
+	// SplitCIDR splits IPv4 CIDRs wider than /SplitCIDR into /SplitCIDR
+	// blocks. Zero disables splitting.
+	SplitCIDR int
+	// ExpandCIDR expands CIDRs and ranges into individual addresses.
+	ExpandCIDR bool
+	// MaxExpand caps the targets produced from one CIDR or range.
+	// Zero means DefaultMaxExpand.
+	MaxExpand int
+}
+
+// Stats counts what Normalize did.
+type Stats struct {
+	Input      int // non-empty input targets
+	Output     int // targets after normalization
+	Duplicates int // targets dropped as duplicates
+	Converted  int // targets converted to an accepted kind
+	Split      int // CIDRs split into smaller blocks
+	Expanded   int // CIDRs and ranges expanded into addresses
+}
+
+// Result is the normalized target list.
+type Result struct {
+	Targets []string
+	Stats   Stats
+}
+
+// maxListedErrors caps how many problems a Normalize error spells out.
+const maxListedErrors = 10
+
+// Normalize parses, splits or expands, converts and deduplicates targets.
+// It returns an error listing every target that is invalid or cannot be
+// converted to an accepted kind.
+func Normalize(lines []string, opts Options) (*Result, error) {
+	res := &Result{}
+	seen := make(map[string]bool)
+	var problems []string
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		res.Stats.Input++
+		out, err := NormalizeTarget(line, opts, &res.Stats)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		for _, s := range out {
+			if seen[s] {
+				res.Stats.Duplicates++
+				continue
+			}
+			seen[s] = true
+			res.Targets = append(res.Targets, s)
+		}
+	}
+	res.Stats.Output = len(res.Targets)
+	if len(problems) > 0 {
+		return nil, JoinProblems(problems)
+	}
+	return res, nil
+}
+
+// EntryResult is a normalized list of imported entries.
+type EntryResult struct {
+	Entries []Entry
+	Stats   Stats
+}
+
+// NormalizeEntries normalizes each entry's target like Normalize. Entries a
+// target expands into share its options and metadata. An entry is a
+// duplicate when both its target and options were already seen.
+func NormalizeEntries(entries []Entry, opts Options) (*EntryResult, error) {
+	res := &EntryResult{}
+	seen := make(map[string]bool)
+	var problems []string
+
+	for _, e := range entries {
+		target := strings.TrimSpace(e.Target)
+		if target == "" {
+			continue
+		}
+		res.Stats.Input++
+		out, err := NormalizeTarget(target, opts, &res.Stats)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		for _, t := range out {
+			key := t + "\x00" + e.Options
+			if seen[key] {
+				res.Stats.Duplicates++
+				continue
+			}
+			seen[key] = true
+			res.Entries = append(res.Entries, Entry{Target: t, Options: e.Options, Metadata: e.Metadata})
+		}
+	}
+	res.Stats.Output = len(res.Entries)
+	if len(problems) > 0 {
+		return nil, JoinProblems(problems)
+	}
+	return res, nil
+}
+
+// NormalizeTarget normalizes one target into its canonical forms without
+// deduplicating them. Conversion, split and expansion counts are added to
+// stats when it is non-nil.
+func NormalizeTarget(s string, opts Options, stats *Stats) ([]string, error) {
+	if opts.MaxExpand <= 0 {
+		opts.MaxExpand = DefaultMaxExpand
+	}
+	if stats == nil {
+		stats = &Stats{}
+	}
+	t, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	expanded, err := reshape(t, opts, stats)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s, err)
+	}
+	var out []string
+	for _, e := range expanded {
+		converted, err := Convert(e, opts.Accept, opts.MaxExpand)
+		if err != nil {
+			return nil, err
+		}
+		if len(converted) != 1 || converted[0].Kind != e.Kind {
+			stats.Converted++
+		}
+		for _, c := range converted {
+			out = append(out, c.String())
+		}
+	}
+	return out, nil
+}
+
+// reshape applies CIDR expansion or splitting.
+func reshape(t Target, opts Options, stats *Stats) ([]Target, error) {
+	switch {
+	case opts.ExpandCIDR && (t.Kind == KindCIDR || t.Kind == KindRange):
+		addrs, err := Addrs(t, opts.MaxExpand)
+		if err != nil {
+			return nil, err
+		}
+		stats.Expanded++
+		out := make([]Target, len(addrs))
+		for i, a := range addrs {
+			out[i] = addrTarget(a)
+		}
+		return out, nil
+	case opts.SplitCIDR > 0 && t.Kind == KindCIDR && t.Prefix.Addr().Is4() && t.Prefix.Bits() < opts.SplitCIDR:
+		blocks, err := SplitPrefix(t.Prefix, opts.SplitCIDR, opts.MaxExpand)
+		if err != nil {
+			return nil, err
+		}
+		stats.Split++
+		out := make([]Target, len(blocks))
+		for i, b := range blocks {
+			out[i] = Target{Kind: KindCIDR, Prefix: b}
+		}
+		return out, nil
+	}
+	return []Target{t}, nil
+}
+
+// httpPorts are ports converted to http:// rather than https:// URLs.
+var httpPorts = map[int]bool{80: true, 8000: true, 8008: true, 8080: true, 8888: true}
+
+// Convert returns t in a kind from accept, or t itself when accept is empty
+// or already contains its kind. Conversions that drop information (a URL's
+// path, a host:port's port) are only used when nothing better is accepted.
+// CIDRs and ranges convert to addresses, at most maxExpand of them.
+func Convert(t Target, accept []Kind, maxExpand int) ([]Target, error) {
+	if len(accept) == 0 || slices.Contains(accept, t.Kind) {
+		return []Target{t}, nil
+	}
+	has := func(k Kind) bool { return slices.Contains(accept, k) }
+	hostKind := KindHostname
+	if t.IsAddr() {
+		hostKind = KindIP
+	}
+
+	switch t.Kind {
+	case KindIP:
+		if has(KindCIDR) {
+			return []Target{{Kind: KindCIDR, Prefix: netip.PrefixFrom(t.Addr, t.Addr.BitLen())}}, nil
+		}
+		if has(KindURL) {
+			return []Target{hostURL(t.Host, 0)}, nil
+		}
+	case KindHostname:
+		if has(KindURL) {
+			return []Target{hostURL(t.Host, 0)}, nil
+		}
+	case KindHostPort:
+		if has(KindURL) {
+			return []Target{hostURL(t.Host, t.Port)}, nil
+		}
+		if has(hostKind) {
+			return []Target{hostOnly(t)}, nil
+		}
+	case KindURL:
+		if has(KindHostPort) {
+			port := t.Port
+			if port == 0 {
+				port = DefaultPort(t.URL.Scheme)
+			}
+			if port != 0 {
+				hp := hostOnly(t)
+				hp.Kind = KindHostPort
+				hp.Port = port
+				return []Target{hp}, nil
+			}
+		}
+		if has(hostKind) {
+			return []Target{hostOnly(t)}, nil
+		}
+	case KindCIDR, KindRange:
+		if has(KindIP) {
+			addrs, err := Addrs(t, maxExpand)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]Target, len(addrs))
+			for i, a := range addrs {
+				out[i] = addrTarget(a)
+			}
+			return out, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %s targets are not accepted (accepts %s)", t, t.Kind, kindList(accept))
+}
+
+func hostURL(host string, port int) Target {
+	scheme := "https"
+	if httpPorts[port] {
+		scheme = "http"
+	}
+	t, _ := Parse(scheme + "://" + joinHostPort(host, port))
+	return t
+}
+
+func hostOnly(t Target) Target {
+	if t.IsAddr() {
+		return addrTarget(t.Addr)
+	}
+	return Target{Kind: KindHostname, Host: t.Host}
+}
+
+// Addrs lists the addresses of a cidr or range target, failing when there
+// are more than max.
+func Addrs(t Target, max int) ([]netip.Addr, error) {
+	var first, last netip.Addr
+	switch t.Kind {
+	case KindCIDR:
+		hostBits := t.Prefix.Addr().BitLen() - t.Prefix.Bits()
+		if hostBits >= 31 || 1<<hostBits > max {
+			return nil, fmt.Errorf("%s expands to more than %d addresses", t, max)
+		}
+		first, last = t.Prefix.Addr(), lastAddr(t.Prefix)
+	case KindRange:
+		return rangeAddrs(t, max)
+	default:
+		return []netip.Addr{t.Addr}, nil
+	}
+	var out []netip.Addr
+	for a := first; ; a = a.Next() {
+		if len(out) == max {
+			return nil, fmt.Errorf("%s expands to more than %d addresses", t, max)
+		}
+		out = append(out, a)
+		if a == last {
+			break
+		}
+	}
+	return out, nil
+}
+
+// rangeAddrs lists a range's addresses in order, every combination of its
+// octet values.
+func rangeAddrs(t Target, max int) ([]netip.Addr, error) {
+	if t.Size() > max {
+		return nil, fmt.Errorf("%s expands to more than %d addresses", t, max)
+	}
+	out := make([]netip.Addr, 0, t.Size())
+	var b [4]byte
+	var walk func(i int)
+	walk = func(i int) {
+		if i == len(b) {
+			out = append(out, netip.AddrFrom4(b))
+			return
+		}
+		for _, sp := range t.Octets[i] {
+			for v := int(sp.First); v <= int(sp.Last); v++ {
+				b[i] = byte(v)
+				walk(i + 1)
+			}
+		}
+	}
+	walk(0)
+	return out, nil
+}
+
+// SplitPrefix splits p into /bits blocks, failing when there would be more
+// than max of them. Prefixes already at least /bits are returned as-is.
+func SplitPrefix(p netip.Prefix, bits, max int) ([]netip.Prefix, error) {
+	if bits > p.Addr().BitLen() {
+		return nil, fmt.Errorf("cannot split %s into /%d blocks", p, bits)
+	}
+	if p.Bits() >= bits {
+		return []netip.Prefix{p}, nil
+	}
+	diff := bits - p.Bits()
+	if diff >= 31 || 1<<diff > max {
+		return nil, fmt.Errorf("%s splits into more than %d /%d blocks", p, max, bits)
+	}
+	out := make([]netip.Prefix, 0, 1<<diff)
+	addr := p.Addr()
+	for i := 0; i < 1<<diff; i++ {
+		block := netip.PrefixFrom(addr, bits)
+		out = append(out, block)
+		addr = lastAddr(block).Next()
+	}
+	return out, nil
+}
+
+// lastAddr returns the final address of p.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+func kindList(kinds []Kind) string {
+	s := make([]string, len(kinds))
+	for i, k := range kinds {
+		s[i] = string(k)
+	}
+	return strings.Join(s, ", ")
+}
+
+// JoinProblems builds the aggregate error for invalid targets.
+func JoinProblems(problems []string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d invalid target(s)", len(problems))
+	for i, p := range problems {
+		if i == maxListedErrors {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(problems)-maxListedErrors)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %s", p)
+	}
+	return errors.New(b.String())
+}
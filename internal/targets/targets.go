@@ -0,0 +1,340 @@
+// Package targets parses scan targets into typed values and normalizes
+// target lists before planning: canonical forms, deduplication, CIDR
+// splitting and expansion, and conversion to the kinds a module accepts.
+package targets
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Kind is the shape of a target.
+type Kind string
+
+const (
+	KindIP       Kind = "ip"       // 192.0.2.1, 2001:db8::1
+	KindCIDR     Kind = "cidr"     // 10.0.0.0/24
+	KindRange    Kind = "range"    // 10.0.0.1-20, 10.0.0-5.1-254 (nmap IPv4 octet ranges)
+	KindHostname Kind = "hostname" // www.example.com
+	KindHostPort Kind = "hostport" // example.com:8443, [2001:db8::1]:22
+	KindURL      Kind = "url"      // https://example.com/login
+)
+
+// Kinds returns every target kind in a stable order.
+func Kinds() []Kind {
+	return []Kind{KindIP, KindCIDR, KindRange, KindHostname, KindHostPort, KindURL}
+}
+
+// ParseKind validates a kind name.
+func ParseKind(s string) (Kind, error) {
+	k := Kind(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Kinds() {
+		if k == known {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown target kind %q", s)
+}
+
+// Target is one parsed target. Which fields are set depends on Kind.
+type Target struct {
+	Kind Kind
+	// Host is the lower-case host name or address text (no brackets) for
+	// ip, hostname, hostport and url targets.
+	Host string
+	// Addr is set when the host is an IP address, and is the first address
+	// of a range.
+	Addr netip.Addr
+	// Octets lists, for range targets, the values each IPv4 octet takes.
+	Octets [4][]OctetSpan
+	// Prefix is set for cidr targets.
+	Prefix netip.Prefix
+	// Port is the explicit port of hostport and url targets, or zero.
+	Port int
+	// URL is set for url targets.
+	URL *url.URL
+}
+
+// Parse classifies and canonicalizes a target string.
+func Parse(s string) (Target, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Target{}, fmt.Errorf("empty target")
+	}
+	if strings.Contains(s, "://") {
+		return parseURL(s)
+	}
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return Target{}, fmt.Errorf("invalid target %q: not a CIDR or URL", s)
+		}
+		p = unmapPrefix(p).Masked()
+		if p.IsSingleIP() {
+			return addrTarget(p.Addr()), nil
+		}
+		return Target{Kind: KindCIDR, Prefix: p}, nil
+	}
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addrTarget(addr.Unmap()), nil
+	}
+	if t, ok, err := parseRange(s); ok {
+		return t, err
+	}
+	if strings.HasPrefix(s, "[") || strings.Count(s, ":") == 1 {
+		host, portStr, err := net.SplitHostPort(s)
+		if err != nil {
+			return Target{}, fmt.Errorf("invalid host:port %q: %w", s, err)
+		}
+		port, err := parsePort(portStr)
+		if err != nil {
+			return Target{}, fmt.Errorf("invalid host:port %q: %w", s, err)
+		}
+		t, err := parseHost(host)
+		if err != nil {
+			return Target{}, err
+		}
+		t.Kind = KindHostPort
+		t.Port = port
+		return t, nil
+	}
+	return parseHost(s)
+}
+
+func parseURL(s string) (Target, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return Target{}, fmt.Errorf("invalid URL %q: %w", s, err)
+	}
+	if u.Scheme == "" || u.Hostname() == "" {
+		return Target{}, fmt.Errorf("invalid URL %q: missing scheme or host", s)
+	}
+	t, err := parseHost(u.Hostname())
+	if err != nil {
+		return Target{}, err
+	}
+	if p := u.Port(); p != "" {
+		port, err := parsePort(p)
+		if err != nil {
+			return Target{}, fmt.Errorf("invalid URL %q: %w", s, err)
+		}
+		t.Port = port
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if t.Port != 0 && t.Port == DefaultPort(u.Scheme) {
+		t.Port = 0
+	}
+	u.Host = joinHostPort(t.Host, t.Port)
+	u.Fragment = ""
+	if u.Path == "/" && u.RawQuery == "" {
+		u.Path = ""
+	}
+	t.Kind = KindURL
+	t.URL = u
+	return t, nil
+}
+
+// parseHost parses a bare address or host name.
+func parseHost(host string) (Target, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addrTarget(addr.Unmap()), nil
+	}
+	name := NormalizeHostname(host)
+	if !ValidHostname(name) {
+		return Target{}, fmt.Errorf("invalid target %q", host)
+	}
+	return Target{Kind: KindHostname, Host: name}, nil
+}
+
+func addrTarget(addr netip.Addr) Target {
+	return Target{Kind: KindIP, Host: addr.String(), Addr: addr}
+}
+
+// OctetSpan is an inclusive run of values one octet of a range takes.
+type OctetSpan struct {
+	First, Last byte
+}
+
+// parseRange accepts nmap-style IPv4 octet ranges: each octet is a number,
+// a span such as 1-254, a comma list of those, or * for 0-255, as in
+// 10.0.0.1-20 or 10.0.0-5.1-254. ok reports whether s looks like a range at
+// all; err is set when it does but the range is malformed.
+func parseRange(s string) (t Target, ok bool, err error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 4 || !strings.ContainsAny(s, "-,*") || strings.Trim(s, "0123456789.-,*") != "" {
+		return Target{}, false, nil
+	}
+	t.Kind = KindRange
+	var first [4]byte
+	for i, part := range parts {
+		spans, err := parseOctet(part)
+		if err != nil {
+			return Target{}, true, fmt.Errorf("invalid range %q: %w", s, err)
+		}
+		t.Octets[i] = spans
+		first[i] = spans[0].First
+	}
+	t.Addr = netip.AddrFrom4(first)
+	return t, true, nil
+}
+
+// parseOctet parses one octet of a range into sorted, merged spans.
+func parseOctet(s string) ([]OctetSpan, error) {
+	if s == "*" {
+		return []OctetSpan{{0, 255}}, nil
+	}
+	var spans []OctetSpan
+	for _, item := range strings.Split(s, ",") {
+		if item == "" {
+			return nil, fmt.Errorf("empty octet")
+		}
+		lo, hi, isSpan := strings.Cut(item, "-")
+		first, err := parseOctetValue(lo, 0)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isSpan {
+			if last, err = parseOctetValue(hi, 255); err != nil {
+				return nil, err
+			}
+		}
+		if last < first {
+			return nil, fmt.Errorf("octet span %q runs backwards", item)
+		}
+		spans = append(spans, OctetSpan{first, last})
+	}
+	slices.SortFunc(spans, func(a, b OctetSpan) int { return int(a.First) - int(b.First) })
+	merged := spans[:1]
+	for _, sp := range spans[1:] {
+		prev := &merged[len(merged)-1]
+		if int(sp.First) <= int(prev.Last)+1 {
+			prev.Last = max(prev.Last, sp.Last)
+			continue
+		}
+		merged = append(merged, sp)
+	}
+	return merged, nil
+}
+
+// parseOctetValue parses one octet value. An empty value is an open span
+// end and takes def.
+func parseOctetValue(s string, def byte) (byte, error) {
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 255 {
+		return 0, fmt.Errorf("octet %q is not a number from 0 to 255", s)
+	}
+	return byte(n), nil
+}
+
+// Size returns how many addresses a range covers.
+func (t Target) Size() int {
+	n := 1
+	for _, spans := range t.Octets {
+		c := 0
+		for _, sp := range spans {
+			c += int(sp.Last) - int(sp.First) + 1
+		}
+		n *= c
+	}
+	return n
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return port, nil
+}
+
+// NormalizeHostname lower-cases a host name and strips a trailing dot.
+func NormalizeHostname(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// ValidHostname accepts DNS names made of letters, digits, hyphens and
+// underscores. The name must already be normalized.
+func ValidHostname(host string) bool {
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// DefaultPort returns the well-known port for a URL scheme, or zero.
+func DefaultPort(scheme string) int {
+	switch scheme {
+	case "http", "ws":
+		return 80
+	case "https", "wss":
+		return 443
+	}
+	return 0
+}
+
+// IsAddr reports whether the target's host is an IP address.
+func (t Target) IsAddr() bool { return t.Addr.IsValid() }
+
+// String returns the canonical form of the target.
+func (t Target) String() string {
+	switch t.Kind {
+	case KindCIDR:
+		return t.Prefix.String()
+	case KindRange:
+		octets := make([]string, len(t.Octets))
+		for i, spans := range t.Octets {
+			items := make([]string, len(spans))
+			for j, sp := range spans {
+				items[j] = strconv.Itoa(int(sp.First))
+				if sp.Last != sp.First {
+					items[j] += "-" + strconv.Itoa(int(sp.Last))
+				}
+			}
+			octets[i] = strings.Join(items, ",")
+		}
+		return strings.Join(octets, ".")
+	case KindHostPort:
+		return joinHostPort(t.Host, t.Port)
+	case KindURL:
+		return t.URL.String()
+	default:
+		return t.Host
+	}
+}
+
+func joinHostPort(host string, port int) string {
+	if port == 0 {
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func unmapPrefix(p netip.Prefix) netip.Prefix {
+	if p.Addr().Is4In6() && p.Bits() >= 96 {
+		return netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+	}
+	return p
+}
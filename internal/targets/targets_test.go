@@ -0,0 +1,187 @@
+package targets
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		kind Kind
+		want string
+	}{
+		{"192.0.2.1", KindIP, "192.0.2.1"},
+		{"::ffff:192.0.2.1", KindIP, "192.0.2.1"},
+		{"2001:db8::1", KindIP, "2001:db8::1"},
+		{"10.0.0.7/24", KindCIDR, "10.0.0.0/24"},
+		{"10.0.0.7/32", KindIP, "10.0.0.7"},
+		{"10.0.0.1-20", KindRange, "10.0.0.1-20"},
+		{"10.0.0-5.1-254", KindRange, "10.0.0-5.1-254"},
+		{"192.168.3,1,2.*", KindRange, "192.168.1-3.0-255"},
+		{"10.0.0.-5", KindRange, "10.0.0.0-5"},
+		{"WWW.Example.COM.", KindHostname, "www.example.com"},
+		{"example.com:8443", KindHostPort, "example.com:8443"},
+		{"[2001:db8::1]:22", KindHostPort, "[2001:db8::1]:22"},
+		{"HTTPS://Example.com:443/", KindURL, "https://example.com"},
+		{"http://example.com:8080/a?b=1#frag", KindURL, "http://example.com:8080/a?b=1"},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got.Kind != tt.kind || got.String() != tt.want {
+			t.Errorf("Parse(%q) = %s %q, want %s %q", tt.in, got.Kind, got.String(), tt.kind, tt.want)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, in := range []string{"", "not a host", "example.com/path", "host:0", "10.0.0.20-1", "10.0.0-300.1", "10.0..1-5", "https://", "*.example.com"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	if k, err := ParseKind(" URL "); err != nil || k != KindURL {
+		t.Errorf("ParseKind(URL) = %q, %v", k, err)
+	}
+	if _, err := ParseKind("email"); err == nil {
+		t.Error("expected unknown kind error")
+	}
+}
+
+func TestNormalize_DedupesCanonicalForms(t *testing.T) {
+	res, err := Normalize([]string{
+		"example.com",
+		"EXAMPLE.com.",
+		"https://example.com/",
+		"https://example.com:443",
+		"10.0.0.1",
+		"10.0.0.1/32",
+		"",
+	}, Options{})
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	want := []string{"example.com", "https://example.com", "10.0.0.1"}
+	if !reflect.DeepEqual(res.Targets, want) {
+		t.Errorf("Targets = %v, want %v", res.Targets, want)
+	}
+	if res.Stats.Input != 6 || res.Stats.Duplicates != 3 || res.Stats.Output != 3 {
+		t.Errorf("Stats = %+v", res.Stats)
+	}
+}
+
+func TestNormalize_ConvertsToAcceptedKinds(t *testing.T) {
+	res, err := Normalize([]string{
+		"example.com",
+		"192.0.2.1",
+		"example.com:8080",
+		"example.com:8443",
+		"https://example.com/login",
+	}, Options{Accept: []Kind{KindURL}})
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	want := []string{
+		"https://example.com",
+		"https://192.0.2.1",
+		"http://example.com:8080",
+		"https://example.com:8443",
+		"https://example.com/login",
+	}
+	if !reflect.DeepEqual(res.Targets, want) {
+		t.Errorf("Targets = %v, want %v", res.Targets, want)
+	}
+	if res.Stats.Converted != 4 {
+		t.Errorf("Converted = %d, want 4", res.Stats.Converted)
+	}
+
+	res, err = Normalize([]string{"https://www.example.com/x", "api.example.com:8443"}, Options{Accept: []Kind{KindHostname}})
+	if err != nil {
+		t.Fatalf("Normalize hostname: %v", err)
+	}
+	if want := []string{"www.example.com", "api.example.com"}; !reflect.DeepEqual(res.Targets, want) {
+		t.Errorf("hostname Targets = %v, want %v", res.Targets, want)
+	}
+}
+
+func TestNormalize_RejectsUnconvertibleKinds(t *testing.T) {
+	_, err := Normalize([]string{"10.0.0.1", "example.com", "bad target"}, Options{Accept: []Kind{KindIP, KindCIDR}})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "2 invalid target(s)") || !strings.Contains(msg, "example.com: hostname targets are not accepted") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNormalize_SplitAndExpandCIDRs(t *testing.T) {
+	res, err := Normalize([]string{"10.0.0.0/22", "10.0.1.0/24", "192.168.0.0/24"}, Options{SplitCIDR: 24})
+	if err != nil {
+		t.Fatalf("Normalize split: %v", err)
+	}
+	want := []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24", "192.168.0.0/24"}
+	if !reflect.DeepEqual(res.Targets, want) {
+		t.Errorf("split = %v, want %v", res.Targets, want)
+	}
+	if res.Stats.Split != 1 || res.Stats.Duplicates != 1 {
+		t.Errorf("split stats = %+v", res.Stats)
+	}
+
+	res, err = Normalize([]string{"10.0.0.0/30", "10.0.0.2-5"}, Options{ExpandCIDR: true})
+	if err != nil {
+		t.Fatalf("Normalize expand: %v", err)
+	}
+	want = []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"}
+	if !reflect.DeepEqual(res.Targets, want) {
+		t.Errorf("expand = %v, want %v", res.Targets, want)
+	}
+
+	if _, err := Normalize([]string{"10.0.0.0/8"}, Options{ExpandCIDR: true, MaxExpand: 1024}); err == nil {
+		t.Error("expected expansion cap error")
+	}
+	if _, err := Normalize([]string{"10.0.0.0/8"}, Options{SplitCIDR: 24, MaxExpand: 1024}); err == nil {
+		t.Error("expected split cap error")
+	}
+}
+
+func TestConvert_CIDRToAddresses(t *testing.T) {
+	cidr, _ := Parse("192.0.2.0/31")
+	got, err := Convert(cidr, []Kind{KindIP}, 16)
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	if len(got) != 2 || got[0].String() != "192.0.2.0" || got[1].String() != "192.0.2.1" {
+		t.Errorf("Convert = %v", got)
+	}
+}
+
+func TestAddrs_OctetRange(t *testing.T) {
+	r, err := Parse("10.0.1-2.5,7")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got, err := Addrs(r, 16)
+	if err != nil {
+		t.Fatalf("Addrs: %v", err)
+	}
+	var s []string
+	for _, a := range got {
+		s = append(s, a.String())
+	}
+	want := []string{"10.0.1.5", "10.0.1.7", "10.0.2.5", "10.0.2.7"}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Addrs = %v, want %v", s, want)
+	}
+	if _, err := Addrs(r, 3); err == nil {
+		t.Error("Addrs over max succeeded, want error")
+	}
+}
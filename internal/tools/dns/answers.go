@@ -0,0 +1,123 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Answer is one resolved name from a JSONL report: the addresses and
+// CNAME targets it resolved to.
+type Answer struct {
+	Host   string
+	Values []string
+	Line   []byte
+}
+
+// record holds the fields of a dnsx (-j) or massdns (-o J) report line.
+type record struct {
+	// dnsx
+	Host  string   `json:"host"`
+	A     []string `json:"a"`
+	AAAA  []string `json:"aaaa"`
+	CNAME []string `json:"cname"`
+	// massdns
+	Name string `json:"name"`
+	Data struct {
+		Answers []struct {
+			Type string `json:"type"`
+			Data string `json:"data"`
+		} `json:"answers"`
+	} `json:"data"`
+}
+
+func (r record) answer() Answer {
+	a := Answer{Host: normalizeName(r.Host)}
+	add := func(v string) {
+		if v = normalizeName(v); v != "" && !slices.Contains(a.Values, v) {
+			a.Values = append(a.Values, v)
+		}
+	}
+	if a.Host == "" {
+		a.Host = normalizeName(r.Name)
+		for _, ans := range r.Data.Answers {
+			switch ans.Type {
+			case "A", "AAAA", "CNAME":
+				add(ans.Data)
+			}
+		}
+	}
+	for _, v := range slices.Concat(r.A, r.AAAA, r.CNAME) {
+		add(v)
+	}
+	slices.Sort(a.Values)
+	return a
+}
+
+func normalizeName(s string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), "."))
+}
+
+// ParseAnswers reads the answers of a dnsx or massdns JSONL report. Lines
+// that are not JSON or name no host are skipped.
+func ParseAnswers(data []byte) []Answer {
+	var out []Answer
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var r record
+		if json.Unmarshal(line, &r) != nil {
+			continue
+		}
+		a := r.answer()
+		if a.Host == "" {
+			continue
+		}
+		a.Line = slices.Clone(line)
+		out = append(out, a)
+	}
+	return out
+}
+
+// FilterStats summarises a wildcard filter over JSONL reports.
+type FilterStats struct {
+	Reports  int // reports read
+	Answers  int // answers kept
+	Wildcard int // answers dropped as wildcard noise
+}
+
+// FilterReports concatenates the JSONL reports, keyed by artifact key, in
+// key order and drops the answers w matches, including those of the
+// probes themselves.
+func FilterReports(reports map[string][]byte, w Wildcards) ([]byte, FilterStats) {
+	keys := make([]string, 0, len(reports))
+	for key := range reports {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var (
+		out   bytes.Buffer
+		stats FilterStats
+	)
+	for _, key := range keys {
+		stats.Reports++
+		for _, a := range ParseAnswers(reports[key]) {
+			if w.Matches(a) {
+				stats.Wildcard++
+				continue
+			}
+			stats.Answers++
+			out.Write(a.Line)
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes(), stats
+}
@@ -0,0 +1,182 @@
+// Package dns detects wildcard DNS for resolution modules such as dnsx and
+// massdns and filters the answers it would otherwise flood results with.
+package dns
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"heph4estus/internal/worker"
+)
+
+// ProbeGroup is the group ID of wildcard probe tasks. It keeps their
+// results and artifacts apart from those of the job's own targets.
+const ProbeGroup = "wildcard-probe"
+
+// probeLabel prefixes the random label a probe resolves.
+const probeLabel = "heph-wc-"
+
+// secondLevel lists the second-level labels under two-letter country TLDs
+// that are registries rather than registrable domains (co.uk, com.au).
+var secondLevel = []string{"ac", "co", "com", "edu", "gov", "ltd", "me", "net", "or", "org", "plc", "sch"}
+
+// Apex returns the registrable domain of host, or "" when host is an IP
+// address or has fewer than two labels. It recognizes the common
+// country-code second levels, not the full public suffix list.
+func Apex(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+	if _, err := netip.ParseAddr(host); err == nil {
+		return ""
+	}
+	labels := strings.Split(host, ".")
+	n := 2
+	if len(labels) >= 2 && len(labels[len(labels)-1]) == 2 && slices.Contains(secondLevel, labels[len(labels)-2]) {
+		n = 3
+	}
+	if len(labels) < n || slices.Contains(labels[len(labels)-n:], "") {
+		return ""
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// ProbesPerZone is how many random names each zone is probed with, so a
+// wildcard that rotates through a pool of addresses shows more of them.
+const ProbesPerZone = 3
+
+// ProbeName returns the name the n-th probe of a job resolves under zone:
+// a label derived from the job ID, which no real host is expected to carry.
+func ProbeName(jobID, zone string, n int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d", jobID, zone, n)))
+	return probeLabel + hex.EncodeToString(sum[:6]) + "." + zone
+}
+
+// Zone returns the zone a wildcard probe for host covers: the domain host
+// sits directly under, or its apex when host is the apex itself. It
+// returns "" for IP addresses and names without an apex.
+func Zone(host string) string {
+	apex := Apex(host)
+	if apex == "" {
+		return ""
+	}
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+	if _, parent, ok := strings.Cut(host, "."); ok && strings.HasSuffix(parent, apex) {
+		return parent
+	}
+	return apex
+}
+
+// IsProbe reports whether host is a probe name.
+func IsProbe(host string) bool {
+	return strings.HasPrefix(strings.ToLower(host), probeLabel)
+}
+
+// ProbeTasks returns ProbesPerZone probe tasks for each distinct zone of
+// hosts, in first-seen order. Each resolves a random name in its zone; any
+// answer it gets comes from a wildcard record.
+func ProbeTasks(tool, jobID string, hosts []string) []worker.Task {
+	var zones []string
+	for _, h := range hosts {
+		if zone := Zone(h); zone != "" && !slices.Contains(zones, zone) {
+			zones = append(zones, zone)
+		}
+	}
+	total := len(zones) * ProbesPerZone
+	tasks := make([]worker.Task, 0, total)
+	for _, zone := range zones {
+		for n := range ProbesPerZone {
+			tasks = append(tasks, worker.Task{
+				ToolName:    tool,
+				JobID:       jobID,
+				Target:      ProbeName(jobID, zone, n),
+				GroupID:     ProbeGroup,
+				ChunkIdx:    len(tasks),
+				TotalChunks: total,
+			})
+		}
+	}
+	return tasks
+}
+
+// Wildcards maps each domain with wildcard DNS to the answers a random name
+// under it resolved to.
+type Wildcards map[string][]string
+
+// Domains returns the wildcard domains in order.
+func (w Wildcards) Domains() []string {
+	domains := make([]string, 0, len(w))
+	for d := range w {
+		domains = append(domains, d)
+	}
+	slices.Sort(domains)
+	return domains
+}
+
+func (w Wildcards) String() string {
+	parts := make([]string, 0, len(w))
+	for _, d := range w.Domains() {
+		parts = append(parts, fmt.Sprintf("%s (%s)", d, strings.Join(w[d], ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Domain returns the wildcard domain host falls under, or "" when none
+// does. The closest enclosing domain wins.
+func (w Wildcards) Domain(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for {
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			return ""
+		}
+		if _, found := w[parent]; found {
+			return parent
+		}
+		host = parent
+	}
+}
+
+// Matches reports whether an answer is wildcard noise: the host falls under
+// a wildcard domain and every value it resolved to is one of the wildcard's
+// answers. Probe answers always match.
+func (w Wildcards) Matches(a Answer) bool {
+	if IsProbe(a.Host) {
+		return true
+	}
+	domain := w.Domain(a.Host)
+	if domain == "" || len(a.Values) == 0 {
+		return false
+	}
+	for _, v := range a.Values {
+		if !slices.Contains(w[domain], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// DetectWildcards collects the answers of probe reports, keyed by artifact
+// key, into wildcards. Probes that resolved to nothing are left out.
+func DetectWildcards(reports map[string][]byte) Wildcards {
+	w := make(Wildcards)
+	for _, data := range reports {
+		for _, a := range ParseAnswers(data) {
+			if !IsProbe(a.Host) || len(a.Values) == 0 {
+				continue
+			}
+			_, domain, _ := strings.Cut(a.Host, ".")
+			for _, v := range a.Values {
+				if !slices.Contains(w[domain], v) {
+					w[domain] = append(w[domain], v)
+				}
+			}
+		}
+	}
+	for d := range w {
+		slices.Sort(w[d])
+	}
+	return w
+}
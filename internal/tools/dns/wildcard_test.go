@@ -0,0 +1,113 @@
+package dns
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApex(t *testing.T) {
+	for host, want := range map[string]string{
+		"www.example.com":      "example.com",
+		"a.b.Example.COM.":     "example.com",
+		"example.com":          "example.com",
+		"shop.example.co.uk":   "example.co.uk",
+		"co.uk":                "",
+		"localhost":            "",
+		"10.0.0.1":             "",
+		"x.y.example.com.au":   "example.com.au",
+		"deep.sub.example.org": "example.org",
+	} {
+		if got := Apex(host); got != want {
+			t.Errorf("Apex(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestZone(t *testing.T) {
+	for host, want := range map[string]string{
+		"www.example.com":    "example.com",
+		"example.com":        "example.com",
+		"a.dev.Example.COM.": "dev.example.com",
+		"shop.example.co.uk": "example.co.uk",
+		"x.y.example.com.au": "y.example.com.au",
+		"10.0.0.1":           "",
+		"localhost":          "",
+	} {
+		if got := Zone(host); got != want {
+			t.Errorf("Zone(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestProbeTasks(t *testing.T) {
+	tasks := ProbeTasks("dnsx", "job-1", []string{"a.example.com", "b.example.com", "10.0.0.1", "x.dev.example.com", "www.example.org"})
+	if len(tasks) != 3*ProbesPerZone {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+	var zones []string
+	names := map[string]bool{}
+	for i, task := range tasks {
+		if !IsProbe(task.Target) || task.GroupID != ProbeGroup || task.ChunkIdx != i || task.TotalChunks != len(tasks) {
+			t.Errorf("probe = %+v", task)
+		}
+		names[task.Target] = true
+		_, zone, _ := strings.Cut(task.Target, ".")
+		if len(zones) == 0 || zones[len(zones)-1] != zone {
+			zones = append(zones, zone)
+		}
+	}
+	if strings.Join(zones, ",") != "example.com,dev.example.com,example.org" {
+		t.Errorf("probed zones = %v", zones)
+	}
+	if len(names) != len(tasks) {
+		t.Errorf("probe names repeat within a job: %v", names)
+	}
+	first := tasks[0]
+	if again := ProbeTasks("dnsx", "job-1", []string{"a.example.com"}); again[0].Target != first.Target {
+		t.Error("probe names are not stable for a job")
+	}
+	if other := ProbeTasks("dnsx", "job-2", []string{"a.example.com"}); other[0].Target == first.Target {
+		t.Error("probe names repeat across jobs")
+	}
+}
+
+func TestDetectAndFilter(t *testing.T) {
+	// A rotating wildcard answers each probe name differently; together
+	// the probes collect its pool.
+	reports := map[string][]byte{
+		"wildcard-probe/probe0.jsonl": []byte(`{"host":"` + ProbeName("job-1", "example.com", 0) + `","a":["1.2.3.4"]}` + "\n"),
+		"wildcard-probe/probe1.jsonl": []byte(`{"host":"` + ProbeName("job-1", "example.com", 1) + `","a":["1.2.3.5"]}` + "\n"),
+		"other/probe.jsonl":           []byte(`{"name":"` + ProbeName("job-1", "example.org", 0) + `.","status":"NXDOMAIN","data":{}}` + "\n"),
+	}
+	w := DetectWildcards(reports)
+	if w.String() != "example.com (1.2.3.4, 1.2.3.5)" {
+		t.Fatalf("wildcards = %q", w)
+	}
+
+	results := map[string][]byte{
+		"a.jsonl": []byte(`{"host":"www.example.com","a":["9.9.9.9"]}` + "\n" +
+			`{"host":"junk.example.com","a":["1.2.3.5"]}` + "\n" +
+			`{"host":"mixed.dev.example.com","a":["1.2.3.4","8.8.8.8"]}` + "\n" +
+			"not json\n"),
+		"b.jsonl": []byte(`{"name":"api.example.com.","type":"A","data":{"answers":[{"type":"A","data":"1.2.3.4"}]}}` + "\n" +
+			`{"name":"www.example.org.","type":"A","data":{"answers":[{"type":"CNAME","data":"cdn.example.net."},{"type":"A","data":"1.2.3.4"}]}}` + "\n"),
+	}
+	for k, v := range reports {
+		results[k] = v
+	}
+	out, stats := FilterReports(results, w)
+	if stats.Reports != 5 || stats.Answers != 3 || stats.Wildcard != 5 {
+		t.Fatalf("stats = %+v\n%s", stats, out)
+	}
+	for _, want := range []string{"www.example.com", "mixed.dev.example.com", "www.example.org"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("filtered output lacks %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "junk.example.com") || strings.Contains(string(out), "api.example.com") {
+		t.Errorf("wildcard answers kept:\n%s", out)
+	}
+	if d := w.Domain("a.b.example.com"); d != "example.com" {
+		t.Errorf("Domain = %q", d)
+	}
+}
@@ -0,0 +1,103 @@
+package nmap
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"heph4estus/internal/targets"
+)
+
+// Distribution modes for ParseTargetsWithMode and heph nmap --mode.
+const (
+	ModeTargetOnly       = "target-only"
+	ModeTargetPorts      = "target-ports"
+	ModeDiscoverThenScan = "discover-then-scan"
+)
+
+// DiscoveryGroup is the group ID of host discovery tasks. It keeps their
+// results and artifacts apart from those of the port scan that follows.
+const DiscoveryGroup = "discovery"
+
+// DefaultDiscoveryOptions is the nmap host discovery scan.
+const DefaultDiscoveryOptions = "-sn"
+
+// DiscoverySplitCIDR is the /N block size wide IPv4 CIDRs are split into
+// for host discovery unless --split-cidr says otherwise, so a wide network
+// spreads across workers.
+const DiscoverySplitCIDR = 24
+
+// PortOptionsKey is the queue task metadata key that carries a discovery
+// task's PortOptions.
+const PortOptionsKey = "port_options"
+
+// LiveHost is a host found up by discovery, with the options its port scan
+// runs with.
+type LiveHost struct {
+	Address string
+	Options string
+}
+
+// ParseDiscoveryTasks returns one host discovery task per target line. The
+// task runs discoveryOptions; the line's own options, or defaultOptions,
+// are kept in PortOptions for the port scan of the hosts it finds.
+func (s *Scanner) ParseDiscoveryTasks(content, defaultOptions, discoveryOptions string) []ScanTask {
+	lines := s.ParseTargets(content, defaultOptions)
+	tasks := make([]ScanTask, len(lines))
+	for i, l := range lines {
+		tasks[i] = ScanTask{
+			Target:      l.Target,
+			Options:     discoveryOptions,
+			GroupID:     DiscoveryGroup,
+			ChunkIdx:    i,
+			TotalChunks: len(lines),
+			PortOptions: l.Options,
+		}
+	}
+	return tasks
+}
+
+// PortScanTasks builds the port scan phase: port-chunked tasks for each live
+// host. Discovery already showed the hosts are up, so -Pn is added to skip
+// nmap's own host discovery.
+func (s *Scanner) PortScanTasks(hosts []LiveHost, portChunks int) []ScanTask {
+	var b strings.Builder
+	seen := make(map[string]bool)
+	for _, h := range hosts {
+		if h.Address == "" || seen[h.Address] {
+			continue
+		}
+		seen[h.Address] = true
+		opts := h.Options
+		if !hasFlag(opts, "-Pn") {
+			opts = strings.TrimSpace("-Pn " + opts)
+		}
+		fmt.Fprintf(&b, "%s %s\n", h.Address, opts)
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	return s.ParseTargetsWithMode(b.String(), "", ModeTargetPorts, portChunks)
+}
+
+// UpHosts returns the addresses of the hosts an nmap XML report found up.
+func UpHosts(report []byte) ([]string, error) {
+	entries, err := targets.Import(bytes.NewReader(report), targets.FormatNmapXML)
+	if err != nil {
+		return nil, fmt.Errorf("parsing nmap XML: %w", err)
+	}
+	hosts := make([]string, len(entries))
+	for i, e := range entries {
+		hosts[i] = e.Target
+	}
+	return hosts, nil
+}
+
+func hasFlag(options, flag string) bool {
+	for _, f := range strings.Fields(options) {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package nmap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDiscoveryTasks(t *testing.T) {
+	s := NewScanner(nil)
+	tasks := s.ParseDiscoveryTasks("10.0.0.0/24 -sV -p 22,80\n10.0.1.0/24\n", "-sS", DefaultDiscoveryOptions)
+
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+	for i, task := range tasks {
+		if task.Options != "-sn" {
+			t.Errorf("task %d: options = %q, want -sn", i, task.Options)
+		}
+		if task.GroupID != DiscoveryGroup || task.ChunkIdx != i || task.TotalChunks != 2 {
+			t.Errorf("task %d: chunk fields = %q %d/%d", i, task.GroupID, task.ChunkIdx, task.TotalChunks)
+		}
+	}
+	if tasks[0].PortOptions != "-sV -p 22,80" {
+		t.Errorf("line options not kept: %q", tasks[0].PortOptions)
+	}
+	if tasks[1].PortOptions != "-sS" {
+		t.Errorf("default options not kept: %q", tasks[1].PortOptions)
+	}
+}
+
+func TestPortScanTasks(t *testing.T) {
+	s := NewScanner(nil)
+	tasks := s.PortScanTasks([]LiveHost{
+		{Address: "10.0.0.5", Options: "-sS -p 1-100"},
+		{Address: "10.0.0.5", Options: "-sS -p 1-100"},
+		{Address: "10.0.0.9", Options: "-Pn -sV -p 22"},
+	}, 2)
+
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks (2 chunks + 1 single port), got %d", len(tasks))
+	}
+	if tasks[0].Target != "10.0.0.5" || !strings.HasPrefix(tasks[0].Options, "-Pn -sS -p ") {
+		t.Errorf("task 0 = %+v", tasks[0])
+	}
+	if tasks[0].TotalChunks != 2 {
+		t.Errorf("TotalChunks = %d, want 2", tasks[0].TotalChunks)
+	}
+	if tasks[2].Target != "10.0.0.9" || strings.Count(tasks[2].Options, "-Pn") != 1 {
+		t.Errorf("task 2 = %+v", tasks[2])
+	}
+
+	if got := s.PortScanTasks(nil, 5); got != nil {
+		t.Errorf("no hosts should give no tasks, got %d", len(got))
+	}
+}
+
+func TestUpHosts(t *testing.T) {
+	report := `<?xml version="1.0"?>
+<nmaprun>
+<host><status state="up"/><address addr="10.0.0.5" addrtype="ipv4"/></host>
+<host><status state="down"/><address addr="10.0.0.6" addrtype="ipv4"/></host>
+<host><status state="up"/><address addr="aa:bb:cc:dd:ee:ff" addrtype="mac"/><address addr="10.0.0.7" addrtype="ipv4"/></host>
+</nmaprun>`
+	hosts, err := UpHosts([]byte(report))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(hosts, ",") != "10.0.0.5,10.0.0.7" {
+		t.Errorf("hosts = %v", hosts)
+	}
+	if _, err := UpHosts([]byte("not xml")); err == nil {
+		t.Error("expected error for invalid XML")
+	}
+}
@@ -0,0 +1,49 @@
+package nmap
+
+import "strings"
+
+// DefaultHandoffOptions are the options used for hosts handed off from a
+// fast port discovery scan such as masscan: service and version detection
+// plus the default scripts.
+const DefaultHandoffOptions = "-sV -sC"
+
+// PortScanOptions returns options that scan exactly ports, a -p list such
+// as "22,443,U:53", replacing any port selection in options. Scan types are
+// added for protocols in ports that options do not cover; once UDP or SCTP
+// ports need a scan type, nmap's implicit TCP scan no longer applies, so
+// -sS is added for TCP ports too.
+func PortScanOptions(options, ports string) string {
+	if _, remaining, found, err := ExtractPorts(options); err == nil && found {
+		options = remaining
+	}
+	set, err := ParsePorts(ports)
+	if err != nil {
+		return strings.TrimSpace(options + " -p " + ports)
+	}
+
+	needed := map[string]bool{
+		ProtoTCP:  len(set.Any) > 0 || len(set.TCP) > 0,
+		ProtoUDP:  len(set.UDP) > 0,
+		ProtoSCTP: len(set.SCTP) > 0,
+	}
+	explicit := explicitScanProtocols(options)
+	covered := make(map[string]bool)
+	for _, p := range explicit {
+		covered[p] = true
+	}
+	implicitTCP := len(explicit) == 0 && !needed[ProtoUDP] && !needed[ProtoSCTP]
+
+	args := []string{options}
+	for _, st := range []struct{ proto, flag string }{
+		{ProtoTCP, "-sS"},
+		{ProtoUDP, "-sU"},
+		{ProtoSCTP, "-sY"},
+	} {
+		if !needed[st.proto] || covered[st.proto] || (st.proto == ProtoTCP && implicitTCP) {
+			continue
+		}
+		args = append(args, st.flag)
+	}
+	args = append(args, "-p", set.String())
+	return strings.TrimSpace(strings.Join(args, " "))
+}
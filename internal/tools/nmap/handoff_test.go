@@ -0,0 +1,22 @@
+package nmap
+
+import "testing"
+
+func TestPortScanOptions(t *testing.T) {
+	tests := []struct {
+		options, ports, want string
+	}{
+		{"-sV -sC", "22,443", "-sV -sC -p 22,443"},
+		{"-sV -sC", "22,U:53", "-sV -sC -sS -sU -p 22,U:53"},
+		{"-sV", "U:53,U:161", "-sV -sU -p U:53,161"},
+		{"-sT -sV", "80,U:53", "-sT -sV -sU -p 80,U:53"},
+		{"-sS -sU -sV", "80,U:53", "-sS -sU -sV -p 80,U:53"},
+		{"-sV -p 1-1000", "8080", "-sV -p 8080"},
+		{"-sV", "S:80", "-sV -sY -p S:80"},
+	}
+	for _, tt := range tests {
+		if got := PortScanOptions(tt.options, tt.ports); got != tt.want {
+			t.Errorf("PortScanOptions(%q, %q) = %q, want %q", tt.options, tt.ports, got, tt.want)
+		}
+	}
+}
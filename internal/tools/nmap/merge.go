@@ -0,0 +1,562 @@
+package nmap
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/netip"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats a merged report can be written in.
+const (
+	MergeFormatXML   = "xml"
+	MergeFormatGnmap = "gnmap"
+	MergeFormatJSON  = "json"
+)
+
+// MergeFormats lists every merged report format.
+var MergeFormats = []string{MergeFormatXML, MergeFormatGnmap, MergeFormatJSON}
+
+// Run is an nmap XML report (an nmaprun element), reduced to what a merged
+// report keeps.
+type Run struct {
+	XMLName          xml.Name   `xml:"nmaprun" json:"-"`
+	Scanner          string     `xml:"scanner,attr" json:"scanner"`
+	Args             string     `xml:"args,attr,omitempty" json:"args,omitempty"`
+	Start            int64      `xml:"start,attr,omitempty" json:"start,omitempty"`
+	StartStr         string     `xml:"startstr,attr,omitempty" json:"startstr,omitempty"`
+	Version          string     `xml:"version,attr,omitempty" json:"version,omitempty"`
+	XMLOutputVersion string     `xml:"xmloutputversion,attr,omitempty" json:"-"`
+	ScanInfo         []ScanInfo `xml:"scaninfo" json:"scaninfo,omitempty"`
+	Hosts            []Host     `xml:"host" json:"hosts"`
+	RunStats         RunStats   `xml:"runstats" json:"runstats"`
+}
+
+// ScanInfo describes one scan type and the ports it covered.
+type ScanInfo struct {
+	Type        string `xml:"type,attr" json:"type"`
+	Protocol    string `xml:"protocol,attr" json:"protocol"`
+	NumServices int    `xml:"numservices,attr" json:"numservices"`
+	Services    string `xml:"services,attr" json:"services"`
+}
+
+// Host is one host of a report.
+type Host struct {
+	StartTime   int64      `xml:"starttime,attr,omitempty" json:"starttime,omitempty"`
+	EndTime     int64      `xml:"endtime,attr,omitempty" json:"endtime,omitempty"`
+	Status      Status     `xml:"status" json:"status"`
+	Addresses   []Address  `xml:"address" json:"addresses"`
+	Hostnames   []Hostname `xml:"hostnames>hostname" json:"hostnames,omitempty"`
+	Ports       []Port     `xml:"ports>port" json:"ports,omitempty"`
+	OS          *RawXML    `xml:"os" json:"-"`
+	HostScripts []Script   `xml:"hostscript>script" json:"hostscripts,omitempty"`
+	Times       *Times     `xml:"times" json:"-"`
+}
+
+// Status is a host's or port's state and why nmap decided it.
+type Status struct {
+	State     string `xml:"state,attr" json:"state"`
+	Reason    string `xml:"reason,attr,omitempty" json:"reason,omitempty"`
+	ReasonTTL string `xml:"reason_ttl,attr,omitempty" json:"-"`
+}
+
+// Address is a host address.
+type Address struct {
+	Addr     string `xml:"addr,attr" json:"addr"`
+	AddrType string `xml:"addrtype,attr" json:"addrtype"`
+	Vendor   string `xml:"vendor,attr,omitempty" json:"vendor,omitempty"`
+}
+
+// Hostname is a host name and where it came from.
+type Hostname struct {
+	Name string `xml:"name,attr" json:"name"`
+	Type string `xml:"type,attr,omitempty" json:"type,omitempty"`
+}
+
+// Port is one scanned port.
+type Port struct {
+	Protocol string   `xml:"protocol,attr" json:"protocol"`
+	PortID   int      `xml:"portid,attr" json:"portid"`
+	State    Status   `xml:"state" json:"state"`
+	Service  *Service `xml:"service" json:"service,omitempty"`
+	Scripts  []Script `xml:"script" json:"scripts,omitempty"`
+}
+
+// Service is the service detected on a port.
+type Service struct {
+	Name      string   `xml:"name,attr" json:"name"`
+	Product   string   `xml:"product,attr,omitempty" json:"product,omitempty"`
+	Version   string   `xml:"version,attr,omitempty" json:"version,omitempty"`
+	ExtraInfo string   `xml:"extrainfo,attr,omitempty" json:"extrainfo,omitempty"`
+	Tunnel    string   `xml:"tunnel,attr,omitempty" json:"tunnel,omitempty"`
+	Method    string   `xml:"method,attr,omitempty" json:"method,omitempty"`
+	Conf      string   `xml:"conf,attr,omitempty" json:"conf,omitempty"`
+	CPE       []string `xml:"cpe" json:"cpe,omitempty"`
+}
+
+// Script is the output of one NSE script. Its structured output is kept
+// verbatim.
+type Script struct {
+	ID     string `xml:"id,attr" json:"id"`
+	Output string `xml:"output,attr" json:"output"`
+	Inner  string `xml:",innerxml" json:"-"`
+}
+
+// RawXML keeps an element's content verbatim.
+type RawXML struct {
+	Inner string `xml:",innerxml"`
+}
+
+// Times is nmap's round-trip timing for a host.
+type Times struct {
+	SRTT   string `xml:"srtt,attr"`
+	RTTVar string `xml:"rttvar,attr"`
+	To     string `xml:"to,attr"`
+}
+
+// RunStats is a report's closing summary.
+type RunStats struct {
+	Finished Finished  `xml:"finished" json:"finished"`
+	Hosts    HostStats `xml:"hosts" json:"hosts"`
+}
+
+// Finished records when and how a scan ended.
+type Finished struct {
+	Time    int64   `xml:"time,attr" json:"time"`
+	TimeStr string  `xml:"timestr,attr,omitempty" json:"timestr,omitempty"`
+	Elapsed float64 `xml:"elapsed,attr" json:"elapsed"`
+	Summary string  `xml:"summary,attr,omitempty" json:"summary,omitempty"`
+	Exit    string  `xml:"exit,attr,omitempty" json:"exit,omitempty"`
+}
+
+// HostStats counts the hosts of a report.
+type HostStats struct {
+	Up    int `xml:"up,attr" json:"up"`
+	Down  int `xml:"down,attr" json:"down"`
+	Total int `xml:"total,attr" json:"total"`
+}
+
+// ParseRun decodes an nmap XML report.
+func ParseRun(data []byte) (*Run, error) {
+	var run Run
+	if err := xml.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("parsing nmap XML: %w", err)
+	}
+	return &run, nil
+}
+
+// MergeRuns combines the reports of one scan's chunks into a single report.
+// Hosts are merged by address, their ports by protocol and number and their
+// scripts by ID. The merged scan starts with the earliest report and
+// finishes with the latest.
+func MergeRuns(runs []*Run) *Run {
+	merged := &Run{Scanner: "nmap", XMLOutputVersion: "1.05"}
+	hosts := make(map[string]*Host)
+	var order []string
+	infos := make(map[string]map[int]bool)
+	var infoOrder []ScanInfo
+
+	for _, r := range runs {
+		if r == nil {
+			continue
+		}
+		if merged.Version == "" {
+			merged.Version = r.Version
+		}
+		if r.XMLOutputVersion != "" {
+			merged.XMLOutputVersion = r.XMLOutputVersion
+		}
+		if r.Start > 0 && (merged.Start == 0 || r.Start < merged.Start) {
+			merged.Start, merged.StartStr, merged.Args = r.Start, r.StartStr, r.Args
+		}
+		if merged.Args == "" {
+			merged.Args = r.Args
+		}
+		if r.RunStats.Finished.Time > merged.RunStats.Finished.Time {
+			merged.RunStats.Finished.Time = r.RunStats.Finished.Time
+			merged.RunStats.Finished.TimeStr = r.RunStats.Finished.TimeStr
+		}
+		for _, si := range r.ScanInfo {
+			key := si.Type + "/" + si.Protocol
+			ports, err := ParsePorts(si.Services)
+			if err != nil {
+				continue
+			}
+			if infos[key] == nil {
+				infos[key] = make(map[int]bool)
+				infoOrder = append(infoOrder, ScanInfo{Type: si.Type, Protocol: si.Protocol})
+			}
+			for _, p := range ports.All() {
+				infos[key][p] = true
+			}
+		}
+		for _, h := range r.Hosts {
+			key := hostKey(h)
+			if existing, ok := hosts[key]; ok {
+				mergeHost(existing, h)
+				continue
+			}
+			h := h
+			h.Addresses = slices.Clone(h.Addresses)
+			h.Hostnames = slices.Clone(h.Hostnames)
+			h.HostScripts = slices.Clone(h.HostScripts)
+			ports := h.Ports
+			h.Ports = nil
+			mergePorts(&h, ports)
+			hosts[key] = &h
+			order = append(order, key)
+		}
+	}
+
+	for _, si := range infoOrder {
+		ports := sortedPorts(infos[si.Type+"/"+si.Protocol])
+		si.NumServices = len(ports)
+		si.Services = FormatPortSpec(ports)
+		merged.ScanInfo = append(merged.ScanInfo, si)
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return lessHostKey(order[i], order[j]) })
+	for _, key := range order {
+		h := hosts[key]
+		sort.Slice(h.Ports, func(i, j int) bool {
+			if h.Ports[i].Protocol != h.Ports[j].Protocol {
+				return h.Ports[i].Protocol < h.Ports[j].Protocol
+			}
+			return h.Ports[i].PortID < h.Ports[j].PortID
+		})
+		merged.Hosts = append(merged.Hosts, *h)
+		if h.Status.State == "up" {
+			merged.RunStats.Hosts.Up++
+		} else {
+			merged.RunStats.Hosts.Down++
+		}
+	}
+	merged.RunStats.Hosts.Total = len(merged.Hosts)
+
+	fin := &merged.RunStats.Finished
+	if merged.Start > 0 && fin.Time >= merged.Start {
+		fin.Elapsed = float64(fin.Time - merged.Start)
+	}
+	if fin.TimeStr == "" && fin.Time > 0 {
+		fin.TimeStr = time.Unix(fin.Time, 0).UTC().Format(time.ANSIC)
+	}
+	fin.Exit = "success"
+	fin.Summary = fmt.Sprintf("Nmap done at %s; %d IP %s (%d %s up) scanned in %.2f seconds",
+		fin.TimeStr, merged.RunStats.Hosts.Total, plural(merged.RunStats.Hosts.Total, "address", "addresses"),
+		merged.RunStats.Hosts.Up, plural(merged.RunStats.Hosts.Up, "host", "hosts"), fin.Elapsed)
+	return merged
+}
+
+// MergeReports parses the chunk reports of a job, keyed by artifact key or
+// relative path, and merges them into one report for the whole job and one
+// per scan target. Reports that do not parse are skipped and returned in
+// skipped; job is nil when none parse.
+func MergeReports(reports map[string][]byte) (job *Run, perTarget map[string]*Run, skipped map[string]error) {
+	keys := make([]string, 0, len(reports))
+	for key := range reports {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var all []*Run
+	byTarget := make(map[string][]*Run)
+	skipped = make(map[string]error)
+	for _, key := range keys {
+		run, err := ParseRun(reports[key])
+		if err != nil {
+			skipped[key] = err
+			continue
+		}
+		all = append(all, run)
+		stem := targetStem(key)
+		byTarget[stem] = append(byTarget[stem], run)
+	}
+
+	if len(all) == 0 {
+		return nil, nil, skipped
+	}
+	perTarget = make(map[string]*Run, len(byTarget))
+	for stem, runs := range byTarget {
+		perTarget[stem] = MergeRuns(runs)
+	}
+	return MergeRuns(all), perTarget, skipped
+}
+
+// Encode renders the report in format: xml, gnmap or json.
+func (r *Run) Encode(format string) ([]byte, error) {
+	switch format {
+	case MergeFormatXML:
+		return r.XML()
+	case MergeFormatGnmap:
+		return []byte(r.Gnmap()), nil
+	case MergeFormatJSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unknown merge format %q (want %s)", format, strings.Join(MergeFormats, ", "))
+	}
+}
+
+// WriteMerged writes the job report to dir as job.<format> and each
+// target's report as targets/<target>.<format>, once per format. It returns
+// the paths written.
+func WriteMerged(dir string, job *Run, perTarget map[string]*Run, formats []string) ([]string, error) {
+	stems := make([]string, 0, len(perTarget))
+	for stem := range perTarget {
+		stems = append(stems, stem)
+	}
+	sort.Strings(stems)
+
+	var written []string
+	write := func(name string, run *Run, format string) error {
+		data, err := run.Encode(format)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(dir, name+"."+format)
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", dest, err)
+		}
+		if err := os.WriteFile(dest, data, 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", dest, err)
+		}
+		written = append(written, dest)
+		return nil
+	}
+	for _, format := range formats {
+		if err := write("job", job, format); err != nil {
+			return written, err
+		}
+		for _, stem := range stems {
+			if err := write(filepath.Join("targets", stem), perTarget[stem], format); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// XML renders the report as a standalone nmap XML document.
+func (r *Run) XML() ([]byte, error) {
+	data, err := xml.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding nmap XML: %w", err)
+	}
+	out := []byte(xml.Header + "<!DOCTYPE nmaprun>\n")
+	out = append(out, data...)
+	return append(out, '\n'), nil
+}
+
+// Gnmap renders the report in nmap's grepable output format.
+func (r *Run) Gnmap() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Nmap %s scan initiated %s as: %s\n", r.Version, r.StartStr, r.Args)
+	for _, h := range r.Hosts {
+		name := ""
+		if len(h.Hostnames) > 0 {
+			name = h.Hostnames[0].Name
+		}
+		prefix := fmt.Sprintf("Host: %s (%s)", hostAddress(h), name)
+		state := h.Status.State
+		if state != "" {
+			state = strings.ToUpper(state[:1]) + state[1:]
+		}
+		fmt.Fprintf(&b, "%s\tStatus: %s\n", prefix, state)
+		if len(h.Ports) == 0 {
+			continue
+		}
+		parts := make([]string, len(h.Ports))
+		for i, p := range h.Ports {
+			service, version := "", ""
+			if p.Service != nil {
+				service = p.Service.Name
+				version = strings.Join(nonEmpty(p.Service.Product, p.Service.Version, p.Service.ExtraInfo), " ")
+			}
+			parts[i] = fmt.Sprintf("%d/%s/%s//%s//%s/", p.PortID, p.State.State, p.Protocol, service, version)
+		}
+		fmt.Fprintf(&b, "%s\tPorts: %s\n", prefix, strings.Join(parts, ", "))
+	}
+	fin := r.RunStats.Finished
+	fmt.Fprintf(&b, "# Nmap done at %s -- %d IP %s (%d %s up) scanned in %.2f seconds\n",
+		fin.TimeStr, r.RunStats.Hosts.Total, plural(r.RunStats.Hosts.Total, "address", "addresses"),
+		r.RunStats.Hosts.Up, plural(r.RunStats.Hosts.Up, "host", "hosts"), fin.Elapsed)
+	return b.String()
+}
+
+// mergeHost folds other into h.
+func mergeHost(h *Host, other Host) {
+	if h.Status.State != "up" && other.Status.State == "up" {
+		h.Status = other.Status
+	}
+	if other.StartTime > 0 && (h.StartTime == 0 || other.StartTime < h.StartTime) {
+		h.StartTime = other.StartTime
+	}
+	if other.EndTime > h.EndTime {
+		h.EndTime = other.EndTime
+	}
+	for _, a := range other.Addresses {
+		if !containsAddress(h.Addresses, a) {
+			h.Addresses = append(h.Addresses, a)
+		}
+	}
+	for _, n := range other.Hostnames {
+		if !containsHostname(h.Hostnames, n) {
+			h.Hostnames = append(h.Hostnames, n)
+		}
+	}
+	if h.OS == nil {
+		h.OS = other.OS
+	}
+	if h.Times == nil {
+		h.Times = other.Times
+	}
+	h.HostScripts = mergeScripts(h.HostScripts, other.HostScripts)
+	mergePorts(h, other.Ports)
+}
+
+// mergePorts folds ports into h. When the same port appears twice, an open
+// state wins over any other, and a service with version details wins over
+// one without.
+func mergePorts(h *Host, ports []Port) {
+	for _, p := range ports {
+		i := -1
+		for j := range h.Ports {
+			if h.Ports[j].Protocol == p.Protocol && h.Ports[j].PortID == p.PortID {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			h.Ports = append(h.Ports, p)
+			continue
+		}
+		existing := &h.Ports[i]
+		if existing.State.State != "open" && p.State.State == "open" {
+			existing.State = p.State
+		}
+		if existing.Service == nil || (existing.Service.Product == "" && p.Service != nil && p.Service.Product != "") {
+			if p.Service != nil {
+				existing.Service = p.Service
+			}
+		}
+		existing.Scripts = mergeScripts(existing.Scripts, p.Scripts)
+	}
+}
+
+func mergeScripts(scripts, more []Script) []Script {
+	for _, s := range more {
+		dup := false
+		for _, e := range scripts {
+			if e.ID == s.ID {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			scripts = append(scripts, s)
+		}
+	}
+	return scripts
+}
+
+// hostKey identifies a host across reports: its IP address, or its first
+// address or host name when it has none.
+func hostKey(h Host) string {
+	if addr := hostAddress(h); addr != "" {
+		return addr
+	}
+	if len(h.Hostnames) > 0 {
+		return h.Hostnames[0].Name
+	}
+	return ""
+}
+
+func hostAddress(h Host) string {
+	for _, a := range h.Addresses {
+		if a.AddrType == "ipv4" || a.AddrType == "ipv6" {
+			return a.Addr
+		}
+	}
+	if len(h.Addresses) > 0 {
+		return h.Addresses[0].Addr
+	}
+	return ""
+}
+
+// lessHostKey orders IP addresses numerically, before host names.
+func lessHostKey(a, b string) bool {
+	ia, errA := netip.ParseAddr(a)
+	ib, errB := netip.ParseAddr(b)
+	switch {
+	case errA == nil && errB == nil:
+		return ia.Less(ib)
+	case errA == nil:
+		return true
+	case errB == nil:
+		return false
+	default:
+		return a < b
+	}
+}
+
+// targetStem is the scan target a chunk report belongs to, taken from its
+// artifact file name.
+func targetStem(key string) string {
+	base := path.Base(key)
+	base = strings.TrimSuffix(base, path.Ext(base))
+	if i := strings.Index(base, "_chunk"); i > 0 {
+		return base[:i]
+	}
+	if i := strings.LastIndex(base, "_"); i > 0 {
+		if _, err := strconv.ParseInt(base[i+1:], 10, 64); err == nil {
+			return base[:i]
+		}
+	}
+	return base
+}
+
+func containsAddress(addrs []Address, a Address) bool {
+	for _, e := range addrs {
+		if e.Addr == a.Addr && e.AddrType == a.AddrType {
+			return true
+		}
+	}
+	return false
+}
+
+func containsHostname(names []Hostname, n Hostname) bool {
+	for _, e := range names {
+		if e.Name == n.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
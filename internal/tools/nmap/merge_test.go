@@ -0,0 +1,191 @@
+package nmap
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const chunk0 = `<?xml version="1.0"?>
+<nmaprun scanner="nmap" args="nmap -p 1-1000 10.0.0.1" start="1700000000" startstr="Tue Nov 14 22:13:20 2023" version="7.94" xmloutputversion="1.05">
+<scaninfo type="syn" protocol="tcp" numservices="1000" services="1-1000"/>
+<host starttime="1700000001" endtime="1700000010"><status state="up" reason="syn-ack"/>
+<address addr="10.0.0.1" addrtype="ipv4"/>
+<hostnames><hostname name="web.example.com" type="PTR"/></hostnames>
+<ports>
+<port protocol="tcp" portid="22"><state state="open" reason="syn-ack"/><service name="ssh" product="OpenSSH" version="8.9p1"/></port>
+<port protocol="tcp" portid="80"><state state="open" reason="syn-ack"/><service name="http"/><script id="http-title" output="Welcome"><elem key="title">Welcome</elem></script></port>
+</ports>
+</host>
+<runstats><finished time="1700000010" timestr="Tue Nov 14 22:13:30 2023" elapsed="10.00" exit="success"/><hosts up="1" down="0" total="1"/></runstats>
+</nmaprun>`
+
+const chunk1 = `<?xml version="1.0"?>
+<nmaprun scanner="nmap" args="nmap -p 1001-2000 10.0.0.1" start="1700000005" startstr="Tue Nov 14 22:13:25 2023" version="7.94" xmloutputversion="1.05">
+<scaninfo type="syn" protocol="tcp" numservices="1000" services="1001-2000"/>
+<host starttime="1700000006" endtime="1700000030"><status state="up" reason="syn-ack"/>
+<address addr="10.0.0.1" addrtype="ipv4"/>
+<ports>
+<port protocol="tcp" portid="80"><state state="open" reason="syn-ack"/><service name="http" product="nginx"/><script id="http-title" output="Welcome"/><script id="http-server-header" output="nginx"/></port>
+<port protocol="tcp" portid="1433"><state state="filtered" reason="no-response"/></port>
+</ports>
+</host>
+<host><status state="down" reason="no-response"/><address addr="10.0.0.2" addrtype="ipv4"/></host>
+<runstats><finished time="1700000030" timestr="Tue Nov 14 22:13:50 2023" elapsed="25.00" exit="success"/><hosts up="1" down="1" total="2"/></runstats>
+</nmaprun>`
+
+func mustParseRun(t *testing.T, data string) *Run {
+	t.Helper()
+	run, err := ParseRun([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseRun: %v", err)
+	}
+	return run
+}
+
+func TestMergeRunsDedupesHostsPortsAndScripts(t *testing.T) {
+	merged := MergeRuns([]*Run{mustParseRun(t, chunk0), mustParseRun(t, chunk1)})
+
+	if len(merged.Hosts) != 2 {
+		t.Fatalf("hosts = %d, want 2", len(merged.Hosts))
+	}
+	h := merged.Hosts[0]
+	if hostAddress(h) != "10.0.0.1" {
+		t.Fatalf("first host = %s, want 10.0.0.1", hostAddress(h))
+	}
+	var ports []int
+	for _, p := range h.Ports {
+		ports = append(ports, p.PortID)
+	}
+	if len(ports) != 3 || ports[0] != 22 || ports[1] != 80 || ports[2] != 1433 {
+		t.Errorf("ports = %v, want [22 80 1433]", ports)
+	}
+	http := h.Ports[1]
+	if len(http.Scripts) != 2 {
+		t.Errorf("port 80 scripts = %d, want 2 (deduped)", len(http.Scripts))
+	}
+	if http.Service == nil || http.Service.Product != "nginx" {
+		t.Errorf("port 80 service = %+v, want the detailed one", http.Service)
+	}
+	if h.StartTime != 1700000001 || h.EndTime != 1700000030 {
+		t.Errorf("host times = %d-%d, want 1700000001-1700000030", h.StartTime, h.EndTime)
+	}
+	if len(h.Hostnames) != 1 || h.Hostnames[0].Name != "web.example.com" {
+		t.Errorf("hostnames = %+v", h.Hostnames)
+	}
+
+	if merged.Start != 1700000000 || merged.RunStats.Finished.Time != 1700000030 {
+		t.Errorf("run = %d-%d, want 1700000000-1700000030", merged.Start, merged.RunStats.Finished.Time)
+	}
+	if merged.RunStats.Finished.Elapsed != 30 {
+		t.Errorf("elapsed = %v, want 30", merged.RunStats.Finished.Elapsed)
+	}
+	if got := merged.RunStats.Hosts; got.Up != 1 || got.Down != 1 || got.Total != 2 {
+		t.Errorf("host stats = %+v, want 1 up, 1 down", got)
+	}
+	if len(merged.ScanInfo) != 1 || merged.ScanInfo[0].Services != "1-2000" || merged.ScanInfo[0].NumServices != 2000 {
+		t.Errorf("scaninfo = %+v, want one syn/tcp over 1-2000", merged.ScanInfo)
+	}
+}
+
+func TestMergeRunsOpenStateWins(t *testing.T) {
+	closed := strings.Replace(chunk0, `portid="22"><state state="open"`, `portid="22"><state state="closed"`, 1)
+	merged := MergeRuns([]*Run{mustParseRun(t, closed), mustParseRun(t, chunk0)})
+	if got := merged.Hosts[0].Ports[0].State.State; got != "open" {
+		t.Errorf("port 22 state = %q, want open", got)
+	}
+}
+
+func TestRunXMLIsValidNmapRun(t *testing.T) {
+	merged := MergeRuns([]*Run{mustParseRun(t, chunk0), mustParseRun(t, chunk1)})
+	data, err := merged.XML()
+	if err != nil {
+		t.Fatalf("XML: %v", err)
+	}
+	var back Run
+	if err := xml.Unmarshal(data, &back); err != nil {
+		t.Fatalf("merged XML does not parse: %v", err)
+	}
+	if len(back.Hosts) != 2 || len(back.Hosts[0].Ports) != 3 {
+		t.Errorf("round trip lost hosts or ports: %+v", back.Hosts)
+	}
+	if !strings.Contains(string(data), `<elem key="title">Welcome</elem>`) {
+		t.Error("structured script output was not kept")
+	}
+	hosts, err := UpHosts(data)
+	if err != nil || len(hosts) != 1 || hosts[0] != "10.0.0.1" {
+		t.Errorf("UpHosts = %v, %v", hosts, err)
+	}
+}
+
+func TestRunGnmap(t *testing.T) {
+	merged := MergeRuns([]*Run{mustParseRun(t, chunk0), mustParseRun(t, chunk1)})
+	out := merged.Gnmap()
+	for _, want := range []string{
+		"Host: 10.0.0.1 (web.example.com)\tStatus: Up\n",
+		"Host: 10.0.0.1 (web.example.com)\tPorts: 22/open/tcp//ssh//OpenSSH 8.9p1/, 80/open/tcp//http//nginx/, 1433/filtered/tcp/////\n",
+		"Host: 10.0.0.2 ()\tStatus: Down\n",
+		"# Nmap done at Tue Nov 14 22:13:50 2023 -- 2 IP addresses (1 host up) scanned in 30.00 seconds\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("gnmap output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunEncodeJSON(t *testing.T) {
+	merged := MergeRuns([]*Run{mustParseRun(t, chunk0)})
+	data, err := merged.Encode(MergeFormatJSON)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var back Run
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if len(back.Hosts) != 1 || back.Hosts[0].Ports[0].Service.Name != "ssh" {
+		t.Errorf("json round trip = %+v", back.Hosts)
+	}
+	if _, err := merged.Encode("html"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestMergeReportsGroupsByTarget(t *testing.T) {
+	reports := map[string][]byte{
+		"10.0.0.1_line1/10.0.0.1_chunk0_of_2_1700000000.xml": []byte(chunk0),
+		"10.0.0.1_line1/10.0.0.1_chunk1_of_2_1700000005.xml": []byte(chunk1),
+		"other.example.com_1700000000.xml":                   []byte(strings.ReplaceAll(chunk0, "10.0.0.1", "10.0.0.9")),
+		"broken_chunk0_of_1_1.xml":                           []byte("<nmaprun"),
+	}
+	job, perTarget, skipped := MergeReports(reports)
+	if len(skipped) != 1 {
+		t.Errorf("skipped = %v, want the broken report", skipped)
+	}
+	if len(perTarget) != 2 || perTarget["10.0.0.1"] == nil || perTarget["other.example.com"] == nil {
+		t.Fatalf("per-target groups = %v", perTarget)
+	}
+	if len(perTarget["10.0.0.1"].Hosts) != 2 {
+		t.Errorf("10.0.0.1 report hosts = %d, want 2", len(perTarget["10.0.0.1"].Hosts))
+	}
+	if len(job.Hosts) != 3 {
+		t.Errorf("job report hosts = %d, want 3", len(job.Hosts))
+	}
+
+	dir := t.TempDir()
+	files, err := WriteMerged(dir, job, perTarget, MergeFormats)
+	if err != nil {
+		t.Fatalf("WriteMerged: %v", err)
+	}
+	if len(files) != 9 {
+		t.Errorf("wrote %d files, want 9", len(files))
+	}
+	for _, name := range []string{"job.xml", "job.gnmap", "job.json", filepath.Join("targets", "10.0.0.1.xml")} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
+	}
+}
@@ -10,6 +10,9 @@ type ScanTask struct {
 	GroupID     string `json:"group_id,omitempty"`
 	ChunkIdx    int    `json:"chunk_idx,omitempty"`
 	TotalChunks int    `json:"total_chunks,omitempty"`
+	// PortOptions, on a host discovery task, are the options used to port
+	// scan the hosts it finds.
+	PortOptions string `json:"port_options,omitempty"`
 }
 
 // ScanResult represents the result of a scan
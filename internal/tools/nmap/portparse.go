@@ -8,66 +8,226 @@ import (
 )
 
 // ParsePortSpec parses an nmap-style port specification string into a sorted
-// slice of individual port numbers. Supported formats:
+// slice of individual port numbers, across all protocols. Supported formats:
 //   - Single port: "80"
 //   - Comma-separated: "22,80,443"
-//   - Range: "1-1024"
+//   - Range: "1-1024", open-ended "1024-", or "-" for every port
 //   - Mixed: "22,80,100-200,443,8000-9000"
+//   - Service names, with * and ? wildcards: "http,ssh", "http*"
+//   - Protocol prefixes: "T:80,443,U:53" (see ParsePorts)
 func ParsePortSpec(spec string) ([]int, error) {
+	set, err := ParsePorts(spec)
+	if err != nil {
+		return nil, err
+	}
+	return set.All(), nil
+}
+
+// PortSet is a parsed nmap port specification. Any holds ports given
+// without a protocol prefix, which nmap scans on every protocol its scan
+// types cover; TCP, UDP and SCTP hold ports given after T:, U: and S:.
+// Each list is sorted and free of duplicates.
+type PortSet struct {
+	Any  []int
+	TCP  []int
+	UDP  []int
+	SCTP []int
+}
+
+// ParsePorts parses a port specification that may use protocol prefixes.
+// A prefix applies to the ports after it up to the next prefix, so
+// "22,T:80,443,U:53" is port 22 on any protocol, TCP 80 and 443, and UDP
+// 53. Service names resolve through nmap's services table to the
+// protocols they are listed for.
+func ParsePorts(spec string) (PortSet, error) {
 	spec = strings.TrimSpace(spec)
 	if spec == "" {
-		return nil, fmt.Errorf("empty port specification")
+		return PortSet{}, fmt.Errorf("empty port specification")
 	}
 
-	seen := make(map[int]bool)
-	parts := strings.Split(spec, ",")
+	seen := make(map[string]map[int]bool)
+	add := func(proto string, ports ...int) {
+		if seen[proto] == nil {
+			seen[proto] = make(map[int]bool)
+		}
+		for _, p := range ports {
+			seen[proto][p] = true
+		}
+	}
 
-	for _, part := range parts {
+	proto := ""
+	for _, part := range strings.Split(spec, ",") {
 		part = strings.TrimSpace(part)
+		if p, rest, ok := cutProtoPrefix(part); ok {
+			proto, part = p, strings.TrimSpace(rest)
+		}
 		if part == "" {
-			return nil, fmt.Errorf("invalid port specification: empty segment in %q", spec)
+			return PortSet{}, fmt.Errorf("invalid port specification: empty segment in %q", spec)
 		}
 
+		if !isNumericPart(part) {
+			found := servicePorts(part, proto)
+			if len(found) == 0 {
+				return PortSet{}, fmt.Errorf("invalid port %q: unknown service", part)
+			}
+			for p, ports := range found {
+				add(p, ports...)
+			}
+			continue
+		}
+
+		if part == "-" {
+			part = "1-65535"
+		}
 		if strings.Contains(part, "-") {
 			bounds := strings.SplitN(part, "-", 2)
 			start, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
 			if err != nil {
-				return nil, fmt.Errorf("invalid port %q: %w", bounds[0], err)
+				return PortSet{}, fmt.Errorf("invalid port %q: %w", bounds[0], err)
 			}
-			end, err := strconv.Atoi(strings.TrimSpace(bounds[1]))
+			endStr := strings.TrimSpace(bounds[1])
+			if endStr == "" {
+				endStr = "65535"
+			}
+			end, err := strconv.Atoi(endStr)
 			if err != nil {
-				return nil, fmt.Errorf("invalid port %q: %w", bounds[1], err)
+				return PortSet{}, fmt.Errorf("invalid port %q: %w", bounds[1], err)
 			}
 			if err := validatePort(start); err != nil {
-				return nil, err
+				return PortSet{}, err
 			}
 			if err := validatePort(end); err != nil {
-				return nil, err
+				return PortSet{}, err
 			}
 			if start > end {
-				return nil, fmt.Errorf("invalid port range: start %d > end %d", start, end)
+				return PortSet{}, fmt.Errorf("invalid port range: start %d > end %d", start, end)
 			}
 			for p := start; p <= end; p++ {
-				seen[p] = true
+				add(proto, p)
 			}
 		} else {
 			port, err := strconv.Atoi(part)
 			if err != nil {
-				return nil, fmt.Errorf("invalid port %q: %w", part, err)
+				return PortSet{}, fmt.Errorf("invalid port %q: %w", part, err)
 			}
 			if err := validatePort(port); err != nil {
-				return nil, err
+				return PortSet{}, err
 			}
-			seen[port] = true
+			add(proto, port)
 		}
 	}
 
-	ports := make([]int, 0, len(seen))
-	for p := range seen {
-		ports = append(ports, p)
+	return PortSet{
+		Any:  sortedPorts(seen[""]),
+		TCP:  sortedPorts(seen[ProtoTCP]),
+		UDP:  sortedPorts(seen[ProtoUDP]),
+		SCTP: sortedPorts(seen[ProtoSCTP]),
+	}, nil
+}
+
+// Len returns the number of (protocol, port) entries in the set.
+func (s PortSet) Len() int {
+	return len(s.Any) + len(s.TCP) + len(s.UDP) + len(s.SCTP)
+}
+
+// All returns every port in the set regardless of protocol, sorted.
+func (s PortSet) All() []int {
+	seen := make(map[int]bool, s.Len())
+	for _, list := range [][]int{s.Any, s.TCP, s.UDP, s.SCTP} {
+		for _, p := range list {
+			seen[p] = true
+		}
+	}
+	return sortedPorts(seen)
+}
+
+// String renders the set as a port specification nmap accepts, with
+// unprefixed ports first: "22,T:80,443,U:53".
+func (s PortSet) String() string {
+	var parts []string
+	if len(s.Any) > 0 {
+		parts = append(parts, FormatPortSpec(s.Any))
+	}
+	if len(s.TCP) > 0 {
+		parts = append(parts, "T:"+FormatPortSpec(s.TCP))
+	}
+	if len(s.UDP) > 0 {
+		parts = append(parts, "U:"+FormatPortSpec(s.UDP))
+	}
+	if len(s.SCTP) > 0 {
+		parts = append(parts, "S:"+FormatPortSpec(s.SCTP))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Without returns the set minus x. An unprefixed port in x is removed from
+// every protocol; a prefixed one only from its own protocol's list.
+func (s PortSet) Without(x PortSet) PortSet {
+	drop := func(list []int, excluded ...[]int) []int {
+		skip := make(map[int]bool)
+		for _, e := range excluded {
+			for _, p := range e {
+				skip[p] = true
+			}
+		}
+		var kept []int
+		for _, p := range list {
+			if !skip[p] {
+				kept = append(kept, p)
+			}
+		}
+		return kept
+	}
+	return PortSet{
+		Any:  drop(s.Any, x.Any),
+		TCP:  drop(s.TCP, x.Any, x.TCP),
+		UDP:  drop(s.UDP, x.Any, x.UDP),
+		SCTP: drop(s.SCTP, x.Any, x.SCTP),
 	}
-	sort.Ints(ports)
-	return ports, nil
+}
+
+// Split divides the set into at most n roughly equal chunks. Ports keep
+// their protocol, so a UDP port never lands in a chunk as TCP.
+func (s PortSet) Split(n int) []PortSet {
+	type entry struct {
+		proto string
+		port  int
+	}
+	var entries []entry
+	for _, l := range []struct {
+		proto string
+		ports []int
+	}{{"", s.Any}, {ProtoTCP, s.TCP}, {ProtoUDP, s.UDP}, {ProtoSCTP, s.SCTP}} {
+		for _, p := range l.ports {
+			entries = append(entries, entry{l.proto, p})
+		}
+	}
+	idx := make([]int, len(entries))
+	for i := range idx {
+		idx[i] = i
+	}
+	var chunks []PortSet
+	for _, group := range SplitPorts(idx, n) {
+		if len(group) == 0 {
+			continue
+		}
+		var c PortSet
+		for _, i := range group {
+			e := entries[i]
+			switch e.proto {
+			case ProtoTCP:
+				c.TCP = append(c.TCP, e.port)
+			case ProtoUDP:
+				c.UDP = append(c.UDP, e.port)
+			case ProtoSCTP:
+				c.SCTP = append(c.SCTP, e.port)
+			default:
+				c.Any = append(c.Any, e.port)
+			}
+		}
+		chunks = append(chunks, c)
+	}
+	return chunks
 }
 
 // SplitPorts divides a sorted slice of ports into n roughly equal chunks.
@@ -152,6 +312,217 @@ func ExtractPortFlag(options string) (portSpec, remainingOptions string, found b
 	return
 }
 
+// ExtractPorts resolves the ports an nmap options string scans: -p (in any
+// form ParsePorts accepts), --top-ports N from nmap's services table for
+// the protocols the scan types cover, and --exclude-ports. It returns the
+// ports and the options with -p and --top-ports removed. --exclude-ports
+// stays in the options so nmap applies it exactly; it is also subtracted
+// from the returned set so chunks split evenly. found is false when the
+// options select no ports of their own.
+func ExtractPorts(options string) (ports PortSet, remaining string, found bool, err error) {
+	fields := strings.Fields(options)
+	var spec, top, exclude string
+	var rest []string
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		switch {
+		case f == "-p" && i+1 < len(fields):
+			spec = fields[i+1]
+			i++
+		case strings.HasPrefix(f, "-p") && len(f) > 2:
+			spec = f[2:]
+		case f == "--top-ports" && i+1 < len(fields):
+			top = fields[i+1]
+			i++
+		case strings.HasPrefix(f, "--top-ports="):
+			top = strings.TrimPrefix(f, "--top-ports=")
+		case f == "--exclude-ports" && i+1 < len(fields):
+			exclude = fields[i+1]
+			rest = append(rest, f, fields[i+1])
+			i++
+		case strings.HasPrefix(f, "--exclude-ports="):
+			exclude = strings.TrimPrefix(f, "--exclude-ports=")
+			rest = append(rest, f)
+		default:
+			rest = append(rest, f)
+		}
+	}
+	remaining = strings.Join(rest, " ")
+	if spec == "" && top == "" {
+		return PortSet{}, options, false, nil
+	}
+
+	if spec != "" {
+		if ports, err = ParsePorts(spec); err != nil {
+			return PortSet{}, "", false, err
+		}
+	}
+	if top != "" {
+		n, convErr := strconv.Atoi(top)
+		if convErr != nil || n <= 0 {
+			return PortSet{}, "", false, fmt.Errorf("invalid --top-ports %q: want a positive number", top)
+		}
+		// With -p as well, nmap picks the top ports from within the -p list.
+		var within *PortSet
+		if spec != "" {
+			expanded := ports.expand()
+			within = &expanded
+		}
+		if ports, err = topPortSet(scanProtocols(remaining), n, within); err != nil {
+			return PortSet{}, "", false, err
+		}
+	}
+	if exclude != "" {
+		x, exErr := ParsePorts(exclude)
+		if exErr != nil {
+			return PortSet{}, "", false, fmt.Errorf("--exclude-ports: %w", exErr)
+		}
+		ports = ports.Without(x)
+	}
+	if ports.Len() == 0 {
+		return PortSet{}, "", false, fmt.Errorf("port options %q select no ports", options)
+	}
+	return ports, remaining, true, nil
+}
+
+// expand copies unprefixed ports into every protocol list.
+func (s PortSet) expand() PortSet {
+	merge := func(a, b []int) []int {
+		seen := make(map[int]bool, len(a)+len(b))
+		for _, p := range append(append([]int{}, a...), b...) {
+			seen[p] = true
+		}
+		return sortedPorts(seen)
+	}
+	return PortSet{TCP: merge(s.Any, s.TCP), UDP: merge(s.Any, s.UDP), SCTP: merge(s.Any, s.SCTP)}
+}
+
+// topPortSet returns the n most common ports of each protocol, limited to
+// the ports within lists for that protocol when within is set.
+func topPortSet(protos []string, n int, within *PortSet) (PortSet, error) {
+	allowed := func(ports []int) map[int]bool {
+		if within == nil {
+			return nil
+		}
+		m := make(map[int]bool, len(ports))
+		for _, p := range ports {
+			m[p] = true
+		}
+		return m
+	}
+	var s PortSet
+	for _, p := range protos {
+		var dst *[]int
+		switch p {
+		case ProtoTCP:
+			dst = &s.TCP
+		case ProtoUDP:
+			dst = &s.UDP
+		case ProtoSCTP:
+			dst = &s.SCTP
+		default:
+			continue
+		}
+		ports, err := topPortsWithin(p, n, allowed(within.protoPorts(p)))
+		if err != nil {
+			return PortSet{}, err
+		}
+		*dst = ports
+	}
+	return s, nil
+}
+
+// protoPorts returns the ports listed for proto; nil on a nil set.
+func (s *PortSet) protoPorts(proto string) []int {
+	if s == nil {
+		return nil
+	}
+	switch proto {
+	case ProtoTCP:
+		return s.TCP
+	case ProtoUDP:
+		return s.UDP
+	case ProtoSCTP:
+		return s.SCTP
+	}
+	return s.Any
+}
+
+// scanProtocols returns the protocols the -s scan types in options cover.
+// nmap scans TCP when no scan type is given.
+func scanProtocols(options string) []string {
+	if protos := explicitScanProtocols(options); len(protos) > 0 {
+		return protos
+	}
+	return []string{ProtoTCP}
+}
+
+// explicitScanProtocols returns the protocols the -s scan types in options
+// cover, or nil when options give no scan type.
+func explicitScanProtocols(options string) []string {
+	seen := make(map[string]bool)
+	for _, f := range strings.Fields(options) {
+		if !strings.HasPrefix(f, "-s") || strings.HasPrefix(f, "--") {
+			continue
+		}
+		for _, c := range f[2:] {
+			switch c {
+			case 'S', 'T', 'A', 'W', 'M', 'N', 'F', 'X':
+				seen[ProtoTCP] = true
+			case 'U':
+				seen[ProtoUDP] = true
+			case 'Y', 'Z':
+				seen[ProtoSCTP] = true
+			}
+		}
+	}
+	var protos []string
+	for _, p := range []string{ProtoTCP, ProtoUDP, ProtoSCTP} {
+		if seen[p] {
+			protos = append(protos, p)
+		}
+	}
+	return protos
+}
+
+func cutProtoPrefix(part string) (proto, rest string, ok bool) {
+	if len(part) < 2 || part[1] != ':' {
+		return "", part, false
+	}
+	switch part[0] {
+	case 'T', 't':
+		return ProtoTCP, part[2:], true
+	case 'U', 'u':
+		return ProtoUDP, part[2:], true
+	case 'S', 's':
+		return ProtoSCTP, part[2:], true
+	}
+	return "", part, false
+}
+
+// isNumericPart reports whether part is a port or range rather than a
+// service name.
+func isNumericPart(part string) bool {
+	for _, c := range part {
+		if (c < '0' || c > '9') && c != '-' && c != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedPorts(set map[int]bool) []int {
+	if len(set) == 0 {
+		return nil
+	}
+	ports := make([]int, 0, len(set))
+	for p := range set {
+		ports = append(ports, p)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
 func validatePort(port int) error {
 	if port < 1 || port > 65535 {
 		return fmt.Errorf("port %d out of range (1-65535)", port)
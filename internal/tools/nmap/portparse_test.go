@@ -424,3 +424,115 @@ func makeRange(start, end int) []int {
 	}
 	return r
 }
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		spec string
+		want PortSet
+	}{
+		{"22,80", PortSet{Any: []int{22, 80}}},
+		{"T:80,443,U:53,161", PortSet{TCP: []int{80, 443}, UDP: []int{53, 161}}},
+		{"22,T:80,S:2905", PortSet{Any: []int{22}, TCP: []int{80}, SCTP: []int{2905}}},
+		{"http,ssh", PortSet{TCP: []int{22, 80, 8008}, UDP: []int{80}}},
+		{"T:http,U:snmp", PortSet{TCP: []int{80, 8008}, UDP: []int{161}}},
+		{"65530-", PortSet{Any: []int{65530, 65531, 65532, 65533, 65534, 65535}}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePorts(tt.spec)
+		if err != nil {
+			t.Errorf("ParsePorts(%q): %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePorts(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+
+	all, err := ParsePorts("-")
+	if err != nil || all.Len() != 65535 {
+		t.Errorf("ParsePorts(-) = %d ports, %v", all.Len(), err)
+	}
+	for _, bad := range []string{"nosuchservice", "U:", "T:0", "U:100-50"} {
+		if _, err := ParsePorts(bad); err == nil {
+			t.Errorf("ParsePorts(%q) should fail", bad)
+		}
+	}
+}
+
+func TestPortSetStringAndSplit(t *testing.T) {
+	set, err := ParsePorts("22,T:80,443,U:53,161")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := set.String(); got != "22,T:80,443,U:53,161" {
+		t.Errorf("String() = %q", got)
+	}
+
+	chunks := set.Split(2)
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	if got := chunks[0].String(); got != "22,T:80,443" {
+		t.Errorf("chunk 0 = %q", got)
+	}
+	if got := chunks[1].String(); got != "U:53,161" {
+		t.Errorf("chunk 1 = %q, UDP ports must stay UDP", got)
+	}
+	if got := len(set.Split(10)); got != 5 {
+		t.Errorf("Split(10) gave %d chunks, want one per port", got)
+	}
+}
+
+func TestPortSetWithout(t *testing.T) {
+	set := PortSet{Any: []int{22, 80}, TCP: []int{443, 8080}, UDP: []int{53, 80}}
+	got := set.Without(PortSet{Any: []int{80}, TCP: []int{8080}, UDP: []int{22}})
+	want := PortSet{Any: []int{22}, TCP: []int{443}, UDP: []int{53}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Without = %+v, want %+v", got, want)
+	}
+}
+
+func TestExtractPorts(t *testing.T) {
+	useServices(t, testServices)
+	tests := []struct {
+		name          string
+		options       string
+		wantPorts     string
+		wantRemaining string
+		wantFound     bool
+	}{
+		{"plain -p", "-sS -p 22,80 -T4", "22,80", "-sS -T4", true},
+		{"-p- is every port", "-sS -p-", "1-65535", "-sS", true},
+		{"protocol prefixes", "-sS -sU -p T:80,U:53", "T:80,U:53", "-sS -sU", true},
+		{"top ports tcp", "-sS --top-ports 3", "T:23,80,443", "-sS", true},
+		{"top ports udp", "-sU --top-ports=2", "U:161,631", "-sU", true},
+		{"top ports both", "-sSU --top-ports 1", "T:80,U:631", "-sSU", true},
+		{"top ports default tcp", "-sV --top-ports 1", "T:80", "-sV", true},
+		{"top ports within -p", "-p 1-100 --top-ports 3", "T:21,23,80", "", true},
+		{"exclude ports", "-p 20-25 --exclude-ports 21,23", "20,22,24-25", "--exclude-ports 21,23", true},
+		{"no ports", "-sS -T4", "", "-sS -T4", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ports, remaining, found, err := ExtractPorts(tt.options)
+			if err != nil {
+				t.Fatalf("ExtractPorts(%q): %v", tt.options, err)
+			}
+			if found != tt.wantFound {
+				t.Errorf("found = %v, want %v", found, tt.wantFound)
+			}
+			if got := ports.String(); got != tt.wantPorts {
+				t.Errorf("ports = %q, want %q", got, tt.wantPorts)
+			}
+			if remaining != tt.wantRemaining {
+				t.Errorf("remaining = %q, want %q", remaining, tt.wantRemaining)
+			}
+		})
+	}
+
+	for _, bad := range []string{"--top-ports 0", "--top-ports many", "--top-ports 8", "-p 1-22 --top-ports 3", "-p 80 --exclude-ports 80", "-p nosuchservice"} {
+		if _, _, _, err := ExtractPorts(bad); err == nil {
+			t.Errorf("ExtractPorts(%q) should fail", bad)
+		}
+	}
+}
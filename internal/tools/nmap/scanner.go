@@ -90,7 +90,8 @@ func (s *Scanner) ParseTargets(content string, defaultOptions string) []ScanTask
 
 // ParseTargetsWithMode parses targets with support for port-splitting distribution.
 // In "target-only" mode (or empty), it delegates to ParseTargets.
-// In "target-ports" mode, each target's port range is split into portChunks chunks,
+// In "target-ports" mode, each target's ports (-p, --top-ports and
+// --exclude-ports, or every port) are split into portChunks chunks,
 // producing one ScanTask per chunk.
 func (s *Scanner) ParseTargetsWithMode(content, defaultOptions, mode string, portChunks int) []ScanTask {
 	if mode == "" || mode == "target-only" {
@@ -119,26 +120,23 @@ func (s *Scanner) ParseTargetsWithMode(content, defaultOptions, mode string, por
 			options = strings.Join(parts[1:], " ")
 		}
 
-		portSpec, remainingOptions, found := ExtractPortFlag(options)
-		if !found {
-			portSpec = "1-65535"
-			remainingOptions = options
-		}
-
-		ports, err := ParsePortSpec(portSpec)
+		ports, remainingOptions, found, err := ExtractPorts(options)
 		if err != nil {
 			if s.logger != nil {
-				s.logger.Error("Invalid port spec %q for target %s, emitting unsplit: %v", portSpec, target, err)
+				s.logger.Error("Invalid port options %q for target %s, emitting unsplit: %v", options, target, err)
 			}
 			tasks = append(tasks, ScanTask{Target: target, Options: options})
 			continue
 		}
+		if !found {
+			ports = PortSet{Any: allPorts()}
+		}
 
-		chunks := SplitPorts(ports, portChunks)
+		chunks := ports.Split(portChunks)
 		groupID := fmt.Sprintf("%s_line%d", target, lineNum)
 
 		for i, chunk := range chunks {
-			chunkOptions := strings.TrimSpace(remainingOptions + " -p " + FormatPortSpec(chunk))
+			chunkOptions := strings.TrimSpace(remainingOptions + " -p " + chunk.String())
 			tasks = append(tasks, ScanTask{
 				Target:      target,
 				Options:     chunkOptions,
@@ -156,3 +154,12 @@ func (s *Scanner) ParseTargetsWithMode(content, defaultOptions, mode string, por
 func (s *Scanner) FormatResult(result ScanResult) ([]byte, error) {
 	return json.Marshal(result)
 }
+
+// allPorts returns 1-65535, what target-ports mode scans without -p.
+func allPorts() []int {
+	ports := make([]int, 65535)
+	for i := range ports {
+		ports[i] = i + 1
+	}
+	return ports
+}
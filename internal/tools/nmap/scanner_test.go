@@ -180,6 +180,20 @@ func TestParseTargetsWithMode_TargetPorts_InvalidPortSpec(t *testing.T) {
 	}
 }
 
+func TestParseTargetsWithMode_TargetPorts_TopPortsBeyondTable(t *testing.T) {
+	useServices(t, testServices)
+	s := NewScanner(nil)
+
+	// The table lists 7 TCP ports, so nmap resolves --top-ports 100 itself.
+	tasks := s.ParseTargetsWithMode("example.com -sS --top-ports 100\n", "", "target-ports", 5)
+	if len(tasks) != 1 || tasks[0].GroupID != "" {
+		t.Fatalf("expected 1 unsplit task, got %+v", tasks)
+	}
+	if tasks[0].Options != "-sS --top-ports 100" {
+		t.Errorf("expected original options, got %q", tasks[0].Options)
+	}
+}
+
 func TestParseTargetsWithMode_TargetPorts_MoreChunksThanPorts(t *testing.T) {
 	s := NewScanner(nil)
 
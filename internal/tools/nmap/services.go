@@ -0,0 +1,146 @@
+package nmap
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Protocols a port can be scanned on.
+const (
+	ProtoTCP  = "tcp"
+	ProtoUDP  = "udp"
+	ProtoSCTP = "sctp"
+)
+
+//go:embed nmap-services
+var bundledServices string
+
+// bundledSource names the bundled table in errors.
+const bundledSource = "the bundled nmap-services"
+
+// service is one entry of a services table.
+type service struct {
+	name  string
+	port  int
+	proto string
+	freq  float64
+}
+
+// serviceTable is nmap's services table, most frequent first.
+type serviceTable struct {
+	entries []service
+	// source is the file the table was read from, or bundledSource.
+	source string
+}
+
+// services loads the table once: the installed nmap's when there is one,
+// since that is what a local nmap scans, else the bundled copy of the
+// worker image's. Tests replace it.
+var services = sync.OnceValue(func() serviceTable {
+	for _, p := range servicesPaths() {
+		data, err := os.ReadFile(p)
+		if err == nil {
+			if t := parseServices(string(data), p); len(t.entries) > 0 {
+				return t
+			}
+		}
+	}
+	return parseServices(bundledServices, bundledSource)
+})
+
+// servicesPaths lists where nmap looks for nmap-services, in its order.
+func servicesPaths() []string {
+	var paths []string
+	if dir := os.Getenv("NMAPDIR"); dir != "" {
+		paths = append(paths, filepath.Join(dir, "nmap-services"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".nmap", "nmap-services"))
+	}
+	return append(paths,
+		"/usr/local/share/nmap/nmap-services",
+		"/usr/share/nmap/nmap-services",
+		"/opt/homebrew/share/nmap/nmap-services",
+	)
+}
+
+// parseServices parses an nmap-services file, most frequent first.
+func parseServices(data, source string) serviceTable {
+	t := serviceTable{source: source}
+	for _, line := range strings.Split(data, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		portStr, proto, ok := strings.Cut(fields[1], "/")
+		port, err := strconv.Atoi(portStr)
+		if !ok || err != nil {
+			continue
+		}
+		var freq float64
+		if len(fields) > 2 {
+			if freq, err = strconv.ParseFloat(fields[2], 64); err != nil {
+				continue
+			}
+		}
+		t.entries = append(t.entries, service{name: fields[0], port: port, proto: proto, freq: freq})
+	}
+	sort.SliceStable(t.entries, func(i, j int) bool {
+		if t.entries[i].freq != t.entries[j].freq {
+			return t.entries[i].freq > t.entries[j].freq
+		}
+		return t.entries[i].port < t.entries[j].port
+	})
+	return t
+}
+
+// TopPorts returns the n most frequently open ports of proto, sorted by port
+// number. It fails when the services table lists fewer than n ports of
+// proto, rather than guessing at what nmap would scan.
+func TopPorts(proto string, n int) ([]int, error) {
+	return topPortsWithin(proto, n, nil)
+}
+
+// topPortsWithin is TopPorts restricted to the ports in within, or to every
+// port when within is nil.
+func topPortsWithin(proto string, n int, within map[int]bool) ([]int, error) {
+	t := services()
+	var ports []int
+	for _, s := range t.entries {
+		if len(ports) >= n {
+			break
+		}
+		if s.proto == proto && (within == nil || within[s.port]) {
+			ports = append(ports, s.port)
+		}
+	}
+	if len(ports) < n {
+		return nil, fmt.Errorf("--top-ports %d exceeds the %d %s ports %s lists", n, len(ports), proto, t.source)
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
+
+// servicePorts returns the ports whose service name matches pattern, which
+// may use nmap's * and ? wildcards. An empty proto matches every protocol.
+func servicePorts(pattern, proto string) map[string][]int {
+	pattern = strings.ToLower(pattern)
+	found := make(map[string][]int)
+	for _, s := range services().entries {
+		if proto != "" && s.proto != proto {
+			continue
+		}
+		if ok, _ := path.Match(pattern, strings.ToLower(s.name)); ok {
+			found[s.proto] = append(found[s.proto], s.port)
+		}
+	}
+	return found
+}
@@ -0,0 +1,95 @@
+package nmap
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testServices is an excerpt of nmap's nmap-services.
+const testServices = `# Fields in this file are: Service name, portnum/protocol, open-frequency, optional comments
+ftp	21/tcp	0.197667	# File Transfer [Control]
+ssh	22/tcp	0.182286	# Secure Shell Login
+telnet	23/tcp	0.221265
+smtp	25/tcp	0.131314	# Simple Mail Transfer
+domain	53/udp	0.213496	# Domain Name Server
+http	80/tcp	0.484143	# World Wide Web HTTP
+ntp	123/udp	0.330879	# Network Time Protocol
+netbios-ns	137/udp	0.365163	# NETBIOS Name Service
+snmp	161/udp	0.433467	# Simple Net Mgmt Proto
+https	443/tcp	0.208669	# secure http (SSL)
+ipp	631/udp	0.450281	# Internet Printing Protocol
+ms-wbt-server	3389/tcp	0.083904	# Microsoft Remote Display Protocol
+`
+
+// TestMain pins the bundled table, so tests do not depend on whether nmap
+// is installed.
+func TestMain(m *testing.M) {
+	services = func() serviceTable { return parseServices(bundledServices, bundledSource) }
+	os.Exit(m.Run())
+}
+
+// useServices makes data the installed nmap-services for one test.
+func useServices(t *testing.T, data string) {
+	t.Helper()
+	prev := services
+	services = func() serviceTable { return parseServices(data, "/usr/share/nmap/nmap-services") }
+	t.Cleanup(func() { services = prev })
+}
+
+func TestTopPorts(t *testing.T) {
+	useServices(t, testServices)
+	if got, err := TopPorts(ProtoTCP, 5); err != nil || !reflect.DeepEqual(got, []int{21, 22, 23, 80, 443}) {
+		t.Errorf("TopPorts(tcp, 5) = %v, %v", got, err)
+	}
+	if got, err := TopPorts(ProtoUDP, 3); err != nil || !reflect.DeepEqual(got, []int{137, 161, 631}) {
+		t.Errorf("TopPorts(udp, 3) = %v, %v", got, err)
+	}
+	// Asking for more ports than the table lists fails rather than
+	// silently scanning fewer.
+	if _, err := TopPorts(ProtoTCP, 8); err == nil || !strings.Contains(err.Error(), "exceeds the 7 tcp ports") {
+		t.Errorf("TopPorts(tcp, 8) error = %v", err)
+	}
+	if _, err := TopPorts(ProtoSCTP, 1); err == nil {
+		t.Error("TopPorts(sctp) should fail on a table without sctp ports")
+	}
+}
+
+func TestTopPortsFromBundledTable(t *testing.T) {
+	want := []int{21, 22, 23, 25, 80, 110, 139, 443, 445, 3389}
+	if got, err := TopPorts(ProtoTCP, 10); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("TopPorts(tcp, 10) = %v, %v; want %v", got, err, want)
+	}
+	if got, err := TopPorts(ProtoTCP, 1000); err != nil || len(got) != 1000 {
+		t.Errorf("TopPorts(tcp, 1000) = %d ports, %v", len(got), err)
+	}
+	if _, err := TopPorts(ProtoTCP, 1001); err == nil || !strings.Contains(err.Error(), bundledSource) {
+		t.Errorf("TopPorts(tcp, 1001) error = %v", err)
+	}
+}
+
+func TestServicesFromNmapDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/nmap-services", []byte(testServices), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NMAPDIR", dir)
+	if got := servicesPaths()[0]; got != dir+"/nmap-services" {
+		t.Errorf("first services path = %q, want NMAPDIR's", got)
+	}
+}
+
+func TestServicePorts(t *testing.T) {
+	got := servicePorts("ssh", "")
+	if !reflect.DeepEqual(got, map[string][]int{ProtoTCP: {22}}) {
+		t.Errorf("servicePorts(ssh) = %v", got)
+	}
+	got = servicePorts("domain", ProtoUDP)
+	if !reflect.DeepEqual(got, map[string][]int{ProtoUDP: {53}}) {
+		t.Errorf("servicePorts(domain, udp) = %v", got)
+	}
+	if got := servicePorts("HTTPS*", ProtoTCP); len(got[ProtoTCP]) != 2 {
+		t.Errorf("servicePorts(HTTPS*) = %v, want https and https-alt", got)
+	}
+}
@@ -0,0 +1,82 @@
+package nuclei
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// MergeStats summarises a merge of nuclei JSONL reports.
+type MergeStats struct {
+	Reports    int // reports read
+	Findings   int // distinct findings kept
+	Duplicates int // findings dropped as already seen
+	Invalid    int // lines that were not JSON findings
+}
+
+// finding holds the fields that identify a nuclei result.
+type finding struct {
+	TemplateID    string `json:"template-id"`
+	MatcherName   string `json:"matcher-name"`
+	ExtractorName string `json:"extractor-name"`
+	MatchedAt     string `json:"matched-at"`
+	Host          string `json:"host"`
+}
+
+// key identifies a finding across shards: the same template matching the
+// same location the same way is one finding however often it ran.
+func (f finding) key() string {
+	where := f.MatchedAt
+	if where == "" {
+		where = f.Host
+	}
+	return strings.Join([]string{f.TemplateID, f.MatcherName, f.ExtractorName, where}, "\x00")
+}
+
+// MergeFindings concatenates the JSONL reports, keyed by artifact key, in
+// key order and drops repeated findings, keeping the first of each. Shards
+// whose templates overlap report the same finding more than once.
+func MergeFindings(reports map[string][]byte) ([]byte, MergeStats) {
+	keys := make([]string, 0, len(reports))
+	for key := range reports {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var (
+		out   bytes.Buffer
+		stats MergeStats
+	)
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		stats.Reports++
+		sc := bufio.NewScanner(bytes.NewReader(reports[key]))
+		sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for sc.Scan() {
+			line := bytes.TrimSpace(sc.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var f finding
+			if err := json.Unmarshal(line, &f); err != nil || f.TemplateID == "" {
+				stats.Invalid++
+				continue
+			}
+			k := f.key()
+			if seen[k] {
+				stats.Duplicates++
+				continue
+			}
+			seen[k] = true
+			stats.Findings++
+			out.Write(line)
+			out.WriteByte('\n')
+		}
+		if sc.Err() != nil {
+			stats.Invalid++
+		}
+	}
+	return out.Bytes(), stats
+}
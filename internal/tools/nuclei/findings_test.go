@@ -0,0 +1,29 @@
+package nuclei
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeFindings(t *testing.T) {
+	reports := map[string][]byte{
+		"a_line1/a_chunk1_of_2_1.jsonl": []byte(`{"template-id":"cve-x","matched-at":"https://a/x","info":{"severity":"high"}}` + "\n" +
+			`{"template-id":"panel","matcher-name":"title","matched-at":"https://a/"}` + "\n"),
+		"a_line1/a_chunk0_of_2_1.jsonl": []byte(`{"template-id":"cve-x","matched-at":"https://a/x","shard":0}` + "\n\n" +
+			`{"template-id":"panel","matcher-name":"version","matched-at":"https://a/"}` + "\n" +
+			"garbage\n" +
+			`{"template-id":"dns","host":"a"}` + "\n"),
+	}
+	merged, stats := MergeFindings(reports)
+	if stats.Reports != 2 || stats.Findings != 4 || stats.Duplicates != 1 || stats.Invalid != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	lines := strings.Split(strings.TrimSpace(string(merged)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("merged = %q", merged)
+	}
+	// Reports merge in key order, so chunk 0's copy of cve-x is kept.
+	if !strings.Contains(lines[0], `"shard":0`) {
+		t.Errorf("first finding = %s", lines[0])
+	}
+}
@@ -0,0 +1,404 @@
+package nuclei
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"heph4estus/internal/worker"
+)
+
+// Ways a template set can be split into shards.
+const (
+	ShardByDir  = "dir"
+	ShardByTag  = "tag"
+	ShardByHash = "hash"
+)
+
+// MaxShardFilter caps the size of a shard's filter. The filter travels in
+// every task message, which SQS caps at 256 KiB, and reaches nuclei as one
+// argument, which Linux caps at 128 KiB.
+const MaxShardFilter = 64 << 10
+
+// ShardModes lists every shard mode.
+var ShardModes = []string{ShardByDir, ShardByTag, ShardByHash}
+
+// filterFlags are the nuclei options that select templates. A sharded job
+// sets them itself, so they cannot also come from the job options.
+var filterFlags = []string{"-t", "-templates", "-tags", "-id", "-template-id", "-w", "-workflows"}
+
+// Template is one nuclei template of a local template set.
+type Template struct {
+	ID   string
+	Path string // relative to the template set root, slash-separated
+	Tags []string
+}
+
+// Shard is one slice of the template set and the nuclei options that
+// select it.
+type Shard struct {
+	Templates int    `json:"templates"`
+	Filter    string `json:"filter"`
+}
+
+// ShardPlan is a template set split into shards. Every target task of a
+// sharded job runs once per shard.
+type ShardPlan struct {
+	By        string  `json:"by"`
+	Templates int     `json:"templates"`
+	Shards    []Shard `json:"shards"`
+	// Untagged counts templates without tags (tag mode only). No tag
+	// shard selects them, so a last shard excludes every tag instead.
+	Untagged int `json:"untagged,omitempty"`
+	// Overlap counts the extra template runs of templates whose tags land
+	// in more than one shard (tag mode only). Their findings are
+	// deduplicated when merged.
+	Overlap int `json:"overlap,omitempty"`
+}
+
+func (p *ShardPlan) String() string {
+	if p == nil {
+		return ""
+	}
+	s := fmt.Sprintf("%d templates in %d shards by %s", p.Templates, len(p.Shards), p.By)
+	if p.Untagged > 0 {
+		s += fmt.Sprintf(", %d untagged in a catch-all shard", p.Untagged)
+	}
+	if p.Overlap > 0 {
+		s += fmt.Sprintf(", %d overlapping runs", p.Overlap)
+	}
+	return s
+}
+
+// DefaultTemplatesDir returns where nuclei installs its template set.
+func DefaultTemplatesDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "nuclei-templates"
+	}
+	return filepath.Join(home, "nuclei-templates")
+}
+
+// LoadTemplates reads the id and tags of every template under root. Hidden
+// directories and YAML files without an id are skipped.
+func LoadTemplates(root string) ([]Template, error) {
+	var out []Template
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := strings.ToLower(filepath.Ext(p)); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", p, err)
+		}
+		var doc struct {
+			ID   string `yaml:"id"`
+			Info struct {
+				Tags yaml.Node `yaml:"tags"`
+			} `yaml:"info"`
+		}
+		if yaml.Unmarshal(data, &doc) != nil || doc.ID == "" {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		out = append(out, Template{ID: doc.ID, Path: filepath.ToSlash(rel), Tags: parseTags(&doc.Info.Tags)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading nuclei templates from %s: %w", root, err)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no nuclei templates found in %s", root)
+	}
+	return out, nil
+}
+
+// parseTags accepts tags as a comma-separated string or a list.
+func parseTags(n *yaml.Node) []string {
+	var raw []string
+	switch n.Kind {
+	case yaml.ScalarNode:
+		raw = strings.Split(n.Value, ",")
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			raw = append(raw, c.Value)
+		}
+	}
+	var tags []string
+	for _, t := range raw {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// PlanShards splits templates into at most n shards. Directory mode packs
+// directories (descending into the largest until they fit), tag mode packs
+// tags and hash mode buckets template IDs. Empty shards are dropped. Tag
+// mode adds a catch-all shard for untagged templates, which excludes every
+// tag with -etags; it also runs templates whose tags only the workers'
+// set has.
+//
+// Directory shards select their templates with -t paths relative to the
+// template set, which nuclei resolves against its installed templates.
+// When the set is shipped to workers as an asset bundle, byID selects them
+// with -id instead, so the filter narrows the bundle. Hash shards list
+// individual templates, so they need byID: paths planned from a local copy
+// need not exist in the set a worker has installed.
+//
+// A plan with a filter over MaxShardFilter is rejected; more shards make
+// each filter shorter.
+func PlanShards(templates []Template, n int, by string, byID bool) (*ShardPlan, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("template shard count must be positive")
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no templates to shard")
+	}
+	plan := &ShardPlan{By: by, Templates: len(templates)}
+	switch by {
+	case ShardByDir:
+		groups := dirGroups(templates, n)
+		for _, keys := range packGroups(groups, n) {
+			if byID {
+				plan.Shards = append(plan.Shards, idShard(templates, func(t Template) bool {
+					return slices.ContainsFunc(keys, func(key string) bool {
+						return t.Path == key || strings.HasPrefix(t.Path, key+"/")
+					})
+				}))
+				continue
+			}
+			plan.Shards = append(plan.Shards, groupShard(keys, groups, "-t"))
+		}
+	case ShardByTag:
+		groups := make(map[string]int)
+		for _, t := range templates {
+			if len(t.Tags) == 0 {
+				plan.Untagged++
+			}
+			for _, tag := range t.Tags {
+				groups[tag]++
+			}
+		}
+		if len(groups) == 0 {
+			return nil, fmt.Errorf("none of the %d templates has tags", len(templates))
+		}
+		for _, keys := range packGroups(groups, n) {
+			plan.Shards = append(plan.Shards, groupShard(keys, groups, "-tags"))
+		}
+		plan.Overlap = tagOverlap(templates, plan.Shards)
+		if plan.Untagged > 0 {
+			plan.Shards = append(plan.Shards, untaggedShard(groups, plan.Untagged))
+		}
+	case ShardByHash:
+		if !byID {
+			return nil, fmt.Errorf("hash shards list individual templates; ship the template set with --templates so workers run the set they were planned from")
+		}
+		bucket := func(t Template) int {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(t.ID))
+			return int(h.Sum32() % uint32(n))
+		}
+		for i := range n {
+			if s := idShard(templates, func(t Template) bool { return bucket(t) == i }); s.Templates > 0 {
+				plan.Shards = append(plan.Shards, s)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown shard mode %q (want %s)", by, strings.Join(ShardModes, ", "))
+	}
+	if err := plan.checkFilters(n); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// checkFilters rejects a plan with a filter over MaxShardFilter, suggesting
+// a shard count whose filters would fit.
+func (p *ShardPlan) checkFilters(n int) error {
+	var total, largest int
+	for _, s := range p.Shards {
+		total += len(s.Filter)
+		largest = max(largest, len(s.Filter))
+	}
+	if largest <= MaxShardFilter {
+		return nil
+	}
+	// Shards are uneven, so aim each at three quarters of the cap.
+	suggest := max(n+1, (total*4/3+MaxShardFilter-1)/MaxShardFilter)
+	return fmt.Errorf("a %s shard filter is %d KiB, above the %d KiB limit of a task; use at least %d shards", p.By, largest>>10, MaxShardFilter>>10, suggest)
+}
+
+// dirGroups groups template paths by directory, starting at the top level
+// and splitting any group larger than an even share into its children
+// until every group fits or is a single file.
+func dirGroups(templates []Template, n int) map[string]int {
+	share := (len(templates) + n - 1) / n
+	members := make(map[string][]string)
+	for _, t := range templates {
+		key, _, _ := strings.Cut(t.Path, "/")
+		members[key] = append(members[key], t.Path)
+	}
+	for {
+		var largest string
+		for key, paths := range members {
+			if len(paths) <= share || (len(paths) == 1 && paths[0] == key) {
+				continue
+			}
+			if largest == "" || len(paths) > len(members[largest]) || (len(paths) == len(members[largest]) && key < largest) {
+				largest = key
+			}
+		}
+		if largest == "" {
+			break
+		}
+		paths := members[largest]
+		delete(members, largest)
+		for _, p := range paths {
+			next, _, _ := strings.Cut(strings.TrimPrefix(p, largest+"/"), "/")
+			key := path.Join(largest, next)
+			members[key] = append(members[key], p)
+		}
+	}
+	groups := make(map[string]int, len(members))
+	for key, paths := range members {
+		groups[key] = len(paths)
+	}
+	return groups
+}
+
+// packGroups assigns groups, largest first, to the least loaded of n shards
+// and returns each non-empty shard's sorted group keys.
+func packGroups(groups map[string]int, n int) [][]string {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if groups[a] != groups[b] {
+			return groups[b] - groups[a]
+		}
+		return strings.Compare(a, b)
+	})
+	members := make([][]string, n)
+	loads := make([]int, n)
+	for _, key := range keys {
+		i := slices.Index(loads, slices.Min(loads))
+		members[i] = append(members[i], key)
+		loads[i] += groups[key]
+	}
+	var packed [][]string
+	for _, keys := range members {
+		if len(keys) == 0 {
+			continue
+		}
+		slices.Sort(keys)
+		packed = append(packed, keys)
+	}
+	return packed
+}
+
+// groupShard renders packed group keys as flag followed by the keys.
+func groupShard(keys []string, groups map[string]int, flag string) Shard {
+	s := Shard{Filter: flag + " " + strings.Join(keys, ",")}
+	for _, key := range keys {
+		s.Templates += groups[key]
+	}
+	return s
+}
+
+// untaggedShard selects the templates that carry none of the tags in
+// groups.
+func untaggedShard(groups map[string]int, untagged int) Shard {
+	tags := make([]string, 0, len(groups))
+	for tag := range groups {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return Shard{Templates: untagged, Filter: "-etags " + strings.Join(tags, ",")}
+}
+
+// idShard selects by ID the templates for which in returns true.
+func idShard(templates []Template, in func(Template) bool) Shard {
+	var ids []string
+	for _, t := range templates {
+		if in(t) && !slices.Contains(ids, t.ID) {
+			ids = append(ids, t.ID)
+		}
+	}
+	slices.Sort(ids)
+	return Shard{Templates: len(ids), Filter: "-id " + strings.Join(ids, ",")}
+}
+
+// tagOverlap counts how many extra shards each template runs in because its
+// tags were packed into different shards.
+func tagOverlap(templates []Template, shards []Shard) int {
+	shardOf := make(map[string]int)
+	for i, s := range shards {
+		for _, tag := range strings.Split(strings.TrimPrefix(s.Filter, "-tags "), ",") {
+			shardOf[tag] = i
+		}
+	}
+	overlap := 0
+	for _, t := range templates {
+		seen := make(map[int]bool)
+		for _, tag := range t.Tags {
+			seen[shardOf[tag]] = true
+		}
+		if len(seen) > 1 {
+			overlap += len(seen) - 1
+		}
+	}
+	return overlap
+}
+
+// CheckOptions rejects job options that already select templates.
+func CheckOptions(options string) error {
+	args, err := worker.SplitOptions(options)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		if slices.Contains(filterFlags, name) {
+			return fmt.Errorf("option %s selects templates and cannot be combined with template shards", name)
+		}
+	}
+	return nil
+}
+
+// Tasks returns one task per target task and shard. The shard's filter is
+// appended to the task options, and a target's shards share a group so
+// their results are kept together.
+func (p *ShardPlan) Tasks(tasks []worker.Task) []worker.Task {
+	out := make([]worker.Task, 0, len(tasks)*len(p.Shards))
+	for i, t := range tasks {
+		groupID := fmt.Sprintf("%s_line%d", t.Target, i+1)
+		for j, s := range p.Shards {
+			task := t
+			task.Options = strings.TrimSpace(t.Options + " " + s.Filter)
+			task.GroupID = groupID
+			task.ChunkIdx = j
+			task.TotalChunks = len(p.Shards)
+			out = append(out, task)
+		}
+	}
+	return out
+}
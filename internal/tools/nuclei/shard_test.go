@@ -0,0 +1,235 @@
+package nuclei
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"heph4estus/internal/worker"
+)
+
+func writeTemplate(t *testing.T, root, rel, body string) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	root := t.TempDir()
+	writeTemplate(t, root, "http/cves/a.yaml", "id: cve-a\ninfo:\n  tags: cve, RCE ,cve\n")
+	writeTemplate(t, root, "dns/b.yml", "id: dns-b\ninfo:\n  tags: [dns]\n")
+	writeTemplate(t, root, "http/helper.txt", "not a template")
+	writeTemplate(t, root, "http/no-id.yaml", "info:\n  tags: x\n")
+	writeTemplate(t, root, ".github/ci.yaml", "id: ci\n")
+
+	got, err := LoadTemplates(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("templates = %+v", got)
+	}
+	if got[0].ID != "dns-b" || got[0].Path != "dns/b.yml" || strings.Join(got[0].Tags, ",") != "dns" {
+		t.Errorf("dns template = %+v", got[0])
+	}
+	if got[1].Path != "http/cves/a.yaml" || strings.Join(got[1].Tags, ",") != "cve,rce" {
+		t.Errorf("http template = %+v", got[1])
+	}
+	if _, err := LoadTemplates(t.TempDir()); err == nil {
+		t.Error("expected an error for an empty template set")
+	}
+}
+
+func templateSet() []Template {
+	var ts []Template
+	for i := range 6 {
+		ts = append(ts, Template{ID: "cve-" + string(rune('a'+i)), Path: "http/cves/" + string(rune('a'+i)) + ".yaml", Tags: []string{"cve"}})
+	}
+	ts = append(ts,
+		Template{ID: "panel", Path: "http/panels/p.yaml", Tags: []string{"panel", "cve"}},
+		Template{ID: "dns", Path: "dns/d.yaml", Tags: []string{"dns"}},
+		Template{ID: "ssl", Path: "ssl/s.yaml"},
+	)
+	return ts
+}
+
+func TestPlanShardsByDir(t *testing.T) {
+	plan, err := PlanShards(templateSet(), 3, ShardByDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// http (7) exceeds an even share of 3 and splits into cves and panels;
+	// cves (6) still exceeds it and splits into its files.
+	var filters []string
+	for _, s := range plan.Shards {
+		filters = append(filters, s.Filter)
+		if s.Templates != 3 {
+			t.Errorf("shard %q has %d templates, want 3", s.Filter, s.Templates)
+		}
+	}
+	want := []string{
+		"-t dns,http/cves/c.yaml,http/cves/f.yaml",
+		"-t http/cves/a.yaml,http/cves/d.yaml,http/panels",
+		"-t http/cves/b.yaml,http/cves/e.yaml,ssl",
+	}
+	if strings.Join(filters, "|") != strings.Join(want, "|") {
+		t.Errorf("filters = %q, want %q", filters, want)
+	}
+
+	// A single shard keeps whole top-level directories.
+	plan, err = PlanShards(templateSet(), 1, ShardByDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Shards) != 1 || plan.Shards[0].Filter != "-t dns,http,ssl" {
+		t.Errorf("one shard = %+v", plan.Shards)
+	}
+}
+
+func TestPlanShardsByID(t *testing.T) {
+	plan, err := PlanShards(templateSet(), 1, ShardByDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "-id cve-a,cve-b,cve-c,cve-d,cve-e,cve-f,dns,panel,ssl"
+	if len(plan.Shards) != 1 || plan.Shards[0].Filter != want || plan.Shards[0].Templates != 9 {
+		t.Errorf("one shard = %+v", plan.Shards)
+	}
+
+	for _, by := range []string{ShardByDir, ShardByHash} {
+		plan, err := PlanShards(templateSet(), 3, by, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total := 0
+		for _, s := range plan.Shards {
+			if !strings.HasPrefix(s.Filter, "-id ") {
+				t.Errorf("%s shard filter = %q", by, s.Filter)
+			}
+			total += s.Templates
+		}
+		if total != 9 {
+			t.Errorf("%s shards cover %d templates, want 9", by, total)
+		}
+	}
+}
+
+func TestPlanShardsCapsFilterSize(t *testing.T) {
+	var templates []Template
+	for i := range 8000 {
+		id := fmt.Sprintf("CVE-2024-%05d-some-vulnerable-product", i)
+		templates = append(templates, Template{ID: id, Path: "http/cves/2024/" + id + ".yaml"})
+	}
+	_, err := PlanShards(templates, 2, ShardByHash, true)
+	if err == nil || !strings.Contains(err.Error(), "use at least 7 shards") {
+		t.Fatalf("oversized shards error = %v", err)
+	}
+	plan, err := PlanShards(templates, 7, ShardByHash, true)
+	if err != nil {
+		t.Fatalf("PlanShards(7): %v", err)
+	}
+	for _, s := range plan.Shards {
+		if len(s.Filter) > MaxShardFilter {
+			t.Errorf("shard filter is %d bytes", len(s.Filter))
+		}
+	}
+}
+
+func TestPlanShardsByTag(t *testing.T) {
+	plan, err := PlanShards(templateSet(), 2, ShardByTag, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Shards) != 3 || plan.Shards[0].Filter != "-tags cve" || plan.Shards[1].Filter != "-tags dns,panel" {
+		t.Fatalf("shards = %+v", plan.Shards)
+	}
+	if plan.Untagged != 1 || plan.Overlap != 1 {
+		t.Errorf("untagged = %d, overlap = %d", plan.Untagged, plan.Overlap)
+	}
+	if !strings.Contains(plan.String(), "1 untagged in a catch-all shard") {
+		t.Errorf("String() = %q", plan.String())
+	}
+}
+
+func TestPlanShardsByTagRunsUntaggedTemplates(t *testing.T) {
+	plan, err := PlanShards(templateSet(), 2, ShardByTag, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := plan.Shards[len(plan.Shards)-1]
+	if last.Filter != "-etags cve,dns,panel" || last.Templates != plan.Untagged {
+		t.Errorf("catch-all shard = %+v", last)
+	}
+
+	tagged := []Template{{ID: "a", Tags: []string{"cve"}}, {ID: "b", Tags: []string{"dns"}}}
+	if plan, err = PlanShards(tagged, 2, ShardByTag, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range plan.Shards {
+		if strings.HasPrefix(s.Filter, "-etags") {
+			t.Errorf("fully tagged set got a catch-all shard: %+v", plan.Shards)
+		}
+	}
+}
+
+func TestPlanShardsByHash(t *testing.T) {
+	first, err := PlanShards(templateSet(), 4, ShardByHash, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := PlanShards(templateSet(), 4, ShardByHash, true)
+	total := 0
+	for i, s := range first.Shards {
+		if s.Filter != again.Shards[i].Filter {
+			t.Fatal("hash sharding is not deterministic")
+		}
+		total += s.Templates
+	}
+	if total != 9 {
+		t.Errorf("hash shards cover %d templates, want 9", total)
+	}
+
+	for _, tc := range []struct {
+		n  int
+		by string
+	}{{0, ShardByDir}, {2, "random"}, {2, ShardByHash}} {
+		if _, err := PlanShards(templateSet(), tc.n, tc.by, false); err == nil {
+			t.Errorf("PlanShards(%d, %q) = nil error", tc.n, tc.by)
+		}
+	}
+}
+
+func TestShardPlanTasks(t *testing.T) {
+	plan := &ShardPlan{By: ShardByDir, Shards: []Shard{{Filter: "-t http"}, {Filter: "-t dns"}}}
+	tasks := plan.Tasks([]worker.Task{
+		{ToolName: "nuclei", Target: "https://a.example", Options: "-severity high"},
+		{ToolName: "nuclei", Target: "b.example"},
+	})
+	if len(tasks) != 4 {
+		t.Fatalf("tasks = %d, want 4", len(tasks))
+	}
+	if got := tasks[1]; got.Options != "-severity high -t dns" || got.ChunkIdx != 1 || got.TotalChunks != 2 || got.GroupID != "https://a.example_line1" {
+		t.Errorf("task = %+v", got)
+	}
+	if got := tasks[2]; got.Options != "-t http" || got.Target != "b.example" || got.GroupID != "b.example_line2" {
+		t.Errorf("task = %+v", got)
+	}
+}
+
+func TestCheckOptions(t *testing.T) {
+	if err := CheckOptions("-severity high -timeout 5"); err != nil {
+		t.Errorf("CheckOptions = %v", err)
+	}
+	for _, opts := range []string{"-t cves/", "-tags=rce", "-severity high -id x"} {
+		if err := CheckOptions(opts); err == nil {
+			t.Errorf("CheckOptions(%q) = nil, want error", opts)
+		}
+	}
+}
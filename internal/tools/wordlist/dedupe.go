@@ -0,0 +1,326 @@
+package wordlist
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// MaxDedupeMemory caps the entries dedupe holds in memory at once. A
+	// list that would need more is spilled to hash partitions on disk and
+	// each partition is deduplicated on its own, so the result stays exact.
+	MaxDedupeMemory = 128 * 1024 * 1024
+	// dedupeEntryOverhead approximates what a set entry costs beyond its
+	// bytes.
+	dedupeEntryOverhead = 64
+	// maxDedupePartitions bounds the partition files open at once.
+	maxDedupePartitions = 512
+)
+
+// dedupeMemory is MaxDedupeMemory; tests lower it to exercise spilling.
+var dedupeMemory int64 = MaxDedupeMemory
+
+// duplicates decides which emitted entries repeat an earlier one.
+type duplicates interface {
+	// repeat reports whether out, the next emitted entry, was emitted
+	// before.
+	repeat(out string) (bool, error)
+	close() error
+}
+
+// dedupePartitions returns how many on-disk partitions deduplicating the
+// output of words source entries totalling entryBytes needs, or zero when
+// it fits in memory. The estimate assumes every variant is emitted.
+func dedupePartitions(t *Transform, words int, entryBytes int64) int {
+	if words == 0 {
+		return 0
+	}
+	perEntry := ceilDiv(entryBytes, int64(words)) + int64(t.maxAddedLen()) + dedupeEntryOverhead
+	n := ceilDiv(int64(words)*int64(t.variants())*perEntry, dedupeMemory)
+	if n <= 1 {
+		return 0
+	}
+	return int(min(n, maxDedupePartitions))
+}
+
+// memorySet deduplicates in memory.
+type memorySet map[string]struct{}
+
+func (s memorySet) repeat(out string) (bool, error) {
+	if _, ok := s[out]; ok {
+		return true, nil
+	}
+	s[out] = struct{}{}
+	return false, nil
+}
+
+func (s memorySet) close() error { return nil }
+
+// partitionedSet deduplicates a list too large for memory. A first pass
+// writes every emitted entry with its position to one of several partition
+// files by hash, so repeats share a partition. Each partition is then
+// deduplicated in memory, recording the positions of its repeats in order.
+// repeat merges those position lists while the caller's pass emits the same
+// entries again.
+type partitionedSet struct {
+	dir   string
+	dups  []string
+	merge *positionMerge
+	next  uint64
+}
+
+// newPartitionedSet indexes the repeats among the entries that t emits for
+// the wordlist at path, spilling to a temporary directory.
+func newPartitionedSet(path string, scannerMax int, t *Transform, partitions int) (*partitionedSet, error) {
+	dir, err := os.MkdirTemp("", "heph-dedupe-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating dedupe temp dir: %w", err)
+	}
+	s := &partitionedSet{dir: dir}
+	if err := s.index(path, scannerMax, t, partitions); err != nil {
+		_ = s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *partitionedSet) index(path string, scannerMax int, t *Transform, partitions int) error {
+	parts := make([]string, partitions)
+	files := make([]*os.File, partitions)
+	writers := make([]*bufio.Writer, partitions)
+	defer func() {
+		for _, f := range files {
+			if f != nil {
+				_ = f.Close()
+			}
+		}
+	}()
+	for i := range parts {
+		parts[i] = filepath.Join(s.dir, fmt.Sprintf("part_%04d", i))
+		f, err := os.Create(parts[i])
+		if err != nil {
+			return fmt.Errorf("creating dedupe partition: %w", err)
+		}
+		files[i] = f
+		writers[i] = bufio.NewWriterSize(f, 32*1024)
+	}
+
+	// The same rules without dedupe emit every candidate in order.
+	rules := *t
+	rules.Dedupe = false
+	pipe, err := newPipeline(&rules)
+	if err != nil {
+		return err
+	}
+	var (
+		pos uint64
+		buf [2 * binary.MaxVarintLen64]byte
+	)
+	spill := func(out string) error {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(out))
+		w := writers[h.Sum64()%uint64(partitions)]
+		n := binary.PutUvarint(buf[:], pos)
+		n += binary.PutUvarint(buf[n:], uint64(len(out)))
+		pos++
+		if _, err := w.Write(buf[:n]); err != nil {
+			return fmt.Errorf("writing dedupe partition: %w", err)
+		}
+		if _, err := w.WriteString(out); err != nil {
+			return fmt.Errorf("writing dedupe partition: %w", err)
+		}
+		return nil
+	}
+	if err := eachEntry(path, scannerMax, func(line string) error { return pipe.apply(line, spill) }); err != nil {
+		return err
+	}
+	for i, w := range writers {
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("writing dedupe partition: %w", err)
+		}
+		if err := files[i].Close(); err != nil {
+			return fmt.Errorf("writing dedupe partition: %w", err)
+		}
+		files[i] = nil
+	}
+
+	for i, part := range parts {
+		dups := filepath.Join(s.dir, fmt.Sprintf("dups_%04d", i))
+		if err := findRepeats(part, dups); err != nil {
+			return err
+		}
+		_ = os.Remove(part)
+		s.dups = append(s.dups, dups)
+	}
+	return nil
+}
+
+// findRepeats reads one partition and writes the positions of its repeated
+// entries to dups, in increasing order and delta-encoded.
+func findRepeats(part, dups string) error {
+	in, err := os.Open(part)
+	if err != nil {
+		return fmt.Errorf("reading dedupe partition: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.Create(dups)
+	if err != nil {
+		return fmt.Errorf("creating dedupe index: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	r := bufio.NewReader(in)
+	w := bufio.NewWriter(out)
+	seen := make(map[string]struct{})
+	var (
+		last uint64
+		buf  [binary.MaxVarintLen64]byte
+	)
+	for {
+		pos, err := binary.ReadUvarint(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading dedupe partition: %w", err)
+		}
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return fmt.Errorf("reading dedupe partition: %w", err)
+		}
+		entry := make([]byte, size)
+		if _, err := io.ReadFull(r, entry); err != nil {
+			return fmt.Errorf("reading dedupe partition: %w", err)
+		}
+		if _, ok := seen[string(entry)]; !ok {
+			seen[string(entry)] = struct{}{}
+			continue
+		}
+		n := binary.PutUvarint(buf[:], pos-last)
+		last = pos
+		if _, err := w.Write(buf[:n]); err != nil {
+			return fmt.Errorf("writing dedupe index: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing dedupe index: %w", err)
+	}
+	return out.Close()
+}
+
+func (s *partitionedSet) repeat(string) (bool, error) {
+	if s.merge == nil {
+		m, err := openPositionMerge(s.dups)
+		if err != nil {
+			return false, err
+		}
+		s.merge = m
+	}
+	pos := s.next
+	s.next++
+	head, ok, err := s.merge.peek()
+	if err != nil || !ok || head != pos {
+		return false, err
+	}
+	return true, s.merge.pop()
+}
+
+func (s *partitionedSet) close() error {
+	if s.merge != nil {
+		s.merge.close()
+	}
+	return os.RemoveAll(s.dir)
+}
+
+// positionReader streams one delta-encoded position list.
+type positionReader struct {
+	file *os.File
+	r    *bufio.Reader
+	pos  uint64
+}
+
+// positionMerge merges sorted position lists into one increasing stream.
+type positionMerge struct {
+	open  []*positionReader
+	queue []*positionReader
+}
+
+func openPositionMerge(paths []string) (*positionMerge, error) {
+	m := &positionMerge{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			m.close()
+			return nil, fmt.Errorf("reading dedupe index: %w", err)
+		}
+		pr := &positionReader{file: f, r: bufio.NewReaderSize(f, 4096)}
+		m.open = append(m.open, pr)
+		ok, err := pr.advance(0)
+		if err != nil {
+			m.close()
+			return nil, err
+		}
+		if ok {
+			m.queue = append(m.queue, pr)
+		}
+	}
+	heap.Init(m)
+	return m, nil
+}
+
+// advance reads the next position after base.
+func (pr *positionReader) advance(base uint64) (bool, error) {
+	delta, err := binary.ReadUvarint(pr.r)
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading dedupe index: %w", err)
+	}
+	pr.pos = base + delta
+	return true, nil
+}
+
+func (m *positionMerge) peek() (uint64, bool, error) {
+	if len(m.queue) == 0 {
+		return 0, false, nil
+	}
+	return m.queue[0].pos, true, nil
+}
+
+func (m *positionMerge) pop() error {
+	pr := m.queue[0]
+	ok, err := pr.advance(pr.pos)
+	if err != nil {
+		return err
+	}
+	if ok {
+		heap.Fix(m, 0)
+	} else {
+		heap.Pop(m)
+	}
+	return nil
+}
+
+func (m *positionMerge) close() {
+	for _, pr := range m.open {
+		_ = pr.file.Close()
+	}
+}
+
+func (m *positionMerge) Len() int           { return len(m.queue) }
+func (m *positionMerge) Less(i, j int) bool { return m.queue[i].pos < m.queue[j].pos }
+func (m *positionMerge) Swap(i, j int)      { m.queue[i], m.queue[j] = m.queue[j], m.queue[i] }
+func (m *positionMerge) Push(x any)         { m.queue = append(m.queue, x.(*positionReader)) }
+func (m *positionMerge) Pop() any {
+	last := m.queue[len(m.queue)-1]
+	m.queue = m.queue[:len(m.queue)-1]
+	return last
+}
@@ -21,6 +21,38 @@ type Policy struct {
 	TargetChunkSize     int64
 	MaxChunkSize        int64
 	ScannerMaxTokenSize int
+	// Transform, when set, rewrites entries as they are counted and split.
+	Transform *Transform
+	// Skip and Limit select a slice of the (transformed) entries: the first
+	// Skip are dropped and, when Limit is positive, at most Limit are kept.
+	// Chunk sizes are then computed from the slice alone.
+	Skip  int
+	Limit int
+}
+
+// slice counts entries against Policy.Skip and Policy.Limit.
+type slice struct {
+	skip, limit, seen int
+}
+
+// slice returns a fresh entry counter, or nil when the policy keeps every
+// entry.
+func (p Policy) slice() *slice {
+	if p.Skip <= 0 && p.Limit <= 0 {
+		return nil
+	}
+	return &slice{skip: p.Skip, limit: p.Limit}
+}
+
+// admit reports whether the next entry is inside the slice.
+func (s *slice) admit() bool {
+	s.seen++
+	return s.seen > s.skip && !s.done(s.seen-1)
+}
+
+// done reports whether n entries already pass the end of the slice.
+func (s *slice) done(n int) bool {
+	return s.limit > 0 && n >= s.skip+s.limit
 }
 
 // Metadata is the bounded-memory preflight result for a wordlist file.
@@ -32,7 +64,19 @@ type Metadata struct {
 	RequestedChunks  int
 	TargetChunkSize  int64
 	MaxChunkSize     int64
-	totalEntryBytes  int64
+	// Transform reports what Policy.Transform removed and added. It is zero
+	// when no transform ran; TotalWords already counts the output.
+	Transform       TransformStats
+	totalEntryBytes int64
+	// dedupePartitions is how many on-disk partitions dedupe spills to,
+	// zero when it runs in memory, so both passes dedupe alike.
+	dedupePartitions int
+}
+
+// EntryBytes returns the newline-terminated size of the entries that will
+// be split, after any transform and slice.
+func (m *Metadata) EntryBytes() int64 {
+	return m.totalEntryBytes
 }
 
 // Chunk describes one temporary chunk file and its final upload key.
@@ -75,6 +119,9 @@ func InspectFile(path string, policy Policy) (*Metadata, error) {
 	if policy.RequestedChunks < 0 {
 		return nil, fmt.Errorf("requested chunk count must be positive")
 	}
+	if policy.Skip < 0 || policy.Limit < 0 {
+		return nil, fmt.Errorf("wordlist slice bounds must be positive")
+	}
 
 	info, err := os.Stat(path)
 	if err != nil {
@@ -85,22 +132,68 @@ func InspectFile(path string, policy Policy) (*Metadata, error) {
 	}
 
 	sourceBytes := info.Size()
-	if policy.RequestedChunks > 0 {
-		avg := ceilDiv(sourceBytes, int64(policy.RequestedChunks))
-		if avg > policy.MaxChunkSize {
-			return nil, fmt.Errorf("requested chunk count %d would average %s per chunk, above max safe chunk size %s; increase --chunks", policy.RequestedChunks, formatBytes(avg), formatBytes(policy.MaxChunkSize))
+	if policy.RequestedChunks > 0 && policy.slice() == nil {
+		if err := checkRequestedChunks(sourceBytes, policy); err != nil {
+			return nil, err
 		}
 	}
 
-	words, entryBytes, err := scanWordlistStats(path, policy.ScannerMaxTokenSize)
+	// A transformed slice is cut from the transform's output, so the raw
+	// pass counts every entry to size the dedupe.
+	rawSlice := policy.slice()
+	if !policy.Transform.IsZero() {
+		rawSlice = nil
+	}
+	words, entryBytes, err := scanWordlistStats(path, policy.ScannerMaxTokenSize, nil, rawSlice)
 	if err != nil {
 		return nil, err
 	}
 	if words == 0 {
+		if rawSlice != nil {
+			return nil, fmt.Errorf("no wordlist entries after the first %d", policy.Skip)
+		}
 		return nil, fmt.Errorf("no entries found in wordlist")
 	}
 
-	effective := effectiveChunkCount(sourceBytes, words, policy)
+	var (
+		stats      TransformStats
+		partitions int
+	)
+	if !policy.Transform.IsZero() {
+		if policy.Transform.Dedupe {
+			partitions = dedupePartitions(policy.Transform, words, entryBytes)
+		}
+		pipe, err := newFilePipeline(path, policy, partitions)
+		if err != nil {
+			return nil, err
+		}
+		words, entryBytes, err = scanWordlistStats(path, policy.ScannerMaxTokenSize, pipe, policy.slice())
+		if closeErr := pipe.close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		if words == 0 {
+			if policy.slice() != nil {
+				return nil, fmt.Errorf("no wordlist entries after the first %d once transformed (%s)", policy.Skip, policy.Transform)
+			}
+			return nil, fmt.Errorf("no entries left in wordlist after transforms (%s)", policy.Transform)
+		}
+		stats = pipe.stats
+	}
+
+	// A slice is sized by its own entries rather than the whole file.
+	sizeBytes := sourceBytes
+	if policy.slice() != nil {
+		sizeBytes = entryBytes
+		if policy.RequestedChunks > 0 {
+			if err := checkRequestedChunks(sizeBytes, policy); err != nil {
+				return nil, err
+			}
+		}
+	}
+	effective := effectiveChunkCount(sizeBytes, words, policy)
 	return &Metadata{
 		Path:             path,
 		TotalWords:       words,
@@ -109,12 +202,15 @@ func InspectFile(path string, policy Policy) (*Metadata, error) {
 		RequestedChunks:  policy.RequestedChunks,
 		TargetChunkSize:  policy.TargetChunkSize,
 		MaxChunkSize:     policy.MaxChunkSize,
+		Transform:        stats,
 		totalEntryBytes:  entryBytes,
+		dedupePartitions: partitions,
 	}, nil
 }
 
 // SplitFile streams path into temporary chunk files under tempDir. Non-empty
-// entries are preserved exactly except that chunks are newline-terminated.
+// entries are preserved exactly except that chunks are newline-terminated,
+// unless policy.Transform rewrites them on the way through.
 func SplitFile(path, tempDir string, policy Policy, keyForChunk func(int) string) (*Result, error) {
 	meta, err := InspectFile(path, policy)
 	if err != nil {
@@ -146,6 +242,17 @@ func splitWithMetadata(meta *Metadata, tempDir string, policy Policy, keyForChun
 		_ = file.Close()
 	}()
 
+	var pipe *pipeline
+	if !policy.Transform.IsZero() {
+		pipe, err = newFilePipeline(meta.Path, policy, meta.dedupePartitions)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = pipe.close()
+		}()
+	}
+
 	result := &Result{Metadata: *meta}
 	targetBytes := ceilDiv(meta.totalEntryBytes, int64(meta.EffectiveChunks))
 	if targetBytes < 1 {
@@ -153,6 +260,7 @@ func splitWithMetadata(meta *Metadata, tempDir string, policy Policy, keyForChun
 	}
 
 	scanner := newScanner(file, policy.ScannerMaxTokenSize)
+	window := policy.slice()
 	var (
 		currentFile *os.File
 		writer      *bufio.Writer
@@ -225,27 +333,19 @@ func splitWithMetadata(meta *Metadata, tempDir string, policy Policy, keyForChun
 		return nil
 	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-
+	writeEntry := func(line string) error {
 		entryBytes := int64(len(line) + 1)
 		if entryBytes > policy.MaxChunkSize {
-			cleanupFailedSplit()
-			return result, fmt.Errorf("wordlist entry is %s, above max safe chunk size %s", formatBytes(entryBytes), formatBytes(policy.MaxChunkSize))
+			return fmt.Errorf("wordlist entry is %s, above max safe chunk size %s", formatBytes(entryBytes), formatBytes(policy.MaxChunkSize))
 		}
 
 		futureChunks := meta.EffectiveChunks - chunkIndex - 1
 		if currentFile != nil && chunkWords > 0 && chunkBytes+entryBytes > policy.MaxChunkSize {
 			if futureChunks <= 0 {
-				cleanupFailedSplit()
-				return result, fmt.Errorf("wordlist chunk %d would exceed max safe chunk size %s; increase --chunks", chunkIndex, formatBytes(policy.MaxChunkSize))
+				return fmt.Errorf("wordlist chunk %d would exceed max safe chunk size %s; increase --chunks", chunkIndex, formatBytes(policy.MaxChunkSize))
 			}
 			if err := finishChunk(); err != nil {
-				cleanupFailedSplit()
-				return result, err
+				return err
 			}
 			chunkIndex++
 			futureChunks = meta.EffectiveChunks - chunkIndex - 1
@@ -256,29 +356,56 @@ func splitWithMetadata(meta *Metadata, tempDir string, policy Policy, keyForChun
 		forceSplit := currentFile != nil && chunkWords > 0 && futureChunks > 0 && remainingWordsIncludingLine <= futureChunks
 		if sizeSplit || forceSplit {
 			if err := finishChunk(); err != nil {
-				cleanupFailedSplit()
-				return result, err
+				return err
 			}
 			chunkIndex++
 		}
 
 		if currentFile == nil {
 			if err := startChunk(); err != nil {
-				cleanupFailedSplit()
-				return result, err
+				return err
 			}
 		}
 		if _, err := writer.WriteString(line); err != nil {
-			cleanupFailedSplit()
-			return result, fmt.Errorf("writing wordlist chunk %d: %w", chunkIndex, err)
+			return fmt.Errorf("writing wordlist chunk %d: %w", chunkIndex, err)
 		}
 		if err := writer.WriteByte('\n'); err != nil {
-			cleanupFailedSplit()
-			return result, fmt.Errorf("writing wordlist chunk %d: %w", chunkIndex, err)
+			return fmt.Errorf("writing wordlist chunk %d: %w", chunkIndex, err)
 		}
 		chunkBytes += entryBytes
 		chunkWords++
 		processed++
+		return nil
+	}
+
+	emit := writeEntry
+	if window != nil {
+		emit = func(line string) error {
+			if !window.admit() {
+				return nil
+			}
+			return writeEntry(line)
+		}
+	}
+
+	for scanner.Scan() {
+		if window != nil && window.done(window.seen) {
+			break
+		}
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		var err error
+		if pipe != nil {
+			err = pipe.apply(line, emit)
+		} else {
+			err = emit(line)
+		}
+		if err != nil {
+			cleanupFailedSplit()
+			return result, err
+		}
 	}
 	if err := scanner.Err(); err != nil {
 		cleanupFailedSplit()
@@ -296,7 +423,9 @@ func splitWithMetadata(meta *Metadata, tempDir string, policy Policy, keyForChun
 	return result, nil
 }
 
-func scanWordlistStats(path string, scannerMax int) (int, int64, error) {
+// scanWordlistStats counts non-empty entries and their newline-terminated
+// bytes, after pipe when it is set and within window when that is set.
+func scanWordlistStats(path string, scannerMax int, pipe *pipeline, window *slice) (int, int64, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return 0, 0, fmt.Errorf("opening wordlist file: %w", err)
@@ -308,13 +437,29 @@ func scanWordlistStats(path string, scannerMax int) (int, int64, error) {
 	scanner := newScanner(file, scannerMax)
 	var words int
 	var entryBytes int64
+	count := func(out string) error {
+		if window != nil && !window.admit() {
+			return nil
+		}
+		words++
+		entryBytes += int64(len(out) + 1)
+		return nil
+	}
 	for scanner.Scan() {
+		if window != nil && window.done(window.seen) {
+			break
+		}
 		line := scanner.Text()
 		if line == "" {
 			continue
 		}
-		words++
-		entryBytes += int64(len(line) + 1)
+		if pipe == nil {
+			_ = count(line)
+			continue
+		}
+		if err := pipe.apply(line, count); err != nil {
+			return 0, 0, err
+		}
 	}
 	if err := scanner.Err(); err != nil {
 		return 0, 0, scannerError(err, scannerMax)
@@ -322,6 +467,49 @@ func scanWordlistStats(path string, scannerMax int) (int, int64, error) {
 	return words, entryBytes, nil
 }
 
+// newFilePipeline compiles policy.Transform for one pass over path. Dedupe
+// runs in memory, or over partitions spilled to disk when there are any.
+func newFilePipeline(path string, policy Policy, partitions int) (*pipeline, error) {
+	pipe, err := newPipeline(policy.Transform)
+	if err != nil || !policy.Transform.Dedupe {
+		return pipe, err
+	}
+	if partitions == 0 {
+		pipe.dups = memorySet{}
+		return pipe, nil
+	}
+	dups, err := newPartitionedSet(path, policy.ScannerMaxTokenSize, policy.Transform, partitions)
+	if err != nil {
+		return nil, err
+	}
+	pipe.dups = dups
+	return pipe, nil
+}
+
+// eachEntry calls fn with every non-empty entry of the wordlist at path.
+func eachEntry(path string, scannerMax int, fn func(string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening wordlist file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	scanner := newScanner(file, scannerMax)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return scannerError(err, scannerMax)
+	}
+	return nil
+}
+
 func newScanner(file *os.File, maxTokenSize int) *bufio.Scanner {
 	scanner := bufio.NewScanner(file)
 	initialSize := 64 * 1024
@@ -342,6 +530,14 @@ func scannerError(err error, maxTokenSize int) error {
 	return fmt.Errorf("scanning wordlist file: %w", err)
 }
 
+func checkRequestedChunks(sizeBytes int64, policy Policy) error {
+	avg := ceilDiv(sizeBytes, int64(policy.RequestedChunks))
+	if avg > policy.MaxChunkSize {
+		return fmt.Errorf("requested chunk count %d would average %s per chunk, above max safe chunk size %s; increase --chunks", policy.RequestedChunks, formatBytes(avg), formatBytes(policy.MaxChunkSize))
+	}
+	return nil
+}
+
 func effectiveChunkCount(sourceBytes int64, totalWords int, policy Policy) int {
 	var desired int
 	if policy.RequestedChunks > 0 {
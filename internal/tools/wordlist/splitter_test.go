@@ -288,3 +288,43 @@ func TestSplitFileCleanupRemovesTempFiles(t *testing.T) {
 		}
 	}
 }
+
+func TestSplitFileSlice(t *testing.T) {
+	path := writeWordlist(t, "a\nb\n\nc\nd\ne\n")
+
+	head, err := SplitFile(path, t.TempDir(), Policy{RequestedChunks: 2, Limit: 2}, nil)
+	if err != nil {
+		t.Fatalf("split head: %v", err)
+	}
+	defer cleanupSplitResult(t, head)
+	if got := strings.Join(splitAll(t, head), " "); got != "a b" || head.TotalWords != 2 || len(head.Chunks) != 2 {
+		t.Fatalf("head = %q (%d words, %d chunks)", got, head.TotalWords, len(head.Chunks))
+	}
+
+	rest, err := SplitFile(path, t.TempDir(), Policy{RequestedChunks: 1, Skip: 2}, nil)
+	if err != nil {
+		t.Fatalf("split rest: %v", err)
+	}
+	defer cleanupSplitResult(t, rest)
+	if got := strings.Join(splitAll(t, rest), " "); got != "c d e" || rest.TotalWords != 3 {
+		t.Fatalf("rest = %q (%d words)", got, rest.TotalWords)
+	}
+
+	if _, err := InspectFile(path, Policy{Skip: 5}); err == nil || !strings.Contains(err.Error(), "after the first 5") {
+		t.Fatalf("empty slice error = %v", err)
+	}
+}
+
+func TestSplitFileSliceCutsTransformedEntries(t *testing.T) {
+	path := writeWordlist(t, "a\nA\nb\nc\n")
+	tr := &Transform{Dedupe: true, Lowercase: true, Extensions: []string{"php"}}
+
+	rest, err := SplitFile(path, t.TempDir(), Policy{RequestedChunks: 1, Skip: 3, Transform: tr}, nil)
+	if err != nil {
+		t.Fatalf("split rest: %v", err)
+	}
+	defer cleanupSplitResult(t, rest)
+	if got := strings.Join(splitAll(t, rest), " "); got != "b.php c c.php" {
+		t.Fatalf("rest = %q", got)
+	}
+}
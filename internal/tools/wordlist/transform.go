@@ -0,0 +1,256 @@
+package wordlist
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Transform rewrites entries while a wordlist is inspected and split. Steps
+// run in a fixed order: case folding, then the length and regex filters on
+// the folded entry, then prefix, suffix and extension expansion, and finally
+// deduplication of everything emitted. The zero value passes entries through
+// unchanged.
+type Transform struct {
+	// Dedupe drops repeated output entries, keeping the first. It is exact;
+	// lists too large to deduplicate within MaxDedupeMemory are spilled to
+	// temporary files instead.
+	Dedupe bool `json:"dedupe,omitempty"`
+	// Lowercase folds every entry to lower case.
+	Lowercase bool `json:"lowercase,omitempty"`
+	// Include keeps only entries matching this regular expression.
+	Include string `json:"include,omitempty"`
+	// Exclude drops entries matching this regular expression.
+	Exclude string `json:"exclude,omitempty"`
+	// MinLength and MaxLength bound the entry length in characters. Zero
+	// disables a bound.
+	MinLength int `json:"min_length,omitempty"`
+	MaxLength int `json:"max_length,omitempty"`
+	// Prefixes and Suffixes each add a variant of every kept entry, e.g.
+	// prefix "." turns "env" into "env" and ".env".
+	Prefixes []string `json:"prefixes,omitempty"`
+	Suffixes []string `json:"suffixes,omitempty"`
+	// Extensions add "<entry>.<ext>" for the entry and each of its prefix
+	// and suffix variants, like ffuf's -e. A leading dot is optional.
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// TransformStats counts what a Transform did. Output equals
+// Input - Filtered + Added - Duplicates.
+type TransformStats struct {
+	Input      int // non-empty source entries
+	Filtered   int // source entries dropped by length or regex filters
+	Added      int // variants added by prefix, suffix and extension rules
+	Duplicates int // output entries dropped as duplicates
+	Output     int // entries written to chunks
+}
+
+// Removed returns how many entries the transform dropped.
+func (s TransformStats) Removed() int {
+	return s.Filtered + s.Duplicates
+}
+
+// IsZero reports whether t leaves entries unchanged.
+func (t *Transform) IsZero() bool {
+	return t == nil || (!t.Dedupe && !t.Lowercase && t.Include == "" && t.Exclude == "" &&
+		t.MinLength == 0 && t.MaxLength == 0 && len(t.Prefixes) == 0 && len(t.Suffixes) == 0 && len(t.Extensions) == 0)
+}
+
+// Validate checks that the regexes compile and the rules are usable.
+func (t *Transform) Validate() error {
+	_, err := newPipeline(t)
+	return err
+}
+
+// String summarizes the enabled rules, e.g. "dedupe, lowercase, +3 extensions".
+func (t *Transform) String() string {
+	if t.IsZero() {
+		return "none"
+	}
+	var parts []string
+	if t.Dedupe {
+		parts = append(parts, "dedupe")
+	}
+	if t.Lowercase {
+		parts = append(parts, "lowercase")
+	}
+	if t.Include != "" {
+		parts = append(parts, fmt.Sprintf("include /%s/", t.Include))
+	}
+	if t.Exclude != "" {
+		parts = append(parts, fmt.Sprintf("exclude /%s/", t.Exclude))
+	}
+	switch {
+	case t.MinLength > 0 && t.MaxLength > 0:
+		parts = append(parts, fmt.Sprintf("length %d-%d", t.MinLength, t.MaxLength))
+	case t.MinLength > 0:
+		parts = append(parts, fmt.Sprintf("length >= %d", t.MinLength))
+	case t.MaxLength > 0:
+		parts = append(parts, fmt.Sprintf("length <= %d", t.MaxLength))
+	}
+	if n := len(t.Prefixes); n > 0 {
+		parts = append(parts, fmt.Sprintf("+%d prefixes", n))
+	}
+	if n := len(t.Suffixes); n > 0 {
+		parts = append(parts, fmt.Sprintf("+%d suffixes", n))
+	}
+	if n := len(t.Extensions); n > 0 {
+		parts = append(parts, fmt.Sprintf("+%d extensions", n))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// variants returns how many output entries one kept source entry expands to.
+func (t *Transform) variants() int {
+	if t == nil {
+		return 1
+	}
+	return (1 + len(t.Prefixes) + len(t.Suffixes)) * (1 + len(t.Extensions))
+}
+
+// maxAddedLen returns the most bytes a prefix, suffix and extension add to
+// an entry together.
+func (t *Transform) maxAddedLen() int {
+	affix := 0
+	for _, s := range append(append([]string(nil), t.Prefixes...), t.Suffixes...) {
+		affix = max(affix, len(s))
+	}
+	ext := 0
+	for _, e := range t.Extensions {
+		ext = max(ext, len(strings.TrimPrefix(e, "."))+1)
+	}
+	return affix + ext
+}
+
+// pipeline is a compiled Transform plus its running stats. It is
+// deterministic, so two passes over the same file emit the same entries.
+type pipeline struct {
+	t       *Transform
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+	exts    []string
+	// dups is set by the pass when t.Dedupe is, so each pass can pick
+	// in-memory or spilled dedupe.
+	dups  duplicates
+	stats TransformStats
+}
+
+// newPipeline compiles t.
+func newPipeline(t *Transform) (*pipeline, error) {
+	p := &pipeline{t: t}
+	if t == nil {
+		return p, nil
+	}
+	var err error
+	if t.Include != "" {
+		if p.include, err = regexp.Compile(t.Include); err != nil {
+			return nil, fmt.Errorf("invalid include regex: %w", err)
+		}
+	}
+	if t.Exclude != "" {
+		if p.exclude, err = regexp.Compile(t.Exclude); err != nil {
+			return nil, fmt.Errorf("invalid exclude regex: %w", err)
+		}
+	}
+	if t.MinLength < 0 || t.MaxLength < 0 {
+		return nil, fmt.Errorf("wordlist length bounds must be positive")
+	}
+	if t.MaxLength > 0 && t.MinLength > t.MaxLength {
+		return nil, fmt.Errorf("minimum length %d is above maximum length %d", t.MinLength, t.MaxLength)
+	}
+	for _, rule := range [][]string{t.Prefixes, t.Suffixes} {
+		for _, s := range rule {
+			if s == "" || strings.ContainsAny(s, "\r\n") {
+				return nil, fmt.Errorf("invalid prefix or suffix %q", s)
+			}
+		}
+	}
+	for _, ext := range t.Extensions {
+		ext = strings.TrimPrefix(ext, ".")
+		if ext == "" || strings.ContainsAny(ext, "/\r\n") {
+			return nil, fmt.Errorf("invalid extension %q", ext)
+		}
+		p.exts = append(p.exts, "."+ext)
+	}
+	return p, nil
+}
+
+// apply runs one source entry through the pipeline and emits its outputs.
+func (p *pipeline) apply(entry string, emit func(string) error) error {
+	p.stats.Input++
+	t := p.t
+	if t == nil {
+		p.stats.Output++
+		return emit(entry)
+	}
+	if t.Lowercase {
+		entry = strings.ToLower(entry)
+	}
+	if !p.keep(entry) {
+		p.stats.Filtered++
+		return nil
+	}
+
+	bases := make([]string, 0, 1+len(t.Prefixes)+len(t.Suffixes))
+	bases = append(bases, entry)
+	for _, prefix := range t.Prefixes {
+		bases = append(bases, prefix+entry)
+	}
+	for _, suffix := range t.Suffixes {
+		bases = append(bases, entry+suffix)
+	}
+	first := true
+	for _, base := range bases {
+		for i := -1; i < len(p.exts); i++ {
+			out := base
+			if i >= 0 {
+				out += p.exts[i]
+			}
+			if !first {
+				p.stats.Added++
+			}
+			first = false
+			if p.dups != nil {
+				repeat, err := p.dups.repeat(out)
+				if err != nil {
+					return err
+				}
+				if repeat {
+					p.stats.Duplicates++
+					continue
+				}
+			}
+			p.stats.Output++
+			if err := emit(out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// close releases the dedupe state, removing any spilled files.
+func (p *pipeline) close() error {
+	if p == nil || p.dups == nil {
+		return nil
+	}
+	return p.dups.close()
+}
+
+func (p *pipeline) keep(entry string) bool {
+	t := p.t
+	if t.MinLength > 0 || t.MaxLength > 0 {
+		n := utf8.RuneCountInString(entry)
+		if n < t.MinLength || (t.MaxLength > 0 && n > t.MaxLength) {
+			return false
+		}
+	}
+	if p.include != nil && !p.include.MatchString(entry) {
+		return false
+	}
+	if p.exclude != nil && p.exclude.MatchString(entry) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,206 @@
+package wordlist
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func splitAll(t *testing.T, result *Result) []string {
+	t.Helper()
+	var entries []string
+	for _, chunk := range result.Chunks {
+		entries = append(entries, strings.Fields(readChunk(t, chunk.Path))...)
+	}
+	return entries
+}
+
+func TestSplitFileTransformPipeline(t *testing.T) {
+	path := writeWordlist(t, "Admin\nadmin\nx\nlogin\nbackup.old\nLogin\n")
+
+	result, err := SplitFile(path, t.TempDir(), Policy{
+		RequestedChunks: 2,
+		Transform: &Transform{
+			Dedupe:     true,
+			Lowercase:  true,
+			MinLength:  2,
+			Exclude:    `\.old$`,
+			Prefixes:   []string{"_"},
+			Extensions: []string{"php", ".bak"},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("SplitFile: %v", err)
+	}
+	defer cleanupSplitResult(t, result)
+
+	got := strings.Join(splitAll(t, result), " ")
+	want := "admin admin.php admin.bak _admin _admin.php _admin.bak login login.php login.bak _login _login.php _login.bak"
+	if got != want {
+		t.Fatalf("entries = %q, want %q", got, want)
+	}
+
+	st := result.Transform
+	if st.Input != 6 || st.Filtered != 2 || st.Added != 20 || st.Duplicates != 12 || st.Output != 12 {
+		t.Fatalf("stats = %+v", st)
+	}
+	if st.Input-st.Filtered+st.Added-st.Duplicates != st.Output {
+		t.Fatalf("stats do not balance: %+v", st)
+	}
+	if result.TotalWords != 12 {
+		t.Fatalf("TotalWords = %d, want 12", result.TotalWords)
+	}
+	words := 0
+	for _, chunk := range result.Chunks {
+		words += chunk.WordCount
+	}
+	if words != result.TotalWords || len(result.Chunks) != 2 {
+		t.Fatalf("chunks hold %d words in %d chunks", words, len(result.Chunks))
+	}
+}
+
+func TestSplitFileTransformSuffixAndInclude(t *testing.T) {
+	path := writeWordlist(t, "api\nadmin\nv1\n")
+
+	result, err := SplitFile(path, t.TempDir(), Policy{Transform: &Transform{
+		Include:  `^a`,
+		Suffixes: []string{"/"},
+	}}, nil)
+	if err != nil {
+		t.Fatalf("SplitFile: %v", err)
+	}
+	defer cleanupSplitResult(t, result)
+
+	if got := strings.Join(splitAll(t, result), " "); got != "api api/ admin admin/" {
+		t.Fatalf("entries = %q", got)
+	}
+}
+
+func TestInspectFileTransformMatchesSplit(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&b, "word%d\n", i%1500)
+	}
+	path := writeWordlist(t, b.String())
+	policy := Policy{RequestedChunks: 7, Transform: &Transform{Dedupe: true, Extensions: []string{"txt"}}}
+
+	meta, err := InspectFile(path, policy)
+	if err != nil {
+		t.Fatalf("InspectFile: %v", err)
+	}
+	if meta.TotalWords != 3000 || meta.Transform.Duplicates != 7000 {
+		t.Fatalf("meta = %d words, stats %+v", meta.TotalWords, meta.Transform)
+	}
+
+	result, err := SplitFile(path, t.TempDir(), policy, nil)
+	if err != nil {
+		t.Fatalf("SplitFile: %v", err)
+	}
+	defer cleanupSplitResult(t, result)
+	if got := len(splitAll(t, result)); got != meta.TotalWords {
+		t.Fatalf("split wrote %d entries, inspect counted %d", got, meta.TotalWords)
+	}
+	if result.EffectiveChunks != 7 {
+		t.Fatalf("EffectiveChunks = %d, want 7", result.EffectiveChunks)
+	}
+}
+
+func TestInspectFileTransformRemovesEverything(t *testing.T) {
+	path := writeWordlist(t, "a\nb\n")
+
+	_, err := InspectFile(path, Policy{Transform: &Transform{MinLength: 3}})
+	if err == nil || !strings.Contains(err.Error(), "no entries left") {
+		t.Fatalf("expected no-entries error, got %v", err)
+	}
+}
+
+func TestTransformValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   Transform
+		want string
+	}{
+		{"bad regex", Transform{Include: "("}, "invalid include regex"},
+		{"bounds", Transform{MinLength: 5, MaxLength: 2}, "above maximum length"},
+		{"empty extension", Transform{Extensions: []string{"."}}, "invalid extension"},
+		{"empty prefix", Transform{Prefixes: []string{""}}, "invalid prefix"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tr.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if err := (&Transform{Dedupe: true, Extensions: []string{"php"}}).Validate(); err != nil {
+		t.Fatalf("valid transform: %v", err)
+	}
+}
+
+func TestSplitFileDedupeSpillsToDiskExactly(t *testing.T) {
+	var b strings.Builder
+	var want []string
+	seen := map[string]bool{}
+	for i := 0; i < 5000; i++ {
+		word := fmt.Sprintf("entry-%d", i%1700)
+		b.WriteString(word + "\n")
+		for _, out := range []string{word, word + ".php"} {
+			if !seen[out] {
+				seen[out] = true
+				want = append(want, out)
+			}
+		}
+	}
+	path := writeWordlist(t, b.String())
+
+	spill := t.TempDir()
+	t.Setenv("TMPDIR", spill)
+	defer func(prev int64) { dedupeMemory = prev }(dedupeMemory)
+	dedupeMemory = 16 * 1024
+
+	result, err := SplitFile(path, t.TempDir(), Policy{
+		RequestedChunks: 3,
+		Transform:       &Transform{Dedupe: true, Extensions: []string{"php"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("SplitFile: %v", err)
+	}
+	defer cleanupSplitResult(t, result)
+
+	if result.dedupePartitions < 2 {
+		t.Fatalf("dedupePartitions = %d, want a spill", result.dedupePartitions)
+	}
+	if left, _ := os.ReadDir(spill); len(left) != 0 {
+		t.Fatalf("spilled files left behind: %v", left)
+	}
+	got := splitAll(t, result)
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("got %d entries, want the %d unique ones in first-seen order", len(got), len(want))
+	}
+	if s := result.Transform; s.Duplicates != 10000-len(want) || s.Output != len(want) || result.TotalWords != len(want) {
+		t.Fatalf("stats = %+v, words = %d, want %d duplicates of 10000", s, result.TotalWords, 10000-len(want))
+	}
+}
+
+func TestSplitFileDedupeSpilledSlice(t *testing.T) {
+	path := writeWordlist(t, strings.Repeat("a\nb\na\nc\nb\nd\n", 50))
+
+	defer func(prev int64) { dedupeMemory = prev }(dedupeMemory)
+	dedupeMemory = 128
+
+	result, err := SplitFile(path, t.TempDir(), Policy{
+		Skip:      1,
+		Limit:     2,
+		Transform: &Transform{Dedupe: true},
+	}, nil)
+	if err != nil {
+		t.Fatalf("SplitFile: %v", err)
+	}
+	defer cleanupSplitResult(t, result)
+
+	if got := strings.Join(splitAll(t, result), " "); got != "b c" {
+		t.Fatalf("entries = %q, want %q", got, "b c")
+	}
+}
@@ -13,9 +13,11 @@ import (
 	"heph4estus/internal/operator"
 	"heph4estus/internal/tui/core"
 	"heph4estus/internal/tui/views/deploy"
+	diffview "heph4estus/internal/tui/views/diff"
 	genericview "heph4estus/internal/tui/views/generic"
 	"heph4estus/internal/tui/views/menu"
 	nmapview "heph4estus/internal/tui/views/nmap"
+	"heph4estus/internal/tui/views/search"
 	"heph4estus/internal/tui/views/settings"
 )
 
@@ -25,6 +27,10 @@ type App struct {
 	width      int
 	height     int
 	quitting   bool
+
+	// localProvider is reused across views so --cloud local keeps a single
+	// embedded queue for the whole session.
+	localProvider cloud.Provider
 }
 
 // NewApp creates a new App starting at the main menu.
@@ -58,6 +64,8 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			newView = menu.New()
 		case core.ViewNmapConfig:
 			newView = nmapview.NewConfig()
+		case core.ViewSearch:
+			newView = search.New()
 		}
 		if newView != nil {
 			a.switchView(newView)
@@ -91,6 +99,10 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if infra, ok := msg.Data.(core.InfraOutputs); ok {
 				newView = a.createGenericResultsView(infra)
 			}
+		case core.ViewDiff:
+			if req, ok := msg.Data.(core.DiffRequest); ok {
+				newView = a.createDiffView(req)
+			}
 		}
 		if newView != nil {
 			a.switchView(newView)
@@ -112,7 +124,26 @@ func (a *App) switchView(v core.View) {
 }
 
 func (a *App) buildProvider(cloudKind cloud.Kind) (cloud.Provider, error) {
-	return factory.BuildForKind(context.Background(), cloudKind, nopLogger{})
+	if !cloudKind.IsLocal() {
+		return factory.BuildForKind(context.Background(), cloudKind, nopLogger{})
+	}
+	if a.localProvider == nil {
+		p, err := factory.BuildForKind(context.Background(), cloudKind, nopLogger{})
+		if err != nil {
+			return nil, err
+		}
+		a.localProvider = p
+	}
+	return a.localProvider, nil
+}
+
+// Close releases session-scoped resources such as the local runtime's
+// embedded queue.
+func (a *App) Close() error {
+	if c, ok := a.localProvider.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
 }
 
 func (a *App) createStatusView(infra core.InfraOutputs) core.View {
@@ -149,6 +180,11 @@ func (a *App) createGenericResultsView(infra core.InfraOutputs) core.View {
 	return genericview.NewResults(infra, source, destroyer)
 }
 
+func (a *App) createDiffView(req core.DiffRequest) core.View {
+	source, _ := a.buildResultsDeps(req.Infra, req.Tool)
+	return diffview.New(req, diffview.DefaultDeps(req.Infra.JobID, source))
+}
+
 // buildDestroyer creates a Destroyer for the given terraform directory, or nil
 // if no directory is provided.
 func (a *App) buildDestroyer(terraformDir string) core.Destroyer {
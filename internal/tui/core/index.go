@@ -0,0 +1,27 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+
+	"heph4estus/internal/findings"
+	"heph4estus/internal/index"
+)
+
+// IndexExport adds a job exported to dir (<out>/<tool>/<job_id>) to the
+// local results index, replacing what was indexed for it before.
+func IndexExport(ctx context.Context, jobID, dir string) (index.Job, error) {
+	tasks, err := findings.Collect(ctx, &LocalResultsSource{
+		ResultsDir:   filepath.Join(dir, "results"),
+		ArtifactsDir: filepath.Join(dir, "artifacts"),
+	})
+	if err != nil {
+		return index.Job{JobID: jobID}, err
+	}
+	db, err := index.OpenDefault()
+	if err != nil {
+		return index.Job{JobID: jobID}, err
+	}
+	defer func() { _ = db.Close() }()
+	return db.Put(index.Job{JobID: jobID, Source: dir}, tasks)
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"strings"
+
+	"heph4estus/internal/cloud"
+	"heph4estus/internal/fleet"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/runner"
+)
+
+// RuntimeOutputs renders the outputs as the terraform output map the job
+// runner reads worker launch settings and fleet metadata from.
+func (o InfraOutputs) RuntimeOutputs() map[string]string {
+	out := map[string]string{
+		"sqs_queue_url":                 o.SQSQueueURL,
+		"s3_bucket_name":                o.S3BucketName,
+		"ecs_cluster_name":              o.ECSClusterName,
+		"task_definition_arn":           o.TaskDefinitionARN,
+		"subnet_ids":                    "[" + strings.Join(o.SubnetIDs, " ") + "]",
+		"security_group_id":             o.SecurityGroupID,
+		"ecr_repo_url":                  o.ECRRepoURL,
+		"ami_id":                        o.AMIID,
+		"instance_profile_arn":          o.InstanceProfileARN,
+		"docker_image":                  o.ExpectedWorkerVersion,
+		"controller_ip":                 o.ControllerIP,
+		"generation_id":                 o.GenerationID,
+		"nats_url":                      o.NATSUrl,
+		"controller_ca_pem":             o.ControllerCAPEM,
+		"controller_host":               o.ControllerHost,
+		"nats_operator_client_cert_pem": o.NATSClientCertPEM,
+		"nats_operator_client_key_pem":  o.NATSClientKeyPEM,
+	}
+	for k, v := range out {
+		if v == "" || v == "[]" {
+			delete(out, k)
+		}
+	}
+	return out
+}
+
+// NewRunner builds the job runner a status view drives for o. The deploy
+// view has already waited for a provider-native fleet, so its
+// FleetWorkerCount workers are taken as ready.
+func NewRunner(o InfraOutputs, provider cloud.Provider, tracker *operator.Tracker, logf func(format string, args ...any)) (*runner.Runner, error) {
+	return runner.New(runner.Config{
+		Provider:         provider,
+		Tracker:          tracker,
+		Cloud:            o.Cloud,
+		QueueID:          o.SQSQueueURL,
+		Bucket:           o.S3BucketName,
+		Outputs:          o.RuntimeOutputs(),
+		Workers:          o.WorkerCount,
+		ComputeMode:      o.ComputeMode,
+		JitterMaxSeconds: o.JitterMaxSeconds,
+		Placement:        o.Placement,
+		CleanupPolicy:    o.CleanupPolicy,
+		Budget:           o.Budget,
+		Window:           o.Window,
+		WaitForFleet: func(context.Context, cloud.Kind, map[string]string, fleet.PlacementPolicy) (int, error) {
+			switch {
+			case o.FleetWorkerCount > 0:
+				return o.FleetWorkerCount, nil
+			case o.WorkerCount > 0:
+				return o.WorkerCount, nil
+			}
+			return 1, nil
+		},
+		Logf: logf,
+	})
+}
@@ -5,6 +5,8 @@ import (
 
 	"heph4estus/internal/cloud"
 	"heph4estus/internal/fleet"
+	"heph4estus/internal/operator"
+	"heph4estus/internal/schedule"
 )
 
 // View is the interface that all TUI views implement.
@@ -29,6 +31,8 @@ const (
 	ViewGenericConfig
 	ViewGenericStatus
 	ViewGenericResults
+	ViewSearch
+	ViewDiff
 )
 
 // NavigateMsg is sent by views to request navigation.
@@ -64,6 +68,8 @@ type DeployConfig struct {
 	WorkerCount    int
 	ComputeMode    string // "auto", "fargate", "spot" — default "auto"
 	Placement      fleet.PlacementPolicy
+	Budget         operator.Budget  // Job budget from the profile; zero is unlimited
+	Window         *schedule.Window // Scan window from the profile; nil is any time
 
 	// Scan hardening settings.
 	JitterMaxSeconds   int
@@ -124,6 +130,8 @@ type InfraOutputs struct {
 	WorkerCount    int
 	ComputeMode    string // Resolved compute mode
 	Placement      fleet.PlacementPolicy
+	Budget         operator.Budget  // Enforced by the status view's monitor loop
+	Window         *schedule.Window // Passed to workers, which pause while it is closed
 
 	// Scan hardening settings (passed as env vars to workers).
 	JitterMaxSeconds   int
@@ -194,3 +202,11 @@ type StageCompleteMsg struct {
 
 // TickMsg is sent by periodic timers for polling / stream draining.
 type TickMsg struct{}
+
+// DiffRequest opens the diff view for a job's results, from the results
+// view it returns to.
+type DiffRequest struct {
+	Infra InfraOutputs
+	Tool  string // module whose results are compared
+	Back  ViewID // results view to return to, with Infra as its data
+}
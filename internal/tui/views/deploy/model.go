@@ -444,6 +444,8 @@ func (m *Model) emitNavigateToStatus() tea.Cmd {
 				WorkerCount:           cfg.WorkerCount,
 				ComputeMode:           cfg.ComputeMode,
 				Placement:             cfg.Placement,
+				Budget:                cfg.Budget,
+				Window:                cfg.Window,
 				JitterMaxSeconds:      cfg.JitterMaxSeconds,
 				NmapTimingTemplate:    cfg.NmapTimingTemplate,
 				DNSServers:            cfg.DNSServers,
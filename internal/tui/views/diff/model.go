@@ -0,0 +1,332 @@
+// Package diff is the TUI view that compares a job's findings with those of
+// an earlier job of the same tool from the results index.
+package diff
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"heph4estus/internal/findings"
+	"heph4estus/internal/index"
+	"heph4estus/internal/tui/core"
+)
+
+type keyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Back   key.Binding
+	Quit   key.Binding
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Select, k.Back, k.Quit}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{k.Up, k.Down, k.Select, k.Back, k.Quit}}
+}
+
+var keys = keyMap{
+	Up:     key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:   key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	Select: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "compare")),
+	Back:   key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+	Quit:   key.NewBinding(key.WithKeys("q", "Q"), key.WithHelp("q", "quit")),
+}
+
+// jobsLoadedMsg carries the indexed jobs the head can be compared with.
+type jobsLoadedMsg struct {
+	jobs []index.Job
+	err  error
+}
+
+// diffLoadedMsg carries the computed diff.
+type diffLoadedMsg struct {
+	diff findings.Diff
+	err  error
+}
+
+// Deps abstracts the results index and the head job's results for
+// testability.
+type Deps struct {
+	Jobs      func() ([]index.Job, error)
+	BaseTasks func(jobID string) ([]findings.Task, error)
+	HeadTasks func(ctx context.Context) ([]findings.Task, error)
+}
+
+// DefaultDeps reads base jobs from the default results index and the head
+// job from the index when it is indexed, else from source.
+func DefaultDeps(headJobID string, source core.ResultsSource) Deps {
+	indexedTasks := func(jobID string) ([]findings.Task, error) {
+		db, err := index.OpenDefault()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = db.Close() }()
+		return db.Tasks(jobID)
+	}
+	return Deps{
+		Jobs: func() ([]index.Job, error) {
+			db, err := index.OpenDefault()
+			if err != nil {
+				return nil, err
+			}
+			defer func() { _ = db.Close() }()
+			return db.Jobs()
+		},
+		BaseTasks: indexedTasks,
+		HeadTasks: func(ctx context.Context) ([]findings.Task, error) {
+			if tasks, err := indexedTasks(headJobID); err == nil {
+				return tasks, nil
+			}
+			if s3, ok := source.(*core.S3ResultsSource); ok && s3.Storage == nil {
+				return nil, fmt.Errorf("job %s is not indexed and its cloud storage is unavailable", headJobID)
+			}
+			src, ok := source.(findings.Source)
+			if !ok {
+				return nil, fmt.Errorf("results of %s cannot be read", headJobID)
+			}
+			return findings.Collect(ctx, src)
+		},
+	}
+}
+
+// Model lets the operator pick an earlier job and shows how the current
+// job's findings differ from it.
+type Model struct {
+	req  core.DiffRequest
+	deps Deps
+	help help.Model
+
+	jobs    []index.Job // candidate base jobs, most recent first
+	loaded  bool
+	cursor  int
+	loading bool
+	diff    *findings.Diff
+	vp      viewport.Model
+	errMsg  string
+
+	width  int
+	height int
+}
+
+// New creates a diff view for the job in req.
+func New(req core.DiffRequest, deps Deps) *Model {
+	h := help.New()
+	h.Styles = help.Styles{
+		ShortKey:       lipgloss.NewStyle().Foreground(core.Steel),
+		ShortDesc:      lipgloss.NewStyle().Foreground(core.Steel),
+		ShortSeparator: lipgloss.NewStyle().Foreground(core.Steel),
+		FullKey:        lipgloss.NewStyle().Foreground(core.Steel),
+		FullDesc:       lipgloss.NewStyle().Foreground(core.Steel),
+		FullSeparator:  lipgloss.NewStyle().Foreground(core.Steel),
+		Ellipsis:       lipgloss.NewStyle().Foreground(core.Steel),
+	}
+	vp := viewport.New()
+	vp.SetWidth(80)
+	vp.SetHeight(20)
+	return &Model{req: req, deps: deps, help: h, vp: vp}
+}
+
+func (m *Model) Init() tea.Cmd {
+	deps := m.deps
+	tool, head := m.req.Tool, m.req.Infra.JobID
+	return func() tea.Msg {
+		all, err := deps.Jobs()
+		if err != nil {
+			return jobsLoadedMsg{err: err}
+		}
+		var jobs []index.Job
+		for _, j := range all {
+			if strings.EqualFold(j.Tool, tool) && j.JobID != head {
+				jobs = append(jobs, j)
+			}
+		}
+		return jobsLoadedMsg{jobs: jobs}
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (core.View, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.SetWidth(msg.Width)
+		m.vp.SetWidth(msg.Width - 4)
+		m.vp.SetHeight(max(msg.Height-10, 5))
+		m.renderDiff()
+		return m, nil
+
+	case jobsLoadedMsg:
+		m.loaded = true
+		m.jobs = msg.jobs
+		if msg.err != nil {
+			m.errMsg = fmt.Sprintf("Error reading the results index: %v", msg.err)
+		}
+		return m, nil
+
+	case diffLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errMsg = fmt.Sprintf("Error comparing jobs: %v", msg.err)
+			return m, nil
+		}
+		m.errMsg = ""
+		m.diff = &msg.diff
+		m.renderDiff()
+		return m, nil
+
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "q", "Q":
+			return m, tea.Quit
+		case "esc":
+			if m.diff != nil {
+				m.diff = nil
+				return m, nil
+			}
+			req := m.req
+			return m, func() tea.Msg {
+				return core.NavigateWithDataMsg{Target: req.Back, Data: req.Infra}
+			}
+		}
+		if m.diff != nil {
+			var cmd tea.Cmd
+			m.vp, cmd = m.vp.Update(msg)
+			return m, cmd
+		}
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.jobs)-1 {
+				m.cursor++
+			}
+		case "enter":
+			if len(m.jobs) > 0 && !m.loading {
+				m.loading = true
+				return m, m.compare(m.jobs[m.cursor].JobID)
+			}
+		}
+	}
+	return m, nil
+}
+
+// compare diffs the head job against base off the UI goroutine.
+func (m *Model) compare(base string) tea.Cmd {
+	deps := m.deps
+	tool, head := m.req.Tool, m.req.Infra.JobID
+	return func() tea.Msg {
+		baseTasks, err := deps.BaseTasks(base)
+		if err != nil {
+			return diffLoadedMsg{err: err}
+		}
+		headTasks, err := deps.HeadTasks(context.Background())
+		if err != nil {
+			return diffLoadedMsg{err: err}
+		}
+		d := findings.Compare(taskFindings(baseTasks), taskFindings(headTasks))
+		d.Tool, d.Base, d.Head = tool, base, head
+		return diffLoadedMsg{diff: d}
+	}
+}
+
+func taskFindings(tasks []findings.Task) []findings.Finding {
+	var out []findings.Finding
+	for _, t := range tasks {
+		out = append(out, t.Findings...)
+	}
+	return out
+}
+
+var (
+	addedStyle   = lipgloss.NewStyle().Foreground(core.Gold)
+	removedStyle = lipgloss.NewStyle().Foreground(core.Ember)
+	fieldStyle   = lipgloss.NewStyle().Foreground(core.Ember).Bold(true)
+	headingStyle = lipgloss.NewStyle().Foreground(core.Gold).Bold(true)
+)
+
+// renderDiff lays the diff out in the viewport, one section per finding
+// type, with the changed attributes of each changed finding highlighted.
+func (m *Model) renderDiff() {
+	if m.diff == nil {
+		return
+	}
+	d := *m.diff
+	var b strings.Builder
+	if d.Empty() {
+		b.WriteString(core.MutedStyle.Render("No differences.") + "\n")
+	}
+	for _, t := range d.Types() {
+		added, removed, changed := d.Of(t)
+		b.WriteString(headingStyle.Render(t.Label()) + "\n")
+		for _, f := range added {
+			b.WriteString(addedStyle.Render(fmt.Sprintf("+ %s  %s", f.Location(), findings.Brief(f))) + "\n")
+		}
+		for _, f := range removed {
+			b.WriteString(removedStyle.Render(fmt.Sprintf("- %s  %s", f.Location(), findings.Brief(f))) + "\n")
+		}
+		for _, c := range changed {
+			line := core.NormalStyle.Render("~ " + c.Head.Location())
+			if c.Head.Check != "" {
+				line += core.NormalStyle.Render("  " + c.Head.Check)
+			}
+			for _, field := range c.Fields {
+				before, after := c.Values(field)
+				line += "  " + fieldStyle.Render(field+":") + " " + core.MutedStyle.Render(before) + " → " + core.NormalStyle.Render(after)
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n")
+	}
+	m.vp.SetContent(b.String())
+	m.vp.GotoTop()
+}
+
+func (m *Model) View() string {
+	var b strings.Builder
+	b.WriteString(core.TitleBarStyle.Render(fmt.Sprintf("  Diff %s %s  ", m.req.Tool, m.req.Infra.JobID)))
+	b.WriteString("\n\n")
+
+	switch {
+	case m.diff != nil:
+		b.WriteString("  " + core.MutedStyle.Render(fmt.Sprintf("%s -> %s: %s", m.diff.Base, m.diff.Head, m.diff.Summary())) + "\n\n")
+		b.WriteString(m.vp.View())
+	case m.errMsg != "":
+		b.WriteString("  " + core.ErrorStyle.Render(m.errMsg) + "\n")
+	case !m.loaded:
+		b.WriteString("  " + core.MutedStyle.Render("Reading the results index...") + "\n")
+	case len(m.jobs) == 0:
+		b.WriteString("  " + core.MutedStyle.Render(fmt.Sprintf("No other %s jobs are indexed. Index one with: heph index --job-id <id>", m.req.Tool)) + "\n")
+	default:
+		b.WriteString("  " + core.MutedStyle.Render("Compare with which earlier job?") + "\n\n")
+		for i, j := range m.jobs {
+			when := ""
+			if !j.Finished.IsZero() {
+				when = j.Finished.Local().Format("2006-01-02 15:04")
+			}
+			line := fmt.Sprintf("  %-40s %-16s %d findings", j.JobID, when, j.Findings)
+			if i == m.cursor {
+				b.WriteString(core.SelectedStyle.Render("► "+line[2:]) + "\n")
+			} else {
+				b.WriteString(core.NormalStyle.Render(line) + "\n")
+			}
+		}
+		if m.loading {
+			b.WriteString("\n  " + core.MutedStyle.Render("Comparing...") + "\n")
+		}
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(core.StatusBarStyle.Render(m.help.View(keys)))
+	return b.String()
+}
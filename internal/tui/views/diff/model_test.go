@@ -0,0 +1,107 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"heph4estus/internal/findings"
+	"heph4estus/internal/index"
+	"heph4estus/internal/tui/core"
+)
+
+func port(asset string, p int, service string) findings.Finding {
+	return findings.Finding{Tool: "nmap", Type: findings.TypeOpenPort, Severity: findings.SeverityInfo, Asset: asset, Port: p, Protocol: "tcp", Service: service}
+}
+
+func testDeps(baseErr error) Deps {
+	return Deps{
+		Jobs: func() ([]index.Job, error) {
+			return []index.Job{
+				{JobID: "nmap-3", Tool: "nmap"},
+				{JobID: "httpx-2", Tool: "httpx"},
+				{JobID: "nmap-1", Tool: "nmap", Finished: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Findings: 2},
+			}, nil
+		},
+		BaseTasks: func(jobID string) ([]findings.Task, error) {
+			if baseErr != nil {
+				return nil, baseErr
+			}
+			return []findings.Task{{Findings: []findings.Finding{port("10.0.0.1", 80, "http"), port("10.0.0.2", 22, "ssh")}}}, nil
+		},
+		HeadTasks: func(context.Context) ([]findings.Task, error) {
+			return []findings.Task{{Findings: []findings.Finding{port("10.0.0.1", 80, "http-proxy"), port("10.0.0.3", 8443, "https")}}}, nil
+		},
+	}
+}
+
+func newModel(deps Deps) *Model {
+	m := New(core.DiffRequest{
+		Infra: core.InfraOutputs{JobID: "nmap-3", ToolName: "nmap"},
+		Tool:  "nmap",
+		Back:  core.ViewNmapResults,
+	}, deps)
+	m.Update(m.Init()())
+	return m
+}
+
+func TestDiffPicksEarlierJobAndShowsChanges(t *testing.T) {
+	m := newModel(testDeps(nil))
+	if len(m.jobs) != 1 || m.jobs[0].JobID != "nmap-1" {
+		t.Fatalf("candidate jobs = %+v; want other nmap jobs only", m.jobs)
+	}
+	if v := m.View(); !strings.Contains(v, "nmap-1") || !strings.Contains(v, "2 findings") {
+		t.Errorf("picker view:\n%s", v)
+	}
+
+	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter should start the comparison")
+	}
+	m.Update(cmd())
+	if m.diff == nil {
+		t.Fatalf("no diff: %s", m.errMsg)
+	}
+	v := m.View()
+	for _, want := range []string{"nmap-1 -> nmap-3", "1 new, 1 removed, 1 changed", "Open ports", "+ 10.0.0.3:8443", "- 10.0.0.2:22", "~ 10.0.0.1:80", "service:", "http-proxy"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("diff view lacks %q:\n%s", want, v)
+		}
+	}
+
+	// esc leaves the diff, then returns to the results view.
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.diff != nil {
+		t.Fatal("esc should close the diff")
+	}
+	_, cmd = m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	nav, ok := cmd().(core.NavigateWithDataMsg)
+	if !ok || nav.Target != core.ViewNmapResults {
+		t.Fatalf("expected navigation back to results, got %#v", nav)
+	}
+	if infra, ok := nav.Data.(core.InfraOutputs); !ok || infra.JobID != "nmap-3" {
+		t.Errorf("back data = %#v", nav.Data)
+	}
+}
+
+func TestDiffErrorsAndEmptyIndex(t *testing.T) {
+	m := newModel(testDeps(errors.New("job nmap-1 is not indexed")))
+	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m.Update(cmd())
+	if v := m.View(); !strings.Contains(v, "not indexed") {
+		t.Errorf("expected error:\n%s", v)
+	}
+
+	deps := testDeps(nil)
+	deps.Jobs = func() ([]index.Job, error) { return nil, nil }
+	m = newModel(deps)
+	if v := m.View(); !strings.Contains(v, "No other nmap jobs are indexed") {
+		t.Errorf("expected empty hint:\n%s", v)
+	}
+	if _, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); cmd != nil {
+		t.Error("enter without jobs should do nothing")
+	}
+}
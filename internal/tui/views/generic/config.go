@@ -2,7 +2,6 @@ package generic
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 
@@ -17,6 +16,7 @@ import (
 	"heph4estus/internal/infra"
 	"heph4estus/internal/modules"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/targets"
 	wordlisttool "heph4estus/internal/tools/wordlist"
 	"heph4estus/internal/tui/core"
 )
@@ -307,11 +307,21 @@ func (m *ConfigModel) handleTargetListFileRead(msg fileReadMsg) tea.Cmd {
 		m.errMsg = placementErr.Error()
 		return nil
 	}
+	budget, budgetErr := operator.ResolveBudget(operator.Budget{}, opCfg)
+	if budgetErr != nil {
+		m.errMsg = budgetErr.Error()
+		return nil
+	}
+	window, windowErr := operator.ResolveWindow("", "", "", opCfg)
+	if windowErr != nil {
+		m.errMsg = windowErr.Error()
+		return nil
+	}
 	toolOptions := strings.TrimSpace(m.inputs[cfgFieldOptions].Value())
 
-	if cloudKind.IsSelfhostedFamily() && !cloudKind.IsProviderNative() {
-		// Manual selfhosted: bypass deploy view, go directly to status.
-		shCfg := factory.SelfhostedConfigFromEnv()
+	if cloudKind.IsLocal() || (cloudKind.IsSelfhostedFamily() && !cloudKind.IsProviderNative()) {
+		// Manual selfhosted and local: bypass deploy view, go directly to status.
+		shCfg := factory.DirectRuntimeConfigFromEnv(cloudKind)
 		if shCfg.QueueID == "" || shCfg.Bucket == "" {
 			m.errMsg = fmt.Sprintf("%s requires SELFHOSTED_QUEUE_ID and SELFHOSTED_BUCKET environment variables", cloudKind.Canonical())
 			return nil
@@ -327,6 +337,8 @@ func (m *ConfigModel) handleTargetListFileRead(msg fileReadMsg) tea.Cmd {
 					WorkerCount:    workerCount,
 					ComputeMode:    computeMode,
 					Placement:      placement,
+					Budget:         budget,
+					Window:         window,
 					ToolName:       m.toolName,
 					ToolOptions:    toolOptions,
 					CleanupPolicy:  cleanupPolicy,
@@ -363,6 +375,8 @@ func (m *ConfigModel) handleTargetListFileRead(msg fileReadMsg) tea.Cmd {
 				WorkerCount:    workerCount,
 				ComputeMode:    computeMode,
 				Placement:      placement,
+				Budget:         budget,
+				Window:         window,
 				ToolName:       m.toolName,
 				ToolOptions:    toolOptions,
 				PostDeployView: core.ViewGenericStatus,
@@ -414,11 +428,21 @@ func (m *ConfigModel) handleWordlistFileRead(msg wordlistReadMsg) tea.Cmd {
 		m.errMsg = placementErr.Error()
 		return nil
 	}
+	budget, budgetErr := operator.ResolveBudget(operator.Budget{}, wlCfg)
+	if budgetErr != nil {
+		m.errMsg = budgetErr.Error()
+		return nil
+	}
+	window, windowErr := operator.ResolveWindow("", "", "", wlCfg)
+	if windowErr != nil {
+		m.errMsg = windowErr.Error()
+		return nil
+	}
 	toolOptions := strings.TrimSpace(m.wlInputs[wlFieldOptions].Value())
 
-	if cloudKind.IsSelfhostedFamily() && !cloudKind.IsProviderNative() {
-		// Manual selfhosted: bypass deploy view, go directly to status.
-		shCfg := factory.SelfhostedConfigFromEnv()
+	if cloudKind.IsLocal() || (cloudKind.IsSelfhostedFamily() && !cloudKind.IsProviderNative()) {
+		// Manual selfhosted and local: bypass deploy view, go directly to status.
+		shCfg := factory.DirectRuntimeConfigFromEnv(cloudKind)
 		if shCfg.QueueID == "" || shCfg.Bucket == "" {
 			m.errMsg = fmt.Sprintf("%s requires SELFHOSTED_QUEUE_ID and SELFHOSTED_BUCKET environment variables", cloudKind.Canonical())
 			return nil
@@ -433,6 +457,8 @@ func (m *ConfigModel) handleWordlistFileRead(msg wordlistReadMsg) tea.Cmd {
 					WorkerCount:     workerCount,
 					ComputeMode:     computeMode,
 					Placement:       placement,
+					Budget:          budget,
+					Window:          window,
 					ToolName:        m.toolName,
 					ToolOptions:     toolOptions,
 					WordlistPath:    msg.path,
@@ -472,6 +498,8 @@ func (m *ConfigModel) handleWordlistFileRead(msg wordlistReadMsg) tea.Cmd {
 				WorkerCount:     workerCount,
 				ComputeMode:     computeMode,
 				Placement:       placement,
+				Budget:          budget,
+				Window:          window,
 				ToolName:        m.toolName,
 				ToolOptions:     toolOptions,
 				PostDeployView:  core.ViewGenericStatus,
@@ -609,11 +637,11 @@ func (m *ConfigModel) submitTargetList() tea.Cmd {
 	}
 	m.errMsg = ""
 	return func() tea.Msg {
-		data, err := os.ReadFile(path)
+		entries, err := targets.ImportFile(path, "")
 		if err != nil {
 			return fileReadMsg{err: err}
 		}
-		return fileReadMsg{content: string(data)}
+		return fileReadMsg{content: targets.TextLines(entries)}
 	}
 }
 
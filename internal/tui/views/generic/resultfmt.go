@@ -10,6 +10,8 @@ import (
 	"sort"
 	"strings"
 
+	"heph4estus/internal/findings"
+	"heph4estus/internal/targets"
 	"heph4estus/internal/tui/core"
 	"heph4estus/internal/worker"
 )
@@ -112,10 +114,49 @@ func formatToolArtifact(r worker.Result, artifact []byte) (string, string, error
 		body, err := formatMasscanArtifact(artifact)
 		return "Masscan Open Ports", body, err
 	default:
-		return "Raw Artifact", rawArtifact(artifact), nil
+		if !findings.Has(r.ToolName) {
+			return "Raw Artifact", rawArtifact(artifact), nil
+		}
+		body, err := formatFindings(r, artifact)
+		return strings.ToUpper(r.ToolName) + " Findings", body, err
 	}
 }
 
+// formatFindings renders the normalized findings of a tool without a
+// dedicated formatter.
+func formatFindings(r worker.Result, artifact []byte) (string, error) {
+	fs, err := findings.Parse(findings.OriginOf(r), artifact)
+	if err != nil {
+		return "", err
+	}
+	if len(fs) == 0 {
+		return "No findings.", nil
+	}
+	findings.Sort(fs)
+
+	lines := []string{
+		fmt.Sprintf("%-12s %-8s %-44s %s", "TYPE", "SEVERITY", "LOCATION", "DETAIL"),
+		fmt.Sprintf("%-12s %-8s %-44s %s", strings.Repeat("-", 12), strings.Repeat("-", 8), strings.Repeat("-", 44), strings.Repeat("-", 30)),
+	}
+	for _, f := range fs {
+		location := f.URL
+		if location == "" {
+			location = f.Asset
+			if f.Port > 0 {
+				location = fmt.Sprintf("%s:%d", f.Asset, f.Port)
+			}
+		}
+		detail := strings.Join(nonEmpty(f.Check, f.Title, f.Evidence), " | ")
+		lines = append(lines, fmt.Sprintf("%-12s %-8s %-44s %s",
+			string(f.Type),
+			strings.ToUpper(string(f.Severity)),
+			clipText(location, 44),
+			clipText(firstNonEmpty(detail, "-"), 80),
+		))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 type nmapXMLRun struct {
 	Hosts []nmapXMLHost `xml:"host"`
 }
@@ -317,26 +358,10 @@ func formatFFUFArtifact(artifact []byte) (string, error) {
 	return strings.Join(lines, "\n"), nil
 }
 
-type masscanRecord struct {
-	IP    string        `json:"ip"`
-	Ports []masscanPort `json:"ports"`
-}
-
-type masscanPort struct {
-	Port   int    `json:"port"`
-	Proto  string `json:"proto"`
-	Status string `json:"status"`
-	Reason string `json:"reason"`
-}
-
 func formatMasscanArtifact(artifact []byte) (string, error) {
-	var records []masscanRecord
-	if err := json.Unmarshal(artifact, &records); err != nil {
-		jsonlRecords, jsonlErr := parseJSONLines[masscanRecord](artifact)
-		if jsonlErr != nil {
-			return "", err
-		}
-		records = jsonlRecords
+	records, err := targets.ParseMasscan(artifact)
+	if err != nil {
+		return "", err
 	}
 	if len(records) == 0 {
 		return "No masscan ports found.", nil
@@ -76,6 +76,22 @@ func TestFormatMasscanArtifact(t *testing.T) {
 	}
 }
 
+func TestFormatToolArtifactUsesFindings(t *testing.T) {
+	r := worker.Result{ToolName: "gobuster", Target: "https://example.com/"}
+	title, body, err := formatToolArtifact(r, []byte("/admin                (Status: 301) [Size: 178] [--> https://example.com/admin/]\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if title != "GOBUSTER Findings" {
+		t.Errorf("title = %q", title)
+	}
+	for _, want := range []string{"path", "INFO", "https://example.com/admin", "status 301"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in gobuster output:\n%s", want, body)
+		}
+	}
+}
+
 func TestFormatResultMalformedArtifactFallsBackToRaw(t *testing.T) {
 	result := worker.Result{
 		ToolName:  "nuclei",
@@ -12,6 +12,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"heph4estus/internal/jobs"
+	"heph4estus/internal/tools/dns"
 	"heph4estus/internal/tui/core"
 	"heph4estus/internal/worker"
 )
@@ -40,6 +41,12 @@ type destroyCompleteMsg struct {
 	err error
 }
 
+// wildcardsLoadedMsg carries the wildcard DNS answers the job's probes
+// found.
+type wildcardsLoadedMsg struct {
+	wildcards dns.Wildcards
+}
+
 type resultsKeyMap struct {
 	Up      key.Binding
 	Down    key.Binding
@@ -47,16 +54,17 @@ type resultsKeyMap struct {
 	Next    key.Binding
 	Prev    key.Binding
 	Destroy key.Binding
+	Compare key.Binding
 	Back    key.Binding
 	Quit    key.Binding
 }
 
 func (k resultsKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Up, k.Down, k.Enter, k.Next, k.Prev, k.Destroy, k.Back, k.Quit}
+	return []key.Binding{k.Up, k.Down, k.Enter, k.Next, k.Prev, k.Destroy, k.Compare, k.Back, k.Quit}
 }
 
 func (k resultsKeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{{k.Up, k.Down, k.Enter, k.Next, k.Prev, k.Destroy, k.Back, k.Quit}}
+	return [][]key.Binding{{k.Up, k.Down, k.Enter, k.Next, k.Prev, k.Destroy, k.Compare, k.Back, k.Quit}}
 }
 
 var resultsKeys = resultsKeyMap{
@@ -66,6 +74,7 @@ var resultsKeys = resultsKeyMap{
 	Next:    key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next page")),
 	Prev:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "prev page")),
 	Destroy: key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "destroy infra")),
+	Compare: key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "diff vs earlier job")),
 	Back:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
 	Quit:    key.NewBinding(key.WithKeys("q", "Q"), key.WithHelp("q", "quit")),
 }
@@ -82,6 +91,7 @@ type ResultsModel struct {
 	cursor     int
 	results    map[string]*worker.Result
 	details    map[string]string
+	wildcards  dns.Wildcards // domains with wildcard DNS, flagged in the list
 	detail     bool
 	detailVP   viewport.Model
 	destroying bool
@@ -159,6 +169,11 @@ func (m *ResultsModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
 			}
 		case "q", "Q":
 			return m, tea.Quit
+		case "c":
+			req := core.DiffRequest{Infra: m.infra, Tool: m.infra.ToolName, Back: core.ViewGenericResults}
+			return m, func() tea.Msg {
+				return core.NavigateWithDataMsg{Target: core.ViewDiff, Data: req}
+			}
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
@@ -206,7 +221,10 @@ func (m *ResultsModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
 		}
 		m.allKeys = msg.keys
 		m.total = msg.total
-		return m, m.loadPageStatuses()
+		return m, tea.Batch(m.loadPageStatuses(), m.loadWildcards())
+
+	case wildcardsLoadedMsg:
+		m.wildcards = msg.wildcards
 
 	case pageStatusesMsg:
 		for k, r := range msg.statuses {
@@ -281,6 +299,12 @@ func (m *ResultsModel) View() string {
 		}
 	}
 
+	if len(m.wildcards) > 0 && !m.detail {
+		warnStyle := lipgloss.NewStyle().Foreground(core.Ember)
+		b.WriteString("  " + warnStyle.Render("Wildcard DNS: "+m.wildcards.String()) + "\n")
+		b.WriteString("  " + core.MutedStyle.Render("Results under these domains are flagged; answers matching the wildcard are dropped when merged") + "\n\n")
+	}
+
 	if m.detail {
 		b.WriteString(m.detailVP.View())
 		b.WriteString("\n\n")
@@ -319,6 +343,11 @@ func (m *ResultsModel) View() string {
 				}
 
 				line := fmt.Sprintf("  %-40s %-10s %-8s", truncate(target, 38), chunkLabel, status)
+				if dns.IsProbe(target) {
+					line += " probe"
+				} else if m.wildcards.Domain(target) != "" {
+					line += " wildcard"
+				}
 				if i == m.cursor {
 					b.WriteString(core.SelectedStyle.Render("► "+line[2:]) + "\n")
 				} else {
@@ -419,6 +448,39 @@ func (m *ResultsModel) loadPageStatuses() tea.Cmd {
 	}
 }
 
+// loadWildcards reads the job's wildcard DNS probe results, if it ran any,
+// and collects the answers their reports hold.
+func (m *ResultsModel) loadWildcards() tea.Cmd {
+	var probeKeys []string
+	for _, k := range m.allKeys {
+		if strings.HasPrefix(k, dns.ProbeGroup+"/") || strings.Contains(k, "/"+dns.ProbeGroup+"/") {
+			probeKeys = append(probeKeys, k)
+		}
+	}
+	if len(probeKeys) == 0 {
+		return nil
+	}
+	src := m.source
+	return func() tea.Msg {
+		ctx := context.Background()
+		reports := make(map[string][]byte, len(probeKeys))
+		for _, k := range probeKeys {
+			data, err := src.Download(ctx, k)
+			if err != nil {
+				continue
+			}
+			var result worker.Result
+			if err := json.Unmarshal(data, &result); err != nil || result.OutputKey == "" {
+				continue
+			}
+			if report, err := downloadResultArtifact(ctx, src, result.OutputKey); err == nil {
+				reports[k] = report
+			}
+		}
+		return wildcardsLoadedMsg{wildcards: dns.DetectWildcards(reports)}
+	}
+}
+
 func (m *ResultsModel) loadDetail() tea.Cmd {
 	pk := m.pageKeys()
 	if m.cursor >= len(pk) {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"heph4estus/internal/tools/dns"
 	"heph4estus/internal/tui/core"
 	"heph4estus/internal/worker"
 )
@@ -101,6 +102,41 @@ func TestGenericResultsInit(t *testing.T) {
 	}
 }
 
+func TestGenericResultsFlagsWildcardDomains(t *testing.T) {
+	infra := testResultInfra()
+	infra.ToolName = "dnsx"
+	probe := dns.ProbeName(infra.JobID, "example.com", 0)
+	probeKey := dns.ProbeGroup + "/" + probe + "_chunk0_of_1_1700000000.json"
+	hostKey := "junk.example.com_1700000001.json"
+	pr, _ := json.Marshal(worker.Result{Target: probe, OutputKey: "artifacts/probe.jsonl", Timestamp: time.Now()})
+	hr, _ := json.Marshal(worker.Result{Target: "junk.example.com", Timestamp: time.Now()})
+	source := &mockResultsSource{
+		keys:      []string{probeKey, hostKey},
+		data:      map[string][]byte{probeKey: pr, hostKey: hr},
+		artifacts: map[string][]byte{"artifacts/probe.jsonl": []byte(`{"host":"` + probe + `","a":["1.2.3.4"]}` + "\n")},
+	}
+	m := NewResults(infra, source, nil)
+	_, cmd := m.Update(m.Init()())
+	if cmd == nil {
+		t.Fatal("expected status and wildcard load commands")
+	}
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("expected two batched commands, got %T", cmd())
+	}
+	for _, c := range batch {
+		m.Update(c())
+	}
+
+	if m.wildcards.String() != "example.com (1.2.3.4)" {
+		t.Fatalf("wildcards = %q", m.wildcards)
+	}
+	v := m.View()
+	if !strings.Contains(v, "Wildcard DNS: example.com (1.2.3.4)") || !strings.Contains(v, " wildcard") || !strings.Contains(v, " probe") {
+		t.Errorf("view does not flag wildcard domains:\n%s", v)
+	}
+}
+
 func TestGenericResultsViewContainsToolName(t *testing.T) {
 	source := &mockResultsSource{keys: []string{}}
 	m := NewResults(testResultInfra(), source, nil)
@@ -242,6 +278,22 @@ func TestGenericResultsEscNavigatesBack(t *testing.T) {
 	}
 }
 
+func TestGenericResultsCompareOpensDiff(t *testing.T) {
+	m := NewResults(testResultInfra(), &mockResultsSource{}, nil)
+	_, cmd := m.Update(tea.KeyPressMsg{Code: 'c', Text: "c"})
+	if cmd == nil {
+		t.Fatal("expected command from c")
+	}
+	nav, ok := cmd().(core.NavigateWithDataMsg)
+	if !ok || nav.Target != core.ViewDiff {
+		t.Fatalf("expected NavigateWithDataMsg to ViewDiff, got %#v", nav)
+	}
+	req, ok := nav.Data.(core.DiffRequest)
+	if !ok || req.Tool != testResultInfra().ToolName || req.Back != core.ViewGenericResults || req.Infra.JobID != testResultInfra().JobID {
+		t.Errorf("diff request = %#v", nav.Data)
+	}
+}
+
 func TestGenericResultsListError(t *testing.T) {
 	source := &mockResultsSource{listErr: fmt.Errorf("access denied")}
 	m := NewResults(testResultInfra(), source, nil)
@@ -2,10 +2,10 @@ package generic
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -14,45 +14,43 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"heph4estus/internal/cloud"
-	awscloud "heph4estus/internal/cloud/aws"
 	"heph4estus/internal/jobs"
+	"heph4estus/internal/modules"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/runner"
+	"heph4estus/internal/targets"
 	"heph4estus/internal/tui/core"
-	"heph4estus/internal/worker"
 )
 
 type statusPhase int
 
 const (
-	phaseUploading statusPhase = iota // wordlist only: uploading chunks
-	phaseEnqueuing
-	phaseLaunching
-	phaseScanning
-	phaseExporting  // exporting results locally before cleanup
-	phaseDestroying // auto-destroying infrastructure after export
+	phaseStarting   statusPhase = iota // runner uploading, enqueueing and launching
+	phaseScanning                      // runner waiting for results
+	phaseExporting                     // exporting results locally before cleanup
+	phaseDestroying                    // auto-destroying infrastructure after export
 	phaseComplete
+	phaseCancelled // stopped by the job budget
 )
 
-type enqueueProgressMsg struct {
-	sent  int
-	total int
-	err   error
-}
-
-type launchProgressMsg struct {
-	launched int
-	total    int
-	err      error
+// runnerLogMsg is a status line the runner logged.
+type runnerLogMsg struct {
+	line string
 }
 
-type spotLaunchMsg struct {
-	launchProgressMsg
-	instanceIDs []string
+// startedMsg reports that Runner.Start returned.
+type startedMsg struct {
+	err error
 }
 
+// scanProgressMsg reports one result count observed by Runner.Wait.
 type scanProgressMsg struct {
 	completed int
-	err       error
+}
+
+// scanDoneMsg reports that Runner.Wait returned.
+type scanDoneMsg struct {
+	err error
 }
 
 type exportCompleteMsg struct {
@@ -61,80 +59,36 @@ type exportCompleteMsg struct {
 	err   error
 }
 
+// budgetStopMsg reports the cleanup after a budget breach cancelled the job.
+type budgetStopMsg struct {
+	destroyErr error
+	destroyed  bool
+}
+
 // autoDestroyCompleteMsg reports the outcome of auto-destroy in the status view.
 type autoDestroyCompleteMsg struct {
 	err error
 }
 
-type uploadCompleteMsg struct {
-	tasks []worker.Task
-	words int
-	err   error
-}
-
-const SpotThreshold = 50
-
-// GenericSubmitter abstracts target enqueueing and worker launching for generic tools.
-type GenericSubmitter interface {
-	EnqueueTasks(ctx context.Context, queueURL string, tasks []worker.Task) error
-	LaunchWorkers(ctx context.Context, opts cloud.ContainerOpts) (string, error)
-	LaunchSpotWorkers(ctx context.Context, opts cloud.SpotOpts) ([]string, error)
-}
-
-// GenericTracker abstracts result counting.
-type GenericTracker interface {
-	CountResults(ctx context.Context, bucket, prefix string) (int, error)
-}
+const SpotThreshold = runner.SpotThreshold
 
-// GenericUploader abstracts chunk uploads to storage.
-type GenericUploader interface {
-	UploadChunks(ctx context.Context, bucket string, plan *jobs.WordlistPlan) error
-}
-
-type realUploader struct {
-	storage cloud.Storage
-}
-
-func (u *realUploader) UploadChunks(ctx context.Context, bucket string, plan *jobs.WordlistPlan) error {
-	return jobs.UploadChunks(ctx, u.storage, bucket, plan)
-}
-
-type realSubmitter struct {
-	queue   cloud.Queue
-	compute cloud.Compute
-}
-
-func (s *realSubmitter) EnqueueTasks(ctx context.Context, queueURL string, tasks []worker.Task) error {
-	bodies := make([]string, len(tasks))
-	for i, t := range tasks {
-		b, err := json.Marshal(t)
-		if err != nil {
-			return fmt.Errorf("marshal task %d: %w", i, err)
-		}
-		bodies[i] = string(b)
-	}
-	return s.queue.SendBatch(ctx, queueURL, bodies)
-}
-
-func (s *realSubmitter) LaunchWorkers(ctx context.Context, opts cloud.ContainerOpts) (string, error) {
-	return s.compute.RunContainer(ctx, opts)
-}
-
-func (s *realSubmitter) LaunchSpotWorkers(ctx context.Context, opts cloud.SpotOpts) ([]string, error) {
-	return s.compute.RunSpotInstances(ctx, opts)
-}
+// CounterThreshold is the target count at or above which progress is read
+// from a ProgressCounter, when one is provided, instead of Storage.Count().
+const CounterThreshold = 10_000
 
+// realTracker is the storage the runner counts results in. At or above
+// CounterThreshold it answers counts from the progress counter.
 type realTracker struct {
+	cloud.Storage
 	counter    cloud.ProgressCounter
-	storage    cloud.Storage
 	useCounter bool
 }
 
-func (t *realTracker) CountResults(ctx context.Context, bucket, prefix string) (int, error) {
+func (t *realTracker) Count(ctx context.Context, bucket, prefix string) (int, error) {
 	if t.useCounter {
 		return t.counter.Get(ctx, bucket)
 	}
-	return t.storage.Count(ctx, bucket, prefix)
+	return t.Storage.Count(ctx, bucket, prefix)
 }
 
 type statusKeyMap struct {
@@ -155,28 +109,29 @@ var statusKeys = statusKeyMap{
 	Quit: key.NewBinding(key.WithKeys("q", "Q"), key.WithHelp("q", "quit")),
 }
 
-// StatusModel displays enqueue -> launch -> scan progress for generic tools.
+// StatusModel drives a generic tool job through runner.Runner and displays
+// its progress.
 type StatusModel struct {
-	submitter  GenericSubmitter
-	tracker    GenericTracker
-	uploader   GenericUploader
+	runner     *runner.Runner
+	runnerErr  error
+	plan       *runner.Plan
+	events     chan tea.Msg // runner log lines, progress and outcomes
 	jobTracker *operator.Tracker
-	storage    cloud.Storage  // for local export on completion
 	destroyer  core.Destroyer // for auto-destroy after export (nil = no destroy)
 	infra      core.InfraOutputs
 
 	phase        statusPhase
 	isWordlist   bool
-	totalTargets int // for target_list: target count; for wordlist: chunk count
-	totalWords   int // only for wordlist jobs
-	enqueueSent  int
+	spillDir     string // temp dir holding WordlistContent, if it was spilled
+	totalTargets int    // for target_list: target count; for wordlist: chunk count
+	totalWords   int    // only for wordlist jobs
 	workersUp    int
 	completed    int
 	startTime    time.Time
+	lastLog      string
 	errMsg       string
 
-	spotInstanceIDs []string
-	rateSamples     []rateSample
+	rateSamples []rateSample
 
 	// Cleanup / export state
 	cleanupWarning string
@@ -194,21 +149,18 @@ type rateSample struct {
 // NewStatus creates a status view with real cloud clients.
 func NewStatus(infra core.InfraOutputs, q cloud.Queue, s cloud.Storage, c cloud.Compute, counter cloud.ProgressCounter, jt *operator.Tracker, destroyer core.Destroyer) *StatusModel {
 	targets := parseTargetLines(infra.TargetsContent)
-	useCounter := counter != nil && len(targets) >= 10_000
+	useCounter := counter != nil && len(targets) >= CounterThreshold
 
 	m := NewStatusWithDeps(infra,
-		&realSubmitter{queue: q, compute: c},
-		&realTracker{counter: counter, storage: s, useCounter: useCounter},
-		&realUploader{storage: s},
+		runner.Services{Q: q, S: &realTracker{Storage: s, counter: counter, useCounter: useCounter}, C: c},
 		jt,
 	)
-	m.storage = s
 	m.destroyer = destroyer
 	return m
 }
 
-// NewStatusWithDeps creates a status view with injected dependencies (for testing).
-func NewStatusWithDeps(infra core.InfraOutputs, sub GenericSubmitter, tracker GenericTracker, uploader GenericUploader, jt ...*operator.Tracker) *StatusModel {
+// NewStatusWithDeps creates a status view over an injected provider (for testing).
+func NewStatusWithDeps(infra core.InfraOutputs, provider cloud.Provider, jt ...*operator.Tracker) *StatusModel {
 	h := help.New()
 	h.Styles = help.Styles{
 		ShortKey:       lipgloss.NewStyle().Foreground(core.Steel),
@@ -226,21 +178,24 @@ func NewStatusWithDeps(infra core.InfraOutputs, sub GenericSubmitter, tracker Ge
 	if len(jt) > 0 && jt[0] != nil {
 		jobTracker = jt[0]
 	}
-	return &StatusModel{
-		submitter:  sub,
-		tracker:    tracker,
-		uploader:   uploader,
+	m := &StatusModel{
+		events:     make(chan tea.Msg, 64),
 		jobTracker: jobTracker,
 		infra:      infra,
 		isWordlist: isWL,
 		startTime:  time.Now(),
 		help:       h,
 	}
+	m.runner, m.runnerErr = core.NewRunner(infra, provider, jobTracker, m.logf)
+	return m
 }
 
-func (m *StatusModel) trackPhase(phase operator.Phase) {
-	if m.jobTracker != nil && m.infra.JobID != "" {
-		_ = m.jobTracker.UpdatePhase(m.infra.JobID, phase)
+// logf hands a runner status line to the view. Lines are dropped rather
+// than blocking the runner when the view falls behind.
+func (m *StatusModel) logf(format string, args ...any) {
+	select {
+	case m.events <- runnerLogMsg{line: fmt.Sprintf(format, args...)}:
+	default:
 	}
 }
 
@@ -251,151 +206,158 @@ func (m *StatusModel) trackFail(err error) {
 	}
 }
 
-func (m *StatusModel) trackCreate() {
-	if m.jobTracker == nil || m.infra.JobID == "" {
-		return
-	}
-	rec := &operator.JobRecord{
-		JobID:                 m.infra.JobID,
-		ToolName:              m.infra.ToolName,
-		Phase:                 operator.PhaseEnqueuing,
-		TotalTasks:            m.totalTargets,
-		TotalWords:            m.totalWords,
-		WorkerCount:           m.infra.WorkerCount,
-		ComputeMode:           m.infra.ComputeMode,
-		Cloud:                 string(m.infra.Cloud),
-		Bucket:                m.infra.S3BucketName,
-		Placement:             m.infra.Placement,
-		ExpectedWorkerVersion: m.infra.ExpectedWorkerVersion,
-		RuntimeTarget:         m.infra.RuntimeTarget,
-		NATSUrl:               m.infra.NATSUrl,
-		ControllerIP:          m.infra.ControllerIP,
-		GenerationID:          m.infra.GenerationID,
-		ControllerCAPEM:       m.infra.ControllerCAPEM,
-		ControllerHost:        m.infra.ControllerHost,
-		NATSClientCertPEM:     m.infra.NATSClientCertPEM,
-		NATSClientKeyPEM:      m.infra.NATSClientKeyPEM,
-	}
-	if m.isWordlist {
-		rec.Phase = operator.PhaseUploading
-	}
-	_ = m.jobTracker.Create(rec)
-}
-
 func (m *StatusModel) Init() tea.Cmd {
+	if m.runnerErr != nil {
+		m.errMsg = fmt.Sprintf("Runner error: %v", m.runnerErr)
+		return nil
+	}
 	if m.infra.JobID == "" {
 		m.infra.JobID = jobs.NewID(m.infra.ToolName)
 	}
 
-	if m.isWordlist {
-		return m.initWordlist()
+	cfg, err := m.jobConfig()
+	if err != nil {
+		m.errMsg = err.Error()
+		return nil
 	}
-	return m.initTargetList()
-}
-
-func (m *StatusModel) initTargetList() tea.Cmd {
-	targets := parseTargetLines(m.infra.TargetsContent)
-
-	tasks := make([]worker.Task, len(targets))
-	for i, t := range targets {
-		tasks[i] = worker.Task{
-			ToolName: m.infra.ToolName,
-			JobID:    m.infra.JobID,
-			Target:   t,
-			Options:  m.infra.ToolOptions,
-		}
+	if cfg.WordlistPath != "" && cfg.WordlistPath != m.infra.WordlistPath {
+		// The content was spilled to a temp file, which a calibrated job
+		// reads again when it starts.
+		m.spillDir = filepath.Dir(cfg.WordlistPath)
 	}
-	m.totalTargets = len(tasks)
-
-	if m.totalTargets == 0 {
-		m.errMsg = "No targets found"
+	plan, err := m.runner.Plan(cfg, m.infra.JobID)
+	if err != nil {
+		m.removeSpill()
+		m.errMsg = fmt.Sprintf("Planning failed: %v", err)
 		return nil
 	}
-
-	m.phase = phaseEnqueuing
-	m.trackCreate()
-	infra := m.infra
-	sub := m.submitter
-	return func() tea.Msg {
-		err := sub.EnqueueTasks(context.Background(), infra.SQSQueueURL, tasks)
-		return enqueueProgressMsg{sent: len(tasks), total: len(tasks), err: err}
+	m.plan = plan
+	m.totalTargets = len(plan.Tasks)
+	if plan.Wordlist != nil {
+		m.totalWords = plan.Wordlist.TotalWords
+	}
+	if m.jobTracker != nil {
+		_ = m.jobTracker.Create(m.runner.NewRecord(plan))
 	}
+
+	m.phase = phaseStarting
+	return tea.Batch(m.start(), m.listen())
 }
 
-func (m *StatusModel) initWordlist() tea.Cmd {
+// jobConfig describes the job for the runner: the wordlist to chunk, or the
+// target lines normalized to the kinds the tool's module accepts. The
+// operator profile's scope, if any, is enforced at planning.
+func (m *StatusModel) jobConfig() (jobs.JobConfig, error) {
 	infra := m.infra
-	uploader := m.uploader
-
-	plan, tempDir, err := m.planWordlist(infra)
+	sc, err := operator.LoadProfileScope()
 	if err != nil {
-		m.errMsg = fmt.Sprintf("Wordlist error: %v", err)
-		return nil
+		return jobs.JobConfig{}, fmt.Errorf("Scope check failed: %v", err)
 	}
+	cfg := jobs.JobConfig{ToolName: infra.ToolName, Options: infra.ToolOptions, Scope: sc}
 
-	m.totalTargets = len(plan.Tasks)
-	m.totalWords = plan.TotalWords
-	m.phase = phaseUploading
-	m.trackCreate()
-
-	return func() (msg tea.Msg) {
-		defer func() {
-			if tempDir != "" {
-				if err := os.RemoveAll(tempDir); err != nil {
-					msg = uploadCleanupError(msg, fmt.Errorf("removing wordlist temp dir: %w", err))
-				}
-			}
-		}()
-		defer func() {
-			if err := plan.Cleanup(); err != nil {
-				msg = uploadCleanupError(msg, fmt.Errorf("cleaning wordlist chunks: %w", err))
+	if m.isWordlist {
+		cfg.RuntimeTarget = infra.RuntimeTarget
+		cfg.ChunkCount = infra.ChunkCount
+		cfg.WordlistPath = infra.WordlistPath
+		if cfg.WordlistPath == "" {
+			path, err := spillWordlist(infra.WordlistContent)
+			if err != nil {
+				return jobs.JobConfig{}, fmt.Errorf("Wordlist error: %v", err)
 			}
-		}()
-		if err := uploader.UploadChunks(context.Background(), infra.S3BucketName, plan); err != nil {
-			return uploadCompleteMsg{err: err}
+			cfg.WordlistPath = path
 		}
-		return uploadCompleteMsg{tasks: plan.Tasks, words: plan.TotalWords}
+		return cfg, nil
+	}
+
+	entries, err := targets.Import(strings.NewReader(infra.TargetsContent), targets.FormatText)
+	if err != nil {
+		return jobs.JobConfig{}, fmt.Errorf("Target error: %v", err)
 	}
+	if len(entries) == 0 {
+		return jobs.JobConfig{}, fmt.Errorf("No targets found")
+	}
+	cfg.Entries = entries
+	if reg, err := modules.NewDefaultRegistry(); err == nil {
+		if mod, err := reg.Get(infra.ToolName); err == nil {
+			opts := mod.TargetOptions()
+			cfg.TargetOptions = &opts
+			cfg.WildcardProbe = mod.ResolvesDNS()
+		}
+	}
+	return cfg, nil
 }
 
-func uploadCleanupError(msg tea.Msg, err error) tea.Msg {
-	if err == nil {
-		return msg
+// spillWordlist writes wordlist content held in memory to a temp file for
+// the runner to chunk.
+func spillWordlist(content string) (string, error) {
+	dir, err := os.MkdirTemp("", "heph-wordlist-*")
+	if err != nil {
+		return "", fmt.Errorf("creating temp dir: %w", err)
 	}
-	if current, ok := msg.(uploadCompleteMsg); ok && current.err != nil {
-		return msg
+	path := filepath.Join(dir, "wordlist.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("writing wordlist: %w", err)
 	}
-	return uploadCompleteMsg{err: err}
+	return path, nil
 }
 
-func (m *StatusModel) planWordlist(infra core.InfraOutputs) (*jobs.WordlistPlan, string, error) {
-	if infra.WordlistPath != "" {
-		tempDir, err := os.MkdirTemp("", "heph-wordlist-*")
-		if err != nil {
-			return nil, "", fmt.Errorf("creating temp dir: %w", err)
+// removeSpill removes the temp file wordlist content was written to.
+func (m *StatusModel) removeSpill() {
+	if m.spillDir != "" {
+		_ = os.RemoveAll(m.spillDir)
+	}
+}
+
+// start uploads, enqueues and launches through the runner, which records
+// any failure on the job.
+func (m *StatusModel) start() tea.Cmd {
+	r, plan, events := m.runner, m.plan, m.events
+	return func() tea.Msg {
+		err := r.Start(context.Background(), plan)
+		if cleanupErr := plan.Cleanup(); cleanupErr != nil && err == nil {
+			m.logf("Warning: %v", cleanupErr)
 		}
-		plan, err := jobs.PlanWordlistFile(
-			infra.ToolName, infra.JobID,
-			infra.RuntimeTarget, infra.ToolOptions,
-			infra.WordlistPath, tempDir,
-			infra.ChunkCount, infra.WorkerCount,
-		)
-		if err != nil {
-			_ = os.RemoveAll(tempDir)
-			return nil, "", err
+		m.removeSpill()
+		events <- startedMsg{err: err}
+		return nil
+	}
+}
+
+// waitForResults waits through the runner, which enforces the job budget,
+// and reports progress and the outcome as events.
+func (m *StatusModel) waitForResults() tea.Cmd {
+	r, plan, events := m.runner, m.plan, m.events
+	logf := m.logf
+	return func() tea.Msg {
+		ctx := context.Background()
+		err := r.Wait(ctx, plan.ToolName, plan.JobID, len(plan.Tasks), func(p runner.Progress) {
+			select {
+			case events <- scanProgressMsg{completed: p.Completed}:
+			default:
+			}
+		})
+		if err == nil && plan.WildcardProbes > 0 {
+			if _, wErr := r.RecordWildcards(ctx, plan.ToolName, plan.JobID); wErr != nil {
+				logf("Warning: %v", wErr)
+			}
 		}
-		return plan, tempDir, nil
+		events <- scanDoneMsg{err: err}
+		return nil
 	}
+}
 
-	chunkCount := infra.ChunkCount
-	if chunkCount <= 0 {
-		chunkCount = infra.WorkerCount
+// listen delivers the next runner event.
+func (m *StatusModel) listen() tea.Cmd {
+	events := m.events
+	return func() tea.Msg {
+		return <-events
 	}
-	plan, err := jobs.PlanWordlistJob(
-		infra.ToolName, infra.JobID,
-		infra.RuntimeTarget, infra.ToolOptions,
-		infra.WordlistContent, chunkCount,
-	)
-	return plan, "", err
+}
+
+// parseTargetLines splits content into non-empty, non-comment target lines.
+func parseTargetLines(content string) []string {
+	return jobs.ParseTargetLines(content)
 }
 
 func (m *StatusModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
@@ -415,89 +377,65 @@ func (m *StatusModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
 			return m, tea.Quit
 		}
 
-	case uploadCompleteMsg:
-		if msg.err != nil {
-			m.errMsg = fmt.Sprintf("Upload failed: %v", msg.err)
-			m.trackFail(msg.err)
-			return m, nil
-		}
-		m.totalWords = msg.words
-		m.totalTargets = len(msg.tasks)
-		m.phase = phaseEnqueuing
-		m.trackPhase(operator.PhaseEnqueuing)
-		infra := m.infra
-		sub := m.submitter
-		tasks := msg.tasks
-		return m, func() tea.Msg {
-			err := sub.EnqueueTasks(context.Background(), infra.SQSQueueURL, tasks)
-			return enqueueProgressMsg{sent: len(tasks), total: len(tasks), err: err}
-		}
+	case runnerLogMsg:
+		m.lastLog = msg.line
+		return m, m.listen()
 
-	case enqueueProgressMsg:
+	case startedMsg:
 		if msg.err != nil {
-			m.errMsg = fmt.Sprintf("Enqueue failed: %v", msg.err)
-			m.trackFail(msg.err)
+			m.errMsg = fmt.Sprintf("Start failed: %v", msg.err)
 			return m, nil
 		}
-		m.enqueueSent = msg.sent
-		m.phase = phaseLaunching
-		m.trackPhase(operator.PhaseLaunching)
-		return m, m.launchWorkers()
+		m.totalTargets = len(m.plan.Tasks)
+		m.workersUp = m.runner.WorkersUp()
+		m.phase = phaseScanning
+		return m, tea.Batch(m.waitForResults(), m.listen())
 
-	case spotLaunchMsg:
-		if msg.err != nil {
-			m.errMsg = fmt.Sprintf("Spot launch failed: %v", msg.err)
-			m.trackFail(msg.err)
-			return m, nil
+	case scanProgressMsg:
+		m.completed = msg.completed
+		m.rateSamples = append(m.rateSamples, rateSample{time: time.Now(), count: msg.completed})
+		cutoff := time.Now().Add(-30 * time.Second)
+		for len(m.rateSamples) > 1 && m.rateSamples[0].time.Before(cutoff) {
+			m.rateSamples = m.rateSamples[1:]
 		}
-		m.spotInstanceIDs = msg.instanceIDs
-		m.workersUp = msg.launched
-		m.phase = phaseScanning
-		m.trackPhase(operator.PhaseScanning)
-		return m, m.pollProgress()
+		return m, m.listen()
 
-	case launchProgressMsg:
+	case scanDoneMsg:
+		if errors.Is(msg.err, operator.ErrBudgetExceeded) {
+			return m, m.stopForBudget(msg.err)
+		}
 		if msg.err != nil {
-			m.errMsg = fmt.Sprintf("Launch failed: %v", msg.err)
+			m.errMsg = fmt.Sprintf("Scan failed: %v", msg.err)
 			m.trackFail(msg.err)
 			return m, nil
 		}
-		m.workersUp = msg.launched
-		m.phase = phaseScanning
-		m.trackPhase(operator.PhaseScanning)
-		return m, m.pollProgress()
-
-	case scanProgressMsg:
-		if msg.err != nil {
-			m.errMsg = fmt.Sprintf("Progress check failed: %v", msg.err)
-		} else {
-			m.completed = msg.completed
-			m.rateSamples = append(m.rateSamples, rateSample{time: time.Now(), count: msg.completed})
-			cutoff := time.Now().Add(-30 * time.Second)
-			for len(m.rateSamples) > 1 && m.rateSamples[0].time.Before(cutoff) {
-				m.rateSamples = m.rateSamples[1:]
+		if m.jobTracker != nil && m.infra.JobID != "" {
+			_ = m.jobTracker.Complete(m.infra.JobID)
+		}
+		if m.shouldExport() {
+			m.phase = phaseExporting
+			return m, m.exportResults()
+		}
+		if m.infra.CleanupPolicy == "destroy-after" {
+			if m.infra.Cloud.IsLocal() {
+				m.cleanupWarning = "destroy-after skipped: local runs without infrastructure"
+			} else if m.infra.Cloud.IsSelfhostedFamily() && !m.infra.Cloud.IsProviderNative() {
+				m.cleanupWarning = "destroy-after skipped: selfhosted does not support auto-destroy"
+			} else if m.infra.OutputDir == "" {
+				m.cleanupWarning = "destroy-after skipped: no output directory configured"
 			}
 		}
+		m.phase = phaseComplete
+		return m, m.navigateToResults()
 
-		if m.completed >= m.totalTargets {
-			if m.jobTracker != nil && m.infra.JobID != "" {
-				_ = m.jobTracker.Complete(m.infra.JobID)
-			}
-			if m.shouldExport() {
-				m.phase = phaseExporting
-				return m, m.exportResults()
-			}
-			if m.infra.CleanupPolicy == "destroy-after" {
-				if m.infra.Cloud.IsSelfhostedFamily() && !m.infra.Cloud.IsProviderNative() {
-					m.cleanupWarning = "destroy-after skipped: selfhosted does not support auto-destroy"
-				} else if m.infra.OutputDir == "" {
-					m.cleanupWarning = "destroy-after skipped: no output directory configured"
-				}
-			}
-			m.phase = phaseComplete
-			return m, m.navigateToResults()
+	case budgetStopMsg:
+		if msg.destroyErr != nil {
+			m.infra.DestroyErr = msg.destroyErr.Error()
+			m.cleanupWarning = fmt.Sprintf("destroy failed: %v", msg.destroyErr)
 		}
-		return m, m.pollProgress()
+		m.infra.Destroyed = msg.destroyed
+		m.phase = phaseCancelled
+		return m, nil
 
 	case exportCompleteMsg:
 		if msg.err != nil {
@@ -508,6 +446,11 @@ func (m *StatusModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
 		m.infra.Exported = true
 		m.infra.ExportDir = msg.dir
 		// Auto-destroy if destroy-after policy and destroyer is available.
+		if m.infra.Cloud.IsLocal() {
+			m.cleanupWarning = "destroy-after skipped: local runs without infrastructure"
+			m.phase = phaseComplete
+			return m, m.navigateToResults()
+		}
 		if m.infra.Cloud.IsSelfhostedFamily() && !m.infra.Cloud.IsProviderNative() {
 			m.cleanupWarning = "destroy-after skipped: selfhosted does not support auto-destroy"
 			m.phase = phaseComplete
@@ -557,6 +500,12 @@ func (m *StatusModel) View() string {
 	if m.infra.Placement.Summary() != "" {
 		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Placement:"), m.infra.Placement.Summary())
 	}
+	if !m.infra.Budget.IsZero() {
+		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Budget:"), m.infra.Budget)
+	}
+	if m.infra.Window != nil {
+		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Window:"), m.infra.Window)
+	}
 	b.WriteString("\n")
 
 	unitLabel := "targets"
@@ -565,28 +514,17 @@ func (m *StatusModel) View() string {
 	}
 
 	switch m.phase {
-	case phaseUploading:
-		b.WriteString(core.SelectedStyle.Render("  Uploading wordlist chunks...") + "\n\n")
-		if m.infra.RuntimeTarget != "" {
-			fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Target:"), m.infra.RuntimeTarget)
-		}
-		fmt.Fprintf(&b, "  %s%d\n", labelStyle.Render("Chunks:"), m.totalTargets)
-		fmt.Fprintf(&b, "  %s%d\n", labelStyle.Render("Words:"), m.totalWords)
-		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Elapsed:"), elapsed.String())
-
-	case phaseEnqueuing:
-		b.WriteString(core.SelectedStyle.Render("  Enqueueing "+unitLabel+"...") + "\n\n")
+	case phaseStarting:
+		b.WriteString(core.SelectedStyle.Render("  Starting job...") + "\n\n")
 		if m.isWordlist && m.infra.RuntimeTarget != "" {
 			fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Target:"), m.infra.RuntimeTarget)
 			fmt.Fprintf(&b, "  %s%d\n", labelStyle.Render("Words:"), m.totalWords)
 		}
-		fmt.Fprintf(&b, "  %s%d\n", labelStyle.Render("Tasks:"), m.totalTargets)
-		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Elapsed:"), elapsed.String())
-
-	case phaseLaunching:
-		b.WriteString(core.SelectedStyle.Render("  Launching workers...") + "\n\n")
-		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Tasks:"), fmt.Sprintf("%d enqueued", m.enqueueSent))
-		fmt.Fprintf(&b, "  %s%d / %d\n", labelStyle.Render("Workers:"), m.workersUp, m.infra.WorkerCount)
+		fmt.Fprintf(&b, "  %s%d %s\n", labelStyle.Render("Tasks:"), m.totalTargets, unitLabel)
+		fmt.Fprintf(&b, "  %s%d\n", labelStyle.Render("Workers:"), m.infra.WorkerCount)
+		if m.lastLog != "" {
+			fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Status:"), m.lastLog)
+		}
 		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Elapsed:"), elapsed.String())
 
 	case phaseScanning:
@@ -594,7 +532,11 @@ func (m *StatusModel) View() string {
 		bar := progressBar(m.completed, m.totalTargets, 30)
 		rate, eta := m.calcRateETA()
 
-		b.WriteString(core.SelectedStyle.Render("  Scanning") + "\n\n")
+		if !m.infra.Window.Open(time.Now()) {
+			b.WriteString(core.SelectedStyle.Render("  Paused: outside scan window") + "\n\n")
+		} else {
+			b.WriteString(core.SelectedStyle.Render("  Scanning") + "\n\n")
+		}
 		if m.isWordlist && m.infra.RuntimeTarget != "" {
 			fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Target:"), m.infra.RuntimeTarget)
 			fmt.Fprintf(&b, "  %s%d\n", labelStyle.Render("Words:"), m.totalWords)
@@ -637,6 +579,14 @@ func (m *StatusModel) View() string {
 		if m.infra.Destroyed {
 			fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Infra:"), "destroyed")
 		}
+
+	case phaseCancelled:
+		b.WriteString(core.ErrorStyle.Render("  Scan cancelled: budget exceeded") + "\n\n")
+		fmt.Fprintf(&b, "  %s%d / %d\n", labelStyle.Render("Completed:"), m.completed, m.totalTargets)
+		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Elapsed:"), elapsed.String())
+		if m.infra.Destroyed {
+			fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Infra:"), "destroyed")
+		}
 	}
 
 	if m.cleanupWarning != "" {
@@ -657,107 +607,40 @@ func (m *StatusModel) View() string {
 	return content
 }
 
-func useSpot(infra core.InfraOutputs) bool {
-	// Selfhosted only supports RunContainer (no spot instances).
-	if infra.Cloud.IsSelfhostedFamily() {
-		return false
-	}
-	switch infra.ComputeMode {
-	case "spot":
-		return true
-	case "fargate":
-		return false
-	default:
-		return infra.WorkerCount >= SpotThreshold
+// stopForBudget reports a budget breach. The runner has already stopped
+// the workers and failed the job; when the budget asks for it, the
+// infrastructure is destroyed too.
+func (m *StatusModel) stopForBudget(reason error) tea.Cmd {
+	m.errMsg = reason.Error()
+	var destroyer core.Destroyer
+	if m.infra.Budget.Destroy && !m.infra.Cloud.IsLocal() && !(m.infra.Cloud.IsSelfhostedFamily() && !m.infra.Cloud.IsProviderNative()) {
+		destroyer = m.destroyer
 	}
-}
-
-func (m *StatusModel) launchWorkers() tea.Cmd {
-	infra := m.infra
-	sub := m.submitter
-
-	if useSpot(infra) {
-		return m.launchSpotWorkers()
-	}
-	if infra.Cloud.IsProviderNative() {
-		return func() tea.Msg {
-			launched := infra.FleetWorkerCount
-			if launched <= 0 {
-				launched = infra.WorkerCount
-			}
-			if launched <= 0 {
-				launched = 1
-			}
-			return launchProgressMsg{launched: launched, total: launched}
-		}
-	}
-
-	return func() tea.Msg {
-		_, err := sub.LaunchWorkers(context.Background(), cloud.ContainerOpts{
-			Cluster:        infra.ECSClusterName,
-			TaskDefinition: infra.TaskDefinitionARN,
-			ContainerName:  fmt.Sprintf("%s-worker", infra.ToolName),
-			Subnets:        infra.SubnetIDs,
-			SecurityGroups: []string{infra.SecurityGroupID},
-			Env: map[string]string{
-				"QUEUE_URL":          infra.SQSQueueURL,
-				"S3_BUCKET":          infra.S3BucketName,
-				"TOOL_NAME":          infra.ToolName,
-				"JITTER_MAX_SECONDS": strconv.Itoa(infra.JitterMaxSeconds),
-			},
-			Count: infra.WorkerCount,
-		})
-		return launchProgressMsg{launched: infra.WorkerCount, total: infra.WorkerCount, err: err}
+	if destroyer == nil {
+		m.phase = phaseCancelled
+		return nil
 	}
-}
-
-func (m *StatusModel) launchSpotWorkers() tea.Cmd {
-	infra := m.infra
-	sub := m.submitter
+	m.phase = phaseDestroying
 	return func() tea.Msg {
-		userData := awscloud.GenerateUserData(awscloud.UserDataOpts{
-			ECRRepoURL: infra.ECRRepoURL,
-			ImageTag:   "latest",
-			Region:     regionFromECR(infra.ECRRepoURL),
-			EnvVars: map[string]string{
-				"QUEUE_URL":          infra.SQSQueueURL,
-				"S3_BUCKET":          infra.S3BucketName,
-				"TOOL_NAME":          infra.ToolName,
-				"JITTER_MAX_SECONDS": strconv.Itoa(infra.JitterMaxSeconds),
-			},
-		})
-		ids, err := sub.LaunchSpotWorkers(context.Background(), cloud.SpotOpts{
-			AMI:             infra.AMIID,
-			Count:           infra.WorkerCount,
-			SecurityGroups:  []string{infra.SecurityGroupID},
-			SubnetIDs:       infra.SubnetIDs,
-			InstanceProfile: infra.InstanceProfileARN,
-			UserData:        userData,
-			Tags: map[string]string{
-				"Project": "heph4estus",
-				"Tool":    infra.ToolName,
-			},
-		})
-		msg := launchProgressMsg{launched: len(ids), total: infra.WorkerCount, err: err}
-		return spotLaunchMsg{launchProgressMsg: msg, instanceIDs: ids}
+		err := destroyer.Destroy(context.Background())
+		return budgetStopMsg{destroyErr: err, destroyed: err == nil}
 	}
 }
 
 func (m *StatusModel) shouldExport() bool {
-	return m.infra.CleanupPolicy == "destroy-after" && m.infra.OutputDir != "" && m.storage != nil
+	return m.infra.CleanupPolicy == "destroy-after" && m.infra.OutputDir != "" && m.runner != nil
 }
 
 func (m *StatusModel) exportResults() tea.Cmd {
-	storage := m.storage
+	r := m.runner
 	infra := m.infra
 	return func() tea.Msg {
-		result, err := operator.ExportJob(
-			context.Background(), storage,
-			infra.S3BucketName, infra.ToolName, infra.JobID, infra.OutputDir,
-		)
+		result, err := r.Export(context.Background(), infra.ToolName, infra.JobID, infra.OutputDir)
 		if err != nil {
 			return exportCompleteMsg{err: err}
 		}
+		// Indexing is best-effort; heph index can retry it.
+		_, _ = core.IndexExport(context.Background(), infra.JobID, result.Dir)
 		return exportCompleteMsg{dir: result.Dir, count: result.ResultCount + result.ArtifactCount}
 	}
 }
@@ -780,15 +663,6 @@ func (m *StatusModel) navigateToResults() tea.Cmd {
 	}
 }
 
-func (m *StatusModel) pollProgress() tea.Cmd {
-	infra := m.infra
-	tracker := m.tracker
-	return tea.Tick(2*time.Second, func(time.Time) tea.Msg {
-		count, err := tracker.CountResults(context.Background(), infra.S3BucketName, jobs.ResultPrefix(infra.ToolName, infra.JobID))
-		return scanProgressMsg{completed: count, err: err}
-	})
-}
-
 func (m *StatusModel) calcRateETA() (targetsPerMin float64, remaining time.Duration) {
 	if len(m.rateSamples) < 2 {
 		return 0, 0
@@ -816,26 +690,3 @@ func progressBar(current, total, width int) string {
 	filled := min(current*width/total, width)
 	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
 }
-
-func regionFromECR(url string) string {
-	parts := strings.Split(url, ".")
-	for i, p := range parts {
-		if p == "ecr" && i+1 < len(parts) {
-			return parts[i+1]
-		}
-	}
-	return "us-east-1"
-}
-
-// parseTargetLines splits content into non-empty, non-comment target lines.
-func parseTargetLines(content string) []string {
-	var targets []string
-	for _, line := range strings.Split(content, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		targets = append(targets, line)
-	}
-	return targets
-}
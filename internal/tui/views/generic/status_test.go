@@ -2,67 +2,148 @@ package generic
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"heph4estus/internal/cloud"
-	"heph4estus/internal/jobs"
+	"heph4estus/internal/cloud/mock"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/runner"
+	"heph4estus/internal/tools/dns"
 	"heph4estus/internal/tui/core"
 	"heph4estus/internal/worker"
 
 	tea "charm.land/bubbletea/v2"
 )
 
-// mockUploader records chunk uploads.
-type mockUploader struct {
-	uploaded bool
-	err      error
-	plan     *jobs.WordlistPlan
-}
-
-func (u *mockUploader) UploadChunks(_ context.Context, _ string, plan *jobs.WordlistPlan) error {
-	u.uploaded = true
-	u.plan = plan
-	return u.err
-}
-
-// mockSubmitter records calls and returns configured results.
-type mockSubmitter struct {
-	enqueuedTasks []worker.Task
-	enqueueErr    error
-	launchErr     error
-	spotErr       error
-	launchCalls   int
-	spotCalls     int
-}
-
-func (s *mockSubmitter) EnqueueTasks(_ context.Context, _ string, tasks []worker.Task) error {
-	s.enqueuedTasks = tasks
-	return s.enqueueErr
-}
-
-func (s *mockSubmitter) LaunchWorkers(_ context.Context, _ cloud.ContainerOpts) (string, error) {
-	s.launchCalls++
-	return "task-123", s.launchErr
-}
-
-func (s *mockSubmitter) LaunchSpotWorkers(_ context.Context, _ cloud.SpotOpts) ([]string, error) {
-	s.spotCalls++
-	return []string{"i-123"}, s.spotErr
-}
-
-// mockTracker returns a configured count.
-type mockTracker struct {
-	count int
-	err   error
+// fakeCloud records the runner's calls against in-memory storage and queue
+// state.
+type fakeCloud struct {
+	mu         sync.Mutex
+	objects    map[string][]byte
+	enqueued   []worker.Task
+	enqueueErr error
+	containers int
+	spots      int
+	stopped    []string
+	// results is what Count reports.
+	results int
+}
+
+func newFakeCloud() *fakeCloud {
+	return &fakeCloud{objects: map[string][]byte{}}
+}
+
+func (f *fakeCloud) provider() runner.Services {
+	return runner.Services{
+		S: &mock.Storage{
+			UploadFunc: func(_ context.Context, _, key string, data []byte) error {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.objects[key] = data
+				return nil
+			},
+			DownloadFunc: func(_ context.Context, _, key string) ([]byte, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				data, ok := f.objects[key]
+				if !ok {
+					return nil, fmt.Errorf("not found: %s", key)
+				}
+				return data, nil
+			},
+			ListFunc: func(_ context.Context, _, prefix string) ([]string, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				var keys []string
+				for k := range f.objects {
+					if strings.HasPrefix(k, prefix) {
+						keys = append(keys, k)
+					}
+				}
+				return keys, nil
+			},
+			CountFunc: func(context.Context, string, string) (int, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				return f.results, nil
+			},
+		},
+		Q: &mock.Queue{
+			SendBatchFunc: func(_ context.Context, _ string, bodies []string) error {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				if f.enqueueErr != nil {
+					return f.enqueueErr
+				}
+				for _, body := range bodies {
+					var task worker.Task
+					if err := json.Unmarshal([]byte(body), &task); err != nil {
+						return err
+					}
+					f.enqueued = append(f.enqueued, task)
+				}
+				return nil
+			},
+		},
+		C: &mock.Compute{
+			RunContainerFunc: func(context.Context, cloud.ContainerOpts) (string, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.containers++
+				return "task-123", nil
+			},
+			RunSpotInstancesFunc: func(context.Context, cloud.SpotOpts) ([]string, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.spots++
+				return []string{"i-123"}, nil
+			},
+			StopWorkersFunc: func(_ context.Context, ids []string) error {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.stopped = append(f.stopped, ids...)
+				return nil
+			},
+		},
+	}
+}
+
+// await feeds runner events to m until one of type T arrives and returns
+// the command m answered it with.
+func await[T tea.Msg](t *testing.T, m *StatusModel) (T, tea.Cmd) {
+	t.Helper()
+	for {
+		select {
+		case msg := <-m.events:
+			_, cmd := m.Update(msg)
+			if got, ok := msg.(T); ok {
+				return got, cmd
+			}
+		case <-time.After(5 * time.Second):
+			var zero T
+			t.Fatalf("timed out waiting for %T", zero)
+			return zero, nil
+		}
+	}
 }
 
-func (t *mockTracker) CountResults(_ context.Context, _, _ string) (int, error) {
-	return t.count, t.err
+// startJob runs Init and Runner.Start and returns m in the scanning phase.
+func startJob(t *testing.T, m *StatusModel) {
+	t.Helper()
+	if cmd := m.Init(); cmd == nil {
+		t.Fatalf("expected init command, error %q", m.errMsg)
+	}
+	m.start()()
+	if started, _ := await[startedMsg](t, m); started.err != nil {
+		t.Fatalf("start: %v", started.err)
+	}
 }
 
 func testInfra() core.InfraOutputs {
@@ -79,9 +160,8 @@ func testInfra() core.InfraOutputs {
 }
 
 func TestGenericStatusInit(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(testInfra(), sub, tracker, &mockUploader{})
+	f := newFakeCloud()
+	m := NewStatusWithDeps(testInfra(), f.provider())
 
 	cmd := m.Init()
 	if cmd == nil {
@@ -90,36 +170,51 @@ func TestGenericStatusInit(t *testing.T) {
 	if m.totalTargets != 2 {
 		t.Fatalf("expected 2 targets, got %d", m.totalTargets)
 	}
-	if m.phase != phaseEnqueuing {
-		t.Fatalf("expected phaseEnqueuing, got %d", m.phase)
+	if m.phase != phaseStarting {
+		t.Fatalf("expected phaseStarting, got %d", m.phase)
 	}
 
-	// Execute the enqueue command.
-	msg := cmd()
-	ep, ok := msg.(enqueueProgressMsg)
-	if !ok {
-		t.Fatalf("expected enqueueProgressMsg, got %T", msg)
-	}
-	if ep.sent != 2 {
-		t.Fatalf("expected 2 sent, got %d", ep.sent)
+	m.start()()
+	if started, _ := await[startedMsg](t, m); started.err != nil {
+		t.Fatalf("unexpected start error: %v", started.err)
 	}
 
 	// Verify tasks were created with correct fields.
-	if len(sub.enqueuedTasks) != 2 {
-		t.Fatalf("expected 2 enqueued tasks, got %d", len(sub.enqueuedTasks))
+	if len(f.enqueued) != 2 {
+		t.Fatalf("expected 2 enqueued tasks, got %d", len(f.enqueued))
 	}
-	task := sub.enqueuedTasks[0]
+	task := f.enqueued[0]
 	if task.ToolName != "httpx" {
 		t.Errorf("task.ToolName = %q, want httpx", task.ToolName)
 	}
-	if task.Target != "example.com" {
-		t.Errorf("task.Target = %q, want example.com", task.Target)
+	// httpx accepts URLs, so bare host names are converted during planning.
+	if task.Target != "https://example.com" {
+		t.Errorf("task.Target = %q, want https://example.com", task.Target)
 	}
 	if task.Options != "-silent" {
 		t.Errorf("task.Options = %q, want -silent", task.Options)
 	}
 }
 
+func TestGenericStatusInitProbesWildcardDNS(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	infra := testInfra()
+	infra.ToolName = "dnsx"
+	infra.ToolOptions = ""
+	infra.JobID = "dnsx-job"
+	infra.TargetsContent = "www.example.com\napi.example.com\n"
+	f := newFakeCloud()
+	m := NewStatusWithDeps(infra, f.provider())
+
+	startJob(t, m)
+	if m.totalTargets != 2+dns.ProbesPerZone || m.plan.WildcardProbes != dns.ProbesPerZone {
+		t.Fatalf("total = %d, wildcard probes = %d", m.totalTargets, m.plan.WildcardProbes)
+	}
+	if probe := f.enqueued[0]; probe.GroupID != dns.ProbeGroup || probe.Target != dns.ProbeName("dnsx-job", "example.com", 0) {
+		t.Errorf("first task = %+v, want the wildcard probe", probe)
+	}
+}
+
 func TestGenericStatusTrackCreatePersistsNATSClientIdentity(t *testing.T) {
 	infra := testInfra()
 	infra.JobID = "httpx-job"
@@ -131,7 +226,7 @@ func TestGenericStatusTrackCreatePersistsNATSClientIdentity(t *testing.T) {
 
 	store := operator.NewJobStoreAt(t.TempDir())
 	tracker := operator.NewTracker(store)
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{}, tracker)
+	m := NewStatusWithDeps(infra, newFakeCloud().provider(), tracker)
 	_ = m.Init()
 
 	rec, err := store.Load(infra.JobID)
@@ -143,52 +238,84 @@ func TestGenericStatusTrackCreatePersistsNATSClientIdentity(t *testing.T) {
 	}
 }
 
-func TestGenericStatusEnqueueToLaunch(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(testInfra(), sub, tracker, &mockUploader{})
+func TestGenericStatusStartToScanning(t *testing.T) {
+	f := newFakeCloud()
+	m := NewStatusWithDeps(testInfra(), f.provider())
 	m.Init()
+	m.start()()
 
-	_, cmd := m.Update(enqueueProgressMsg{sent: 2, total: 2})
-	if m.phase != phaseLaunching {
-		t.Fatalf("expected phaseLaunching, got %d", m.phase)
+	_, cmd := await[startedMsg](t, m)
+	if m.phase != phaseScanning {
+		t.Fatalf("expected phaseScanning, got %d", m.phase)
+	}
+	if m.workersUp != 5 {
+		t.Fatalf("expected 5 workers up, got %d", m.workersUp)
+	}
+	if f.containers != 1 || f.spots != 0 {
+		t.Fatalf("expected one container launch, got %d containers %d spot", f.containers, f.spots)
 	}
 	if cmd == nil {
-		t.Fatal("expected launch command")
+		t.Fatal("expected wait command")
 	}
 }
 
-func TestGenericStatusLaunchToScanning(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(testInfra(), sub, tracker, &mockUploader{})
+func TestGenericStatusLogShowsWhileStarting(t *testing.T) {
+	m := NewStatusWithDeps(testInfra(), newFakeCloud().provider())
 	m.Init()
-	m.Update(enqueueProgressMsg{sent: 2, total: 2})
 
-	_, cmd := m.Update(launchProgressMsg{launched: 5, total: 5})
-	if m.phase != phaseScanning {
-		t.Fatalf("expected phaseScanning, got %d", m.phase)
+	m.Update(runnerLogMsg{line: "Enqueueing 2 targets..."})
+	if v := m.View(); !strings.Contains(v, "Enqueueing 2 targets...") {
+		t.Fatal("expected view to show the runner's last status line")
 	}
-	if m.workersUp != 5 {
-		t.Fatalf("expected 5 workers up, got %d", m.workersUp)
+}
+
+func TestGenericStatusBudgetExceededStopsWorkers(t *testing.T) {
+	infra := testInfra()
+	infra.Budget = operator.Budget{MaxWallTime: time.Nanosecond, Destroy: true}
+	f := newFakeCloud()
+	f.results = 1
+	store := operator.NewJobStoreAt(t.TempDir())
+	m := NewStatusWithDeps(infra, f.provider(), operator.NewTracker(store))
+	m.destroyer = &mockDestroyer{}
+	startJob(t, m)
+
+	m.waitForResults()()
+	_, cmd := await[scanDoneMsg](t, m)
+	if m.phase != phaseDestroying {
+		t.Fatalf("expected phaseDestroying, got %d", m.phase)
 	}
-	if cmd == nil {
-		t.Fatal("expected poll command")
+	if !strings.Contains(m.errMsg, "budget exceeded: wall time") {
+		t.Fatalf("expected budget error, got %q", m.errMsg)
+	}
+	if len(f.stopped) != 1 || f.stopped[0] != "task-123" {
+		t.Fatalf("expected task-123 stopped, got %v", f.stopped)
+	}
+	if rec, err := store.Load(m.infra.JobID); err != nil || rec.Phase != operator.PhaseFailed {
+		t.Fatalf("expected failed job record, got %+v (%v)", rec, err)
+	}
+	m.Update(cmd())
+	if m.phase != phaseCancelled || !m.infra.Destroyed {
+		t.Fatalf("expected cancelled and destroyed, got phase %d destroyed %v", m.phase, m.infra.Destroyed)
+	}
+	if !strings.Contains(m.View(), "budget exceeded") {
+		t.Fatal("expected view to show the budget breach")
 	}
 }
 
 func TestGenericStatusScanComplete(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(testInfra(), sub, tracker, &mockUploader{})
-	m.Init()
-	m.totalTargets = 2
-	m.phase = phaseScanning
+	f := newFakeCloud()
+	f.results = 2
+	m := NewStatusWithDeps(testInfra(), f.provider())
+	startJob(t, m)
 
-	_, cmd := m.Update(scanProgressMsg{completed: 2})
+	m.waitForResults()()
+	_, cmd := await[scanDoneMsg](t, m)
 	if m.phase != phaseComplete {
 		t.Fatalf("expected phaseComplete, got %d", m.phase)
 	}
+	if m.completed != 2 {
+		t.Fatalf("expected 2 completed, got %d", m.completed)
+	}
 	if cmd == nil {
 		t.Fatal("expected navigation command")
 	}
@@ -203,12 +330,12 @@ func TestGenericStatusScanComplete(t *testing.T) {
 }
 
 func TestGenericStatusEnqueueError(t *testing.T) {
-	sub := &mockSubmitter{enqueueErr: fmt.Errorf("queue full")}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(testInfra(), sub, tracker, &mockUploader{})
-	cmd := m.Init()
-	msg := cmd()
-	m.Update(msg)
+	f := newFakeCloud()
+	f.enqueueErr = fmt.Errorf("queue full")
+	m := NewStatusWithDeps(testInfra(), f.provider())
+	m.Init()
+	m.start()()
+	await[startedMsg](t, m)
 
 	if !strings.Contains(m.errMsg, "queue full") {
 		t.Fatalf("expected error message containing 'queue full', got %q", m.errMsg)
@@ -216,9 +343,7 @@ func TestGenericStatusEnqueueError(t *testing.T) {
 }
 
 func TestGenericStatusViewContainsToolName(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(testInfra(), sub, tracker, &mockUploader{})
+	m := NewStatusWithDeps(testInfra(), newFakeCloud().provider())
 	m.Init()
 	v := m.View()
 	if !strings.Contains(v, "httpx") {
@@ -227,9 +352,7 @@ func TestGenericStatusViewContainsToolName(t *testing.T) {
 }
 
 func TestGenericStatusEscNavigatesBack(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(testInfra(), sub, tracker, &mockUploader{})
+	m := NewStatusWithDeps(testInfra(), newFakeCloud().provider())
 	m.Init()
 
 	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
@@ -249,9 +372,7 @@ func TestGenericStatusEscNavigatesBack(t *testing.T) {
 func TestGenericStatusNoTargets(t *testing.T) {
 	infra := testInfra()
 	infra.TargetsContent = "# only comments\n\n"
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(infra, sub, tracker, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	cmd := m.Init()
 	if cmd != nil {
 		t.Fatal("expected nil command for no targets")
@@ -282,10 +403,8 @@ func testWordlistInfra(t *testing.T) core.InfraOutputs {
 }
 
 func TestGenericStatusWordlistInit(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	uploader := &mockUploader{}
-	m := NewStatusWithDeps(testWordlistInfra(t), sub, tracker, uploader)
+	f := newFakeCloud()
+	m := NewStatusWithDeps(testWordlistInfra(t), f.provider())
 
 	cmd := m.Init()
 	if cmd == nil {
@@ -294,38 +413,33 @@ func TestGenericStatusWordlistInit(t *testing.T) {
 	if !m.isWordlist {
 		t.Fatal("expected isWordlist to be true")
 	}
-	if m.phase != phaseUploading {
-		t.Fatalf("expected phaseUploading, got %d", m.phase)
-	}
 	// 4 entries split into 2 chunks.
 	if m.totalTargets != 2 {
 		t.Fatalf("expected 2 chunks, got %d", m.totalTargets)
 	}
-
-	// Execute the upload command.
-	msg := cmd()
-	uc, ok := msg.(uploadCompleteMsg)
-	if !ok {
-		t.Fatalf("expected uploadCompleteMsg, got %T", msg)
-	}
-	if uc.err != nil {
-		t.Fatalf("unexpected upload error: %v", uc.err)
+	if m.totalWords != 5 {
+		t.Fatalf("expected 5 preserved wordlist entries, got %d", m.totalWords)
 	}
-	if !uploader.uploaded {
-		t.Fatal("expected uploader to have been called")
+
+	m.start()()
+	if started, _ := await[startedMsg](t, m); started.err != nil {
+		t.Fatalf("unexpected start error: %v", started.err)
 	}
-	if uploader.plan == nil || len(uploader.plan.ChunkFiles) != 2 {
-		t.Fatalf("expected file-based chunk plan, got %#v", uploader.plan)
+	chunks := 0
+	for key := range f.objects {
+		if strings.HasPrefix(key, "scans/ffuf/") {
+			chunks++
+		}
 	}
-	if len(uc.tasks) != 2 {
-		t.Fatalf("expected 2 tasks, got %d", len(uc.tasks))
+	if chunks != 2 {
+		t.Fatalf("expected 2 uploaded chunks, got %d in %v", chunks, f.objects)
 	}
-	if uc.words != 5 {
-		t.Fatalf("expected 5 preserved wordlist entries, got %d", uc.words)
+	if len(f.enqueued) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(f.enqueued))
 	}
 
 	// Verify chunk metadata on tasks.
-	task := uc.tasks[0]
+	task := f.enqueued[0]
 	if task.ToolName != "ffuf" {
 		t.Errorf("expected tool ffuf, got %q", task.ToolName)
 	}
@@ -340,37 +454,16 @@ func TestGenericStatusWordlistInit(t *testing.T) {
 	}
 }
 
-func TestGenericStatusWordlistUploadToEnqueue(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(testWordlistInfra(t), sub, tracker, &mockUploader{})
-	m.Init()
-
-	tasks := []worker.Task{
-		{ToolName: "ffuf", Target: "https://example.com/FUZZ", InputKey: "key1", ChunkIdx: 0, TotalChunks: 2},
-		{ToolName: "ffuf", Target: "https://example.com/FUZZ", InputKey: "key2", ChunkIdx: 1, TotalChunks: 2},
-	}
-	_, cmd := m.Update(uploadCompleteMsg{tasks: tasks, words: 4})
-	if m.phase != phaseEnqueuing {
-		t.Fatalf("expected phaseEnqueuing after upload, got %d", m.phase)
-	}
-	if cmd == nil {
-		t.Fatal("expected enqueue command")
-	}
-}
-
 func TestGenericStatusWordlistViewShowsTarget(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(testWordlistInfra(t), sub, tracker, &mockUploader{})
+	m := NewStatusWithDeps(testWordlistInfra(t), newFakeCloud().provider())
 	m.Init()
 
 	v := m.View()
 	if !strings.Contains(v, "example.com/FUZZ") {
 		t.Fatal("expected view to show runtime target")
 	}
-	if !strings.Contains(v, "chunks") || !strings.Contains(v, "Uploading") {
-		t.Fatal("expected view to show uploading chunks status")
+	if !strings.Contains(v, "chunks") || !strings.Contains(v, "Starting") {
+		t.Fatal("expected view to show starting chunks status")
 	}
 	if !strings.Contains(v, "Words") {
 		t.Fatal("expected view to show total words after planning")
@@ -382,22 +475,14 @@ func TestGenericStatusWordlistContentFallback(t *testing.T) {
 	infra.WordlistPath = ""
 	infra.WordlistContent = "admin\nlogin\n"
 
-	uploader := &mockUploader{}
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, uploader)
-	cmd := m.Init()
-	if cmd == nil {
-		t.Fatal("expected init command")
-	}
-	msg := cmd()
-	uc, ok := msg.(uploadCompleteMsg)
-	if !ok {
-		t.Fatalf("expected uploadCompleteMsg, got %T", msg)
+	f := newFakeCloud()
+	m := NewStatusWithDeps(infra, f.provider())
+	startJob(t, m)
+	if m.totalWords != 2 {
+		t.Fatalf("expected 2 words, got %d", m.totalWords)
 	}
-	if uc.err != nil {
-		t.Fatalf("unexpected upload error: %v", uc.err)
-	}
-	if uploader.plan == nil || len(uploader.plan.ChunkData) == 0 {
-		t.Fatal("expected fallback in-memory chunk data")
+	if len(f.enqueued) == 0 || f.enqueued[0].InputKey == "" {
+		t.Fatalf("expected chunked tasks from in-memory content, got %+v", f.enqueued)
 	}
 }
 
@@ -407,7 +492,7 @@ func TestGenericStatusViewShowsReused(t *testing.T) {
 	infra := testInfra()
 	infra.Reused = true
 	infra.CleanupPolicy = "reuse"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.totalTargets = 10
 	m.phase = phaseScanning
 
@@ -421,7 +506,7 @@ func TestGenericStatusViewShowsFreshlyDeployed(t *testing.T) {
 	infra := testInfra()
 	infra.Reused = false
 	infra.CleanupPolicy = "destroy-after"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.totalTargets = 10
 	m.phase = phaseScanning
 
@@ -438,11 +523,11 @@ func TestGenericStatusDestroyAfterWithoutOutputDir(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = ""
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.totalTargets = 2
 	m.phase = phaseScanning
 
-	m.Update(scanProgressMsg{completed: 2})
+	m.Update(scanDoneMsg{})
 	if m.phase != phaseComplete {
 		t.Fatalf("expected phaseComplete, got %d", m.phase)
 	}
@@ -455,12 +540,11 @@ func TestGenericStatusDestroyAfterWithOutputDir(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.totalTargets = 2
 	m.phase = phaseScanning
-	m.storage = &mockExportStorage{}
 
-	m.Update(scanProgressMsg{completed: 2})
+	m.Update(scanDoneMsg{})
 	if m.phase != phaseExporting {
 		t.Fatalf("expected phaseExporting, got %d", m.phase)
 	}
@@ -469,7 +553,7 @@ func TestGenericStatusDestroyAfterWithOutputDir(t *testing.T) {
 func TestGenericStatusExportComplete(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.phase = phaseExporting
 
 	m.Update(exportCompleteMsg{dir: "/tmp/export/httpx/job-1", count: 3})
@@ -484,7 +568,7 @@ func TestGenericStatusExportComplete(t *testing.T) {
 func TestGenericStatusExportFailed(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.phase = phaseExporting
 
 	m.Update(exportCompleteMsg{err: fmt.Errorf("download error")})
@@ -499,7 +583,7 @@ func TestGenericStatusExportFailed(t *testing.T) {
 func TestGenericStatusViewShowsExportingPhase(t *testing.T) {
 	infra := testInfra()
 	infra.OutputDir = "/tmp/out"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.totalTargets = 10
 	m.completed = 10
 	m.phase = phaseExporting
@@ -510,24 +594,13 @@ func TestGenericStatusViewShowsExportingPhase(t *testing.T) {
 	}
 }
 
-// mockExportStorage is a minimal cloud.Storage for export gating tests.
-type mockExportStorage struct{}
-
-func (s *mockExportStorage) Upload(context.Context, string, string, []byte) error { return nil }
-func (s *mockExportStorage) Download(context.Context, string, string) ([]byte, error) {
-	return nil, nil
-}
-func (s *mockExportStorage) List(context.Context, string, string) ([]string, error) { return nil, nil }
-func (s *mockExportStorage) Count(context.Context, string, string) (int, error)     { return 0, nil }
-
 // --- Track 1 PR 5.12: auto-destroy lifecycle tests ---
 
 func TestGenericStatusExportSuccess_DestroyAfter_TriggersDestroy(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
-	m.storage = &mockExportStorage{}
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.destroyer = &mockDestroyer{}
 	m.phase = phaseExporting
 
@@ -547,8 +620,7 @@ func TestGenericStatusExportSuccess_NoDestroyer_ShowsWarning(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
-	m.storage = &mockExportStorage{}
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	// destroyer is nil
 	m.phase = phaseExporting
 
@@ -567,7 +639,7 @@ func TestGenericStatusExportSuccess_NoDestroyer_ShowsWarning(t *testing.T) {
 func TestGenericStatusDestroySuccess_SetsDestroyed(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.phase = phaseDestroying
 
 	_, cmd := m.Update(autoDestroyCompleteMsg{err: nil})
@@ -588,7 +660,7 @@ func TestGenericStatusDestroySuccess_SetsDestroyed(t *testing.T) {
 func TestGenericStatusDestroyFailure_SetsDestroyErr(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.phase = phaseDestroying
 
 	_, cmd := m.Update(autoDestroyCompleteMsg{err: fmt.Errorf("terraform timeout")})
@@ -614,7 +686,7 @@ func TestGenericStatusViewShowsDestroyingPhase(t *testing.T) {
 	infra.CleanupPolicy = "destroy-after"
 	infra.Exported = true
 	infra.ExportDir = "/tmp/export/httpx/job-1"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.totalTargets = 10
 	m.completed = 10
 	m.phase = phaseDestroying
@@ -633,7 +705,7 @@ func TestGenericStatusViewShowsDestroyedOnComplete(t *testing.T) {
 	infra.Exported = true
 	infra.ExportDir = "/tmp/export/httpx/job-1"
 	infra.Destroyed = true
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.totalTargets = 10
 	m.completed = 10
 	m.phase = phaseComplete
@@ -649,14 +721,13 @@ func TestGenericStatusAutoDestroyEndToEnd(t *testing.T) {
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
 	destroyer := &mockDestroyer{}
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
-	m.storage = &mockExportStorage{}
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.destroyer = destroyer
 	m.totalTargets = 2
 	m.phase = phaseScanning
 
 	// Scan complete triggers export.
-	_, _ = m.Update(scanProgressMsg{completed: 2})
+	_, _ = m.Update(scanDoneMsg{})
 	if m.phase != phaseExporting {
 		t.Fatalf("expected phaseExporting, got %d", m.phase)
 	}
@@ -712,8 +783,7 @@ func TestGenericStatusProviderNativeDestroyAfterUsesDestroyer(t *testing.T) {
 	infra.OutputDir = "/tmp/export"
 
 	destroyer := &mockDestroyer{}
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
-	m.storage = &mockExportStorage{}
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.destroyer = destroyer
 	m.phase = phaseExporting
 
@@ -740,36 +810,15 @@ func testSelfhostedInfra() core.InfraOutputs {
 }
 
 func TestGenericStatusSelfhostedUsesLaunchWorkers(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{}
-	m := NewStatusWithDeps(testSelfhostedInfra(), sub, tracker, &mockUploader{})
-	m.Init()
-
-	// Enqueue succeeds → launch phase.
-	_, cmd := m.Update(enqueueProgressMsg{sent: 2, total: 2})
-	if m.phase != phaseLaunching {
-		t.Fatalf("expected phaseLaunching, got %d", m.phase)
-	}
-	if cmd == nil {
-		t.Fatal("expected launch command")
-	}
-	// Execute the launch command — should call LaunchWorkers, not LaunchSpotWorkers.
-	msg := cmd()
-	_, ok := msg.(launchProgressMsg)
-	if !ok {
-		t.Fatalf("expected launchProgressMsg (not spotLaunchMsg), got %T", msg)
-	}
-	if sub.launchCalls != 1 {
-		t.Fatalf("expected LaunchWorkers to be called once, got %d", sub.launchCalls)
-	}
-}
-
-func TestGenericStatusSelfhostedNeverUsesSpot(t *testing.T) {
 	infra := testSelfhostedInfra()
 	infra.WorkerCount = 200 // above SpotThreshold
-	infra.ComputeMode = "auto"
-	if useSpot(infra) {
-		t.Fatal("selfhosted should never use spot even with high worker count")
+	f := newFakeCloud()
+	m := NewStatusWithDeps(infra, f.provider())
+	startJob(t, m)
+
+	// Selfhosted launches containers, never spot, even with many workers.
+	if f.containers != 1 || f.spots != 0 {
+		t.Fatalf("expected one container launch, got %d containers %d spot", f.containers, f.spots)
 	}
 }
 
@@ -777,11 +826,11 @@ func TestGenericStatusSelfhostedDestroyAfterSkipped(t *testing.T) {
 	infra := testSelfhostedInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = ""
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.totalTargets = 2
 	m.phase = phaseScanning
 
-	m.Update(scanProgressMsg{completed: 2})
+	m.Update(scanDoneMsg{})
 	if m.phase != phaseComplete {
 		t.Fatalf("expected phaseComplete, got %d", m.phase)
 	}
@@ -794,8 +843,7 @@ func TestGenericStatusSelfhostedExportComplete_SkipsDestroy(t *testing.T) {
 	infra := testSelfhostedInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, &mockUploader{})
-	m.storage = &mockExportStorage{}
+	m := NewStatusWithDeps(infra, newFakeCloud().provider())
 	m.destroyer = &mockDestroyer{} // destroyer exists but should be skipped
 	m.phase = phaseExporting
 
@@ -817,23 +865,28 @@ func TestGenericStatusProviderNativeSkipsLaunchWorkers(t *testing.T) {
 	infra.Cloud = cloud.KindHetzner
 	infra.FleetWorkerCount = 3
 
-	sub := &mockSubmitter{}
-	m := NewStatusWithDeps(infra, sub, &mockTracker{}, &mockUploader{})
-	m.Init()
+	f := newFakeCloud()
+	m := NewStatusWithDeps(infra, f.provider())
+	startJob(t, m)
 
-	_, cmd := m.Update(enqueueProgressMsg{sent: 2, total: 2})
-	if cmd == nil {
-		t.Fatal("expected launch command")
+	if m.workersUp != 3 {
+		t.Fatalf("expected the 3 fleet workers, got %d", m.workersUp)
 	}
-	msg := cmd()
-	if _, ok := msg.(launchProgressMsg); !ok {
-		t.Fatalf("expected launchProgressMsg, got %T", msg)
+	if f.containers != 0 || f.spots != 0 {
+		t.Fatalf("expected provider-native path to skip launches, got %d containers %d spot", f.containers, f.spots)
 	}
-	if sub.launchCalls != 0 {
-		t.Fatalf("expected provider-native path to skip LaunchWorkers, got %d calls", sub.launchCalls)
-	}
-	if sub.spotCalls != 0 {
-		t.Fatalf("expected provider-native path to skip LaunchSpotWorkers, got %d calls", sub.spotCalls)
+}
+
+func TestGenericStatusAWSAboveThresholdUsesSpot(t *testing.T) {
+	infra := testInfra()
+	infra.WorkerCount = SpotThreshold
+	infra.ComputeMode = "auto"
+	f := newFakeCloud()
+	m := NewStatusWithDeps(infra, f.provider())
+	startJob(t, m)
+
+	if f.spots != 1 || f.containers != 0 {
+		t.Fatalf("expected one spot launch, got %d spot %d containers", f.spots, f.containers)
 	}
 }
 
@@ -184,8 +184,11 @@ func buildMenuItems() []list.Item {
 		}
 	}
 
-	// Settings is always last.
-	items = append(items, menuItem{title: "Settings", enabled: true, target: core.ViewSettings})
+	// Search and Settings are always last.
+	items = append(items,
+		menuItem{title: "Search results — findings of every indexed job", enabled: true, target: core.ViewSearch},
+		menuItem{title: "Settings", enabled: true, target: core.ViewSettings},
+	)
 	return items
 }
 
@@ -174,9 +174,34 @@ func TestBuildMenuItemsContainsAllModules(t *testing.T) {
 	}
 
 	items := buildMenuItems()
-	// Should have one item per module + Settings.
-	expectedCount := len(reg.List()) + 1
+	// Should have one item per module + Search + Settings.
+	expectedCount := len(reg.List()) + 2
 	if len(items) != expectedCount {
 		t.Errorf("expected %d menu items, got %d", expectedCount, len(items))
 	}
 }
+
+func TestMenuSearchRoutesToSearchView(t *testing.T) {
+	m := New()
+	items := buildMenuItems()
+	searchIdx := -1
+	for i, item := range items {
+		if mi, ok := item.(menuItem); ok && mi.target == core.ViewSearch {
+			searchIdx = i
+		}
+	}
+	if searchIdx != len(items)-2 {
+		t.Fatalf("search entry at %d, want just before Settings (%d)", searchIdx, len(items)-2)
+	}
+
+	for i := 0; i < searchIdx; i++ {
+		m.Update(tea.KeyPressMsg{Code: 'j'})
+	}
+	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected command from selecting search")
+	}
+	if nav, ok := cmd().(core.NavigateMsg); !ok || nav.Target != core.ViewSearch {
+		t.Fatalf("expected NavigateMsg to ViewSearch, got %#v", nav)
+	}
+}
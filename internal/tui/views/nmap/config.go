@@ -2,7 +2,6 @@ package nmap
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 
@@ -16,6 +15,7 @@ import (
 	"heph4estus/internal/fleet"
 	"heph4estus/internal/infra"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/targets"
 	"heph4estus/internal/tui/core"
 )
 
@@ -228,10 +228,20 @@ func (m *ConfigModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
 			m.errMsg = placementErr.Error()
 			return m, nil
 		}
+		budget, budgetErr := operator.ResolveBudget(operator.Budget{}, opCfg)
+		if budgetErr != nil {
+			m.errMsg = budgetErr.Error()
+			return m, nil
+		}
+		window, windowErr := operator.ResolveWindow("", "", "", opCfg)
+		if windowErr != nil {
+			m.errMsg = windowErr.Error()
+			return m, nil
+		}
 
-		if cloudKind.IsSelfhostedFamily() && !cloudKind.IsProviderNative() {
-			// Manual selfhosted: bypass deploy view, go directly to status.
-			shCfg := factory.SelfhostedConfigFromEnv()
+		if cloudKind.IsLocal() || (cloudKind.IsSelfhostedFamily() && !cloudKind.IsProviderNative()) {
+			// Manual selfhosted and local: bypass deploy view, go directly to status.
+			shCfg := factory.DirectRuntimeConfigFromEnv(cloudKind)
 			if shCfg.QueueID == "" || shCfg.Bucket == "" {
 				m.errMsg = fmt.Sprintf("%s requires SELFHOSTED_QUEUE_ID and SELFHOSTED_BUCKET environment variables", cloudKind.Canonical())
 				return m, nil
@@ -248,6 +258,8 @@ func (m *ConfigModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
 						WorkerCount:        workerCount,
 						ComputeMode:        computeMode,
 						Placement:          placement,
+						Budget:             budget,
+						Window:             window,
 						JitterMaxSeconds:   jitterMax,
 						NmapTimingTemplate: strings.TrimSpace(m.inputs[fieldTimingTemplate].Value()),
 						DNSServers:         strings.TrimSpace(m.inputs[fieldDNSServers].Value()),
@@ -287,6 +299,8 @@ func (m *ConfigModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
 					WorkerCount:        workerCount,
 					ComputeMode:        computeMode,
 					Placement:          placement,
+					Budget:             budget,
+					Window:             window,
 					JitterMaxSeconds:   jitterMax,
 					NmapTimingTemplate: strings.TrimSpace(m.inputs[fieldTimingTemplate].Value()),
 					DNSServers:         strings.TrimSpace(m.inputs[fieldDNSServers].Value()),
@@ -390,11 +404,11 @@ func (m *ConfigModel) submit() tea.Cmd {
 	}
 	m.errMsg = ""
 	return func() tea.Msg {
-		data, err := os.ReadFile(path)
+		entries, err := targets.ImportFile(path, "")
 		if err != nil {
 			return fileReadMsg{err: err}
 		}
-		return fileReadMsg{content: string(data)}
+		return fileReadMsg{content: targets.TextLines(entries)}
 	}
 }
 
@@ -42,16 +42,17 @@ type resultsKeyMap struct {
 	Next    key.Binding
 	Prev    key.Binding
 	Destroy key.Binding
+	Compare key.Binding
 	Back    key.Binding
 	Quit    key.Binding
 }
 
 func (k resultsKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Up, k.Down, k.Enter, k.Next, k.Prev, k.Destroy, k.Back, k.Quit}
+	return []key.Binding{k.Up, k.Down, k.Enter, k.Next, k.Prev, k.Destroy, k.Compare, k.Back, k.Quit}
 }
 
 func (k resultsKeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{{k.Up, k.Down, k.Enter, k.Next, k.Prev, k.Destroy, k.Back, k.Quit}}
+	return [][]key.Binding{{k.Up, k.Down, k.Enter, k.Next, k.Prev, k.Destroy, k.Compare, k.Back, k.Quit}}
 }
 
 var resultsKeys = resultsKeyMap{
@@ -61,6 +62,7 @@ var resultsKeys = resultsKeyMap{
 	Next:    key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next page")),
 	Prev:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "prev page")),
 	Destroy: key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "destroy infra")),
+	Compare: key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "diff vs earlier job")),
 	Back:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
 	Quit:    key.NewBinding(key.WithKeys("q", "Q"), key.WithHelp("q", "quit")),
 }
@@ -153,6 +155,11 @@ func (m *ResultsModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
 			}
 		case "q", "Q":
 			return m, tea.Quit
+		case "c":
+			req := core.DiffRequest{Infra: m.infra, Tool: "nmap", Back: core.ViewNmapResults}
+			return m, func() tea.Msg {
+				return core.NavigateWithDataMsg{Target: core.ViewDiff, Data: req}
+			}
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
@@ -2,9 +2,8 @@ package nmap
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -13,9 +12,9 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"heph4estus/internal/cloud"
-	awscloud "heph4estus/internal/cloud/aws"
 	"heph4estus/internal/jobs"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/runner"
 	nmaptool "heph4estus/internal/tools/nmap"
 	"heph4estus/internal/tui/core"
 	"heph4estus/internal/worker"
@@ -25,32 +24,32 @@ import (
 type statusPhase int
 
 const (
-	phaseEnqueuing statusPhase = iota
-	phaseLaunching
-	phaseScanning
-	phaseExporting  // exporting results locally before cleanup
-	phaseDestroying // auto-destroying infrastructure after export
+	phaseStarting   statusPhase = iota // runner enqueueing and launching
+	phaseScanning                      // runner waiting for results
+	phaseExporting                     // exporting results locally before cleanup
+	phaseDestroying                    // auto-destroying infrastructure after export
 	phaseComplete
+	phaseCancelled // stopped by the job budget
 )
 
-// enqueueProgressMsg reports batch-send progress.
-type enqueueProgressMsg struct {
-	sent  int
-	total int
-	err   error
+// runnerLogMsg is a status line the runner logged.
+type runnerLogMsg struct {
+	line string
 }
 
-// launchProgressMsg reports worker launch progress.
-type launchProgressMsg struct {
-	launched int
-	total    int
-	err      error
+// startedMsg reports that Runner.Start returned.
+type startedMsg struct {
+	err error
 }
 
-// scanProgressMsg reports S3 result count.
+// scanProgressMsg reports one result count observed by Runner.Wait.
 type scanProgressMsg struct {
 	completed int
-	err       error
+}
+
+// scanDoneMsg reports that Runner.Wait returned.
+type scanDoneMsg struct {
+	err error
 }
 
 // exportCompleteMsg reports the outcome of a local result export.
@@ -60,6 +59,12 @@ type exportCompleteMsg struct {
 	err   error
 }
 
+// budgetStopMsg reports the cleanup after a budget breach cancelled the job.
+type budgetStopMsg struct {
+	destroyErr error
+	destroyed  bool
+}
+
 // autoDestroyCompleteMsg reports the outcome of auto-destroy in the status view.
 type autoDestroyCompleteMsg struct {
 	err error
@@ -67,45 +72,7 @@ type autoDestroyCompleteMsg struct {
 
 // SpotThreshold is the worker count at or above which auto mode selects spot
 // instances instead of Fargate.
-const SpotThreshold = 50
-
-// JobSubmitter abstracts target enqueueing and worker launching.
-type JobSubmitter interface {
-	EnqueueTargets(ctx context.Context, queueURL string, tasks []worker.Task) error
-	LaunchWorkers(ctx context.Context, opts cloud.ContainerOpts) (string, error)
-	LaunchSpotWorkers(ctx context.Context, opts cloud.SpotOpts) ([]string, error)
-}
-
-// ProgressTracker abstracts result counting.
-type ProgressTracker interface {
-	CountResults(ctx context.Context, bucket, prefix string) (int, error)
-}
-
-// realSubmitter uses cloud.Queue and cloud.Compute.
-type realSubmitter struct {
-	queue   cloud.Queue
-	compute cloud.Compute
-}
-
-func (s *realSubmitter) EnqueueTargets(ctx context.Context, queueURL string, tasks []worker.Task) error {
-	bodies := make([]string, len(tasks))
-	for i, t := range tasks {
-		b, err := json.Marshal(t)
-		if err != nil {
-			return fmt.Errorf("marshal task %d: %w", i, err)
-		}
-		bodies[i] = string(b)
-	}
-	return s.queue.SendBatch(ctx, queueURL, bodies)
-}
-
-func (s *realSubmitter) LaunchWorkers(ctx context.Context, opts cloud.ContainerOpts) (string, error) {
-	return s.compute.RunContainer(ctx, opts)
-}
-
-func (s *realSubmitter) LaunchSpotWorkers(ctx context.Context, opts cloud.SpotOpts) ([]string, error) {
-	return s.compute.RunSpotInstances(ctx, opts)
-}
+const SpotThreshold = runner.SpotThreshold
 
 // CounterThreshold is the target count above which we automatically use an
 // atomic ProgressCounter instead of Storage.Count(). At 10k+ targets,
@@ -113,20 +80,21 @@ func (s *realSubmitter) LaunchSpotWorkers(ctx context.Context, opts cloud.SpotOp
 // is O(1) regardless of scale.
 const CounterThreshold = 10_000
 
-// realTracker automatically selects the progress tracking strategy based on
-// job size. Below CounterThreshold it uses Storage.Count() (simple, no extra
-// infra). At or above it, uses ProgressCounter if one was provided.
+// realTracker is the storage the runner counts results in. It automatically
+// selects the progress tracking strategy based on job size. Below
+// CounterThreshold it uses Storage.Count() (simple, no extra infra). At or
+// above it, uses ProgressCounter if one was provided.
 type realTracker struct {
+	cloud.Storage
 	counter    cloud.ProgressCounter // nil = no counter backend available
-	storage    cloud.Storage
 	useCounter bool
 }
 
-func (t *realTracker) CountResults(ctx context.Context, bucket, prefix string) (int, error) {
+func (t *realTracker) Count(ctx context.Context, bucket, prefix string) (int, error) {
 	if t.useCounter {
 		return t.counter.Get(ctx, bucket)
 	}
-	return t.storage.Count(ctx, bucket, prefix)
+	return t.Storage.Count(ctx, bucket, prefix)
 }
 
 type statusKeyMap struct {
@@ -147,25 +115,25 @@ var statusKeys = statusKeyMap{
 	Quit: key.NewBinding(key.WithKeys("q", "Q"), key.WithHelp("q", "quit")),
 }
 
-// StatusModel displays enqueue → launch → scan progress.
+// StatusModel drives an nmap job through runner.Runner and displays its
+// enqueue → launch → scan progress.
 type StatusModel struct {
-	submitter  JobSubmitter
-	tracker    ProgressTracker
+	runner     *runner.Runner
+	runnerErr  error
+	plan       *runner.Plan
+	events     chan tea.Msg // runner log lines, progress and outcomes
 	jobTracker *operator.Tracker
-	storage    cloud.Storage  // for local export on completion
 	destroyer  core.Destroyer // for auto-destroy after export (nil = no destroy)
 	infra      core.InfraOutputs
 
 	phase        statusPhase
 	totalTargets int
-	enqueueSent  int
 	workersUp    int
 	completed    int
 	startTime    time.Time
+	lastLog      string
 	errMsg       string
 
-	spotInstanceIDs []string
-
 	// Cleanup / export state
 	cleanupWarning string // shown when destroy-after is gated
 
@@ -193,17 +161,15 @@ func NewStatus(infra core.InfraOutputs, q cloud.Queue, s cloud.Storage, c cloud.
 	useCounter := counter != nil && len(targets) >= CounterThreshold
 
 	m := NewStatusWithDeps(infra,
-		&realSubmitter{queue: q, compute: c},
-		&realTracker{counter: counter, storage: s, useCounter: useCounter},
+		runner.Services{Q: q, S: &realTracker{Storage: s, counter: counter, useCounter: useCounter}, C: c},
 		jt,
 	)
-	m.storage = s
 	m.destroyer = destroyer
 	return m
 }
 
-// NewStatusWithDeps creates a status view with injected dependencies.
-func NewStatusWithDeps(infra core.InfraOutputs, sub JobSubmitter, tracker ProgressTracker, jt ...*operator.Tracker) *StatusModel {
+// NewStatusWithDeps creates a status view over an injected provider.
+func NewStatusWithDeps(infra core.InfraOutputs, provider cloud.Provider, jt ...*operator.Tracker) *StatusModel {
 	h := help.New()
 	h.Styles = help.Styles{
 		ShortKey:       lipgloss.NewStyle().Foreground(core.Steel),
@@ -218,20 +184,23 @@ func NewStatusWithDeps(infra core.InfraOutputs, sub JobSubmitter, tracker Progre
 	if len(jt) > 0 && jt[0] != nil {
 		jobTracker = jt[0]
 	}
-	return &StatusModel{
-		submitter:  sub,
-		tracker:    tracker,
+	m := &StatusModel{
+		events:     make(chan tea.Msg, 64),
 		jobTracker: jobTracker,
 		infra:      infra,
 		startTime:  time.Now(),
 		help:       h,
 	}
+	m.runner, m.runnerErr = core.NewRunner(infra, provider, jobTracker, m.logf)
+	return m
 }
 
-// trackPhase updates the job record if a tracker is available.
-func (m *StatusModel) trackPhase(phase operator.Phase) {
-	if m.jobTracker != nil && m.infra.JobID != "" {
-		_ = m.jobTracker.UpdatePhase(m.infra.JobID, phase)
+// logf hands a runner status line to the view. Lines are dropped rather
+// than blocking the runner when the view falls behind.
+func (m *StatusModel) logf(format string, args ...any) {
+	select {
+	case m.events <- runnerLogMsg{line: fmt.Sprintf(format, args...)}:
+	default:
 	}
 }
 
@@ -242,39 +211,45 @@ func (m *StatusModel) trackFail(err error) {
 	}
 }
 
-func (m *StatusModel) trackCreate() {
-	if m.jobTracker == nil || m.infra.JobID == "" {
-		return
-	}
-	_ = m.jobTracker.Create(&operator.JobRecord{
-		JobID:                 m.infra.JobID,
-		ToolName:              "nmap",
-		Phase:                 operator.PhaseEnqueuing,
-		TotalTasks:            m.totalTargets,
-		WorkerCount:           m.infra.WorkerCount,
-		ComputeMode:           m.infra.ComputeMode,
-		Cloud:                 string(m.infra.Cloud),
-		Placement:             m.infra.Placement,
-		ExpectedWorkerVersion: m.infra.ExpectedWorkerVersion,
-		Bucket:                m.infra.S3BucketName,
-		NATSUrl:               m.infra.NATSUrl,
-		ControllerIP:          m.infra.ControllerIP,
-		GenerationID:          m.infra.GenerationID,
-		ControllerCAPEM:       m.infra.ControllerCAPEM,
-		ControllerHost:        m.infra.ControllerHost,
-		NATSClientCertPEM:     m.infra.NATSClientCertPEM,
-		NATSClientKeyPEM:      m.infra.NATSClientKeyPEM,
-	})
-}
-
 func (m *StatusModel) Init() tea.Cmd {
-	scanner := nmaptool.NewScanner(nil)
-	nmapTasks := scanner.ParseTargets(m.infra.TargetsContent, m.infra.NmapOptions)
+	if m.runnerErr != nil {
+		m.errMsg = fmt.Sprintf("Runner error: %v", m.runnerErr)
+		return nil
+	}
 	if m.infra.JobID == "" {
 		m.infra.JobID = jobs.NewID("nmap")
 	}
 
-	// Convert nmap tasks to generic worker tasks with producer-side option injection.
+	tasks := m.scanTasks()
+	if len(tasks) == 0 {
+		m.errMsg = "No targets found"
+		return nil
+	}
+	sc, err := operator.LoadProfileScope()
+	if err != nil {
+		m.errMsg = fmt.Sprintf("Scope check failed: %v", err)
+		return nil
+	}
+	plan, err := m.runner.Plan(jobs.JobConfig{ToolName: "nmap", Tasks: tasks, Scope: sc}, m.infra.JobID)
+	if err != nil {
+		m.errMsg = fmt.Sprintf("Planning failed: %v", err)
+		return nil
+	}
+	m.plan = plan
+	m.totalTargets = len(plan.Tasks)
+	if m.jobTracker != nil {
+		_ = m.jobTracker.Create(m.runner.NewRecord(plan))
+	}
+
+	m.phase = phaseStarting
+	return tea.Batch(m.start(), m.listen())
+}
+
+// scanTasks converts the nmap scan tasks to generic worker tasks with
+// producer-side option injection.
+func (m *StatusModel) scanTasks() []worker.Task {
+	scanner := nmaptool.NewScanner(nil)
+	nmapTasks := scanner.ParseTargets(m.infra.TargetsContent, m.infra.NmapOptions)
 	tasks := make([]worker.Task, len(nmapTasks))
 	for i, t := range nmapTasks {
 		opts := t.Options
@@ -297,20 +272,41 @@ func (m *StatusModel) Init() tea.Cmd {
 			TotalChunks: t.TotalChunks,
 		}
 	}
-	m.totalTargets = len(tasks)
+	return tasks
+}
 
-	if m.totalTargets == 0 {
-		m.errMsg = "No targets found"
+// start enqueues and launches through the runner, which records any
+// failure on the job.
+func (m *StatusModel) start() tea.Cmd {
+	r, plan, events := m.runner, m.plan, m.events
+	return func() tea.Msg {
+		err := r.Start(context.Background(), plan)
+		events <- startedMsg{err: err}
 		return nil
 	}
+}
 
-	m.phase = phaseEnqueuing
-	m.trackCreate()
-	infra := m.infra
-	sub := m.submitter
+// waitForResults waits through the runner, which enforces the job budget,
+// and reports progress and the outcome as events.
+func (m *StatusModel) waitForResults() tea.Cmd {
+	r, plan, events := m.runner, m.plan, m.events
 	return func() tea.Msg {
-		err := sub.EnqueueTargets(context.Background(), infra.SQSQueueURL, tasks)
-		return enqueueProgressMsg{sent: len(tasks), total: len(tasks), err: err}
+		err := r.Wait(context.Background(), plan.ToolName, plan.JobID, len(plan.Tasks), func(p runner.Progress) {
+			select {
+			case events <- scanProgressMsg{completed: p.Completed}:
+			default:
+			}
+		})
+		events <- scanDoneMsg{err: err}
+		return nil
+	}
+}
+
+// listen delivers the next runner event.
+func (m *StatusModel) listen() tea.Cmd {
+	events := m.events
+	return func() tea.Msg {
+		return <-events
 	}
 }
 
@@ -331,74 +327,67 @@ func (m *StatusModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
 			return m, tea.Quit
 		}
 
-	case enqueueProgressMsg:
-		if msg.err != nil {
-			m.errMsg = fmt.Sprintf("Enqueue failed: %v", msg.err)
-			m.trackFail(msg.err)
-			return m, nil
-		}
-		m.enqueueSent = msg.sent
-		m.phase = phaseLaunching
-		m.trackPhase(operator.PhaseLaunching)
-		return m, m.launchWorkers()
+	case runnerLogMsg:
+		m.lastLog = msg.line
+		return m, m.listen()
 
-	case spotLaunchMsg:
+	case startedMsg:
 		if msg.err != nil {
-			m.errMsg = fmt.Sprintf("Spot launch failed: %v", msg.err)
-			m.trackFail(msg.err)
+			m.errMsg = fmt.Sprintf("Start failed: %v", msg.err)
 			return m, nil
 		}
-		m.spotInstanceIDs = msg.instanceIDs
-		m.workersUp = msg.launched
+		m.totalTargets = len(m.plan.Tasks)
+		m.workersUp = m.runner.WorkersUp()
 		m.phase = phaseScanning
-		m.trackPhase(operator.PhaseScanning)
-		return m, m.pollProgress()
+		return m, tea.Batch(m.waitForResults(), m.listen())
 
-	case launchProgressMsg:
+	case scanProgressMsg:
+		m.completed = msg.completed
+		m.rateSamples = append(m.rateSamples, rateSample{time: time.Now(), count: msg.completed})
+		// Keep only last 30s of samples
+		cutoff := time.Now().Add(-30 * time.Second)
+		for len(m.rateSamples) > 1 && m.rateSamples[0].time.Before(cutoff) {
+			m.rateSamples = m.rateSamples[1:]
+		}
+		return m, m.listen()
+
+	case scanDoneMsg:
+		if errors.Is(msg.err, operator.ErrBudgetExceeded) {
+			return m, m.stopForBudget(msg.err)
+		}
 		if msg.err != nil {
-			m.errMsg = fmt.Sprintf("Launch failed: %v", msg.err)
+			m.errMsg = fmt.Sprintf("Scan failed: %v", msg.err)
 			m.trackFail(msg.err)
 			return m, nil
 		}
-		m.workersUp = msg.launched
-		m.phase = phaseScanning
-		m.trackPhase(operator.PhaseScanning)
-		return m, m.pollProgress()
-
-	case scanProgressMsg:
-		if msg.err != nil {
-			m.errMsg = fmt.Sprintf("Progress check failed: %v", msg.err)
-			// Don't stop — try again
-		} else {
-			m.completed = msg.completed
-			m.rateSamples = append(m.rateSamples, rateSample{time: time.Now(), count: msg.completed})
-			// Keep only last 30s of samples
-			cutoff := time.Now().Add(-30 * time.Second)
-			for len(m.rateSamples) > 1 && m.rateSamples[0].time.Before(cutoff) {
-				m.rateSamples = m.rateSamples[1:]
+		if m.jobTracker != nil && m.infra.JobID != "" {
+			_ = m.jobTracker.Complete(m.infra.JobID)
+		}
+		// Export gating: if destroy-after is set, export locally first.
+		if m.shouldExport() {
+			m.phase = phaseExporting
+			return m, m.exportResults()
+		}
+		if m.infra.CleanupPolicy == "destroy-after" {
+			if m.infra.Cloud.IsLocal() {
+				m.cleanupWarning = "destroy-after skipped: local runs without infrastructure"
+			} else if m.infra.Cloud.IsSelfhostedFamily() && !m.infra.Cloud.IsProviderNative() {
+				m.cleanupWarning = "destroy-after skipped: selfhosted does not support auto-destroy"
+			} else if m.infra.OutputDir == "" {
+				m.cleanupWarning = "destroy-after skipped: no output directory configured"
 			}
 		}
+		m.phase = phaseComplete
+		return m, m.navigateToResults()
 
-		if m.completed >= m.totalTargets {
-			if m.jobTracker != nil && m.infra.JobID != "" {
-				_ = m.jobTracker.Complete(m.infra.JobID)
-			}
-			// Export gating: if destroy-after is set, export locally first.
-			if m.shouldExport() {
-				m.phase = phaseExporting
-				return m, m.exportResults()
-			}
-			if m.infra.CleanupPolicy == "destroy-after" {
-				if m.infra.Cloud.IsSelfhostedFamily() && !m.infra.Cloud.IsProviderNative() {
-					m.cleanupWarning = "destroy-after skipped: selfhosted does not support auto-destroy"
-				} else if m.infra.OutputDir == "" {
-					m.cleanupWarning = "destroy-after skipped: no output directory configured"
-				}
-			}
-			m.phase = phaseComplete
-			return m, m.navigateToResults()
+	case budgetStopMsg:
+		if msg.destroyErr != nil {
+			m.infra.DestroyErr = msg.destroyErr.Error()
+			m.cleanupWarning = fmt.Sprintf("destroy failed: %v", msg.destroyErr)
 		}
-		return m, m.pollProgress()
+		m.infra.Destroyed = msg.destroyed
+		m.phase = phaseCancelled
+		return m, nil
 
 	case exportCompleteMsg:
 		if msg.err != nil {
@@ -409,6 +398,11 @@ func (m *StatusModel) Update(msg tea.Msg) (core.View, tea.Cmd) {
 		m.infra.Exported = true
 		m.infra.ExportDir = msg.dir
 		// Auto-destroy if destroy-after policy and destroyer is available.
+		if m.infra.Cloud.IsLocal() {
+			m.cleanupWarning = "destroy-after skipped: local runs without infrastructure"
+			m.phase = phaseComplete
+			return m, m.navigateToResults()
+		}
 		if m.infra.Cloud.IsSelfhostedFamily() && !m.infra.Cloud.IsProviderNative() {
 			m.cleanupWarning = "destroy-after skipped: selfhosted does not support auto-destroy"
 			m.phase = phaseComplete
@@ -458,18 +452,22 @@ func (m *StatusModel) View() string {
 	if m.infra.Placement.Summary() != "" {
 		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Placement:"), m.infra.Placement.Summary())
 	}
+	if !m.infra.Budget.IsZero() {
+		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Budget:"), m.infra.Budget)
+	}
+	if m.infra.Window != nil {
+		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Window:"), m.infra.Window)
+	}
 	b.WriteString("\n")
 
 	switch m.phase {
-	case phaseEnqueuing:
-		b.WriteString(core.SelectedStyle.Render("  Enqueueing targets...") + "\n\n")
-		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Targets:"), fmt.Sprintf("%d", m.totalTargets))
-		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Elapsed:"), elapsed.String())
-
-	case phaseLaunching:
-		b.WriteString(core.SelectedStyle.Render("  Launching workers...") + "\n\n")
-		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Targets:"), fmt.Sprintf("%d enqueued", m.enqueueSent))
-		fmt.Fprintf(&b, "  %s%d / %d\n", labelStyle.Render("Workers:"), m.workersUp, m.infra.WorkerCount)
+	case phaseStarting:
+		b.WriteString(core.SelectedStyle.Render("  Starting scan...") + "\n\n")
+		fmt.Fprintf(&b, "  %s%d\n", labelStyle.Render("Targets:"), m.totalTargets)
+		fmt.Fprintf(&b, "  %s%d\n", labelStyle.Render("Workers:"), m.infra.WorkerCount)
+		if m.lastLog != "" {
+			fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Status:"), m.lastLog)
+		}
 		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Elapsed:"), elapsed.String())
 
 	case phaseScanning:
@@ -477,7 +475,11 @@ func (m *StatusModel) View() string {
 		bar := progressBar(m.completed, m.totalTargets, 30)
 		rate, eta := m.calcRateETA()
 
-		b.WriteString(core.SelectedStyle.Render("  Scanning") + "\n\n")
+		if !m.infra.Window.Open(time.Now()) {
+			b.WriteString(core.SelectedStyle.Render("  Paused: outside scan window") + "\n\n")
+		} else {
+			b.WriteString(core.SelectedStyle.Render("  Scanning") + "\n\n")
+		}
 		fmt.Fprintf(&b, "  %s%d active\n", labelStyle.Render("Workers:"), m.workersUp)
 		if m.infra.Cloud.IsProviderNative() && m.infra.ControllerIP != "" {
 			fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Controller:"), m.infra.ControllerIP)
@@ -516,6 +518,14 @@ func (m *StatusModel) View() string {
 		if m.infra.Destroyed {
 			fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Infra:"), "destroyed")
 		}
+
+	case phaseCancelled:
+		b.WriteString(core.ErrorStyle.Render("  Scan cancelled: budget exceeded") + "\n\n")
+		fmt.Fprintf(&b, "  %s%d / %d\n", labelStyle.Render("Completed:"), m.completed, m.totalTargets)
+		fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Elapsed:"), elapsed.String())
+		if m.infra.Destroyed {
+			fmt.Fprintf(&b, "  %s%s\n", labelStyle.Render("Infra:"), "destroyed")
+		}
 	}
 
 	if m.cleanupWarning != "" {
@@ -536,126 +546,42 @@ func (m *StatusModel) View() string {
 	return content
 }
 
-// useSpot returns true if the compute mode resolves to spot instances.
-func useSpot(infra core.InfraOutputs) bool {
-	// Selfhosted only supports RunContainer (no spot instances).
-	if infra.Cloud.IsSelfhostedFamily() {
-		return false
-	}
-	switch infra.ComputeMode {
-	case "spot":
-		return true
-	case "fargate":
-		return false
-	default: // "auto" or empty
-		return infra.WorkerCount >= SpotThreshold
-	}
-}
-
-func (m *StatusModel) launchWorkers() tea.Cmd {
-	infra := m.infra
-	sub := m.submitter
-
-	if useSpot(infra) {
-		return m.launchSpotWorkers()
+// stopForBudget reports a budget breach. The runner has already stopped
+// the workers and failed the job; when the budget asks for it, the
+// infrastructure is destroyed too.
+func (m *StatusModel) stopForBudget(reason error) tea.Cmd {
+	m.errMsg = reason.Error()
+	var destroyer core.Destroyer
+	if m.infra.Budget.Destroy && !m.infra.Cloud.IsLocal() && !(m.infra.Cloud.IsSelfhostedFamily() && !m.infra.Cloud.IsProviderNative()) {
+		destroyer = m.destroyer
 	}
-	if infra.Cloud.IsProviderNative() {
-		return func() tea.Msg {
-			launched := infra.FleetWorkerCount
-			if launched <= 0 {
-				launched = infra.WorkerCount
-			}
-			if launched <= 0 {
-				launched = 1
-			}
-			return launchProgressMsg{launched: launched, total: launched}
-		}
-	}
-
-	return func() tea.Msg {
-		_, err := sub.LaunchWorkers(context.Background(), cloud.ContainerOpts{
-			Cluster:        infra.ECSClusterName,
-			TaskDefinition: infra.TaskDefinitionARN,
-			ContainerName:  "nmap-worker",
-			Subnets:        infra.SubnetIDs,
-			SecurityGroups: []string{infra.SecurityGroupID},
-			Env: map[string]string{
-				"QUEUE_URL":          infra.SQSQueueURL,
-				"S3_BUCKET":          infra.S3BucketName,
-				"JITTER_MAX_SECONDS": strconv.Itoa(infra.JitterMaxSeconds),
-				"TOOL_NAME":          "nmap",
-			},
-			Count: infra.WorkerCount,
-		})
-		return launchProgressMsg{launched: infra.WorkerCount, total: infra.WorkerCount, err: err}
+	if destroyer == nil {
+		m.phase = phaseCancelled
+		return nil
 	}
-}
-
-func (m *StatusModel) launchSpotWorkers() tea.Cmd {
-	infra := m.infra
-	sub := m.submitter
+	m.phase = phaseDestroying
 	return func() tea.Msg {
-		userData := awscloud.GenerateUserData(awscloud.UserDataOpts{
-			ECRRepoURL: infra.ECRRepoURL,
-			ImageTag:   "latest",
-			Region:     regionFromECR(infra.ECRRepoURL),
-			EnvVars: map[string]string{
-				"QUEUE_URL":          infra.SQSQueueURL,
-				"S3_BUCKET":          infra.S3BucketName,
-				"JITTER_MAX_SECONDS": strconv.Itoa(infra.JitterMaxSeconds),
-				"TOOL_NAME":          "nmap",
-			},
-		})
-		ids, err := sub.LaunchSpotWorkers(context.Background(), cloud.SpotOpts{
-			AMI:             infra.AMIID,
-			Count:           infra.WorkerCount,
-			SecurityGroups:  []string{infra.SecurityGroupID},
-			SubnetIDs:       infra.SubnetIDs,
-			InstanceProfile: infra.InstanceProfileARN,
-			UserData:        userData,
-			Tags: map[string]string{
-				"Project": "heph4estus",
-				"Tool":    "nmap",
-			},
-		})
-		msg := launchProgressMsg{launched: len(ids), total: infra.WorkerCount, err: err}
-		return spotLaunchMsg{launchProgressMsg: msg, instanceIDs: ids}
+		err := destroyer.Destroy(context.Background())
+		return budgetStopMsg{destroyErr: err, destroyed: err == nil}
 	}
 }
 
-// spotLaunchMsg extends launchProgressMsg with instance IDs for tracking.
-type spotLaunchMsg struct {
-	launchProgressMsg
-	instanceIDs []string
-}
-
-func regionFromECR(url string) string {
-	parts := strings.Split(url, ".")
-	for i, p := range parts {
-		if p == "ecr" && i+1 < len(parts) {
-			return parts[i+1]
-		}
-	}
-	return "us-east-1"
-}
-
 // shouldExport returns true when the cleanup policy requires local export
 // before destroy-after can be honored.
 func (m *StatusModel) shouldExport() bool {
-	return m.infra.CleanupPolicy == "destroy-after" && m.infra.OutputDir != "" && m.storage != nil
+	return m.infra.CleanupPolicy == "destroy-after" && m.infra.OutputDir != "" && m.runner != nil
 }
 
 func (m *StatusModel) exportResults() tea.Cmd {
-	storage := m.storage
+	r := m.runner
 	infra := m.infra
 	return func() tea.Msg {
-		result, err := operator.ExportJob(
-			context.Background(), storage,
-			infra.S3BucketName, "nmap", infra.JobID, infra.OutputDir,
-		)
+		result, err := r.Export(context.Background(), "nmap", infra.JobID, infra.OutputDir)
 		if err != nil {
 			return exportCompleteMsg{err: err}
 		}
+		// Indexing is best-effort; heph index can retry it.
+		_, _ = core.IndexExport(context.Background(), infra.JobID, result.Dir)
 		return exportCompleteMsg{dir: result.Dir, count: result.ResultCount + result.ArtifactCount}
 	}
 }
@@ -678,15 +604,6 @@ func (m *StatusModel) navigateToResults() tea.Cmd {
 	}
 }
 
-func (m *StatusModel) pollProgress() tea.Cmd {
-	infra := m.infra
-	tracker := m.tracker
-	return tea.Tick(2*time.Second, func(time.Time) tea.Msg {
-		count, err := tracker.CountResults(context.Background(), infra.S3BucketName, jobs.ResultPrefix("nmap", infra.JobID))
-		return scanProgressMsg{completed: count, err: err}
-	})
-}
-
 func (m *StatusModel) calcRateETA() (targetsPerMin float64, remaining time.Duration) {
 	if len(m.rateSamples) < 2 {
 		return 0, 0
@@ -2,48 +2,112 @@ package nmap
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	tea "charm.land/bubbletea/v2"
 	"heph4estus/internal/cloud"
+	"heph4estus/internal/cloud/mock"
 	"heph4estus/internal/operator"
+	"heph4estus/internal/runner"
 	"heph4estus/internal/tui/core"
 	"heph4estus/internal/worker"
 )
 
-type mockSubmitter struct {
-	enqueueErr    error
-	launchErr     error
-	spotLaunchErr error
-	spotIDs       []string
-	launchCalls   int
-	spotCalls     int
-}
-
-func (s *mockSubmitter) EnqueueTargets(_ context.Context, _ string, _ []worker.Task) error {
-	return s.enqueueErr
-}
-
-func (s *mockSubmitter) LaunchWorkers(_ context.Context, _ cloud.ContainerOpts) (string, error) {
-	s.launchCalls++
-	return "arn:task:1", s.launchErr
-}
-
-func (s *mockSubmitter) LaunchSpotWorkers(_ context.Context, _ cloud.SpotOpts) ([]string, error) {
-	s.spotCalls++
-	if s.spotIDs == nil {
-		s.spotIDs = []string{"i-spot1", "i-spot2"}
+// fakeCloud records the runner's calls against in-memory queue state.
+type fakeCloud struct {
+	mu         sync.Mutex
+	enqueued   []worker.Task
+	enqueueErr error
+	containers int
+	spots      int
+	stopped    []string
+	// results is what Count reports.
+	results int
+}
+
+func (f *fakeCloud) provider() runner.Services {
+	return runner.Services{
+		S: &mock.Storage{
+			UploadFunc: func(context.Context, string, string, []byte) error { return nil },
+			CountFunc: func(context.Context, string, string) (int, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				return f.results, nil
+			},
+		},
+		Q: &mock.Queue{
+			SendBatchFunc: func(_ context.Context, _ string, bodies []string) error {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				if f.enqueueErr != nil {
+					return f.enqueueErr
+				}
+				for _, body := range bodies {
+					var task worker.Task
+					if err := json.Unmarshal([]byte(body), &task); err != nil {
+						return err
+					}
+					f.enqueued = append(f.enqueued, task)
+				}
+				return nil
+			},
+		},
+		C: &mock.Compute{
+			RunContainerFunc: func(context.Context, cloud.ContainerOpts) (string, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.containers++
+				return "arn:task:1", nil
+			},
+			RunSpotInstancesFunc: func(context.Context, cloud.SpotOpts) ([]string, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.spots++
+				return []string{"i-spot1", "i-spot2"}, nil
+			},
+			StopWorkersFunc: func(_ context.Context, ids []string) error {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.stopped = append(f.stopped, ids...)
+				return nil
+			},
+		},
 	}
-	return s.spotIDs, s.spotLaunchErr
 }
 
-type mockTracker struct {
-	count    int
-	countErr error
+// await feeds runner events to m until one of type T arrives and returns
+// the command m answered it with.
+func await[T tea.Msg](t *testing.T, m *StatusModel) (T, tea.Cmd) {
+	t.Helper()
+	for {
+		select {
+		case msg := <-m.events:
+			_, cmd := m.Update(msg)
+			if got, ok := msg.(T); ok {
+				return got, cmd
+			}
+		case <-time.After(5 * time.Second):
+			var zero T
+			t.Fatalf("timed out waiting for %T", zero)
+			return zero, nil
+		}
+	}
 }
 
-func (t *mockTracker) CountResults(_ context.Context, _, _ string) (int, error) {
-	return t.count, t.countErr
+// startJob runs Init and Runner.Start and returns m in the scanning phase.
+func startJob(t *testing.T, m *StatusModel) {
+	t.Helper()
+	if cmd := m.Init(); cmd == nil {
+		t.Fatalf("expected init command, error %q", m.errMsg)
+	}
+	m.start()()
+	if started, _ := await[startedMsg](t, m); started.err != nil {
+		t.Fatalf("start: %v", started.err)
+	}
 }
 
 func testInfra() core.InfraOutputs {
@@ -62,12 +126,15 @@ func testInfra() core.InfraOutputs {
 }
 
 func TestStatusModel_Init(t *testing.T) {
-	m := NewStatusWithDeps(testInfra(), &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(testInfra(), (&fakeCloud{}).provider())
 	cmd := m.Init()
 
 	if m.totalTargets != 2 {
 		t.Fatalf("expected 2 targets, got %d", m.totalTargets)
 	}
+	if m.phase != phaseStarting {
+		t.Fatalf("expected phaseStarting, got %d", m.phase)
+	}
 	if cmd == nil {
 		t.Fatal("expected init command")
 	}
@@ -83,7 +150,7 @@ func TestStatusModel_TrackCreatePersistsNATSClientIdentity(t *testing.T) {
 
 	store := operator.NewJobStoreAt(t.TempDir())
 	tracker := operator.NewTracker(store)
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{}, tracker)
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider(), tracker)
 	_ = m.Init()
 
 	rec, err := store.Load(infra.JobID)
@@ -95,23 +162,33 @@ func TestStatusModel_TrackCreatePersistsNATSClientIdentity(t *testing.T) {
 	}
 }
 
-func TestStatusModel_EnqueueSuccess(t *testing.T) {
-	m := NewStatusWithDeps(testInfra(), &mockSubmitter{}, &mockTracker{})
-	cmd := m.Init()
+func TestStatusModel_EnqueueInjectsOptions(t *testing.T) {
+	infra := testInfra()
+	infra.NoRDNS = true
+	infra.NmapTimingTemplate = "4"
+	infra.DNSServers = "1.1.1.1"
+	f := &fakeCloud{}
+	m := NewStatusWithDeps(infra, f.provider())
+	startJob(t, m)
 
-	msg := cmd()
-	_, _ = m.Update(msg)
-	if m.phase != phaseLaunching {
-		t.Fatalf("expected phaseLaunching, got %d", m.phase)
+	if len(f.enqueued) != 2 {
+		t.Fatalf("expected 2 enqueued tasks, got %d", len(f.enqueued))
+	}
+	task := f.enqueued[0]
+	if !strings.HasPrefix(task.Options, "--dns-servers 1.1.1.1 -T4 -n ") {
+		t.Errorf("task.Options = %q, want injected options first", task.Options)
+	}
+	if task.JobID != "job-123" || task.ToolName != "nmap" {
+		t.Errorf("task = %+v, want nmap job-123", task)
 	}
 }
 
 func TestStatusModel_EnqueueError(t *testing.T) {
-	sub := &mockSubmitter{enqueueErr: context.DeadlineExceeded}
-	m := NewStatusWithDeps(testInfra(), sub, &mockTracker{})
-	cmd := m.Init()
-	msg := cmd()
-	_, _ = m.Update(msg)
+	f := &fakeCloud{enqueueErr: context.DeadlineExceeded}
+	m := NewStatusWithDeps(testInfra(), f.provider())
+	m.Init()
+	m.start()()
+	await[startedMsg](t, m)
 
 	if m.errMsg == "" {
 		t.Fatal("expected error message")
@@ -119,37 +196,25 @@ func TestStatusModel_EnqueueError(t *testing.T) {
 }
 
 func TestStatusModel_LaunchAndScan(t *testing.T) {
-	sub := &mockSubmitter{}
-	tracker := &mockTracker{count: 2}
-	m := NewStatusWithDeps(testInfra(), sub, tracker)
+	f := &fakeCloud{results: 2}
+	m := NewStatusWithDeps(testInfra(), f.provider())
+	startJob(t, m)
 
-	// Enqueue
-	cmd := m.Init()
-	msg := cmd()
-	_, cmd = m.Update(msg)
-
-	// Launch
-	msg = cmd()
-	_, _ = m.Update(msg)
 	if m.phase != phaseScanning {
 		t.Fatalf("expected phaseScanning, got %d", m.phase)
 	}
 	if m.workersUp != 2 {
 		t.Fatalf("expected 2 workers, got %d", m.workersUp)
 	}
-}
-
-func TestStatusModel_ScanProgress(t *testing.T) {
-	m := NewStatusWithDeps(testInfra(), &mockSubmitter{}, &mockTracker{})
-	m.phase = phaseScanning
-	m.totalTargets = 2
-
-	m.Update(scanProgressMsg{completed: 1})
-	if m.completed != 1 {
-		t.Fatalf("expected 1 completed, got %d", m.completed)
+	if f.containers != 1 {
+		t.Fatalf("expected one container launch, got %d", f.containers)
 	}
 
-	_, cmd := m.Update(scanProgressMsg{completed: 2})
+	m.waitForResults()()
+	_, cmd := await[scanDoneMsg](t, m)
+	if m.completed != 2 {
+		t.Fatalf("expected 2 completed, got %d", m.completed)
+	}
 	if m.phase != phaseComplete {
 		t.Fatalf("expected phaseComplete, got %d", m.phase)
 	}
@@ -166,8 +231,48 @@ func TestStatusModel_ScanProgress(t *testing.T) {
 	}
 }
 
+func TestStatusModel_ScanProgress(t *testing.T) {
+	m := NewStatusWithDeps(testInfra(), (&fakeCloud{}).provider())
+	m.phase = phaseScanning
+	m.totalTargets = 2
+
+	_, cmd := m.Update(scanProgressMsg{completed: 1})
+	if m.completed != 1 {
+		t.Fatalf("expected 1 completed, got %d", m.completed)
+	}
+	if m.phase != phaseScanning {
+		t.Fatalf("expected phaseScanning, got %d", m.phase)
+	}
+	if cmd == nil {
+		t.Fatal("expected listen command")
+	}
+}
+
+func TestStatusModel_BudgetExceededCancels(t *testing.T) {
+	infra := testInfra()
+	infra.Budget = operator.Budget{MaxWallTime: time.Nanosecond}
+	f := &fakeCloud{}
+	m := NewStatusWithDeps(infra, f.provider())
+	startJob(t, m)
+
+	m.waitForResults()()
+	_, cmd := await[scanDoneMsg](t, m)
+	if cmd != nil {
+		t.Fatal("expected no destroy without Budget.Destroy")
+	}
+	if m.phase != phaseCancelled {
+		t.Fatalf("expected phaseCancelled, got %d", m.phase)
+	}
+	if len(f.stopped) != 1 || f.stopped[0] != "arn:task:1" {
+		t.Fatalf("expected the launched worker stopped, got %v", f.stopped)
+	}
+	if !strings.Contains(m.View(), "budget exceeded") {
+		t.Fatal("expected view to show the budget breach")
+	}
+}
+
 func TestStatusModel_View(t *testing.T) {
-	m := NewStatusWithDeps(testInfra(), &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(testInfra(), (&fakeCloud{}).provider())
 	m.totalTargets = 100
 	m.phase = phaseScanning
 	m.completed = 50
@@ -200,7 +305,7 @@ func TestRealTracker_UsesCounterAboveThreshold(t *testing.T) {
 				return 42, nil
 			},
 		},
-		storage: &mockCountStorage{
+		Storage: &mockCountStorage{
 			countFunc: func(_ context.Context, _, _ string) (int, error) {
 				storageCalled = true
 				return 42, nil
@@ -209,7 +314,7 @@ func TestRealTracker_UsesCounterAboveThreshold(t *testing.T) {
 		useCounter: true,
 	}
 
-	count, err := tracker.CountResults(context.Background(), "bucket", "scans/nmap/job-123/results/")
+	count, err := tracker.Count(context.Background(), "bucket", "scans/nmap/job-123/results/")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -234,7 +339,7 @@ func TestRealTracker_UsesStorageBelowThreshold(t *testing.T) {
 				return 0, nil
 			},
 		},
-		storage: &mockCountStorage{
+		Storage: &mockCountStorage{
 			countFunc: func(_ context.Context, _, _ string) (int, error) {
 				storageCalled = true
 				return 5, nil
@@ -243,7 +348,7 @@ func TestRealTracker_UsesStorageBelowThreshold(t *testing.T) {
 		useCounter: false, // below threshold
 	}
 
-	count, err := tracker.CountResults(context.Background(), "bucket", "scans/nmap/job-123/results/")
+	count, err := tracker.Count(context.Background(), "bucket", "scans/nmap/job-123/results/")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -260,7 +365,7 @@ func TestRealTracker_NilCounterAlwaysUsesStorage(t *testing.T) {
 
 	tracker := &realTracker{
 		counter: nil,
-		storage: &mockCountStorage{
+		Storage: &mockCountStorage{
 			countFunc: func(_ context.Context, _, _ string) (int, error) {
 				storageCalled = true
 				return 10, nil
@@ -269,7 +374,7 @@ func TestRealTracker_NilCounterAlwaysUsesStorage(t *testing.T) {
 		useCounter: false, // nil counter means this is always false
 	}
 
-	count, err := tracker.CountResults(context.Background(), "bucket", "scans/nmap/job-123/results/")
+	count, err := tracker.Count(context.Background(), "bucket", "scans/nmap/job-123/results/")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -323,35 +428,15 @@ func testSelfhostedInfra() core.InfraOutputs {
 }
 
 func TestStatusModel_SelfhostedUsesLaunchWorkers(t *testing.T) {
-	sub := &mockSubmitter{}
-	m := NewStatusWithDeps(testSelfhostedInfra(), sub, &mockTracker{})
-	m.Init()
-
-	// Enqueue succeeds → launch phase.
-	_, cmd := m.Update(enqueueProgressMsg{sent: 2, total: 2})
-	if m.phase != phaseLaunching {
-		t.Fatalf("expected phaseLaunching, got %d", m.phase)
-	}
-	if cmd == nil {
-		t.Fatal("expected launch command")
-	}
-	// Execute the launch command — should call LaunchWorkers, not LaunchSpotWorkers.
-	msg := cmd()
-	_, ok := msg.(launchProgressMsg)
-	if !ok {
-		t.Fatalf("expected launchProgressMsg (not spotLaunchMsg), got %T", msg)
-	}
-	if sub.launchCalls != 1 {
-		t.Fatalf("expected LaunchWorkers to be called once, got %d", sub.launchCalls)
-	}
-}
-
-func TestStatusModel_SelfhostedNeverUsesSpot(t *testing.T) {
 	infra := testSelfhostedInfra()
 	infra.WorkerCount = 200 // above SpotThreshold
-	infra.ComputeMode = "auto"
-	if useSpot(infra) {
-		t.Fatal("selfhosted should never use spot even with high worker count")
+	f := &fakeCloud{}
+	m := NewStatusWithDeps(infra, f.provider())
+	startJob(t, m)
+
+	// Selfhosted launches containers, never spot, even with many workers.
+	if f.containers != 1 || f.spots != 0 {
+		t.Fatalf("expected one container launch, got %d containers %d spot", f.containers, f.spots)
 	}
 }
 
@@ -359,11 +444,11 @@ func TestStatusModel_SelfhostedDestroyAfterSkipped(t *testing.T) {
 	infra := testSelfhostedInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = ""
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.totalTargets = 2
 	m.phase = phaseScanning
 
-	m.Update(scanProgressMsg{completed: 2})
+	m.Update(scanDoneMsg{})
 	if m.phase != phaseComplete {
 		t.Fatalf("expected phaseComplete, got %d", m.phase)
 	}
@@ -377,23 +462,15 @@ func TestStatusModel_ProviderNativeSkipsLaunchWorkers(t *testing.T) {
 	infra.Cloud = cloud.KindHetzner
 	infra.FleetWorkerCount = 3
 
-	sub := &mockSubmitter{}
-	m := NewStatusWithDeps(infra, sub, &mockTracker{})
-	m.Init()
+	f := &fakeCloud{}
+	m := NewStatusWithDeps(infra, f.provider())
+	startJob(t, m)
 
-	_, cmd := m.Update(enqueueProgressMsg{sent: 2, total: 2})
-	if cmd == nil {
-		t.Fatal("expected launch command")
-	}
-	msg := cmd()
-	if _, ok := msg.(launchProgressMsg); !ok {
-		t.Fatalf("expected launchProgressMsg, got %T", msg)
-	}
-	if sub.launchCalls != 0 {
-		t.Fatalf("expected provider-native path to skip LaunchWorkers, got %d calls", sub.launchCalls)
+	if m.workersUp != 3 {
+		t.Fatalf("expected the 3 fleet workers, got %d", m.workersUp)
 	}
-	if sub.spotCalls != 0 {
-		t.Fatalf("expected provider-native path to skip LaunchSpotWorkers, got %d calls", sub.spotCalls)
+	if f.containers != 0 || f.spots != 0 {
+		t.Fatalf("expected provider-native path to skip launches, got %d containers %d spot", f.containers, f.spots)
 	}
 }
 
@@ -401,8 +478,7 @@ func TestStatusModel_SelfhostedExportComplete_SkipsDestroy(t *testing.T) {
 	infra := testSelfhostedInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
-	m.storage = &mockExportStorage{}
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.destroyer = &mockDestroyer{} // destroyer exists but should be skipped
 	m.phase = phaseExporting
 
@@ -419,39 +495,6 @@ func TestStatusModel_SelfhostedExportComplete_SkipsDestroy(t *testing.T) {
 	}
 }
 
-func TestUseSpot_Auto(t *testing.T) {
-	low := core.InfraOutputs{WorkerCount: 10, ComputeMode: "auto"}
-	if useSpot(low) {
-		t.Fatal("expected Fargate for 10 workers in auto mode")
-	}
-	high := core.InfraOutputs{WorkerCount: 100, ComputeMode: "auto"}
-	if !useSpot(high) {
-		t.Fatal("expected Spot for 100 workers in auto mode")
-	}
-}
-
-func TestUseSpot_Forced(t *testing.T) {
-	fargate := core.InfraOutputs{WorkerCount: 200, ComputeMode: "fargate"}
-	if useSpot(fargate) {
-		t.Fatal("expected Fargate when mode is fargate")
-	}
-	spot := core.InfraOutputs{WorkerCount: 5, ComputeMode: "spot"}
-	if !useSpot(spot) {
-		t.Fatal("expected Spot when mode is spot")
-	}
-}
-
-func TestUseSpot_EmptyDefaultsToAuto(t *testing.T) {
-	empty := core.InfraOutputs{WorkerCount: 10, ComputeMode: ""}
-	if useSpot(empty) {
-		t.Fatal("expected Fargate for empty mode with 10 workers")
-	}
-	emptyHigh := core.InfraOutputs{WorkerCount: 60, ComputeMode: ""}
-	if !useSpot(emptyHigh) {
-		t.Fatal("expected Spot for empty mode with 60 workers")
-	}
-}
-
 func TestStatusModel_SpotLaunch(t *testing.T) {
 	infra := testInfra()
 	infra.ComputeMode = "spot"
@@ -459,33 +502,25 @@ func TestStatusModel_SpotLaunch(t *testing.T) {
 	infra.AMIID = "ami-test"
 	infra.InstanceProfileARN = "arn:profile"
 
-	sub := &mockSubmitter{}
-	m := NewStatusWithDeps(infra, sub, &mockTracker{})
-
-	// Enqueue
-	cmd := m.Init()
-	msg := cmd()
-	_, cmd = m.Update(msg)
+	f := &fakeCloud{}
+	m := NewStatusWithDeps(infra, f.provider())
+	startJob(t, m)
 
-	// Launch — should use spot path
-	msg = cmd()
-	spotMsg, ok := msg.(spotLaunchMsg)
-	if !ok {
-		t.Fatalf("expected spotLaunchMsg, got %T", msg)
-	}
-	_, _ = m.Update(spotMsg)
 	if m.phase != phaseScanning {
 		t.Fatalf("expected phaseScanning, got %d", m.phase)
 	}
-	if len(m.spotInstanceIDs) != 2 {
-		t.Fatalf("expected 2 spot instance IDs, got %d", len(m.spotInstanceIDs))
+	if f.spots != 1 || f.containers != 0 {
+		t.Fatalf("expected one spot launch, got %d spot %d containers", f.spots, f.containers)
+	}
+	if m.workersUp != 2 {
+		t.Fatalf("expected 2 spot workers, got %d", m.workersUp)
 	}
 }
 
 func TestStatusModel_NoTargets(t *testing.T) {
 	infra := testInfra()
 	infra.TargetsContent = ""
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	cmd := m.Init()
 
 	if cmd != nil {
@@ -502,7 +537,7 @@ func TestStatusModel_ViewShowsReusedInfra(t *testing.T) {
 	infra := testInfra()
 	infra.Reused = true
 	infra.CleanupPolicy = "reuse"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.totalTargets = 10
 	m.phase = phaseScanning
 
@@ -519,7 +554,7 @@ func TestStatusModel_ViewShowsFreshlyDeployed(t *testing.T) {
 	infra := testInfra()
 	infra.Reused = false
 	infra.CleanupPolicy = "destroy-after"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.totalTargets = 10
 	m.phase = phaseScanning
 
@@ -536,11 +571,11 @@ func TestStatusModel_DestroyAfterWithoutOutputDir_ShowsWarning(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "" // no output dir
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.totalTargets = 2
 	m.phase = phaseScanning
 
-	_, cmd := m.Update(scanProgressMsg{completed: 2})
+	_, cmd := m.Update(scanDoneMsg{})
 	if m.phase != phaseComplete {
 		t.Fatalf("expected phaseComplete, got %d", m.phase)
 	}
@@ -559,12 +594,11 @@ func TestStatusModel_DestroyAfterWithOutputDir_ExportsFirst(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.totalTargets = 2
 	m.phase = phaseScanning
-	m.storage = &mockExportStorage{} // enable export
 
-	_, cmd := m.Update(scanProgressMsg{completed: 2})
+	_, cmd := m.Update(scanDoneMsg{})
 	if m.phase != phaseExporting {
 		t.Fatalf("expected phaseExporting, got %d", m.phase)
 	}
@@ -577,7 +611,7 @@ func TestStatusModel_ExportComplete_SetsExported(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.phase = phaseExporting
 
 	_, cmd := m.Update(exportCompleteMsg{dir: "/tmp/export/nmap/job-123", count: 5})
@@ -601,7 +635,7 @@ func TestStatusModel_ProviderNativeExportComplete_TriggersDestroy(t *testing.T)
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
 
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.phase = phaseExporting
 	m.destroyer = &mockDestroyer{}
 
@@ -617,7 +651,7 @@ func TestStatusModel_ProviderNativeExportComplete_TriggersDestroy(t *testing.T)
 func TestStatusModel_ExportFailed_SetsWarning(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.phase = phaseExporting
 
 	m.Update(exportCompleteMsg{err: context.DeadlineExceeded})
@@ -636,7 +670,7 @@ func TestStatusModel_ViewShowsExportingPhase(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/out"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.totalTargets = 10
 	m.completed = 10
 	m.phase = phaseExporting
@@ -654,7 +688,7 @@ func TestStatusModel_ViewShowsExportedOnComplete(t *testing.T) {
 	infra := testInfra()
 	infra.Exported = true
 	infra.ExportDir = "/tmp/export/nmap/job-123"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.totalTargets = 10
 	m.completed = 10
 	m.phase = phaseComplete
@@ -672,11 +706,11 @@ func TestStatusModel_ReusePolicyNoExport(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "reuse"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.totalTargets = 2
 	m.phase = phaseScanning
 
-	_, _ = m.Update(scanProgressMsg{completed: 2})
+	_, _ = m.Update(scanDoneMsg{})
 	if m.phase != phaseComplete {
 		t.Fatalf("expected phaseComplete, got %d", m.phase)
 	}
@@ -685,24 +719,13 @@ func TestStatusModel_ReusePolicyNoExport(t *testing.T) {
 	}
 }
 
-// mockExportStorage is a minimal cloud.Storage for export gating tests.
-type mockExportStorage struct{}
-
-func (s *mockExportStorage) Upload(context.Context, string, string, []byte) error { return nil }
-func (s *mockExportStorage) Download(context.Context, string, string) ([]byte, error) {
-	return nil, nil
-}
-func (s *mockExportStorage) List(context.Context, string, string) ([]string, error) { return nil, nil }
-func (s *mockExportStorage) Count(context.Context, string, string) (int, error)     { return 0, nil }
-
 // --- Track 1 PR 5.12: auto-destroy lifecycle tests ---
 
 func TestStatusModel_ExportSuccess_DestroyAfter_TriggersDestroy(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
-	m.storage = &mockExportStorage{}
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.destroyer = &mockDestroyer{}
 	m.phase = phaseExporting
 
@@ -722,8 +745,7 @@ func TestStatusModel_ExportSuccess_ReusePolicySkipsDestroy(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "reuse"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
-	m.storage = &mockExportStorage{}
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.destroyer = &mockDestroyer{}
 	m.phase = phaseExporting
 
@@ -736,11 +758,11 @@ func TestStatusModel_ExportSuccess_ReusePolicySkipsDestroy(t *testing.T) {
 	infra2 := testInfra()
 	infra2.CleanupPolicy = "reuse"
 	infra2.OutputDir = "/tmp/export"
-	m2 := NewStatusWithDeps(infra2, &mockSubmitter{}, &mockTracker{})
+	m2 := NewStatusWithDeps(infra2, (&fakeCloud{}).provider())
 	m2.totalTargets = 2
 	m2.phase = phaseScanning
 
-	_, _ = m2.Update(scanProgressMsg{completed: 2})
+	_, _ = m2.Update(scanDoneMsg{})
 	if m2.phase != phaseComplete {
 		t.Fatalf("expected phaseComplete for reuse policy, got %d", m2.phase)
 	}
@@ -750,8 +772,7 @@ func TestStatusModel_ExportSuccess_NoDestroyer_ShowsWarning(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
-	m.storage = &mockExportStorage{}
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	// destroyer is nil
 	m.phase = phaseExporting
 
@@ -770,7 +791,7 @@ func TestStatusModel_ExportSuccess_NoDestroyer_ShowsWarning(t *testing.T) {
 func TestStatusModel_DestroySuccess_SetsDestroyed(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.phase = phaseDestroying
 
 	_, cmd := m.Update(autoDestroyCompleteMsg{err: nil})
@@ -794,7 +815,7 @@ func TestStatusModel_DestroySuccess_SetsDestroyed(t *testing.T) {
 func TestStatusModel_DestroyFailure_SetsDestroyErr(t *testing.T) {
 	infra := testInfra()
 	infra.CleanupPolicy = "destroy-after"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.phase = phaseDestroying
 
 	_, cmd := m.Update(autoDestroyCompleteMsg{err: context.DeadlineExceeded})
@@ -820,7 +841,7 @@ func TestStatusModel_ViewShowsDestroyingPhase(t *testing.T) {
 	infra.CleanupPolicy = "destroy-after"
 	infra.Exported = true
 	infra.ExportDir = "/tmp/export/nmap/job-123"
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.totalTargets = 10
 	m.completed = 10
 	m.phase = phaseDestroying
@@ -839,7 +860,7 @@ func TestStatusModel_ViewShowsDestroyedOnComplete(t *testing.T) {
 	infra.Exported = true
 	infra.ExportDir = "/tmp/export/nmap/job-123"
 	infra.Destroyed = true
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.totalTargets = 10
 	m.completed = 10
 	m.phase = phaseComplete
@@ -855,14 +876,13 @@ func TestStatusModel_AutoDestroyEndToEnd(t *testing.T) {
 	infra.CleanupPolicy = "destroy-after"
 	infra.OutputDir = "/tmp/export"
 	destroyer := &mockDestroyer{}
-	m := NewStatusWithDeps(infra, &mockSubmitter{}, &mockTracker{})
-	m.storage = &mockExportStorage{}
+	m := NewStatusWithDeps(infra, (&fakeCloud{}).provider())
 	m.destroyer = destroyer
 	m.totalTargets = 2
 	m.phase = phaseScanning
 
 	// Scan complete triggers export.
-	_, _ = m.Update(scanProgressMsg{completed: 2})
+	_, _ = m.Update(scanDoneMsg{})
 	if m.phase != phaseExporting {
 		t.Fatalf("expected phaseExporting, got %d", m.phase)
 	}
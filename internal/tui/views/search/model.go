@@ -0,0 +1,279 @@
+// Package search is the TUI view that searches the local results index
+// across jobs.
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"heph4estus/internal/index"
+	"heph4estus/internal/tui/core"
+)
+
+type keyMap struct {
+	Search key.Binding
+	Up     key.Binding
+	Down   key.Binding
+	Back   key.Binding
+	Quit   key.Binding
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Search, k.Up, k.Down, k.Back, k.Quit}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{k.Search, k.Up, k.Down, k.Back, k.Quit}}
+}
+
+var keys = keyMap{
+	Search: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "search")),
+	Up:     key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "up")),
+	Down:   key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "down")),
+	Back:   key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+	Quit:   key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
+}
+
+// searchDoneMsg carries the outcome of a search.
+type searchDoneMsg struct {
+	query   string
+	records []index.Record
+	jobs    int
+	err     error
+}
+
+// Deps abstracts the results index for testability.
+type Deps struct {
+	// Search runs q and returns the matching findings and the number of
+	// indexed jobs.
+	Search func(q index.Query) ([]index.Record, int, error)
+	Now    func() time.Time
+}
+
+// DefaultDeps searches the index at its default path.
+func DefaultDeps() Deps {
+	return Deps{
+		Search: func(q index.Query) ([]index.Record, int, error) {
+			db, err := index.OpenDefault()
+			if err != nil {
+				return nil, 0, err
+			}
+			defer func() { _ = db.Close() }()
+			jobs, err := db.Jobs()
+			if err != nil {
+				return nil, 0, err
+			}
+			records, err := db.Search(q)
+			return records, len(jobs), err
+		},
+		Now: time.Now,
+	}
+}
+
+// Model searches the findings of every indexed job.
+type Model struct {
+	deps  Deps
+	input textinput.Model
+	help  help.Model
+
+	query    string // the query the records answer
+	records  []index.Record
+	jobs     int
+	searched bool
+	cursor   int
+	errMsg   string
+
+	width  int
+	height int
+}
+
+// New creates a search view over the default results index.
+func New() *Model {
+	return NewWithDeps(DefaultDeps())
+}
+
+// NewWithDeps creates a search view with injected dependencies (for testing).
+func NewWithDeps(deps Deps) *Model {
+	in := textinput.New()
+	in.Placeholder = "port:8443 since:30d severity:high,critical tool:nuclei example.com"
+	in.CharLimit = 256
+	in.SetWidth(70)
+	in.Focus()
+
+	h := help.New()
+	h.Styles = help.Styles{
+		ShortKey:       lipgloss.NewStyle().Foreground(core.Steel),
+		ShortDesc:      lipgloss.NewStyle().Foreground(core.Steel),
+		ShortSeparator: lipgloss.NewStyle().Foreground(core.Steel),
+		FullKey:        lipgloss.NewStyle().Foreground(core.Steel),
+		FullDesc:       lipgloss.NewStyle().Foreground(core.Steel),
+		FullSeparator:  lipgloss.NewStyle().Foreground(core.Steel),
+		Ellipsis:       lipgloss.NewStyle().Foreground(core.Steel),
+	}
+	return &Model{deps: deps, input: in, help: h}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *Model) Update(msg tea.Msg) (core.View, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.SetWidth(msg.Width)
+		return m, nil
+
+	case searchDoneMsg:
+		m.searched = true
+		m.query = msg.query
+		m.records, m.jobs, m.cursor = msg.records, msg.jobs, 0
+		m.errMsg = ""
+		if msg.err != nil {
+			m.records = nil
+			m.errMsg = msg.err.Error()
+		}
+		return m, nil
+
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return core.NavigateMsg{Target: core.ViewMenu} }
+		case "enter":
+			return m, m.search(m.input.Value())
+		case "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case "down":
+			if m.cursor < len(m.records)-1 {
+				m.cursor++
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// search parses and runs a query off the UI goroutine.
+func (m *Model) search(text string) tea.Cmd {
+	deps := m.deps
+	return func() tea.Msg {
+		q, err := index.ParseQuery(text, deps.Now())
+		if err != nil {
+			return searchDoneMsg{query: text, err: err}
+		}
+		records, jobs, err := deps.Search(q)
+		if errors.Is(err, index.ErrLocked) {
+			err = fmt.Errorf("%w; try again when it finishes", err)
+		}
+		return searchDoneMsg{query: text, records: records, jobs: jobs, err: err}
+	}
+}
+
+// visibleRows is how many result rows fit on screen.
+func (m *Model) visibleRows() int {
+	if m.height == 0 {
+		return 20
+	}
+	// Title, input, summary, header, detail and help take about 14 lines.
+	return max(m.height-14, 3)
+}
+
+func (m *Model) View() string {
+	var b strings.Builder
+
+	b.WriteString(core.TitleBarStyle.Render("  Search Results Index  "))
+	b.WriteString("\n\n")
+	b.WriteString("  " + m.input.View() + "\n")
+	b.WriteString("  " + core.MutedStyle.Render("Terms: tool: job: port: severity: type: since: until: (30d, 2026-10-01); other words match targets") + "\n\n")
+
+	switch {
+	case m.errMsg != "":
+		b.WriteString("  " + core.ErrorStyle.Render(m.errMsg) + "\n")
+	case !m.searched:
+		b.WriteString("  " + core.MutedStyle.Render("Jobs are indexed when exported with --out, or with heph index --job-id <id>.") + "\n")
+	case m.jobs == 0:
+		b.WriteString("  " + core.MutedStyle.Render("The results index is empty; index jobs with heph index --job-id <id>.") + "\n")
+	case len(m.records) == 0:
+		b.WriteString("  " + core.MutedStyle.Render(fmt.Sprintf("No findings match in %d indexed jobs.", m.jobs)) + "\n")
+	default:
+		m.writeRecords(&b)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.help.View(keys))
+	return b.String()
+}
+
+func (m *Model) writeRecords(b *strings.Builder) {
+	jobs := make(map[string]bool)
+	for _, r := range m.records {
+		jobs[r.JobID] = true
+	}
+	summary := fmt.Sprintf("%d findings in %d of %d indexed jobs", len(m.records), len(jobs), m.jobs)
+	if m.query != "" {
+		summary += fmt.Sprintf(" for %q", m.query)
+	}
+	b.WriteString("  " + core.MutedStyle.Render(summary) + "\n\n")
+
+	headerStyle := lipgloss.NewStyle().Foreground(core.Gold).Bold(true)
+	b.WriteString(headerStyle.Render(fmt.Sprintf("  %-9s %-13s %-40s %-20s %s", "SEVERITY", "TYPE", "LOCATION", "JOB", "SEEN")))
+	b.WriteString("\n")
+
+	rows := m.visibleRows()
+	start := 0
+	if m.cursor >= rows {
+		start = m.cursor - rows + 1
+	}
+	end := min(start+rows, len(m.records))
+	for i := start; i < end; i++ {
+		r := m.records[i]
+		seen := ""
+		if !r.Time.IsZero() {
+			seen = r.Time.Local().Format("2006-01-02 15:04")
+		}
+		line := fmt.Sprintf("  %-9s %-13s %-40s %-20s %s",
+			strings.ToUpper(string(r.Severity)), r.Type, truncate(r.Location(), 40), truncate(r.JobID, 20), seen)
+		if i == m.cursor {
+			b.WriteString(core.SelectedStyle.Render("► "+line[2:]) + "\n")
+		} else {
+			b.WriteString(core.NormalStyle.Render(line) + "\n")
+		}
+	}
+	if end < len(m.records) {
+		b.WriteString("  " + core.MutedStyle.Render(fmt.Sprintf("… %d more", len(m.records)-end)) + "\n")
+	}
+
+	// Detail of the selected finding.
+	r := m.records[m.cursor]
+	var detail []string
+	for _, part := range []string{r.Tool, r.Check, r.Title, r.Evidence} {
+		if part != "" {
+			detail = append(detail, part)
+		}
+	}
+	if r.Target != "" {
+		detail = append(detail, "target "+r.Target)
+	}
+	b.WriteString("\n  " + core.MutedStyle.Render(strings.Join(detail, "  |  ")) + "\n")
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
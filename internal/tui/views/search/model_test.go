@@ -0,0 +1,100 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"heph4estus/internal/findings"
+	"heph4estus/internal/index"
+	"heph4estus/internal/tui/core"
+)
+
+var now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func typeQuery(m *Model, q string) {
+	for _, r := range q {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+}
+
+func TestSearchRunsParsedQuery(t *testing.T) {
+	var got index.Query
+	m := NewWithDeps(Deps{
+		Search: func(q index.Query) ([]index.Record, int, error) {
+			got = q
+			return []index.Record{
+				{Finding: findings.Finding{Tool: "nmap", JobID: "nmap-1", Type: findings.TypeOpenPort, Severity: findings.SeverityInfo, Asset: "10.0.0.2", Port: 8443, Service: "https"}, Target: "10.0.0.0/24", Time: now},
+				{Finding: findings.Finding{Tool: "httpx", JobID: "httpx-2", Type: findings.TypeHTTPService, Severity: findings.SeverityInfo, Asset: "app.example.com", Port: 8443, URL: "https://app.example.com:8443", Title: "Login"}, Time: now},
+			}, 5, nil
+		},
+		Now: func() time.Time { return now },
+	})
+	typeQuery(m, "port:8443 since:30d")
+	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter should start a search")
+	}
+	m.Update(cmd())
+
+	if got.Port != 8443 || !got.Since.Equal(now.AddDate(0, 0, -30)) {
+		t.Fatalf("query = %+v", got)
+	}
+	v := m.View()
+	for _, want := range []string{"2 findings in 2 of 5 indexed jobs", "10.0.0.2:8443", "https://app.example.com:8443", "nmap-1", "target 10.0.0.0/24"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("view missing %q:\n%s", want, v)
+		}
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	if v := m.View(); !strings.Contains(v, "Login") {
+		t.Errorf("detail should follow the cursor:\n%s", v)
+	}
+}
+
+func TestSearchShowsErrorsAndEmptyIndex(t *testing.T) {
+	m := NewWithDeps(Deps{
+		Search: func(index.Query) ([]index.Record, int, error) { return nil, 0, nil },
+		Now:    func() time.Time { return now },
+	})
+	typeQuery(m, "port:http")
+	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m.Update(cmd())
+	if v := m.View(); !strings.Contains(v, "is not a port") {
+		t.Errorf("expected parse error:\n%s", v)
+	}
+
+	m = NewWithDeps(Deps{
+		Search: func(index.Query) ([]index.Record, int, error) { return nil, 0, nil },
+		Now:    func() time.Time { return now },
+	})
+	_, cmd = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m.Update(cmd())
+	if v := m.View(); !strings.Contains(v, "index is empty") {
+		t.Errorf("expected empty index hint:\n%s", v)
+	}
+
+	m = NewWithDeps(Deps{
+		Search: func(index.Query) ([]index.Record, int, error) { return nil, 0, index.ErrLocked },
+		Now:    func() time.Time { return now },
+	})
+	_, cmd = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	msg := cmd().(searchDoneMsg)
+	if !errors.Is(msg.err, index.ErrLocked) {
+		t.Errorf("err = %v", msg.err)
+	}
+}
+
+func TestSearchEscReturnsToMenu(t *testing.T) {
+	m := NewWithDeps(DefaultDeps())
+	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if cmd == nil {
+		t.Fatal("esc should navigate")
+	}
+	if nav, ok := cmd().(core.NavigateMsg); !ok || nav.Target != core.ViewMenu {
+		t.Fatalf("expected NavigateMsg to menu, got %#v", nav)
+	}
+}
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"heph4estus/internal/modules"
+)
+
+// maxAssetBytes caps the unpacked size of an asset bundle.
+const maxAssetBytes = 256 << 20
+
+// fetchAssets downloads the job's asset bundle and extracts it into
+// tempDir/assets, returning that directory.
+func (e *Executor) fetchAssets(ctx context.Context, mod *modules.ModuleDefinition, tempDir, key string) (string, error) {
+	if len(mod.Assets) == 0 {
+		return "", fmt.Errorf("module %s does not accept assets", mod.Name)
+	}
+	data, err := e.storage.Download(ctx, e.bucket, key)
+	if err != nil {
+		return "", fmt.Errorf("downloading assets from %s: %w", key, err)
+	}
+	dir := filepath.Join(tempDir, "assets")
+	if err := ExtractAssets(data, dir); err != nil {
+		return "", fmt.Errorf("extracting assets from %s: %w", key, err)
+	}
+	return dir, nil
+}
+
+// ExtractAssets unpacks a gzipped tar into dir. Only directories and regular
+// files are written; entries that would land outside dir are rejected.
+func ExtractAssets(data []byte, dir string) error {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zr.Close() }()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	var total int64
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("entry %q escapes the bundle", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			total += hdr.Size
+			if total > maxAssetBytes {
+				return fmt.Errorf("bundle exceeds %d bytes", maxAssetBytes)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode)&0o755|0o600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, io.LimitReader(tr, hdr.Size))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("entry %q is not a file or directory", hdr.Name)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+
+	"heph4estus/internal/modules"
+)
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, body := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExecute_Assets(t *testing.T) {
+	storage := &mockStorage{data: map[string][]byte{
+		"inputs/assets_scripts.tar.gz": tarGz(t, map[string]string{"custom/probe.nse": "probe\n"}),
+	}}
+	mod := &modules.ModuleDefinition{
+		Name:          "cat",
+		Exec:          []string{"sh", "-c", `cat "$1/custom/probe.nse" > "$2"`, "sh", "{{assets}}", "{{output}}"},
+		InputType:     modules.InputTypeTargetList,
+		OutputExt:     "txt",
+		InstallCmd:    "true",
+		DefaultCPU:    256,
+		DefaultMemory: 512,
+		Timeout:       "1m",
+		Assets:        []string{"scripts"},
+	}
+	executor := NewExecutor(&mockLogger{}, storage, "test-bucket")
+	task := Task{ToolName: "cat", Target: "a.example", Assets: "inputs/assets_scripts.tar.gz"}
+
+	result, out, err := executor.Execute(context.Background(), mod, task)
+	if err != nil || result.Error != "" {
+		t.Fatalf("Execute = %v, %q", err, result.Error)
+	}
+	if string(out) != "probe\n" {
+		t.Fatalf("output = %q", out)
+	}
+
+	mod.Assets = nil
+	if _, _, err := executor.Execute(context.Background(), mod, task); err == nil || !strings.Contains(err.Error(), "does not accept assets") {
+		t.Fatalf("undeclared assets: %v", err)
+	}
+}
+
+func TestExtractAssets_RejectsEscapes(t *testing.T) {
+	for _, name := range []string{"../evil.sh", "/etc/evil.sh", "a/../../evil.sh"} {
+		if err := ExtractAssets(tarGz(t, map[string]string{name: "x"}), t.TempDir()); err == nil {
+			t.Errorf("ExtractAssets(%q) = nil error", name)
+		}
+	}
+}
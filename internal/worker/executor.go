@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -39,6 +40,7 @@ func (e *Executor) Execute(ctx context.Context, mod *modules.ModuleDefinition, t
 		JobID:     task.JobID,
 		Target:    task.Target,
 		Timestamp: time.Now(),
+		Metadata:  task.Metadata,
 	}
 
 	tempDir, err := os.MkdirTemp("", "heph-worker-*")
@@ -73,6 +75,24 @@ func (e *Executor) Execute(ctx context.Context, mod *modules.ModuleDefinition, t
 		Target:  task.Target,
 		Options: task.Options,
 	}
+	if len(mod.Wordlists) > 0 {
+		vars.Wordlists, err = e.fetchWordlists(ctx, mod, tempDir, task.Wordlists)
+		if err != nil {
+			return result, nil, err
+		}
+		vars.Wordlists[mod.ChunkedWordlist()] = inputPath
+	}
+	if task.Assets != "" {
+		vars.Assets, err = e.fetchAssets(ctx, mod, tempDir, task.Assets)
+		if err != nil {
+			return result, nil, err
+		}
+	}
+	if rate := task.Rate; rate > 0 {
+		vars.Rate = strconv.Itoa(rate)
+	} else if mod.DefaultRate > 0 {
+		vars.Rate = strconv.Itoa(mod.DefaultRate)
+	}
 
 	// Execute with module timeout.
 	timeout := mod.TimeoutDuration()
@@ -115,7 +135,9 @@ func (e *Executor) Execute(ctx context.Context, mod *modules.ModuleDefinition, t
 		}
 	}
 
+	started := time.Now()
 	output, execErr := cmd.CombinedOutput()
+	result.DurationMs = time.Since(started).Milliseconds()
 	result.Output = string(output)
 
 	if execErr != nil {
@@ -137,3 +159,30 @@ func (e *Executor) Execute(ctx context.Context, mod *modules.ModuleDefinition, t
 
 	return result, outputBytes, nil
 }
+
+// fetchWordlists downloads the whole wordlists the module declares into
+// tempDir and returns their paths by name. Keys for undeclared names are
+// ignored.
+func (e *Executor) fetchWordlists(ctx context.Context, mod *modules.ModuleDefinition, tempDir string, keys map[string]string) (map[string]string, error) {
+	paths := make(map[string]string, len(keys)+1)
+	for _, w := range mod.WholeWordlists() {
+		name := w.Name
+		key, ok := keys[name]
+		if !ok {
+			if !w.Optional {
+				return nil, fmt.Errorf("task has no %s wordlist", name)
+			}
+			continue
+		}
+		data, err := e.storage.Download(ctx, e.bucket, key)
+		if err != nil {
+			return nil, fmt.Errorf("downloading wordlist %s from %s: %w", name, key, err)
+		}
+		path := filepath.Join(tempDir, "wordlist-"+name)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return nil, fmt.Errorf("writing wordlist %s: %w", name, err)
+		}
+		paths[name] = path
+	}
+	return paths, nil
+}
@@ -99,6 +99,9 @@ func TestExecute_Timeout(t *testing.T) {
 	if elapsed > 2*time.Second {
 		t.Fatalf("timeout took too long: %v", elapsed)
 	}
+	if result.DurationMs < 100 || result.DurationMs > elapsed.Milliseconds() {
+		t.Fatalf("DurationMs = %d, want between 100 and %d", result.DurationMs, elapsed.Milliseconds())
+	}
 }
 
 func TestExecute_InputFromTarget(t *testing.T) {
@@ -328,3 +331,41 @@ func TestExecute_ExplicitShellOptIn(t *testing.T) {
 		t.Fatalf("expected shell output, got %q", result.Output)
 	}
 }
+
+func TestExecute_NamedWordlists(t *testing.T) {
+	storage := &mockStorage{
+		data: map[string][]byte{
+			"inputs/chunk_0.txt": []byte("admin\n"),
+			"inputs/users.txt":   []byte("root\n"),
+		},
+	}
+	mod := &modules.ModuleDefinition{
+		Name:          "pair",
+		Exec:          []string{"sh", "-c", `cat "$1" "$2" > "$3"`, "sh", "{{wordlist:PATH}}", "{{wordlist:USER}}", "{{output}}"},
+		InputType:     modules.InputTypeWordlist,
+		OutputExt:     "txt",
+		InstallCmd:    "true",
+		DefaultCPU:    256,
+		DefaultMemory: 512,
+		Timeout:       "1m",
+		Wordlists: []modules.WordlistInput{
+			{Name: "PATH", Chunked: true},
+			{Name: "USER"},
+		},
+	}
+	executor := NewExecutor(&mockLogger{}, storage, "test-bucket")
+	task := Task{ToolName: "pair", InputKey: "inputs/chunk_0.txt", Wordlists: map[string]string{"USER": "inputs/users.txt"}}
+
+	result, out, err := executor.Execute(context.Background(), mod, task)
+	if err != nil || result.Error != "" {
+		t.Fatalf("Execute = %v, %q", err, result.Error)
+	}
+	if string(out) != "admin\nroot\n" {
+		t.Fatalf("output = %q", out)
+	}
+
+	task.Wordlists = nil
+	if _, _, err := executor.Execute(context.Background(), mod, task); err == nil || !strings.Contains(err.Error(), "no USER wordlist") {
+		t.Fatalf("missing required wordlist: %v", err)
+	}
+}
@@ -1,6 +1,12 @@
 package worker
 
-import "time"
+import (
+	"time"
+
+	"heph4estus/internal/politeness"
+	"heph4estus/internal/schedule"
+	"heph4estus/internal/scope"
+)
 
 // Task is the generic SQS message body for any tool.
 type Task struct {
@@ -12,6 +18,33 @@ type Task struct {
 	GroupID     string `json:"group_id,omitempty"`
 	ChunkIdx    int    `json:"chunk_idx,omitempty"`
 	TotalChunks int    `json:"total_chunks,omitempty"`
+	// Scope, when set, is the engagement scope the job was planned under.
+	// Workers re-check Target against it before executing.
+	Scope *scope.Digest `json:"scope,omitempty"`
+	// Window, when set, is the job's scan window. A worker that receives
+	// the task while the window is closed hands it back and pauses.
+	Window *schedule.Window `json:"window,omitempty"`
+	// GlobalRate, when set, is the job's rate limit across the whole fleet.
+	// Rate is this task's share of it, divided by the planned worker count;
+	// workers that follow the fleet heartbeat stream instead claim a share
+	// across the job's live workers, capped by what the job's other workers
+	// hold. Modules receive the share through the {{rate}} placeholder.
+	GlobalRate int `json:"global_rate,omitempty"`
+	Rate       int `json:"rate,omitempty"`
+	// Politeness, when set, caps how hard the fleet works the target's
+	// host. Workers take a shared lease before executing and requeue the
+	// task, delayed, while the host is saturated.
+	Politeness *politeness.Limits `json:"politeness,omitempty"`
+	// Wordlists maps the names of wordlists shipped whole to every task to
+	// their storage keys. The chunked wordlist stays in InputKey.
+	Wordlists map[string]string `json:"wordlists,omitempty"`
+	// Assets, when set, is the storage key of the job's asset bundle, a
+	// gzipped tar workers extract for the module's {{assets}} placeholder.
+	Assets string `json:"assets,omitempty"`
+	// Metadata is per-target context from the imported target list (for
+	// example an asset owner or previously discovered ports). It is copied
+	// into the Result unchanged.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // Result is the generic output uploaded to S3.
@@ -26,4 +59,8 @@ type Result struct {
 	GroupID     string    `json:"group_id,omitempty"`
 	ChunkIdx    int       `json:"chunk_idx,omitempty"`
 	TotalChunks int       `json:"total_chunks,omitempty"`
+	// DurationMs is how long the module command ran, in milliseconds.
+	DurationMs int64 `json:"duration_ms,omitempty"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -11,20 +12,46 @@ type TemplateVars struct {
 	Output  string
 	Target  string
 	Options string
+	Rate    string
+	// Wordlists maps named wordlist inputs to their file paths for the
+	// {{wordlist:NAME}} placeholder.
+	Wordlists map[string]string
+	// Assets is the directory the job's asset bundle was extracted into, for
+	// the {{assets}} placeholder.
+	Assets string
+}
+
+// unsetInput matches {{wordlist:NAME}} and {{assets}} placeholders left
+// after rendering, which belong to optional inputs the job did not supply.
+var unsetInput = regexp.MustCompile(`\{\{(wordlist:[A-Za-z0-9_]+|assets)\}\}`)
+
+func (v TemplateVars) replacer(withOptions bool) *strings.Replacer {
+	pairs := []string{
+		"{{input}}", v.Input,
+		"{{output}}", v.Output,
+		"{{target}}", v.Target,
+		"{{wordlist}}", v.Input,
+		"{{rate}}", v.Rate,
+	}
+	if withOptions {
+		pairs = append(pairs, "{{options}}", v.Options)
+	}
+	for name, path := range v.Wordlists {
+		pairs = append(pairs, "{{wordlist:"+name+"}}", path)
+	}
+	if v.Assets != "" {
+		pairs = append(pairs, "{{assets}}", v.Assets)
+	}
+	return strings.NewReplacer(pairs...)
 }
 
 // RenderCommand substitutes template placeholders in a module command string.
-// Supported placeholders: {{input}}, {{output}}, {{target}}, {{wordlist}}, {{options}}.
-// {{wordlist}} is an alias for {{input}}.
+// Supported placeholders: {{input}}, {{output}}, {{target}}, {{wordlist}}, {{options}}, {{rate}},
+// {{wordlist:NAME}} and {{assets}}. {{wordlist}} is an alias for {{input}}.
+// Placeholders of wordlists missing from vars.Wordlists, and {{assets}} when
+// the job shipped no assets, render empty.
 func RenderCommand(cmdTemplate string, vars TemplateVars) string {
-	r := strings.NewReplacer(
-		"{{input}}", vars.Input,
-		"{{output}}", vars.Output,
-		"{{target}}", vars.Target,
-		"{{wordlist}}", vars.Input,
-		"{{options}}", vars.Options,
-	)
-	return r.Replace(cmdTemplate)
+	return unsetInput.ReplaceAllString(vars.replacer(true).Replace(cmdTemplate), "")
 }
 
 // CommandUsesPlaceholder checks if a command template contains a given placeholder.
@@ -34,15 +61,12 @@ func CommandUsesPlaceholder(cmdTemplate, placeholder string) bool {
 
 // RenderArgs substitutes placeholders into argv-style command definitions.
 // The {{options}} placeholder expands into zero or more arguments using
-// shell-like quoting rules, but without invoking a shell.
+// shell-like quoting rules, but without invoking a shell. Arguments that
+// reference a wordlist missing from vars.Wordlists, or {{assets}} when the
+// job shipped no assets, are dropped.
 func RenderArgs(execTemplate []string, vars TemplateVars) ([]string, error) {
 	args := make([]string, 0, len(execTemplate))
-	replacer := strings.NewReplacer(
-		"{{input}}", vars.Input,
-		"{{output}}", vars.Output,
-		"{{target}}", vars.Target,
-		"{{wordlist}}", vars.Input,
-	)
+	replacer := vars.replacer(false)
 
 	for _, arg := range execTemplate {
 		if arg == "{{options}}" {
@@ -53,7 +77,11 @@ func RenderArgs(execTemplate []string, vars TemplateVars) ([]string, error) {
 			args = append(args, optionArgs...)
 			continue
 		}
-		args = append(args, replacer.Replace(arg))
+		rendered := replacer.Replace(arg)
+		if unsetInput.MatchString(rendered) {
+			continue
+		}
+		args = append(args, rendered)
 	}
 	return args, nil
 }
@@ -1,6 +1,11 @@
 package worker
 
-import "testing"
+import (
+	"slices"
+	"testing"
+
+	"heph4estus/internal/modules"
+)
 
 func TestRenderCommand(t *testing.T) {
 	tests := []struct {
@@ -82,6 +87,30 @@ func TestRenderArgs(t *testing.T) {
 			vars:     TemplateVars{Target: "10.0.0.1"},
 			want:     []string{"tool", "10.0.0.1"},
 		},
+		{
+			name:     "rate",
+			template: []string{"masscan", "--rate", "{{rate}}", "{{target}}"},
+			vars:     TemplateVars{Target: "10.0.0.0/24", Rate: "250"},
+			want:     []string{"masscan", "--rate", "250", "10.0.0.0/24"},
+		},
+		{
+			name:     "named wordlists",
+			template: []string{"ffuf", "-w", "{{input}}:FUZZ", "-w={{wordlist:W2}}:W2", "-w={{wordlist:W3}}:W3"},
+			vars:     TemplateVars{Input: "/tmp/input", Wordlists: map[string]string{"W2": "/tmp/w2"}},
+			want:     []string{"ffuf", "-w", "/tmp/input:FUZZ", "-w=/tmp/w2:W2"},
+		},
+		{
+			name:     "assets",
+			template: []string{"nuclei", "-t={{assets}}", "-u", "{{target}}"},
+			vars:     TemplateVars{Target: "a.example", Assets: "/tmp/assets"},
+			want:     []string{"nuclei", "-t=/tmp/assets", "-u", "a.example"},
+		},
+		{
+			name:     "unset assets removed",
+			template: []string{"nmap", "--script={{assets}}", "{{target}}"},
+			vars:     TemplateVars{Target: "10.0.0.1"},
+			want:     []string{"nmap", "10.0.0.1"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -102,6 +131,35 @@ func TestRenderArgs(t *testing.T) {
 	}
 }
 
+func TestRenderArgs_FFUFPitchfork(t *testing.T) {
+	reg, err := modules.NewDefaultRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := reg.Get("ffuf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := RenderArgs(mod.Exec, TemplateVars{
+		Input:     "/tmp/users",
+		Output:    "/tmp/out.json",
+		Target:    "https://a.example/login?u=FUZZ&p=W2",
+		Options:   "-mode pitchfork",
+		Wordlists: map[string]string{"W2": "/tmp/passwords"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"ffuf", "-w", "/tmp/users:FUZZ", "-w=/tmp/passwords:W2",
+		"-u", "https://a.example/login?u=FUZZ&p=W2", "-of", "json", "-o", "/tmp/out.json", "-ac",
+		"-mode", "pitchfork",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("RenderArgs() = %q, want %q", got, want)
+	}
+}
+
 func TestCommandUsesPlaceholder(t *testing.T) {
 	tests := []struct {
 		template    string
@@ -167,3 +225,10 @@ func TestSplitOptions_UnterminatedQuote(t *testing.T) {
 		t.Fatal("expected error for unterminated quote")
 	}
 }
+
+func TestRenderCommand_UnsetWordlistRendersEmpty(t *testing.T) {
+	got := RenderCommand("tool -a {{wordlist:A}} -b '{{wordlist:B}}'", TemplateVars{Wordlists: map[string]string{"A": "/tmp/a"}})
+	if want := "tool -a /tmp/a -b ''"; got != want {
+		t.Fatalf("RenderCommand() = %q, want %q", got, want)
+	}
+}